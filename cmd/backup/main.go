@@ -10,11 +10,15 @@ import (
 	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/model"
 	"github.com/edulinq/autograder/internal/procedures/backup"
+	"github.com/edulinq/autograder/internal/util"
 )
 
 var args struct {
 	config.ConfigArgs
 	Course string `help:"ID of the course." arg:"" optional:""`
+
+	Incremental bool   `help:"Only store the submission files that changed since each course's most recent backup." default:"false"`
+	Verify      string `help:"Instead of making a backup, verify that the backup at this path is complete."`
 }
 
 func main() {
@@ -27,6 +31,11 @@ func main() {
 		log.Fatal("Could not load config options.", err)
 	}
 
+	if args.Verify != "" {
+		verify(args.Verify)
+		return
+	}
+
 	db.MustOpen()
 	defer db.MustClose()
 
@@ -52,8 +61,12 @@ func backupFromMap(courses map[string]*model.Course) []string {
 	courseIDs := make([]string, 0)
 	errorCount := 0
 
+	options := backup.BackupOptions{
+		Incremental: args.Incremental,
+	}
+
 	for _, course := range courses {
-		err := backup.BackupCourseFull(course, "", "")
+		_, err := backup.BackupCourseWithOptions(course, options)
 		if err != nil {
 			log.Error("Failed to backup course.", err, course)
 			errorCount++
@@ -68,3 +81,16 @@ func backupFromMap(courses map[string]*model.Course) []string {
 
 	return courseIDs
 }
+
+func verify(path string) {
+	result, err := backup.VerifyBackup(path)
+	if err != nil {
+		log.Fatal("Failed to verify backup.", err, log.NewAttr("path", path))
+	}
+
+	fmt.Println(util.MustToJSONIndent(result))
+
+	if !result.Valid {
+		log.Fatal("Backup is not complete.", log.NewAttr("path", path))
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/edulinq/autograder/internal/db"
 	"github.com/edulinq/autograder/internal/grader"
 	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/report"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -19,6 +20,7 @@ var args struct {
 	Assignment     string `help:"ID of the assignment." arg:""`
 	Submission     string `help:"Path to submission directory." required:"" type:"existingdir"`
 	OutPath        string `help:"Option path to output a JSON grading result." type:"path"`
+	HTMLOutPath    string `help:"Option path to output an HTML grading report." type:"path"`
 	User           string `help:"User email for the submission." default:"testuser"`
 	Message        string `help:"Submission message." default:""`
 	AllowLate      bool   `help:"Allow this submission to be graded, even if it is late." default:"false"`
@@ -68,5 +70,17 @@ func main() {
 		}
 	}
 
+	if args.HTMLOutPath != "" {
+		html, err := report.GetSubmissionHTMLReport(result.Info)
+		if err != nil {
+			log.Fatal("Failed to create HTML report.", assignment, err)
+		}
+
+		err = util.WriteFile(html, args.HTMLOutPath)
+		if err != nil {
+			log.Fatal("Failed to output HTML report.", assignment, log.NewAttr("outpath", args.HTMLOutPath), err)
+		}
+	}
+
 	fmt.Println(result.Info.Report())
 }
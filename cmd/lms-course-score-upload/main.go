@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/alecthomas/kong"
@@ -33,7 +34,7 @@ func main() {
 
 	course := db.MustGetCourse(args.Course)
 
-	result, err := scoring.FullCourseScoringAndUpload(course, args.DryRun)
+	result, err := scoring.FullCourseScoringAndUpload(context.Background(), course, args.DryRun)
 	if err != nil {
 		log.Fatal("Failed to score and upload assignment.", err, course)
 	}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/alecthomas/kong"
@@ -60,7 +61,7 @@ func main() {
 	if args.DryRun {
 		fmt.Println("Dry Run: Skipping upload.")
 	} else {
-		err = lms.UpdateAssignmentScores(course, assignment.GetLMSID(), grades)
+		err = lms.UpdateAssignmentScores(context.Background(), course, assignment.GetLMSID(), grades)
 		if err != nil {
 			log.Fatal("Could not upload grades.", err, assignment)
 		}
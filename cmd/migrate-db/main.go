@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/procedures/migrate"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+var args struct {
+	config.ConfigArgs
+
+	Source string `help:"Type of the database to migrate from." arg:""`
+	Target string `help:"Type of the database to migrate to." arg:""`
+
+	ValidateOnly   bool `help:"Do not migrate any data, only check that the target holds the same data as the source." default:"false"`
+	SkipValidation bool `help:"Do not check that the target holds the same data as the source after migrating." default:"false"`
+}
+
+func main() {
+	kong.Parse(&args,
+		kong.Description("Copy all data from one database backend into another (e.g., 'disk' to 'sqlite')."+
+			" Migrations can be resumed (or run again to pick up new data) by running this command again."+
+			" Both databases will use the current config (e.g., `db.pg.uri`)."),
+	)
+
+	err := config.HandleConfigArgs(args.ConfigArgs)
+	if err != nil {
+		log.Fatal("Could not load config options.", err)
+	}
+
+	if args.ValidateOnly && args.SkipValidation {
+		log.Fatal("Cannot both only validate and skip validation.")
+	}
+
+	if !args.ValidateOnly {
+		result, err := migrate.MigrateTypes(args.Source, args.Target)
+		if err != nil {
+			log.Fatal("Failed to migrate database.", err,
+				log.NewAttr("source", args.Source), log.NewAttr("target", args.Target), log.NewAttr("partial-result", result))
+		}
+
+		fmt.Println("Migration Counts:")
+		fmt.Println(util.MustToJSONIndent(result))
+	}
+
+	if args.SkipValidation {
+		return
+	}
+
+	result, err := migrate.ValidateTypes(args.Source, args.Target)
+	if err != nil {
+		log.Fatal("Failed to validate database migration.", err,
+			log.NewAttr("source", args.Source), log.NewAttr("target", args.Target))
+	}
+
+	fmt.Println("Validation:")
+	fmt.Println(util.MustToJSONIndent(result))
+
+	if !result.IsValid() {
+		os.Exit(1)
+	}
+}
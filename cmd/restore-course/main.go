@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/procedures/backup"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+var args struct {
+	config.ConfigArgs
+	Path string `help:"Path to a course backup (zip file or directory)." arg:""`
+
+	NewCourseID          string `help:"Restore the course under this ID instead of the ID in the backup."`
+	Merge                bool   `help:"Allow restoring into a course that already exists." default:"false"`
+	OverwriteCourse      bool   `help:"When merging, replace the existing course config with the config from the backup." default:"false"`
+	OverwriteUsers       bool   `help:"Replace the course information of conflicting users with the information from the backup." default:"false"`
+	OverwriteSubmissions bool   `help:"Replace conflicting submissions with the submissions from the backup." default:"false"`
+	SendEmails           bool   `help:"Send emails to newly created users." default:"false"`
+	DryRun               bool   `help:"Validate the backup and show what would be restored, but do not make any changes." default:"false"`
+}
+
+func main() {
+	kong.Parse(&args,
+		kong.Description("Restore a course from a backup."),
+	)
+
+	err := config.HandleConfigArgs(args.ConfigArgs)
+	if err != nil {
+		log.Fatal("Could not load config options.", err)
+	}
+
+	db.MustOpen()
+	defer db.MustClose()
+
+	options := backup.RestoreOptions{
+		NewCourseID:          args.NewCourseID,
+		Merge:                args.Merge,
+		OverwriteCourse:      args.OverwriteCourse,
+		OverwriteUsers:       args.OverwriteUsers,
+		OverwriteSubmissions: args.OverwriteSubmissions,
+		SendEmails:           args.SendEmails,
+		DryRun:               args.DryRun,
+		ContextUser:          db.MustGetRoot(),
+	}
+
+	result, userErr, err := backup.RestoreCourse(args.Path, options)
+	if userErr != nil {
+		log.Fatal("Backup could not be restored.", userErr, log.NewAttr("path", args.Path))
+	}
+
+	if err != nil {
+		log.Fatal("Failed to restore course.", err, log.NewAttr("path", args.Path))
+	}
+
+	fmt.Println(util.MustToJSONIndent(result))
+}
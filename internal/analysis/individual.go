@@ -13,6 +13,7 @@ import (
 	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/model"
 	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/tracing"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -48,7 +49,11 @@ func IndividualAnalysis(options AnalysisOptions) (map[string]*model.IndividualAn
 		StoreFunc:               db.StoreIndividualAnalysis,
 		RemoveFunc:              db.RemoveIndividualAnalysis,
 		WorkFunc: func(fullSubmissionID string) (*model.IndividualAnalysis, error) {
-			return computeSingleIndividualAnalysis(options, fullSubmissionID, true)
+			_, span := tracing.StartSpan(options.Context, "analysis.individual", tracing.NewAttr("submission", fullSubmissionID))
+			analysis, err := computeSingleIndividualAnalysis(options, fullSubmissionID, true)
+			tracing.EndSpan(span, err)
+
+			return analysis, err
 		},
 		WorkItemKeyFunc: func(fullSubmissionID string) string {
 			return fmt.Sprintf("analysis-individual-%s", fullSubmissionID)
@@ -78,7 +83,7 @@ func IndividualAnalysis(options AnalysisOptions) (map[string]*model.IndividualAn
 
 		logAttributes := submissionIDToLogValues(fullSubmissionID)
 		logAttributes = append([]any{err}, logAttributes...)
-		logAttributes = append(logAttributes, log.NewAttr("job-id", output.ID))
+		logAttributes = append(logAttributes, log.NewAttr("job-id", output.ID), tracing.NewLogAttr(options.Context))
 		log.Error("Failed to run individual analysis.", logAttributes...)
 	}
 
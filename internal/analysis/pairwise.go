@@ -17,6 +17,7 @@ import (
 	"github.com/edulinq/autograder/internal/jobmanager"
 	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/tracing"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -80,7 +81,11 @@ func PairwiseAnalysis(options AnalysisOptions) (map[model.PairwiseKey]*model.Pai
 		StoreFunc:               db.StorePairwiseAnalysis,
 		RemoveFunc:              db.RemovePairwiseAnalysis,
 		WorkFunc: func(key model.PairwiseKey) (*model.PairwiseAnalysis, error) {
-			return computeSinglePairwiseAnalysis(options, key, templateFileStore)
+			_, span := tracing.StartSpan(options.Context, "analysis.pairwise", tracing.NewAttr("pair", key.String()))
+			analysis, err := computeSinglePairwiseAnalysis(options, key, templateFileStore)
+			tracing.EndSpan(span, err)
+
+			return analysis, err
 		},
 		WorkItemKeyFunc: func(key model.PairwiseKey) string {
 			return fmt.Sprintf("analysis-pairwise-single-%s", key.String())
@@ -108,7 +113,7 @@ func PairwiseAnalysis(options AnalysisOptions) (map[model.PairwiseKey]*model.Pai
 	for pairwiseKey, err := range output.WorkErrors {
 		workErrors[pairwiseKey.String()] = err.Error()
 
-		log.Error("Failed to run pairwise analysis.", err, pairwiseKey, log.NewAttr("job-id", output.ID))
+		log.Error("Failed to run pairwise analysis.", err, pairwiseKey, log.NewAttr("job-id", output.ID), tracing.NewLogAttr(options.Context))
 	}
 
 	return output.ResultItems, len(output.RemainingItems), workErrors, nil
@@ -292,7 +297,7 @@ func computeFileSims(options AnalysisOptions, inputDirs [2]string, assignment *m
 		if err != nil {
 			// If at least one engine worked, don't error out, just log.
 			if len(similarities[relpath]) > 0 {
-				log.Warn("Not all engines successfully computed similarity. Some engines did complete successfully.", err)
+				log.Warn("Not all engines successfully computed similarity. Some engines did complete successfully.", err, tracing.NewLogAttr(options.Context))
 			} else {
 				return nil, nil, nil, err
 			}
@@ -1,13 +1,74 @@
 package core
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/model"
 )
 
+// Endpoints (or endpoint prefixes) that submit-only tokens can use.
+var submitOnlyEndpoints []string = []string{
+	"courses/assignments/submissions/submit",
+	"courses/assignments/submissions/queue/",
+}
+
+// Endpoint prefixes that manage credentials.
+// Scoped tokens can never use these endpoints (so they cannot be used to create less restricted credentials).
+var credentialEndpoints []string = []string{
+	"users/password/",
+	"users/tokens/",
+}
+
+// Endpoints that read-only tokens can use.
+// These must match an endpoint exactly, and should only be added to when an endpoint can never modify data
+// (e.g., analysis endpoints are not included since they store results).
+var readOnlyEndpoints map[string]bool = map[string]bool{
+	"courses/admin/extensions/list":                         true,
+	"courses/assignments/get":                               true,
+	"courses/assignments/git/get":                           true,
+	"courses/assignments/git/list":                          true,
+	"courses/assignments/groups/get":                        true,
+	"courses/assignments/groups/list":                       true,
+	"courses/assignments/images/fetch":                      true,
+	"courses/assignments/images/info":                       true,
+	"courses/assignments/list":                              true,
+	"courses/assignments/report":                            true,
+	"courses/assignments/submissions/fetch/course/attempts": true,
+	"courses/assignments/submissions/fetch/course/scores":   true,
+	"courses/assignments/submissions/fetch/user/attempt":    true,
+	"courses/assignments/submissions/fetch/user/attempts":   true,
+	"courses/assignments/submissions/fetch/user/history":    true,
+	"courses/assignments/submissions/fetch/user/peek":       true,
+	"courses/assignments/submissions/grade/manual/fetch":    true,
+	"courses/assignments/submissions/queue/position":        true,
+	"courses/assignments/submissions/queue/result":          true,
+	"courses/assignments/submissions/queue/status":          true,
+	"courses/get":         true,
+	"courses/list":        true,
+	"courses/stats/query": true,
+	"courses/users/get":   true,
+	"courses/users/list":  true,
+	"lms/user/get":        true,
+	"logs/query":          true,
+	"metadata/describe":   true,
+	"metadata/heartbeat":  true,
+	"stats/query":         true,
+	"system/stacks":       true,
+	"users/auth":          true,
+	"users/get":           true,
+	"users/list":          true,
+}
+
 // Return a user only in the case that the authentication is successful.
 // If any error is retuturned, then the request should end and the response sent based on the error.
 // This assumes basic validation has already been done on the request.
+// The matched password/token will be stored in this request.
+// Expired tokens and tokens whose scope does not include this request's endpoint will not authenticate.
+// Any of the user's expired tokens will be removed.
 func (this *APIRequestUserContext) Auth() (*model.ServerUser, *APIError) {
 	if this.UserEmail == model.RootUserEmail {
 		return nil, NewAuthError("-051", this, "Root is not allowed to authenticate.")
@@ -22,14 +83,96 @@ func (this *APIRequestUserContext) Auth() (*model.ServerUser, *APIError) {
 		return nil, NewAuthError("-013", this, "Unknown User")
 	}
 
-	auth, err := user.Auth(this.UserPass)
+	token, err := user.AuthToken(this.UserPass)
 	if err != nil {
 		return nil, NewInternalError("-037", this.Endpoint, "User auth failed.").Err(err)
 	}
 
-	if !auth {
+	removeExpiredTokens(user)
+
+	if token == nil {
 		return nil, NewAuthError("-014", this, "Bad Password")
 	}
 
+	if token.IsExpired() {
+		return nil, NewAuthError("-053", this, "Token Expired").Add("token-id", token.ID)
+	}
+
+	if !tokenScopeAllowsEndpoint(token.Scope, this.Endpoint) {
+		return nil, NewAuthError("-054", this, "Token scope does not allow this endpoint.").Add("token-id", token.ID)
+	}
+
+	this.Token = token
+
 	return user, nil
 }
+
+// Check that the token used to authenticate this request can be used with the given course.
+// An empty course ID indicates that the request is not for a specific course.
+func (this *APIRequestUserContext) checkTokenCourseScope(courseID string) *APIError {
+	if (this.Token == nil) || (this.Token.Scope == nil) || (len(this.Token.Scope.Courses) == 0) {
+		return nil
+	}
+
+	if courseID == "" {
+		return NewAuthError("-055", this, "Token is restricted to specific courses and cannot be used for non-course requests.").
+			Add("token-id", this.Token.ID)
+	}
+
+	if !slices.Contains(this.Token.Scope.Courses, courseID) {
+		return NewAuthError("-056", this, fmt.Sprintf("Token scope does not allow course '%s'.", courseID)).
+			Add("token-id", this.Token.ID)
+	}
+
+	return nil
+}
+
+func tokenScopeAllowsEndpoint(scope *model.TokenScope, endpoint string) bool {
+	if scope.IsEmpty() {
+		return true
+	}
+
+	endpoint = strings.TrimPrefix(strings.TrimPrefix(endpoint, CURRENT_PREFIX), "/")
+
+	if hasAnyPrefix(endpoint, credentialEndpoints) {
+		return false
+	}
+
+	if scope.ReadOnly && !readOnlyEndpoints[strings.TrimSuffix(endpoint, "/")] {
+		return false
+	}
+
+	if scope.SubmitOnly && !hasAnyPrefix(endpoint, submitOnlyEndpoints) {
+		return false
+	}
+
+	if (len(scope.Endpoints) > 0) && !hasAnyPrefix(endpoint, scope.Endpoints) {
+		return false
+	}
+
+	return true
+}
+
+func hasAnyPrefix(text string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Remove any expired tokens that a user has.
+// Failures are logged, but otherwise ignored (the tokens will be removed next time).
+func removeExpiredTokens(user *model.ServerUser) {
+	for _, token := range user.RemoveExpiredTokens() {
+		_, err := db.DeleteUserToken(user.Email, token.ID)
+		if err != nil {
+			log.Warn("Failed to remove expired token.", err, user, log.NewAttr("token-id", token.ID))
+			continue
+		}
+
+		log.Debug("Removed expired token.", user, log.NewAttr("token-id", token.ID))
+	}
+}
@@ -3,6 +3,9 @@ package core
 import (
 	"testing"
 
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -56,3 +59,170 @@ func TestAuth(test *testing.T) {
 		}
 	}
 }
+
+func TestAuthTokenScope(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	type userRequest struct {
+		APIRequestUserContext
+		MinServerRoleUser
+	}
+
+	type courseRequest struct {
+		APIRequestCourseUserContext
+		MinCourseRoleStudent
+	}
+
+	email := "course-student@test.edulinq.org"
+
+	user, err := db.GetServerUser(email)
+	if err != nil {
+		test.Fatalf("Failed to get user: '%v'.", err)
+	}
+
+	past := timestamp.Now() - 1
+	future := timestamp.Now() + 1000000
+
+	initialTokenCount := len(user.Tokens)
+
+	// Creating a token removes any expired tokens, so the expired token is created last.
+	tokenInfos := []struct {
+		name           string
+		expirationTime *timestamp.Timestamp
+		scope          *model.TokenScope
+	}{
+		{"not-expired", &future, nil},
+		{"read-only", nil, &model.TokenScope{ReadOnly: true}},
+		{"submit-only", nil, &model.TokenScope{SubmitOnly: true}},
+		{"course", nil, &model.TokenScope{Courses: []string{"course101"}}},
+		{"other", nil, &model.TokenScope{Courses: []string{"other"}}},
+		{"endpoints", nil, &model.TokenScope{Endpoints: []string{"courses/assignments/"}}},
+		{"expired", &past, nil},
+	}
+
+	passes := map[string]string{
+		"password": util.Sha256HexFromString("course-student"),
+	}
+
+	tokenIDs := make(map[string]string)
+
+	for _, info := range tokenInfos {
+		token, cleartext, err := user.CreateRandomTokenFull(info.name, model.TokenSourceUser, info.expirationTime, info.scope)
+		if err != nil {
+			test.Fatalf("Failed to create token '%s': '%v'.", info.name, err)
+		}
+
+		passes[info.name] = util.Sha256HexFromString(cleartext)
+		tokenIDs[info.name] = token.ID
+	}
+
+	err = db.UpsertUser(user)
+	if err != nil {
+		test.Fatalf("Failed to save user: '%v'.", err)
+	}
+
+	testCases := []struct {
+		pass          string
+		endpoint      string
+		courseRequest bool
+		locator       string
+	}{
+		// Any auth will remove expired tokens, so the expired token is checked first.
+		{"expired", "courses/get", true, "-053"},
+		{"expired", "courses/get", true, "-014"},
+		{"password", "users/tokens/create", false, ""},
+		{"not-expired", "users/tokens/create", false, ""},
+
+		{"read-only", "courses/get", true, ""},
+		{"read-only", "courses/assignments/submissions/fetch/user/peek", true, ""},
+		{"read-only", "courses/users/drop", true, "-054"},
+		{"read-only", "users/tokens/list", false, "-054"},
+		{"read-only", "courses/assignments/submissions/queue/status", true, ""},
+		{"read-only", "courses/assignments/submissions/analysis/individual", true, "-054"},
+		{"read-only", "courses/assignments/submissions/analysis/pairwise", true, "-054"},
+		{"read-only", "courses/assignments/submissions/fetch/user/peek/extra", true, "-054"},
+		{"read-only", "courses/assignments/git/poll", true, "-054"},
+
+		{"submit-only", "courses/assignments/submissions/submit", true, ""},
+		{"submit-only", "courses/assignments/submissions/queue/status", true, ""},
+		{"submit-only", "courses/assignments/submissions/fetch/user/peek", true, "-054"},
+
+		{"course", "courses/get", true, ""},
+		{"course", "users/get", false, "-055"},
+		{"other", "courses/get", true, "-056"},
+
+		{"endpoints", "courses/assignments/get", true, ""},
+		{"endpoints", "courses/get", true, "-054"},
+		{"endpoints", "users/tokens/create", false, "-054"},
+	}
+
+	for i, testCase := range testCases {
+		userContext := APIRequestUserContext{
+			UserEmail: email,
+			UserPass:  passes[testCase.pass],
+		}
+
+		var request any = &userRequest{APIRequestUserContext: userContext}
+		if testCase.courseRequest {
+			request = &courseRequest{
+				APIRequestCourseUserContext: APIRequestCourseUserContext{
+					APIRequestUserContext: userContext,
+					CourseID:              "course101",
+				},
+			}
+		}
+
+		apiErr := ValidateAPIRequest(nil, request, MakeFullAPIPath(testCase.endpoint))
+		if apiErr != nil {
+			if testCase.locator != apiErr.Locator {
+				test.Errorf("Case %d: Unexpected error. Expected: '%s', Actual: '%s' -- '%v'.", i, testCase.locator, apiErr.Locator, apiErr)
+			}
+
+			continue
+		}
+
+		if testCase.locator != "" {
+			test.Errorf("Case %d: Did not get an expected error: '%s'.", i, testCase.locator)
+			continue
+		}
+	}
+
+	// The expired token should have been removed.
+	user, err = db.GetServerUser(email)
+	if err != nil {
+		test.Fatalf("Failed to get user: '%v'.", err)
+	}
+
+	for _, token := range user.Tokens {
+		if token.ID == tokenIDs["expired"] {
+			test.Fatalf("Expired token was not removed.")
+		}
+	}
+
+	expectedTokenCount := initialTokenCount + len(tokenInfos) - 1
+	if len(user.Tokens) != expectedTokenCount {
+		test.Fatalf("Unexpected number of tokens. Expected: %d, Actual: %d.", expectedTokenCount, len(user.Tokens))
+	}
+}
+
+// Read-only endpoints must match exactly, so make sure each one is a real endpoint.
+func TestReadOnlyEndpointsExist(test *testing.T) {
+	path, err := util.GetAPIDescriptionFilepath()
+	if err != nil {
+		test.Fatalf("Unable to get the API description filepath: '%v'.", err)
+	}
+
+	var descriptions APIDescription
+	err = util.JSONFromFile(path, &descriptions)
+	if err != nil {
+		test.Fatalf("Failed to load api.json: '%v'.", err)
+	}
+
+	for endpoint := range readOnlyEndpoints {
+		_, exists := descriptions.Endpoints[endpoint]
+		if !exists {
+			test.Errorf("Read-only endpoint does not exist: '%s'.", endpoint)
+		}
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"net/http"
 	"reflect"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/tracing"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -319,3 +322,13 @@ func applyAPIRequestCourseUserContext(apiError *APIError, context *APIRequestCou
 func applyAPIRequestAssignmentContext(apiError *APIError, context *APIRequestAssignmentContext) {
 	apiError.AssignmentID = context.AssignmentID
 }
+
+// End a span, marking it as failed if there is an API error.
+// A nil *APIError cannot be passed directly as an error (it would not be a nil interface).
+func endSpanWithAPIError(span trace.Span, apiErr *APIError) {
+	if apiErr == nil {
+		tracing.EndSpan(span, nil)
+	} else {
+		tracing.EndSpan(span, apiErr)
+	}
+}
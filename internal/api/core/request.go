@@ -12,6 +12,7 @@ import (
 	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/model"
 	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/tracing"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -40,6 +41,10 @@ type APIRequest struct {
 	Sender    string              `json:"-"`
 	Timestamp timestamp.Timestamp `json:"-"`
 	Context   context.Context     `json:"-"`
+
+	// The ID of the trace this request is a part of (empty if this request is not being traced).
+	// Clients can choose their own trace IDs, so this is only used to match logs with traces.
+	TraceID string `json:"-"`
 }
 
 // Context for a request that has a user (pretty much the lowest level of request).
@@ -54,6 +59,9 @@ type APIRequestUserContext struct {
 	RootUserNonce string `json:"root-user-nonce,omitempty"`
 
 	ServerUser *model.ServerUser `json:"-"`
+
+	// The password/token that authenticated this request (nil for root requests).
+	Token *model.Token `json:"-"`
 }
 
 // Context for a request that has a course and user from that course.
@@ -78,7 +86,6 @@ type APIRequestAssignmentContext struct {
 }
 
 func (this *APIRequest) Validate(httpRequest *http.Request, request any, endpoint string) *APIError {
-	this.RequestID = util.UUID()
 	this.Endpoint = endpoint
 	this.Timestamp = timestamp.Now()
 
@@ -89,6 +96,12 @@ func (this *APIRequest) Validate(httpRequest *http.Request, request any, endpoin
 		this.Sender = httpRequest.RemoteAddr
 	}
 
+	this.RequestID = util.UUID()
+	this.TraceID = tracing.GetTraceID(this.Context)
+
+	// The context carries the span for the whole route.
+	tracing.SetAttributes(this.Context, tracing.NewAttr("request-id", this.RequestID))
+
 	return nil
 }
 
@@ -130,7 +143,9 @@ func (this *APIRequestUserContext) Validate(httpRequest *http.Request, request a
 			return NewBadRequestError("-017", this, "No user password specified.")
 		}
 
+		_, span := tracing.StartSpan(this.Context, "api.auth", tracing.NewAttr("user", this.UserEmail))
 		this.ServerUser, apiErr = this.Auth()
+		endSpanWithAPIError(span, apiErr)
 		if apiErr != nil {
 			return apiErr
 		}
@@ -169,6 +184,11 @@ func (this *APIRequestCourseUserContext) Validate(httpRequest *http.Request, req
 
 	this.CourseID = id
 
+	apiErr = this.checkTokenCourseScope(this.CourseID)
+	if apiErr != nil {
+		return apiErr
+	}
+
 	this.Course, err = db.GetCourse(this.CourseID)
 	if err != nil {
 		return NewInternalError("-032", this, "Unable to get course").Err(err)
@@ -238,13 +258,19 @@ func getLogAttributesFromAPIRequest(apiRequest ValidAPIRequest) []any {
 	if err != nil {
 		log.Error(fmt.Sprintf("Failed to convert APIRequest to JSON: '%#v'.", apiRequest), err)
 
-		return []any{
+		attributes := []any{
 			log.NewAttr("id", typedAPIRequest.RequestID),
 			log.NewAttr("endpoint", typedAPIRequest.Endpoint),
 			log.NewAttr("sender", typedAPIRequest.Sender),
 			log.NewAttr("timestamp", typedAPIRequest.Timestamp),
 			log.NewAttr("conversion-error", err.Error()),
 		}
+
+		if typedAPIRequest.TraceID != "" {
+			attributes = append(attributes, log.NewAttr("trace-id", typedAPIRequest.TraceID))
+		}
+
+		return attributes
 	}
 
 	// Clean up the data.
@@ -254,6 +280,10 @@ func getLogAttributesFromAPIRequest(apiRequest ValidAPIRequest) []any {
 	generalData["endpoint"] = typedAPIRequest.Endpoint
 	generalData["sender"] = typedAPIRequest.Sender
 
+	if typedAPIRequest.TraceID != "" {
+		generalData["trace-id"] = typedAPIRequest.TraceID
+	}
+
 	// Remove passwords.
 	delete(generalData, "user-pass")
 	delete(generalData, "new-pass")
@@ -374,6 +404,12 @@ func validateRequestStruct(httpRequest *http.Request, rawRequest any, endpoint s
 				return false, apiErr
 			}
 
+			// This is not a course request, so course-restricted tokens are not allowed.
+			apiErr = userRequest.checkTokenCourseScope("")
+			if apiErr != nil {
+				return false, apiErr
+			}
+
 			fieldValue.Set(reflect.ValueOf(userRequest))
 		} else if fieldValue.Type() == reflect.TypeOf((*APIRequestCourseUserContext)(nil)).Elem() {
 			// APIRequestCourseUserContext
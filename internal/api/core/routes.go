@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/edulinq/autograder/internal/util"
@@ -22,6 +23,16 @@ func MakeFullAPIPath(suffix string) string {
 	return CURRENT_PREFIX + "/" + suffix
 }
 
+// Get the absolute URL for an endpoint (using the current prefix) on the host that |request| was made to.
+func MakeFullAPIURL(request *http.Request, suffix string) string {
+	scheme := "http"
+	if request.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + request.Host + MakeFullAPIPath(suffix)
+}
+
 func SetAPIRoutes(routes *[]Route) {
 	apiRoutes = routes
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/stats"
 	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/tracing"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -47,6 +48,10 @@ func GetRouteServer(routes *[]Route) http.HandlerFunc {
 func ServeRoutes(routes *[]Route, response http.ResponseWriter, request *http.Request) {
 	log.Trace("Raw Request", log.NewAttr("method", request.Method), log.NewAttr("url", request.URL.Path))
 
+	request, span := tracing.StartHTTPSpan(request, "api.route",
+		tracing.NewAttr("http.method", request.Method), tracing.NewAttr("url.path", request.URL.Path))
+	defer span.End()
+
 	if routes == nil {
 		http.NotFound(response, request)
 	}
@@ -71,6 +76,7 @@ func ServeRoutes(routes *[]Route, response http.ResponseWriter, request *http.Re
 
 		err := route.Handle(response, request)
 		if err != nil {
+			tracing.SetSpanError(span, err)
 			log.Error("Handler had an error.", err, log.NewAttr("path", request.URL.Path))
 			http.Error(response, "Server Error", http.StatusInternalServerError)
 		}
@@ -113,7 +119,9 @@ func handleAPIEndpoint(response http.ResponseWriter, request *http.Request, apiH
 	log.Debug("Incoming API Request", getLogAttributesFromAPIRequest(apiRequest)...)
 
 	// Execute the handler.
+	_, span := tracing.StartSpan(request.Context(), "api.handler")
 	apiResponse, apiErr := callHandler(apiHandler, apiRequest)
+	endSpanWithAPIError(span, apiErr)
 
 	return sendAPIResponse(apiRequest, response, apiResponse, apiErr, false, startTime)
 }
@@ -223,7 +231,9 @@ func createAPIRequest(request *http.Request, apiHandler ValidAPIHandler) (ValidA
 	}
 
 	// Validate the request.
+	_, span := tracing.StartSpan(request.Context(), "api.validate")
 	apiErr = ValidateAPIRequest(request, apiRequest, endpoint)
+	endSpanWithAPIError(span, apiErr)
 	if apiErr != nil {
 		return nil, apiErr
 	}
@@ -31,6 +31,10 @@ func stopTestServer() {
 	}
 }
 
+func GetTestServerURL() string {
+	return serverURL
+}
+
 func SetTestServerURL(url string) string {
 	oldURL := serverURL
 	serverURL = url
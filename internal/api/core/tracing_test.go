@@ -0,0 +1,101 @@
+package core
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/tracing"
+)
+
+func TestAPIRequestTracing(test *testing.T) {
+	endpoint := `/test/api/tracing`
+
+	var requestTraceID string
+	var logAttributes []any
+
+	handler := func(request *BaseTestRequest) (*any, *APIError) {
+		_, span := tracing.StartSpan(request.Context, "test.handler")
+		span.End()
+
+		requestTraceID = request.TraceID
+		logAttributes = getLogAttributesFromAPIRequest(request)
+
+		return nil, nil
+	}
+
+	routes = append(routes, MustNewAPIRoute(endpoint, handler))
+
+	exporter := tracetest.NewInMemoryExporter()
+	defer tracing.SetExporterForTesting(exporter)()
+
+	response := SendTestAPIRequest(test, endpoint, nil)
+	if !response.Success {
+		test.Fatalf("Response is not a success: '%s'.", response.String())
+	}
+
+	expectedNames := []string{"api.auth", "api.validate", "test.handler", "api.handler", "api.route"}
+
+	spans := exporter.GetSpans()
+	if len(spans) != len(expectedNames) {
+		test.Fatalf("Unexpected number of spans. Expected: %d, Actual: %d.", len(expectedNames), len(spans))
+	}
+
+	for i, span := range spans {
+		if span.Name != expectedNames[i] {
+			test.Errorf("Span %d: Unexpected name. Expected: '%s', Actual: '%s'.", i, expectedNames[i], span.Name)
+		}
+
+		if span.SpanContext.TraceID().String() != requestTraceID {
+			test.Errorf("Span %d: Trace ID does not match the request's trace ID. Expected: '%s', Actual: '%s'.", i, requestTraceID, span.SpanContext.TraceID().String())
+		}
+	}
+
+	// Trace IDs can come from clients, so request IDs are always generated by the server.
+	if (len(response.ID) != 36) || (response.ID == requestTraceID) {
+		test.Fatalf("Unexpected request ID: '%s'.", response.ID)
+	}
+
+	// The request ID is attached to the route's span.
+	foundRequestID := false
+	for _, attribute := range spans[len(spans)-1].Attributes {
+		if (string(attribute.Key) == "request-id") && (attribute.Value.AsString() == response.ID) {
+			foundRequestID = true
+		}
+	}
+
+	if !foundRequestID {
+		test.Fatalf("Could not find request ID ('%s') in route span attributes: '%v'.", response.ID, spans[len(spans)-1].Attributes)
+	}
+
+	// The trace ID is logged separately.
+	foundTraceID := false
+	for _, attribute := range logAttributes {
+		logAttribute, ok := attribute.(*log.Attr)
+		if ok && (logAttribute.Name == "trace-id") && (logAttribute.Value == requestTraceID) {
+			foundTraceID = true
+		}
+	}
+
+	if !foundTraceID {
+		test.Fatalf("Could not find trace ID ('%s') in log attributes: '%v'.", requestTraceID, logAttributes)
+	}
+}
+
+func TestAPIRequestTracingDisabled(test *testing.T) {
+	endpoint := `/test/api/tracing/disabled`
+
+	handler := func(request *BaseTestRequest) (*any, *APIError) {
+		return nil, nil
+	}
+
+	routes = append(routes, MustNewAPIRoute(endpoint, handler))
+
+	response := SendTestAPIRequest(test, endpoint, nil)
+
+	// Without tracing, request IDs are UUIDs.
+	if len(response.ID) != 36 {
+		test.Fatalf("Unexpected request ID: '%s'.", response.ID)
+	}
+}
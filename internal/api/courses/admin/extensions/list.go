@@ -0,0 +1,26 @@
+package extensions
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+)
+
+type ListRequest struct {
+	core.APIRequestCourseUserContext
+	core.MinCourseRoleAdmin
+}
+
+type ListResponse struct {
+	Extensions []*model.Extension `json:"extensions"`
+}
+
+// List all the extensions (course-wide and assignment-specific) in the course.
+func HandleList(request *ListRequest) (*ListResponse, *core.APIError) {
+	extensions, err := db.GetExtensions(request.Course)
+	if err != nil {
+		return nil, core.NewInternalError("-654", request, "Failed to get extensions.").Err(err)
+	}
+
+	return &ListResponse{extensions}, nil
+}
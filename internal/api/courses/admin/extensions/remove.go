@@ -0,0 +1,42 @@
+package extensions
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+)
+
+type RemoveRequest struct {
+	core.APIRequestCourseUserContext
+	core.MinCourseRoleAdmin
+
+	TargetUser core.TargetCourseUser `json:"target-email" required:""`
+
+	// Leave empty for a course-wide extension.
+	AssignmentID string `json:"assignment-id"`
+}
+
+type RemoveResponse struct {
+	FoundUser      bool `json:"found-user"`
+	FoundExtension bool `json:"found-extension"`
+}
+
+// Remove a user's extension.
+func HandleRemove(request *RemoveRequest) (*RemoveResponse, *core.APIError) {
+	response := RemoveResponse{}
+
+	if !request.TargetUser.Found {
+		return &response, nil
+	}
+
+	response.FoundUser = true
+
+	removed, err := db.RemoveExtension(request.Course, request.AssignmentID, request.TargetUser.Email)
+	if err != nil {
+		return nil, core.NewInternalError("-658", request, "Failed to remove extension.").
+			Err(err).Add("target-user", request.TargetUser.Email).Add("assignment-id", request.AssignmentID)
+	}
+
+	response.FoundExtension = removed
+
+	return &response, nil
+}
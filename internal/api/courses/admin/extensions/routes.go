@@ -0,0 +1,17 @@
+package extensions
+
+// All the API endpoints handled by this package.
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+)
+
+var routes []core.Route = []core.Route{
+	core.MustNewAPIRoute(`courses/admin/extensions/list`, HandleList),
+	core.MustNewAPIRoute(`courses/admin/extensions/remove`, HandleRemove),
+	core.MustNewAPIRoute(`courses/admin/extensions/upsert`, HandleUpsert),
+}
+
+func GetRoutes() *[]core.Route {
+	return &routes
+}
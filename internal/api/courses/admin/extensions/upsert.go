@@ -0,0 +1,74 @@
+package extensions
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+type UpsertRequest struct {
+	core.APIRequestCourseUserContext
+	core.MinCourseRoleAdmin
+
+	TargetUser core.TargetCourseUser `json:"target-email" required:""`
+
+	// Leave empty for a course-wide extension.
+	AssignmentID string `json:"assignment-id"`
+
+	DueDate           *timestamp.Timestamp `json:"due-date"`
+	ExtraTime         *util.DurationSpec   `json:"extra-time"`
+	ExtraAttempts     int                  `json:"extra-attempts"`
+	RuntimeMultiplier float64              `json:"runtime-multiplier"`
+	Notes             string               `json:"notes"`
+}
+
+type UpsertResponse struct {
+	FoundUser bool             `json:"found-user"`
+	Extension *model.Extension `json:"extension"`
+}
+
+// Give a user an extension (replacing any existing extension for the same user and assignment).
+func HandleUpsert(request *UpsertRequest) (*UpsertResponse, *core.APIError) {
+	response := UpsertResponse{}
+
+	if !request.TargetUser.Found {
+		return &response, nil
+	}
+
+	response.FoundUser = true
+
+	if (request.AssignmentID != "") && (request.Course.GetAssignment(request.AssignmentID) == nil) {
+		return nil, core.NewBadRequestError("-655", request, "Unknown assignment.").
+			Add("assignment-id", request.AssignmentID)
+	}
+
+	extension := &model.Extension{
+		CourseID:          request.Course.GetID(),
+		AssignmentID:      request.AssignmentID,
+		User:              request.TargetUser.Email,
+		DueDate:           request.DueDate,
+		ExtraTime:         request.ExtraTime,
+		ExtraAttempts:     request.ExtraAttempts,
+		RuntimeMultiplier: request.RuntimeMultiplier,
+		Notes:             request.Notes,
+		UpdateTime:        timestamp.Now(),
+	}
+
+	err := extension.Validate()
+	if err != nil {
+		return nil, core.NewBadRequestError("-656", request, "Invalid extension.").
+			Err(err).Add("target-user", request.TargetUser.Email).Add("assignment-id", request.AssignmentID)
+	}
+
+	err = db.SaveExtension(extension)
+	if err != nil {
+		return nil, core.NewInternalError("-657", request, "Failed to save extension.").
+			Err(err).Add("target-user", request.TargetUser.Email).Add("assignment-id", request.AssignmentID)
+	}
+
+	response.Extension = extension
+
+	return &response, nil
+}
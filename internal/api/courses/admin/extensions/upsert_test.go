@@ -0,0 +1,130 @@
+package extensions
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestUpsertListRemove(test *testing.T) {
+	// Leave the course in a good state after the test.
+	defer db.ResetForTesting()
+
+	testCases := []struct {
+		email         string
+		targetEmail   string
+		assignmentID  string
+		dueDate       any
+		extraAttempts int
+		foundUser     bool
+		locator       string
+	}{
+		// Valid.
+		{"course-admin", "course-student@test.edulinq.org", "", nil, 1, true, ""},
+		{"course-admin", "course-student@test.edulinq.org", "hw0", 1000, 2, true, ""},
+		{"course-owner", "course-student@test.edulinq.org", "hw0", nil, 0, true, ""},
+		{"server-admin", "course-student@test.edulinq.org", "", nil, 1, true, ""},
+
+		// Missing user.
+		{"course-admin", "ZZZ@test.edulinq.org", "", nil, 1, false, ""},
+
+		// Bad requests.
+		{"course-admin", "course-student@test.edulinq.org", "ZZZ", nil, 1, true, "-655"},
+		{"course-admin", "course-student@test.edulinq.org", "", 1000, 1, true, "-656"},
+		{"course-admin", "course-student@test.edulinq.org", "hw0", nil, -1, true, "-656"},
+
+		// Roles below admin.
+		{"course-grader", "course-student@test.edulinq.org", "", nil, 1, false, "-020"},
+		{"course-student", "course-student@test.edulinq.org", "", nil, 1, false, "-020"},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+
+		fields := map[string]any{
+			"target-email":   testCase.targetEmail,
+			"assignment-id":  testCase.assignmentID,
+			"due-date":       testCase.dueDate,
+			"extra-attempts": testCase.extraAttempts,
+			"notes":          "Some notes.",
+		}
+
+		response := core.SendTestAPIRequestFull(test, `courses/admin/extensions/upsert`, fields, nil, testCase.email)
+		if !response.Success {
+			if testCase.locator != response.Locator {
+				test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator)
+			}
+
+			continue
+		}
+
+		if testCase.locator != "" {
+			test.Errorf("Case %d: Did not get an expected error '%s'.", i, testCase.locator)
+			continue
+		}
+
+		var upsertResponse UpsertResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &upsertResponse)
+
+		if testCase.foundUser != upsertResponse.FoundUser {
+			test.Errorf("Case %d: Found user does not match. Expected: '%v', Actual: '%v'.", i, testCase.foundUser, upsertResponse.FoundUser)
+			continue
+		}
+
+		if !testCase.foundUser {
+			continue
+		}
+
+		extension := upsertResponse.Extension
+		if (extension == nil) || (extension.User != testCase.targetEmail) || (extension.AssignmentID != testCase.assignmentID) ||
+			(extension.ExtraAttempts != testCase.extraAttempts) {
+			test.Errorf("Case %d: Unexpected extension: '%s'.", i, util.MustToJSONIndent(extension))
+			continue
+		}
+
+		// List the extensions.
+		response = core.SendTestAPIRequestFull(test, `courses/admin/extensions/list`, nil, nil, testCase.email)
+		if !response.Success {
+			test.Errorf("Case %d: List response is not a success when it should be: '%v'.", i, response)
+			continue
+		}
+
+		var listResponse ListResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &listResponse)
+
+		expected := []*model.Extension{extension}
+		if !reflect.DeepEqual(expected, listResponse.Extensions) {
+			test.Errorf("Case %d: Unexpected extensions. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(expected), util.MustToJSONIndent(listResponse.Extensions))
+			continue
+		}
+
+		// Remove the extension (twice).
+		for _, expectedFound := range []bool{true, false} {
+			fields = map[string]any{
+				"target-email":  testCase.targetEmail,
+				"assignment-id": testCase.assignmentID,
+			}
+
+			response = core.SendTestAPIRequestFull(test, `courses/admin/extensions/remove`, fields, nil, testCase.email)
+			if !response.Success {
+				test.Errorf("Case %d: Remove response is not a success when it should be: '%v'.", i, response)
+				break
+			}
+
+			var removeResponse RemoveResponse
+			util.MustJSONFromString(util.MustToJSON(response.Content), &removeResponse)
+
+			expectedResponse := RemoveResponse{FoundUser: true, FoundExtension: expectedFound}
+			if expectedResponse != removeResponse {
+				test.Errorf("Case %d: Unexpected remove response. Expected: '%s', Actual: '%s'.", i,
+					util.MustToJSON(expectedResponse), util.MustToJSON(removeResponse))
+				break
+			}
+		}
+	}
+}
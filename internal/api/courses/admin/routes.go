@@ -4,13 +4,19 @@ package admin
 
 import (
 	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/api/courses/admin/extensions"
 )
 
-var routes []core.Route = []core.Route{
+var baseRoutes []core.Route = []core.Route{
 	core.MustNewAPIRoute(`courses/admin/email`, HandleEmail),
 	core.MustNewAPIRoute(`courses/admin/update`, HandleUpdate),
 }
 
 func GetRoutes() *[]core.Route {
+	routes := make([]core.Route, 0)
+
+	routes = append(routes, baseRoutes...)
+	routes = append(routes, *(extensions.GetRoutes())...)
+
 	return &routes
 }
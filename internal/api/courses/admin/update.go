@@ -20,6 +20,7 @@ type UpdateResponse struct {
 func HandleUpdate(request *UpdateRequest) (*UpdateResponse, *core.APIError) {
 	options := request.CourseUpsertOptions
 	options.ContextUser = request.ServerUser
+	options.Context = request.Context
 
 	result, err := courses.UpdateFromLocalSource(request.Course, options)
 	if err != nil {
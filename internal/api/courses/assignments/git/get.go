@@ -0,0 +1,51 @@
+package git
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+)
+
+type GetRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleStudent
+
+	TargetUser core.TargetCourseUserSelfOrGrader `json:"target-email"`
+}
+
+type GetResponse struct {
+	FoundUser bool           `json:"found-user"`
+	FoundRepo bool           `json:"found-repo"`
+	Repo      *model.GitRepo `json:"repo"`
+
+	// The template repo for the assignment (if any).
+	TemplateURL string `json:"template-url"`
+}
+
+// Get a user's registered git repo for an assignment.
+func HandleGet(request *GetRequest) (*GetResponse, *core.APIError) {
+	if request.Assignment.GitSubmission == nil {
+		return nil, core.NewBadRequestError("-678", request, "Assignment does not accept git submissions.")
+	}
+
+	response := GetResponse{
+		TemplateURL: request.Assignment.GitSubmission.TemplateURL,
+	}
+
+	if !request.TargetUser.Found {
+		return &response, nil
+	}
+
+	response.FoundUser = true
+
+	repo, err := db.GetGitRepo(request.Assignment, request.TargetUser.Email)
+	if err != nil {
+		return nil, core.NewInternalError("-679", request, "Failed to get git repo.").
+			Err(err).Add("target-user", request.TargetUser.Email)
+	}
+
+	response.FoundRepo = (repo != nil)
+	response.Repo = repo
+
+	return &response, nil
+}
@@ -0,0 +1,255 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestRegisterGetListRemove(test *testing.T) {
+	defer db.ResetForTesting()
+
+	testCases := []struct {
+		email       string
+		targetEmail string
+		url         string
+		locator     string
+		foundUser   bool
+	}{
+		{"course-student", "", "https://github.com/test/hw0-course-student.git", "", true},
+		{"course-student", "course-student@test.edulinq.org", "https://GitHub.com/Test/HW0-Course-Student", "", true},
+		{"course-admin", "course-student@test.edulinq.org", "https://github.com/test/student", "", true},
+		{"course-admin", "course-student@test.edulinq.org", "https://gitlab.edulinq.org/test/student.git", "", true},
+		{"course-admin", "ZZZ@test.edulinq.org", "https://github.com/test/student", "", false},
+
+		// Server admins may register any kind of repo.
+		{"server-admin", "course-student@test.edulinq.org", "git@github.com:test/student.git", "", true},
+		{"server-admin", "course-student@test.edulinq.org", "file:///tmp/student", "", true},
+
+		// Bad URLs.
+		{"course-student", "", "repo", "-675", false},
+		{"course-student", "", "", "-038", false},
+
+		// Disallowed URLs.
+		{"course-student", "", "file:///tmp/student", "-685", false},
+		{"course-student", "", "git@github.com:test/student.git", "-685", false},
+		{"course-student", "", "ssh://git@github.com/test/student.git", "-685", false},
+		{"course-student", "", "git://github.com/test/student.git", "-685", false},
+		{"course-student", "", "http://github.com/test/student.git", "-685", false},
+		{"course-student", "", "https://evil.edulinq.org/test/student.git", "-685", false},
+		{"course-student", "", "https://github.com:8443/test/student.git", "-685", false},
+		{"course-student", "", "https://gitlab.edulinq.org/test/hw0-course-student.git", "-685", false},
+		{"course-student", "", "https://github.com/other/hw0-course-student.git", "-685", false},
+		{"course-student", "", "https://github.com/test/student.git", "-685", false},
+		{"course-student", "", "https://github.com/test/hw0-course-other.git", "-685", false},
+		{"course-student", "", "https://github.com/test/hw0-course-student/extra.git", "-685", false},
+		{"course-admin", "course-student@test.edulinq.org", "file:///tmp/student", "-685", false},
+		{"course-admin", "course-student@test.edulinq.org", "git@github.com:test/student.git", "-685", false},
+
+		// Students cannot register repos for others.
+		{"course-student", "course-other@test.edulinq.org", "https://github.com/test/hw0-course-other", "-033", false},
+
+		// Repos cannot be registered to multiple users.
+		{"course-student", "", "https://github.com/test/hw0-course-grader.git", "-685", false},
+		{"course-admin", "course-student@test.edulinq.org", "https://github.com/test/hw0-course-grader.git", "-697", false},
+		{"course-admin", "course-student@test.edulinq.org", "git@github.com:test/hw0-course-grader", "-685", false},
+		{"server-admin", "course-student@test.edulinq.org", "git@github.com:test/hw0-course-grader", "-697", false},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+		assignment := mustEnableGitSubmission(test)
+
+		err := db.SaveGitRepo(assignment, &model.GitRepo{
+			CourseID:     assignment.GetCourse().GetID(),
+			AssignmentID: assignment.GetID(),
+			User:         "course-grader@test.edulinq.org",
+			URL:          "https://github.com/test/hw0-course-grader",
+		})
+		if err != nil {
+			test.Fatalf("Case %d: Failed to save git repo: '%v'.", i, err)
+		}
+
+		fields := map[string]any{
+			"target-email": testCase.targetEmail,
+			"url":          testCase.url,
+		}
+
+		response := core.SendTestAPIRequestFull(test, `courses/assignments/git/register`, fields, nil, testCase.email)
+		if !response.Success {
+			if testCase.locator != response.Locator {
+				test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator)
+			}
+
+			continue
+		}
+
+		if testCase.locator != "" {
+			test.Errorf("Case %d: Did not get an expected error '%s'.", i, testCase.locator)
+			continue
+		}
+
+		var registerResponse RegisterResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &registerResponse)
+
+		if testCase.foundUser != registerResponse.FoundUser {
+			test.Errorf("Case %d: Unexpected found user. Expected: '%v', Actual: '%v'.", i, testCase.foundUser, registerResponse.FoundUser)
+			continue
+		}
+
+		if !testCase.foundUser {
+			continue
+		}
+
+		response = core.SendTestAPIRequestFull(test, `courses/assignments/git/get`, map[string]any{"target-email": "course-student@test.edulinq.org"}, nil, "course-grader")
+		if !response.Success {
+			test.Errorf("Case %d: Get response is not a success when it should be: '%v'.", i, response)
+			continue
+		}
+
+		var getResponse GetResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &getResponse)
+
+		if !getResponse.FoundRepo {
+			test.Errorf("Case %d: Did not find registered repo.", i)
+			continue
+		}
+
+		if !reflect.DeepEqual(registerResponse.Repo, getResponse.Repo) {
+			test.Errorf("Case %d: Unexpected repo. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(registerResponse.Repo), util.MustToJSONIndent(getResponse.Repo))
+			continue
+		}
+
+		response = core.SendTestAPIRequestFull(test, `courses/assignments/git/list`, nil, nil, "course-grader")
+		if !response.Success {
+			test.Errorf("Case %d: List response is not a success when it should be: '%v'.", i, response)
+			continue
+		}
+
+		var listResponse ListResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &listResponse)
+
+		actual := make([]*model.GitRepo, 0, len(listResponse.Repos))
+		for _, repo := range listResponse.Repos {
+			if repo.User == "course-student@test.edulinq.org" {
+				actual = append(actual, repo)
+			}
+		}
+
+		expected := []*model.GitRepo{registerResponse.Repo}
+		if !reflect.DeepEqual(expected, actual) {
+			test.Errorf("Case %d: Unexpected repos. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(expected), util.MustToJSONIndent(actual))
+			continue
+		}
+
+		for _, expectedFound := range []bool{true, false} {
+			response = core.SendTestAPIRequestFull(test, `courses/assignments/git/remove`, map[string]any{"target-email": testCase.targetEmail}, nil, testCase.email)
+			if !response.Success {
+				test.Errorf("Case %d: Remove response is not a success when it should be: '%v'.", i, response)
+				break
+			}
+
+			var removeResponse RemoveResponse
+			util.MustJSONFromString(util.MustToJSON(response.Content), &removeResponse)
+
+			if expectedFound != removeResponse.FoundRepo {
+				test.Errorf("Case %d: Unexpected remove result. Expected: '%v', Actual: '%v'.", i, expectedFound, removeResponse.FoundRepo)
+				break
+			}
+		}
+	}
+}
+
+func TestRegisterKeepsLastCommit(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	assignment := mustEnableGitSubmission(test)
+
+	err := db.SaveGitRepo(assignment, &model.GitRepo{
+		CourseID:     assignment.GetCourse().GetID(),
+		AssignmentID: assignment.GetID(),
+		User:         "course-student@test.edulinq.org",
+		URL:          "https://github.com/test/hw0-course-student.git",
+		LastCommit:   "abc123",
+	})
+	if err != nil {
+		test.Fatalf("Failed to save git repo: '%v'.", err)
+	}
+
+	testCases := []struct {
+		url                string
+		expectedLastCommit string
+	}{
+		{"https://github.com/test/hw0-course-student", "abc123"},
+		{"https://github.com/test/other.git", ""},
+	}
+
+	for i, testCase := range testCases {
+		fields := map[string]any{
+			"target-email": "course-student@test.edulinq.org",
+			"url":          testCase.url,
+		}
+
+		response := core.SendTestAPIRequestFull(test, `courses/assignments/git/register`, fields, nil, "course-admin")
+		if !response.Success {
+			test.Errorf("Case %d: Register response is not a success when it should be: '%v'.", i, response)
+			continue
+		}
+
+		var registerResponse RegisterResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &registerResponse)
+
+		if testCase.expectedLastCommit != registerResponse.Repo.LastCommit {
+			test.Errorf("Case %d: Unexpected last commit. Expected: '%s', Actual: '%s'.", i, testCase.expectedLastCommit, registerResponse.Repo.LastCommit)
+			continue
+		}
+	}
+}
+
+func TestNoGitSubmission(test *testing.T) {
+	testCases := []struct {
+		endpoint string
+		locator  string
+	}{
+		{`courses/assignments/git/get`, "-678"},
+		{`courses/assignments/git/register`, "-674"},
+		{`courses/assignments/git/poll`, "-682"},
+	}
+
+	for i, testCase := range testCases {
+		response := core.SendTestAPIRequestFull(test, testCase.endpoint, map[string]any{"url": "https://github.com/test/student"}, nil, "course-admin")
+		if response.Success {
+			test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response)
+			continue
+		}
+
+		if testCase.locator != response.Locator {
+			test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator)
+			continue
+		}
+	}
+}
+
+func mustEnableGitSubmission(test *testing.T) *model.Assignment {
+	assignment := db.MustGetTestAssignment()
+	assignment.GitSubmission = &model.GitSubmissionOptions{
+		TemplateURL:     "https://github.com/test/template",
+		RepoNamePattern: "hw0-{user}",
+		AllowedHosts:    []string{"gitlab.edulinq.org"},
+		WebhookSecret:   "secret",
+	}
+
+	err := db.SaveAssignment(assignment)
+	if err != nil {
+		test.Fatalf("Failed to save assignment: '%v'.", err)
+	}
+
+	return assignment
+}
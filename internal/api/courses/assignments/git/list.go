@@ -0,0 +1,26 @@
+package git
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+)
+
+type ListRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleGrader
+}
+
+type ListResponse struct {
+	Repos []*model.GitRepo `json:"repos"`
+}
+
+// List all the registered git repos for an assignment.
+func HandleList(request *ListRequest) (*ListResponse, *core.APIError) {
+	repos, err := db.GetGitRepos(request.Assignment)
+	if err != nil {
+		return nil, core.NewInternalError("-680", request, "Failed to get git repos.").Err(err)
+	}
+
+	return &ListResponse{repos}, nil
+}
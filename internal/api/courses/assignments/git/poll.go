@@ -0,0 +1,30 @@
+package git
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/procedures/gitsubmit"
+)
+
+type PollRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleAdmin
+}
+
+type PollResponse struct {
+	// The number of new commits that were graded.
+	GradedCount int `json:"graded-count"`
+}
+
+// Check all the registered git repos for an assignment and grade any new commits.
+func HandlePoll(request *PollRequest) (*PollResponse, *core.APIError) {
+	if request.Assignment.GitSubmission == nil {
+		return nil, core.NewBadRequestError("-682", request, "Assignment does not accept git submissions.")
+	}
+
+	count, err := gitsubmit.PollAssignment(request.Context, request.Assignment)
+	if err != nil {
+		return nil, core.NewInternalError("-683", request, "Failed to poll git repos.").Err(err)
+	}
+
+	return &PollResponse{count}, nil
+}
@@ -0,0 +1,104 @@
+package git
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+type RegisterRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleStudent
+
+	TargetUser core.TargetCourseUserSelfOrAdmin `json:"target-email"`
+
+	URL core.NonEmptyString `json:"url" required:""`
+}
+
+type RegisterResponse struct {
+	FoundUser bool           `json:"found-user"`
+	Repo      *model.GitRepo `json:"repo"`
+}
+
+// Register (or replace) a user's git repo for an assignment.
+// New commits to the repo will be graded as submissions from the user.
+// Students may only register their own repo next to the template repo (see GitSubmissionOptions.CheckStudentRepoURL()).
+// Graders and above may register any https repo on an allowed host
+// (the template repo's host or one of the assignment's allowed hosts).
+// Only server admins may register arbitrary (e.g., local or ssh) repos.
+// A repo may only be registered to one user.
+func HandleRegister(request *RegisterRequest) (*RegisterResponse, *core.APIError) {
+	if request.Assignment.GitSubmission == nil {
+		return nil, core.NewBadRequestError("-674", request, "Assignment does not accept git submissions.")
+	}
+
+	response := RegisterResponse{}
+
+	if !request.TargetUser.Found {
+		return &response, nil
+	}
+
+	response.FoundUser = true
+
+	repo := &model.GitRepo{
+		CourseID:     request.Course.GetID(),
+		AssignmentID: request.Assignment.GetID(),
+		User:         request.TargetUser.Email,
+		URL:          string(request.URL),
+		CreatedBy:    request.User.Email,
+		UpdateTime:   timestamp.Now(),
+	}
+
+	err := repo.Validate()
+	if err != nil {
+		return nil, core.NewBadRequestError("-675", request, "Invalid git repo.").Err(err)
+	}
+
+	if request.ServerUser.Role < model.ServerRoleAdmin {
+		if request.User.Role < model.CourseRoleGrader {
+			err = request.Assignment.GitSubmission.CheckStudentRepoURL(repo.URL, repo.User)
+		} else {
+			err = request.Assignment.GitSubmission.CheckRepoURL(repo.URL)
+		}
+
+		if err != nil {
+			return nil, core.NewBadRequestError("-685", request, "Git repo URL is not allowed.").Err(err).Add("url", repo.URL)
+		}
+	}
+
+	otherRepos, err := db.GetGitReposByURL(request.Assignment, repo.URL)
+	if err != nil {
+		return nil, core.NewInternalError("-696", request, "Failed to get git repos with the same URL.").
+			Err(err).Add("url", repo.URL)
+	}
+
+	for _, otherRepo := range otherRepos {
+		if otherRepo.User != repo.User {
+			return nil, core.NewBadRequestError("-697", request, "Git repo is already registered to another user.").
+				Add("url", repo.URL).Add("other-user", otherRepo.User)
+		}
+	}
+
+	// Keep track of what has already been graded if the same repo is registered again.
+	oldRepo, err := db.GetGitRepo(request.Assignment, repo.User)
+	if err != nil {
+		return nil, core.NewInternalError("-676", request, "Failed to get existing git repo.").
+			Err(err).Add("target-user", repo.User)
+	}
+
+	if (oldRepo != nil) && oldRepo.MatchesURL(repo.URL) {
+		repo.LastCommit = oldRepo.LastCommit
+		repo.LastCommitTime = oldRepo.LastCommitTime
+	}
+
+	err = db.SaveGitRepo(request.Assignment, repo)
+	if err != nil {
+		return nil, core.NewInternalError("-677", request, "Failed to save git repo.").
+			Err(err).Add("target-user", repo.User).Add("url", repo.URL)
+	}
+
+	response.Repo = repo
+
+	return &response, nil
+}
@@ -0,0 +1,40 @@
+package git
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+)
+
+type RemoveRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleStudent
+
+	TargetUser core.TargetCourseUserSelfOrAdmin `json:"target-email"`
+}
+
+type RemoveResponse struct {
+	FoundUser bool `json:"found-user"`
+	FoundRepo bool `json:"found-repo"`
+}
+
+// Remove a user's registered git repo for an assignment.
+// Submissions already made from the repo are not affected.
+func HandleRemove(request *RemoveRequest) (*RemoveResponse, *core.APIError) {
+	response := RemoveResponse{}
+
+	if !request.TargetUser.Found {
+		return &response, nil
+	}
+
+	response.FoundUser = true
+
+	removed, err := db.RemoveGitRepo(request.Assignment, request.TargetUser.Email)
+	if err != nil {
+		return nil, core.NewInternalError("-681", request, "Failed to remove git repo.").
+			Err(err).Add("target-user", request.TargetUser.Email)
+	}
+
+	response.FoundRepo = removed
+
+	return &response, nil
+}
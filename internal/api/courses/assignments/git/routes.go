@@ -0,0 +1,22 @@
+package git
+
+// All the API endpoints handled by this package.
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+)
+
+var routes []core.Route = []core.Route{
+	core.MustNewAPIRoute(`courses/assignments/git/get`, HandleGet),
+	core.MustNewAPIRoute(`courses/assignments/git/list`, HandleList),
+	core.MustNewAPIRoute(`courses/assignments/git/poll`, HandlePoll),
+	core.MustNewAPIRoute(`courses/assignments/git/register`, HandleRegister),
+	core.MustNewAPIRoute(`courses/assignments/git/remove`, HandleRemove),
+
+	// Push webhooks come from the git host (not a user), so this is not a standard API endpoint.
+	core.NewBaseRoute("POST", core.MakeFullAPIPath(`courses/assignments/git/webhook`), HandleWebhook),
+}
+
+func GetRoutes() *[]core.Route {
+	return &routes
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/procedures/gitsubmit"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const MAX_WEBHOOK_BODY_BYTES = 25 * 1024 * 1024
+
+type WebhookResponse struct {
+	// The users whose repos will be graded because of this push.
+	Users []string `json:"users"`
+}
+
+// Receive a (GitHub-style) push webhook for an assignment.
+// The course and assignment are specified in the URL's query ("course-id" and "assignment-id"),
+// and the body must be signed (HMAC-SHA256) with the assignment's webhook secret.
+// Any registered repos that match the pushed repo are graded in the background.
+func HandleWebhook(response http.ResponseWriter, request *http.Request) error {
+	courseID := request.URL.Query().Get("course-id")
+	assignmentID := request.URL.Query().Get("assignment-id")
+
+	course, err := db.GetCourse(courseID)
+	if err != nil {
+		log.Error("Failed to get course for git webhook.", err, log.NewCourseAttr(courseID))
+		http.Error(response, "Failed to get course.", http.StatusInternalServerError)
+		return nil
+	}
+
+	if course == nil {
+		http.Error(response, fmt.Sprintf("Unknown course: '%s'.", courseID), http.StatusNotFound)
+		return nil
+	}
+
+	assignment := course.GetAssignment(assignmentID)
+	if assignment == nil {
+		http.Error(response, fmt.Sprintf("Unknown assignment: '%s'.", assignmentID), http.StatusNotFound)
+		return nil
+	}
+
+	if (assignment.GitSubmission == nil) || (assignment.GitSubmission.WebhookSecret == "") {
+		http.Error(response, "Assignment does not accept git webhooks.", http.StatusNotFound)
+		return nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(response, request.Body, MAX_WEBHOOK_BODY_BYTES))
+	if err != nil {
+		http.Error(response, "Failed to read webhook body.", http.StatusBadRequest)
+		return nil
+	}
+
+	if !gitsubmit.VerifySignature(assignment.GitSubmission.WebhookSecret, body, request.Header.Get(gitsubmit.SIGNATURE_HEADER)) {
+		log.Warn("Git webhook has a bad signature.", assignment)
+		http.Error(response, "Bad webhook signature.", http.StatusUnauthorized)
+		return nil
+	}
+
+	result := WebhookResponse{
+		Users: make([]string, 0),
+	}
+
+	// Other events (e.g., "ping" when the webhook is created) are acknowledged, but ignored.
+	event := request.Header.Get(gitsubmit.EVENT_HEADER)
+	if (event != "") && (event != gitsubmit.EVENT_PUSH) {
+		return writeWebhookResponse(response, &result)
+	}
+
+	var push gitsubmit.PushEvent
+	err = json.Unmarshal(body, &push)
+	if err != nil {
+		http.Error(response, "Failed to parse push event.", http.StatusBadRequest)
+		return nil
+	}
+
+	result.Users, err = gitsubmit.SubmitPush(assignment, &push)
+	if err != nil {
+		log.Error("Failed to submit git push.", err, assignment)
+		http.Error(response, "Failed to submit push.", http.StatusInternalServerError)
+		return nil
+	}
+
+	return writeWebhookResponse(response, &result)
+}
+
+func writeWebhookResponse(response http.ResponseWriter, result *WebhookResponse) error {
+	response.Header().Set("Content-Type", "application/json")
+	_, err := fmt.Fprint(response, util.MustToJSON(result))
+	if err != nil {
+		return fmt.Errorf("Could not write git webhook response: '%w'.", err)
+	}
+
+	return nil
+}
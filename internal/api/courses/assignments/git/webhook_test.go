@@ -0,0 +1,100 @@
+package git
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/procedures/gitsubmit"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestWebhook(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	assignment := mustEnableGitSubmission(test)
+
+	err := db.SaveGitRepo(assignment, &model.GitRepo{
+		CourseID:     assignment.GetCourse().GetID(),
+		AssignmentID: assignment.GetID(),
+		User:         "course-student@test.edulinq.org",
+		URL:          "https://github.com/test/student",
+	})
+	if err != nil {
+		test.Fatalf("Failed to save git repo: '%v'.", err)
+	}
+
+	// A push to a branch that was deleted (so nothing will actually be graded).
+	pushBody := `{"ref": "refs/heads/main", "after": "0000000000000000000000000000000000000000", "deleted": true, "repository": {"clone_url": "https://github.com/test/student.git"}}`
+
+	testCases := []struct {
+		assignmentID  string
+		event         string
+		body          string
+		secret        string
+		status        int
+		expectedUsers []string
+	}{
+		{"hw0", gitsubmit.EVENT_PING, `{}`, "secret", http.StatusOK, []string{}},
+		{"hw0", gitsubmit.EVENT_PUSH, pushBody, "secret", http.StatusOK, []string{}},
+		{"hw0", "", pushBody, "secret", http.StatusOK, []string{}},
+
+		{"hw0", gitsubmit.EVENT_PUSH, pushBody, "ZZZ", http.StatusUnauthorized, nil},
+		{"hw0", gitsubmit.EVENT_PUSH, `{`, "secret", http.StatusBadRequest, nil},
+		{"ZZZ", gitsubmit.EVENT_PUSH, pushBody, "secret", http.StatusNotFound, nil},
+	}
+
+	for i, testCase := range testCases {
+		url := core.GetTestServerURL() + core.MakeFullAPIPath(`courses/assignments/git/webhook`) +
+			"?course-id=course101&assignment-id=" + testCase.assignmentID
+
+		request, err := http.NewRequest("POST", url, bytes.NewBufferString(testCase.body))
+		if err != nil {
+			test.Fatalf("Case %d: Failed to create request: '%v'.", i, err)
+		}
+
+		signature := gitsubmit.SIGNATURE_PREFIX + hex.EncodeToString(gitsubmit.ComputeSignature(testCase.secret, []byte(testCase.body)))
+		request.Header.Set(gitsubmit.SIGNATURE_HEADER, signature)
+
+		if testCase.event != "" {
+			request.Header.Set(gitsubmit.EVENT_HEADER, testCase.event)
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			test.Errorf("Case %d: Failed to send request: '%v'.", i, err)
+			continue
+		}
+
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			test.Errorf("Case %d: Failed to read response: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.status != response.StatusCode {
+			test.Errorf("Case %d: Unexpected status. Expected: '%d', Actual: '%d'. Body: '%s'.", i, testCase.status, response.StatusCode, string(body))
+			continue
+		}
+
+		if testCase.status != http.StatusOK {
+			continue
+		}
+
+		var webhookResponse WebhookResponse
+		util.MustJSONFromString(string(body), &webhookResponse)
+
+		if !reflect.DeepEqual(testCase.expectedUsers, webhookResponse.Users) {
+			test.Errorf("Case %d: Unexpected users. Expected: '%v', Actual: '%v'.", i, testCase.expectedUsers, webhookResponse.Users)
+			continue
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package groups
+
+import (
+	"slices"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+type AcceptRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleStudent
+
+	GroupID core.NonEmptyString `json:"group-id" required:""`
+}
+
+type AcceptResponse struct {
+	Group *model.Group `json:"group"`
+}
+
+// Accept an invite to join a group.
+// The requesting user may not already be in a group, and may not have already submitted to the assignment.
+func HandleAccept(request *AcceptRequest) (*AcceptResponse, *core.APIError) {
+	group, err := getInvitedGroup(request.Assignment, string(request.GroupID), request.User.Email)
+	if err != nil {
+		return nil, core.NewInternalError("-686", request, "Failed to get groups.").
+			Err(err).Add("group-id", request.GroupID)
+	}
+
+	if group == nil {
+		return nil, core.NewBadRequestError("-687", request, "You have not been invited to this group.").
+			Add("group-id", request.GroupID)
+	}
+
+	userErr, err := checkNoSubmissions(request.Assignment, []string{request.User.Email})
+	if err != nil {
+		return nil, core.NewInternalError("-688", request, "Failed to check for existing submissions.").
+			Err(err).Add("group-id", group.ID)
+	}
+
+	if userErr != nil {
+		return nil, core.NewBadRequestError("-689", request, "Cannot join a group after submitting to the assignment.").
+			Err(userErr).Add("group-id", group.ID)
+	}
+
+	group.Members = append(group.Members, request.User.Email)
+	group.UpdateTime = timestamp.Now()
+
+	err = group.Validate(request.Assignment.Groups)
+	if err != nil {
+		return nil, core.NewBadRequestError("-690", request, "Invalid group.").
+			Err(err).Add("group-id", group.ID)
+	}
+
+	err = db.SaveGroup(request.Assignment, group)
+	if err != nil {
+		return nil, core.NewBadRequestError("-691", request, "Failed to save group.").
+			Err(err).Add("group-id", group.ID)
+	}
+
+	return &AcceptResponse{group}, nil
+}
+
+// Get the group with the given ID if the user has been invited to it.
+// Returns nil if there is no such group or the user was not invited.
+func getInvitedGroup(assignment *model.Assignment, groupID string, email string) (*model.Group, error) {
+	groups, err := db.GetGroups(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	index := slices.IndexFunc(groups, func(group *model.Group) bool {
+		return (group.ID == groupID)
+	})
+
+	if (index < 0) || !groups[index].HasInvite(email) {
+		return nil, nil
+	}
+
+	return groups[index], nil
+}
@@ -0,0 +1,71 @@
+package groups
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// Build and validate a group for an assignment.
+// All members and invited users must be students in the course.
+// Returns (group, user error, internal error).
+func newGroup(assignment *model.Assignment, groupID string, members []string, invited []string, createdBy string) (*model.Group, error, error) {
+	if groupID == "" {
+		groupID = util.UUID()
+	}
+
+	group := &model.Group{
+		ID:           groupID,
+		CourseID:     assignment.GetCourse().GetID(),
+		AssignmentID: assignment.GetID(),
+		Members:      members,
+		Invited:      invited,
+		CreatedBy:    createdBy,
+		UpdateTime:   timestamp.Now(),
+	}
+
+	err := group.Validate(assignment.Groups)
+	if err != nil {
+		return nil, err, nil
+	}
+
+	users, err := db.GetCourseUsers(assignment.GetCourse())
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to get course users: '%w'.", err)
+	}
+
+	for _, email := range group.GetAllUsers() {
+		user := users[email]
+		if user == nil {
+			return nil, fmt.Errorf("Group member '%s' is not enrolled in the course.", email), nil
+		}
+
+		if user.Role != model.CourseRoleStudent {
+			return nil, fmt.Errorf("Group member '%s' is not a student in the course.", email), nil
+		}
+	}
+
+	return group, nil, nil
+}
+
+// Check that none of the given users have made a submission for the assignment.
+// Students cannot join a group after they have started submitting on their own
+// (since their earlier submissions would not be shared with the group).
+// Returns (user error, internal error).
+func checkNoSubmissions(assignment *model.Assignment, emails []string) (error, error) {
+	for _, email := range emails {
+		history, err := db.GetSubmissionHistory(assignment, email)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get submission history for '%s': '%w'.", email, err)
+		}
+
+		if len(history) > 0 {
+			return fmt.Errorf("User '%s' has already submitted to this assignment.", email), nil
+		}
+	}
+
+	return nil, nil
+}
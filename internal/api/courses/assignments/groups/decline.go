@@ -0,0 +1,46 @@
+package groups
+
+import (
+	"slices"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+type DeclineRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleStudent
+
+	GroupID core.NonEmptyString `json:"group-id" required:""`
+}
+
+type DeclineResponse struct {
+	FoundInvite bool `json:"found-invite"`
+}
+
+// Decline an invite to join a group.
+func HandleDecline(request *DeclineRequest) (*DeclineResponse, *core.APIError) {
+	group, err := getInvitedGroup(request.Assignment, string(request.GroupID), request.User.Email)
+	if err != nil {
+		return nil, core.NewInternalError("-692", request, "Failed to get groups.").
+			Err(err).Add("group-id", request.GroupID)
+	}
+
+	if group == nil {
+		return &DeclineResponse{false}, nil
+	}
+
+	group.Invited = slices.DeleteFunc(group.Invited, func(email string) bool {
+		return (email == request.User.Email)
+	})
+	group.UpdateTime = timestamp.Now()
+
+	err = db.SaveGroup(request.Assignment, group)
+	if err != nil {
+		return nil, core.NewInternalError("-693", request, "Failed to decline invite.").
+			Err(err).Add("group-id", group.ID)
+	}
+
+	return &DeclineResponse{true}, nil
+}
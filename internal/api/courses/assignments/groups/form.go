@@ -0,0 +1,55 @@
+package groups
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+)
+
+type FormRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleStudent
+
+	// The students to invite to the group (not including the requesting user).
+	Partners []string `json:"partners" required:""`
+}
+
+type FormResponse struct {
+	Group *model.Group `json:"group"`
+}
+
+// Form a new group and invite the given partners to it.
+// Partners only become members once they accept their invite (see courses/assignments/groups/accept).
+// The assignment must allow students to form their own groups, the requesting user may not already be in a group,
+// and no one in the group may have already submitted to the assignment.
+func HandleForm(request *FormRequest) (*FormResponse, *core.APIError) {
+	if (request.Assignment.Groups == nil) || !request.Assignment.Groups.AllowStudentGroups {
+		return nil, core.NewBadRequestError("-665", request, "This assignment does not allow students to form groups.")
+	}
+
+	group, userErr, err := newGroup(request.Assignment, "", []string{request.User.Email}, request.Partners, request.User.Email)
+	if err != nil {
+		return nil, core.NewInternalError("-666", request, "Failed to create group.").Err(err)
+	}
+
+	if userErr != nil {
+		return nil, core.NewBadRequestError("-667", request, "Invalid group.").Err(userErr)
+	}
+
+	userErr, err = checkNoSubmissions(request.Assignment, group.GetAllUsers())
+	if err != nil {
+		return nil, core.NewInternalError("-694", request, "Failed to check for existing submissions.").Err(err)
+	}
+
+	if userErr != nil {
+		return nil, core.NewBadRequestError("-695", request, "Cannot form a group with users that have already submitted.").Err(userErr)
+	}
+
+	err = db.SaveGroup(request.Assignment, group)
+	if err != nil {
+		return nil, core.NewBadRequestError("-668", request, "Failed to save group.").
+			Err(err).Add("group-id", group.ID)
+	}
+
+	return &FormResponse{group}, nil
+}
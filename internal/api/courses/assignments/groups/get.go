@@ -0,0 +1,50 @@
+package groups
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+)
+
+type GetRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleStudent
+
+	TargetUser core.TargetCourseUserSelfOrGrader `json:"target-email"`
+}
+
+type GetResponse struct {
+	FoundUser  bool         `json:"found-user"`
+	FoundGroup bool         `json:"found-group"`
+	Group      *model.Group `json:"group"`
+
+	// Groups that the user has been invited to (but has not accepted yet).
+	Invites []*model.Group `json:"invites"`
+}
+
+// Get the group a user is in (and any groups they have been invited to) for an assignment.
+func HandleGet(request *GetRequest) (*GetResponse, *core.APIError) {
+	response := GetResponse{
+		Invites: make([]*model.Group, 0),
+	}
+
+	if !request.TargetUser.Found {
+		return &response, nil
+	}
+
+	response.FoundUser = true
+
+	groups, err := db.GetGroups(request.Assignment)
+	if err != nil {
+		return nil, core.NewInternalError("-660", request, "Failed to get groups.").
+			Err(err).Add("target-user", request.TargetUser.Email)
+	}
+
+	group := model.GetUserGroup(groups, request.TargetUser.Email)
+
+	response.FoundGroup = (group != nil)
+	response.Group = group
+	response.Invites = model.GetUserInvites(groups, request.TargetUser.Email)
+
+	return &response, nil
+}
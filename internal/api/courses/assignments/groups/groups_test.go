@@ -0,0 +1,385 @@
+package groups
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestUpsertAndRemove(test *testing.T) {
+	defer db.ResetForTesting()
+
+	testCases := []struct {
+		email   string
+		groupID string
+		members []string
+		locator string
+	}{
+		{"course-admin", "g1", []string{"course-student@test.edulinq.org", "course-other@test.edulinq.org"}, ""},
+		{"course-owner", "", []string{"course-student@test.edulinq.org"}, ""},
+
+		// Bad groups.
+		{"course-admin", "g1", []string{"course-student@test.edulinq.org", "course-other@test.edulinq.org", "course-grader@test.edulinq.org"}, "-662"},
+		{"course-admin", "g1", []string{"ZZZ@test.edulinq.org"}, "-662"},
+		{"course-admin", "g1", []string{"course-student@test.edulinq.org", "course-admin@test.edulinq.org"}, "-662"},
+		{"course-admin", "g 1", []string{"course-student@test.edulinq.org"}, "-662"},
+
+		// Roles below admin.
+		{"course-grader", "g1", []string{"course-student@test.edulinq.org"}, "-020"},
+		{"course-student", "g1", []string{"course-student@test.edulinq.org"}, "-020"},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+		mustSetGroupOptions(test, false)
+		mustMakeStudent(test, "course-other@test.edulinq.org")
+
+		fields := map[string]any{
+			"group-id": testCase.groupID,
+			"members":  testCase.members,
+		}
+
+		response := core.SendTestAPIRequestFull(test, `courses/assignments/groups/upsert`, fields, nil, testCase.email)
+		if !response.Success {
+			if testCase.locator != response.Locator {
+				test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator)
+			}
+
+			continue
+		}
+
+		if testCase.locator != "" {
+			test.Errorf("Case %d: Did not get an expected error '%s'.", i, testCase.locator)
+			continue
+		}
+
+		var upsertResponse UpsertResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &upsertResponse)
+
+		group := upsertResponse.Group
+		if (testCase.groupID != "") && (testCase.groupID != group.ID) {
+			test.Errorf("Case %d: Unexpected group ID. Expected: '%s', Actual: '%s'.", i, testCase.groupID, group.ID)
+			continue
+		}
+
+		response = core.SendTestAPIRequestFull(test, `courses/assignments/groups/list`, nil, nil, "course-grader")
+		if !response.Success {
+			test.Errorf("Case %d: List response is not a success when it should be: '%v'.", i, response)
+			continue
+		}
+
+		var listResponse ListResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &listResponse)
+
+		expected := []*model.Group{group}
+		if !reflect.DeepEqual(expected, listResponse.Groups) {
+			test.Errorf("Case %d: Unexpected groups. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(expected), util.MustToJSONIndent(listResponse.Groups))
+			continue
+		}
+
+		for _, expectedFound := range []bool{true, false} {
+			response = core.SendTestAPIRequestFull(test, `courses/assignments/groups/remove`, map[string]any{"group-id": group.ID}, nil, testCase.email)
+			if !response.Success {
+				test.Errorf("Case %d: Remove response is not a success when it should be: '%v'.", i, response)
+				break
+			}
+
+			var removeResponse RemoveResponse
+			util.MustJSONFromString(util.MustToJSON(response.Content), &removeResponse)
+
+			if expectedFound != removeResponse.FoundGroup {
+				test.Errorf("Case %d: Unexpected remove result. Expected: '%v', Actual: '%v'.", i, expectedFound, removeResponse.FoundGroup)
+				break
+			}
+		}
+	}
+}
+
+func TestFormAcceptLeave(test *testing.T) {
+	defer db.ResetForTesting()
+
+	testCases := []struct {
+		allowStudentGroups bool
+		email              string
+		partners           []string
+		locator            string
+	}{
+		{true, "course-other", []string{"course-grader@test.edulinq.org"}, ""},
+		{true, "course-other", []string{}, ""},
+
+		// Students not allowed to form groups.
+		{false, "course-other", []string{"course-grader@test.edulinq.org"}, "-665"},
+
+		// Too many members.
+		{true, "course-other", []string{"course-grader@test.edulinq.org", "course-student@test.edulinq.org"}, "-667"},
+
+		// Partner is not a student.
+		{true, "course-other", []string{"course-admin@test.edulinq.org"}, "-667"},
+
+		// Partner has already submitted.
+		{true, "course-other", []string{"course-student@test.edulinq.org"}, "-695"},
+
+		// Requester has already submitted.
+		{true, "course-student", []string{"course-other@test.edulinq.org"}, "-695"},
+
+		// Requester already in a group.
+		{true, "course-grader", []string{"course-other@test.edulinq.org"}, "-668"},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+		assignment := mustSetGroupOptions(test, testCase.allowStudentGroups)
+		mustMakeStudent(test, "course-other@test.edulinq.org")
+		mustMakeStudent(test, "course-grader@test.edulinq.org")
+
+		if testCase.email == "course-grader" {
+			mustSaveExistingGroup(test, assignment, []string{"course-grader@test.edulinq.org"}, nil)
+		}
+
+		fields := map[string]any{
+			"partners": testCase.partners,
+		}
+
+		response := core.SendTestAPIRequestFull(test, `courses/assignments/groups/form`, fields, nil, testCase.email)
+		if !response.Success {
+			if testCase.locator != response.Locator {
+				test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator)
+			}
+
+			continue
+		}
+
+		if testCase.locator != "" {
+			test.Errorf("Case %d: Did not get an expected error '%s'.", i, testCase.locator)
+			continue
+		}
+
+		var formResponse FormResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &formResponse)
+
+		group := formResponse.Group
+
+		// Partners are only invited.
+		email := testCase.email + "@test.edulinq.org"
+		if !reflect.DeepEqual([]string{email}, group.Members) || (len(group.Invited) != len(testCase.partners)) {
+			test.Errorf("Case %d: Unexpected group: '%s'.", i, util.MustToJSONIndent(group))
+			continue
+		}
+
+		// Partners see an invite, but are not in the group until they accept.
+		for _, partner := range testCase.partners {
+			getResponse := mustGetGroup(test, partner)
+			if getResponse.FoundGroup || !reflect.DeepEqual([]*model.Group{group}, getResponse.Invites) {
+				test.Errorf("Case %d: Unexpected group for invited partner '%s': '%s'.", i, partner, util.MustToJSONIndent(getResponse))
+				continue
+			}
+
+			response = core.SendTestAPIRequestFull(test, `courses/assignments/groups/accept`, map[string]any{"group-id": group.ID}, nil, partner)
+			if !response.Success {
+				test.Errorf("Case %d: Accept response is not a success when it should be: '%v'.", i, response)
+				continue
+			}
+
+			var acceptResponse AcceptResponse
+			util.MustJSONFromString(util.MustToJSON(response.Content), &acceptResponse)
+
+			group = acceptResponse.Group
+		}
+
+		if (len(group.Members) != (len(testCase.partners) + 1)) || (len(group.Invited) != 0) {
+			test.Errorf("Case %d: Unexpected group after accepting: '%s'.", i, util.MustToJSONIndent(group))
+			continue
+		}
+
+		// All members can see the group.
+		for _, member := range group.Members {
+			getResponse := mustGetGroup(test, member)
+			if !getResponse.FoundGroup || !reflect.DeepEqual(group, getResponse.Group) || (len(getResponse.Invites) != 0) {
+				test.Errorf("Case %d: Unexpected group for '%s': '%s'.", i, member, util.MustToJSONIndent(getResponse))
+				continue
+			}
+		}
+
+		// Leave the group (twice).
+		for _, expectedFound := range []bool{true, false} {
+			response = core.SendTestAPIRequestFull(test, `courses/assignments/groups/leave`, nil, nil, testCase.email)
+			if !response.Success {
+				test.Errorf("Case %d: Leave response is not a success when it should be: '%v'.", i, response)
+				break
+			}
+
+			var leaveResponse LeaveResponse
+			util.MustJSONFromString(util.MustToJSON(response.Content), &leaveResponse)
+
+			if expectedFound != leaveResponse.FoundGroup {
+				test.Errorf("Case %d: Unexpected leave result. Expected: '%v', Actual: '%v'.", i, expectedFound, leaveResponse.FoundGroup)
+				break
+			}
+		}
+
+		getResponse := mustGetGroup(test, email)
+		if getResponse.FoundGroup {
+			test.Errorf("Case %d: Found a group after leaving: '%s'.", i, util.MustToJSONIndent(getResponse))
+			continue
+		}
+
+		// Remaining partners are still in the group.
+		for _, partner := range testCase.partners {
+			getResponse := mustGetGroup(test, partner)
+			if !getResponse.FoundGroup || getResponse.Group.HasMember(email) {
+				test.Errorf("Case %d: Unexpected group for remaining partner '%s': '%s'.", i, partner, util.MustToJSONIndent(getResponse))
+				continue
+			}
+		}
+	}
+}
+
+func TestAcceptDecline(test *testing.T) {
+	defer db.ResetForTesting()
+
+	testCases := []struct {
+		email          string
+		invited        string
+		existingGroup  bool
+		accept         bool
+		locator        string
+		expectedFound  bool
+		expectedMember bool
+	}{
+		{"course-grader", "course-grader@test.edulinq.org", false, true, "", true, true},
+		{"course-grader", "course-grader@test.edulinq.org", false, false, "", true, false},
+
+		// Already in a group.
+		{"course-grader", "course-grader@test.edulinq.org", true, true, "-691", false, false},
+
+		// Already submitted (the invite was made directly).
+		{"course-student", "course-student@test.edulinq.org", false, true, "-689", false, false},
+
+		// Not invited.
+		{"course-owner", "course-grader@test.edulinq.org", false, true, "-687", false, false},
+		{"course-owner", "course-grader@test.edulinq.org", false, false, "", false, false},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+		assignment := mustSetGroupOptions(test, true)
+		mustMakeStudent(test, "course-other@test.edulinq.org")
+		mustMakeStudent(test, "course-grader@test.edulinq.org")
+
+		if testCase.existingGroup {
+			mustSaveExistingGroup(test, assignment, []string{"course-grader@test.edulinq.org"}, nil)
+		}
+
+		group := mustSaveGroup(test, assignment, "invite", []string{"course-other@test.edulinq.org"}, []string{testCase.invited})
+
+		endpoint := `courses/assignments/groups/decline`
+		if testCase.accept {
+			endpoint = `courses/assignments/groups/accept`
+		}
+
+		response := core.SendTestAPIRequestFull(test, endpoint, map[string]any{"group-id": group.ID}, nil, testCase.email)
+		if !response.Success {
+			if testCase.locator != response.Locator {
+				test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator)
+			}
+
+			continue
+		}
+
+		if testCase.locator != "" {
+			test.Errorf("Case %d: Did not get an expected error '%s'.", i, testCase.locator)
+			continue
+		}
+
+		if !testCase.accept {
+			var declineResponse DeclineResponse
+			util.MustJSONFromString(util.MustToJSON(response.Content), &declineResponse)
+
+			if testCase.expectedFound != declineResponse.FoundInvite {
+				test.Errorf("Case %d: Unexpected decline result. Expected: '%v', Actual: '%v'.", i, testCase.expectedFound, declineResponse.FoundInvite)
+				continue
+			}
+		}
+
+		email := testCase.email + "@test.edulinq.org"
+		getResponse := mustGetGroup(test, email)
+
+		if testCase.expectedMember != getResponse.FoundGroup {
+			test.Errorf("Case %d: Unexpected membership. Expected: '%v', Actual: '%s'.", i, testCase.expectedMember, util.MustToJSONIndent(getResponse))
+			continue
+		}
+
+		if len(getResponse.Invites) != 0 {
+			test.Errorf("Case %d: Found unexpected invites: '%s'.", i, util.MustToJSONIndent(getResponse.Invites))
+			continue
+		}
+	}
+}
+
+func mustGetGroup(test *testing.T, email string) *GetResponse {
+	fields := map[string]any{
+		"target-email": email,
+	}
+
+	response := core.SendTestAPIRequestFull(test, `courses/assignments/groups/get`, fields, nil, "course-admin")
+	if !response.Success {
+		test.Fatalf("Get response is not a success when it should be: '%v'.", response)
+	}
+
+	var getResponse GetResponse
+	util.MustJSONFromString(util.MustToJSON(response.Content), &getResponse)
+
+	return &getResponse
+}
+
+func mustSetGroupOptions(test *testing.T, allowStudentGroups bool) *model.Assignment {
+	assignment := db.MustGetTestAssignment()
+	assignment.Groups = &model.GroupOptions{
+		MaxSize:            2,
+		AllowStudentGroups: allowStudentGroups,
+	}
+
+	err := db.SaveAssignment(assignment)
+	if err != nil {
+		test.Fatalf("Failed to save assignment: '%v'.", err)
+	}
+
+	return assignment
+}
+
+func mustMakeStudent(test *testing.T, email string) {
+	user := db.MustGetServerUser(email)
+	user.CourseInfo["course101"].Role = model.CourseRoleStudent
+	db.MustUpsertUser(user)
+}
+
+func mustSaveExistingGroup(test *testing.T, assignment *model.Assignment, members []string, invited []string) {
+	mustSaveGroup(test, assignment, "existing", members, invited)
+}
+
+func mustSaveGroup(test *testing.T, assignment *model.Assignment, id string, members []string, invited []string) *model.Group {
+	group := &model.Group{
+		ID:           id,
+		CourseID:     "course101",
+		AssignmentID: "hw0",
+		Members:      members,
+		Invited:      invited,
+	}
+
+	err := group.Validate(&model.GroupOptions{MaxSize: 3})
+	if err != nil {
+		test.Fatalf("Failed to validate group '%s': '%v'.", id, err)
+	}
+
+	err = db.SaveGroup(assignment, group)
+	if err != nil {
+		test.Fatalf("Failed to save group '%s': '%v'.", id, err)
+	}
+
+	return group
+}
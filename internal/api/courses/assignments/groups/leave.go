@@ -0,0 +1,49 @@
+package groups
+
+import (
+	"slices"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+type LeaveRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleStudent
+}
+
+type LeaveResponse struct {
+	FoundGroup bool `json:"found-group"`
+}
+
+// Leave your group for an assignment.
+// The group is removed once its last member leaves.
+func HandleLeave(request *LeaveRequest) (*LeaveResponse, *core.APIError) {
+	group, err := db.GetUserGroup(request.Assignment, request.User.Email)
+	if err != nil {
+		return nil, core.NewInternalError("-669", request, "Failed to get group.").Err(err)
+	}
+
+	if group == nil {
+		return &LeaveResponse{false}, nil
+	}
+
+	group.Members = slices.DeleteFunc(group.Members, func(member string) bool {
+		return (member == request.User.Email)
+	})
+	group.UpdateTime = timestamp.Now()
+
+	if len(group.Members) == 0 {
+		_, err = db.RemoveGroup(request.Assignment, group.ID)
+	} else {
+		err = db.SaveGroup(request.Assignment, group)
+	}
+
+	if err != nil {
+		return nil, core.NewInternalError("-670", request, "Failed to leave group.").
+			Err(err).Add("group-id", group.ID)
+	}
+
+	return &LeaveResponse{true}, nil
+}
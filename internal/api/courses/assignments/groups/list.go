@@ -0,0 +1,26 @@
+package groups
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+)
+
+type ListRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleGrader
+}
+
+type ListResponse struct {
+	Groups []*model.Group `json:"groups"`
+}
+
+// List all the groups for an assignment.
+func HandleList(request *ListRequest) (*ListResponse, *core.APIError) {
+	groups, err := db.GetGroups(request.Assignment)
+	if err != nil {
+		return nil, core.NewInternalError("-659", request, "Failed to get groups.").Err(err)
+	}
+
+	return &ListResponse{groups}, nil
+}
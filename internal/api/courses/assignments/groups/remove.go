@@ -0,0 +1,29 @@
+package groups
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+)
+
+type RemoveRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleAdmin
+
+	GroupID core.NonEmptyString `json:"group-id" required:""`
+}
+
+type RemoveResponse struct {
+	FoundGroup bool `json:"found-group"`
+}
+
+// Remove a group from an assignment.
+// Submissions already made by the group are not affected.
+func HandleRemove(request *RemoveRequest) (*RemoveResponse, *core.APIError) {
+	removed, err := db.RemoveGroup(request.Assignment, string(request.GroupID))
+	if err != nil {
+		return nil, core.NewInternalError("-664", request, "Failed to remove group.").
+			Err(err).Add("group-id", request.GroupID)
+	}
+
+	return &RemoveResponse{removed}, nil
+}
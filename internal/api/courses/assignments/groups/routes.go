@@ -0,0 +1,22 @@
+package groups
+
+// All the API endpoints handled by this package.
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+)
+
+var routes []core.Route = []core.Route{
+	core.MustNewAPIRoute(`courses/assignments/groups/accept`, HandleAccept),
+	core.MustNewAPIRoute(`courses/assignments/groups/decline`, HandleDecline),
+	core.MustNewAPIRoute(`courses/assignments/groups/form`, HandleForm),
+	core.MustNewAPIRoute(`courses/assignments/groups/get`, HandleGet),
+	core.MustNewAPIRoute(`courses/assignments/groups/leave`, HandleLeave),
+	core.MustNewAPIRoute(`courses/assignments/groups/list`, HandleList),
+	core.MustNewAPIRoute(`courses/assignments/groups/remove`, HandleRemove),
+	core.MustNewAPIRoute(`courses/assignments/groups/upsert`, HandleUpsert),
+}
+
+func GetRoutes() *[]core.Route {
+	return &routes
+}
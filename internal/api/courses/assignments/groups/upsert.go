@@ -0,0 +1,41 @@
+package groups
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+)
+
+type UpsertRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleAdmin
+
+	// Leave empty to create a new group.
+	GroupID string   `json:"group-id"`
+	Members []string `json:"members" required:""`
+}
+
+type UpsertResponse struct {
+	Group *model.Group `json:"group"`
+}
+
+// Create a group (or replace the members of an existing group) for an assignment.
+// Members are added directly (without an invite), even if they have already submitted.
+func HandleUpsert(request *UpsertRequest) (*UpsertResponse, *core.APIError) {
+	group, userErr, err := newGroup(request.Assignment, request.GroupID, request.Members, nil, request.User.Email)
+	if err != nil {
+		return nil, core.NewInternalError("-661", request, "Failed to create group.").Err(err)
+	}
+
+	if userErr != nil {
+		return nil, core.NewBadRequestError("-662", request, "Invalid group.").Err(userErr)
+	}
+
+	err = db.SaveGroup(request.Assignment, group)
+	if err != nil {
+		return nil, core.NewBadRequestError("-663", request, "Failed to save group.").
+			Err(err).Add("group-id", group.ID)
+	}
+
+	return &UpsertResponse{group}, nil
+}
@@ -4,6 +4,8 @@ package assignments
 
 import (
 	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/api/courses/assignments/git"
+	"github.com/edulinq/autograder/internal/api/courses/assignments/groups"
 	"github.com/edulinq/autograder/internal/api/courses/assignments/images"
 	"github.com/edulinq/autograder/internal/api/courses/assignments/submissions"
 )
@@ -18,6 +20,8 @@ func GetRoutes() *[]core.Route {
 	routes := make([]core.Route, 0)
 
 	routes = append(routes, baseRoutes...)
+	routes = append(routes, *(git.GetRoutes())...)
+	routes = append(routes, *(groups.GetRoutes())...)
 	routes = append(routes, *(images.GetRoutes())...)
 	routes = append(routes, *(submissions.GetRoutes())...)
 
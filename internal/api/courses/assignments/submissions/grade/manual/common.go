@@ -0,0 +1,35 @@
+package manual
+
+import (
+	"github.com/edulinq/autograder/internal/model"
+)
+
+type ManualGradeResponse struct {
+	FoundUser       bool `json:"found-user"`
+	FoundSubmission bool `json:"found-submission"`
+
+	Rubric      *model.Rubric      `json:"rubric"`
+	ManualGrade *model.ManualGrade `json:"manual-grade"`
+
+	// True if the manual grade was made for an earlier submission and is being carried forward.
+	StaleManualGrade bool `json:"stale-manual-grade"`
+
+	AutograderScore float64 `json:"autograder-score"`
+	ManualScore     float64 `json:"manual-score"`
+	TotalScore      float64 `json:"total-score"`
+}
+
+// Fill in the scores for a submission and its (possibly nil) manual grade.
+func (this *ManualGradeResponse) setScores(assignment *model.Assignment, gradingInfo *model.GradingInfo, manualGrade *model.ManualGrade) {
+	this.ManualGrade = manualGrade
+	this.StaleManualGrade = ((manualGrade != nil) && (manualGrade.ShortID != gradingInfo.ShortID))
+	this.AutograderScore = gradingInfo.Score
+	this.ManualScore = manualGrade.GetScore(assignment.Rubric)
+	this.TotalScore = this.AutograderScore + this.ManualScore
+}
+
+func newResponse(assignment *model.Assignment) *ManualGradeResponse {
+	return &ManualGradeResponse{
+		Rubric: assignment.Rubric,
+	}
+}
@@ -0,0 +1,48 @@
+package manual
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+)
+
+type FetchRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleGrader
+
+	TargetUser       core.TargetCourseUserSelfOrGrader `json:"target-email"`
+	TargetSubmission string                            `json:"target-submission"`
+}
+
+// Get the rubric and manual grade for a submission. Defaults to the most recent submission.
+// If the submission has not been manually graded, the manual grade from an earlier submission is carried forward (and marked as stale).
+func HandleFetch(request *FetchRequest) (*ManualGradeResponse, *core.APIError) {
+	response := newResponse(request.Assignment)
+
+	if !request.TargetUser.Found {
+		return response, nil
+	}
+
+	response.FoundUser = true
+
+	gradingInfo, err := db.GetSubmissionResult(request.Assignment, request.TargetUser.Email, request.TargetSubmission)
+	if err != nil {
+		return nil, core.NewInternalError("-649", request, "Failed to get submission result.").
+			Err(err).Add("target-user", request.TargetUser.Email).Add("submission", request.TargetSubmission)
+	}
+
+	if gradingInfo == nil {
+		return response, nil
+	}
+
+	response.FoundSubmission = true
+
+	manualGrade, err := db.GetLatestManualGrade(request.Assignment, request.TargetUser.Email, gradingInfo.ShortID)
+	if err != nil {
+		return nil, core.NewInternalError("-650", request, "Failed to get manual grade.").
+			Err(err).Add("target-user", request.TargetUser.Email).Add("submission", gradingInfo.ID)
+	}
+
+	response.setScores(request.Assignment, gradingInfo, manualGrade)
+
+	return response, nil
+}
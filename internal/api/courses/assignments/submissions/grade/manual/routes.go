@@ -0,0 +1,16 @@
+package manual
+
+// All the API endpoints handled by this package.
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+)
+
+var routes []core.Route = []core.Route{
+	core.MustNewAPIRoute(`courses/assignments/submissions/grade/manual/fetch`, HandleFetch),
+	core.MustNewAPIRoute(`courses/assignments/submissions/grade/manual/upsert`, HandleUpsert),
+}
+
+func GetRoutes() *[]core.Route {
+	return &routes
+}
@@ -0,0 +1,72 @@
+package manual
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+type UpsertRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleGrader
+
+	TargetUser       core.TargetCourseUserSelfOrGrader `json:"target-email"`
+	TargetSubmission string                            `json:"target-submission"`
+
+	Selections []string `json:"selections"`
+	Comments   string   `json:"comments"`
+	Adjustment float64  `json:"adjustment"`
+}
+
+// Set the manual grade for a submission (replacing any existing manual grade). Defaults to the most recent submission.
+func HandleUpsert(request *UpsertRequest) (*ManualGradeResponse, *core.APIError) {
+	response := newResponse(request.Assignment)
+
+	if !request.TargetUser.Found {
+		return response, nil
+	}
+
+	response.FoundUser = true
+
+	gradingInfo, err := db.GetSubmissionResult(request.Assignment, request.TargetUser.Email, request.TargetSubmission)
+	if err != nil {
+		return nil, core.NewInternalError("-651", request, "Failed to get submission result.").
+			Err(err).Add("target-user", request.TargetUser.Email).Add("submission", request.TargetSubmission)
+	}
+
+	if gradingInfo == nil {
+		return response, nil
+	}
+
+	response.FoundSubmission = true
+
+	manualGrade := &model.ManualGrade{
+		ID:           gradingInfo.ID,
+		ShortID:      gradingInfo.ShortID,
+		CourseID:     gradingInfo.CourseID,
+		AssignmentID: gradingInfo.AssignmentID,
+		User:         gradingInfo.User,
+		Grader:       request.ServerUser.Email,
+		UpdateTime:   timestamp.Now(),
+		Selections:   request.Selections,
+		Comments:     request.Comments,
+		Adjustment:   request.Adjustment,
+	}
+
+	err = manualGrade.Validate(request.Assignment.Rubric)
+	if err != nil {
+		return nil, core.NewBadRequestError("-652", request, "Invalid manual grade.").
+			Err(err).Add("target-user", request.TargetUser.Email).Add("submission", gradingInfo.ID)
+	}
+
+	err = db.SaveManualGrade(manualGrade)
+	if err != nil {
+		return nil, core.NewInternalError("-653", request, "Failed to save manual grade.").
+			Err(err).Add("target-user", request.TargetUser.Email).Add("submission", gradingInfo.ID)
+	}
+
+	response.setScores(request.Assignment, gradingInfo, manualGrade)
+
+	return response, nil
+}
@@ -0,0 +1,242 @@
+package manual
+
+import (
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestUpsertAndFetch(test *testing.T) {
+	// Leave the course in a good state after the test.
+	defer db.ResetForTesting()
+
+	testCases := []struct {
+		email            string
+		targetEmail      string
+		targetSubmission string
+		selections       []string
+		adjustment       float64
+		foundUser        bool
+		foundSubmission  bool
+		locator          string
+		expectedManual   float64
+		expectedTotal    float64
+	}{
+		// Grader, other, recent.
+		{"course-grader", "course-student@test.edulinq.org", "", []string{"style"}, 0, true, true, "", 1, 3},
+		{"course-grader", "course-student@test.edulinq.org", "", []string{"style", "docs"}, 0.25, true, true, "", 1.75, 3.75},
+		{"course-grader", "course-student@test.edulinq.org", "", []string{"hardcoded"}, 0, true, true, "", -2, 0},
+		{"course-grader", "course-student@test.edulinq.org", "", nil, 1, true, true, "", 1, 3},
+
+		// Grader, other, specific.
+		{"course-grader", "course-student@test.edulinq.org", "1697406256", []string{"style"}, 0, true, true, "", 1, 1},
+		{"course-grader", "course-student@test.edulinq.org", "course101::hw0::course-student@test.edulinq.org::1697406265", []string{"docs"}, 0, true, true, "", 0.5, 1.5},
+
+		// Grader, other, missing.
+		{"course-grader", "course-student@test.edulinq.org", "ZZZ", []string{"style"}, 0, true, false, "", 0, 0},
+
+		// Grader, self (no submissions).
+		{"course-grader", "", "", []string{"style"}, 0, true, false, "", 0, 0},
+
+		// Grader, missing user.
+		{"course-grader", "ZZZ@test.edulinq.org", "", []string{"style"}, 0, false, false, "", 0, 0},
+
+		// Roles above grader.
+		{"course-admin", "course-student@test.edulinq.org", "", []string{"docs"}, 0, true, true, "", 0.5, 2.5},
+		{"course-owner", "course-student@test.edulinq.org", "", []string{"docs"}, 0, true, true, "", 0.5, 2.5},
+
+		// Unknown rubric item.
+		{"course-grader", "course-student@test.edulinq.org", "", []string{"zzz"}, 0, true, true, "-652", 0, 0},
+
+		// Roles below grader.
+		{"course-student", "course-student@test.edulinq.org", "", []string{"style"}, 0, false, false, "-020", 0, 0},
+		{"course-other", "course-student@test.edulinq.org", "", []string{"style"}, 0, false, false, "-020", 0, 0},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+
+		fields := map[string]any{
+			"target-email":      testCase.targetEmail,
+			"target-submission": testCase.targetSubmission,
+			"selections":        testCase.selections,
+			"comments":          "Some comments.",
+			"adjustment":        testCase.adjustment,
+		}
+
+		response := core.SendTestAPIRequestFull(test, `courses/assignments/submissions/grade/manual/upsert`, fields, nil, testCase.email)
+		if !response.Success {
+			if testCase.locator != response.Locator {
+				test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator)
+			}
+
+			continue
+		}
+
+		if testCase.locator != "" {
+			test.Errorf("Case %d: Did not get an expected error '%s'.", i, testCase.locator)
+			continue
+		}
+
+		var upsertResponse ManualGradeResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &upsertResponse)
+
+		if !checkResponse(test, i, "upsert", testCase.foundUser, testCase.foundSubmission, testCase.expectedManual, testCase.expectedTotal, &upsertResponse) {
+			continue
+		}
+
+		if !testCase.foundSubmission {
+			continue
+		}
+
+		if upsertResponse.ManualGrade.Grader != (testCase.email + "@test.edulinq.org") {
+			test.Errorf("Case %d: Unexpected grader. Expected: '%s', Actual: '%s'.", i, testCase.email, upsertResponse.ManualGrade.Grader)
+			continue
+		}
+
+		// Fetch the grade that was just saved.
+		fields = map[string]any{
+			"target-email":      testCase.targetEmail,
+			"target-submission": testCase.targetSubmission,
+		}
+
+		response = core.SendTestAPIRequestFull(test, `courses/assignments/submissions/grade/manual/fetch`, fields, nil, testCase.email)
+		if !response.Success {
+			test.Errorf("Case %d: Fetch response is not a success when it should be: '%v'.", i, response)
+			continue
+		}
+
+		var fetchResponse ManualGradeResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &fetchResponse)
+
+		if !checkResponse(test, i, "fetch", testCase.foundUser, testCase.foundSubmission, testCase.expectedManual, testCase.expectedTotal, &fetchResponse) {
+			continue
+		}
+
+		if util.MustToJSON(upsertResponse.ManualGrade) != util.MustToJSON(fetchResponse.ManualGrade) {
+			test.Errorf("Case %d: Fetched manual grade does not match. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(upsertResponse.ManualGrade), util.MustToJSONIndent(fetchResponse.ManualGrade))
+			continue
+		}
+	}
+}
+
+func TestFetchNoManualGrade(test *testing.T) {
+	db.ResetForTesting()
+
+	fields := map[string]any{
+		"target-email": "course-student@test.edulinq.org",
+	}
+
+	response := core.SendTestAPIRequestFull(test, `courses/assignments/submissions/grade/manual/fetch`, fields, nil, "course-grader")
+	if !response.Success {
+		test.Fatalf("Response is not a success when it should be: '%v'.", response)
+	}
+
+	var responseContent ManualGradeResponse
+	util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+	checkResponse(test, 0, "fetch", true, true, 0, 2, &responseContent)
+
+	if responseContent.ManualGrade != nil {
+		test.Fatalf("Found an unexpected manual grade: '%s'.", util.MustToJSONIndent(responseContent.ManualGrade))
+	}
+
+	if (responseContent.Rubric == nil) || (len(responseContent.Rubric.Items) != 3) {
+		test.Fatalf("Unexpected rubric: '%s'.", util.MustToJSONIndent(responseContent.Rubric))
+	}
+}
+
+func TestFetchStaleManualGrade(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	fields := map[string]any{
+		"target-email":      "course-student@test.edulinq.org",
+		"target-submission": "1697406265",
+		"selections":        []string{"docs"},
+	}
+
+	response := core.SendTestAPIRequestFull(test, `courses/assignments/submissions/grade/manual/upsert`, fields, nil, "course-grader")
+	if !response.Success {
+		test.Fatalf("Upsert response is not a success when it should be: '%v'.", response)
+	}
+
+	testCases := []struct {
+		targetSubmission string
+		expectedGrade    bool
+		expectedStale    bool
+		expectedManual   float64
+		expectedTotal    float64
+	}{
+		// The grade is carried forward to later submissions.
+		{"", true, true, 0.5, 2.5},
+		{"1697406272", true, true, 0.5, 2.5},
+
+		// The graded submission.
+		{"1697406265", true, false, 0.5, 1.5},
+
+		// The grade is not carried back to earlier submissions.
+		{"1697406256", false, false, 0, 0},
+	}
+
+	for i, testCase := range testCases {
+		fields = map[string]any{
+			"target-email":      "course-student@test.edulinq.org",
+			"target-submission": testCase.targetSubmission,
+		}
+
+		response = core.SendTestAPIRequestFull(test, `courses/assignments/submissions/grade/manual/fetch`, fields, nil, "course-grader")
+		if !response.Success {
+			test.Errorf("Case %d: Fetch response is not a success when it should be: '%v'.", i, response)
+			continue
+		}
+
+		var responseContent ManualGradeResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+		if !checkResponse(test, i, "fetch", true, true, testCase.expectedManual, testCase.expectedTotal, &responseContent) {
+			continue
+		}
+
+		if testCase.expectedGrade != (responseContent.ManualGrade != nil) {
+			test.Errorf("Case %d: Unexpected manual grade presence. Expected: '%v', Actual: '%s'.", i, testCase.expectedGrade, util.MustToJSONIndent(responseContent.ManualGrade))
+			continue
+		}
+
+		if testCase.expectedStale != responseContent.StaleManualGrade {
+			test.Errorf("Case %d: Unexpected stale value. Expected: '%v', Actual: '%v'.", i, testCase.expectedStale, responseContent.StaleManualGrade)
+			continue
+		}
+	}
+}
+
+func checkResponse(test *testing.T, i int, label string, foundUser bool, foundSubmission bool, expectedManual float64, expectedTotal float64, response *ManualGradeResponse) bool {
+	if foundUser != response.FoundUser {
+		test.Errorf("Case %d (%s): Found user does not match. Expected: '%v', Actual: '%v'.", i, label, foundUser, response.FoundUser)
+		return false
+	}
+
+	if foundSubmission != response.FoundSubmission {
+		test.Errorf("Case %d (%s): Found submission does not match. Expected: '%v', Actual: '%v'.", i, label, foundSubmission, response.FoundSubmission)
+		return false
+	}
+
+	if !foundSubmission {
+		return true
+	}
+
+	if expectedManual != response.ManualScore {
+		test.Errorf("Case %d (%s): Unexpected manual score. Expected: '%f', Actual: '%f'.", i, label, expectedManual, response.ManualScore)
+		return false
+	}
+
+	if expectedTotal != response.TotalScore {
+		test.Errorf("Case %d (%s): Unexpected total score. Expected: '%f', Actual: '%f'.", i, label, expectedTotal, response.TotalScore)
+		return false
+	}
+
+	return true
+}
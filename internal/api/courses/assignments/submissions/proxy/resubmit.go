@@ -16,6 +16,9 @@ type ResubmitRequest struct {
 
 	ProxyUser core.TargetCourseUser `json:"proxy-email" required:""`
 	ProxyTime *timestamp.Timestamp  `json:"proxy-time"`
+
+	// Always run the grader, even if there is cached output for this submission.
+	BypassCache bool `json:"bypass-cache"`
 }
 
 type ResubmitResponse struct {
@@ -70,6 +73,7 @@ func HandleResubmit(request *ResubmitRequest) (*ResubmitResponse, *core.APIError
 	gradeOptions.CheckRejection = false
 	gradeOptions.ProxyUser = request.User.Email
 	gradeOptions.ProxyTime = grader.ResolveProxyTime(request.ProxyTime, request.Assignment)
+	gradeOptions.NoCache = request.BypassCache
 
 	response.BaseSubmitResponse = core.GradeRequestSubmission(request.APIRequestAssignmentContext, tempDir, request.ProxyUser.Email, message, gradeOptions)
 
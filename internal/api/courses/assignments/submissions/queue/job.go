@@ -0,0 +1,33 @@
+package queue
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/grader/queue"
+	"github.com/edulinq/autograder/internal/model"
+)
+
+// Get a grading job (and its queue position) that the context user is allowed to see.
+// Jobs for other assignments are treated as missing.
+// Only graders (or above) may see jobs from other users.
+func getJob(request *core.APIRequestAssignmentContext, jobID string) (*queue.Job, int, *core.APIError) {
+	job, position, err := queue.GetJob(jobID)
+	if err != nil {
+		return nil, 0, core.NewInternalError("-646", request, "Failed to get grading job.").
+			Err(err).Add("job-id", jobID)
+	}
+
+	if job == nil {
+		return nil, 0, nil
+	}
+
+	if (job.CourseID != request.Course.GetID()) || (job.AssignmentID != request.Assignment.GetID()) {
+		return nil, 0, nil
+	}
+
+	if (job.User != request.User.Email) && (request.User.Role < model.CourseRoleGrader) {
+		return nil, 0, core.NewPermissionsError("-647", request, model.CourseRoleGrader, request.User.Role, "Non-Self Grading Job").
+			Add("job-id", jobID)
+	}
+
+	return job, position, nil
+}
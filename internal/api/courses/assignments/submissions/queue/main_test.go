@@ -0,0 +1,50 @@
+package queue
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/grader/queue"
+)
+
+// Use the common main for all tests in this package.
+func TestMain(suite *testing.M) {
+	core.APITestingMain(suite, GetRoutes())
+}
+
+// Add a job that will not get graded (until more workers are allowed).
+// Returns the job ID.
+func mustEnqueuePendingJob(test *testing.T, email string) string {
+	oldWorkers := config.GRADING_QUEUE_WORKERS.Get()
+	config.GRADING_QUEUE_WORKERS.Set(0)
+	defer config.GRADING_QUEUE_WORKERS.Set(oldWorkers)
+
+	return mustEnqueueJob(test, email)
+}
+
+func mustEnqueueJob(test *testing.T, email string) string {
+	assignment := db.MustGetTestSubmissionAssignment()
+	submissionDir := filepath.Join(assignment.GetSourceDir(), "test-submissions", "solution")
+
+	job, _, full, err := queue.Enqueue(assignment, submissionDir, email, "", true)
+	if err != nil {
+		test.Fatalf("Failed to enqueue job: '%v'.", err)
+	}
+
+	if full {
+		test.Fatalf("Grading queue is unexpectedly full.")
+	}
+
+	return job.ID
+}
+
+func getTestFields(jobID string) map[string]any {
+	return map[string]any{
+		"course-id":     db.TEST_SUBMISSION_COURSE_ID,
+		"assignment-id": db.TEST_SUBMISSION_ASSIGNMENT_ID,
+		"job-id":        jobID,
+	}
+}
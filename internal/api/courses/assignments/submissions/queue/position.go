@@ -0,0 +1,50 @@
+package queue
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/grader/queue"
+)
+
+type QueuePositionRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleStudent
+
+	JobID string `json:"job-id" required:""`
+}
+
+type QueuePositionResponse struct {
+	FoundJob bool `json:"found-job"`
+
+	// The position (starting at 1) of the job in the grading queue.
+	// Zero if the job is no longer waiting (it is being graded or is complete).
+	Position int `json:"position"`
+
+	// The total number of submissions waiting to be graded.
+	QueueLength int `json:"queue-length"`
+}
+
+// Get the position of an asynchronous submission in the grading queue.
+func HandleQueuePosition(request *QueuePositionRequest) (*QueuePositionResponse, *core.APIError) {
+	response := QueuePositionResponse{}
+
+	job, position, apiErr := getJob(&request.APIRequestAssignmentContext, request.JobID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if job == nil {
+		return &response, nil
+	}
+
+	length, err := queue.GetPendingCount()
+	if err != nil {
+		return nil, core.NewInternalError("-648", request, "Failed to get grading queue length.").
+			Err(err).Add("job-id", request.JobID)
+	}
+
+	response.FoundJob = true
+	response.Position = position
+	response.QueueLength = length
+
+	return &response, nil
+}
@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/grader/queue"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestQueuePosition(test *testing.T) {
+	queue.ResetForTesting()
+	defer queue.ResetForTesting()
+
+	jobIDs := []string{
+		mustEnqueuePendingJob(test, "course-student@test.edulinq.org"),
+		mustEnqueuePendingJob(test, "course-student@test.edulinq.org"),
+		mustEnqueuePendingJob(test, "course-student@test.edulinq.org"),
+	}
+
+	for i, jobID := range jobIDs {
+		response := core.SendTestAPIRequestFull(test, `courses/assignments/submissions/queue/position`, getTestFields(jobID), nil, "course-student")
+		if !response.Success {
+			test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response)
+			continue
+		}
+
+		var responseContent QueuePositionResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+		expected := QueuePositionResponse{
+			FoundJob:    true,
+			Position:    i + 1,
+			QueueLength: len(jobIDs),
+		}
+
+		if expected != responseContent {
+			test.Errorf("Case %d: Unexpected response. Expected: '%s', Actual: '%s'.",
+				i, util.MustToJSONIndent(expected), util.MustToJSONIndent(responseContent))
+			continue
+		}
+	}
+}
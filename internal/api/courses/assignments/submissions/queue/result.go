@@ -0,0 +1,45 @@
+package queue
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/grader/queue"
+)
+
+type QueueResultRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleStudent
+
+	JobID string `json:"job-id" required:""`
+}
+
+type QueueResultResponse struct {
+	FoundJob bool `json:"found-job"`
+	Complete bool `json:"complete"`
+
+	core.BaseSubmitResponse
+}
+
+// Get the result of an asynchronous submission once it has been graded.
+func HandleQueueResult(request *QueueResultRequest) (*QueueResultResponse, *core.APIError) {
+	response := QueueResultResponse{}
+
+	job, _, apiErr := getJob(&request.APIRequestAssignmentContext, request.JobID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if job == nil {
+		return &response, nil
+	}
+
+	response.FoundJob = true
+
+	if (job.Status != queue.JobStatusComplete) || (job.Result == nil) {
+		return &response, nil
+	}
+
+	response.Complete = true
+	response.BaseSubmitResponse = core.BaseSubmitResponse(*job.Result)
+
+	return &response, nil
+}
@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/grader/queue"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestQueueResult(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	queue.ResetForTesting()
+	defer queue.ResetForTesting()
+
+	completeJobID := mustEnqueueJob(test, "course-student@test.edulinq.org")
+
+	_, err := queue.WaitForJob(completeJobID, 60*1000)
+	if err != nil {
+		test.Fatalf("Failed to wait for grading job: '%v'.", err)
+	}
+
+	pendingJobID := mustEnqueuePendingJob(test, "course-student@test.edulinq.org")
+
+	testCases := []struct {
+		jobID    string
+		foundJob bool
+		complete bool
+	}{
+		{completeJobID, true, true},
+		{pendingJobID, true, false},
+		{"ZZZ", false, false},
+	}
+
+	for i, testCase := range testCases {
+		response := core.SendTestAPIRequestFull(test, `courses/assignments/submissions/queue/result`, getTestFields(testCase.jobID), nil, "course-student")
+		if !response.Success {
+			test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response)
+			continue
+		}
+
+		var responseContent QueueResultResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+		if testCase.foundJob != responseContent.FoundJob {
+			test.Errorf("Case %d: Found job does not match. Expected: '%v', Actual: '%v'.", i, testCase.foundJob, responseContent.FoundJob)
+			continue
+		}
+
+		if testCase.complete != responseContent.Complete {
+			test.Errorf("Case %d: Complete does not match. Expected: '%v', Actual: '%v'.", i, testCase.complete, responseContent.Complete)
+			continue
+		}
+
+		if testCase.complete != responseContent.GradingSuccess {
+			test.Errorf("Case %d: Grading success does not match. Expected: '%v', Actual: '%v'.", i, testCase.complete, responseContent.GradingSuccess)
+			continue
+		}
+
+		if testCase.complete && (responseContent.GradingInfo == nil) {
+			test.Errorf("Case %d: Missing grading info.", i)
+			continue
+		}
+	}
+}
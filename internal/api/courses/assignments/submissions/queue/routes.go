@@ -0,0 +1,17 @@
+package queue
+
+// All the API endpoints handled by this package.
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+)
+
+var routes []core.Route = []core.Route{
+	core.MustNewAPIRoute(`courses/assignments/submissions/queue/position`, HandleQueuePosition),
+	core.MustNewAPIRoute(`courses/assignments/submissions/queue/result`, HandleQueueResult),
+	core.MustNewAPIRoute(`courses/assignments/submissions/queue/status`, HandleQueueStatus),
+}
+
+func GetRoutes() *[]core.Route {
+	return &routes
+}
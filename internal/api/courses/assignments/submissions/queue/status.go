@@ -0,0 +1,47 @@
+package queue
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/grader/queue"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+type QueueStatusRequest struct {
+	core.APIRequestAssignmentContext
+	core.MinCourseRoleStudent
+
+	JobID string `json:"job-id" required:""`
+}
+
+type QueueStatusResponse struct {
+	FoundJob bool `json:"found-job"`
+
+	Status      queue.JobStatus      `json:"status,omitempty"`
+	Position    int                  `json:"position"`
+	EnqueueTime *timestamp.Timestamp `json:"enqueue-time,omitempty"`
+	StartTime   *timestamp.Timestamp `json:"start-time,omitempty"`
+	EndTime     *timestamp.Timestamp `json:"end-time,omitempty"`
+}
+
+// Get the status of an asynchronous submission.
+func HandleQueueStatus(request *QueueStatusRequest) (*QueueStatusResponse, *core.APIError) {
+	response := QueueStatusResponse{}
+
+	job, position, apiErr := getJob(&request.APIRequestAssignmentContext, request.JobID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if job == nil {
+		return &response, nil
+	}
+
+	response.FoundJob = true
+	response.Status = job.Status
+	response.Position = position
+	response.EnqueueTime = &job.EnqueueTime
+	response.StartTime = job.StartTime
+	response.EndTime = job.EndTime
+
+	return &response, nil
+}
@@ -0,0 +1,112 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/grader/queue"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestQueueStatus(test *testing.T) {
+	queue.ResetForTesting()
+	defer queue.ResetForTesting()
+
+	jobID := mustEnqueuePendingJob(test, "course-student@test.edulinq.org")
+	graderJobID := mustEnqueuePendingJob(test, "course-grader@test.edulinq.org")
+
+	testCases := []struct {
+		email     string
+		jobID     string
+		foundJob  bool
+		permError bool
+		locator   string
+	}{
+		// Self.
+		{"course-student", jobID, true, false, ""},
+
+		// Grader, other.
+		{"course-grader", jobID, true, false, ""},
+
+		// Role escalation, other.
+		{"server-admin", jobID, true, false, ""},
+
+		// Missing.
+		{"course-student", "ZZZ", false, false, ""},
+
+		// Student, other.
+		{"course-student", graderJobID, false, true, "-647"},
+	}
+
+	for i, testCase := range testCases {
+		response := core.SendTestAPIRequestFull(test, `courses/assignments/submissions/queue/status`, getTestFields(testCase.jobID), nil, testCase.email)
+		if !response.Success {
+			if testCase.permError {
+				if response.Locator != testCase.locator {
+					test.Errorf("Case %d: Incorrect error returned on permissions error. Expected '%s', found '%s'.",
+						i, testCase.locator, response.Locator)
+				}
+			} else {
+				test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response)
+			}
+
+			continue
+		}
+
+		if testCase.permError {
+			test.Errorf("Case %d: Did not get an expected permissions error.", i)
+			continue
+		}
+
+		var responseContent QueueStatusResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+		if testCase.foundJob != responseContent.FoundJob {
+			test.Errorf("Case %d: Found job does not match. Expected: '%v', Actual: '%v'.", i, testCase.foundJob, responseContent.FoundJob)
+			continue
+		}
+
+		if !testCase.foundJob {
+			continue
+		}
+
+		if responseContent.Status != queue.JobStatusQueued {
+			test.Errorf("Case %d: Unexpected status. Expected: '%s', Actual: '%s'.", i, queue.JobStatusQueued, responseContent.Status)
+			continue
+		}
+
+		if responseContent.Position != 1 {
+			test.Errorf("Case %d: Unexpected position. Expected: 1, Actual: %d.", i, responseContent.Position)
+			continue
+		}
+
+		if (responseContent.EnqueueTime == nil) || (responseContent.StartTime != nil) || (responseContent.EndTime != nil) {
+			test.Errorf("Case %d: Unexpected times: '%s'.", i, util.MustToJSONIndent(responseContent))
+			continue
+		}
+	}
+}
+
+// Jobs can only be accessed through their own assignment.
+func TestQueueStatusWrongAssignment(test *testing.T) {
+	queue.ResetForTesting()
+	defer queue.ResetForTesting()
+
+	jobID := mustEnqueuePendingJob(test, "course-student@test.edulinq.org")
+
+	fields := map[string]any{
+		"job-id": jobID,
+	}
+
+	response := core.SendTestAPIRequestFull(test, `courses/assignments/submissions/queue/status`, fields, nil, "course-student")
+	if !response.Success {
+		test.Fatalf("Response is not a success when it should be: '%v'.", response)
+	}
+
+	var responseContent QueueStatusResponse
+	util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+	if responseContent.FoundJob {
+		test.Fatalf("Found a job from another assignment: '%s'.", util.MustToJSONIndent(responseContent))
+	}
+}
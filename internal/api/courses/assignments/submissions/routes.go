@@ -6,7 +6,9 @@ import (
 	"github.com/edulinq/autograder/internal/api/core"
 	"github.com/edulinq/autograder/internal/api/courses/assignments/submissions/analysis"
 	"github.com/edulinq/autograder/internal/api/courses/assignments/submissions/fetch"
+	"github.com/edulinq/autograder/internal/api/courses/assignments/submissions/grade/manual"
 	"github.com/edulinq/autograder/internal/api/courses/assignments/submissions/proxy"
+	"github.com/edulinq/autograder/internal/api/courses/assignments/submissions/queue"
 )
 
 var baseRoutes []core.Route = []core.Route{
@@ -20,7 +22,9 @@ func GetRoutes() *[]core.Route {
 	routes = append(routes, baseRoutes...)
 	routes = append(routes, *(analysis.GetRoutes())...)
 	routes = append(routes, *(fetch.GetRoutes())...)
+	routes = append(routes, *(manual.GetRoutes())...)
 	routes = append(routes, *(proxy.GetRoutes())...)
+	routes = append(routes, *(queue.GetRoutes())...)
 
 	return &routes
 }
@@ -3,8 +3,11 @@ package submissions
 import (
 	"github.com/edulinq/autograder/internal/api/core"
 	"github.com/edulinq/autograder/internal/grader"
+	"github.com/edulinq/autograder/internal/grader/queue"
 )
 
+const QUEUE_FULL_MESSAGE = "The grading queue is currently full, please try again later."
+
 type SubmitRequest struct {
 	core.APIRequestAssignmentContext
 	core.MinCourseRoleStudent
@@ -12,14 +15,26 @@ type SubmitRequest struct {
 
 	Message   string `json:"message"`
 	AllowLate bool   `json:"allow-late"`
+
+	// Place the submission in the grading queue and return immediately (instead of waiting for grading to finish).
+	// The returned job ID can be used with the `courses/assignments/submissions/queue/*` endpoints.
+	Async bool `json:"async"`
 }
 
 type SubmitResponse struct {
 	core.BaseSubmitResponse
+
+	// Only set for async submissions that were accepted into the grading queue.
+	JobID         string `json:"job-id,omitempty"`
+	QueuePosition int    `json:"queue-position,omitempty"`
 }
 
 // Submit an assignment submission to the autograder.
 func HandleSubmit(request *SubmitRequest) (*SubmitResponse, *core.APIError) {
+	if request.Async {
+		return handleAsyncSubmit(request)
+	}
+
 	response := SubmitResponse{}
 
 	gradeOptions := grader.GetDefaultGradeOptions()
@@ -29,3 +44,36 @@ func HandleSubmit(request *SubmitRequest) (*SubmitResponse, *core.APIError) {
 
 	return &response, nil
 }
+
+func handleAsyncSubmit(request *SubmitRequest) (*SubmitResponse, *core.APIError) {
+	response := SubmitResponse{}
+
+	// Check for rejection now so the submitter finds out right away,
+	// and so lateness is based on when the submission was made (not when it was graded).
+	reject, err := grader.CheckForRejection(request.Assignment, request.Files.TempDir, request.User.Email, request.Message, request.AllowLate)
+	if err != nil {
+		return nil, core.NewInternalError("-644", request, "Failed to check submission for rejection.").Err(err)
+	}
+
+	if reject != nil {
+		response.Rejected = true
+		response.Message = reject.String()
+		return &response, nil
+	}
+
+	job, position, full, err := queue.Enqueue(request.Assignment, request.Files.TempDir, request.User.Email, request.Message, true)
+	if err != nil {
+		return nil, core.NewInternalError("-645", request, "Failed to add submission to the grading queue.").Err(err)
+	}
+
+	if full {
+		response.Rejected = true
+		response.Message = QUEUE_FULL_MESSAGE
+		return &response, nil
+	}
+
+	response.JobID = job.ID
+	response.QueuePosition = position
+
+	return &response, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/edulinq/autograder/internal/config"
 	"github.com/edulinq/autograder/internal/db"
 	"github.com/edulinq/autograder/internal/grader"
+	"github.com/edulinq/autograder/internal/grader/queue"
 	"github.com/edulinq/autograder/internal/model"
 	"github.com/edulinq/autograder/internal/timestamp"
 	"github.com/edulinq/autograder/internal/util"
@@ -231,3 +232,127 @@ func TestRejectLateSubmission(test *testing.T) {
 		}
 	}
 }
+
+func TestSubmitAsync(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	queue.ResetForTesting()
+	defer queue.ResetForTesting()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	paths := []string{filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELPATH)}
+
+	fields := map[string]any{
+		"course-id":     assignment.GetCourse().GetID(),
+		"assignment-id": assignment.GetID(),
+		"allow-late":    true,
+		"async":         true,
+	}
+
+	response := core.SendTestAPIRequestFull(test, `courses/assignments/submissions/submit`, fields, paths, "course-student")
+	if !response.Success {
+		test.Fatalf("Response is not a success when it should be: '%v'.", util.MustToJSONIndent(response))
+	}
+
+	var responseContent SubmitResponse
+	util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+	if responseContent.JobID == "" {
+		test.Fatalf("Did not get a job ID: '%s'.", util.MustToJSONIndent(responseContent))
+	}
+
+	// Async submissions are not graded in the request.
+	if responseContent.Rejected || responseContent.GradingSuccess || (responseContent.GradingInfo != nil) {
+		test.Fatalf("Unexpected grading information in async response: '%s'.", util.MustToJSONIndent(responseContent))
+	}
+
+	job, err := queue.WaitForJob(responseContent.JobID, 60*1000)
+	if err != nil {
+		test.Fatalf("Failed to wait for grading job: '%v'.", err)
+	}
+
+	if (job.Result == nil) || !job.Result.GradingSuccess {
+		test.Fatalf("Grading job was not a success: '%s'.", util.MustToJSONIndent(job))
+	}
+
+	if job.User != "course-student@test.edulinq.org" {
+		test.Fatalf("Unexpected job user. Expected: '%s', Actual: '%s'.", "course-student@test.edulinq.org", job.User)
+	}
+
+	submission, err := db.GetSubmissionResult(assignment, "course-student@test.edulinq.org", "")
+	if err != nil {
+		test.Fatalf("Failed to get submission: '%v'.", err)
+	}
+
+	if submission.ID != job.Result.GradingInfo.ID {
+		test.Fatalf("Most recent submission does not match job. Expected: '%s', Actual: '%s'.", job.Result.GradingInfo.ID, submission.ID)
+	}
+}
+
+func TestSubmitAsyncReject(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	queue.ResetForTesting()
+	defer queue.ResetForTesting()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	paths := []string{filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELPATH)}
+
+	dueDate := timestamp.Zero()
+	assignment.DueDate = &dueDate
+	db.MustSaveAssignment(assignment)
+
+	oldSize := config.GRADING_QUEUE_SIZE.Get()
+	defer config.GRADING_QUEUE_SIZE.Set(oldSize)
+
+	testCases := []struct {
+		allowLate bool
+		queueSize int
+		expected  string
+	}{
+		{false, 10, (&grader.RejectLate{AssignmentName: assignment.Name, DueDate: *assignment.DueDate}).String()},
+		{true, 0, QUEUE_FULL_MESSAGE},
+	}
+
+	timeDeltaPattern := regexp.MustCompile(`(\d+h)?(\d+m)?(\d+\.)?\d+[mun]?s`)
+	timeDeltaReplacement := "<time-delta:TIME>"
+
+	for i, testCase := range testCases {
+		config.GRADING_QUEUE_SIZE.Set(testCase.queueSize)
+
+		fields := map[string]any{
+			"course-id":     assignment.GetCourse().GetID(),
+			"assignment-id": assignment.GetID(),
+			"allow-late":    testCase.allowLate,
+			"async":         true,
+		}
+
+		response := core.SendTestAPIRequestFull(test, `courses/assignments/submissions/submit`, fields, paths, "course-student")
+		if !response.Success {
+			test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, util.MustToJSONIndent(response))
+			continue
+		}
+
+		var responseContent SubmitResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+		if !responseContent.Rejected {
+			test.Errorf("Case %d: Response is not rejected when it should be: '%s'.", i, util.MustToJSONIndent(responseContent))
+			continue
+		}
+
+		if responseContent.JobID != "" {
+			test.Errorf("Case %d: Got a job ID for a rejected submission: '%s'.", i, responseContent.JobID)
+			continue
+		}
+
+		expected := timeDeltaPattern.ReplaceAllString(testCase.expected, timeDeltaReplacement)
+		actual := timeDeltaPattern.ReplaceAllString(responseContent.Message, timeDeltaReplacement)
+		if expected != actual {
+			test.Errorf("Case %d: Unexpected rejection reason. Expected: '%s', Actual: '%s'.", i, expected, actual)
+			continue
+		}
+	}
+}
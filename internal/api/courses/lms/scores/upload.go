@@ -23,7 +23,7 @@ type UploadResponse struct {
 
 // Perform a full scoring and upload scores to the course's LMS.
 func HandleUpload(request *UploadRequest) (*UploadResponse, *core.APIError) {
-	scores, err := scoring.FullCourseScoringAndUpload(request.Course, request.DryRun)
+	scores, err := scoring.FullCourseScoringAndUpload(request.Context, request.Course, request.DryRun)
 	if err != nil {
 		return nil, core.NewInternalError("-617", request,
 			"Failed to perform a full course scoring.").Err(err)
@@ -0,0 +1,52 @@
+package courses
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/procedures/backup"
+)
+
+type RestoreRequest struct {
+	core.APIRequestUserContext
+	core.MinServerRoleAdmin
+
+	backup.RestoreOptions
+	Files core.POSTFiles `json:"-"`
+}
+
+type RestoreResponse struct {
+	Result *backup.RestoreResult `json:"result"`
+}
+
+// Restore a course from a backup zip file.
+// Incremental backups are completed using the backups in the server's backup dir.
+func HandleRestore(request *RestoreRequest) (*RestoreResponse, *core.APIError) {
+	if len(request.Files.Filenames) != 1 {
+		return nil, core.NewBadRequestError("-671", request,
+			fmt.Sprintf("Expected exactly one file, found %d.", len(request.Files.Filenames)))
+	}
+
+	path := filepath.Join(request.Files.TempDir, request.Files.Filenames[0])
+
+	options := request.RestoreOptions
+	options.ContextUser = request.ServerUser
+
+	// Uploaded backups are alone in a temp dir,
+	// so incremental backups can only depend on backups that the server already has.
+	options.BackupsDir = config.GetBackupDir()
+
+	result, userErr, err := backup.RestoreCourse(path, options)
+	if userErr != nil {
+		return nil, core.NewBadRequestError("-672", request, userErr.Error()).Err(userErr)
+	}
+
+	if err != nil {
+		return nil, core.NewInternalError("-673", request,
+			"Failed to restore course.").Err(err)
+	}
+
+	return &RestoreResponse{result}, nil
+}
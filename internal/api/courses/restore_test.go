@@ -0,0 +1,174 @@
+package courses
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/procedures/backup"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestRestore(test *testing.T) {
+	defer db.ResetForTesting()
+
+	tempDir := util.MustMkDirTemp("test-internal.api.courses.restore-")
+	defer util.RemoveDirent(tempDir)
+
+	err := backup.BackupCourseFull(db.MustGetTestCourse(), tempDir, "test")
+	if err != nil {
+		test.Fatalf("Failed to backup course: '%v'.", err)
+	}
+
+	backupPath := filepath.Join(tempDir, "course101-test.zip")
+
+	otherPath := filepath.Join(tempDir, "other.zip")
+	err = util.CopyFile(backupPath, otherPath)
+	if err != nil {
+		test.Fatalf("Failed to copy backup: '%v'.", err)
+	}
+
+	testCases := []struct {
+		email               string
+		paths               []string
+		newCourseID         string
+		dryRun              bool
+		expectedLocator     string
+		expectedSubmissions int
+	}{
+		{"server-admin", []string{backupPath}, "restored", false, "", 3},
+		{"server-admin", []string{backupPath}, "restored", true, "", 3},
+		{"server-owner", []string{backupPath}, "restored", false, "", 3},
+
+		// Course already exists.
+		{"server-admin", []string{backupPath}, "", false, "-672", 0},
+
+		// Bad files.
+		{"server-admin", []string{}, "restored", false, "-030", 0},
+		{"server-admin", []string{backupPath, otherPath}, "restored", false, "-671", 0},
+
+		// Bad permissions.
+		{"server-creator", []string{backupPath}, "restored", false, "-041", 0},
+		{"course-admin", []string{backupPath}, "restored", false, "-041", 0},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+
+		fields := map[string]any{
+			"new-course-id": testCase.newCourseID,
+			"dry-run":       testCase.dryRun,
+		}
+
+		response := core.SendTestAPIRequestFull(test, `courses/restore`, fields, testCase.paths, testCase.email)
+		if !response.Success {
+			if testCase.expectedLocator != response.Locator {
+				test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.expectedLocator, response.Locator)
+			}
+
+			continue
+		}
+
+		if testCase.expectedLocator != "" {
+			test.Errorf("Case %d: Did not get an expected error '%s'.", i, testCase.expectedLocator)
+			continue
+		}
+
+		var responseContent RestoreResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+		if len(responseContent.Result.RestoredSubmissions) != testCase.expectedSubmissions {
+			test.Errorf("Case %d: Unexpected restored submissions. Expected: %d, Actual: '%v'.", i,
+				testCase.expectedSubmissions, responseContent.Result.RestoredSubmissions)
+			continue
+		}
+
+		course, err := db.GetCourse(testCase.newCourseID)
+		if err != nil {
+			test.Errorf("Case %d: Failed to get restored course: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.dryRun != (course == nil) {
+			test.Errorf("Case %d: Unexpected course existence. Dry run: %v, Exists: %v.", i, testCase.dryRun, (course != nil))
+			continue
+		}
+	}
+}
+
+// Uploaded incremental backups are completed with the backups in the server's backup dir.
+func TestRestoreIncremental(test *testing.T) {
+	defer db.ResetForTesting()
+	defer config.BACKUP_DIR.Set(config.BACKUP_DIR.Get())
+
+	backupDir := util.MustMkDirTemp("test-internal.api.courses.restore-incremental-")
+	defer util.RemoveDirent(backupDir)
+
+	config.BACKUP_DIR.Set(backupDir)
+
+	options := backup.BackupOptions{
+		Dest:        backupDir,
+		BackupID:    "1",
+		Incremental: true,
+	}
+
+	baseResult, err := backup.BackupCourseWithOptions(db.MustGetTestCourse(), options)
+	if err != nil {
+		test.Fatalf("Failed to make base backup: '%v'.", err)
+	}
+
+	options.BackupID = "2"
+
+	result, err := backup.BackupCourseWithOptions(db.MustGetTestCourse(), options)
+	if err != nil {
+		test.Fatalf("Failed to make incremental backup: '%v'.", err)
+	}
+
+	if !result.Manifest.Incremental {
+		test.Fatalf("Second backup is not incremental: '%s'.", util.MustToJSONIndent(result.Manifest))
+	}
+
+	testCases := []struct {
+		removeBase      bool
+		expectedLocator string
+	}{
+		{false, ""},
+		{true, "-672"},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+
+		if testCase.removeBase {
+			util.RemoveDirent(baseResult.Path)
+		}
+
+		fields := map[string]any{
+			"new-course-id": "restored",
+		}
+
+		response := core.SendTestAPIRequestFull(test, `courses/restore`, fields, []string{result.Path}, "server-admin")
+		if !response.Success {
+			if testCase.expectedLocator != response.Locator {
+				test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.expectedLocator, response.Locator)
+			}
+
+			continue
+		}
+
+		if testCase.expectedLocator != "" {
+			test.Errorf("Case %d: Did not get an expected error '%s'.", i, testCase.expectedLocator)
+			continue
+		}
+
+		var responseContent RestoreResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+		if len(responseContent.Result.RestoredSubmissions) != 3 {
+			test.Errorf("Case %d: Unexpected restored submissions. Expected: 3, Actual: '%v'.", i, responseContent.Result.RestoredSubmissions)
+			continue
+		}
+	}
+}
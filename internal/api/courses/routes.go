@@ -15,6 +15,7 @@ import (
 var baseRoutes []core.Route = []core.Route{
 	core.MustNewAPIRoute(`courses/get`, HandleGet),
 	core.MustNewAPIRoute(`courses/list`, HandleList),
+	core.MustNewAPIRoute(`courses/restore`, HandleRestore),
 }
 
 func GetRoutes() *[]core.Route {
@@ -15,6 +15,9 @@ type QueryRequest struct {
 
 type QueryResponse struct {
 	Records []*stats.Metric `json:"results"`
+
+	// Only set when the query has an aggregation (in which case, results will be empty).
+	Aggregates []*stats.AggregateResult `json:"aggregates,omitempty"`
 }
 
 // Query metrics for a specific course.
@@ -28,6 +31,15 @@ func HandleQuery(request *QueryRequest) (*QueryResponse, *core.APIError) {
 	// The request must be for the given course.
 	request.Query.Where[stats.MetricAttributeCourseID] = request.Course.ID
 
+	if request.Query.Aggregate != nil {
+		aggregates, err := db.GetAggregateMetrics(request.Query)
+		if err != nil {
+			return nil, core.NewInternalError("-684", request, "Failed to aggregate course stats.").Err(err)
+		}
+
+		return &QueryResponse{Aggregates: aggregates}, nil
+	}
+
 	records, err := db.GetMetrics(request.Query)
 	if err != nil {
 		return nil, core.NewInternalError("-631", request, "Failed to query course stats.").Err(err)
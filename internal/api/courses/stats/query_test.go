@@ -1,6 +1,7 @@
 package stats
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/edulinq/autograder/internal/api/core"
@@ -149,6 +150,53 @@ func TestQueryContextCourse(test *testing.T) {
 	}
 }
 
+// Test that aggregations only include metrics from the context course.
+func TestQueryAggregateContextCourse(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	for _, record := range testRecords {
+		err := db.StoreMetric(record)
+		if err != nil {
+			test.Fatalf("Failed to store test record: '%v'.", err)
+		}
+	}
+
+	query := &stats.Query{
+		Type: stats.MetricTypeGradingTime,
+		Where: map[stats.MetricAttribute]any{
+			stats.MetricAttributeCourseID: "C1",
+		},
+		Aggregate: &stats.Aggregation{
+			GroupBy:   []stats.MetricAttribute{stats.MetricAttributeCourseID},
+			Functions: []stats.AggregateFunction{stats.AggregateFunctionCount, stats.AggregateFunctionSum},
+		},
+	}
+
+	var fields map[string]any
+	util.MustJSONFromString(util.MustToJSON(query), &fields)
+
+	response := core.SendTestAPIRequestFull(test, `courses/stats/query`, fields, nil, "course-admin")
+	if !response.Success {
+		test.Fatalf("Response is not a success when it should be: '%v'.", response)
+	}
+
+	var responseContent QueryResponse
+	util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+	expected := []*stats.AggregateResult{
+		&stats.AggregateResult{
+			Group:  map[stats.MetricAttribute]any{stats.MetricAttributeCourseID: db.TEST_COURSE_ID},
+			Values: map[string]float64{"count": 1, "sum": 100},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, responseContent.Aggregates) {
+		test.Fatalf("Unexpected aggregates. Expected: %s, Actual: %s.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(responseContent.Aggregates))
+	}
+}
+
 var testRecords []*stats.Metric = []*stats.Metric{
 	// Context course metrics.
 	&stats.Metric{
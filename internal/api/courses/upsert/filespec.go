@@ -18,6 +18,7 @@ type FileSpecRequest struct {
 func HandleFileSpec(request *FileSpecRequest) (*UpsertResponse, *core.APIError) {
 	options := request.CourseUpsertOptions
 	options.ContextUser = request.ServerUser
+	options.Context = request.Context
 
 	results, err := courses.UpsertFromFileSpec(&request.FileSpec, options)
 	if err != nil {
@@ -27,6 +27,7 @@ func HandleZipFile(request *ZipFileRequest) (*UpsertResponse, *core.APIError) {
 
 	options := request.CourseUpsertOptions
 	options.ContextUser = request.ServerUser
+	options.Context = request.Context
 
 	results, err := courses.UpsertFromZipFile(path, options)
 	if err != nil {
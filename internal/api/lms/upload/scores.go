@@ -51,7 +51,7 @@ func HandleUploadScores(request *UploadScoresRequest) (*UploadScoresResponse, *c
 		return &response, nil
 	}
 
-	err := lms.UpdateAssignmentScores(request.Course, string(request.AssignmentLMSID), scores)
+	err := lms.UpdateAssignmentScores(request.Context, request.Course, string(request.AssignmentLMSID), scores)
 	if err != nil {
 		return nil, core.NewInternalError("-406", request,
 			"Failed to upload LMS scores.").Err(err)
@@ -33,7 +33,7 @@ func HandleUserGet(request *UserGetRequest) (*UserGetResponse, *core.APIError) {
 	response.FoundAGUser = true
 	response.User = core.NewCourseUserInfo(request.TargetUser.User)
 
-	lmsUser, err := lms.FetchUser(request.Course, string(request.TargetUser.Email))
+	lmsUser, err := lms.FetchUser(request.Context, request.Course, string(request.TargetUser.Email))
 	if err != nil {
 		return nil, core.NewInternalError("-402", request,
 			"Failed to fetch LMS user.").Err(err).Add("target-user", string(request.TargetUser.Email))
@@ -69,6 +69,16 @@ func TestQuery(test *testing.T) {
 		// User's can only query their own logs.
 		{"server-user@test.edulinq.org", log.RawLogQuery{}, 0, true, ""},
 		{"server-user@test.edulinq.org", log.RawLogQuery{TargetUser: email}, 1, false, ""},
+
+		// Search.
+		{"server-admin@test.edulinq.org", log.RawLogQuery{Attributes: map[string]string{specialKey: specialValue}, MessageText: "WARN"}, 1, false, ""},
+		{"server-admin@test.edulinq.org", log.RawLogQuery{Attributes: map[string]string{specialKey: specialValue}, Limit: 2, Sort: 1}, 2, false, ""},
+		{"server-admin@test.edulinq.org", log.RawLogQuery{Attributes: map[string]string{specialKey: "ZZZ"}}, 0, false, ""},
+		{"server-admin@test.edulinq.org", log.RawLogQuery{Offset: -1}, 0, false, "-1100"},
+
+		// Search filters do not grant any additional permissions.
+		{"server-user@test.edulinq.org", log.RawLogQuery{Attributes: map[string]string{specialKey: specialValue}}, 0, true, ""},
+		{"course-admin@test.edulinq.org", log.RawLogQuery{CourseID: courseID, MessageText: "info"}, 2, false, ""},
 	}
 
 	for i, testCase := range testCases {
@@ -0,0 +1,28 @@
+package lti
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/lti"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// Serve the tool's public keys, which platforms use to verify the tool's service requests.
+func HandleKeys(response http.ResponseWriter, request *http.Request) error {
+	keySet, err := lti.GetToolKeySet()
+	if err != nil {
+		log.Error("Failed to get LTI key set.", err)
+		http.Error(response, "Failed to get LTI key set.", http.StatusInternalServerError)
+		return nil
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	_, err = fmt.Fprint(response, util.MustToJSON(keySet))
+	if err != nil {
+		return fmt.Errorf("Could not write LTI key set: '%w'.", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package lti
+
+import (
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/jwt"
+	"github.com/edulinq/autograder/internal/lti"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestKeys(test *testing.T) {
+	body, err := util.Get(core.GetTestServerURL() + core.MakeFullAPIPath(`lti/jwks`))
+	if err != nil {
+		test.Fatalf("Failed to get keys: '%v'.", err)
+	}
+
+	var keySet jwt.JSONWebKeySet
+	util.MustJSONFromString(body, &keySet)
+
+	expected, err := lti.GetToolKeySet()
+	if err != nil {
+		test.Fatalf("Failed to get tool key set: '%v'.", err)
+	}
+
+	if util.MustToJSON(expected) != util.MustToJSON(keySet) {
+		test.Fatalf("Unexpected key set. Expected: '%s', Actual: '%s'.", util.MustToJSON(expected), util.MustToJSON(keySet))
+	}
+
+	// Tokens signed by the tool can be verified with the served keys.
+	token, err := lti.SignToolJWT(map[string]any{"sub": "test"})
+	if err != nil {
+		test.Fatalf("Failed to sign token: '%v'.", err)
+	}
+
+	_, err = jwt.VerifyWithKeySetURL(token, core.GetTestServerURL()+core.MakeFullAPIPath(`lti/jwks`))
+	if err != nil {
+		test.Fatalf("Failed to verify tool token: '%v'.", err)
+	}
+}
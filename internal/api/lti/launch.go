@@ -0,0 +1,71 @@
+package lti
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/lti/launch"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// Finish an LTI launch (the platform posts the launch here).
+// On success, either redirect to the configured launch redirect (with the launch information in the fragment),
+// or respond with the launch result as JSON.
+func HandleLaunch(response http.ResponseWriter, request *http.Request) error {
+	err := request.ParseForm()
+	if err != nil {
+		http.Error(response, "Failed to parse LTI launch.", http.StatusBadRequest)
+		return nil
+	}
+
+	platformErr := request.PostForm.Get("error")
+	if platformErr != "" {
+		message := fmt.Sprintf("LTI platform returned an error: '%s'.", platformErr)
+		description := request.PostForm.Get("error_description")
+		if description != "" {
+			message = fmt.Sprintf("LTI platform returned an error: '%s' (%s).", platformErr, description)
+		}
+
+		http.Error(response, message, http.StatusBadRequest)
+		return nil
+	}
+
+	result, userErr, err := launch.FinishLaunch(request.PostForm.Get("state"), request.PostForm.Get("id_token"))
+	if err != nil {
+		log.Error("Failed to finish LTI launch.", err)
+		http.Error(response, "Failed to finish LTI launch.", http.StatusInternalServerError)
+		return nil
+	}
+
+	if userErr != nil {
+		http.Error(response, userErr.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	launchRedirect := config.LTI_LAUNCH_REDIRECT.Get()
+	if launchRedirect != "" {
+		fragment := url.Values{}
+		fragment.Set("email", result.Email)
+		fragment.Set("token", result.TokenCleartext)
+		fragment.Set("course", result.CourseID)
+
+		if result.AssignmentID != "" {
+			fragment.Set("assignment", result.AssignmentID)
+		}
+
+		// Use "See Other" so the browser will follow the redirect with a GET.
+		http.Redirect(response, request, launchRedirect+"#"+fragment.Encode(), http.StatusSeeOther)
+		return nil
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	_, err = fmt.Fprint(response, util.MustToJSON(result))
+	if err != nil {
+		return fmt.Errorf("Could not write LTI launch response: '%w'.", err)
+	}
+
+	return nil
+}
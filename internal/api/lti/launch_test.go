@@ -0,0 +1,198 @@
+package lti
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/lti"
+	"github.com/edulinq/autograder/internal/lti/launch"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestLaunchFlow(test *testing.T) {
+	platform, err := lti.NewTestPlatform()
+	if err != nil {
+		test.Fatalf("Failed to start test platform: '%v'.", err)
+	}
+	defer platform.Close()
+
+	defer config.LTI_LAUNCH_REDIRECT.Set(config.LTI_LAUNCH_REDIRECT.Get())
+
+	defer config.LTI_PLATFORMS.Set(config.LTI_PLATFORMS.Get())
+	config.LTI_PLATFORMS.Set(platform.Issuer() + "=" + platform.Issuer() + "/jwks")
+
+	testCases := []struct {
+		method         string
+		claims         map[string]any
+		launchRedirect string
+		status         int
+	}{
+		{"GET", map[string]any{"email": "course-student@test.edulinq.org"}, "", http.StatusOK},
+		{"POST", map[string]any{"email": "course-student@test.edulinq.org"}, "", http.StatusOK},
+		{"GET", map[string]any{"email": "course-student@test.edulinq.org"}, "http://localhost/web/", http.StatusSeeOther},
+		{"GET", map[string]any{}, "", http.StatusBadRequest},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+
+		course := db.MustGetCourse("course101")
+		course.LMS = platform.Adapter()
+		db.MustSaveCourse(course)
+
+		config.LTI_LAUNCH_REDIRECT.Set(testCase.launchRedirect)
+		platform.SetLaunchClaims(testCase.claims)
+
+		form := url.Values{}
+		form.Set("iss", platform.Issuer())
+		form.Set("login_hint", "lti-user-1")
+		form.Set("client_id", lti.TEST_CLIENT_ID)
+
+		loginURL := core.GetTestServerURL() + core.MakeFullAPIPath(`lti/login`)
+
+		var response *http.Response
+		if testCase.method == "GET" {
+			response = sendNoRedirect(test, "GET", loginURL+"?"+form.Encode(), nil)
+		} else {
+			response = sendNoRedirect(test, "POST", loginURL, form)
+		}
+
+		if response.StatusCode != http.StatusFound {
+			test.Errorf("Case %d: Login did not redirect, got status %d.", i, response.StatusCode)
+			continue
+		}
+
+		launchURL, launchForm, err := platform.Authorize(response.Header.Get("Location"))
+		if err != nil {
+			test.Errorf("Case %d: Failed to authorize: '%v'.", i, err)
+			continue
+		}
+
+		if launchURL != (core.GetTestServerURL() + core.MakeFullAPIPath(`lti/launch`)) {
+			test.Errorf("Case %d: Unexpected launch URL: '%s'.", i, launchURL)
+			continue
+		}
+
+		values := url.Values{}
+		for key, value := range launchForm {
+			values.Set(key, value)
+		}
+
+		response = sendNoRedirect(test, "POST", launchURL, values)
+		if response.StatusCode != testCase.status {
+			test.Errorf("Case %d: Unexpected launch status. Expected: %d, Actual: %d.", i, testCase.status, response.StatusCode)
+			continue
+		}
+
+		if testCase.status == http.StatusBadRequest {
+			continue
+		}
+
+		var email string
+		var token string
+
+		if testCase.status == http.StatusSeeOther {
+			location := response.Header.Get("Location")
+			if !strings.HasPrefix(location, testCase.launchRedirect+"#") {
+				test.Errorf("Case %d: Unexpected launch redirect: '%s'.", i, location)
+				continue
+			}
+
+			fragment, err := url.ParseQuery(strings.SplitN(location, "#", 2)[1])
+			if err != nil {
+				test.Errorf("Case %d: Failed to parse redirect fragment: '%v'.", i, err)
+				continue
+			}
+
+			if fragment.Get("course") != "course101" {
+				test.Errorf("Case %d: Unexpected course in redirect: '%s'.", i, location)
+				continue
+			}
+
+			email = fragment.Get("email")
+			token = fragment.Get("token")
+		} else {
+			body, err := io.ReadAll(response.Body)
+			if err != nil {
+				test.Errorf("Case %d: Failed to read launch body: '%v'.", i, err)
+				continue
+			}
+
+			var result launch.LaunchResult
+			util.MustJSONFromBytes(body, &result)
+
+			if result.CourseID != "course101" {
+				test.Errorf("Case %d: Unexpected launch result: '%s'.", i, string(body))
+				continue
+			}
+
+			email = result.Email
+			token = result.TokenCleartext
+		}
+
+		if email != "course-student@test.edulinq.org" {
+			test.Errorf("Case %d: Unexpected email: '%s'.", i, email)
+			continue
+		}
+
+		auth, err := db.MustGetServerUser(email).Auth(util.Sha256HexFromString(token))
+		if err != nil {
+			test.Errorf("Case %d: Failed to auth user: '%v'.", i, err)
+			continue
+		}
+
+		if !auth {
+			test.Errorf("Case %d: Launch token did not authenticate the user.", i)
+			continue
+		}
+	}
+
+	db.ResetForTesting()
+}
+
+func TestLaunchPlatformError(test *testing.T) {
+	form := url.Values{}
+	form.Set("error", "login_required")
+
+	response := sendNoRedirect(test, "POST", core.GetTestServerURL()+core.MakeFullAPIPath(`lti/launch`), form)
+	if response.StatusCode != http.StatusBadRequest {
+		test.Fatalf("Unexpected status. Expected: %d, Actual: %d.", http.StatusBadRequest, response.StatusCode)
+	}
+}
+
+func sendNoRedirect(test *testing.T, method string, uri string, form url.Values) *http.Response {
+	client := http.Client{
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	var body io.Reader = nil
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	request, err := http.NewRequest(method, uri, body)
+	if err != nil {
+		test.Fatalf("Failed to create request for '%s': '%v'.", uri, err)
+	}
+
+	if form != nil {
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		test.Fatalf("Failed to %s '%s': '%v'.", method, uri, err)
+	}
+
+	test.Cleanup(func() { response.Body.Close() })
+
+	return response
+}
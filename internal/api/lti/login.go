@@ -0,0 +1,42 @@
+package lti
+
+import (
+	"net/http"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/lti/launch"
+)
+
+// Handle a third-party initiated login from the platform by redirecting the user back to the platform to authenticate.
+// The platform may send the login parameters as either query parameters (GET) or a form (POST).
+func HandleLogin(response http.ResponseWriter, request *http.Request) error {
+	err := request.ParseForm()
+	if err != nil {
+		http.Error(response, "Failed to parse LTI login request.", http.StatusBadRequest)
+		return nil
+	}
+
+	loginRequest := &launch.LoginRequest{
+		Issuer:       request.Form.Get("iss"),
+		ClientID:     request.Form.Get("client_id"),
+		DeploymentID: request.Form.Get("lti_deployment_id"),
+		LoginHint:    request.Form.Get("login_hint"),
+		MessageHint:  request.Form.Get("lti_message_hint"),
+	}
+
+	authURL, userErr, err := launch.StartLogin(loginRequest, core.MakeFullAPIURL(request, `lti/launch`))
+	if err != nil {
+		log.Error("Failed to start LTI login.", err)
+		http.Error(response, "Failed to start LTI login.", http.StatusInternalServerError)
+		return nil
+	}
+
+	if userErr != nil {
+		http.Error(response, userErr.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	http.Redirect(response, request, authURL, http.StatusFound)
+	return nil
+}
@@ -0,0 +1,20 @@
+package lti
+
+// All the LTI endpoints handled by this package.
+// These are not standard API endpoints,
+// since they are visited by a user's browser (and redirected to/from the platform) or by the platform itself.
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+)
+
+var routes []core.Route = []core.Route{
+	core.NewBaseRoute("GET", core.MakeFullAPIPath(`lti/login`), HandleLogin),
+	core.NewBaseRoute("POST", core.MakeFullAPIPath(`lti/login`), HandleLogin),
+	core.NewBaseRoute("POST", core.MakeFullAPIPath(`lti/launch`), HandleLaunch),
+	core.NewBaseRoute("GET", core.MakeFullAPIPath(`lti/jwks`), HandleKeys),
+}
+
+func GetRoutes() *[]core.Route {
+	return &routes
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/edulinq/autograder/internal/api/courses"
 	"github.com/edulinq/autograder/internal/api/lms"
 	"github.com/edulinq/autograder/internal/api/logs"
+	"github.com/edulinq/autograder/internal/api/lti"
 	"github.com/edulinq/autograder/internal/api/metadata"
+	"github.com/edulinq/autograder/internal/api/sso"
 	"github.com/edulinq/autograder/internal/api/static"
 	"github.com/edulinq/autograder/internal/api/stats"
 	"github.com/edulinq/autograder/internal/api/system"
@@ -30,7 +32,9 @@ func GetRoutes() *[]core.Route {
 	routes = append(routes, *(courses.GetRoutes())...)
 	routes = append(routes, *(lms.GetRoutes())...)
 	routes = append(routes, *(logs.GetRoutes())...)
+	routes = append(routes, *(lti.GetRoutes())...)
 	routes = append(routes, *(metadata.GetRoutes())...)
+	routes = append(routes, *(sso.GetRoutes())...)
 	routes = append(routes, *(stats.GetRoutes())...)
 	routes = append(routes, *(system.GetRoutes())...)
 	routes = append(routes, *(users.GetRoutes())...)
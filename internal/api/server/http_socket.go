@@ -12,6 +12,7 @@ import (
 	"sync"
 
 	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/api/stats"
 	"github.com/edulinq/autograder/internal/config"
 	"github.com/edulinq/autograder/internal/lockmanager"
 	"github.com/edulinq/autograder/internal/log"
@@ -19,6 +20,7 @@ import (
 
 var apiServer *http.Server = nil
 var httpRedirectServer *http.Server = nil
+var exportServer *http.Server = nil
 
 var portRegex = regexp.MustCompile(`:\d+$`)
 
@@ -110,6 +112,25 @@ func createAPIServer(routes *[]core.Route) error {
 		}
 	}
 
+	// Setup a separate server for exported metrics.
+	exportPort := config.STATS_EXPORT_PORT.Get()
+	if config.STATS_EXPORT_ENABLE.Get() && (exportPort > 0) {
+		logAttrs = append(logAttrs, log.NewAttr("export-port", exportPort))
+
+		mux := http.NewServeMux()
+		mux.HandleFunc(stats.EXPORT_PATH, func(response http.ResponseWriter, request *http.Request) {
+			err := stats.ServeExport(response, request)
+			if err != nil {
+				log.Error("Failed to serve exported metrics.", err)
+			}
+		})
+
+		exportServer = &http.Server{
+			Addr:    fmt.Sprintf(":%d", exportPort),
+			Handler: mux,
+		}
+	}
+
 	logAttrs = append(logAttrs, log.NewAttr("port", mainPort))
 
 	log.Info("API Server Created.", logAttrs...)
@@ -127,6 +148,15 @@ func runAPIServerInternal() error {
 		}()
 	}
 
+	if exportServer != nil {
+		go func() {
+			err := exportServer.ListenAndServe()
+			if (err != nil) && (err != http.ErrServerClosed) {
+				log.Error("Metrics export server returned an error.", err)
+			}
+		}()
+	}
+
 	var err error = nil
 
 	if config.WEB_HTTPS_ENABLE.Get() {
@@ -192,6 +222,13 @@ func stopAPIServer() {
 		}
 	}
 
+	if exportServer != nil {
+		err := exportServer.Shutdown(context.Background())
+		if err != nil {
+			log.Error("Failed to stop the metrics export server.", err)
+		}
+	}
+
 	if apiServer != nil {
 		err := apiServer.Shutdown(context.Background())
 		if err != nil {
@@ -200,5 +237,6 @@ func stopAPIServer() {
 	}
 
 	httpRedirectServer = nil
+	exportServer = nil
 	apiServer = nil
 }
@@ -0,0 +1,66 @@
+package sso
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/oidc"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// Finish a single sign-on login (the provider sends the user here).
+// On success, either redirect to the configured login redirect (with the email and token in the fragment),
+// or respond with the login result as JSON.
+func HandleCallback(response http.ResponseWriter, request *http.Request) error {
+	if !oidc.IsEnabled() {
+		http.NotFound(response, request)
+		return nil
+	}
+
+	query := request.URL.Query()
+
+	providerErr := query.Get("error")
+	if providerErr != "" {
+		message := fmt.Sprintf("Single sign-on provider returned an error: '%s'.", providerErr)
+		description := query.Get("error_description")
+		if description != "" {
+			message = fmt.Sprintf("Single sign-on provider returned an error: '%s' (%s).", providerErr, description)
+		}
+
+		http.Error(response, message, http.StatusBadRequest)
+		return nil
+	}
+
+	result, userErr, err := oidc.FinishLogin(query.Get("state"), query.Get("code"))
+	if err != nil {
+		log.Error("Failed to finish single sign-on login.", err)
+		http.Error(response, "Failed to finish single sign-on login.", http.StatusInternalServerError)
+		return nil
+	}
+
+	if userErr != nil {
+		http.Error(response, userErr.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	loginRedirect := config.OIDC_LOGIN_REDIRECT.Get()
+	if loginRedirect != "" {
+		fragment := url.Values{}
+		fragment.Set("email", result.Email)
+		fragment.Set("token", result.TokenCleartext)
+
+		http.Redirect(response, request, loginRedirect+"#"+fragment.Encode(), http.StatusFound)
+		return nil
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	_, err = fmt.Fprint(response, util.MustToJSON(result))
+	if err != nil {
+		return fmt.Errorf("Could not write single sign-on login response: '%w'.", err)
+	}
+
+	return nil
+}
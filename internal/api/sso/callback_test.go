@@ -0,0 +1,150 @@
+package sso
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/oidc"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestLoginFlow(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	provider, err := oidc.NewTestProvider()
+	if err != nil {
+		test.Fatalf("Failed to start test provider: '%v'.", err)
+	}
+	defer provider.Close()
+
+	defer provider.SetConfig()()
+	defer config.OIDC_LOGIN_REDIRECT.Set(config.OIDC_LOGIN_REDIRECT.Get())
+
+	testCases := []struct {
+		claims        map[string]any
+		loginRedirect string
+		status        int
+		email         string
+	}{
+		{map[string]any{"email": "course-student@test.edulinq.org", "email_verified": true}, "", http.StatusOK, "course-student@test.edulinq.org"},
+		{map[string]any{"email": "course-student@test.edulinq.org", "email_verified": true}, "http://localhost/web/", http.StatusFound, "course-student@test.edulinq.org"},
+		{map[string]any{"email": "new-user@test.edulinq.org", "email_verified": true}, "", http.StatusOK, "new-user@test.edulinq.org"},
+		{map[string]any{"email": "course-student@test.edulinq.org", "email_verified": false}, "", http.StatusBadRequest, ""},
+		{map[string]any{"email": "course-student@test.edulinq.org"}, "", http.StatusBadRequest, ""},
+	}
+
+	for i, testCase := range testCases {
+		config.OIDC_LOGIN_REDIRECT.Set(testCase.loginRedirect)
+		provider.SetClaims(testCase.claims)
+
+		response := getNoRedirect(test, core.GetTestServerURL()+core.MakeFullAPIPath(`sso/login`))
+		if response.StatusCode != http.StatusFound {
+			test.Errorf("Case %d: Login did not redirect, got status %d.", i, response.StatusCode)
+			continue
+		}
+
+		callbackURL, err := provider.Authorize(response.Header.Get("Location"))
+		if err != nil {
+			test.Errorf("Case %d: Failed to authorize: '%v'.", i, err)
+			continue
+		}
+
+		response = getNoRedirect(test, callbackURL)
+		if response.StatusCode != testCase.status {
+			test.Errorf("Case %d: Unexpected callback status. Expected: %d, Actual: %d.", i, testCase.status, response.StatusCode)
+			continue
+		}
+
+		if testCase.status == http.StatusBadRequest {
+			continue
+		}
+
+		var email string
+		var token string
+
+		if testCase.status == http.StatusFound {
+			location := response.Header.Get("Location")
+			if !strings.HasPrefix(location, testCase.loginRedirect+"#") {
+				test.Errorf("Case %d: Unexpected login redirect: '%s'.", i, location)
+				continue
+			}
+
+			fragment, err := url.ParseQuery(strings.SplitN(location, "#", 2)[1])
+			if err != nil {
+				test.Errorf("Case %d: Failed to parse redirect fragment: '%v'.", i, err)
+				continue
+			}
+
+			email = fragment.Get("email")
+			token = fragment.Get("token")
+		} else {
+			body, err := io.ReadAll(response.Body)
+			if err != nil {
+				test.Errorf("Case %d: Failed to read callback body: '%v'.", i, err)
+				continue
+			}
+
+			var result oidc.LoginResult
+			util.MustJSONFromBytes(body, &result)
+
+			email = result.Email
+			token = result.TokenCleartext
+		}
+
+		if email != testCase.email {
+			test.Errorf("Case %d: Unexpected email. Expected: '%s', Actual: '%s'.", i, testCase.email, email)
+			continue
+		}
+
+		// The session token should authenticate the user.
+		user := db.MustGetServerUser(email)
+		if user == nil {
+			test.Errorf("Case %d: Could not find logged in user '%s'.", i, email)
+			continue
+		}
+
+		auth, err := user.Auth(util.Sha256HexFromString(token))
+		if err != nil {
+			test.Errorf("Case %d: Failed to auth user: '%v'.", i, err)
+			continue
+		}
+
+		if !auth {
+			test.Errorf("Case %d: Session token did not authenticate the user.", i)
+			continue
+		}
+	}
+}
+
+func TestLoginDisabled(test *testing.T) {
+	for _, endpoint := range []string{`sso/login`, `sso/callback`} {
+		response := getNoRedirect(test, core.GetTestServerURL()+core.MakeFullAPIPath(endpoint))
+		if response.StatusCode != http.StatusNotFound {
+			test.Errorf("Endpoint '%s': Unexpected status. Expected: %d, Actual: %d.", endpoint, http.StatusNotFound, response.StatusCode)
+		}
+	}
+}
+
+func getNoRedirect(test *testing.T, uri string) *http.Response {
+	client := http.Client{
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	response, err := client.Get(uri)
+	if err != nil {
+		test.Fatalf("Failed to GET '%s': '%v'.", uri, err)
+	}
+
+	test.Cleanup(func() { response.Body.Close() })
+
+	return response
+}
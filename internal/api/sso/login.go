@@ -0,0 +1,39 @@
+package sso
+
+import (
+	"net/http"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/oidc"
+)
+
+// Start a single sign-on login by redirecting the user to the provider.
+func HandleLogin(response http.ResponseWriter, request *http.Request) error {
+	if !oidc.IsEnabled() {
+		http.NotFound(response, request)
+		return nil
+	}
+
+	authURL, err := oidc.StartLogin(getRedirectURL(request))
+	if err != nil {
+		log.Error("Failed to start single sign-on login.", err)
+		http.Error(response, "Failed to start single sign-on login.", http.StatusInternalServerError)
+		return nil
+	}
+
+	http.Redirect(response, request, authURL, http.StatusFound)
+	return nil
+}
+
+// Get the URL the provider should send the user back to.
+// Use the configured URL if set, otherwise the callback endpoint on the host this request was made to.
+func getRedirectURL(request *http.Request) string {
+	redirectURL := config.OIDC_REDIRECT_URL.Get()
+	if redirectURL != "" {
+		return redirectURL
+	}
+
+	return core.MakeFullAPIURL(request, `sso/callback`)
+}
@@ -0,0 +1,18 @@
+package sso
+
+// All the single sign-on endpoints handled by this package.
+// These are not standard API endpoints,
+// since they are visited by a user's browser (and redirected to/from the provider).
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+)
+
+var routes []core.Route = []core.Route{
+	core.NewBaseRoute("GET", core.MakeFullAPIPath(`sso/login`), HandleLogin),
+	core.NewBaseRoute("GET", core.MakeFullAPIPath(`sso/callback`), HandleCallback),
+}
+
+func GetRoutes() *[]core.Route {
+	return &routes
+}
@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/stats"
+)
+
+const EXPORT_PATH = `/metrics`
+
+// Serve live metrics (for Prometheus and friends) on the main API server.
+// This is only available when exporting is enabled and a separate export port is not set.
+func HandleExport(response http.ResponseWriter, request *http.Request) error {
+	if !config.STATS_EXPORT_ENABLE.Get() || (config.STATS_EXPORT_PORT.Get() > 0) {
+		http.NotFound(response, request)
+		return nil
+	}
+
+	return ServeExport(response, request)
+}
+
+// Serve live metrics in the Prometheus text format,
+// or the OpenMetrics text format if the requester asks for it.
+// If config.STATS_EXPORT_TOKEN is set, the request must have a matching bearer token.
+func ServeExport(response http.ResponseWriter, request *http.Request) error {
+	token := config.STATS_EXPORT_TOKEN.Get()
+	if token != "" {
+		actual, found := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+		if !found || (subtle.ConstantTimeCompare([]byte(token), []byte(strings.TrimSpace(actual))) != 1) {
+			response.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(response, "Missing or invalid metrics token.", http.StatusUnauthorized)
+			return nil
+		}
+	}
+
+	openMetrics := strings.Contains(request.Header.Get("Accept"), "application/openmetrics-text")
+
+	if openMetrics {
+		response.Header().Set("Content-Type", stats.EXPORT_CONTENT_TYPE_OPENMETRICS)
+	} else {
+		response.Header().Set("Content-Type", stats.EXPORT_CONTENT_TYPE_TEXT)
+	}
+
+	return stats.WriteExport(response, openMetrics)
+}
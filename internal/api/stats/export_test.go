@@ -0,0 +1,98 @@
+package stats
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/stats"
+)
+
+func TestHandleExport(test *testing.T) {
+	defer config.STATS_EXPORT_ENABLE.Set(config.STATS_EXPORT_ENABLE.Get())
+	defer config.STATS_EXPORT_PORT.Set(config.STATS_EXPORT_PORT.Get())
+	defer config.STATS_EXPORT_TOKEN.Set(config.STATS_EXPORT_TOKEN.Get())
+
+	// Make sure there is at least one API request metric (the request itself does not need to succeed).
+	core.SendTestAPIRequestFull(test, `stats/query`, nil, nil, "server-admin")
+
+	testCases := []struct {
+		enable      bool
+		port        int
+		token       string
+		auth        string
+		accept      string
+		status      int
+		contentType string
+		expected    []string
+	}{
+		{true, 0, "", "", "", http.StatusOK, stats.EXPORT_CONTENT_TYPE_TEXT, []string{"# TYPE autograder_api_request_duration_seconds histogram", `endpoint="/api/v03/stats/query"`, "autograder_grading_in_flight 0"}},
+		{true, 0, "", "", "application/openmetrics-text", http.StatusOK, stats.EXPORT_CONTENT_TYPE_OPENMETRICS, []string{"# EOF"}},
+		{true, 0, "secret", "Bearer secret", "", http.StatusOK, stats.EXPORT_CONTENT_TYPE_TEXT, []string{"autograder_api_request_duration_seconds_count"}},
+
+		// Bad tokens.
+		{true, 0, "secret", "", "", http.StatusUnauthorized, "", nil},
+		{true, 0, "secret", "Bearer ZZZ", "", http.StatusUnauthorized, "", nil},
+		{true, 0, "secret", "secret", "", http.StatusUnauthorized, "", nil},
+
+		// Not on the main server.
+		{false, 0, "", "", "", http.StatusNotFound, "", nil},
+		{true, 12345, "", "", "", http.StatusNotFound, "", nil},
+	}
+
+	for i, testCase := range testCases {
+		config.STATS_EXPORT_ENABLE.Set(testCase.enable)
+		config.STATS_EXPORT_PORT.Set(testCase.port)
+		config.STATS_EXPORT_TOKEN.Set(testCase.token)
+
+		request, err := http.NewRequest("GET", core.GetTestServerURL()+EXPORT_PATH, nil)
+		if err != nil {
+			test.Fatalf("Case %d: Failed to create request: '%v'.", i, err)
+		}
+
+		if testCase.auth != "" {
+			request.Header.Set("Authorization", testCase.auth)
+		}
+
+		if testCase.accept != "" {
+			request.Header.Set("Accept", testCase.accept)
+		}
+
+		httpResponse, err := http.DefaultClient.Do(request)
+		if err != nil {
+			test.Errorf("Case %d: Failed to send request: '%v'.", i, err)
+			continue
+		}
+
+		body, err := io.ReadAll(httpResponse.Body)
+		httpResponse.Body.Close()
+		if err != nil {
+			test.Errorf("Case %d: Failed to read response: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.status != httpResponse.StatusCode {
+			test.Errorf("Case %d: Unexpected status. Expected: '%d', Actual: '%d'.", i, testCase.status, httpResponse.StatusCode)
+			continue
+		}
+
+		if testCase.status != http.StatusOK {
+			continue
+		}
+
+		contentType := httpResponse.Header.Get("Content-Type")
+		if testCase.contentType != contentType {
+			test.Errorf("Case %d: Unexpected content type. Expected: '%s', Actual: '%s'.", i, testCase.contentType, contentType)
+			continue
+		}
+
+		for _, expected := range testCase.expected {
+			if !strings.Contains(string(body), expected) {
+				test.Errorf("Case %d: Export is missing expected text '%s'. Export: '%s'.", i, expected, string(body))
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package stats
+
+import (
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/procedures/retention"
+)
+
+type PurgeRequest struct {
+	core.APIRequestUserContext
+	core.MinServerRoleAdmin
+
+	// If true, only report what would be removed and downsampled.
+	DryRun bool `json:"dry-run"`
+}
+
+type PurgeResponse struct {
+	retention.Result
+}
+
+// Remove and downsample old stats and logs according to the server's retention config.
+func HandlePurge(request *PurgeRequest) (*PurgeResponse, *core.APIError) {
+	result, err := retention.RunFromConfig(request.DryRun)
+	if err != nil {
+		return nil, core.NewInternalError("-304", request, "Failed to apply stats and log retention.").Err(err)
+	}
+
+	return &PurgeResponse{*result}, nil
+}
@@ -0,0 +1,108 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/api/core"
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/procedures/retention"
+	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestPurge(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	defer config.STATS_RETENTION_DAYS.Set(config.STATS_RETENTION_DAYS.Get())
+	config.STATS_RETENTION_DAYS.Set(1)
+
+	for _, record := range testRecords {
+		err := db.StoreMetric(record)
+		if err != nil {
+			test.Fatalf("Failed to store test record: '%v'.", err)
+		}
+	}
+
+	testCases := []struct {
+		email           string
+		expectedLocator string
+		dryRun          bool
+		expected        *retention.Result
+		expectedCount   int
+	}{
+		{
+			email:           "server-user",
+			expectedLocator: "-041",
+			expectedCount:   3,
+		},
+		{
+			email:  "server-admin",
+			dryRun: true,
+			expected: &retention.Result{
+				DryRun: true,
+				Metrics: map[stats.MetricType]*retention.MetricResult{
+					stats.MetricTypeGradingTime: &retention.MetricResult{Removed: 3},
+				},
+				Logs: map[string]int{},
+			},
+			expectedCount: 3,
+		},
+		{
+			email: "server-admin",
+			expected: &retention.Result{
+				Metrics: map[stats.MetricType]*retention.MetricResult{
+					stats.MetricTypeGradingTime: &retention.MetricResult{Removed: 3},
+				},
+				Logs: map[string]int{},
+			},
+			expectedCount: 0,
+		},
+	}
+
+	for i, testCase := range testCases {
+		fields := map[string]any{
+			"dry-run": testCase.dryRun,
+		}
+
+		response := core.SendTestAPIRequestFull(test, `stats/purge`, fields, nil, testCase.email)
+		if !response.Success {
+			if testCase.expectedLocator != "" {
+				if testCase.expectedLocator != response.Locator {
+					test.Errorf("Case %d: Incorrect locator. Expected: '%s', Actual: '%s'.", i, testCase.expectedLocator, response.Locator)
+				}
+			} else {
+				test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response)
+			}
+		} else if testCase.expectedLocator != "" {
+			test.Errorf("Case %d: Unexpected success when locator '%s' was expected.", i, testCase.expectedLocator)
+		} else {
+			var responseContent PurgeResponse
+			util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+			// Only check grading time metrics, other metrics (e.g., API requests) are also being stored.
+			actual := &responseContent.Result
+			for metricType := range actual.Metrics {
+				if metricType != stats.MetricTypeGradingTime {
+					delete(actual.Metrics, metricType)
+				}
+			}
+
+			if !reflect.DeepEqual(testCase.expected, actual) {
+				test.Errorf("Case %d: Unexpected result. Expected: '%s', Actual: '%s'.",
+					i, util.MustToJSONIndent(testCase.expected), util.MustToJSONIndent(actual))
+			}
+		}
+
+		metrics, err := db.GetMetrics(stats.Query{Type: stats.MetricTypeGradingTime})
+		if err != nil {
+			test.Fatalf("Case %d: Failed to get metrics: '%v'.", i, err)
+		}
+
+		if testCase.expectedCount != len(metrics) {
+			test.Errorf("Case %d: Unexpected number of metrics. Expected: %d, Actual: %d.", i, testCase.expectedCount, len(metrics))
+		}
+	}
+}
@@ -20,6 +20,9 @@ type QueryRequest struct {
 
 type QueryResponse struct {
 	Records []*stats.Metric `json:"results"`
+
+	// Only set when the query has an aggregation (in which case, results will be empty).
+	Aggregates []*stats.AggregateResult `json:"aggregates,omitempty"`
 }
 
 // Query stats for the server.
@@ -30,6 +33,15 @@ func HandleQuery(request *QueryRequest) (*QueryResponse, *core.APIError) {
 		return nil, core.NewBadRequestError("-301", request, message).Err(err)
 	}
 
+	if request.Query.Aggregate != nil {
+		aggregates, err := db.GetAggregateMetricsFull(request.Query, request.UseTestingData)
+		if err != nil {
+			return nil, core.NewInternalError("-303", request, "Failed to aggregate stats.").Err(err)
+		}
+
+		return &QueryResponse{Aggregates: aggregates}, nil
+	}
+
 	records, err := db.GetMetricsFull(request.Query, request.UseTestingData)
 	if err != nil {
 		return nil, core.NewInternalError("-302", request, "Failed to query stats.").Err(err)
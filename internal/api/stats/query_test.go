@@ -561,6 +561,151 @@ func TestQueryTestingMetrics(test *testing.T) {
 	}
 }
 
+func TestQueryAggregate(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	for _, record := range testRecords {
+		err := db.StoreMetric(record)
+		if err != nil {
+			test.Fatalf("Failed to store test record: '%v'.", err)
+		}
+	}
+
+	testCases := []struct {
+		email           string
+		expectedLocator string
+		query           *stats.Query
+		expected        []*stats.AggregateResult
+	}{
+		// Default function (count).
+		{
+			email: "server-admin",
+			query: &stats.Query{
+				Type:      stats.MetricTypeGradingTime,
+				Aggregate: &stats.Aggregation{},
+			},
+			expected: []*stats.AggregateResult{
+				&stats.AggregateResult{
+					Values: map[string]float64{"count": 3},
+				},
+			},
+		},
+
+		// Group by user.
+		{
+			email: "server-admin",
+			query: &stats.Query{
+				Type: stats.MetricTypeGradingTime,
+				Aggregate: &stats.Aggregation{
+					GroupBy:     []stats.MetricAttribute{stats.MetricAttributeUserEmail},
+					Functions:   []stats.AggregateFunction{stats.AggregateFunctionMean, stats.AggregateFunctionMax},
+					Percentiles: []float64{50},
+				},
+			},
+			expected: []*stats.AggregateResult{
+				&stats.AggregateResult{
+					Group:  map[stats.MetricAttribute]any{stats.MetricAttributeUserEmail: "U1"},
+					Values: map[string]float64{"mean": 150, "max": 200, "p50": 150},
+				},
+				&stats.AggregateResult{
+					Group:  map[stats.MetricAttribute]any{stats.MetricAttributeUserEmail: "U2"},
+					Values: map[string]float64{"mean": 300, "max": 300, "p50": 300},
+				},
+			},
+		},
+
+		// Filter and limit.
+		{
+			email: "server-admin",
+			query: &stats.Query{
+				Type:  stats.MetricTypeGradingTime,
+				After: timestamp.FromMSecs(150),
+				Limit: 1,
+				Aggregate: &stats.Aggregation{
+					GroupBy:   []stats.MetricAttribute{stats.MetricAttributeCourseID},
+					Functions: []stats.AggregateFunction{stats.AggregateFunctionSum},
+				},
+			},
+			expected: []*stats.AggregateResult{
+				&stats.AggregateResult{
+					Group:  map[stats.MetricAttribute]any{stats.MetricAttributeCourseID: "C2"},
+					Values: map[string]float64{"sum": 200},
+				},
+			},
+		},
+
+		// Bad aggregations.
+		{
+			email:           "server-admin",
+			expectedLocator: "-301",
+			query: &stats.Query{
+				Type: stats.MetricTypeGradingTime,
+				Aggregate: &stats.Aggregation{
+					TimeBucket: "zzz",
+				},
+			},
+		},
+		{
+			email:           "server-admin",
+			expectedLocator: "-301",
+			query: &stats.Query{
+				Type: stats.MetricTypeGradingTime,
+				Aggregate: &stats.Aggregation{
+					Percentiles: []float64{101},
+				},
+			},
+		},
+
+		// Permissions.
+		{
+			email:           "server-user",
+			expectedLocator: "-041",
+			query: &stats.Query{
+				Type:      stats.MetricTypeGradingTime,
+				Aggregate: &stats.Aggregation{},
+			},
+		},
+	}
+
+	for i, testCase := range testCases {
+		var fields map[string]any
+		util.MustJSONFromString(util.MustToJSON(testCase.query), &fields)
+
+		response := core.SendTestAPIRequestFull(test, `stats/query`, fields, nil, testCase.email)
+		if !response.Success {
+			if testCase.expectedLocator != "" {
+				if testCase.expectedLocator != response.Locator {
+					test.Errorf("Case %d: Incorrect locator. Expected: '%s', Actual: '%s'.", i, testCase.expectedLocator, response.Locator)
+				}
+			} else {
+				test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response)
+			}
+
+			continue
+		}
+
+		if testCase.expectedLocator != "" {
+			test.Errorf("Case %d: Unexpected success when locator '%s' was expected.", i, testCase.expectedLocator)
+			continue
+		}
+
+		var responseContent QueryResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+		if len(responseContent.Records) != 0 {
+			test.Errorf("Case %d: Got records for an aggregate query: '%s'.", i, util.MustToJSONIndent(responseContent.Records))
+			continue
+		}
+
+		if !reflect.DeepEqual(testCase.expected, responseContent.Aggregates) {
+			test.Errorf("Case %d: Unexpected aggregates. Expected: %s, Actual: %s.",
+				i, util.MustToJSONIndent(testCase.expected), util.MustToJSONIndent(responseContent.Aggregates))
+			continue
+		}
+	}
+}
+
 var testRecords []*stats.Metric = []*stats.Metric{
 	&stats.Metric{
 		Timestamp: timestamp.FromMSecs(100),
@@ -9,7 +9,11 @@ import (
 func GetRoutes() *[]core.Route {
 	routes := make([]core.Route, 0)
 
+	routes = append(routes, core.MustNewAPIRoute(`stats/purge`, HandlePurge))
 	routes = append(routes, core.MustNewAPIRoute(`stats/query`, HandleQuery))
 
+	// Scraped by monitoring systems (e.g., Prometheus), so this is not a standard API endpoint.
+	routes = append(routes, core.NewBaseRoute("GET", EXPORT_PATH, HandleExport))
+
 	return &routes
 }
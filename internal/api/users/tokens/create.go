@@ -1,9 +1,12 @@
 package tokens
 
 import (
+	"fmt"
+
 	"github.com/edulinq/autograder/internal/api/core"
 	"github.com/edulinq/autograder/internal/db"
 	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
 )
 
 type CreateRequest struct {
@@ -13,6 +16,12 @@ type CreateRequest struct {
 	TargetUser core.TargetServerUserSelfOrAdmin `json:"target-user"`
 
 	Name string `json:"name"`
+
+	// When the token stops working (the token never expires if not set).
+	ExpirationTime *timestamp.Timestamp `json:"expiration-time"`
+
+	// Limit what the token can be used for (the token can do anything the user can if not set).
+	Scope *model.TokenScope `json:"scope"`
 }
 
 type CreateResponse struct {
@@ -22,6 +31,7 @@ type CreateResponse struct {
 }
 
 // Create a new authentication token.
+// Tokens may optionally expire and be limited to a scope (e.g., read-only, submit-only, specific courses, or specific endpoints).
 func HandleCreate(request *CreateRequest) (*CreateResponse, *core.APIError) {
 	response := CreateResponse{}
 
@@ -31,7 +41,19 @@ func HandleCreate(request *CreateRequest) (*CreateResponse, *core.APIError) {
 
 	response.FoundUser = true
 
-	token, cleartext, err := request.TargetUser.User.CreateRandomToken(request.Name, model.TokenSourceUser)
+	if (request.ExpirationTime != nil) && (*request.ExpirationTime <= request.Timestamp) {
+		return nil, core.NewBadRequestError("-816", request,
+			fmt.Sprintf("Token expiration time (%s) is not in the future.", request.ExpirationTime.SafeString()))
+	}
+
+	if request.Scope != nil {
+		err := request.Scope.Validate()
+		if err != nil {
+			return nil, core.NewBadRequestError("-817", request, "Token scope is not valid.").Err(err)
+		}
+	}
+
+	token, cleartext, err := request.TargetUser.User.CreateRandomTokenFull(request.Name, model.TokenSourceUser, request.ExpirationTime, request.Scope)
 	if err != nil {
 		return nil, core.NewInternalError("-801", request,
 			"Failed to create random user token.").Err(err)
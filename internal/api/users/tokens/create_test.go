@@ -7,6 +7,7 @@ import (
 	"github.com/edulinq/autograder/internal/api/core"
 	"github.com/edulinq/autograder/internal/db"
 	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -152,3 +153,73 @@ func TestCreateBase(test *testing.T) {
 		}
 	}
 }
+
+func TestCreateExpirationAndScope(test *testing.T) {
+	defer db.ResetForTesting()
+
+	future := timestamp.Now() + 1000000
+
+	testCases := []struct {
+		expirationTime any
+		scope          any
+		locator        string
+	}{
+		{nil, nil, ""},
+		{future, nil, ""},
+		{nil, map[string]any{"read-only": true, "courses": []string{"course101"}}, ""},
+		{future, map[string]any{"submit-only": true}, ""},
+
+		// An empty scope is the same as no scope.
+		{nil, map[string]any{}, ""},
+
+		// Errors.
+		{1, nil, "-816"},
+		{nil, map[string]any{"read-only": true, "submit-only": true}, "-817"},
+		{nil, map[string]any{"courses": []string{"bad id!"}}, "-817"},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+
+		fields := map[string]any{
+			"name":            "test",
+			"expiration-time": testCase.expirationTime,
+			"scope":           testCase.scope,
+		}
+
+		response := core.SendTestAPIRequestFull(test, "users/tokens/create", fields, nil, "course-student@test.edulinq.org")
+		if !response.Success {
+			if testCase.locator != response.Locator {
+				test.Errorf("Case %d: Incorrect error returned. Expected: '%s', Actual: '%s'.",
+					i, testCase.locator, response.Locator)
+			}
+
+			continue
+		}
+
+		if testCase.locator != "" {
+			test.Errorf("Case %d: Did not get an expected error. Expected: '%s'", i, testCase.locator)
+			continue
+		}
+
+		var responseContent CreateResponse
+		util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent)
+
+		var expectedScope *model.TokenScope = nil
+		if (testCase.scope != nil) && (len(testCase.scope.(map[string]any)) > 0) {
+			expectedScope = &model.TokenScope{}
+			util.MustJSONFromString(util.MustToJSON(testCase.scope), expectedScope)
+		}
+
+		if util.MustToJSON(expectedScope) != util.MustToJSON(responseContent.TokenInfo.Scope) {
+			test.Errorf("Case %d: Unexpected scope. Expected: '%s', Actual: '%s'.",
+				i, util.MustToJSON(expectedScope), util.MustToJSON(responseContent.TokenInfo.Scope))
+			continue
+		}
+
+		if (testCase.expirationTime == nil) != (responseContent.TokenInfo.ExpirationTime == nil) {
+			test.Errorf("Case %d: Unexpected expiration time: '%s'.", i, responseContent.TokenInfo.ExpirationTime.SafeString())
+			continue
+		}
+	}
+}
@@ -10,13 +10,14 @@ import (
 const (
 	WORK_DIR_BASENAME = "autograder"
 
-	BACKUP_DIRNAME    = "backup"
-	CACHE_DIRNAME     = "cache"
-	CONFIG_DIRNAME    = "config"
-	DATABASE_DIRNAME  = "database"
-	LOGS_DIRNAME      = "logs"
-	SOURCES_DIRNAME   = "sources"
-	TEMPLATES_DIRNAME = "templates"
+	BACKUP_DIRNAME        = "backup"
+	CACHE_DIRNAME         = "cache"
+	CONFIG_DIRNAME        = "config"
+	DATABASE_DIRNAME      = "database"
+	GRADING_QUEUE_DIRNAME = "grading-queue"
+	LOGS_DIRNAME          = "logs"
+	SOURCES_DIRNAME       = "sources"
+	TEMPLATES_DIRNAME     = "templates"
 
 	TESTDATA_DIRNAME = "testdata"
 )
@@ -67,6 +68,10 @@ func GetDatabaseDir() string {
 	return filepath.Join(GetWorkDir(), DATABASE_DIRNAME)
 }
 
+func GetGradingQueueDir() string {
+	return filepath.Join(GetWorkDir(), GRADING_QUEUE_DIRNAME)
+}
+
 func GetLogsDir() string {
 	return filepath.Join(GetWorkDir(), LOGS_DIRNAME)
 }
@@ -1,5 +1,9 @@
 package config
 
+import (
+	"runtime"
+)
+
 var (
 	// Base
 	NAME = MustNewStringOption("instance.name", "autograder",
@@ -20,11 +24,25 @@ var (
 	CI_DOCKER_BUILD   = MustNewBoolOption("ci.docker.build", false, "Inform the system that we are running in a Docker build step of a CI. There are some tests that are flaky in this environment.")
 
 	// Logging
-	LOG_TEXT_LEVEL    = MustNewStringOption("log.text.level", "INFO", "The default logging level for the text (stderr) logger.")
-	LOG_BACKEND_LEVEL = MustNewStringOption("log.backend.level", "INFO", "The default logging level for the backend (database) logger.")
+	LOG_TEXT_LEVEL       = MustNewStringOption("log.text.level", "INFO", "The default logging level for the text (stderr) logger.")
+	LOG_BACKEND_LEVEL    = MustNewStringOption("log.backend.level", "INFO", "The default logging level for the backend (database) logger.")
+	LOG_RETENTION_DAYS   = MustNewIntOption("log.retention.days", 0, "Remove log records older than this number of days. Zero or less means to keep log records forever.")
+	LOG_RETENTION_LEVELS = MustNewStringOption("log.retention.levels", "", "Per-level overrides of log.retention.days as a comma-separated list of 'level=days' (e.g., 'TRACE=1,DEBUG=7'). Zero or less days means to keep records of that level forever.")
 
 	// Stats
-	STATS_SYSTEM_INTERVAL_MS = MustNewIntOption("stats.system.interval", 60*1000, "The number of milliseconds between system stats collection events.")
+	STATS_SYSTEM_INTERVAL_MS      = MustNewIntOption("stats.system.interval", 60*1000, "The number of milliseconds between system stats collection events.")
+	STATS_EXPORT_ENABLE           = MustNewBoolOption("stats.export.enable", false, "Serve live metrics in the Prometheus/OpenMetrics text format at /metrics.")
+	STATS_EXPORT_PORT             = MustNewIntOption("stats.export.port", 0, "Serve exported metrics on this port instead of on the main API server. Zero or less means to use the main API server.")
+	STATS_EXPORT_TOKEN            = MustNewStringOption("stats.export.token", "", "If set, requests for exported metrics must include this value as a bearer token.")
+	STATS_RETENTION_DAYS          = MustNewIntOption("stats.retention.days", 0, "Remove metrics older than this number of days. Zero or less means to keep metrics forever.")
+	STATS_RETENTION_TYPES         = MustNewStringOption("stats.retention.types", "", "Per-type overrides of stats.retention.days as a comma-separated list of 'type=days' (e.g., 'api-request=30,cpu-usage=7'). Zero or less days means to keep metrics of that type forever.")
+	STATS_ROLLUP_HOURLY_DAYS      = MustNewIntOption("stats.rollup.hourly", 0, "Downsample metrics older than this number of days into hourly rollups. Zero or less disables hourly rollups.")
+	STATS_ROLLUP_DAILY_DAYS       = MustNewIntOption("stats.rollup.daily", 0, "Downsample metrics older than this number of days into daily rollups. Zero or less disables daily rollups.")
+	STATS_RETENTION_INTERVAL_SECS = MustNewIntOption("stats.retention.interval", 6*60*60, "The number of seconds between background runs of stats and log retention (removal and downsampling). Zero or less disables the background runs.")
+
+	// Tracing
+	TRACING_ENABLE        = MustNewBoolOption("tracing.enable", false, "Record traces of API requests, grading, and analysis and export them to an OTLP collector.")
+	TRACING_OTLP_ENDPOINT = MustNewStringOption("tracing.otlp.endpoint", "http://localhost:4318/v1/traces", "The URL of the OTLP/HTTP collector to export traces to. An 'http' URL will not use TLS.")
 
 	// Email
 	EMAIL_FROM                 = MustNewStringOption("email.from", "", "From address for emails sent from the autograder.")
@@ -40,7 +58,12 @@ var (
 	DOCKER_MAX_OUTPUT_SIZE_KB = MustNewIntOption("docker.output.maxsize", 4*1024, "The maximum allowed size (in KB) for stdout and stderr combined. The default is 4096 KB (4 MB).")
 
 	// Grading
-	GRADING_RUNTIME_MAX_SECS = MustNewIntOption("grading.runtime.max", 60*5, "The maximum number of seconds a Docker container can be running for.")
+	GRADING_RUNTIME_MAX_SECS           = MustNewIntOption("grading.runtime.max", 60*5, "The maximum number of seconds a Docker container can be running for.")
+	GRADING_MAX_CONCURRENT             = MustNewIntOption("grading.concurrency.max", runtime.NumCPU(), "The maximum number of graders that can run at the same time across the whole server. Zero or less means no limit.")
+	GRADING_MAX_CONCURRENT_COURSE      = MustNewIntOption("grading.concurrency.course", 0, "The maximum number of graders that can run at the same time for a single course. Zero or less means no limit.")
+	GRADING_RESUBMISSION_MAX_WAIT_SECS = MustNewIntOption("grading.concurrency.resubmission.maxwait", 60, "The maximum number of seconds a resubmission will wait behind first attempts before it gets the same priority as a first attempt. Zero or less means resubmissions are promoted immediately.")
+	GRADING_QUEUE_SIZE                 = MustNewIntOption("grading.queue.size", 1000, "The maximum number of asynchronous submissions that can be waiting to be graded.")
+	GRADING_QUEUE_WORKERS              = MustNewIntOption("grading.queue.workers", 4, "The maximum number of asynchronous submissions that can be graded at the same time.")
 
 	// Tasks
 	NO_TASKS             = MustNewBoolOption("tasks.disable", false, "Disable all scheduled tasks.")
@@ -58,8 +81,27 @@ var (
 	WEB_STATIC_ROOT      = MustNewStringOption("web.static.root", "", "The root directory to serve as part of the static portion of the API. Defaults to empty string, which indicates the embedded static directory.")
 	WEB_STATIC_FALLBACK  = MustNewBoolOption("web.static.fallback", false, "For any unmatched route (potential 404) that does not have an API prefix, try to match it in the static root before giving the final 404.")
 
+	// Single Sign-On (OpenID Connect)
+	OIDC_ISSUER           = MustNewStringOption("oidc.issuer", "", "The issuer URL of the OpenID Connect provider to use for single sign-on. Empty disables single sign-on.")
+	OIDC_CLIENT_ID        = MustNewStringOption("oidc.client.id", "", "The client ID registered with the OpenID Connect provider.")
+	OIDC_CLIENT_SECRET    = MustNewStringOption("oidc.client.secret", "", "The client secret registered with the OpenID Connect provider.")
+	OIDC_REDIRECT_URL     = MustNewStringOption("oidc.redirect", "", "The callback URL registered with the OpenID Connect provider. Defaults to the callback endpoint on the host the login request was made to.")
+	OIDC_SCOPES           = MustNewStringOption("oidc.scopes", "openid email profile", "The (space-separated) scopes to request from the OpenID Connect provider.")
+	OIDC_EMAIL_CLAIM      = MustNewStringOption("oidc.claim.email", "email", "The ID token claim that holds the user's email.")
+	OIDC_NAME_CLAIM       = MustNewStringOption("oidc.claim.name", "name", "The ID token claim that holds the user's name.")
+	OIDC_REQUIRE_VERIFIED = MustNewBoolOption("oidc.claim.email.verified", true, "Require ID tokens to have an 'email_verified' claim that is true. Only disable this for providers that never issue unverified emails.")
+	OIDC_AUTO_PROVISION   = MustNewBoolOption("oidc.provision", true, "Create a server user the first time an unknown user logs in with single sign-on.")
+	OIDC_SESSION_SECS     = MustNewIntOption("oidc.session.duration", 24*60*60, "The number of seconds that a token issued by a single sign-on login is valid for.")
+	OIDC_LOGIN_REDIRECT   = MustNewStringOption("oidc.login.redirect", "", "After a successful single sign-on login, redirect to this URL with the user's email and token in the URL fragment. Empty means to respond with JSON instead.")
+
+	// LTI 1.3
+	LTI_KEY_PATH        = MustNewStringOption("lti.key.path", "", "The path to the PEM-encoded RSA private key the autograder signs LTI messages with. Empty means a key will be generated (and saved) in the work directory.")
+	LTI_SESSION_SECS    = MustNewIntOption("lti.session.duration", 24*60*60, "The number of seconds that a token issued by an LTI launch is valid for.")
+	LTI_LAUNCH_REDIRECT = MustNewStringOption("lti.launch.redirect", "", "After a successful LTI launch, redirect to this URL with the user's email, token, course, and assignment in the URL fragment. Empty means to respond with JSON instead.")
+	LTI_PLATFORMS       = MustNewStringOption("lti.platforms", "", "The LTI platforms that courses may be linked to, as a comma-separated list of 'issuer=key-set-url' (e.g., 'https://canvas.instructure.com=https://sso.canvaslms.com/api/lti/security/jwks'). A course's LTI registration is only used if its issuer is listed here, and launches are always verified against the key set URL listed here. Empty means that no LTI launches are accepted.")
+
 	// Database
-	DB_TYPE   = MustNewStringOption("db.type", "disk", "The type of database to use.")
+	DB_TYPE   = MustNewStringOption("db.type", "disk", "The type of database to use (one of 'disk', 'sqlite', or 'postgres').")
 	DB_PG_URI = MustNewStringOption("db.pg.uri", "", "Connection string to connect to a Postgres Database. Empty if not using Postgres.")
 
 	// Job Management
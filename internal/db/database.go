@@ -12,9 +12,12 @@ import (
 
 	"github.com/edulinq/autograder/internal/config"
 	"github.com/edulinq/autograder/internal/db/disk"
+	"github.com/edulinq/autograder/internal/db/pg"
+	"github.com/edulinq/autograder/internal/db/sqlite"
 	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/model"
 	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/timestamp"
 )
 
 var backend Backend
@@ -147,6 +150,72 @@ type Backend interface {
 	// A nil map should only be returned on error.
 	GetRecentSubmissionContents(assignment *model.Assignment, reference *model.ParsedCourseUserReference) (map[string]*model.GradingResult, error)
 
+	// Manual Grading Operations
+
+	// Save a manual grade, replacing any existing manual grade for the same submission.
+	// The submission being graded should already exist.
+	SaveManualGrade(grade *model.ManualGrade) error
+
+	// Get the manual grade for a specific (or most recent) submission.
+	// The submission ID will either be a short submission ID, or empty (if the most recent submission is to be used).
+	// Can return nil if the submission has not been manually graded.
+	GetManualGrade(assignment *model.Assignment, email string, shortSubmissionID string) (*model.ManualGrade, error)
+
+	// Get the manual grade for the most recent of a user's submissions (at or before the given submission) that has been manually graded.
+	// The submission ID will either be a short submission ID, or empty (if all of the user's submissions should be considered).
+	// This allows manual grades to be carried forward when a user resubmits after being graded.
+	// Can return nil if none of the submissions have been manually graded.
+	GetLatestManualGrade(assignment *model.Assignment, email string, shortSubmissionID string) (*model.ManualGrade, error)
+
+	// Extension Operations
+
+	// Save an extension, replacing any existing extension for the same (course, assignment, user).
+	SaveExtension(extension *model.Extension) error
+
+	// Get all the extensions for a course (both course-wide and assignment-specific).
+	// A nil slice should only be returned on error.
+	GetExtensions(courseID string) ([]*model.Extension, error)
+
+	// Remove an extension.
+	// An empty assignment ID refers to a course-wide extension.
+	// Returns true if the extension existed before removal.
+	RemoveExtension(courseID string, assignmentID string, email string) (bool, error)
+
+	// Git Repo Operations
+
+	// Save a git repo, replacing any existing repo for the same user and assignment.
+	SaveGitRepo(assignment *model.Assignment, repo *model.GitRepo) error
+
+	// Get all the git repos for an assignment (sorted by user).
+	// A nil slice should only be returned on error.
+	GetGitRepos(assignment *model.Assignment) ([]*model.GitRepo, error)
+
+	// Remove a user's git repo.
+	// Returns true if the repo existed before removal.
+	RemoveGitRepo(assignment *model.Assignment, email string) (bool, error)
+
+	// LMS Comment Operations
+
+	// Save LMS comments, replacing any existing comment for the same (course, assignment, user).
+	SaveLMSComments(comments []*model.LMSComment) error
+
+	// Get all the LMS comments for an LMS assignment (sorted by user LMS ID).
+	// A nil slice should only be returned on error.
+	GetLMSComments(courseID string, assignmentLMSID string) ([]*model.LMSComment, error)
+
+	// Group Operations
+
+	// Save a group, replacing any existing group with the same ID for the assignment.
+	SaveGroup(assignment *model.Assignment, group *model.Group) error
+
+	// Get all the groups for an assignment (sorted by ID).
+	// A nil slice should only be returned on error.
+	GetGroups(assignment *model.Assignment) ([]*model.Group, error)
+
+	// Remove a group.
+	// Returns true if the group existed before removal.
+	RemoveGroup(assignment *model.Assignment, groupID string) (bool, error)
+
 	// Task Operations
 
 	// Get all the active tasks that come from the given course.
@@ -172,13 +241,42 @@ type Backend interface {
 
 	// Get any logs that match the specific requirements.
 	// Each parameter (except for the log level) can be passed with a zero value, in which case it will not be used for filtering.
+	// Matching records are sorted, offset, and limited according to the query (see log.LimitAndSort()).
 	GetLogRecords(query log.ParsedLogQuery) ([]*log.Record, error)
 
+	// Count the log records with exactly the given level that are from before the given time.
+	CountLogRecords(level log.LogLevel, before timestamp.Timestamp) (int, error)
+
+	// Remove the log records with exactly the given level that are from before the given time.
+	// Returns the number of removed records.
+	RemoveLogRecords(level log.LogLevel, before timestamp.Timestamp) (int, error)
+
 	// Stats Operations
 
 	// DB backends will also be used as stats storage backends.
 	stats.StorageBackend
 
+	// Aggregate the metrics that match a query (the query must have an aggregation).
+	// Unlike GetMetrics(), the query's time window, sort, and limit are applied by the backend.
+	GetAggregateMetrics(query stats.Query) ([]*stats.AggregateResult, error)
+
+	// Get the metrics that match the query's type and time window (without reading the rest of the metrics).
+	// The query's attributes, sort, limit, and aggregation are ignored.
+	GetMetricsInWindow(query stats.Query) ([]*stats.Metric, error)
+
+	// Count the metrics that match the query's type and time window.
+	// The query's attributes, sort, limit, and aggregation are ignored.
+	CountMetricsInWindow(query stats.Query) (int, error)
+
+	// Get the time of the oldest metric that matches the query's type and time window (or nil if there are no matches).
+	// The query's attributes, sort, limit, and aggregation are ignored.
+	GetOldestMetricTimeInWindow(query stats.Query) (*timestamp.Timestamp, error)
+
+	// Remove the metrics that match the query's type and time window, and store the replacements in their place.
+	// The query's attributes, sort, limit, and aggregation are ignored.
+	// Returns the number of removed metrics.
+	ReplaceMetrics(query stats.Query, replacements []*stats.Metric) (int, error)
+
 	// Analysis Operations
 
 	// Fetch any matching individual analysis results.
@@ -213,15 +311,7 @@ func Open() error {
 	}
 
 	var err error
-	dbType := config.DB_TYPE.Get()
-
-	switch dbType {
-	case DB_TYPE_DISK:
-		backend, err = disk.Open()
-	default:
-		err = fmt.Errorf("Unknown database type: '%s'.", dbType)
-	}
-
+	backend, err = openBackend(config.DB_TYPE.Get())
 	if err != nil {
 		return fmt.Errorf("Failed to open database: '%w'.", err)
 	}
@@ -258,6 +348,39 @@ func Open() error {
 	return nil
 }
 
+// Open a standalone backend of the given type (with its tables ready).
+// Unlike Open(), the returned backend is not used as the database for the rest of the system
+// (e.g., it will not receive logs or stats),
+// and the caller is responsible for closing it.
+// This is useful when working with more than one database at a time (e.g., migrations).
+func OpenBackend(dbType string) (Backend, error) {
+	backend, err := openBackend(dbType)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open '%s' database: '%w'.", dbType, err)
+	}
+
+	err = backend.EnsureTables()
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+
+	return backend, nil
+}
+
+func openBackend(dbType string) (Backend, error) {
+	switch dbType {
+	case DB_TYPE_DISK:
+		return disk.Open()
+	case DB_TYPE_SQLITE:
+		return sqlite.Open()
+	case DB_TYPE_POSTGRES:
+		return pg.Open()
+	default:
+		return nil, fmt.Errorf("Unknown database type: '%s'.", dbType)
+	}
+}
+
 func Close() error {
 	dbLock.Lock()
 	defer dbLock.Unlock()
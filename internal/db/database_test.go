@@ -12,6 +12,7 @@ import (
 // Backends to put through the standard tests.
 var testBackends []string = []string{
 	DB_TYPE_DISK,
+	DB_TYPE_SQLITE,
 }
 
 // Methods attatched to this struct will be called for each backend in testBackends.
@@ -36,7 +37,14 @@ func TestDatabases(test *testing.T) {
 	// Quiet the logs.
 	log.SetLevelFatal()
 
-	for _, dbType := range testBackends {
+	backends := testBackends
+
+	// Postgres is only tested when there is a database to connect to.
+	if config.DB_PG_URI.Get() != "" {
+		backends = append(backends, DB_TYPE_POSTGRES)
+	}
+
+	for _, dbType := range backends {
 		config.DB_TYPE.Set(dbType)
 
 		PrepForTestingMain()
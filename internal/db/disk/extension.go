@@ -0,0 +1,111 @@
+package disk
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// All extensions for a course are stored in a single file in the course's dir.
+func (this *backend) SaveExtension(extension *model.Extension) error {
+	path := this.getExtensionsPath(extension.CourseID)
+
+	this.contextLock(path)
+	defer this.contextUnlock(path)
+
+	extensions, err := this.getExtensionsLock(path)
+	if err != nil {
+		return err
+	}
+
+	extensions = slices.DeleteFunc(extensions, func(other *model.Extension) bool {
+		return (other.AssignmentID == extension.AssignmentID) && (other.User == extension.User)
+	})
+
+	extensions = append(extensions, extension)
+
+	return this.writeExtensionsLock(path, extensions)
+}
+
+func (this *backend) GetExtensions(courseID string) ([]*model.Extension, error) {
+	path := this.getExtensionsPath(courseID)
+
+	this.contextReadLock(path)
+	defer this.contextReadUnlock(path)
+
+	return this.getExtensionsLock(path)
+}
+
+func (this *backend) RemoveExtension(courseID string, assignmentID string, email string) (bool, error) {
+	path := this.getExtensionsPath(courseID)
+
+	this.contextLock(path)
+	defer this.contextUnlock(path)
+
+	extensions, err := this.getExtensionsLock(path)
+	if err != nil {
+		return false, err
+	}
+
+	count := len(extensions)
+	extensions = slices.DeleteFunc(extensions, func(other *model.Extension) bool {
+		return (other.AssignmentID == assignmentID) && (other.User == email)
+	})
+
+	if count == len(extensions) {
+		return false, nil
+	}
+
+	err = this.writeExtensionsLock(path, extensions)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (this *backend) getExtensionsLock(path string) ([]*model.Extension, error) {
+	extensions := make([]*model.Extension, 0)
+
+	if !util.PathExists(path) {
+		return extensions, nil
+	}
+
+	err := util.JSONFromFile(path, &extensions)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to deserialize extensions '%s': '%w'.", path, err)
+	}
+
+	return extensions, nil
+}
+
+func (this *backend) writeExtensionsLock(path string, extensions []*model.Extension) error {
+	slices.SortFunc(extensions, func(a *model.Extension, b *model.Extension) int {
+		result := strings.Compare(a.AssignmentID, b.AssignmentID)
+		if result != 0 {
+			return result
+		}
+
+		return strings.Compare(a.User, b.User)
+	})
+
+	err := util.MkDir(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("Failed to make dir for extensions '%s': '%w'.", path, err)
+	}
+
+	err = util.ToJSONFileIndent(extensions, path)
+	if err != nil {
+		return fmt.Errorf("Failed to write extensions '%s': '%w'.", path, err)
+	}
+
+	return nil
+}
+
+func (this *backend) getExtensionsPath(courseID string) string {
+	return filepath.Join(this.getCourseDirFromID(courseID), model.EXTENSIONS_FILENAME)
+}
@@ -0,0 +1,106 @@
+package disk
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// All git repos for an assignment are stored in a single file in the assignment's dir.
+func (this *backend) SaveGitRepo(assignment *model.Assignment, repo *model.GitRepo) error {
+	path := this.getGitReposPath(assignment)
+
+	this.contextLock(path)
+	defer this.contextUnlock(path)
+
+	repos, err := this.getGitReposLock(path)
+	if err != nil {
+		return err
+	}
+
+	repos = slices.DeleteFunc(repos, func(other *model.GitRepo) bool {
+		return (other.User == repo.User)
+	})
+
+	repos = append(repos, repo)
+
+	return this.writeGitReposLock(path, repos)
+}
+
+func (this *backend) GetGitRepos(assignment *model.Assignment) ([]*model.GitRepo, error) {
+	path := this.getGitReposPath(assignment)
+
+	this.contextReadLock(path)
+	defer this.contextReadUnlock(path)
+
+	return this.getGitReposLock(path)
+}
+
+func (this *backend) RemoveGitRepo(assignment *model.Assignment, email string) (bool, error) {
+	path := this.getGitReposPath(assignment)
+
+	this.contextLock(path)
+	defer this.contextUnlock(path)
+
+	repos, err := this.getGitReposLock(path)
+	if err != nil {
+		return false, err
+	}
+
+	count := len(repos)
+	repos = slices.DeleteFunc(repos, func(other *model.GitRepo) bool {
+		return (other.User == email)
+	})
+
+	if count == len(repos) {
+		return false, nil
+	}
+
+	err = this.writeGitReposLock(path, repos)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (this *backend) getGitReposLock(path string) ([]*model.GitRepo, error) {
+	repos := make([]*model.GitRepo, 0)
+
+	if !util.PathExists(path) {
+		return repos, nil
+	}
+
+	err := util.JSONFromFile(path, &repos)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to deserialize git repos '%s': '%w'.", path, err)
+	}
+
+	return repos, nil
+}
+
+func (this *backend) writeGitReposLock(path string, repos []*model.GitRepo) error {
+	slices.SortFunc(repos, func(a *model.GitRepo, b *model.GitRepo) int {
+		return strings.Compare(a.User, b.User)
+	})
+
+	err := util.MkDir(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("Failed to make dir for git repos '%s': '%w'.", path, err)
+	}
+
+	err = util.ToJSONFileIndent(repos, path)
+	if err != nil {
+		return fmt.Errorf("Failed to write git repos '%s': '%w'.", path, err)
+	}
+
+	return nil
+}
+
+func (this *backend) getGitReposPath(assignment *model.Assignment) string {
+	return filepath.Join(this.getAssignmentDir(assignment), model.GIT_REPOS_FILENAME)
+}
@@ -0,0 +1,106 @@
+package disk
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// All groups for an assignment are stored in a single file in the assignment's dir.
+func (this *backend) SaveGroup(assignment *model.Assignment, group *model.Group) error {
+	path := this.getGroupsPath(assignment)
+
+	this.contextLock(path)
+	defer this.contextUnlock(path)
+
+	groups, err := this.getGroupsLock(path)
+	if err != nil {
+		return err
+	}
+
+	groups = slices.DeleteFunc(groups, func(other *model.Group) bool {
+		return (other.ID == group.ID)
+	})
+
+	groups = append(groups, group)
+
+	return this.writeGroupsLock(path, groups)
+}
+
+func (this *backend) GetGroups(assignment *model.Assignment) ([]*model.Group, error) {
+	path := this.getGroupsPath(assignment)
+
+	this.contextReadLock(path)
+	defer this.contextReadUnlock(path)
+
+	return this.getGroupsLock(path)
+}
+
+func (this *backend) RemoveGroup(assignment *model.Assignment, groupID string) (bool, error) {
+	path := this.getGroupsPath(assignment)
+
+	this.contextLock(path)
+	defer this.contextUnlock(path)
+
+	groups, err := this.getGroupsLock(path)
+	if err != nil {
+		return false, err
+	}
+
+	count := len(groups)
+	groups = slices.DeleteFunc(groups, func(other *model.Group) bool {
+		return (other.ID == groupID)
+	})
+
+	if count == len(groups) {
+		return false, nil
+	}
+
+	err = this.writeGroupsLock(path, groups)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (this *backend) getGroupsLock(path string) ([]*model.Group, error) {
+	groups := make([]*model.Group, 0)
+
+	if !util.PathExists(path) {
+		return groups, nil
+	}
+
+	err := util.JSONFromFile(path, &groups)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to deserialize groups '%s': '%w'.", path, err)
+	}
+
+	return groups, nil
+}
+
+func (this *backend) writeGroupsLock(path string, groups []*model.Group) error {
+	slices.SortFunc(groups, func(a *model.Group, b *model.Group) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	err := util.MkDir(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("Failed to make dir for groups '%s': '%w'.", path, err)
+	}
+
+	err = util.ToJSONFileIndent(groups, path)
+	if err != nil {
+		return fmt.Errorf("Failed to write groups '%s': '%w'.", path, err)
+	}
+
+	return nil
+}
+
+func (this *backend) getGroupsPath(assignment *model.Assignment) string {
+	return filepath.Join(this.getAssignmentDir(assignment), model.GROUPS_FILENAME)
+}
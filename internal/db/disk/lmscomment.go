@@ -0,0 +1,111 @@
+package disk
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// All LMS comments for a course are stored in a single file in the course's dir.
+func (this *backend) SaveLMSComments(comments []*model.LMSComment) error {
+	// Group the comments by course.
+	courseComments := make(map[string][]*model.LMSComment)
+	for _, comment := range comments {
+		courseComments[comment.CourseID] = append(courseComments[comment.CourseID], comment)
+	}
+
+	for courseID, newComments := range courseComments {
+		err := this.saveCourseLMSComments(courseID, newComments)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (this *backend) saveCourseLMSComments(courseID string, newComments []*model.LMSComment) error {
+	path := this.getLMSCommentsPath(courseID)
+
+	this.contextLock(path)
+	defer this.contextUnlock(path)
+
+	comments, err := this.getLMSCommentsLock(path)
+	if err != nil {
+		return err
+	}
+
+	for _, comment := range newComments {
+		comments = slices.DeleteFunc(comments, func(other *model.LMSComment) bool {
+			return (other.AssignmentLMSID == comment.AssignmentLMSID) && (other.UserLMSID == comment.UserLMSID)
+		})
+
+		comments = append(comments, comment)
+	}
+
+	return this.writeLMSCommentsLock(path, comments)
+}
+
+func (this *backend) GetLMSComments(courseID string, assignmentLMSID string) ([]*model.LMSComment, error) {
+	path := this.getLMSCommentsPath(courseID)
+
+	this.contextReadLock(path)
+	defer this.contextReadUnlock(path)
+
+	comments, err := this.getLMSCommentsLock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	comments = slices.DeleteFunc(comments, func(comment *model.LMSComment) bool {
+		return comment.AssignmentLMSID != assignmentLMSID
+	})
+
+	return comments, nil
+}
+
+func (this *backend) getLMSCommentsLock(path string) ([]*model.LMSComment, error) {
+	comments := make([]*model.LMSComment, 0)
+
+	if !util.PathExists(path) {
+		return comments, nil
+	}
+
+	err := util.JSONFromFile(path, &comments)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to deserialize LMS comments '%s': '%w'.", path, err)
+	}
+
+	return comments, nil
+}
+
+func (this *backend) writeLMSCommentsLock(path string, comments []*model.LMSComment) error {
+	slices.SortFunc(comments, func(a *model.LMSComment, b *model.LMSComment) int {
+		result := strings.Compare(a.AssignmentLMSID, b.AssignmentLMSID)
+		if result != 0 {
+			return result
+		}
+
+		return strings.Compare(a.UserLMSID, b.UserLMSID)
+	})
+
+	err := util.MkDir(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("Failed to make dir for LMS comments '%s': '%w'.", path, err)
+	}
+
+	err = util.ToJSONFileIndent(comments, path)
+	if err != nil {
+		return fmt.Errorf("Failed to write LMS comments '%s': '%w'.", path, err)
+	}
+
+	return nil
+}
+
+func (this *backend) getLMSCommentsPath(courseID string) string {
+	return filepath.Join(this.getCourseDirFromID(courseID), model.LMS_COMMENTS_FILENAME)
+}
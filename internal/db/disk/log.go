@@ -1,9 +1,11 @@
 package disk
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/timestamp"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -28,8 +30,56 @@ func (this *backend) GetLogRecords(query log.ParsedLogQuery) ([]*log.Record, err
 	records, err := util.FilterJSONLFile(path, log.Record{}, func(record *log.Record) bool {
 		return query.Match(record)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return log.LimitAndSort(records, query), nil
+}
+
+func (this *backend) CountLogRecords(level log.LogLevel, before timestamp.Timestamp) (int, error) {
+	this.logLock.RLock()
+	defer this.logLock.RUnlock()
+
+	path := this.getLogPath()
+	if !util.PathExists(path) {
+		return 0, nil
+	}
+
+	// Count while filtering, so the matching records are not kept.
+	count := 0
+	_, err := util.FilterJSONLFile(path, log.Record{}, func(record *log.Record) bool {
+		if (record.Level == level) && (record.Timestamp < before) {
+			count++
+		}
+
+		return false
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count log records: '%w'.", err)
+	}
+
+	return count, nil
+}
+
+func (this *backend) RemoveLogRecords(level log.LogLevel, before timestamp.Timestamp) (int, error) {
+	this.logLock.Lock()
+	defer this.logLock.Unlock()
+
+	count := 0
+	err := util.RemoveEntriesJSONLFile(this.getLogPath(), log.Record{}, func(record *log.Record) bool {
+		if (record.Level != level) || (record.Timestamp >= before) {
+			return false
+		}
+
+		count++
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to remove log records: '%w'.", err)
+	}
 
-	return records, err
+	return count, nil
 }
 
 func (this *backend) getLogPath() string {
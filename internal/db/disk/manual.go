@@ -0,0 +1,94 @@
+package disk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// Manual grades are stored alongside the submission they are for.
+func (this *backend) SaveManualGrade(grade *model.ManualGrade) error {
+	baseDir := this.getSubmissionDir(grade.CourseID, grade.AssignmentID, grade.User, grade.ShortID)
+
+	this.contextLock(baseDir)
+	defer this.contextUnlock(baseDir)
+
+	if !util.PathExists(filepath.Join(baseDir, model.SUBMISSION_RESULT_FILENAME)) {
+		return fmt.Errorf("Cannot save manual grade for a submission that does not exist: '%s'.", grade.ID)
+	}
+
+	path := filepath.Join(baseDir, model.MANUAL_GRADE_FILENAME)
+	err := util.ToJSONFileIndent(grade, path)
+	if err != nil {
+		return fmt.Errorf("Failed to write manual grade '%s': '%w'.", path, err)
+	}
+
+	return nil
+}
+
+func (this *backend) GetManualGrade(assignment *model.Assignment, email string, shortSubmissionID string) (*model.ManualGrade, error) {
+	var err error
+
+	if shortSubmissionID == "" {
+		shortSubmissionID, err = this.getMostRecentSubmissionID(assignment, email)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get most recent submission id: '%w'.", err)
+		}
+	}
+
+	if shortSubmissionID == "" {
+		return nil, nil
+	}
+
+	baseDir := this.getSubmissionDirFromAssignment(assignment, email, shortSubmissionID)
+
+	this.contextLock(baseDir)
+	defer this.contextUnlock(baseDir)
+
+	path := filepath.Join(baseDir, model.MANUAL_GRADE_FILENAME)
+	if !util.PathExists(path) {
+		return nil, nil
+	}
+
+	var grade model.ManualGrade
+	err = util.JSONFromFile(path, &grade)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to deserialize manual grade '%s': '%w'.", path, err)
+	}
+
+	return &grade, nil
+}
+
+func (this *backend) GetLatestManualGrade(assignment *model.Assignment, email string, shortSubmissionID string) (*model.ManualGrade, error) {
+	submissionsDir := this.getUserSubmissionDir(assignment.GetCourse().GetID(), assignment.GetID(), email)
+	if !util.PathExists(submissionsDir) {
+		return nil, nil
+	}
+
+	dirents, err := os.ReadDir(submissionsDir)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read user submissions dir '%s': '%w'.", submissionsDir, err)
+	}
+
+	// Dirents are sorted by name, so the most recent submissions are last.
+	for i := len(dirents) - 1; i >= 0; i-- {
+		currentID := dirents[i].Name()
+		if (shortSubmissionID != "") && (currentID > shortSubmissionID) {
+			continue
+		}
+
+		grade, err := this.GetManualGrade(assignment, email, currentID)
+		if err != nil {
+			return nil, err
+		}
+
+		if grade != nil {
+			return grade, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 
 	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/timestamp"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -26,6 +27,64 @@ func (this *backend) GetMetrics(query stats.Query) ([]*stats.Metric, error) {
 	return records, err
 }
 
+func (this *backend) GetAggregateMetrics(query stats.Query) ([]*stats.AggregateResult, error) {
+	records, err := this.GetMetrics(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats.Aggregate(records, query), nil
+}
+
+func (this *backend) GetMetricsInWindow(query stats.Query) ([]*stats.Metric, error) {
+	path, err := this.getStatsPath(query.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	this.contextReadLock(path)
+	defer this.contextReadUnlock(path)
+
+	return util.FilterJSONLFile(path, stats.Metric{}, func(record *stats.Metric) bool {
+		return (record.Type == query.Type) && query.MatchTimeWindow(record)
+	})
+}
+
+func (this *backend) CountMetricsInWindow(query stats.Query) (int, error) {
+	records, err := this.GetMetricsInWindow(query)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(records), nil
+}
+
+func (this *backend) GetOldestMetricTimeInWindow(query stats.Query) (*timestamp.Timestamp, error) {
+	path, err := this.getStatsPath(query.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	this.contextReadLock(path)
+	defer this.contextReadUnlock(path)
+
+	// Track the oldest time while filtering, so the matching metrics are not kept.
+	var oldest *timestamp.Timestamp = nil
+	_, err = util.FilterJSONLFile(path, stats.Metric{}, func(record *stats.Metric) bool {
+		if (record.Type == query.Type) && query.MatchTimeWindow(record) && ((oldest == nil) || (record.Timestamp < *oldest)) {
+			recordTime := record.Timestamp
+			oldest = &recordTime
+		}
+
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return oldest, nil
+}
+
 func (this *backend) StoreMetric(record *stats.Metric) error {
 	path, err := this.getStatsPath(record.Type)
 	if err != nil {
@@ -38,6 +97,40 @@ func (this *backend) StoreMetric(record *stats.Metric) error {
 	return util.AppendJSONLFile(path, record)
 }
 
+func (this *backend) ReplaceMetrics(query stats.Query, replacements []*stats.Metric) (int, error) {
+	path, err := this.getStatsPath(query.Type)
+	if err != nil {
+		return 0, err
+	}
+
+	this.contextLock(path)
+	defer this.contextUnlock(path)
+
+	count := 0
+	err = util.RemoveEntriesJSONLFile(path, stats.Metric{}, func(record *stats.Metric) bool {
+		if (record.Type != query.Type) || !query.MatchTimeWindow(record) {
+			return false
+		}
+
+		count++
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to remove metrics: '%w'.", err)
+	}
+
+	if len(replacements) == 0 {
+		return count, nil
+	}
+
+	err = util.AppendJSONLFileMany(path, replacements)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to store replacement metrics: '%w'.", err)
+	}
+
+	return count, nil
+}
+
 func (this *backend) getStatsPath(metricType stats.MetricType) (string, error) {
 	if metricType == "" {
 		return "", fmt.Errorf("No metric type was given.")
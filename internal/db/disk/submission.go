@@ -8,7 +8,6 @@ import (
 	"slices"
 	"time"
 
-	"github.com/edulinq/autograder/internal/common"
 	"github.com/edulinq/autograder/internal/model"
 	"github.com/edulinq/autograder/internal/util"
 )
@@ -31,38 +30,7 @@ func (this *backend) saveSubmissionLock(course *model.Course, submission *model.
 		defer this.contextUnlock(baseDir)
 	}
 
-	err := util.MkDir(baseDir)
-	if err != nil {
-		return fmt.Errorf("Failed to make submission dir '%s': '%w'.", baseDir, err)
-	}
-
-	resultPath := filepath.Join(baseDir, model.SUBMISSION_RESULT_FILENAME)
-	err = util.ToJSONFileIndent(submission.Info, resultPath)
-	if err != nil {
-		return fmt.Errorf("Failed to write submission result '%s': '%w'.", resultPath, err)
-	}
-
-	err = util.GzipBytesToDirectory(filepath.Join(baseDir, common.GRADING_INPUT_DIRNAME), submission.InputFilesGZip)
-	if err != nil {
-		return fmt.Errorf("Failed to write submission input files: '%w'.", err)
-	}
-
-	err = util.GzipBytesToDirectory(filepath.Join(baseDir, common.GRADING_OUTPUT_DIRNAME), submission.OutputFilesGZip)
-	if err != nil {
-		return fmt.Errorf("Failed to write submission input files: '%w'.", err)
-	}
-
-	err = util.WriteFile(submission.Stdout, filepath.Join(baseDir, common.SUBMISSION_STDOUT_FILENAME))
-	if err != nil {
-		return fmt.Errorf("Failed to write submission stdout file: '%w'.", err)
-	}
-
-	err = util.WriteFile(submission.Stderr, filepath.Join(baseDir, common.SUBMISSION_STDERR_FILENAME))
-	if err != nil {
-		return fmt.Errorf("Failed to write submission stderr file: '%w'.", err)
-	}
-
-	return nil
+	return model.WriteGradingResult(submission, baseDir)
 }
 
 func (this *backend) SaveSubmissions(course *model.Course, submissions []*model.GradingResult) error {
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/model"
+)
+
+func SaveExtension(extension *model.Extension) error {
+	if backend == nil {
+		return fmt.Errorf("Database has not been opened.")
+	}
+
+	return backend.SaveExtension(extension)
+}
+
+func GetExtensions(course *model.Course) ([]*model.Extension, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("Database has not been opened.")
+	}
+
+	return backend.GetExtensions(course.GetID())
+}
+
+func RemoveExtension(course *model.Course, assignmentID string, email string) (bool, error) {
+	if backend == nil {
+		return false, fmt.Errorf("Database has not been opened.")
+	}
+
+	return backend.RemoveExtension(course.GetID(), assignmentID, email)
+}
+
+// Get the effective extension (course-wide and assignment-specific merged) for each user with one for this assignment.
+func GetAssignmentExtensions(assignment *model.Assignment) (map[string]*model.Extension, error) {
+	extensions, err := GetExtensions(assignment.GetCourse())
+	if err != nil {
+		return nil, err
+	}
+
+	courseExtensions := make(map[string]*model.Extension)
+	assignmentExtensions := make(map[string]*model.Extension)
+
+	for _, extension := range extensions {
+		if extension.IsCourseWide() {
+			courseExtensions[extension.User] = extension
+		} else if extension.AssignmentID == assignment.GetID() {
+			assignmentExtensions[extension.User] = extension
+		}
+	}
+
+	results := make(map[string]*model.Extension, len(courseExtensions)+len(assignmentExtensions))
+
+	for email, extension := range courseExtensions {
+		results[email] = model.MergeExtensions(extension, assignmentExtensions[email])
+	}
+
+	for email, extension := range assignmentExtensions {
+		_, ok := results[email]
+		if !ok {
+			results[email] = extension
+		}
+	}
+
+	return results, nil
+}
+
+// Get the effective extension for a user on an assignment.
+// Returns nil if the user has no extension.
+func GetExtension(assignment *model.Assignment, email string) (*model.Extension, error) {
+	extensions, err := GetAssignmentExtensions(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	return extensions[email], nil
+}
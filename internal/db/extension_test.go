@@ -0,0 +1,190 @@
+package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *DBTests) DBTestExtensionBase(test *testing.T) {
+	ResetForTesting()
+	defer ResetForTesting()
+
+	assignment := MustGetTestAssignment()
+	course := assignment.GetCourse()
+
+	extensions, err := GetExtensions(course)
+	if err != nil {
+		test.Fatalf("Failed to get initial extensions: '%v'.", err)
+	}
+
+	if len(extensions) != 0 {
+		test.Fatalf("Found extensions before any were saved: '%s'.", util.MustToJSONIndent(extensions))
+	}
+
+	dueDate := timestamp.FromMSecs(1000)
+
+	courseExtension := &model.Extension{
+		CourseID:          "course101",
+		User:              "course-student@test.edulinq.org",
+		ExtraTime:         &util.DurationSpec{Days: 1},
+		ExtraAttempts:     1,
+		RuntimeMultiplier: 1.5,
+		UpdateTime:        timestamp.FromMSecs(100),
+	}
+
+	assignmentExtension := &model.Extension{
+		CourseID:      "course101",
+		AssignmentID:  "hw0",
+		User:          "course-student@test.edulinq.org",
+		DueDate:       &dueDate,
+		ExtraAttempts: 2,
+		Notes:         "Accommodation.",
+		UpdateTime:    timestamp.FromMSecs(200),
+	}
+
+	otherExtension := &model.Extension{
+		CourseID:      "course101",
+		AssignmentID:  "hw0",
+		User:          "course-other@test.edulinq.org",
+		ExtraAttempts: 3,
+		UpdateTime:    timestamp.FromMSecs(300),
+	}
+
+	for _, extension := range []*model.Extension{otherExtension, assignmentExtension, courseExtension} {
+		err = SaveExtension(extension)
+		if err != nil {
+			test.Fatalf("Failed to save extension: '%v'.", err)
+		}
+	}
+
+	// Sorted by assignment, then user.
+	expected := []*model.Extension{courseExtension, otherExtension, assignmentExtension}
+
+	extensions, err = GetExtensions(course)
+	if err != nil {
+		test.Fatalf("Failed to get extensions: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, extensions) {
+		test.Fatalf("Unexpected extensions. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(extensions))
+	}
+
+	// Check the merged extensions.
+	expectedMerged := map[string]*model.Extension{
+		"course-student@test.edulinq.org": &model.Extension{
+			CourseID:          "course101",
+			AssignmentID:      "hw0",
+			User:              "course-student@test.edulinq.org",
+			DueDate:           &dueDate,
+			ExtraTime:         &util.DurationSpec{Days: 1},
+			ExtraAttempts:     2,
+			RuntimeMultiplier: 1.5,
+			Notes:             "Accommodation.",
+			UpdateTime:        timestamp.FromMSecs(200),
+		},
+		"course-other@test.edulinq.org": otherExtension,
+	}
+
+	merged, err := GetAssignmentExtensions(assignment)
+	if err != nil {
+		test.Fatalf("Failed to get assignment extensions: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expectedMerged, merged) {
+		test.Fatalf("Unexpected assignment extensions. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expectedMerged), util.MustToJSONIndent(merged))
+	}
+
+	// Update an existing extension.
+	otherExtension.ExtraAttempts = 5
+
+	err = SaveExtension(otherExtension)
+	if err != nil {
+		test.Fatalf("Failed to update extension: '%v'.", err)
+	}
+
+	extension, err := GetExtension(assignment, "course-other@test.edulinq.org")
+	if err != nil {
+		test.Fatalf("Failed to get updated extension: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(otherExtension, extension) {
+		test.Fatalf("Unexpected updated extension. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(otherExtension), util.MustToJSONIndent(extension))
+	}
+
+	// Extensions show up in dumps.
+	tempDir := util.MustMkDirTemp("test-extension-dump-")
+	defer util.RemoveDirent(tempDir)
+
+	err = DumpCourse(course, tempDir)
+	if err != nil {
+		test.Fatalf("Failed to dump course: '%v'.", err)
+	}
+
+	var dumpedExtensions []*model.Extension
+	err = util.JSONFromFile(filepath.Join(tempDir, model.EXTENSIONS_FILENAME), &dumpedExtensions)
+	if err != nil {
+		test.Fatalf("Failed to read dumped extensions: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, dumpedExtensions) {
+		test.Fatalf("Unexpected dumped extensions. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(dumpedExtensions))
+	}
+
+	// Remove extensions.
+	testCases := []struct {
+		assignmentID string
+		email        string
+		expected     bool
+	}{
+		{"", "course-student@test.edulinq.org", true},
+		{"", "course-student@test.edulinq.org", false},
+		{"hw0", "course-grader@test.edulinq.org", false},
+		{"hw0", "course-student@test.edulinq.org", true},
+	}
+
+	for i, testCase := range testCases {
+		removed, err := RemoveExtension(course, testCase.assignmentID, testCase.email)
+		if err != nil {
+			test.Errorf("Case %d: Failed to remove extension: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.expected != removed {
+			test.Errorf("Case %d: Unexpected removal result. Expected: '%v', Actual: '%v'.", i, testCase.expected, removed)
+			continue
+		}
+	}
+
+	extension, err = GetExtension(assignment, "course-student@test.edulinq.org")
+	if err != nil {
+		test.Fatalf("Failed to get removed extension: '%v'.", err)
+	}
+
+	if extension != nil {
+		test.Fatalf("Found a removed extension: '%s'.", util.MustToJSONIndent(extension))
+	}
+
+	// Clearing the course removes the extensions.
+	err = ClearCourse(course)
+	if err != nil {
+		test.Fatalf("Failed to clear course: '%v'.", err)
+	}
+
+	extensions, err = GetExtensions(course)
+	if err != nil {
+		test.Fatalf("Failed to get cleared extensions: '%v'.", err)
+	}
+
+	if len(extensions) != 0 {
+		test.Fatalf("Found extensions after clearing the course: '%s'.", util.MustToJSONIndent(extensions))
+	}
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/model"
+)
+
+func SaveGitRepo(assignment *model.Assignment, repo *model.GitRepo) error {
+	if backend == nil {
+		return fmt.Errorf("Database has not been opened.")
+	}
+
+	err := repo.Validate()
+	if err != nil {
+		return fmt.Errorf("Invalid git repo: '%w'.", err)
+	}
+
+	return backend.SaveGitRepo(assignment, repo)
+}
+
+func GetGitRepos(assignment *model.Assignment) ([]*model.GitRepo, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("Database has not been opened.")
+	}
+
+	return backend.GetGitRepos(assignment)
+}
+
+// Get a user's git repo for an assignment.
+// Returns nil if the user has not registered a repo.
+func GetGitRepo(assignment *model.Assignment, email string) (*model.GitRepo, error) {
+	repos, err := GetGitRepos(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.GetUserGitRepo(repos, email), nil
+}
+
+// Get all the git repos for an assignment that match the given URL.
+// Usually there will be at most one (registering a repo that another user has is not allowed),
+// but repos saved directly (e.g., restored from a backup) are not checked.
+func GetGitReposByURL(assignment *model.Assignment, url string) ([]*model.GitRepo, error) {
+	repos, err := GetGitRepos(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*model.GitRepo, 0)
+	for _, repo := range repos {
+		if repo.MatchesURL(url) {
+			matches = append(matches, repo)
+		}
+	}
+
+	return matches, nil
+}
+
+func RemoveGitRepo(assignment *model.Assignment, email string) (bool, error) {
+	if backend == nil {
+		return false, fmt.Errorf("Database has not been opened.")
+	}
+
+	return backend.RemoveGitRepo(assignment, email)
+}
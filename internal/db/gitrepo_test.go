@@ -0,0 +1,155 @@
+package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *DBTests) DBTestGitRepoBase(test *testing.T) {
+	ResetForTesting()
+	defer ResetForTesting()
+
+	assignment := MustGetTestAssignment()
+
+	repos, err := GetGitRepos(assignment)
+	if err != nil {
+		test.Fatalf("Failed to get initial git repos: '%v'.", err)
+	}
+
+	if len(repos) != 0 {
+		test.Fatalf("Found git repos before any were saved: '%s'.", util.MustToJSONIndent(repos))
+	}
+
+	studentRepo := &model.GitRepo{
+		CourseID:     "course101",
+		AssignmentID: "hw0",
+		User:         "course-student@test.edulinq.org",
+		URL:          "https://github.com/test/student.git",
+		CreatedBy:    "course-student@test.edulinq.org",
+		UpdateTime:   timestamp.FromMSecs(100),
+	}
+
+	otherRepo := &model.GitRepo{
+		CourseID:     "course101",
+		AssignmentID: "hw0",
+		User:         "course-other@test.edulinq.org",
+		URL:          "git@github.com:test/other.git",
+		CreatedBy:    "course-admin@test.edulinq.org",
+		UpdateTime:   timestamp.FromMSecs(200),
+	}
+
+	for _, repo := range []*model.GitRepo{studentRepo, otherRepo} {
+		err = SaveGitRepo(assignment, repo)
+		if err != nil {
+			test.Fatalf("Failed to save git repo: '%v'.", err)
+		}
+	}
+
+	// Sorted by user.
+	expected := []*model.GitRepo{otherRepo, studentRepo}
+
+	repos, err = GetGitRepos(assignment)
+	if err != nil {
+		test.Fatalf("Failed to get git repos: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, repos) {
+		test.Fatalf("Unexpected git repos. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(repos))
+	}
+
+	// Update an existing repo.
+	lastCommitTime := timestamp.FromMSecs(300)
+	studentRepo.LastCommit = "abc123"
+	studentRepo.LastCommitTime = &lastCommitTime
+
+	err = SaveGitRepo(assignment, studentRepo)
+	if err != nil {
+		test.Fatalf("Failed to update git repo: '%v'.", err)
+	}
+
+	repo, err := GetGitRepo(assignment, "course-student@test.edulinq.org")
+	if err != nil {
+		test.Fatalf("Failed to get user git repo: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(studentRepo, repo) {
+		test.Fatalf("Unexpected user git repo. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(studentRepo), util.MustToJSONIndent(repo))
+	}
+
+	// Repos can be found by any form of their URL.
+	matches, err := GetGitReposByURL(assignment, "https://github.com/test/other")
+	if err != nil {
+		test.Fatalf("Failed to get git repos by URL: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual([]*model.GitRepo{otherRepo}, matches) {
+		test.Fatalf("Unexpected git repos by URL. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent([]*model.GitRepo{otherRepo}), util.MustToJSONIndent(matches))
+	}
+
+	// Invalid repos are not saved.
+	err = SaveGitRepo(assignment, &model.GitRepo{CourseID: "course101", AssignmentID: "hw0", User: "course-grader@test.edulinq.org"})
+	if err == nil {
+		test.Fatalf("Did not get an error on an invalid git repo.")
+	}
+
+	// Repos show up in dumps.
+	tempDir := util.MustMkDirTemp("test-git-repo-dump-")
+	defer util.RemoveDirent(tempDir)
+
+	err = DumpCourse(assignment.GetCourse(), tempDir)
+	if err != nil {
+		test.Fatalf("Failed to dump course: '%v'.", err)
+	}
+
+	var dumpedRepos []*model.GitRepo
+	err = util.JSONFromFile(filepath.Join(tempDir, "assignments", "hw0", model.GIT_REPOS_FILENAME), &dumpedRepos)
+	if err != nil {
+		test.Fatalf("Failed to read dumped git repos: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, dumpedRepos) {
+		test.Fatalf("Unexpected dumped git repos. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(dumpedRepos))
+	}
+
+	// Remove repos.
+	testCases := []struct {
+		email    string
+		expected bool
+	}{
+		{"course-student@test.edulinq.org", true},
+		{"course-student@test.edulinq.org", false},
+		{"ZZZ@test.edulinq.org", false},
+		{"course-other@test.edulinq.org", true},
+	}
+
+	for i, testCase := range testCases {
+		removed, err := RemoveGitRepo(assignment, testCase.email)
+		if err != nil {
+			test.Errorf("Case %d: Failed to remove git repo: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.expected != removed {
+			test.Errorf("Case %d: Unexpected removal result. Expected: '%v', Actual: '%v'.", i, testCase.expected, removed)
+			continue
+		}
+	}
+
+	repos, err = GetGitRepos(assignment)
+	if err != nil {
+		test.Fatalf("Failed to get git repos after removal: '%v'.", err)
+	}
+
+	if len(repos) != 0 {
+		test.Fatalf("Found git repos after removal: '%s'.", util.MustToJSONIndent(repos))
+	}
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/model"
+)
+
+// Save a group.
+// Users may only be in one group per assignment,
+// so it is an error for any member to already be in a different group.
+func SaveGroup(assignment *model.Assignment, group *model.Group) error {
+	if backend == nil {
+		return fmt.Errorf("Database has not been opened.")
+	}
+
+	groups, err := backend.GetGroups(assignment)
+	if err != nil {
+		return fmt.Errorf("Failed to get existing groups: '%w'.", err)
+	}
+
+	for _, other := range groups {
+		if other.ID == group.ID {
+			continue
+		}
+
+		for _, member := range group.Members {
+			if other.HasMember(member) {
+				return fmt.Errorf("User '%s' is already in group '%s'.", member, other.ID)
+			}
+		}
+	}
+
+	return backend.SaveGroup(assignment, group)
+}
+
+func GetGroups(assignment *model.Assignment) ([]*model.Group, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("Database has not been opened.")
+	}
+
+	return backend.GetGroups(assignment)
+}
+
+// Get the group a user is in for an assignment.
+// Returns nil if the user is not in a group.
+func GetUserGroup(assignment *model.Assignment, email string) (*model.Group, error) {
+	groups, err := GetGroups(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.GetUserGroup(groups, email), nil
+}
+
+func RemoveGroup(assignment *model.Assignment, groupID string) (bool, error) {
+	if backend == nil {
+		return false, fmt.Errorf("Database has not been opened.")
+	}
+
+	return backend.RemoveGroup(assignment, groupID)
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *DBTests) DBTestGroupBase(test *testing.T) {
+	ResetForTesting()
+	defer ResetForTesting()
+
+	assignment := MustGetTestAssignment()
+
+	groups, err := GetGroups(assignment)
+	if err != nil {
+		test.Fatalf("Failed to get initial groups: '%v'.", err)
+	}
+
+	if len(groups) != 0 {
+		test.Fatalf("Found groups before any were saved: '%s'.", util.MustToJSONIndent(groups))
+	}
+
+	group1 := &model.Group{
+		ID:           "g1",
+		CourseID:     "course101",
+		AssignmentID: "hw0",
+		Members:      []string{"course-other@test.edulinq.org", "course-student@test.edulinq.org"},
+		CreatedBy:    "course-admin@test.edulinq.org",
+		UpdateTime:   timestamp.FromMSecs(100),
+	}
+
+	group2 := &model.Group{
+		ID:           "g2",
+		CourseID:     "course101",
+		AssignmentID: "hw0",
+		Members:      []string{"course-grader@test.edulinq.org"},
+		CreatedBy:    "course-admin@test.edulinq.org",
+		UpdateTime:   timestamp.FromMSecs(200),
+	}
+
+	for _, group := range []*model.Group{group2, group1} {
+		err = SaveGroup(assignment, group)
+		if err != nil {
+			test.Fatalf("Failed to save group: '%v'.", err)
+		}
+	}
+
+	expected := []*model.Group{group1, group2}
+
+	groups, err = GetGroups(assignment)
+	if err != nil {
+		test.Fatalf("Failed to get groups: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, groups) {
+		test.Fatalf("Unexpected groups. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(groups))
+	}
+
+	group, err := GetUserGroup(assignment, "course-student@test.edulinq.org")
+	if err != nil {
+		test.Fatalf("Failed to get user group: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(group1, group) {
+		test.Fatalf("Unexpected user group. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(group1), util.MustToJSONIndent(group))
+	}
+
+	// Users cannot be in multiple groups.
+	conflict := &model.Group{
+		ID:           "g3",
+		CourseID:     "course101",
+		AssignmentID: "hw0",
+		Members:      []string{"course-student@test.edulinq.org"},
+	}
+
+	err = SaveGroup(assignment, conflict)
+	if err == nil {
+		test.Fatalf("Did not get an error when saving a group with a member in another group.")
+	}
+
+	// Updating a group is not a conflict with itself.
+	group1.Members = []string{"course-student@test.edulinq.org"}
+
+	err = SaveGroup(assignment, group1)
+	if err != nil {
+		test.Fatalf("Failed to update group: '%v'.", err)
+	}
+
+	group, err = GetUserGroup(assignment, "course-other@test.edulinq.org")
+	if err != nil {
+		test.Fatalf("Failed to get user group: '%v'.", err)
+	}
+
+	if group != nil {
+		test.Fatalf("Found a group for a user that was removed from it: '%s'.", util.MustToJSONIndent(group))
+	}
+
+	// Groups show up in dumps.
+	tempDir := util.MustMkDirTemp("test-group-dump-")
+	defer util.RemoveDirent(tempDir)
+
+	err = DumpCourse(assignment.GetCourse(), tempDir)
+	if err != nil {
+		test.Fatalf("Failed to dump course: '%v'.", err)
+	}
+
+	var dumpedGroups []*model.Group
+	err = util.JSONFromFile(filepath.Join(tempDir, "assignments", "hw0", model.GROUPS_FILENAME), &dumpedGroups)
+	if err != nil {
+		test.Fatalf("Failed to read dumped groups: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, dumpedGroups) {
+		test.Fatalf("Unexpected dumped groups. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(dumpedGroups))
+	}
+
+	// Remove groups.
+	testCases := []struct {
+		groupID  string
+		expected bool
+	}{
+		{"g1", true},
+		{"g1", false},
+		{"ZZZ", false},
+		{"g2", true},
+	}
+
+	for i, testCase := range testCases {
+		removed, err := RemoveGroup(assignment, testCase.groupID)
+		if err != nil {
+			test.Errorf("Case %d: Failed to remove group: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.expected != removed {
+			test.Errorf("Case %d: Unexpected removal result. Expected: '%v', Actual: '%v'.", i, testCase.expected, removed)
+			continue
+		}
+	}
+
+	groups, err = GetGroups(assignment)
+	if err != nil {
+		test.Fatalf("Failed to get groups after removal: '%v'.", err)
+	}
+
+	if len(groups) != 0 {
+		test.Fatalf("Found groups after removal: '%s'.", util.MustToJSONIndent(groups))
+	}
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/model"
+)
+
+func SaveLMSComments(comments []*model.LMSComment) error {
+	if backend == nil {
+		return fmt.Errorf("Database has not been opened.")
+	}
+
+	for _, comment := range comments {
+		err := comment.Validate()
+		if err != nil {
+			return fmt.Errorf("Invalid LMS comment: '%w'.", err)
+		}
+	}
+
+	return backend.SaveLMSComments(comments)
+}
+
+func SaveLMSComment(comment *model.LMSComment) error {
+	return SaveLMSComments([]*model.LMSComment{comment})
+}
+
+func GetLMSComments(course *model.Course, assignmentLMSID string) ([]*model.LMSComment, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("Database has not been opened.")
+	}
+
+	return backend.GetLMSComments(course.GetID(), assignmentLMSID)
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *DBTests) DBTestLMSCommentBase(test *testing.T) {
+	ResetForTesting()
+	defer ResetForTesting()
+
+	course := MustGetTestCourse()
+
+	comments, err := GetLMSComments(course, "A1")
+	if err != nil {
+		test.Fatalf("Failed to get initial LMS comments: '%v'.", err)
+	}
+
+	if len(comments) != 0 {
+		test.Fatalf("Found LMS comments before any were saved: '%s'.", util.MustToJSONIndent(comments))
+	}
+
+	studentComment := &model.LMSComment{
+		CourseID:        "course101",
+		AssignmentLMSID: "A1",
+		UserLMSID:       "U2",
+		Text:            "student",
+		UpdateTime:      timestamp.FromMSecs(100),
+	}
+
+	otherComment := &model.LMSComment{
+		CourseID:        "course101",
+		AssignmentLMSID: "A1",
+		UserLMSID:       "U1",
+		Text:            "other",
+		UpdateTime:      timestamp.FromMSecs(200),
+	}
+
+	otherAssignmentComment := &model.LMSComment{
+		CourseID:        "course101",
+		AssignmentLMSID: "A2",
+		UserLMSID:       "U1",
+		Text:            "other assignment",
+		UpdateTime:      timestamp.FromMSecs(300),
+	}
+
+	err = SaveLMSComments([]*model.LMSComment{studentComment, otherComment, otherAssignmentComment})
+	if err != nil {
+		test.Fatalf("Failed to save LMS comments: '%v'.", err)
+	}
+
+	// Sorted by user.
+	expected := []*model.LMSComment{otherComment, studentComment}
+
+	comments, err = GetLMSComments(course, "A1")
+	if err != nil {
+		test.Fatalf("Failed to get LMS comments: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, comments) {
+		test.Fatalf("Unexpected LMS comments. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(comments))
+	}
+
+	// Update an existing comment.
+	studentComment.Text = "updated"
+	studentComment.UpdateTime = timestamp.FromMSecs(400)
+
+	err = SaveLMSComment(studentComment)
+	if err != nil {
+		test.Fatalf("Failed to update LMS comment: '%v'.", err)
+	}
+
+	comments, err = GetLMSComments(course, "A1")
+	if err != nil {
+		test.Fatalf("Failed to get updated LMS comments: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, comments) {
+		test.Fatalf("Unexpected updated LMS comments. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(comments))
+	}
+
+	// Invalid comments are not saved.
+	err = SaveLMSComment(&model.LMSComment{CourseID: "course101", AssignmentLMSID: "A1"})
+	if err == nil {
+		test.Fatalf("Did not get an error on an invalid LMS comment.")
+	}
+
+	// Comments show up in dumps.
+	tempDir := util.MustMkDirTemp("test-lms-comment-dump-")
+	defer util.RemoveDirent(tempDir)
+
+	err = DumpCourse(course, tempDir)
+	if err != nil {
+		test.Fatalf("Failed to dump course: '%v'.", err)
+	}
+
+	var dumpedComments []*model.LMSComment
+	err = util.JSONFromFile(filepath.Join(tempDir, model.LMS_COMMENTS_FILENAME), &dumpedComments)
+	if err != nil {
+		test.Fatalf("Failed to read dumped LMS comments: '%v'.", err)
+	}
+
+	expectedDumped := []*model.LMSComment{otherComment, studentComment, otherAssignmentComment}
+	if !reflect.DeepEqual(expectedDumped, dumpedComments) {
+		test.Fatalf("Unexpected dumped LMS comments. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expectedDumped), util.MustToJSONIndent(dumpedComments))
+	}
+
+	// Clearing the course removes the comments.
+	err = ClearCourse(course)
+	if err != nil {
+		test.Fatalf("Failed to clear course: '%v'.", err)
+	}
+
+	comments, err = GetLMSComments(course, "A1")
+	if err != nil {
+		test.Fatalf("Failed to get cleared LMS comments: '%v'.", err)
+	}
+
+	if len(comments) != 0 {
+		test.Fatalf("Found LMS comments after clearing the course: '%s'.", util.MustToJSONIndent(comments))
+	}
+}
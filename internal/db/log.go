@@ -27,7 +27,23 @@ func GetLogRecordsFull(query log.ParsedLogQuery, useTestingData bool) ([]*log.Re
 		}
 	}
 
-	return records, nil
+	return log.LimitAndSort(records, query), nil
+}
+
+func CountLogRecords(level log.LogLevel, before timestamp.Timestamp) (int, error) {
+	if backend == nil {
+		return 0, fmt.Errorf("Database has not been opened.")
+	}
+
+	return backend.CountLogRecords(level, before)
+}
+
+func RemoveLogRecords(level log.LogLevel, before timestamp.Timestamp) (int, error) {
+	if backend == nil {
+		return 0, fmt.Errorf("Database has not been opened.")
+	}
+
+	return backend.RemoveLogRecords(level, before)
 }
 
 var TESTING_LOG_RECORDS []*log.Record = []*log.Record{
@@ -261,6 +261,140 @@ func (this *DBTests) DBTestGetLogsContext(test *testing.T) {
 	}
 }
 
+func (this *DBTests) DBTestGetLogsSearch(test *testing.T) {
+	log.SetLevels(log.LevelOff, log.LevelOff)
+	defer log.SetLevelFatal()
+
+	// Wait for old logs to get written.
+	time.Sleep(10 * time.Millisecond)
+
+	Clear()
+	defer Clear()
+
+	records := []*log.Record{
+		&log.Record{
+			Level:      log.LevelInfo,
+			Message:    "Started container.",
+			Timestamp:  timestamp.Timestamp(100),
+			Attributes: map[string]any{"container-name": "alpha"},
+		},
+		&log.Record{
+			Level:      log.LevelError,
+			Message:    "Container failed.",
+			Timestamp:  timestamp.Timestamp(200),
+			Error:      "Exit Code 1",
+			Attributes: map[string]any{"container-name": "alpha", "request-id": "123"},
+		},
+		&log.Record{
+			Level:      log.LevelInfo,
+			Message:    "Started container.",
+			Timestamp:  timestamp.Timestamp(300),
+			Attributes: map[string]any{"container-name": "beta"},
+		},
+		&log.Record{
+			Level:     log.LevelWarn,
+			Message:   "Slow request.",
+			Timestamp: timestamp.Timestamp(400),
+			Error:     "timeout",
+		},
+	}
+
+	for _, record := range records {
+		err := backend.LogDirect(record)
+		if err != nil {
+			test.Fatalf("Failed to store log record: '%v'.", err)
+		}
+	}
+
+	testCases := []struct {
+		query           log.ParsedLogQuery
+		expectedRecords []*log.Record
+	}{
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo},
+			records,
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, MessageText: "started"},
+			[]*log.Record{records[0], records[2]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, ErrorText: "CODE"},
+			[]*log.Record{records[1]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, Attributes: map[string]string{"container-name": "alpha"}},
+			[]*log.Record{records[0], records[1]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, Attributes: map[string]string{"container-name": "alpha", "request-id": "123"}},
+			[]*log.Record{records[1]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, Attributes: map[string]string{"container-name": "ZZZ"}},
+			[]*log.Record{},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, Before: timestamp.Timestamp(300)},
+			[]*log.Record{records[0], records[1]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, After: timestamp.Timestamp(200), Before: timestamp.Timestamp(400)},
+			[]*log.Record{records[1], records[2]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, Sort: 1},
+			[]*log.Record{records[3], records[2], records[1], records[0]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, Sort: 1, Offset: 1, Limit: 2},
+			[]*log.Record{records[2], records[1]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, MessageText: "container", Limit: 1},
+			[]*log.Record{records[0]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, Offset: 10},
+			[]*log.Record{},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, Sort: -1, Limit: 2},
+			[]*log.Record{records[0], records[1]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelWarn, Sort: 1, Limit: 1},
+			[]*log.Record{records[3]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelWarn, Sort: -1, Offset: 1},
+			[]*log.Record{records[3]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, Before: timestamp.Timestamp(400), Sort: 1, Limit: 1},
+			[]*log.Record{records[2]},
+		},
+		{
+			log.ParsedLogQuery{Level: log.LevelInfo, MessageText: "started", Sort: 1, Offset: 1},
+			[]*log.Record{records[0]},
+		},
+	}
+
+	for i, testCase := range testCases {
+		actualRecords, err := GetLogRecords(testCase.query)
+		if err != nil {
+			test.Errorf("Case %d: Failed to get log records: '%v'.", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(testCase.expectedRecords, actualRecords) {
+			test.Errorf("Case %d: Unexpected records. Expected: %s, Actual: %s.", i,
+				util.MustToJSONIndent(testCase.expectedRecords), util.MustToJSONIndent(actualRecords))
+			continue
+		}
+	}
+}
+
 func (this *DBTests) DBTestGetTestingLogs(test *testing.T) {
 	testCases := []struct {
 		query           log.ParsedLogQuery
@@ -281,6 +415,23 @@ func (this *DBTests) DBTestGetTestingLogs(test *testing.T) {
 			},
 			[]*log.Record{TESTING_LOG_RECORDS[0]},
 		},
+		{
+			log.ParsedLogQuery{
+				Level:     log.LevelTrace,
+				ErrorText: "warning",
+				Sort:      1,
+			},
+			[]*log.Record{TESTING_LOG_RECORDS[7], TESTING_LOG_RECORDS[6]},
+		},
+		{
+			log.ParsedLogQuery{
+				Level:  log.LevelTrace,
+				Before: timestamp.Timestamp(300),
+				Offset: 1,
+				Limit:  2,
+			},
+			[]*log.Record{TESTING_LOG_RECORDS[1], TESTING_LOG_RECORDS[2]},
+		},
 	}
 
 	for i, testCase := range testCases {
@@ -297,3 +448,67 @@ func (this *DBTests) DBTestGetTestingLogs(test *testing.T) {
 		}
 	}
 }
+
+func (this *DBTests) DBTestRemoveLogRecords(test *testing.T) {
+	log.SetLevels(log.LevelOff, log.LevelOff)
+	defer log.SetLevelFatal()
+
+	// Wait for old logs to get written.
+	time.Sleep(10 * time.Millisecond)
+
+	Clear()
+	defer Clear()
+
+	for _, record := range TESTING_LOG_RECORDS {
+		err := backend.LogDirect(record)
+		if err != nil {
+			test.Fatalf("Failed to store log record: '%v'.", err)
+		}
+	}
+
+	testCases := []struct {
+		level         log.LogLevel
+		before        timestamp.Timestamp
+		expectedCount int
+	}{
+		{log.LevelDebug, timestamp.Timestamp(250), 1},
+		{log.LevelDebug, timestamp.Timestamp(250), 0},
+		{log.LevelTrace, timestamp.Timestamp(1000), 2},
+		{log.LevelInfo, timestamp.Timestamp(0), 0},
+	}
+
+	for i, testCase := range testCases {
+		count, err := CountLogRecords(testCase.level, testCase.before)
+		if err != nil {
+			test.Errorf("Case %d: Failed to count log records: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.expectedCount != count {
+			test.Errorf("Case %d: Unexpected count before removal. Expected: %d, Actual: %d.", i, testCase.expectedCount, count)
+			continue
+		}
+
+		count, err = RemoveLogRecords(testCase.level, testCase.before)
+		if err != nil {
+			test.Errorf("Case %d: Failed to remove log records: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.expectedCount != count {
+			test.Errorf("Case %d: Unexpected count. Expected: %d, Actual: %d.", i, testCase.expectedCount, count)
+			continue
+		}
+	}
+
+	records, err := GetLogRecords(log.ParsedLogQuery{Level: log.LevelTrace})
+	if err != nil {
+		test.Fatalf("Failed to get log records: '%v'.", err)
+	}
+
+	expected := TESTING_LOG_RECORDS[3:]
+	if !reflect.DeepEqual(expected, records) {
+		test.Fatalf("Unexpected records. Expected: %s, Actual: %s.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(records))
+	}
+}
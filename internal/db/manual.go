@@ -0,0 +1,34 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/common"
+	"github.com/edulinq/autograder/internal/model"
+)
+
+func SaveManualGrade(grade *model.ManualGrade) error {
+	if backend == nil {
+		return fmt.Errorf("Database has not been opened.")
+	}
+
+	return backend.SaveManualGrade(grade)
+}
+
+func GetManualGrade(assignment *model.Assignment, email string, submissionID string) (*model.ManualGrade, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("Database has not been opened.")
+	}
+
+	shortSubmissionID := common.GetShortSubmissionID(submissionID)
+	return backend.GetManualGrade(assignment, email, shortSubmissionID)
+}
+
+func GetLatestManualGrade(assignment *model.Assignment, email string, submissionID string) (*model.ManualGrade, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("Database has not been opened.")
+	}
+
+	shortSubmissionID := common.GetShortSubmissionID(submissionID)
+	return backend.GetLatestManualGrade(assignment, email, shortSubmissionID)
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *DBTests) DBTestManualGradeBase(test *testing.T) {
+	ResetForTesting()
+	defer ResetForTesting()
+
+	assignment := MustGetTestAssignment()
+	email := "course-student@test.edulinq.org"
+
+	grade, err := GetManualGrade(assignment, email, "")
+	if err != nil {
+		test.Fatalf("Failed to get initial manual grade: '%v'.", err)
+	}
+
+	if grade != nil {
+		test.Fatalf("Found a manual grade before one was saved: '%s'.", util.MustToJSONIndent(grade))
+	}
+
+	expected := &model.ManualGrade{
+		ShortID:      "1697406265",
+		CourseID:     "course101",
+		AssignmentID: "hw0",
+		User:         email,
+		Grader:       "course-grader@test.edulinq.org",
+		UpdateTime:   timestamp.FromMSecs(100),
+		Selections:   []string{"style"},
+		Comments:     "Nice.",
+		Adjustment:   -0.5,
+	}
+
+	err = expected.Validate(assignment.Rubric)
+	if err != nil {
+		test.Fatalf("Failed to validate manual grade: '%v'.", err)
+	}
+
+	err = SaveManualGrade(expected)
+	if err != nil {
+		test.Fatalf("Failed to save manual grade: '%v'.", err)
+	}
+
+	testCases := []struct {
+		email            string
+		targetSubmission string
+		expected         *model.ManualGrade
+	}{
+		{email, "1697406265", expected},
+		{email, expected.ID, expected},
+
+		// Other submissions do not have a manual grade.
+		{email, "", nil},
+		{email, "1697406256", nil},
+		{email, "ZZZ", nil},
+		{"ZZZ@test.edulinq.org", "", nil},
+		{"ZZZ@test.edulinq.org", "1697406265", nil},
+	}
+
+	for i, testCase := range testCases {
+		grade, err := GetManualGrade(assignment, testCase.email, testCase.targetSubmission)
+		if err != nil {
+			test.Errorf("Case %d: Failed to get manual grade: '%v'.", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(testCase.expected, grade) {
+			test.Errorf("Case %d: Unexpected manual grade. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(testCase.expected), util.MustToJSONIndent(grade))
+			continue
+		}
+	}
+
+	// Update the existing grade.
+	expected.Selections = []string{"docs"}
+	expected.Comments = ""
+
+	err = SaveManualGrade(expected)
+	if err != nil {
+		test.Fatalf("Failed to update manual grade: '%v'.", err)
+	}
+
+	grade, err = GetManualGrade(assignment, email, "1697406265")
+	if err != nil {
+		test.Fatalf("Failed to get updated manual grade: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, grade) {
+		test.Fatalf("Unexpected updated manual grade. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(grade))
+	}
+
+	// Manual grades show up in dumps.
+	tempDir := util.MustMkDirTemp("test-manual-grade-dump-")
+	defer util.RemoveDirent(tempDir)
+
+	err = DumpCourse(assignment.GetCourse(), tempDir)
+	if err != nil {
+		test.Fatalf("Failed to dump course: '%v'.", err)
+	}
+
+	path := filepath.Join(tempDir, model.SUBMISSIONS_DIRNAME, "hw0", email, "1697406265", model.MANUAL_GRADE_FILENAME)
+
+	var dumpedGrade model.ManualGrade
+	err = util.JSONFromFile(path, &dumpedGrade)
+	if err != nil {
+		test.Fatalf("Failed to read dumped manual grade: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, &dumpedGrade) {
+		test.Fatalf("Unexpected dumped manual grade. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(dumpedGrade))
+	}
+
+	// Removing the submission also removes the manual grade.
+	_, err = RemoveSubmission(assignment, email, "1697406265")
+	if err != nil {
+		test.Fatalf("Failed to remove submission: '%v'.", err)
+	}
+
+	grade, err = GetManualGrade(assignment, email, "1697406265")
+	if err != nil {
+		test.Fatalf("Failed to get removed manual grade: '%v'.", err)
+	}
+
+	if grade != nil {
+		test.Fatalf("Found a manual grade for a removed submission: '%s'.", util.MustToJSONIndent(grade))
+	}
+
+	// Manual grades cannot be saved for missing submissions.
+	err = SaveManualGrade(expected)
+	if err == nil {
+		test.Fatalf("Did not get an error when saving a manual grade for a missing submission.")
+	}
+}
+
+func (this *DBTests) DBTestGetLatestManualGrade(test *testing.T) {
+	ResetForTesting()
+	defer ResetForTesting()
+
+	assignment := MustGetTestAssignment()
+	email := "course-student@test.edulinq.org"
+
+	grades := make(map[string]*model.ManualGrade)
+	for _, shortID := range []string{"1697406256", "1697406265"} {
+		grade := &model.ManualGrade{
+			ShortID:      shortID,
+			CourseID:     "course101",
+			AssignmentID: "hw0",
+			User:         email,
+			Grader:       "course-grader@test.edulinq.org",
+			UpdateTime:   timestamp.FromMSecs(100),
+			Selections:   []string{"style"},
+		}
+
+		err := grade.Validate(assignment.Rubric)
+		if err != nil {
+			test.Fatalf("Failed to validate manual grade '%s': '%v'.", shortID, err)
+		}
+
+		err = SaveManualGrade(grade)
+		if err != nil {
+			test.Fatalf("Failed to save manual grade '%s': '%v'.", shortID, err)
+		}
+
+		grades[shortID] = grade
+	}
+
+	testCases := []struct {
+		email            string
+		targetSubmission string
+		expected         *model.ManualGrade
+	}{
+		// All submissions.
+		{email, "", grades["1697406265"]},
+
+		// The most recent submission was not graded.
+		{email, "1697406272", grades["1697406265"]},
+		{email, "course101::hw0::course-student@test.edulinq.org::1697406272", grades["1697406265"]},
+
+		// Graded submissions.
+		{email, "1697406265", grades["1697406265"]},
+		{email, "1697406256", grades["1697406256"]},
+
+		// Before any graded submissions.
+		{email, "1697406255", nil},
+
+		// Missing user.
+		{"ZZZ@test.edulinq.org", "", nil},
+	}
+
+	for i, testCase := range testCases {
+		grade, err := GetLatestManualGrade(assignment, testCase.email, testCase.targetSubmission)
+		if err != nil {
+			test.Errorf("Case %d: Failed to get latest manual grade: '%v'.", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(testCase.expected, grade) {
+			test.Errorf("Case %d: Unexpected manual grade. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(testCase.expected), util.MustToJSONIndent(grade))
+			continue
+		}
+	}
+}
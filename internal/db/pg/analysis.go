@@ -0,0 +1,216 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) GetIndividualAnalysis(fullSubmissionIDs []string) (map[string]*model.IndividualAnalysis, error) {
+	results := make(map[string]*model.IndividualAnalysis, len(fullSubmissionIDs))
+
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT data FROM analysis_individual WHERE full_id = ANY($1)`,
+		fullSubmissionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query individual analysis: '%w'.", err)
+	}
+
+	records, err := collectJSONRows[model.IndividualAnalysis](rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read individual analysis: '%w'.", err)
+	}
+
+	for _, record := range records {
+		results[record.FullID] = record
+	}
+
+	return results, nil
+}
+
+func (this *backend) GetPairwiseAnalysis(keys []model.PairwiseKey) (map[model.PairwiseKey]*model.PairwiseAnalysis, error) {
+	results := make(map[model.PairwiseKey]*model.PairwiseAnalysis, len(keys))
+
+	firstIDs, secondIDs := splitPairwiseKeys(keys)
+
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT data FROM analysis_pairwise
+		WHERE (full_id_1, full_id_2) IN (SELECT * FROM UNNEST($1::TEXT[], $2::TEXT[]))`,
+		firstIDs, secondIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query pairwise analysis: '%w'.", err)
+	}
+
+	records, err := collectJSONRows[model.PairwiseAnalysis](rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read pairwise analysis: '%w'.", err)
+	}
+
+	for _, record := range records {
+		results[record.SubmissionIDs] = record
+	}
+
+	return results, nil
+}
+
+func (this *backend) RemoveIndividualAnalysis(fullSubmissionIDs []string) error {
+	_, err := this.pool.Exec(context.Background(),
+		`DELETE FROM analysis_individual WHERE full_id = ANY($1)`,
+		fullSubmissionIDs)
+	if err != nil {
+		return fmt.Errorf("Failed to remove individual analysis: '%w'.", err)
+	}
+
+	return nil
+}
+
+func (this *backend) RemovePairwiseAnalysis(keys []model.PairwiseKey) error {
+	firstIDs, secondIDs := splitPairwiseKeys(keys)
+
+	_, err := this.pool.Exec(context.Background(),
+		`DELETE FROM analysis_pairwise
+		WHERE (full_id_1, full_id_2) IN (SELECT * FROM UNNEST($1::TEXT[], $2::TEXT[]))`,
+		firstIDs, secondIDs)
+	if err != nil {
+		return fmt.Errorf("Failed to remove pairwise analysis: '%w'.", err)
+	}
+
+	return nil
+}
+
+func (this *backend) StoreIndividualAnalysis(records []*model.IndividualAnalysis) error {
+	return this.withTransaction(func(tx pgx.Tx) error {
+		for _, record := range records {
+			if record.CourseID == "" {
+				// This would be a bit strange, just log and skip it.
+				log.Warn("Found empty course ID in individual analysis.", log.NewAttr("record", record))
+				continue
+			}
+
+			data, err := util.ToJSON(record)
+			if err != nil {
+				return fmt.Errorf("Failed to serialize individual analysis '%s': '%w'.", record.FullID, err)
+			}
+
+			_, err = tx.Exec(context.Background(),
+				`INSERT INTO analysis_individual (full_id, course_id, data) VALUES ($1, $2, $3)
+				ON CONFLICT (full_id) DO UPDATE SET course_id = EXCLUDED.course_id, data = EXCLUDED.data`,
+				record.FullID, record.CourseID, data)
+			if err != nil {
+				return fmt.Errorf("Failed to store individual analysis for course '%s': '%w'.", record.CourseID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) StorePairwiseAnalysis(records []*model.PairwiseAnalysis) error {
+	return this.withTransaction(func(tx pgx.Tx) error {
+		for _, record := range records {
+			courseID := record.SubmissionIDs.Course()
+			if courseID == "" {
+				// This would be a bit strange, just log and skip it.
+				log.Warn("Found empty course ID in pairwise analysis.", log.NewAttr("record", record))
+				continue
+			}
+
+			data, err := util.ToJSON(record)
+			if err != nil {
+				return fmt.Errorf("Failed to serialize pairwise analysis '%s': '%w'.", record.SubmissionIDs.String(), err)
+			}
+
+			_, err = tx.Exec(context.Background(),
+				`INSERT INTO analysis_pairwise (full_id_1, full_id_2, course_id, data) VALUES ($1, $2, $3, $4)
+				ON CONFLICT (full_id_1, full_id_2) DO UPDATE SET course_id = EXCLUDED.course_id, data = EXCLUDED.data`,
+				record.SubmissionIDs[0], record.SubmissionIDs[1], courseID, data)
+			if err != nil {
+				return fmt.Errorf("Failed to store pairwise analysis for course '%s': '%w'.", courseID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Write out all analysis for a course in the same format as the disk database.
+func (this *backend) dumpAnalysis(course *model.Course, targetDir string) error {
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT data FROM analysis_individual WHERE course_id = $1 ORDER BY full_id`,
+		course.GetID())
+	if err != nil {
+		return fmt.Errorf("Failed to query individual analysis for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	individualRecords, err := collectJSONRows[model.IndividualAnalysis](rows)
+	if err != nil {
+		return fmt.Errorf("Failed to read individual analysis for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	if len(individualRecords) > 0 {
+		err = util.AppendJSONLFileMany(filepath.Join(targetDir, DUMP_ANALYSIS_INDIVIDUAL_FILENAME), individualRecords)
+		if err != nil {
+			return fmt.Errorf("Failed to dump individual analysis for course '%s': '%w'.", course.GetID(), err)
+		}
+	}
+
+	rows, err = this.pool.Query(context.Background(),
+		`SELECT data FROM analysis_pairwise WHERE course_id = $1 ORDER BY full_id_1, full_id_2`,
+		course.GetID())
+	if err != nil {
+		return fmt.Errorf("Failed to query pairwise analysis for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	pairwiseRecords, err := collectJSONRows[model.PairwiseAnalysis](rows)
+	if err != nil {
+		return fmt.Errorf("Failed to read pairwise analysis for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	if len(pairwiseRecords) > 0 {
+		err = util.AppendJSONLFileMany(filepath.Join(targetDir, DUMP_ANALYSIS_PAIRWISE_FILENAME), pairwiseRecords)
+		if err != nil {
+			return fmt.Errorf("Failed to dump pairwise analysis for course '%s': '%w'.", course.GetID(), err)
+		}
+	}
+
+	return nil
+}
+
+func splitPairwiseKeys(keys []model.PairwiseKey) ([]string, []string) {
+	firstIDs := make([]string, 0, len(keys))
+	secondIDs := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		firstIDs = append(firstIDs, key[0])
+		secondIDs = append(secondIDs, key[1])
+	}
+
+	return firstIDs, secondIDs
+}
+
+// Read rows that only contain a single JSON column into objects.
+func collectJSONRows[T any](rows pgx.Rows) ([]*T, error) {
+	dataList, err := pgx.CollectRows(rows, pgx.RowTo[[]byte])
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*T, 0, len(dataList))
+	for _, data := range dataList {
+		var record T
+		err = util.JSONFromBytes(data, &record)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
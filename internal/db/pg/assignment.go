@@ -0,0 +1,34 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) SaveAssignment(assignment *model.Assignment) error {
+	return this.withTransaction(func(tx pgx.Tx) error {
+		return saveAssignment(tx, assignment)
+	})
+}
+
+func saveAssignment(tx pgx.Tx, assignment *model.Assignment) error {
+	data, err := util.ToJSON(assignment)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize assignment '%s': '%w'.", assignment.FullID(), err)
+	}
+
+	_, err = tx.Exec(context.Background(),
+		`INSERT INTO assignments (course_id, id, data) VALUES ($1, $2, $3)
+		ON CONFLICT (course_id, id) DO UPDATE SET data = EXCLUDED.data`,
+		assignment.GetCourse().GetID(), assignment.GetID(), data)
+	if err != nil {
+		return fmt.Errorf("Failed to save assignment '%s': '%v'.", assignment.FullID(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// Names used when dumping a course.
+// These match the layout of the disk database so that dumps from any backend look the same.
+const (
+	DUMP_ASSIGNMENTS_DIRNAME          = "assignments"
+	DUMP_ANALYSIS_INDIVIDUAL_FILENAME = "analysis-individual.jsonl"
+	DUMP_ANALYSIS_PAIRWISE_FILENAME   = "analysis-pairwise.jsonl"
+)
+
+func (this *backend) ClearCourse(course *model.Course) error {
+	return this.withTransaction(func(tx pgx.Tx) error {
+		courseID := course.GetID()
+
+		statements := []string{
+			`DELETE FROM courses WHERE id = $1`,
+			`DELETE FROM assignments WHERE course_id = $1`,
+			`DELETE FROM submissions WHERE course_id = $1`,
+			`DELETE FROM submission_ids WHERE course_id = $1`,
+			`DELETE FROM manual_grades WHERE course_id = $1`,
+			`DELETE FROM extensions WHERE course_id = $1`,
+			`DELETE FROM lms_comments WHERE course_id = $1`,
+			`DELETE FROM assignment_groups WHERE course_id = $1`,
+			`DELETE FROM git_repos WHERE course_id = $1`,
+			`DELETE FROM analysis_individual WHERE course_id = $1`,
+			`DELETE FROM analysis_pairwise WHERE course_id = $1`,
+			`UPDATE users SET data = data #- ARRAY['course-info', $1::TEXT] WHERE (data->'course-info') ? $1`,
+		}
+
+		for _, statement := range statements {
+			_, err := tx.Exec(context.Background(), statement, courseID)
+			if err != nil {
+				return fmt.Errorf("Failed to clear course '%s': '%w'.", courseID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) AddTestCourse(path string) (*model.Course, error) {
+	course, submissions, err := model.FullLoadCourseFromPath(util.ShouldAbs(path), true)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.SaveCourse(course)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.SaveSubmissions(course, submissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return course, nil
+}
+
+func (this *backend) SaveCourse(course *model.Course) error {
+	return this.withTransaction(func(tx pgx.Tx) error {
+		data, err := util.ToJSON(course)
+		if err != nil {
+			return fmt.Errorf("Failed to serialize course '%s': '%w'.", course.GetID(), err)
+		}
+
+		_, err = tx.Exec(context.Background(),
+			`INSERT INTO courses (id, data) VALUES ($1, $2)
+			ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data`,
+			course.GetID(), data)
+		if err != nil {
+			return fmt.Errorf("Failed to save course '%s': '%w'.", course.GetID(), err)
+		}
+
+		for _, assignment := range course.Assignments {
+			err = saveAssignment(tx, assignment)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) DumpCourse(course *model.Course, targetDir string) error {
+	dbCourse, err := this.GetCourse(course.GetID())
+	if err != nil {
+		return err
+	}
+
+	if dbCourse == nil {
+		return fmt.Errorf("Cannot dump unknown course '%s'.", course.GetID())
+	}
+
+	err = util.ToJSONFileIndent(dbCourse, filepath.Join(targetDir, model.COURSE_CONFIG_FILENAME))
+	if err != nil {
+		return fmt.Errorf("Failed to dump course config for '%s': '%w'.", course.GetID(), err)
+	}
+
+	for _, assignment := range dbCourse.Assignments {
+		path := filepath.Join(targetDir, DUMP_ASSIGNMENTS_DIRNAME, assignment.GetID(), model.ASSIGNMENT_CONFIG_FILENAME)
+
+		err = util.MkDir(filepath.Dir(path))
+		if err != nil {
+			return fmt.Errorf("Failed to make dump dir for assignment '%s': '%w'.", assignment.FullID(), err)
+		}
+
+		err = util.ToJSONFileIndent(assignment, path)
+		if err != nil {
+			return fmt.Errorf("Failed to dump assignment config for '%s': '%w'.", assignment.FullID(), err)
+		}
+	}
+
+	err = this.dumpSubmissions(dbCourse, filepath.Join(targetDir, model.SUBMISSIONS_DIRNAME))
+	if err != nil {
+		return err
+	}
+
+	err = this.dumpManualGrades(dbCourse, filepath.Join(targetDir, model.SUBMISSIONS_DIRNAME))
+	if err != nil {
+		return err
+	}
+
+	err = this.dumpGroups(dbCourse, targetDir)
+	if err != nil {
+		return err
+	}
+
+	err = this.dumpGitRepos(dbCourse, targetDir)
+	if err != nil {
+		return err
+	}
+
+	err = this.dumpExtensions(dbCourse, targetDir)
+	if err != nil {
+		return err
+	}
+
+	err = this.dumpLMSComments(dbCourse, targetDir)
+	if err != nil {
+		return err
+	}
+
+	err = this.dumpAnalysis(dbCourse, targetDir)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (this *backend) GetCourse(courseID string) (*model.Course, error) {
+	var courseJSON []byte
+	err := this.pool.QueryRow(context.Background(), `SELECT data FROM courses WHERE id = $1`, courseID).Scan(&courseJSON)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get course '%s': '%w'.", courseID, err)
+	}
+
+	rows, err := this.pool.Query(context.Background(), `SELECT data FROM assignments WHERE course_id = $1 ORDER BY id`, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get assignments for course '%s': '%w'.", courseID, err)
+	}
+
+	assignmentJSONs, err := pgx.CollectRows(rows, pgx.RowTo[[]byte])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read assignments for course '%s': '%w'.", courseID, err)
+	}
+
+	return model.LoadCourseFromJSON(courseJSON, assignmentJSONs)
+}
+
+func (this *backend) GetCourses() (map[string]*model.Course, error) {
+	rows, err := this.pool.Query(context.Background(), `SELECT id FROM courses ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list courses: '%w'.", err)
+	}
+
+	courseIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read course IDs: '%w'.", err)
+	}
+
+	courses := make(map[string]*model.Course, len(courseIDs))
+	for _, courseID := range courseIDs {
+		course, err := this.GetCourse(courseID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load course '%s': '%w'", courseID, err)
+		}
+
+		// The course was removed after it was listed.
+		if course == nil {
+			continue
+		}
+
+		courses[course.GetID()] = course
+	}
+
+	return courses, nil
+}
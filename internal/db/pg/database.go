@@ -1,14 +1,174 @@
+// A database backend that stores all data in a Postgres database.
+// Multiple autograder instances may share the same database.
+// Most objects are stored as JSON documents next to the (indexed) columns used to look them up.
+// Writes that need to read existing data first (e.g., merging users) are done inside transactions.
 package pg
 
 import (
 	"context"
 	"fmt"
+	"net/url"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/log"
 )
 
+// An arbitrary (but constant) key used to lock the database when creating tables.
+const SCHEMA_LOCK_KEY int64 = 8675309
+
+var tableNames []string = []string{
+	"courses",
+	"assignments",
+	"users",
+	"submissions",
+	"submission_ids",
+	"manual_grades",
+	"extensions",
+	"lms_comments",
+	"assignment_groups",
+	"git_repos",
+	"tasks",
+	"logs",
+	"metrics",
+	"analysis_individual",
+	"analysis_pairwise",
+}
+
+var schema []string = []string{
+	`CREATE TABLE IF NOT EXISTS courses (
+		id TEXT PRIMARY KEY,
+		data JSONB NOT NULL
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS assignments (
+		course_id TEXT NOT NULL,
+		id TEXT NOT NULL,
+		data JSONB NOT NULL,
+		PRIMARY KEY (course_id, id)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS users (
+		email TEXT PRIMARY KEY,
+		data JSONB NOT NULL
+	)`,
+
+	// Short IDs use the "C" collation so they sort the same way that the disk backend sorts them.
+	`CREATE TABLE IF NOT EXISTS submissions (
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		user_email TEXT NOT NULL,
+		short_id TEXT COLLATE "C" NOT NULL,
+		grading_start_time BIGINT NOT NULL,
+		info JSONB NOT NULL,
+		input_files JSONB NOT NULL,
+		output_files JSONB NOT NULL,
+		stdout BYTEA NOT NULL,
+		stderr BYTEA NOT NULL,
+		PRIMARY KEY (course_id, assignment_id, user_email, short_id)
+	)`,
+
+	// Submission IDs that have been handed out (but may not have been saved yet).
+	`CREATE TABLE IF NOT EXISTS submission_ids (
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		user_email TEXT NOT NULL,
+		short_id TEXT COLLATE "C" NOT NULL,
+		PRIMARY KEY (course_id, assignment_id, user_email, short_id)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS manual_grades (
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		user_email TEXT NOT NULL,
+		short_id TEXT COLLATE "C" NOT NULL,
+		data JSONB NOT NULL,
+		PRIMARY KEY (course_id, assignment_id, user_email, short_id)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS extensions (
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		user_email TEXT NOT NULL,
+		data JSONB NOT NULL,
+		PRIMARY KEY (course_id, assignment_id, user_email)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS lms_comments (
+		course_id TEXT NOT NULL,
+		assignment_lms_id TEXT NOT NULL,
+		user_lms_id TEXT NOT NULL,
+		data JSONB NOT NULL,
+		PRIMARY KEY (course_id, assignment_lms_id, user_lms_id)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS assignment_groups (
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		id TEXT NOT NULL,
+		data JSONB NOT NULL,
+		PRIMARY KEY (course_id, assignment_id, id)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS git_repos (
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		user_email TEXT NOT NULL,
+		data JSONB NOT NULL,
+		PRIMARY KEY (course_id, assignment_id, user_email)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS tasks (
+		hash TEXT PRIMARY KEY,
+		source TEXT NOT NULL,
+		course_id TEXT NOT NULL,
+		next_run_time BIGINT NOT NULL,
+		data JSONB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS tasks_course_index ON tasks (source, course_id)`,
+	`CREATE INDEX IF NOT EXISTS tasks_next_run_time_index ON tasks (next_run_time)`,
+
+	`CREATE TABLE IF NOT EXISTS logs (
+		id BIGSERIAL PRIMARY KEY,
+		level INTEGER NOT NULL,
+		timestamp BIGINT NOT NULL,
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		user_email TEXT NOT NULL,
+		data JSONB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS logs_timestamp_index ON logs (timestamp)`,
+	`CREATE INDEX IF NOT EXISTS logs_context_index ON logs (course_id, assignment_id, user_email)`,
+
+	`CREATE TABLE IF NOT EXISTS metrics (
+		id BIGSERIAL PRIMARY KEY,
+		type TEXT NOT NULL,
+		timestamp BIGINT NOT NULL,
+		attributes JSONB NOT NULL,
+		data JSONB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS metrics_type_timestamp_index ON metrics (type, timestamp)`,
+	`CREATE INDEX IF NOT EXISTS metrics_attributes_index ON metrics USING GIN (attributes)`,
+
+	`CREATE TABLE IF NOT EXISTS analysis_individual (
+		full_id TEXT PRIMARY KEY,
+		course_id TEXT NOT NULL,
+		data JSONB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS analysis_individual_course_index ON analysis_individual (course_id)`,
+
+	`CREATE TABLE IF NOT EXISTS analysis_pairwise (
+		full_id_1 TEXT NOT NULL,
+		full_id_2 TEXT NOT NULL,
+		course_id TEXT NOT NULL,
+		data JSONB NOT NULL,
+		PRIMARY KEY (full_id_1, full_id_2)
+	)`,
+	`CREATE INDEX IF NOT EXISTS analysis_pairwise_course_index ON analysis_pairwise (course_id)`,
+}
+
 type backend struct {
 	pool *pgxpool.Pool
 }
@@ -21,13 +181,95 @@ func Open() (*backend, error) {
 
 	pool, err := pgxpool.New(context.Background(), uri)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to open connection pool to Postgres database at '%s': %w.", uri, err)
+		return nil, fmt.Errorf("Failed to open connection pool to Postgres database at '%s': %w.", redactURI(uri), err)
 	}
 
+	err = pool.Ping(context.Background())
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("Failed to connect to Postgres database at '%s': %w.", redactURI(uri), err)
+	}
+
+	log.Debug("Opened Postgres database.")
+
 	return &backend{pool}, nil
 }
 
+// Get a version of the connection URI that is safe to show in logs and errors (no password).
+// Connection strings that are not URLs (e.g., "host=... password=...") are not shown at all.
+func redactURI(uri string) string {
+	parsed, err := url.Parse(uri)
+	if (err != nil) || (parsed.Scheme == "") {
+		return "<redacted>"
+	}
+
+	return parsed.Redacted()
+}
+
 func (this *backend) Close() error {
 	this.pool.Close()
 	return nil
 }
+
+// Create any missing tables and indexes.
+// Several servers may be starting against the same database,
+// so creation is done while holding an advisory lock.
+func (this *backend) EnsureTables() error {
+	return this.withTransaction(func(tx pgx.Tx) error {
+		_, err := tx.Exec(context.Background(), `SELECT pg_advisory_xact_lock($1)`, SCHEMA_LOCK_KEY)
+		if err != nil {
+			return fmt.Errorf("Failed to acquire schema lock: '%w'.", err)
+		}
+
+		for _, statement := range schema {
+			_, err = tx.Exec(context.Background(), statement)
+			if err != nil {
+				return fmt.Errorf("Failed to create schema (statement: '%s'): '%w'.", statement, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) Clear() error {
+	statement := "TRUNCATE TABLE"
+	for i, tableName := range tableNames {
+		if i > 0 {
+			statement += ","
+		}
+
+		statement += " " + tableName
+	}
+
+	statement += " RESTART IDENTITY"
+
+	_, err := this.pool.Exec(context.Background(), statement)
+	if err != nil {
+		return fmt.Errorf("Failed to clear Postgres database: '%w'.", err)
+	}
+
+	return nil
+}
+
+// Run a function inside of a transaction.
+// The transaction will be committed if the function returns nil, and rolled back otherwise.
+func (this *backend) withTransaction(function func(tx pgx.Tx) error) error {
+	tx, err := this.pool.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("Failed to begin transaction: '%w'.", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	err = function(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(context.Background())
+	if err != nil {
+		return fmt.Errorf("Failed to commit transaction: '%w'.", err)
+	}
+
+	return nil
+}
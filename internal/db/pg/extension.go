@@ -0,0 +1,88 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) SaveExtension(extension *model.Extension) error {
+	data, err := util.ToJSON(extension)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize extension for user '%s': '%w'.", extension.User, err)
+	}
+
+	return this.withTransaction(func(tx pgx.Tx) error {
+		_, err := tx.Exec(context.Background(),
+			`INSERT INTO extensions (course_id, assignment_id, user_email, data) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (course_id, assignment_id, user_email) DO UPDATE SET data = EXCLUDED.data`,
+			extension.CourseID, extension.AssignmentID, extension.User, data)
+		if err != nil {
+			return fmt.Errorf("Failed to save extension for user '%s': '%w'.", extension.User, err)
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) GetExtensions(courseID string) ([]*model.Extension, error) {
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT data FROM extensions WHERE course_id = $1 ORDER BY assignment_id, user_email`,
+		courseID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query extensions for course '%s': '%w'.", courseID, err)
+	}
+
+	extensions, err := collectJSONRows[model.Extension](rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read extensions for course '%s': '%w'.", courseID, err)
+	}
+
+	return extensions, nil
+}
+
+func (this *backend) RemoveExtension(courseID string, assignmentID string, email string) (bool, error) {
+	exists := false
+
+	err := this.withTransaction(func(tx pgx.Tx) error {
+		tag, err := tx.Exec(context.Background(),
+			`DELETE FROM extensions WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3`,
+			courseID, assignmentID, email)
+		if err != nil {
+			return fmt.Errorf("Failed to remove extension for user '%s': '%w'.", email, err)
+		}
+
+		count := tag.RowsAffected()
+
+		exists = (count > 0)
+		return nil
+	})
+
+	return exists, err
+}
+
+// Write out all extensions for a course in the same format as the disk database.
+func (this *backend) dumpExtensions(course *model.Course, targetDir string) error {
+	extensions, err := this.GetExtensions(course.GetID())
+	if err != nil {
+		return err
+	}
+
+	if len(extensions) == 0 {
+		return nil
+	}
+
+	path := filepath.Join(targetDir, model.EXTENSIONS_FILENAME)
+
+	err = util.ToJSONFileIndent(extensions, path)
+	if err != nil {
+		return fmt.Errorf("Failed to dump extensions for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	return nil
+}
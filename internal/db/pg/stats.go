@@ -0,0 +1,408 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) GetMetrics(query stats.Query) ([]*stats.Metric, error) {
+	if query.Type == "" {
+		return nil, fmt.Errorf("No metric type was given.")
+	}
+
+	where := query.Where
+	if where == nil {
+		where = make(map[stats.MetricAttribute]any)
+	}
+
+	whereJSON, err := util.ToJSON(where)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to serialize metric query attributes: '%w'.", err)
+	}
+
+	// JSON containment matches the exact attribute values (including types) like stats.Query.Match().
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT data FROM metrics WHERE type = $1 AND attributes @> $2::JSONB ORDER BY id`,
+		string(query.Type), whereJSON)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query metrics: '%w'.", err)
+	}
+
+	dataList, err := pgx.CollectRows(rows, pgx.RowTo[[]byte])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read metrics: '%w'.", err)
+	}
+
+	records := make([]*stats.Metric, 0, len(dataList))
+	for _, data := range dataList {
+		var record stats.Metric
+		err = util.JSONFromBytes(data, &record)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to deserialize metric: '%w'.", err)
+		}
+
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
+
+func (this *backend) GetMetricsInWindow(query stats.Query) ([]*stats.Metric, error) {
+	if query.Type == "" {
+		return nil, fmt.Errorf("No metric type was given.")
+	}
+
+	before := int64(query.Before)
+	if before == 0 {
+		before = math.MaxInt64
+	}
+
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT data FROM metrics WHERE type = $1 AND timestamp > $2 AND timestamp < $3 ORDER BY id`,
+		string(query.Type), int64(query.After), before)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query metrics: '%w'.", err)
+	}
+
+	dataList, err := pgx.CollectRows(rows, pgx.RowTo[[]byte])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read metrics: '%w'.", err)
+	}
+
+	records := make([]*stats.Metric, 0, len(dataList))
+	for _, data := range dataList {
+		var record stats.Metric
+		err = util.JSONFromBytes(data, &record)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to deserialize metric: '%w'.", err)
+		}
+
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
+
+func (this *backend) CountMetricsInWindow(query stats.Query) (int, error) {
+	if query.Type == "" {
+		return 0, fmt.Errorf("No metric type was given.")
+	}
+
+	before := int64(query.Before)
+	if before == 0 {
+		before = math.MaxInt64
+	}
+
+	var count int64
+	err := this.pool.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM metrics WHERE type = $1 AND timestamp > $2 AND timestamp < $3`,
+		string(query.Type), int64(query.After), before).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count metrics: '%w'.", err)
+	}
+
+	return int(count), nil
+}
+
+func (this *backend) GetOldestMetricTimeInWindow(query stats.Query) (*timestamp.Timestamp, error) {
+	if query.Type == "" {
+		return nil, fmt.Errorf("No metric type was given.")
+	}
+
+	before := int64(query.Before)
+	if before == 0 {
+		before = math.MaxInt64
+	}
+
+	var oldest *int64
+	err := this.pool.QueryRow(context.Background(),
+		`SELECT MIN(timestamp) FROM metrics WHERE type = $1 AND timestamp > $2 AND timestamp < $3`,
+		string(query.Type), int64(query.After), before).Scan(&oldest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the oldest metric time: '%w'.", err)
+	}
+
+	if oldest == nil {
+		return nil, nil
+	}
+
+	result := timestamp.Timestamp(*oldest)
+	return &result, nil
+}
+
+func (this *backend) GetAggregateMetrics(query stats.Query) ([]*stats.AggregateResult, error) {
+	if query.Type == "" {
+		return nil, fmt.Errorf("No metric type was given.")
+	}
+
+	aggregation := query.Aggregate
+	if aggregation == nil {
+		return nil, fmt.Errorf("No aggregation was given.")
+	}
+
+	where := query.Where
+	if where == nil {
+		where = make(map[stats.MetricAttribute]any)
+	}
+
+	whereJSON, err := util.ToJSON(where)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to serialize metric query attributes: '%w'.", err)
+	}
+
+	before := int64(query.Before)
+	if before == 0 {
+		before = math.MaxInt64
+	}
+
+	args := []any{string(query.Type), whereJSON, int64(query.After), before}
+	groupColumns := make([]string, 0, len(aggregation.GroupBy)+1)
+
+	for _, attribute := range aggregation.GroupBy {
+		args = append(args, string(attribute))
+		groupColumns = append(groupColumns, fmt.Sprintf("attributes->($%d::TEXT)", len(args)))
+	}
+
+	bucketSize := aggregation.GetBucketSize()
+	if bucketSize > 0 {
+		// Round down to the start of the bucket (even for negative times).
+		args = append(args, bucketSize)
+		groupColumns = append(groupColumns, fmt.Sprintf("(timestamp - (((timestamp %% $%d::BIGINT) + $%d::BIGINT) %% $%d::BIGINT))", len(args), len(args), len(args)))
+	}
+
+	// Rollups count as all the metrics they summarize (see stats.Downsample()).
+	columns := append(slices.Clone(groupColumns),
+		"COALESCE(SUM(metric_count), 0)::BIGINT",
+		"SUM(metric_sum)",
+		"SUM(metric_sum) / NULLIF(SUM(metric_count), 0)",
+		"MIN(metric_min)",
+		"MAX(metric_max)")
+
+	if len(aggregation.Percentiles) > 0 {
+		fractions := make([]float64, 0, len(aggregation.Percentiles))
+		for _, percentile := range aggregation.Percentiles {
+			fractions = append(fractions, percentile/100.0)
+		}
+
+		// percentile_cont() interpolates the same way as stats.Aggregate().
+		args = append(args, fractions)
+		columns = append(columns, fmt.Sprintf("percentile_cont($%d::DOUBLE PRECISION[]) WITHIN GROUP (ORDER BY value)", len(args)))
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT %s
+		FROM (
+			SELECT
+				timestamp,
+				attributes,
+				COALESCE((data->>'value')::DOUBLE PRECISION, 0) AS value,
+				COALESCE((data->'rollup'->>'count')::BIGINT, 1) AS metric_count,
+				COALESCE((data->'rollup'->>'sum')::DOUBLE PRECISION, (data->>'value')::DOUBLE PRECISION, 0) AS metric_sum,
+				COALESCE((data->'rollup'->>'min')::DOUBLE PRECISION, (data->>'value')::DOUBLE PRECISION, 0) AS metric_min,
+				COALESCE((data->'rollup'->>'max')::DOUBLE PRECISION, (data->>'value')::DOUBLE PRECISION, 0) AS metric_max
+			FROM metrics
+			WHERE
+				type = $1
+				AND attributes @> $2::JSONB
+				AND timestamp > $3
+				AND timestamp < $4
+		) AS matched`, strings.Join(columns, ", "))
+
+	if len(groupColumns) > 0 {
+		ordinals := make([]string, 0, len(groupColumns))
+		for i := range groupColumns {
+			ordinals = append(ordinals, fmt.Sprintf("%d", i+1))
+		}
+
+		sql += " GROUP BY " + strings.Join(ordinals, ", ")
+	}
+
+	rows, err := this.pool.Query(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to aggregate metrics: '%w'.", err)
+	}
+	defer rows.Close()
+
+	results := make([]*stats.AggregateResult, 0)
+
+	for rows.Next() {
+		groupData := make([][]byte, len(aggregation.GroupBy))
+		var bucketStart int64
+		var count int64
+		var sum, mean, min, max *float64
+		var percentiles []float64
+
+		dest := make([]any, 0, len(columns))
+		for i := range groupData {
+			dest = append(dest, &groupData[i])
+		}
+
+		if bucketSize > 0 {
+			dest = append(dest, &bucketStart)
+		}
+
+		dest = append(dest, &count, &sum, &mean, &min, &max)
+
+		if len(aggregation.Percentiles) > 0 {
+			dest = append(dest, &percentiles)
+		}
+
+		err = rows.Scan(dest...)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read aggregate metrics: '%w'.", err)
+		}
+
+		// Aggregating without any groups will always return a row.
+		if count == 0 {
+			continue
+		}
+
+		result := &stats.AggregateResult{
+			Values: make(map[string]float64),
+		}
+
+		for i, attribute := range aggregation.GroupBy {
+			if groupData[i] == nil {
+				continue
+			}
+
+			var value any
+			err = util.JSONFromBytes(groupData[i], &value)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to deserialize aggregate group value: '%w'.", err)
+			}
+
+			if result.Group == nil {
+				result.Group = make(map[stats.MetricAttribute]any)
+			}
+
+			result.Group[attribute] = value
+		}
+
+		if bucketSize > 0 {
+			start := timestamp.Timestamp(bucketStart)
+			result.BucketStart = &start
+		}
+
+		functionValues := map[stats.AggregateFunction]*float64{
+			stats.AggregateFunctionSum:  sum,
+			stats.AggregateFunctionMean: mean,
+			stats.AggregateFunctionMin:  min,
+			stats.AggregateFunctionMax:  max,
+		}
+
+		for _, function := range aggregation.Functions {
+			if function == stats.AggregateFunctionCount {
+				result.Values[string(function)] = float64(count)
+			} else if functionValues[function] != nil {
+				result.Values[string(function)] = *functionValues[function]
+			}
+		}
+
+		for i, percentile := range aggregation.Percentiles {
+			if i < len(percentiles) {
+				result.Values[stats.GetPercentileKey(percentile)] = percentiles[i]
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read aggregate metrics: '%w'.", err)
+	}
+
+	return stats.SortAndLimitAggregates(results, query), nil
+}
+
+func (this *backend) ReplaceMetrics(query stats.Query, replacements []*stats.Metric) (int, error) {
+	if query.Type == "" {
+		return 0, fmt.Errorf("No metric type was given.")
+	}
+
+	before := int64(query.Before)
+	if before == 0 {
+		before = math.MaxInt64
+	}
+
+	count := 0
+
+	err := this.withTransaction(func(tx pgx.Tx) error {
+		result, err := tx.Exec(context.Background(),
+			`DELETE FROM metrics WHERE type = $1 AND timestamp > $2 AND timestamp < $3`,
+			string(query.Type), int64(query.After), before)
+		if err != nil {
+			return fmt.Errorf("Failed to remove metrics: '%w'.", err)
+		}
+
+		count = int(result.RowsAffected())
+
+		for _, replacement := range replacements {
+			attributesJSON, data, err := serializeMetric(replacement)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(context.Background(),
+				`INSERT INTO metrics (type, timestamp, attributes, data) VALUES ($1, $2, $3, $4)`,
+				string(replacement.Type), int64(replacement.Timestamp), attributesJSON, data)
+			if err != nil {
+				return fmt.Errorf("Failed to store replacement metric: '%w'.", err)
+			}
+		}
+
+		return nil
+	})
+
+	return count, err
+}
+
+func (this *backend) StoreMetric(record *stats.Metric) error {
+	if record.Type == "" {
+		return fmt.Errorf("No metric type was given.")
+	}
+
+	attributesJSON, data, err := serializeMetric(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.pool.Exec(context.Background(),
+		`INSERT INTO metrics (type, timestamp, attributes, data) VALUES ($1, $2, $3, $4)`,
+		string(record.Type), int64(record.Timestamp), attributesJSON, data)
+	if err != nil {
+		return fmt.Errorf("Failed to store metric: '%w'.", err)
+	}
+
+	return nil
+}
+
+// Get the JSON for a metric's attributes and the metric itself.
+func serializeMetric(record *stats.Metric) (string, string, error) {
+	attributes := record.Attributes
+	if attributes == nil {
+		attributes = make(map[stats.MetricAttribute]any)
+	}
+
+	attributesJSON, err := util.ToJSON(attributes)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to serialize metric attributes: '%w'.", err)
+	}
+
+	data, err := util.ToJSON(record)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to serialize metric: '%w'.", err)
+	}
+
+	return attributesJSON, data, nil
+}
@@ -0,0 +1,549 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const SUBMISSION_COLUMNS = `info, input_files, output_files, stdout, stderr`
+
+func (this *backend) SaveSubmissions(course *model.Course, submissions []*model.GradingResult) error {
+	return this.withTransaction(func(tx pgx.Tx) error {
+		var errs error = nil
+
+		for _, submission := range submissions {
+			errs = errors.Join(errs, saveSubmission(tx, submission))
+		}
+
+		return errs
+	})
+}
+
+func saveSubmission(tx pgx.Tx, submission *model.GradingResult) error {
+	info := submission.Info
+
+	infoJSON, err := util.ToJSON(info)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize submission result '%s': '%w'.", info.ID, err)
+	}
+
+	inputJSON, err := serializeFiles(submission.InputFilesGZip)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize submission input files '%s': '%w'.", info.ID, err)
+	}
+
+	outputJSON, err := serializeFiles(submission.OutputFilesGZip)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize submission output files '%s': '%w'.", info.ID, err)
+	}
+
+	_, err = tx.Exec(context.Background(),
+		`INSERT INTO submissions
+			(course_id, assignment_id, user_email, short_id, grading_start_time, `+SUBMISSION_COLUMNS+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (course_id, assignment_id, user_email, short_id) DO UPDATE SET
+			grading_start_time = EXCLUDED.grading_start_time,
+			info = EXCLUDED.info,
+			input_files = EXCLUDED.input_files,
+			output_files = EXCLUDED.output_files,
+			stdout = EXCLUDED.stdout,
+			stderr = EXCLUDED.stderr`,
+		info.CourseID, info.AssignmentID, info.User, info.ShortID, int64(info.GradingStartTime),
+		infoJSON, inputJSON, outputJSON, []byte(submission.Stdout), []byte(submission.Stderr))
+	if err != nil {
+		return fmt.Errorf("Failed to save submission '%s': '%w'.", info.ID, err)
+	}
+
+	return nil
+}
+
+// Compute the next submission ID based on the current time.
+// If a submission ID exists for the current time, increment the ID until it is unique.
+// The next submission ID will be the largest ID for the user on this assignment.
+// IDs are claimed by inserting them into the submission_ids table,
+// so multiple instances sharing the database will never be given the same ID.
+func (this *backend) GetNextSubmissionID(assignment *model.Assignment, email string) (string, error) {
+	submissionID := time.Now().Unix()
+
+	for {
+		shortID := fmt.Sprintf("%d", submissionID)
+		claimed := false
+
+		err := this.withTransaction(func(tx pgx.Tx) error {
+			// Submissions may exist without a claimed ID (e.g., from a restore or migration).
+			exists := false
+			err := tx.QueryRow(context.Background(),
+				`SELECT EXISTS (SELECT 1 FROM submissions
+					WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3 AND short_id = $4)`,
+				assignment.GetCourse().GetID(), assignment.GetID(), email, shortID).Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("Failed to check for existing submission ID: '%w'.", err)
+			}
+
+			if exists {
+				return nil
+			}
+
+			tag, err := tx.Exec(context.Background(),
+				`INSERT INTO submission_ids (course_id, assignment_id, user_email, short_id)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT DO NOTHING`,
+				assignment.GetCourse().GetID(), assignment.GetID(), email, shortID)
+			if err != nil {
+				return fmt.Errorf("Failed to claim submission ID: '%w'.", err)
+			}
+
+			claimed = (tag.RowsAffected() > 0)
+
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+
+		if claimed {
+			return shortID, nil
+		}
+
+		// This ID has been used.
+		submissionID++
+	}
+}
+
+func (this *backend) GetPreviousSubmissionID(assignment *model.Assignment, email string, shortSubmissionID string) (string, error) {
+	history, err := this.GetSubmissionHistory(assignment, email)
+	if err != nil {
+		return "", err
+	}
+
+	if len(history) <= 1 {
+		return "", nil
+	}
+
+	index := -1
+	for i, item := range history {
+		if item.ShortID == shortSubmissionID {
+			index = i
+			break
+		}
+	}
+
+	if index <= 0 {
+		return "", nil
+	}
+
+	return history[index-1].ID, nil
+}
+
+func (this *backend) GetSubmissionResult(assignment *model.Assignment, email string, shortSubmissionID string) (*model.GradingInfo, error) {
+	var err error
+
+	if shortSubmissionID == "" {
+		shortSubmissionID, err = this.getMostRecentSubmissionID(assignment, email)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get most recent submission id: '%w'.", err)
+		}
+	}
+
+	if shortSubmissionID == "" {
+		return nil, nil
+	}
+
+	var infoJSON []byte
+	err = this.pool.QueryRow(context.Background(),
+		`SELECT info FROM submissions
+		WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3 AND short_id = $4`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID).Scan(&infoJSON)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get submission result '%s': '%w'.", shortSubmissionID, err)
+	}
+
+	return parseGradingInfo(infoJSON)
+}
+
+func (this *backend) GetSubmissionHistory(assignment *model.Assignment, email string) ([]*model.SubmissionHistoryItem, error) {
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT info FROM submissions
+		WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3
+		ORDER BY grading_start_time, short_id`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query submission history: '%w'.", err)
+	}
+
+	infoJSONs, err := pgx.CollectRows(rows, pgx.RowTo[[]byte])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read submission history: '%w'.", err)
+	}
+
+	history := make([]*model.SubmissionHistoryItem, 0, len(infoJSONs))
+	for _, infoJSON := range infoJSONs {
+		gradingInfo, err := parseGradingInfo(infoJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		history = append(history, gradingInfo.ToHistoryItem())
+	}
+
+	return history, nil
+}
+
+func (this *backend) GetRecentSubmissions(assignment *model.Assignment, reference *model.ParsedCourseUserReference) (map[string]*model.GradingInfo, error) {
+	emails, err := this.getReferencedEmails(assignment, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT DISTINCT ON (user_email) user_email, info FROM submissions
+		WHERE course_id = $1 AND assignment_id = $2 AND user_email = ANY($3)
+		ORDER BY user_email, short_id DESC`,
+		assignment.GetCourse().GetID(), assignment.GetID(), emails)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query recent submissions: '%w'.", err)
+	}
+	defer rows.Close()
+
+	gradingInfos := make(map[string]*model.GradingInfo, len(emails))
+	for _, email := range emails {
+		gradingInfos[email] = nil
+	}
+
+	for rows.Next() {
+		var email string
+		var infoJSON []byte
+
+		err = rows.Scan(&email, &infoJSON)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read recent submission: '%w'.", err)
+		}
+
+		gradingInfos[email], err = parseGradingInfo(infoJSON)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read recent submissions: '%w'.", err)
+	}
+
+	return gradingInfos, nil
+}
+
+func (this *backend) GetScoringInfos(assignment *model.Assignment, reference *model.ParsedCourseUserReference) (map[string]*model.ScoringInfo, error) {
+	scoringInfos := make(map[string]*model.ScoringInfo)
+
+	submissionResults, err := this.GetRecentSubmissions(assignment, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	for email, submissionResult := range submissionResults {
+		if submissionResult == nil {
+			scoringInfos[email] = nil
+		} else {
+			scoringInfos[email] = submissionResult.ToScoringInfo()
+		}
+	}
+
+	return scoringInfos, nil
+}
+
+func (this *backend) GetRecentSubmissionSurvey(assignment *model.Assignment, reference *model.ParsedCourseUserReference) (map[string]*model.SubmissionHistoryItem, error) {
+	results := make(map[string]*model.SubmissionHistoryItem)
+
+	submissionResults, err := this.GetRecentSubmissions(assignment, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	for email, submissionResult := range submissionResults {
+		if submissionResult == nil {
+			results[email] = nil
+		} else {
+			results[email] = submissionResult.ToHistoryItem()
+		}
+	}
+
+	return results, nil
+}
+
+func (this *backend) GetSubmissionContents(assignment *model.Assignment, email string, shortSubmissionID string) (*model.GradingResult, error) {
+	var err error
+
+	if shortSubmissionID == "" {
+		shortSubmissionID, err = this.getMostRecentSubmissionID(assignment, email)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get most recent submission id: '%w'.", err)
+		}
+	}
+
+	if shortSubmissionID == "" {
+		return nil, nil
+	}
+
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT `+SUBMISSION_COLUMNS+` FROM submissions
+		WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3 AND short_id = $4`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query submission contents '%s': '%w'.", shortSubmissionID, err)
+	}
+
+	results, err := collectGradingResults(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	return results[0], nil
+}
+
+func (this *backend) GetRecentSubmissionContents(assignment *model.Assignment, reference *model.ParsedCourseUserReference) (map[string]*model.GradingResult, error) {
+	emails, err := this.getReferencedEmails(assignment, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT DISTINCT ON (user_email) `+SUBMISSION_COLUMNS+` FROM submissions
+		WHERE course_id = $1 AND assignment_id = $2 AND user_email = ANY($3)
+		ORDER BY user_email, short_id DESC`,
+		assignment.GetCourse().GetID(), assignment.GetID(), emails)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query recent submission contents: '%w'.", err)
+	}
+
+	submissions, err := collectGradingResults(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*model.GradingResult, len(emails))
+	for _, email := range emails {
+		results[email] = nil
+	}
+
+	for _, submission := range submissions {
+		results[submission.Info.User] = submission
+	}
+
+	return results, nil
+}
+
+func (this *backend) RemoveSubmission(assignment *model.Assignment, email string, shortSubmissionID string) (bool, error) {
+	var err error
+
+	if shortSubmissionID == "" {
+		shortSubmissionID, err = this.getMostRecentSubmissionID(assignment, email)
+		if err != nil {
+			return false, fmt.Errorf("Failed to get most recent submission id: `%w`.", err)
+		}
+	}
+
+	if shortSubmissionID == "" {
+		return false, nil
+	}
+
+	var count int64 = 0
+
+	err = this.withTransaction(func(tx pgx.Tx) error {
+		_, err := tx.Exec(context.Background(),
+			`DELETE FROM manual_grades
+			WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3 AND short_id = $4`,
+			assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID)
+		if err != nil {
+			return fmt.Errorf("Failed to remove manual grade for submission '%s': '%w'", shortSubmissionID, err)
+		}
+
+		tag, err := tx.Exec(context.Background(),
+			`DELETE FROM submissions
+			WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3 AND short_id = $4`,
+			assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID)
+		if err != nil {
+			return fmt.Errorf("Failed to remove submission '%s': '%w'", shortSubmissionID, err)
+		}
+
+		count = tag.RowsAffected()
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return (count > 0), nil
+}
+
+func (this *backend) GetSubmissionAttempts(assignment *model.Assignment, email string) ([]*model.GradingResult, error) {
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT `+SUBMISSION_COLUMNS+` FROM submissions
+		WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3
+		ORDER BY short_id`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query submission attempts for %s: '%w'.", email, err)
+	}
+
+	return collectGradingResults(rows)
+}
+
+// Get the short ID of the most recent submission (or empty string if there are no submissions).
+// The most recent submission has the largest ID for the user.
+func (this *backend) getMostRecentSubmissionID(assignment *model.Assignment, email string) (string, error) {
+	shortSubmissionID := ""
+	err := this.pool.QueryRow(context.Background(),
+		`SELECT short_id FROM submissions
+		WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3
+		ORDER BY short_id DESC
+		LIMIT 1`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email).Scan(&shortSubmissionID)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return shortSubmissionID, nil
+}
+
+// Get the emails of the course users that match a reference.
+func (this *backend) getReferencedEmails(assignment *model.Assignment, reference *model.ParsedCourseUserReference) ([]string, error) {
+	users, err := this.GetCourseUsers(assignment.GetCourse())
+	if err != nil {
+		return nil, err
+	}
+
+	emails := make([]string, 0, len(users))
+	for email, user := range users {
+		if reference.RefersTo(email, user.Role) {
+			emails = append(emails, email)
+		}
+	}
+
+	return emails, nil
+}
+
+func (this *backend) dumpSubmissions(course *model.Course, baseDir string) error {
+	rows, err := this.pool.Query(context.Background(),
+		`SELECT `+SUBMISSION_COLUMNS+` FROM submissions WHERE course_id = $1`,
+		course.GetID())
+	if err != nil {
+		return fmt.Errorf("Failed to query submissions for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	submissions, err := collectGradingResults(rows)
+	if err != nil {
+		return err
+	}
+
+	for _, submission := range submissions {
+		info := submission.Info
+		dir := filepath.Join(baseDir, info.AssignmentID, info.User, info.ShortID)
+
+		err = model.WriteGradingResult(submission, dir)
+		if err != nil {
+			return fmt.Errorf("Failed to dump submission '%s': '%w'.", info.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// Read grading results from rows that contain the standard submission columns.
+func collectGradingResults(rows pgx.Rows) ([]*model.GradingResult, error) {
+	defer rows.Close()
+
+	results := make([]*model.GradingResult, 0)
+	for rows.Next() {
+		var infoJSON []byte
+		var inputJSON []byte
+		var outputJSON []byte
+		var stdout []byte
+		var stderr []byte
+
+		err := rows.Scan(&infoJSON, &inputJSON, &outputJSON, &stdout, &stderr)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read submission: '%w'.", err)
+		}
+
+		result, err := parseGradingResult(infoJSON, inputJSON, outputJSON, stdout, stderr)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read submissions: '%w'.", err)
+	}
+
+	return results, nil
+}
+
+func serializeFiles(files map[string][]byte) (string, error) {
+	if files == nil {
+		files = make(map[string][]byte, 0)
+	}
+
+	return util.ToJSON(files)
+}
+
+func parseGradingResult(infoJSON []byte, inputJSON []byte, outputJSON []byte, stdout []byte, stderr []byte) (*model.GradingResult, error) {
+	info, err := parseGradingInfo(infoJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	inputFiles := make(map[string][]byte)
+	err = util.JSONFromBytes(inputJSON, &inputFiles)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to deserialize submission input files for '%s': '%w'.", info.ID, err)
+	}
+
+	outputFiles := make(map[string][]byte)
+	err = util.JSONFromBytes(outputJSON, &outputFiles)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to deserialize submission output files for '%s': '%w'.", info.ID, err)
+	}
+
+	return &model.GradingResult{
+		Info:            info,
+		InputFilesGZip:  inputFiles,
+		OutputFilesGZip: outputFiles,
+		Stdout:          string(stdout),
+		Stderr:          string(stderr),
+	}, nil
+}
+
+func parseGradingInfo(infoJSON []byte) (*model.GradingInfo, error) {
+	var gradingInfo model.GradingInfo
+	err := util.JSONFromBytes(infoJSON, &gradingInfo)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to deserialize grading info: '%w'.", err)
+	}
+
+	return &gradingInfo, nil
+}
@@ -0,0 +1,199 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) GetServerUsers() (map[string]*model.ServerUser, error) {
+	return queryServerUsers(this.pool, `SELECT data FROM users`)
+}
+
+func (this *backend) GetCourseUsers(course *model.Course) (map[string]*model.CourseUser, error) {
+	users, err := queryServerUsers(this.pool, `SELECT data FROM users WHERE (data->'course-info') ? $1`, course.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	courseUsers := make(map[string]*model.CourseUser)
+	for email, user := range users {
+		// Don't include root as a course user.
+		if email == model.RootUserEmail {
+			continue
+		}
+
+		courseUser, err := user.ToCourseUser(course.ID, false)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid user '%s': '%w'.", email, err)
+		}
+
+		if courseUser != nil {
+			courseUsers[courseUser.Email] = courseUser
+		}
+	}
+
+	return courseUsers, nil
+}
+
+func (this *backend) GetServerUser(email string) (*model.ServerUser, error) {
+	users, err := queryServerUsers(this.pool, `SELECT data FROM users WHERE email = $1`, email)
+	if err != nil {
+		return nil, err
+	}
+
+	user, exists := users[email]
+	if !exists {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+func (this *backend) UpsertUsers(upsertUsers map[string]*model.ServerUser) error {
+	return this.withTransaction(func(tx pgx.Tx) error {
+		for email, upsertUser := range upsertUsers {
+			if upsertUser == nil {
+				continue
+			}
+
+			users, err := queryServerUsers(tx, `SELECT data FROM users WHERE email = $1 FOR UPDATE`, email)
+			if err != nil {
+				return fmt.Errorf("Failed to get user '%s' to merge before saving: '%w'.", email, err)
+			}
+
+			user, exists := users[email]
+			if exists {
+				_, err = user.Merge(upsertUser)
+				if err != nil {
+					return fmt.Errorf("User '%s' could not be merged with existing user: '%w'.", email, err)
+				}
+			} else {
+				user = upsertUser
+			}
+
+			err = saveServerUser(tx, email, user)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) DeleteUser(email string) error {
+	_, err := this.pool.Exec(context.Background(), `DELETE FROM users WHERE email = $1`, email)
+	if err != nil {
+		return fmt.Errorf("Failed to delete user '%s': '%w'.", email, err)
+	}
+
+	return nil
+}
+
+func (this *backend) RemoveUserFromCourse(course *model.Course, email string) error {
+	_, err := this.pool.Exec(context.Background(),
+		`UPDATE users SET data = data #- ARRAY['course-info', $2::TEXT] WHERE email = $1`,
+		email, course.GetID())
+	if err != nil {
+		return fmt.Errorf("Failed to remove user '%s' from course '%s': '%w'.", email, course.GetID(), err)
+	}
+
+	return nil
+}
+
+func (this *backend) DeleteUserToken(email string, tokenID string) (bool, error) {
+	removed := false
+
+	err := this.withTransaction(func(tx pgx.Tx) error {
+		users, err := queryServerUsers(tx, `SELECT data FROM users WHERE email = $1 FOR UPDATE`, email)
+		if err != nil {
+			return fmt.Errorf("Failed to get user when deleting user token '%s': '%w'.", email, err)
+		}
+
+		user, ok := users[email]
+		if !ok {
+			return nil
+		}
+
+		for i, token := range user.Tokens {
+			if tokenID == token.ID {
+				user.Tokens = slices.Delete(user.Tokens, i, i+1)
+				removed = true
+				break
+			}
+		}
+
+		if !removed {
+			return nil
+		}
+
+		return saveServerUser(tx, email, user)
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return removed, nil
+}
+
+// Something that can run a query (e.g., a pool or transaction).
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Query for users and validate them.
+// The query must select only the user data.
+func queryServerUsers(db querier, query string, args ...any) (map[string]*model.ServerUser, error) {
+	rows, err := db.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query users: '%w'.", err)
+	}
+
+	dataList, err := pgx.CollectRows(rows, pgx.RowTo[[]byte])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read users: '%w'.", err)
+	}
+
+	users := make(map[string]*model.ServerUser, len(dataList))
+	for _, data := range dataList {
+		var user model.ServerUser
+		err = util.JSONFromBytes(data, &user)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to deserialize user: '%w'.", err)
+		}
+
+		users[user.Email] = &user
+	}
+
+	var errs error = nil
+	for _, user := range users {
+		errs = errors.Join(errs, user.Validate())
+	}
+
+	return users, errs
+}
+
+func saveServerUser(tx pgx.Tx, email string, user *model.ServerUser) error {
+	data, err := util.ToJSON(user)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize user '%s': '%w'.", email, err)
+	}
+
+	_, err = tx.Exec(context.Background(),
+		`INSERT INTO users (email, data) VALUES ($1, $2)
+		ON CONFLICT (email) DO UPDATE SET data = EXCLUDED.data`,
+		email, data)
+	if err != nil {
+		return fmt.Errorf("Unable to save user '%s': '%w'.", email, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"database/sql"
+
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// Select the pairs of IDs from a JSON array of pairwise keys (see serializePairwiseKeys()).
+const PAIRWISE_KEYS_QUERY = `SELECT json_extract(value, '$[0]'), json_extract(value, '$[1]') FROM json_each(?)`
+
+func (this *backend) GetIndividualAnalysis(fullSubmissionIDs []string) (map[string]*model.IndividualAnalysis, error) {
+	results := make(map[string]*model.IndividualAnalysis, len(fullSubmissionIDs))
+
+	idsJSON, err := util.ToJSON(fullSubmissionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to serialize individual analysis IDs: '%w'.", err)
+	}
+
+	rows, err := this.db.Query(
+		`SELECT data FROM analysis_individual WHERE full_id IN (SELECT value FROM json_each(?))`,
+		idsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query individual analysis: '%w'.", err)
+	}
+
+	records, err := collectJSONRows[model.IndividualAnalysis](rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read individual analysis: '%w'.", err)
+	}
+
+	for _, record := range records {
+		results[record.FullID] = record
+	}
+
+	return results, nil
+}
+
+func (this *backend) GetPairwiseAnalysis(keys []model.PairwiseKey) (map[model.PairwiseKey]*model.PairwiseAnalysis, error) {
+	results := make(map[model.PairwiseKey]*model.PairwiseAnalysis, len(keys))
+
+	keysJSON, err := serializePairwiseKeys(keys)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to serialize pairwise analysis keys: '%w'.", err)
+	}
+
+	rows, err := this.db.Query(
+		`SELECT data FROM analysis_pairwise
+		WHERE (full_id_1, full_id_2) IN (`+PAIRWISE_KEYS_QUERY+`)`,
+		keysJSON)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query pairwise analysis: '%w'.", err)
+	}
+
+	records, err := collectJSONRows[model.PairwiseAnalysis](rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read pairwise analysis: '%w'.", err)
+	}
+
+	for _, record := range records {
+		results[record.SubmissionIDs] = record
+	}
+
+	return results, nil
+}
+
+func (this *backend) RemoveIndividualAnalysis(fullSubmissionIDs []string) error {
+	idsJSON, err := util.ToJSON(fullSubmissionIDs)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize individual analysis IDs: '%w'.", err)
+	}
+
+	_, err = this.db.Exec(
+		`DELETE FROM analysis_individual WHERE full_id IN (SELECT value FROM json_each(?))`,
+		idsJSON)
+	if err != nil {
+		return fmt.Errorf("Failed to remove individual analysis: '%w'.", err)
+	}
+
+	return nil
+}
+
+func (this *backend) RemovePairwiseAnalysis(keys []model.PairwiseKey) error {
+	keysJSON, err := serializePairwiseKeys(keys)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize pairwise analysis keys: '%w'.", err)
+	}
+
+	_, err = this.db.Exec(
+		`DELETE FROM analysis_pairwise
+		WHERE (full_id_1, full_id_2) IN (`+PAIRWISE_KEYS_QUERY+`)`,
+		keysJSON)
+	if err != nil {
+		return fmt.Errorf("Failed to remove pairwise analysis: '%w'.", err)
+	}
+
+	return nil
+}
+
+func (this *backend) StoreIndividualAnalysis(records []*model.IndividualAnalysis) error {
+	return this.withTransaction(func(tx *sql.Tx) error {
+		for _, record := range records {
+			if record.CourseID == "" {
+				// This would be a bit strange, just log and skip it.
+				log.Warn("Found empty course ID in individual analysis.", log.NewAttr("record", record))
+				continue
+			}
+
+			data, err := util.ToJSON(record)
+			if err != nil {
+				return fmt.Errorf("Failed to serialize individual analysis '%s': '%w'.", record.FullID, err)
+			}
+
+			_, err = tx.Exec(
+				`INSERT INTO analysis_individual (full_id, course_id, data) VALUES (?, ?, ?)
+				ON CONFLICT (full_id) DO UPDATE SET course_id = excluded.course_id, data = excluded.data`,
+				record.FullID, record.CourseID, data)
+			if err != nil {
+				return fmt.Errorf("Failed to store individual analysis for course '%s': '%w'.", record.CourseID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) StorePairwiseAnalysis(records []*model.PairwiseAnalysis) error {
+	return this.withTransaction(func(tx *sql.Tx) error {
+		for _, record := range records {
+			courseID := record.SubmissionIDs.Course()
+			if courseID == "" {
+				// This would be a bit strange, just log and skip it.
+				log.Warn("Found empty course ID in pairwise analysis.", log.NewAttr("record", record))
+				continue
+			}
+
+			data, err := util.ToJSON(record)
+			if err != nil {
+				return fmt.Errorf("Failed to serialize pairwise analysis '%s': '%w'.", record.SubmissionIDs.String(), err)
+			}
+
+			_, err = tx.Exec(
+				`INSERT INTO analysis_pairwise (full_id_1, full_id_2, course_id, data) VALUES (?, ?, ?, ?)
+				ON CONFLICT (full_id_1, full_id_2) DO UPDATE SET course_id = excluded.course_id, data = excluded.data`,
+				record.SubmissionIDs[0], record.SubmissionIDs[1], courseID, data)
+			if err != nil {
+				return fmt.Errorf("Failed to store pairwise analysis for course '%s': '%w'.", courseID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Write out all analysis for a course in the same format as the disk database.
+func (this *backend) dumpAnalysis(course *model.Course, targetDir string) error {
+	rows, err := this.db.Query(
+		`SELECT data FROM analysis_individual WHERE course_id = ? ORDER BY full_id`,
+		course.GetID())
+	if err != nil {
+		return fmt.Errorf("Failed to query individual analysis for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	individualRecords, err := collectJSONRows[model.IndividualAnalysis](rows)
+	if err != nil {
+		return fmt.Errorf("Failed to read individual analysis for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	if len(individualRecords) > 0 {
+		err = util.AppendJSONLFileMany(filepath.Join(targetDir, DUMP_ANALYSIS_INDIVIDUAL_FILENAME), individualRecords)
+		if err != nil {
+			return fmt.Errorf("Failed to dump individual analysis for course '%s': '%w'.", course.GetID(), err)
+		}
+	}
+
+	rows, err = this.db.Query(
+		`SELECT data FROM analysis_pairwise WHERE course_id = ? ORDER BY full_id_1, full_id_2`,
+		course.GetID())
+	if err != nil {
+		return fmt.Errorf("Failed to query pairwise analysis for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	pairwiseRecords, err := collectJSONRows[model.PairwiseAnalysis](rows)
+	if err != nil {
+		return fmt.Errorf("Failed to read pairwise analysis for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	if len(pairwiseRecords) > 0 {
+		err = util.AppendJSONLFileMany(filepath.Join(targetDir, DUMP_ANALYSIS_PAIRWISE_FILENAME), pairwiseRecords)
+		if err != nil {
+			return fmt.Errorf("Failed to dump pairwise analysis for course '%s': '%w'.", course.GetID(), err)
+		}
+	}
+
+	return nil
+}
+
+// Serialize keys as a JSON array of two element arrays.
+// Note that keys are not serialized directly, since they marshal as a single string.
+func serializePairwiseKeys(keys []model.PairwiseKey) (string, error) {
+	pairs := make([][2]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, [2]string(key))
+	}
+
+	return util.ToJSON(pairs)
+}
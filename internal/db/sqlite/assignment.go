@@ -0,0 +1,32 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) SaveAssignment(assignment *model.Assignment) error {
+	return this.withTransaction(func(tx *sql.Tx) error {
+		return saveAssignment(tx, assignment)
+	})
+}
+
+func saveAssignment(tx *sql.Tx, assignment *model.Assignment) error {
+	data, err := util.ToJSON(assignment)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize assignment '%s': '%w'.", assignment.FullID(), err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO assignments (course_id, id, data) VALUES (?, ?, ?)
+		ON CONFLICT (course_id, id) DO UPDATE SET data = excluded.data`,
+		assignment.GetCourse().GetID(), assignment.GetID(), data)
+	if err != nil {
+		return fmt.Errorf("Failed to save assignment '%s': '%v'.", assignment.FullID(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,312 @@
+// A database backend that stores all data in a single embedded SQLite database file.
+// Meant for single-node deployments that want transactional writes without running a separate database server.
+// Most objects are stored as JSON documents next to the (indexed) columns used to look them up.
+// Writes that need to read existing data first (e.g., merging users) are done inside transactions.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	DB_FILENAME = "sqlite-database.db"
+
+	// How long (in ms) to wait on a locked database before giving up.
+	BUSY_TIMEOUT_MS = 10000
+)
+
+var tableNames []string = []string{
+	"courses",
+	"assignments",
+	"users",
+	"submissions",
+	"manual_grades",
+	"extensions",
+	"lms_comments",
+	"assignment_groups",
+	"git_repos",
+	"tasks",
+	"logs",
+	"metrics",
+	"analysis_individual",
+	"analysis_pairwise",
+}
+
+// Text columns use the default (binary) collation,
+// so short IDs sort the same way that the disk backend sorts them.
+var schema []string = []string{
+	`CREATE TABLE IF NOT EXISTS courses (
+		id TEXT PRIMARY KEY,
+		data TEXT NOT NULL
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS assignments (
+		course_id TEXT NOT NULL,
+		id TEXT NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (course_id, id)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS users (
+		email TEXT PRIMARY KEY,
+		data TEXT NOT NULL
+	)`,
+
+	// The primary key covers lookups of a single user's submissions (e.g., history and most recent).
+	`CREATE TABLE IF NOT EXISTS submissions (
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		user_email TEXT NOT NULL,
+		short_id TEXT NOT NULL,
+		grading_start_time INTEGER NOT NULL,
+		info TEXT NOT NULL,
+		input_files TEXT NOT NULL,
+		output_files TEXT NOT NULL,
+		stdout BLOB NOT NULL,
+		stderr BLOB NOT NULL,
+		PRIMARY KEY (course_id, assignment_id, user_email, short_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS submissions_history_index ON submissions (course_id, assignment_id, user_email, grading_start_time)`,
+
+	`CREATE TABLE IF NOT EXISTS manual_grades (
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		user_email TEXT NOT NULL,
+		short_id TEXT NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (course_id, assignment_id, user_email, short_id)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS extensions (
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		user_email TEXT NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (course_id, assignment_id, user_email)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS lms_comments (
+		course_id TEXT NOT NULL,
+		assignment_lms_id TEXT NOT NULL,
+		user_lms_id TEXT NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (course_id, assignment_lms_id, user_lms_id)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS assignment_groups (
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		id TEXT NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (course_id, assignment_id, id)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS git_repos (
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		user_email TEXT NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (course_id, assignment_id, user_email)
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS tasks (
+		hash TEXT PRIMARY KEY,
+		source TEXT NOT NULL,
+		course_id TEXT NOT NULL,
+		next_run_time INTEGER NOT NULL,
+		data TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS tasks_course_index ON tasks (source, course_id)`,
+	`CREATE INDEX IF NOT EXISTS tasks_next_run_time_index ON tasks (next_run_time)`,
+
+	`CREATE TABLE IF NOT EXISTS logs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		level INTEGER NOT NULL,
+		timestamp INTEGER NOT NULL,
+		course_id TEXT NOT NULL,
+		assignment_id TEXT NOT NULL,
+		user_email TEXT NOT NULL,
+		data TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS logs_timestamp_index ON logs (timestamp)`,
+	`CREATE INDEX IF NOT EXISTS logs_context_index ON logs (course_id, assignment_id, user_email)`,
+	`CREATE INDEX IF NOT EXISTS logs_user_index ON logs (user_email)`,
+
+	`CREATE TABLE IF NOT EXISTS metrics (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		type TEXT NOT NULL,
+		timestamp INTEGER NOT NULL,
+		data TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS metrics_type_timestamp_index ON metrics (type, timestamp)`,
+
+	`CREATE TABLE IF NOT EXISTS analysis_individual (
+		full_id TEXT PRIMARY KEY,
+		course_id TEXT NOT NULL,
+		data TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS analysis_individual_course_index ON analysis_individual (course_id)`,
+
+	`CREATE TABLE IF NOT EXISTS analysis_pairwise (
+		full_id_1 TEXT NOT NULL,
+		full_id_2 TEXT NOT NULL,
+		course_id TEXT NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (full_id_1, full_id_2)
+	)`,
+	`CREATE INDEX IF NOT EXISTS analysis_pairwise_course_index ON analysis_pairwise (course_id)`,
+}
+
+type backend struct {
+	db *sql.DB
+}
+
+func Open() (*backend, error) {
+	dbDir := util.ShouldAbs(config.GetDatabaseDir())
+
+	err := util.MkDir(dbDir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to make db dir '%s': '%w'.", dbDir, err)
+	}
+
+	path := filepath.Join(dbDir, DB_FILENAME)
+
+	// WAL allows readers to continue while a write is happening,
+	// and immediate transactions take the write lock up front (instead of failing when upgrading a read lock).
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", BUSY_TIMEOUT_MS))
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "synchronous(NORMAL)")
+	params.Add("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open SQLite database at '%s': '%w'.", path, err)
+	}
+
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("Failed to connect to SQLite database at '%s': '%w'.", path, err)
+	}
+
+	log.Debug("Opened SQLite database.", log.NewAttr("path", path))
+
+	return &backend{db}, nil
+}
+
+func (this *backend) Close() error {
+	return this.db.Close()
+}
+
+func (this *backend) EnsureTables() error {
+	return this.withTransaction(func(tx *sql.Tx) error {
+		for _, statement := range schema {
+			_, err := tx.Exec(statement)
+			if err != nil {
+				return fmt.Errorf("Failed to create schema (statement: '%s'): '%w'.", statement, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) Clear() error {
+	return this.withTransaction(func(tx *sql.Tx) error {
+		for _, tableName := range tableNames {
+			_, err := tx.Exec(`DELETE FROM ` + tableName)
+			if err != nil {
+				return fmt.Errorf("Failed to clear SQLite table '%s': '%w'.", tableName, err)
+			}
+		}
+
+		// Restart the generated IDs.
+		_, err := tx.Exec(`DELETE FROM sqlite_sequence`)
+		if err != nil {
+			return fmt.Errorf("Failed to reset SQLite sequences: '%w'.", err)
+		}
+
+		return nil
+	})
+}
+
+// Run a function inside of a transaction.
+// The transaction will be committed if the function returns nil, and rolled back otherwise.
+func (this *backend) withTransaction(function func(tx *sql.Tx) error) error {
+	tx, err := this.db.Begin()
+	if err != nil {
+		return fmt.Errorf("Failed to begin transaction: '%w'.", err)
+	}
+	defer tx.Rollback()
+
+	err = function(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("Failed to commit transaction: '%w'.", err)
+	}
+
+	return nil
+}
+
+// Something that can run a query (e.g., a database or transaction).
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// Read rows that only contain a single column.
+func collectBytes(rows *sql.Rows) ([][]byte, error) {
+	defer rows.Close()
+
+	dataList := make([][]byte, 0)
+	for rows.Next() {
+		var data []byte
+		err := rows.Scan(&data)
+		if err != nil {
+			return nil, err
+		}
+
+		dataList = append(dataList, data)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return dataList, nil
+}
+
+// Read rows that only contain a single JSON column into objects.
+func collectJSONRows[T any](rows *sql.Rows) ([]*T, error) {
+	dataList, err := collectBytes(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*T, 0, len(dataList))
+	for _, data := range dataList {
+		var record T
+		err = util.JSONFromBytes(data, &record)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
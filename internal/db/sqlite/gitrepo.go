@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) SaveGitRepo(assignment *model.Assignment, repo *model.GitRepo) error {
+	data, err := util.ToJSON(repo)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize git repo for user '%s': '%w'.", repo.User, err)
+	}
+
+	return this.withTransaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO git_repos (course_id, assignment_id, user_email, data) VALUES (?, ?, ?, ?)
+			ON CONFLICT (course_id, assignment_id, user_email) DO UPDATE SET data = excluded.data`,
+			assignment.GetCourse().GetID(), assignment.GetID(), repo.User, data)
+		if err != nil {
+			return fmt.Errorf("Failed to save git repo for user '%s': '%w'.", repo.User, err)
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) GetGitRepos(assignment *model.Assignment) ([]*model.GitRepo, error) {
+	rows, err := this.db.Query(
+		`SELECT data FROM git_repos WHERE course_id = ? AND assignment_id = ? ORDER BY user_email`,
+		assignment.GetCourse().GetID(), assignment.GetID())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query git repos for assignment '%s': '%w'.", assignment.FullID(), err)
+	}
+
+	repos, err := collectJSONRows[model.GitRepo](rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read git repos for assignment '%s': '%w'.", assignment.FullID(), err)
+	}
+
+	return repos, nil
+}
+
+func (this *backend) RemoveGitRepo(assignment *model.Assignment, email string) (bool, error) {
+	exists := false
+
+	err := this.withTransaction(func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			`DELETE FROM git_repos WHERE course_id = ? AND assignment_id = ? AND user_email = ?`,
+			assignment.GetCourse().GetID(), assignment.GetID(), email)
+		if err != nil {
+			return fmt.Errorf("Failed to remove git repo for user '%s': '%w'.", email, err)
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("Failed to check removed git repo for user '%s': '%w'.", email, err)
+		}
+
+		exists = (count > 0)
+		return nil
+	})
+
+	return exists, err
+}
+
+// Write out all git repos for a course in the same format as the disk database.
+func (this *backend) dumpGitRepos(course *model.Course, targetDir string) error {
+	for _, assignment := range course.Assignments {
+		repos, err := this.GetGitRepos(assignment)
+		if err != nil {
+			return err
+		}
+
+		if len(repos) == 0 {
+			continue
+		}
+
+		path := filepath.Join(targetDir, DUMP_ASSIGNMENTS_DIRNAME, assignment.GetID(), model.GIT_REPOS_FILENAME)
+
+		err = util.ToJSONFileIndent(repos, path)
+		if err != nil {
+			return fmt.Errorf("Failed to dump git repos for assignment '%s': '%w'.", assignment.FullID(), err)
+		}
+	}
+
+	return nil
+}
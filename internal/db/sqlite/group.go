@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) SaveGroup(assignment *model.Assignment, group *model.Group) error {
+	data, err := util.ToJSON(group)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize group '%s': '%w'.", group.ID, err)
+	}
+
+	return this.withTransaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO assignment_groups (course_id, assignment_id, id, data) VALUES (?, ?, ?, ?)
+			ON CONFLICT (course_id, assignment_id, id) DO UPDATE SET data = excluded.data`,
+			assignment.GetCourse().GetID(), assignment.GetID(), group.ID, data)
+		if err != nil {
+			return fmt.Errorf("Failed to save group '%s': '%w'.", group.ID, err)
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) GetGroups(assignment *model.Assignment) ([]*model.Group, error) {
+	rows, err := this.db.Query(
+		`SELECT data FROM assignment_groups WHERE course_id = ? AND assignment_id = ? ORDER BY id`,
+		assignment.GetCourse().GetID(), assignment.GetID())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query groups for assignment '%s': '%w'.", assignment.FullID(), err)
+	}
+
+	groups, err := collectJSONRows[model.Group](rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read groups for assignment '%s': '%w'.", assignment.FullID(), err)
+	}
+
+	return groups, nil
+}
+
+func (this *backend) RemoveGroup(assignment *model.Assignment, groupID string) (bool, error) {
+	exists := false
+
+	err := this.withTransaction(func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			`DELETE FROM assignment_groups WHERE course_id = ? AND assignment_id = ? AND id = ?`,
+			assignment.GetCourse().GetID(), assignment.GetID(), groupID)
+		if err != nil {
+			return fmt.Errorf("Failed to remove group '%s': '%w'.", groupID, err)
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("Failed to check removed group '%s': '%w'.", groupID, err)
+		}
+
+		exists = (count > 0)
+		return nil
+	})
+
+	return exists, err
+}
+
+// Write out all groups for a course in the same format as the disk database.
+func (this *backend) dumpGroups(course *model.Course, targetDir string) error {
+	for _, assignment := range course.Assignments {
+		groups, err := this.GetGroups(assignment)
+		if err != nil {
+			return err
+		}
+
+		if len(groups) == 0 {
+			continue
+		}
+
+		path := filepath.Join(targetDir, DUMP_ASSIGNMENTS_DIRNAME, assignment.GetID(), model.GROUPS_FILENAME)
+
+		err = util.ToJSONFileIndent(groups, path)
+		if err != nil {
+			return fmt.Errorf("Failed to dump groups for assignment '%s': '%w'.", assignment.FullID(), err)
+		}
+	}
+
+	return nil
+}
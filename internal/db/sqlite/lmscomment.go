@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) SaveLMSComments(comments []*model.LMSComment) error {
+	return this.withTransaction(func(tx *sql.Tx) error {
+		for _, comment := range comments {
+			data, err := util.ToJSON(comment)
+			if err != nil {
+				return fmt.Errorf("Failed to serialize LMS comment for user '%s': '%w'.", comment.UserLMSID, err)
+			}
+
+			_, err = tx.Exec(
+				`INSERT INTO lms_comments (course_id, assignment_lms_id, user_lms_id, data) VALUES (?, ?, ?, ?)
+				ON CONFLICT (course_id, assignment_lms_id, user_lms_id) DO UPDATE SET data = excluded.data`,
+				comment.CourseID, comment.AssignmentLMSID, comment.UserLMSID, data)
+			if err != nil {
+				return fmt.Errorf("Failed to save LMS comment for user '%s': '%w'.", comment.UserLMSID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) GetLMSComments(courseID string, assignmentLMSID string) ([]*model.LMSComment, error) {
+	rows, err := this.db.Query(
+		`SELECT data FROM lms_comments WHERE course_id = ? AND assignment_lms_id = ? ORDER BY user_lms_id`,
+		courseID, assignmentLMSID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query LMS comments for course '%s': '%w'.", courseID, err)
+	}
+
+	comments, err := collectJSONRows[model.LMSComment](rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read LMS comments for course '%s': '%w'.", courseID, err)
+	}
+
+	return comments, nil
+}
+
+// Write out all LMS comments for a course in the same format as the disk database.
+func (this *backend) dumpLMSComments(course *model.Course, targetDir string) error {
+	rows, err := this.db.Query(
+		`SELECT data FROM lms_comments WHERE course_id = ? ORDER BY assignment_lms_id, user_lms_id`,
+		course.GetID())
+	if err != nil {
+		return fmt.Errorf("Failed to query LMS comments for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	comments, err := collectJSONRows[model.LMSComment](rows)
+	if err != nil {
+		return fmt.Errorf("Failed to read LMS comments for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	if len(comments) == 0 {
+		return nil
+	}
+
+	path := filepath.Join(targetDir, model.LMS_COMMENTS_FILENAME)
+
+	err = util.ToJSONFileIndent(comments, path)
+	if err != nil {
+		return fmt.Errorf("Failed to dump LMS comments for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	return nil
+}
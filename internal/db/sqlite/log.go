@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) LogDirect(record *log.Record) error {
+	data, err := util.ToJSON(record)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize log record: '%w'.", err)
+	}
+
+	_, err = this.db.Exec(
+		`INSERT INTO logs (level, timestamp, course_id, assignment_id, user_email, data) VALUES (?, ?, ?, ?, ?, ?)`,
+		int32(record.Level), int64(record.Timestamp), record.Course, record.Assignment, record.User, data)
+	if err != nil {
+		return fmt.Errorf("Failed to store log record: '%w'.", err)
+	}
+
+	return nil
+}
+
+func (this *backend) GetLogRecords(query log.ParsedLogQuery) ([]*log.Record, error) {
+	before := int64(query.Before)
+	if before == 0 {
+		before = math.MaxInt64
+	}
+
+	// Text and attribute filters are applied in Go (so they match exactly like the other backends),
+	// which means sorting and pagination must also happen in Go.
+	// Without those filters, the database can do all the work.
+	sortInGo := query.HasContentFilters()
+
+	orderBy := "id"
+	limit := int64(math.MaxInt64)
+	offset := int64(0)
+
+	if !sortInGo {
+		orderBy = getLogOrderBy(query.Sort)
+
+		if query.Limit > 0 {
+			limit = int64(query.Limit)
+		}
+
+		if query.Offset > 0 {
+			offset = int64(query.Offset)
+		}
+	}
+
+	// Note that the assignment is only matched when the course is also given (see log.ParsedLogQuery.Match()).
+	rows, err := this.db.Query(
+		`SELECT data FROM logs
+		WHERE
+			level >= ?1
+			AND timestamp >= ?2
+			AND ((?3 = '') OR (course_id = ?3))
+			AND ((?4 = '') OR ((?3 != '') AND (assignment_id = ?4)))
+			AND ((?5 = '') OR (user_email = ?5))
+			AND timestamp < ?6
+		ORDER BY `+orderBy+`
+		LIMIT ?7 OFFSET ?8`,
+		int32(query.Level), int64(query.After), query.CourseID, query.AssignmentID, query.UserEmail, before, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query log records: '%w'.", err)
+	}
+
+	dataList, err := collectBytes(rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read log records: '%w'.", err)
+	}
+
+	records := make([]*log.Record, 0, len(dataList))
+	for _, data := range dataList {
+		var record log.Record
+		err = util.JSONFromBytes(data, &record)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to deserialize log record: '%w'.", err)
+		}
+
+		if !query.Match(&record) {
+			continue
+		}
+
+		records = append(records, &record)
+	}
+
+	if !sortInGo {
+		return records, nil
+	}
+
+	return log.LimitAndSort(records, query), nil
+}
+
+// Get the ordering that matches log.LimitAndSort() (records with the same timestamp stay in storage order).
+func getLogOrderBy(sort int) string {
+	if sort < 0 {
+		return "timestamp ASC, id ASC"
+	}
+
+	if sort > 0 {
+		return "timestamp DESC, id ASC"
+	}
+
+	return "id ASC"
+}
+
+func (this *backend) CountLogRecords(level log.LogLevel, before timestamp.Timestamp) (int, error) {
+	var count int
+	err := this.db.QueryRow(`SELECT COUNT(*) FROM logs WHERE level = ? AND timestamp < ?`, int32(level), int64(before)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count log records: '%w'.", err)
+	}
+
+	return count, nil
+}
+
+func (this *backend) RemoveLogRecords(level log.LogLevel, before timestamp.Timestamp) (int, error) {
+	result, err := this.db.Exec(`DELETE FROM logs WHERE level = ? AND timestamp < ?`, int32(level), int64(before))
+	if err != nil {
+		return 0, fmt.Errorf("Failed to remove log records: '%w'.", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count removed log records: '%w'.", err)
+	}
+
+	return int(count), nil
+}
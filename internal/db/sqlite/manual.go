@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"database/sql"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) SaveManualGrade(grade *model.ManualGrade) error {
+	data, err := util.ToJSON(grade)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize manual grade '%s': '%w'.", grade.ID, err)
+	}
+
+	return this.withTransaction(func(tx *sql.Tx) error {
+		exists := false
+		err := tx.QueryRow(
+			`SELECT EXISTS (SELECT 1 FROM submissions
+				WHERE course_id = ? AND assignment_id = ? AND user_email = ? AND short_id = ?)`,
+			grade.CourseID, grade.AssignmentID, grade.User, grade.ShortID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("Failed to check for submission '%s': '%w'.", grade.ID, err)
+		}
+
+		if !exists {
+			return fmt.Errorf("Cannot save manual grade for a submission that does not exist: '%s'.", grade.ID)
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO manual_grades (course_id, assignment_id, user_email, short_id, data) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (course_id, assignment_id, user_email, short_id) DO UPDATE SET data = excluded.data`,
+			grade.CourseID, grade.AssignmentID, grade.User, grade.ShortID, data)
+		if err != nil {
+			return fmt.Errorf("Failed to save manual grade '%s': '%w'.", grade.ID, err)
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) GetManualGrade(assignment *model.Assignment, email string, shortSubmissionID string) (*model.ManualGrade, error) {
+	var err error
+
+	if shortSubmissionID == "" {
+		shortSubmissionID, err = this.getMostRecentSubmissionID(assignment, email)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get most recent submission id: '%w'.", err)
+		}
+	}
+
+	if shortSubmissionID == "" {
+		return nil, nil
+	}
+
+	rows, err := this.db.Query(
+		`SELECT data FROM manual_grades
+		WHERE course_id = ? AND assignment_id = ? AND user_email = ? AND short_id = ?`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query manual grade '%s': '%w'.", shortSubmissionID, err)
+	}
+
+	grades, err := collectJSONRows[model.ManualGrade](rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read manual grade '%s': '%w'.", shortSubmissionID, err)
+	}
+
+	if len(grades) == 0 {
+		return nil, nil
+	}
+
+	return grades[0], nil
+}
+
+func (this *backend) GetLatestManualGrade(assignment *model.Assignment, email string, shortSubmissionID string) (*model.ManualGrade, error) {
+	rows, err := this.db.Query(
+		`SELECT data FROM manual_grades
+		WHERE course_id = ? AND assignment_id = ? AND user_email = ? AND (? = '' OR short_id <= ?)
+		ORDER BY short_id DESC
+		LIMIT 1`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID, shortSubmissionID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query latest manual grade for '%s': '%w'.", email, err)
+	}
+
+	grades, err := collectJSONRows[model.ManualGrade](rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read latest manual grade for '%s': '%w'.", email, err)
+	}
+
+	if len(grades) == 0 {
+		return nil, nil
+	}
+
+	return grades[0], nil
+}
+
+// Write out all manual grades for a course in the same format as the disk database.
+// The submissions should already be dumped into the base dir.
+func (this *backend) dumpManualGrades(course *model.Course, baseDir string) error {
+	rows, err := this.db.Query(
+		`SELECT data FROM manual_grades WHERE course_id = ?`,
+		course.GetID())
+	if err != nil {
+		return fmt.Errorf("Failed to query manual grades for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	grades, err := collectJSONRows[model.ManualGrade](rows)
+	if err != nil {
+		return fmt.Errorf("Failed to read manual grades for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	for _, grade := range grades {
+		path := filepath.Join(baseDir, grade.AssignmentID, grade.User, grade.ShortID, model.MANUAL_GRADE_FILENAME)
+
+		err = util.ToJSONFileIndent(grade, path)
+		if err != nil {
+			return fmt.Errorf("Failed to dump manual grade '%s': '%w'.", grade.ID, err)
+		}
+	}
+
+	return nil
+}
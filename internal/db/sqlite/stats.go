@@ -0,0 +1,393 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) GetMetrics(query stats.Query) ([]*stats.Metric, error) {
+	return this.getMetrics(query, `SELECT data FROM metrics WHERE type = ? ORDER BY id`, string(query.Type))
+}
+
+func (this *backend) GetMetricsInWindow(query stats.Query) ([]*stats.Metric, error) {
+	before := int64(query.Before)
+	if before == 0 {
+		before = math.MaxInt64
+	}
+
+	// The query's attributes are ignored.
+	windowQuery := stats.Query{Type: query.Type}
+
+	return this.getMetrics(windowQuery,
+		`SELECT data FROM metrics WHERE type = ? AND timestamp > ? AND timestamp < ? ORDER BY id`,
+		string(query.Type), int64(query.After), before)
+}
+
+func (this *backend) CountMetricsInWindow(query stats.Query) (int, error) {
+	if query.Type == "" {
+		return 0, fmt.Errorf("No metric type was given.")
+	}
+
+	before := int64(query.Before)
+	if before == 0 {
+		before = math.MaxInt64
+	}
+
+	var count int
+	err := this.db.QueryRow(
+		`SELECT COUNT(*) FROM metrics WHERE type = ? AND timestamp > ? AND timestamp < ?`,
+		string(query.Type), int64(query.After), before).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count metrics: '%w'.", err)
+	}
+
+	return count, nil
+}
+
+func (this *backend) GetOldestMetricTimeInWindow(query stats.Query) (*timestamp.Timestamp, error) {
+	if query.Type == "" {
+		return nil, fmt.Errorf("No metric type was given.")
+	}
+
+	before := int64(query.Before)
+	if before == 0 {
+		before = math.MaxInt64
+	}
+
+	var oldest *int64
+	err := this.db.QueryRow(
+		`SELECT MIN(timestamp) FROM metrics WHERE type = ? AND timestamp > ? AND timestamp < ?`,
+		string(query.Type), int64(query.After), before).Scan(&oldest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the oldest metric time: '%w'.", err)
+	}
+
+	if oldest == nil {
+		return nil, nil
+	}
+
+	result := timestamp.Timestamp(*oldest)
+	return &result, nil
+}
+
+// Aggregates (including time buckets) are computed in SQL.
+// SQLite does not have percentile functions,
+// so the values closest to each percentile are found in SQL and interpolated in Go (like stats.Aggregate()).
+func (this *backend) GetAggregateMetrics(query stats.Query) ([]*stats.AggregateResult, error) {
+	if query.Type == "" {
+		return nil, fmt.Errorf("No metric type was given.")
+	}
+
+	aggregation := query.Aggregate
+	if aggregation == nil {
+		return nil, fmt.Errorf("No aggregation was given.")
+	}
+
+	before := int64(query.Before)
+	if before == 0 {
+		before = math.MaxInt64
+	}
+
+	args := []any{string(query.Type), int64(query.After), before}
+	conditions := []string{"type = ?1", "timestamp > ?2", "timestamp < ?3"}
+
+	// Compare the JSON text of attributes, so values match exactly (including types) like stats.Query.Match().
+	for attribute, value := range query.Where {
+		valueJSON, err := util.ToJSON(value)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to serialize metric query attribute '%s': '%w'.", attribute, err)
+		}
+
+		args = append(args, getAttributePath(attribute), valueJSON)
+		conditions = append(conditions, fmt.Sprintf("(data -> ?%d) = json(?%d)", len(args)-1, len(args)))
+	}
+
+	groupColumns := make([]string, 0, len(aggregation.GroupBy)+1)
+	groupExpressions := make([]string, 0, len(aggregation.GroupBy)+1)
+
+	for i, attribute := range aggregation.GroupBy {
+		args = append(args, getAttributePath(attribute))
+		groupColumns = append(groupColumns, fmt.Sprintf("group_%d", i))
+		groupExpressions = append(groupExpressions, fmt.Sprintf("(data -> ?%d) AS group_%d", len(args), i))
+	}
+
+	bucketSize := aggregation.GetBucketSize()
+	if bucketSize > 0 {
+		// Round down to the start of the bucket (even for negative times).
+		args = append(args, bucketSize)
+		groupColumns = append(groupColumns, "bucket_start")
+		groupExpressions = append(groupExpressions, fmt.Sprintf("(timestamp - (((timestamp %% ?%d) + ?%d) %% ?%d)) AS bucket_start", len(args), len(args), len(args)))
+	}
+
+	// Rollups count as all the metrics they summarize (see stats.Downsample()).
+	columns := append(slices.Clone(groupColumns),
+		"COALESCE(SUM(metric_count), 0)",
+		"SUM(metric_sum)",
+		"CAST(SUM(metric_sum) AS REAL) / NULLIF(SUM(metric_count), 0)",
+		"MIN(metric_min)",
+		"MAX(metric_max)",
+		"COUNT(*)")
+
+	// For each percentile, get the values at the ranks just below and above the percentile's rank.
+	for _, percentile := range aggregation.Percentiles {
+		args = append(args, percentile/100.0)
+		columns = append(columns,
+			fmt.Sprintf("MAX(CASE WHEN row_index <= (?%d * (row_count - 1)) THEN value END)", len(args)),
+			fmt.Sprintf("MIN(CASE WHEN row_index >= (?%d * (row_count - 1)) THEN value END)", len(args)))
+	}
+
+	partition := ""
+	if len(groupColumns) > 0 {
+		partition = "PARTITION BY " + strings.Join(groupColumns, ", ")
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT %s
+		FROM (
+			SELECT
+				*,
+				(ROW_NUMBER() OVER (%s ORDER BY value) - 1) AS row_index,
+				COUNT(*) OVER (%s) AS row_count
+			FROM (
+				SELECT
+					%s
+					COALESCE(data ->> '$.value', 0) AS value,
+					COALESCE(data ->> '$.rollup.count', 1) AS metric_count,
+					COALESCE(data ->> '$.rollup.sum', data ->> '$.value', 0) AS metric_sum,
+					COALESCE(data ->> '$.rollup.min', data ->> '$.value', 0) AS metric_min,
+					COALESCE(data ->> '$.rollup.max', data ->> '$.value', 0) AS metric_max
+				FROM metrics
+				WHERE %s
+			)
+		)`,
+		strings.Join(columns, ", "),
+		partition, partition,
+		strings.Join(append(groupExpressions, ""), ",\n"),
+		strings.Join(conditions, " AND "))
+
+	if len(groupColumns) > 0 {
+		sql += " GROUP BY " + strings.Join(groupColumns, ", ")
+	}
+
+	rows, err := this.db.Query(sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to aggregate metrics: '%w'.", err)
+	}
+	defer rows.Close()
+
+	results := make([]*stats.AggregateResult, 0)
+
+	for rows.Next() {
+		groupData := make([]*string, len(aggregation.GroupBy))
+		var bucketStart int64
+		var count, rowCount int64
+		var sum, mean, min, max *float64
+		percentileBounds := make([]*float64, 2*len(aggregation.Percentiles))
+
+		dest := make([]any, 0, len(columns))
+		for i := range groupData {
+			dest = append(dest, &groupData[i])
+		}
+
+		if bucketSize > 0 {
+			dest = append(dest, &bucketStart)
+		}
+
+		dest = append(dest, &count, &sum, &mean, &min, &max, &rowCount)
+
+		for i := range percentileBounds {
+			dest = append(dest, &percentileBounds[i])
+		}
+
+		err = rows.Scan(dest...)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read aggregate metrics: '%w'.", err)
+		}
+
+		// Aggregating without any groups will always return a row.
+		if rowCount == 0 {
+			continue
+		}
+
+		result := &stats.AggregateResult{
+			Values: make(map[string]float64),
+		}
+
+		for i, attribute := range aggregation.GroupBy {
+			if groupData[i] == nil {
+				continue
+			}
+
+			var value any
+			err = util.JSONFromString(*groupData[i], &value)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to deserialize aggregate group value: '%w'.", err)
+			}
+
+			if result.Group == nil {
+				result.Group = make(map[stats.MetricAttribute]any)
+			}
+
+			result.Group[attribute] = value
+		}
+
+		if bucketSize > 0 {
+			start := timestamp.Timestamp(bucketStart)
+			result.BucketStart = &start
+		}
+
+		functionValues := map[stats.AggregateFunction]*float64{
+			stats.AggregateFunctionSum:  sum,
+			stats.AggregateFunctionMean: mean,
+			stats.AggregateFunctionMin:  min,
+			stats.AggregateFunctionMax:  max,
+		}
+
+		for _, function := range aggregation.Functions {
+			if function == stats.AggregateFunctionCount {
+				result.Values[string(function)] = float64(count)
+			} else if functionValues[function] != nil {
+				result.Values[string(function)] = *functionValues[function]
+			}
+		}
+
+		for i, percentile := range aggregation.Percentiles {
+			lower := percentileBounds[2*i]
+			upper := percentileBounds[(2*i)+1]
+			if (lower == nil) || (upper == nil) {
+				continue
+			}
+
+			result.Values[stats.GetPercentileKey(percentile)] = interpolatePercentile(percentile, rowCount, *lower, *upper)
+		}
+
+		results = append(results, result)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read aggregate metrics: '%w'.", err)
+	}
+
+	return stats.SortAndLimitAggregates(results, query), nil
+}
+
+func (this *backend) ReplaceMetrics(query stats.Query, replacements []*stats.Metric) (int, error) {
+	if query.Type == "" {
+		return 0, fmt.Errorf("No metric type was given.")
+	}
+
+	before := int64(query.Before)
+	if before == 0 {
+		before = math.MaxInt64
+	}
+
+	count := 0
+
+	err := this.withTransaction(func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			`DELETE FROM metrics WHERE type = ? AND timestamp > ? AND timestamp < ?`,
+			string(query.Type), int64(query.After), before)
+		if err != nil {
+			return fmt.Errorf("Failed to remove metrics: '%w'.", err)
+		}
+
+		removed, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("Failed to count removed metrics: '%w'.", err)
+		}
+
+		count = int(removed)
+
+		for _, replacement := range replacements {
+			data, err := util.ToJSON(replacement)
+			if err != nil {
+				return fmt.Errorf("Failed to serialize metric: '%w'.", err)
+			}
+
+			_, err = tx.Exec(
+				`INSERT INTO metrics (type, timestamp, data) VALUES (?, ?, ?)`,
+				string(replacement.Type), int64(replacement.Timestamp), data)
+			if err != nil {
+				return fmt.Errorf("Failed to store replacement metric: '%w'.", err)
+			}
+		}
+
+		return nil
+	})
+
+	return count, err
+}
+
+func (this *backend) getMetrics(query stats.Query, sql string, args ...any) ([]*stats.Metric, error) {
+	if query.Type == "" {
+		return nil, fmt.Errorf("No metric type was given.")
+	}
+
+	rows, err := this.db.Query(sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query metrics: '%w'.", err)
+	}
+
+	dataList, err := collectBytes(rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read metrics: '%w'.", err)
+	}
+
+	records := make([]*stats.Metric, 0, len(dataList))
+	for _, data := range dataList {
+		var record stats.Metric
+		err = util.JSONFromBytes(data, &record)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to deserialize metric: '%w'.", err)
+		}
+
+		// Attribute values are compared in Go, so they match exactly like the other backends.
+		if !query.Match(&record) {
+			continue
+		}
+
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
+
+func (this *backend) StoreMetric(record *stats.Metric) error {
+	if record.Type == "" {
+		return fmt.Errorf("No metric type was given.")
+	}
+
+	data, err := util.ToJSON(record)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize metric: '%w'.", err)
+	}
+
+	_, err = this.db.Exec(
+		`INSERT INTO metrics (type, timestamp, data) VALUES (?, ?, ?)`,
+		string(record.Type), int64(record.Timestamp), data)
+	if err != nil {
+		return fmt.Errorf("Failed to store metric: '%w'.", err)
+	}
+
+	return nil
+}
+
+// Get the JSON path to a metric's attribute.
+func getAttributePath(attribute stats.MetricAttribute) string {
+	return fmt.Sprintf(`$.attributes."%s"`, attribute)
+}
+
+// Linearly interpolate a percentile between the values at the ranks just below and above it
+// (the same as stats.Aggregate()).
+func interpolatePercentile(percentile float64, count int64, lower float64, upper float64) float64 {
+	rank := (percentile / 100.0) * float64(count-1)
+	return lower + ((rank - math.Floor(rank)) * (upper - lower))
+}
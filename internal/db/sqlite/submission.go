@@ -0,0 +1,539 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"database/sql"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const SUBMISSION_COLUMNS = `info, input_files, output_files, stdout, stderr`
+
+// Select the most recent submission (largest short ID) for each of the users in a JSON array.
+// Parameters are the course ID, assignment ID, and a JSON array of emails.
+// The requested columns are substituted in for "%s".
+const RECENT_SUBMISSIONS_QUERY = `SELECT %s FROM (
+		SELECT *, ROW_NUMBER() OVER (PARTITION BY user_email ORDER BY short_id DESC) AS recent_rank
+		FROM submissions
+		WHERE course_id = ? AND assignment_id = ? AND user_email IN (SELECT value FROM json_each(?))
+	)
+	WHERE recent_rank = 1`
+
+func (this *backend) SaveSubmissions(course *model.Course, submissions []*model.GradingResult) error {
+	return this.withTransaction(func(tx *sql.Tx) error {
+		var errs error = nil
+
+		for _, submission := range submissions {
+			errs = errors.Join(errs, saveSubmission(tx, submission))
+		}
+
+		return errs
+	})
+}
+
+func saveSubmission(tx *sql.Tx, submission *model.GradingResult) error {
+	info := submission.Info
+
+	infoJSON, err := util.ToJSON(info)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize submission result '%s': '%w'.", info.ID, err)
+	}
+
+	inputJSON, err := serializeFiles(submission.InputFilesGZip)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize submission input files '%s': '%w'.", info.ID, err)
+	}
+
+	outputJSON, err := serializeFiles(submission.OutputFilesGZip)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize submission output files '%s': '%w'.", info.ID, err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO submissions
+			(course_id, assignment_id, user_email, short_id, grading_start_time, `+SUBMISSION_COLUMNS+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (course_id, assignment_id, user_email, short_id) DO UPDATE SET
+			grading_start_time = excluded.grading_start_time,
+			info = excluded.info,
+			input_files = excluded.input_files,
+			output_files = excluded.output_files,
+			stdout = excluded.stdout,
+			stderr = excluded.stderr`,
+		info.CourseID, info.AssignmentID, info.User, info.ShortID, int64(info.GradingStartTime),
+		infoJSON, inputJSON, outputJSON, []byte(submission.Stdout), []byte(submission.Stderr))
+	if err != nil {
+		return fmt.Errorf("Failed to save submission '%s': '%w'.", info.ID, err)
+	}
+
+	return nil
+}
+
+// Compute the next submission ID based on the current time.
+// If a submission ID exists for the current time, increment the ID until it is unique.
+// The next submission ID will be the largest ID for the user on this assignment.
+func (this *backend) GetNextSubmissionID(assignment *model.Assignment, email string) (string, error) {
+	submissionID := time.Now().Unix()
+
+	for {
+		exists := false
+		err := this.db.QueryRow(
+			`SELECT EXISTS (SELECT 1 FROM submissions
+				WHERE course_id = ? AND assignment_id = ? AND user_email = ? AND short_id = ?)`,
+			assignment.GetCourse().GetID(), assignment.GetID(), email, fmt.Sprintf("%d", submissionID)).Scan(&exists)
+		if err != nil {
+			return "", fmt.Errorf("Failed to check for existing submission ID: '%w'.", err)
+		}
+
+		if !exists {
+			break
+		}
+
+		// This ID has been used.
+		submissionID++
+	}
+
+	return fmt.Sprintf("%d", submissionID), nil
+}
+
+func (this *backend) GetPreviousSubmissionID(assignment *model.Assignment, email string, shortSubmissionID string) (string, error) {
+	history, err := this.GetSubmissionHistory(assignment, email)
+	if err != nil {
+		return "", err
+	}
+
+	if len(history) <= 1 {
+		return "", nil
+	}
+
+	index := -1
+	for i, item := range history {
+		if item.ShortID == shortSubmissionID {
+			index = i
+			break
+		}
+	}
+
+	if index <= 0 {
+		return "", nil
+	}
+
+	return history[index-1].ID, nil
+}
+
+func (this *backend) GetSubmissionResult(assignment *model.Assignment, email string, shortSubmissionID string) (*model.GradingInfo, error) {
+	var err error
+
+	if shortSubmissionID == "" {
+		shortSubmissionID, err = this.getMostRecentSubmissionID(assignment, email)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get most recent submission id: '%w'.", err)
+		}
+	}
+
+	if shortSubmissionID == "" {
+		return nil, nil
+	}
+
+	var infoJSON []byte
+	err = this.db.QueryRow(
+		`SELECT info FROM submissions
+		WHERE course_id = ? AND assignment_id = ? AND user_email = ? AND short_id = ?`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID).Scan(&infoJSON)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get submission result '%s': '%w'.", shortSubmissionID, err)
+	}
+
+	return parseGradingInfo(infoJSON)
+}
+
+func (this *backend) GetSubmissionHistory(assignment *model.Assignment, email string) ([]*model.SubmissionHistoryItem, error) {
+	rows, err := this.db.Query(
+		`SELECT info FROM submissions
+		WHERE course_id = ? AND assignment_id = ? AND user_email = ?
+		ORDER BY grading_start_time, short_id`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query submission history: '%w'.", err)
+	}
+
+	infoJSONs, err := collectBytes(rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read submission history: '%w'.", err)
+	}
+
+	history := make([]*model.SubmissionHistoryItem, 0, len(infoJSONs))
+	for _, infoJSON := range infoJSONs {
+		gradingInfo, err := parseGradingInfo(infoJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		history = append(history, gradingInfo.ToHistoryItem())
+	}
+
+	return history, nil
+}
+
+func (this *backend) GetRecentSubmissions(assignment *model.Assignment, reference *model.ParsedCourseUserReference) (map[string]*model.GradingInfo, error) {
+	emails, err := this.getReferencedEmails(assignment, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	emailsJSON, err := util.ToJSON(emails)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to serialize emails for recent submissions: '%w'.", err)
+	}
+
+	rows, err := this.db.Query(fmt.Sprintf(RECENT_SUBMISSIONS_QUERY, `user_email, info`),
+		assignment.GetCourse().GetID(), assignment.GetID(), emailsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query recent submissions: '%w'.", err)
+	}
+	defer rows.Close()
+
+	gradingInfos := make(map[string]*model.GradingInfo, len(emails))
+	for _, email := range emails {
+		gradingInfos[email] = nil
+	}
+
+	for rows.Next() {
+		var email string
+		var infoJSON []byte
+
+		err = rows.Scan(&email, &infoJSON)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read recent submission: '%w'.", err)
+		}
+
+		gradingInfos[email], err = parseGradingInfo(infoJSON)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read recent submissions: '%w'.", err)
+	}
+
+	return gradingInfos, nil
+}
+
+func (this *backend) GetScoringInfos(assignment *model.Assignment, reference *model.ParsedCourseUserReference) (map[string]*model.ScoringInfo, error) {
+	scoringInfos := make(map[string]*model.ScoringInfo)
+
+	submissionResults, err := this.GetRecentSubmissions(assignment, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	for email, submissionResult := range submissionResults {
+		if submissionResult == nil {
+			scoringInfos[email] = nil
+		} else {
+			scoringInfos[email] = submissionResult.ToScoringInfo()
+		}
+	}
+
+	return scoringInfos, nil
+}
+
+func (this *backend) GetRecentSubmissionSurvey(assignment *model.Assignment, reference *model.ParsedCourseUserReference) (map[string]*model.SubmissionHistoryItem, error) {
+	results := make(map[string]*model.SubmissionHistoryItem)
+
+	submissionResults, err := this.GetRecentSubmissions(assignment, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	for email, submissionResult := range submissionResults {
+		if submissionResult == nil {
+			results[email] = nil
+		} else {
+			results[email] = submissionResult.ToHistoryItem()
+		}
+	}
+
+	return results, nil
+}
+
+func (this *backend) GetSubmissionContents(assignment *model.Assignment, email string, shortSubmissionID string) (*model.GradingResult, error) {
+	var err error
+
+	if shortSubmissionID == "" {
+		shortSubmissionID, err = this.getMostRecentSubmissionID(assignment, email)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get most recent submission id: '%w'.", err)
+		}
+	}
+
+	if shortSubmissionID == "" {
+		return nil, nil
+	}
+
+	rows, err := this.db.Query(
+		`SELECT `+SUBMISSION_COLUMNS+` FROM submissions
+		WHERE course_id = ? AND assignment_id = ? AND user_email = ? AND short_id = ?`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query submission contents '%s': '%w'.", shortSubmissionID, err)
+	}
+
+	results, err := collectGradingResults(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	return results[0], nil
+}
+
+func (this *backend) GetRecentSubmissionContents(assignment *model.Assignment, reference *model.ParsedCourseUserReference) (map[string]*model.GradingResult, error) {
+	emails, err := this.getReferencedEmails(assignment, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	emailsJSON, err := util.ToJSON(emails)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to serialize emails for recent submission contents: '%w'.", err)
+	}
+
+	rows, err := this.db.Query(fmt.Sprintf(RECENT_SUBMISSIONS_QUERY, SUBMISSION_COLUMNS),
+		assignment.GetCourse().GetID(), assignment.GetID(), emailsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query recent submission contents: '%w'.", err)
+	}
+
+	submissions, err := collectGradingResults(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*model.GradingResult, len(emails))
+	for _, email := range emails {
+		results[email] = nil
+	}
+
+	for _, submission := range submissions {
+		results[submission.Info.User] = submission
+	}
+
+	return results, nil
+}
+
+func (this *backend) RemoveSubmission(assignment *model.Assignment, email string, shortSubmissionID string) (bool, error) {
+	var err error
+
+	if shortSubmissionID == "" {
+		shortSubmissionID, err = this.getMostRecentSubmissionID(assignment, email)
+		if err != nil {
+			return false, fmt.Errorf("Failed to get most recent submission id: `%w`.", err)
+		}
+	}
+
+	if shortSubmissionID == "" {
+		return false, nil
+	}
+
+	var count int64 = 0
+
+	err = this.withTransaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`DELETE FROM manual_grades
+			WHERE course_id = ? AND assignment_id = ? AND user_email = ? AND short_id = ?`,
+			assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID)
+		if err != nil {
+			return fmt.Errorf("Failed to remove manual grade for submission '%s': '%w'", shortSubmissionID, err)
+		}
+
+		result, err := tx.Exec(
+			`DELETE FROM submissions
+			WHERE course_id = ? AND assignment_id = ? AND user_email = ? AND short_id = ?`,
+			assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID)
+		if err != nil {
+			return fmt.Errorf("Failed to remove submission '%s': '%w'", shortSubmissionID, err)
+		}
+
+		count, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("Failed to check removed submission '%s': '%w'", shortSubmissionID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return (count > 0), nil
+}
+
+func (this *backend) GetSubmissionAttempts(assignment *model.Assignment, email string) ([]*model.GradingResult, error) {
+	rows, err := this.db.Query(
+		`SELECT `+SUBMISSION_COLUMNS+` FROM submissions
+		WHERE course_id = ? AND assignment_id = ? AND user_email = ?
+		ORDER BY short_id`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query submission attempts for %s: '%w'.", email, err)
+	}
+
+	return collectGradingResults(rows)
+}
+
+// Get the short ID of the most recent submission (or empty string if there are no submissions).
+// The most recent submission has the largest ID for the user.
+func (this *backend) getMostRecentSubmissionID(assignment *model.Assignment, email string) (string, error) {
+	shortSubmissionID := ""
+	err := this.db.QueryRow(
+		`SELECT short_id FROM submissions
+		WHERE course_id = ? AND assignment_id = ? AND user_email = ?
+		ORDER BY short_id DESC
+		LIMIT 1`,
+		assignment.GetCourse().GetID(), assignment.GetID(), email).Scan(&shortSubmissionID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return shortSubmissionID, nil
+}
+
+// Get the emails of the course users that match a reference.
+func (this *backend) getReferencedEmails(assignment *model.Assignment, reference *model.ParsedCourseUserReference) ([]string, error) {
+	users, err := this.GetCourseUsers(assignment.GetCourse())
+	if err != nil {
+		return nil, err
+	}
+
+	emails := make([]string, 0, len(users))
+	for email, user := range users {
+		if reference.RefersTo(email, user.Role) {
+			emails = append(emails, email)
+		}
+	}
+
+	return emails, nil
+}
+
+func (this *backend) dumpSubmissions(course *model.Course, baseDir string) error {
+	rows, err := this.db.Query(
+		`SELECT `+SUBMISSION_COLUMNS+` FROM submissions WHERE course_id = ?`,
+		course.GetID())
+	if err != nil {
+		return fmt.Errorf("Failed to query submissions for course '%s': '%w'.", course.GetID(), err)
+	}
+
+	submissions, err := collectGradingResults(rows)
+	if err != nil {
+		return err
+	}
+
+	for _, submission := range submissions {
+		info := submission.Info
+		dir := filepath.Join(baseDir, info.AssignmentID, info.User, info.ShortID)
+
+		err = model.WriteGradingResult(submission, dir)
+		if err != nil {
+			return fmt.Errorf("Failed to dump submission '%s': '%w'.", info.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// Read grading results from rows that contain the standard submission columns.
+func collectGradingResults(rows *sql.Rows) ([]*model.GradingResult, error) {
+	defer rows.Close()
+
+	results := make([]*model.GradingResult, 0)
+	for rows.Next() {
+		var infoJSON []byte
+		var inputJSON []byte
+		var outputJSON []byte
+		var stdout []byte
+		var stderr []byte
+
+		err := rows.Scan(&infoJSON, &inputJSON, &outputJSON, &stdout, &stderr)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read submission: '%w'.", err)
+		}
+
+		result, err := parseGradingResult(infoJSON, inputJSON, outputJSON, stdout, stderr)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read submissions: '%w'.", err)
+	}
+
+	return results, nil
+}
+
+func serializeFiles(files map[string][]byte) (string, error) {
+	if files == nil {
+		files = make(map[string][]byte, 0)
+	}
+
+	return util.ToJSON(files)
+}
+
+func parseGradingResult(infoJSON []byte, inputJSON []byte, outputJSON []byte, stdout []byte, stderr []byte) (*model.GradingResult, error) {
+	info, err := parseGradingInfo(infoJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	inputFiles := make(map[string][]byte)
+	err = util.JSONFromBytes(inputJSON, &inputFiles)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to deserialize submission input files for '%s': '%w'.", info.ID, err)
+	}
+
+	outputFiles := make(map[string][]byte)
+	err = util.JSONFromBytes(outputJSON, &outputFiles)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to deserialize submission output files for '%s': '%w'.", info.ID, err)
+	}
+
+	return &model.GradingResult{
+		Info:            info,
+		InputFilesGZip:  inputFiles,
+		OutputFilesGZip: outputFiles,
+		Stdout:          string(stdout),
+		Stderr:          string(stderr),
+	}, nil
+}
+
+func parseGradingInfo(infoJSON []byte) (*model.GradingInfo, error) {
+	var gradingInfo model.GradingInfo
+	err := util.JSONFromBytes(infoJSON, &gradingInfo)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to deserialize grading info: '%w'.", err)
+	}
+
+	return &gradingInfo, nil
+}
@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"database/sql"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *backend) GetActiveCourseTasks(course *model.Course) (map[string]*model.FullScheduledTask, error) {
+	return queryTasks(this.db, `SELECT data FROM tasks WHERE source = ? AND course_id = ?`,
+		string(model.TaskSourceCourse), course.GetID())
+}
+
+func (this *backend) GetActiveTasks() (map[string]*model.FullScheduledTask, error) {
+	return queryTasks(this.db, `SELECT data FROM tasks`)
+}
+
+func (this *backend) GetNextActiveTask() (*model.FullScheduledTask, error) {
+	tasks, err := queryTasks(this.db, `SELECT data FROM tasks ORDER BY next_run_time LIMIT 1`)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range tasks {
+		return task, nil
+	}
+
+	return nil, nil
+}
+
+func (this *backend) UpsertActiveTasks(upsertTasks map[string]*model.FullScheduledTask) error {
+	return this.withTransaction(func(tx *sql.Tx) error {
+		for hash, upsertTask := range upsertTasks {
+			if upsertTask == nil {
+				_, err := tx.Exec(`DELETE FROM tasks WHERE hash = ?`, hash)
+				if err != nil {
+					return fmt.Errorf("Failed to remove active task '%s': '%w'.", hash, err)
+				}
+
+				continue
+			}
+
+			data, err := util.ToJSON(upsertTask)
+			if err != nil {
+				return fmt.Errorf("Failed to serialize active task '%s': '%w'.", hash, err)
+			}
+
+			_, err = tx.Exec(
+				`INSERT INTO tasks (hash, source, course_id, next_run_time, data) VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (hash) DO UPDATE SET
+					source = excluded.source,
+					course_id = excluded.course_id,
+					next_run_time = excluded.next_run_time,
+					data = excluded.data`,
+				hash, string(upsertTask.Source), upsertTask.CourseID, int64(upsertTask.NextRunTime), data)
+			if err != nil {
+				return fmt.Errorf("Failed to upsert active task '%s': '%w'.", hash, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Query for tasks (keyed by hash).
+// The query must select only the task data.
+func queryTasks(db querier, query string, args ...any) (map[string]*model.FullScheduledTask, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query active tasks: '%w'.", err)
+	}
+
+	dataList, err := collectBytes(rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read active tasks: '%w'.", err)
+	}
+
+	tasks := make(map[string]*model.FullScheduledTask, len(dataList))
+	for _, data := range dataList {
+		var task model.FullScheduledTask
+		err = util.JSONFromBytes(data, &task)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to deserialize active task: '%w'.", err)
+		}
+
+		tasks[task.Hash] = &task
+	}
+
+	return tasks, nil
+}
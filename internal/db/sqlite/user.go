@@ -0,0 +1,207 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// A WHERE clause that matches users enrolled in the course given as the only parameter.
+const USER_IN_COURSE_CLAUSE = `EXISTS (SELECT 1 FROM json_each(users.data, '$."course-info"') WHERE json_each.key = ?)`
+
+func (this *backend) GetServerUsers() (map[string]*model.ServerUser, error) {
+	return queryServerUsers(this.db, `SELECT data FROM users`)
+}
+
+func (this *backend) GetCourseUsers(course *model.Course) (map[string]*model.CourseUser, error) {
+	users, err := queryServerUsers(this.db, `SELECT data FROM users WHERE `+USER_IN_COURSE_CLAUSE, course.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	courseUsers := make(map[string]*model.CourseUser)
+	for email, user := range users {
+		// Don't include root as a course user.
+		if email == model.RootUserEmail {
+			continue
+		}
+
+		courseUser, err := user.ToCourseUser(course.ID, false)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid user '%s': '%w'.", email, err)
+		}
+
+		if courseUser != nil {
+			courseUsers[courseUser.Email] = courseUser
+		}
+	}
+
+	return courseUsers, nil
+}
+
+func (this *backend) GetServerUser(email string) (*model.ServerUser, error) {
+	users, err := queryServerUsers(this.db, `SELECT data FROM users WHERE email = ?`, email)
+	if err != nil {
+		return nil, err
+	}
+
+	user, exists := users[email]
+	if !exists {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+func (this *backend) UpsertUsers(upsertUsers map[string]*model.ServerUser) error {
+	return this.withTransaction(func(tx *sql.Tx) error {
+		for email, upsertUser := range upsertUsers {
+			if upsertUser == nil {
+				continue
+			}
+
+			users, err := queryServerUsers(tx, `SELECT data FROM users WHERE email = ?`, email)
+			if err != nil {
+				return fmt.Errorf("Failed to get user '%s' to merge before saving: '%w'.", email, err)
+			}
+
+			user, exists := users[email]
+			if exists {
+				_, err = user.Merge(upsertUser)
+				if err != nil {
+					return fmt.Errorf("User '%s' could not be merged with existing user: '%w'.", email, err)
+				}
+			} else {
+				user = upsertUser
+			}
+
+			err = saveServerUser(tx, email, user)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (this *backend) DeleteUser(email string) error {
+	_, err := this.db.Exec(`DELETE FROM users WHERE email = ?`, email)
+	if err != nil {
+		return fmt.Errorf("Failed to delete user '%s': '%w'.", email, err)
+	}
+
+	return nil
+}
+
+func (this *backend) RemoveUserFromCourse(course *model.Course, email string) error {
+	return this.withTransaction(func(tx *sql.Tx) error {
+		users, err := queryServerUsers(tx, `SELECT data FROM users WHERE email = ?`, email)
+		if err != nil {
+			return fmt.Errorf("Failed to get user when removing user '%s' from course '%s': '%w'.", email, course.GetID(), err)
+		}
+
+		user, ok := users[email]
+		if !ok {
+			return nil
+		}
+
+		_, enrolled := user.CourseInfo[course.GetID()]
+		if !enrolled {
+			return nil
+		}
+
+		delete(user.CourseInfo, course.GetID())
+
+		return saveServerUser(tx, email, user)
+	})
+}
+
+func (this *backend) DeleteUserToken(email string, tokenID string) (bool, error) {
+	removed := false
+
+	err := this.withTransaction(func(tx *sql.Tx) error {
+		users, err := queryServerUsers(tx, `SELECT data FROM users WHERE email = ?`, email)
+		if err != nil {
+			return fmt.Errorf("Failed to get user when deleting user token '%s': '%w'.", email, err)
+		}
+
+		user, ok := users[email]
+		if !ok {
+			return nil
+		}
+
+		for i, token := range user.Tokens {
+			if tokenID == token.ID {
+				user.Tokens = slices.Delete(user.Tokens, i, i+1)
+				removed = true
+				break
+			}
+		}
+
+		if !removed {
+			return nil
+		}
+
+		return saveServerUser(tx, email, user)
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return removed, nil
+}
+
+// Query for users and validate them.
+// The query must select only the user data.
+func queryServerUsers(db querier, query string, args ...any) (map[string]*model.ServerUser, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query users: '%w'.", err)
+	}
+
+	dataList, err := collectBytes(rows)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read users: '%w'.", err)
+	}
+
+	users := make(map[string]*model.ServerUser, len(dataList))
+	for _, data := range dataList {
+		var user model.ServerUser
+		err = util.JSONFromBytes(data, &user)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to deserialize user: '%w'.", err)
+		}
+
+		users[user.Email] = &user
+	}
+
+	var errs error = nil
+	for _, user := range users {
+		errs = errors.Join(errs, user.Validate())
+	}
+
+	return users, errs
+}
+
+func saveServerUser(tx *sql.Tx, email string, user *model.ServerUser) error {
+	data, err := util.ToJSON(user)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize user '%s': '%w'.", email, err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO users (email, data) VALUES (?, ?)
+		ON CONFLICT (email) DO UPDATE SET data = excluded.data`,
+		email, data)
+	if err != nil {
+		return fmt.Errorf("Unable to save user '%s': '%w'.", email, err)
+	}
+
+	return nil
+}
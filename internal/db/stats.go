@@ -32,6 +32,31 @@ func GetMetricsFull(query stats.Query, useTestingData bool) ([]*stats.Metric, er
 	return metrics, nil
 }
 
+func GetAggregateMetrics(query stats.Query) ([]*stats.AggregateResult, error) {
+	return GetAggregateMetricsFull(query, false)
+}
+
+func GetAggregateMetricsFull(query stats.Query, useTestingData bool) ([]*stats.AggregateResult, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("Database has not been opened.")
+	}
+
+	if query.Aggregate == nil {
+		return nil, fmt.Errorf("No aggregation was given.")
+	}
+
+	if !useTestingData {
+		return backend.GetAggregateMetrics(query)
+	}
+
+	metrics, err := GetMetricsFull(query, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats.Aggregate(metrics, query), nil
+}
+
 func StoreMetric(record *stats.Metric) error {
 	if backend == nil {
 		return fmt.Errorf("Database has not been opened.")
@@ -40,6 +65,60 @@ func StoreMetric(record *stats.Metric) error {
 	return backend.StoreMetric(record)
 }
 
+func GetMetricsInWindow(query stats.Query) ([]*stats.Metric, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("Database has not been opened.")
+	}
+
+	if query.Type == "" {
+		return nil, fmt.Errorf("No metric type was given.")
+	}
+
+	return backend.GetMetricsInWindow(query)
+}
+
+func CountMetricsInWindow(query stats.Query) (int, error) {
+	if backend == nil {
+		return 0, fmt.Errorf("Database has not been opened.")
+	}
+
+	if query.Type == "" {
+		return 0, fmt.Errorf("No metric type was given.")
+	}
+
+	return backend.CountMetricsInWindow(query)
+}
+
+func GetOldestMetricTimeInWindow(query stats.Query) (*timestamp.Timestamp, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("Database has not been opened.")
+	}
+
+	if query.Type == "" {
+		return nil, fmt.Errorf("No metric type was given.")
+	}
+
+	return backend.GetOldestMetricTimeInWindow(query)
+}
+
+func ReplaceMetrics(query stats.Query, replacements []*stats.Metric) (int, error) {
+	if backend == nil {
+		return 0, fmt.Errorf("Database has not been opened.")
+	}
+
+	if query.Type == "" {
+		return 0, fmt.Errorf("No metric type was given.")
+	}
+
+	for _, replacement := range replacements {
+		if replacement.Type != query.Type {
+			return 0, fmt.Errorf("Replacement metric type ('%s') does not match the query type ('%s').", replacement.Type, query.Type)
+		}
+	}
+
+	return backend.ReplaceMetrics(query, replacements)
+}
+
 var TESTING_STATS_METRICS []*stats.Metric = []*stats.Metric{
 	&stats.Metric{
 		Timestamp: timestamp.Timestamp(1100),
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"fmt"
+	"math"
 	"reflect"
 	"strings"
 	"testing"
@@ -249,3 +251,311 @@ func (this *DBTests) DBTestGetTestingMetrics(test *testing.T) {
 		}
 	}
 }
+
+func (this *DBTests) DBTestGetAggregateMetrics(test *testing.T) {
+	Clear()
+	defer Clear()
+
+	minute := int64(60 * 1000)
+
+	metrics := []*stats.Metric{
+		&stats.Metric{Timestamp: timestamp.FromMSecs(minute + 1), Type: stats.MetricTypeGradingTime, Value: 10, Attributes: map[stats.MetricAttribute]any{stats.MetricAttributeCourseID: "C1", stats.MetricAttributeAssignmentID: "A1"}},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(minute + 2), Type: stats.MetricTypeGradingTime, Value: 20, Attributes: map[stats.MetricAttribute]any{stats.MetricAttributeCourseID: "C1", stats.MetricAttributeAssignmentID: "A1"}},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(minute + 3), Type: stats.MetricTypeGradingTime, Value: 30, Attributes: map[stats.MetricAttribute]any{stats.MetricAttributeCourseID: "C1", stats.MetricAttributeAssignmentID: "A2"}},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(2*minute + 1), Type: stats.MetricTypeGradingTime, Value: 40, Attributes: map[stats.MetricAttribute]any{stats.MetricAttributeCourseID: "C2", stats.MetricAttributeAssignmentID: "A1"}},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(2*minute + 2), Type: stats.MetricTypeGradingTime, Value: 50, Attributes: map[stats.MetricAttribute]any{stats.MetricAttributeCourseID: "C2"}},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(2*minute + 3), Type: stats.MetricTypeTaskTime, Value: 60, Attributes: map[stats.MetricAttribute]any{stats.MetricAttributeCourseID: "C1"}},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(3 * minute), Type: stats.MetricTypeGradingTime, Value: 15, Attributes: map[stats.MetricAttribute]any{stats.MetricAttributeCourseID: "C1", stats.MetricAttributeAssignmentID: "A2"}, Rollup: &stats.MetricRollup{Bucket: stats.TimeBucketMinute, Count: 4, Sum: 60, Min: 5, Max: 30}},
+	}
+
+	for _, metric := range metrics {
+		err := StoreMetric(metric)
+		if err != nil {
+			test.Fatalf("Failed to store metric: '%v'.", err)
+		}
+	}
+
+	allFunctions := []stats.AggregateFunction{
+		stats.AggregateFunctionCount,
+		stats.AggregateFunctionSum,
+		stats.AggregateFunctionMean,
+		stats.AggregateFunctionMin,
+		stats.AggregateFunctionMax,
+	}
+
+	testCases := []stats.Query{
+		stats.Query{
+			Aggregate: &stats.Aggregation{},
+		},
+		stats.Query{
+			Aggregate: &stats.Aggregation{
+				Functions:   allFunctions,
+				Percentiles: []float64{50, 95},
+			},
+		},
+		stats.Query{
+			Aggregate: &stats.Aggregation{
+				GroupBy:   []stats.MetricAttribute{stats.MetricAttributeAssignmentID},
+				Functions: allFunctions,
+			},
+		},
+		stats.Query{
+			Aggregate: &stats.Aggregation{
+				GroupBy:     []stats.MetricAttribute{stats.MetricAttributeCourseID, stats.MetricAttributeAssignmentID},
+				TimeBucket:  stats.TimeBucketMinute,
+				Percentiles: []float64{25},
+			},
+		},
+		stats.Query{
+			Aggregate: &stats.Aggregation{
+				GroupBy:     []stats.MetricAttribute{stats.MetricAttributeCourseID},
+				TimeBucket:  stats.TimeBucketHour,
+				Functions:   allFunctions,
+				Percentiles: []float64{0, 10, 33.3, 100},
+			},
+		},
+		stats.Query{
+			Sort:  1,
+			Limit: 1,
+			Aggregate: &stats.Aggregation{
+				TimeBucket: stats.TimeBucketMinute,
+			},
+		},
+		stats.Query{
+			After:  timestamp.FromMSecs(minute + 1),
+			Before: timestamp.FromMSecs(2*minute + 2),
+			Where: map[stats.MetricAttribute]any{
+				stats.MetricAttributeAssignmentID: "A1",
+			},
+			Aggregate: &stats.Aggregation{
+				Functions: allFunctions,
+			},
+		},
+		stats.Query{
+			Where: map[stats.MetricAttribute]any{
+				stats.MetricAttributeCourseID: "ZZZ",
+			},
+			Aggregate: &stats.Aggregation{},
+		},
+	}
+
+	for i, query := range testCases {
+		query.Type = stats.MetricTypeGradingTime
+
+		err := query.Validate()
+		if err != nil {
+			test.Errorf("Case %d: Failed to validate query: '%v'.", i, err)
+			continue
+		}
+
+		// The backend should aggregate the same way as the generic aggregation.
+		records, err := GetMetrics(query)
+		if err != nil {
+			test.Errorf("Case %d: Failed to get metrics: '%v'.", i, err)
+			continue
+		}
+
+		expected := stats.Aggregate(records, query)
+
+		actual, err := GetAggregateMetrics(query)
+		if err != nil {
+			test.Errorf("Case %d: Failed to aggregate metrics: '%v'.", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(expected, actual) {
+			test.Errorf("Case %d: Unexpected aggregates. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(expected), util.MustToJSONIndent(actual))
+			continue
+		}
+	}
+
+	_, err := GetAggregateMetrics(stats.Query{Type: stats.MetricTypeGradingTime})
+	if err == nil {
+		test.Fatalf("Did not get an error when aggregating without an aggregation.")
+	}
+}
+
+func (this *DBTests) DBTestGetMetricsInWindow(test *testing.T) {
+	Clear()
+	defer Clear()
+
+	for i := 1; i <= 5; i++ {
+		for _, metricType := range []stats.MetricType{stats.MetricTypeSystemCPU, stats.MetricTypeSystemMemory} {
+			metric := &stats.Metric{
+				Timestamp:  timestamp.FromMSecs(int64(i * 100)),
+				Type:       metricType,
+				Value:      float64(i),
+				Attributes: map[stats.MetricAttribute]any{stats.MetricAttributeCourseID: fmt.Sprintf("C%d", i%2)},
+			}
+
+			err := StoreMetric(metric)
+			if err != nil {
+				test.Fatalf("Failed to store metric: '%v'.", err)
+			}
+		}
+	}
+
+	testCases := []struct {
+		query    stats.Query
+		expected []int64
+	}{
+		{stats.Query{}, []int64{100, 200, 300, 400, 500}},
+		{stats.Query{After: timestamp.FromMSecs(199)}, []int64{200, 300, 400, 500}},
+		{stats.Query{Before: timestamp.FromMSecs(400)}, []int64{100, 200, 300}},
+		{stats.Query{After: timestamp.FromMSecs(199), Before: timestamp.FromMSecs(401)}, []int64{200, 300, 400}},
+		{stats.Query{After: timestamp.FromMSecs(math.MinInt64), Before: timestamp.FromMSecs(200)}, []int64{100}},
+		{stats.Query{After: timestamp.FromMSecs(500)}, []int64{}},
+
+		// Attributes, sort, and limit are ignored.
+		{stats.Query{Where: map[stats.MetricAttribute]any{stats.MetricAttributeCourseID: "C1"}, Sort: 1, Limit: 1}, []int64{100, 200, 300, 400, 500}},
+	}
+
+	for i, testCase := range testCases {
+		testCase.query.Type = stats.MetricTypeSystemCPU
+
+		metrics, err := GetMetricsInWindow(testCase.query)
+		if err != nil {
+			test.Errorf("Case %d: Failed to get metrics: '%v'.", i, err)
+			continue
+		}
+
+		actual := make([]int64, 0, len(metrics))
+		for _, metric := range metrics {
+			if metric.Type != stats.MetricTypeSystemCPU {
+				test.Errorf("Case %d: Got a metric of the wrong type: '%s'.", i, util.MustToJSON(metric))
+			}
+
+			actual = append(actual, metric.Timestamp.ToMSecs())
+		}
+
+		if !reflect.DeepEqual(testCase.expected, actual) {
+			test.Errorf("Case %d: Unexpected metric times. Expected: '%v', Actual: '%v'.", i, testCase.expected, actual)
+			continue
+		}
+
+		count, err := CountMetricsInWindow(testCase.query)
+		if err != nil {
+			test.Errorf("Case %d: Failed to count metrics: '%v'.", i, err)
+			continue
+		}
+
+		if len(testCase.expected) != count {
+			test.Errorf("Case %d: Unexpected count. Expected: %d, Actual: %d.", i, len(testCase.expected), count)
+			continue
+		}
+
+		oldest, err := GetOldestMetricTimeInWindow(testCase.query)
+		if err != nil {
+			test.Errorf("Case %d: Failed to get the oldest metric time: '%v'.", i, err)
+			continue
+		}
+
+		if len(testCase.expected) == 0 {
+			if oldest != nil {
+				test.Errorf("Case %d: Unexpected oldest metric time. Expected: nil, Actual: '%d'.", i, *oldest)
+			}
+
+			continue
+		}
+
+		if (oldest == nil) || (testCase.expected[0] != oldest.ToMSecs()) {
+			test.Errorf("Case %d: Unexpected oldest metric time. Expected: '%d', Actual: '%v'.", i, testCase.expected[0], oldest)
+			continue
+		}
+	}
+
+	_, err := GetMetricsInWindow(stats.Query{})
+	if err == nil {
+		test.Fatalf("Did not get an error when getting metrics without a type.")
+	}
+
+	_, err = CountMetricsInWindow(stats.Query{})
+	if err == nil {
+		test.Fatalf("Did not get an error when counting metrics without a type.")
+	}
+
+	_, err = GetOldestMetricTimeInWindow(stats.Query{})
+	if err == nil {
+		test.Fatalf("Did not get an error when getting the oldest metric time without a type.")
+	}
+}
+
+func (this *DBTests) DBTestReplaceMetrics(test *testing.T) {
+	Clear()
+	defer Clear()
+
+	for i := 1; i <= 5; i++ {
+		for _, metricType := range []stats.MetricType{stats.MetricTypeSystemCPU, stats.MetricTypeSystemMemory} {
+			err := StoreMetric(&stats.Metric{Timestamp: timestamp.FromMSecs(int64(i * 100)), Type: metricType, Value: float64(i)})
+			if err != nil {
+				test.Fatalf("Failed to store metric: '%v'.", err)
+			}
+		}
+	}
+
+	rollup := &stats.Metric{
+		Timestamp: timestamp.FromMSecs(200),
+		Type:      stats.MetricTypeSystemCPU,
+		Value:     3,
+		Rollup: &stats.MetricRollup{
+			Bucket: stats.TimeBucketHour,
+			Count:  3,
+			Sum:    9,
+			Min:    2,
+			Max:    4,
+		},
+	}
+
+	// Replace (200, 400].
+	count, err := ReplaceMetrics(stats.Query{Type: stats.MetricTypeSystemCPU, After: timestamp.FromMSecs(199), Before: timestamp.FromMSecs(401)}, []*stats.Metric{rollup})
+	if err != nil {
+		test.Fatalf("Failed to replace metrics: '%v'.", err)
+	}
+
+	if count != 3 {
+		test.Fatalf("Unexpected number of replaced metrics. Expected: 3, Actual: %d.", count)
+	}
+
+	// Remove everything before 200 (which does not include the rollup).
+	count, err = ReplaceMetrics(stats.Query{Type: stats.MetricTypeSystemCPU, Before: timestamp.FromMSecs(200)}, nil)
+	if err != nil {
+		test.Fatalf("Failed to remove metrics: '%v'.", err)
+	}
+
+	if count != 1 {
+		test.Fatalf("Unexpected number of removed metrics. Expected: 1, Actual: %d.", count)
+	}
+
+	query := stats.Query{Type: stats.MetricTypeSystemCPU, Sort: -1}
+	metrics, err := GetMetrics(query)
+	if err != nil {
+		test.Fatalf("Failed to get metrics: '%v'.", err)
+	}
+
+	expected := []*stats.Metric{
+		rollup,
+		&stats.Metric{Timestamp: timestamp.FromMSecs(500), Type: stats.MetricTypeSystemCPU, Value: 5},
+	}
+
+	actual := stats.LimitAndSort(metrics, query)
+	if !reflect.DeepEqual(expected, actual) {
+		test.Fatalf("Unexpected metrics. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(actual))
+	}
+
+	// Other types are untouched.
+	metrics, err = GetMetrics(stats.Query{Type: stats.MetricTypeSystemMemory})
+	if err != nil {
+		test.Fatalf("Failed to get other metrics: '%v'.", err)
+	}
+
+	if len(metrics) != 5 {
+		test.Fatalf("Unexpected number of other metrics. Expected: 5, Actual: %d.", len(metrics))
+	}
+
+	// Replacements must match the query type.
+	_, err = ReplaceMetrics(stats.Query{Type: stats.MetricTypeSystemMemory}, []*stats.Metric{rollup})
+	if err == nil {
+		test.Fatalf("Did not get an error when replacing with a metric of a different type.")
+	}
+}
@@ -0,0 +1,230 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+const (
+	BYTES_PER_MB = 1024 * 1024
+
+	// The path (inside the container) that a tmpfs is mounted at when a tmpfs size is set.
+	TMPFS_PATH = "/tmp"
+)
+
+// How often the output dir is checked while a container is running.
+var outputSizeCheckInterval time.Duration = 250 * time.Millisecond
+
+// A way that a container went past one of its resource limits.
+type LimitViolation string
+
+const (
+	LimitViolationNone       LimitViolation = ""
+	LimitViolationMemory     LimitViolation = "memory"
+	LimitViolationOutputSize LimitViolation = "output-size"
+)
+
+// Limits on the resources a container can use.
+// All limits are optional, and a zero value means that there is no limit.
+type ResourceLimits struct {
+	// The maximum amount of memory (in MB) a container can use before it is killed.
+	// Swap is not allowed when this is set.
+	MemoryMB int64 `json:"memory-mb,omitempty"`
+
+	// The number of CPUs (can be fractional) a container can use.
+	CPUs float64 `json:"cpus,omitempty"`
+
+	// The maximum number of processes/threads that can be running in a container.
+	MaxPIDs int64 `json:"max-pids,omitempty"`
+
+	// Standard ulimits (e.g., "nofile", "fsize", "nproc").
+	Ulimits []*Ulimit `json:"ulimits,omitempty"`
+
+	// If set, a tmpfs (memory-backed file system) of this size (in MB) will be mounted at TMPFS_PATH.
+	TmpfsSizeMB int64 `json:"tmpfs-size-mb,omitempty"`
+
+	// The maximum total size (in MB) of all files the grader writes to its output directory.
+	OutputSizeMB int64 `json:"output-size-mb,omitempty"`
+}
+
+type Ulimit struct {
+	Name string `json:"name"`
+	Soft int64  `json:"soft"`
+	Hard int64  `json:"hard"`
+}
+
+func (this *ResourceLimits) Validate() error {
+	if this == nil {
+		return nil
+	}
+
+	if this.MemoryMB < 0 {
+		return fmt.Errorf("Memory limit must be non-negative, found: %d.", this.MemoryMB)
+	}
+
+	if this.CPUs < 0 {
+		return fmt.Errorf("CPU limit must be non-negative, found: %f.", this.CPUs)
+	}
+
+	if this.MaxPIDs < 0 {
+		return fmt.Errorf("PID limit must be non-negative, found: %d.", this.MaxPIDs)
+	}
+
+	if this.TmpfsSizeMB < 0 {
+		return fmt.Errorf("Tmpfs size must be non-negative, found: %d.", this.TmpfsSizeMB)
+	}
+
+	if this.OutputSizeMB < 0 {
+		return fmt.Errorf("Output size limit must be non-negative, found: %d.", this.OutputSizeMB)
+	}
+
+	if this.Ulimits == nil {
+		this.Ulimits = make([]*Ulimit, 0)
+	}
+
+	for i, ulimit := range this.Ulimits {
+		if ulimit == nil {
+			return fmt.Errorf("Ulimit at index %d is nil.", i)
+		}
+
+		if ulimit.Name == "" {
+			return fmt.Errorf("Ulimit at index %d is missing a name.", i)
+		}
+
+		if ulimit.Soft > ulimit.Hard {
+			return fmt.Errorf("Soft limit (%d) for ulimit '%s' is larger than the hard limit (%d).", ulimit.Soft, ulimit.Name, ulimit.Hard)
+		}
+	}
+
+	return nil
+}
+
+// Get the Docker resources for these limits.
+// It is safe to call on a nil receiver (no limits).
+func (this *ResourceLimits) ToDocker() container.Resources {
+	resources := container.Resources{}
+
+	if this == nil {
+		return resources
+	}
+
+	if this.MemoryMB > 0 {
+		resources.Memory = this.MemoryMB * BYTES_PER_MB
+		resources.MemorySwap = resources.Memory
+	}
+
+	if this.CPUs > 0 {
+		resources.NanoCPUs = int64(this.CPUs * 1e9)
+	}
+
+	if this.MaxPIDs > 0 {
+		resources.PidsLimit = &this.MaxPIDs
+	}
+
+	for _, ulimit := range this.Ulimits {
+		resources.Ulimits = append(resources.Ulimits, &container.Ulimit{
+			Name: ulimit.Name,
+			Soft: ulimit.Soft,
+			Hard: ulimit.Hard,
+		})
+	}
+
+	return resources
+}
+
+// Get the tmpfs mounts (path to options) for these limits (may be nil).
+func (this *ResourceLimits) GetTmpfs() map[string]string {
+	if (this == nil) || (this.TmpfsSizeMB <= 0) {
+		return nil
+	}
+
+	return map[string]string{
+		TMPFS_PATH: fmt.Sprintf("rw,exec,size=%dm", this.TmpfsSizeMB),
+	}
+}
+
+// Check if the contents of an output dir are within the output size limit.
+func (this *ResourceLimits) CheckOutputSize(dir string) (bool, error) {
+	if (this == nil) || (this.OutputSizeMB <= 0) {
+		return true, nil
+	}
+
+	var totalSize int64 = 0
+
+	err := filepath.WalkDir(dir, func(path string, dirent fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if dirent.IsDir() {
+			return nil
+		}
+
+		info, err := dirent.Info()
+		if err != nil {
+			return err
+		}
+
+		totalSize += info.Size()
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("Failed to compute the size of output dir '%s': '%w'.", dir, err)
+	}
+
+	return (totalSize <= (this.OutputSizeMB * BYTES_PER_MB)), nil
+}
+
+// Check the size of an output dir until the context is done or the output size limit is exceeded.
+// If the limit is exceeded, |onExceeded| is called (once) and watching stops.
+// Bind-mounted output dirs cannot be limited by Docker, so this keeps a running container from filling the host's disk.
+// Output written between checks may still go over the limit, so the output should be checked again after the run.
+func (this *ResourceLimits) WatchOutputSize(ctx context.Context, dir string, onExceeded func()) {
+	if (this == nil) || (this.OutputSizeMB <= 0) {
+		return
+	}
+
+	ticker := time.NewTicker(outputSizeCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		withinLimit, err := this.CheckOutputSize(dir)
+		if err != nil {
+			// Files may be changing while the dir is walked, just check again later.
+			continue
+		}
+
+		if !withinLimit {
+			onExceeded()
+			return
+		}
+	}
+}
+
+// Get a message suitable for users that describes a limit violation.
+// Containers can run out of memory without any explicit limits, so this is safe to call on a nil receiver.
+func (this *ResourceLimits) GetViolationMessage(violation LimitViolation) string {
+	switch violation {
+	case LimitViolationMemory:
+		if (this == nil) || (this.MemoryMB <= 0) {
+			return "Submission used too much memory and was killed. Check for memory leaks or large data structures."
+		}
+
+		return fmt.Sprintf("Submission used too much memory and was killed. The memory limit for this assignment is %d MB. Check for memory leaks or large data structures.", this.MemoryMB)
+	case LimitViolationOutputSize:
+		return fmt.Sprintf("Grader output is too large. The output size limit for this assignment is %d MB.", this.OutputSizeMB)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,186 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestResourceLimitsValidate(test *testing.T) {
+	testCases := []struct {
+		limits   *ResourceLimits
+		hasError bool
+	}{
+		{nil, false},
+		{&ResourceLimits{}, false},
+		{&ResourceLimits{MemoryMB: 256, CPUs: 0.5, MaxPIDs: 64, TmpfsSizeMB: 16, OutputSizeMB: 1}, false},
+		{&ResourceLimits{Ulimits: []*Ulimit{&Ulimit{Name: "nofile", Soft: 10, Hard: 20}}}, false},
+
+		{&ResourceLimits{MemoryMB: -1}, true},
+		{&ResourceLimits{CPUs: -0.5}, true},
+		{&ResourceLimits{MaxPIDs: -1}, true},
+		{&ResourceLimits{TmpfsSizeMB: -1}, true},
+		{&ResourceLimits{OutputSizeMB: -1}, true},
+		{&ResourceLimits{Ulimits: []*Ulimit{nil}}, true},
+		{&ResourceLimits{Ulimits: []*Ulimit{&Ulimit{Soft: 1, Hard: 1}}}, true},
+		{&ResourceLimits{Ulimits: []*Ulimit{&Ulimit{Name: "nofile", Soft: 20, Hard: 10}}}, true},
+	}
+
+	for i, testCase := range testCases {
+		err := testCase.limits.Validate()
+		if testCase.hasError && (err == nil) {
+			test.Errorf("Case %d: Did not get an expected error.", i)
+			continue
+		}
+
+		if !testCase.hasError && (err != nil) {
+			test.Errorf("Case %d: Got an unexpected error: '%v'.", i, err)
+			continue
+		}
+	}
+}
+
+func TestResourceLimitsToDocker(test *testing.T) {
+	var limits *ResourceLimits = nil
+
+	resources := limits.ToDocker()
+	if (resources.Memory != 0) || (resources.NanoCPUs != 0) || (resources.PidsLimit != nil) || (len(resources.Ulimits) != 0) {
+		test.Fatalf("Nil limits produced resources: '%s'.", util.MustToJSONIndent(resources))
+	}
+
+	if limits.GetTmpfs() != nil {
+		test.Fatalf("Nil limits produced a tmpfs.")
+	}
+
+	limits = &ResourceLimits{
+		MemoryMB:    2,
+		CPUs:        1.5,
+		MaxPIDs:     10,
+		Ulimits:     []*Ulimit{&Ulimit{Name: "nofile", Soft: 10, Hard: 20}},
+		TmpfsSizeMB: 4,
+	}
+
+	resources = limits.ToDocker()
+
+	if resources.Memory != (2 * BYTES_PER_MB) {
+		test.Fatalf("Unexpected memory. Expected: %d, Actual: %d.", 2*BYTES_PER_MB, resources.Memory)
+	}
+
+	if resources.MemorySwap != resources.Memory {
+		test.Fatalf("Unexpected memory swap. Expected: %d, Actual: %d.", resources.Memory, resources.MemorySwap)
+	}
+
+	if resources.NanoCPUs != 1500000000 {
+		test.Fatalf("Unexpected nano CPUs. Expected: %d, Actual: %d.", 1500000000, resources.NanoCPUs)
+	}
+
+	if (resources.PidsLimit == nil) || (*resources.PidsLimit != 10) {
+		test.Fatalf("Unexpected PIDs limit: '%v'.", resources.PidsLimit)
+	}
+
+	if (len(resources.Ulimits) != 1) || (resources.Ulimits[0].Name != "nofile") || (resources.Ulimits[0].Soft != 10) || (resources.Ulimits[0].Hard != 20) {
+		test.Fatalf("Unexpected ulimits: '%s'.", util.MustToJSONIndent(resources.Ulimits))
+	}
+
+	tmpfs := limits.GetTmpfs()
+	if tmpfs[TMPFS_PATH] != "rw,exec,size=4m" {
+		test.Fatalf("Unexpected tmpfs: '%s'.", util.MustToJSONIndent(tmpfs))
+	}
+}
+
+func TestResourceLimitsCheckOutputSize(test *testing.T) {
+	tempDir := util.MustMkDirTemp("test-docker-limits-")
+	defer util.RemoveDirent(tempDir)
+
+	err := os.WriteFile(filepath.Join(tempDir, "small.txt"), make([]byte, 512*1024), 0644)
+	if err != nil {
+		test.Fatalf("Failed to write file: '%v'.", err)
+	}
+
+	testCases := []struct {
+		limits   *ResourceLimits
+		size     int
+		expected bool
+	}{
+		{nil, 0, true},
+		{&ResourceLimits{}, 0, true},
+		{&ResourceLimits{OutputSizeMB: 1}, 0, true},
+		{&ResourceLimits{OutputSizeMB: 1}, 1024 * 1024, false},
+	}
+
+	for i, testCase := range testCases {
+		path := filepath.Join(tempDir, "nested", "extra.txt")
+
+		err = util.MkDir(filepath.Dir(path))
+		if err != nil {
+			test.Fatalf("Failed to make dir: '%v'.", err)
+		}
+
+		err = os.WriteFile(path, make([]byte, testCase.size), 0644)
+		if err != nil {
+			test.Fatalf("Failed to write file: '%v'.", err)
+		}
+
+		actual, err := testCase.limits.CheckOutputSize(tempDir)
+		if err != nil {
+			test.Errorf("Case %d: Failed to check output size: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.expected != actual {
+			test.Errorf("Case %d: Unexpected result. Expected: '%v', Actual: '%v'.", i, testCase.expected, actual)
+			continue
+		}
+	}
+}
+
+func TestResourceLimitsWatchOutputSize(test *testing.T) {
+	defer func(interval time.Duration) {
+		outputSizeCheckInterval = interval
+	}(outputSizeCheckInterval)
+	outputSizeCheckInterval = time.Millisecond
+
+	testCases := []struct {
+		limits   *ResourceLimits
+		size     int
+		expected bool
+	}{
+		{nil, 2 * 1024 * 1024, false},
+		{&ResourceLimits{}, 2 * 1024 * 1024, false},
+		{&ResourceLimits{OutputSizeMB: 1}, 512 * 1024, false},
+		{&ResourceLimits{OutputSizeMB: 1}, 2 * 1024 * 1024, true},
+	}
+
+	for i, testCase := range testCases {
+		tempDir := util.MustMkDirTemp("test-docker-limits-watch-")
+		defer util.RemoveDirent(tempDir)
+
+		err := os.WriteFile(filepath.Join(tempDir, "out.txt"), make([]byte, testCase.size), 0644)
+		if err != nil {
+			test.Fatalf("Case %d: Failed to write file: '%v'.", i, err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+
+		exceededCount := 0
+		testCase.limits.WatchOutputSize(ctx, tempDir, func() {
+			exceededCount++
+		})
+
+		cancel()
+
+		if testCase.expected != (exceededCount > 0) {
+			test.Errorf("Case %d: Unexpected result. Expected: '%v', Actual: '%v'.", i, testCase.expected, (exceededCount > 0))
+			continue
+		}
+
+		if exceededCount > 1 {
+			test.Errorf("Case %d: Exceeded callback called more than once: %d.", i, exceededCount)
+			continue
+		}
+	}
+}
@@ -27,6 +27,8 @@ type ImageInfo struct {
 
 	MaxRuntimeSecs int `json:"max-runtime-secs,omitempty"`
 
+	ResourceLimits *ResourceLimits `json:"resource-limits,omitempty"`
+
 	// Fields that are not part of the JSON and are set after deserialization.
 
 	Name string `json:"-"`
@@ -148,5 +150,10 @@ func (this *ImageInfo) Validate() error {
 		return fmt.Errorf("Max runtime seconds must be non-negative, found: %d.", this.MaxRuntimeSecs)
 	}
 
+	err = this.ResourceLimits.Validate()
+	if err != nil {
+		return fmt.Errorf("Failed to validate resource limits: '%w'.", err)
+	}
+
 	return nil
 }
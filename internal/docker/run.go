@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -18,6 +19,7 @@ import (
 
 	"github.com/edulinq/autograder/internal/config"
 	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/tracing"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -37,8 +39,11 @@ type MountInfo struct {
 }
 
 // Run a grading container.
-// Returns: (stdout, stderr, timeout?, canceled?, error)
-func RunGradingContainer(ctx context.Context, logId log.Loggable, imageName string, inputDir string, outputDir string, baseID string, maxRuntimeSecs int) (string, string, bool, bool, error) {
+// Any resource limits (which may be nil) will be enforced.
+// The returned resource usage (which will be nil if the container could not be run)
+// will note the first limit that the container went past (if any).
+// Returns: (stdout, stderr, timeout?, canceled?, resource usage, error)
+func RunGradingContainer(ctx context.Context, logId log.Loggable, imageName string, inputDir string, outputDir string, baseID string, maxRuntimeSecs int, limits *ResourceLimits) (string, string, bool, bool, *ResourceUsage, error) {
 	mounts := []MountInfo{
 		MountInfo{
 			Source:   util.ShouldAbs(inputDir),
@@ -52,21 +57,54 @@ func RunGradingContainer(ctx context.Context, logId log.Loggable, imageName stri
 		},
 	}
 
-	return RunContainer(ctx, logId, imageName, mounts, nil, baseID, maxRuntimeSecs)
+	stdout, stderr, timeout, canceled, usage, err := runContainer(ctx, logId, imageName, mounts, nil, baseID, maxRuntimeSecs, limits, outputDir)
+	if (err != nil) || (usage == nil) {
+		return stdout, stderr, timeout, canceled, usage, err
+	}
+
+	// The container may have been killed for going past the output size limit while it was running.
+	if usage.LimitViolation != LimitViolationNone {
+		return stdout, stderr, timeout, canceled, usage, nil
+	}
+
+	if usage.OOMKilled {
+		usage.LimitViolation = LimitViolationMemory
+		return stdout, stderr, timeout, canceled, usage, nil
+	}
+
+	// Output written after the last check during the run.
+	withinLimit, err := limits.CheckOutputSize(outputDir)
+	if err != nil {
+		return stdout, stderr, timeout, canceled, usage, err
+	}
+
+	if !withinLimit {
+		usage.LimitViolation = LimitViolationOutputSize
+	}
+
+	return stdout, stderr, timeout, canceled, usage, nil
 }
 
-// Run a container.
+// Run a container (without any resource limits).
 // Returns: (stdout, stderr, timeout?, canceled?, error)
 func RunContainer(ctx context.Context, logId log.Loggable, imageName string, mounts []MountInfo, cmd []string, baseID string, maxRuntimeSecs int) (string, string, bool, bool, error) {
+	stdout, stderr, timeout, canceled, _, err := runContainer(ctx, logId, imageName, mounts, cmd, baseID, maxRuntimeSecs, nil, "")
+	return stdout, stderr, timeout, canceled, err
+}
+
+// If |outputDir| is not empty, it will be checked against the output size limit while the container runs.
+// Returns: (stdout, stderr, timeout?, canceled?, resource usage, error)
+func runContainer(ctx context.Context, logId log.Loggable, imageName string, mounts []MountInfo, cmd []string, baseID string, maxRuntimeSecs int, limits *ResourceLimits, outputDir string) (string, string, bool, bool, *ResourceUsage, error) {
 	var stdout string
 	var stderr string
 	var tempTimeout bool
 	var timeout bool
 	var canceled bool
+	var usage *ResourceUsage
 	var err error
 
 	runFunc := func(softTimeoutCtx context.Context) {
-		stdout, stderr, tempTimeout, err = runContainerInternal(softTimeoutCtx, logId, imageName, mounts, cmd, baseID)
+		stdout, stderr, tempTimeout, usage, err = runContainerInternal(softTimeoutCtx, logId, imageName, mounts, cmd, baseID, limits, outputDir)
 		timeout = timeout || tempTimeout
 	}
 
@@ -87,7 +125,7 @@ func RunContainer(ctx context.Context, logId log.Loggable, imageName string, mou
 		err = nil
 	}
 
-	return stdout, stderr, timeout, canceled, err
+	return stdout, stderr, timeout, canceled, usage, err
 }
 
 // An inner run container helper.
@@ -95,14 +133,14 @@ func RunContainer(ctx context.Context, logId log.Loggable, imageName string, mou
 // (we can't fully trust Docker to timeout properly).
 // This function does not try to enforce any timeouts (aside from passing along the context), that is left to callers.
 // If a timeout is detected, it will be returned (but it is only one of many ways a timeout could happen).
-// Returns: (stdout, stderr, timeout (only one of many types), error)
-func runContainerInternal(ctx context.Context, logId log.Loggable, imageName string, mounts []MountInfo, cmd []string, baseID string) (string, string, bool, error) {
+// Returns: (stdout, stderr, timeout (only one of many types), resource usage, error)
+func runContainerInternal(ctx context.Context, logId log.Loggable, imageName string, mounts []MountInfo, cmd []string, baseID string, limits *ResourceLimits, outputDir string) (string, string, bool, *ResourceUsage, error) {
 	// Get a docker client.
 	// Note that cleaning this up needs to wait until after we are sure the container is dead.
 	// This means we won't be defering the close right away (see cleanupRun()).
 	docker, err := getDockerClient()
 	if err != nil {
-		return "", "", false, err
+		return "", "", false, nil, err
 	}
 
 	name := cleanContainerName(fmt.Sprintf("%s-%s", baseID, util.UUID()))
@@ -113,6 +151,7 @@ func runContainerInternal(ctx context.Context, logId log.Loggable, imageName str
 	}
 
 	log.Debug("Creating container.", log.NewAttr("name", name))
+	_, createSpan := tracing.StartSpan(ctx, "docker.container.create", tracing.NewAttr("container", name), tracing.NewAttr("image", imageName))
 	containerInstance, err := docker.ContainerCreate(
 		ctx,
 		&container.Config{
@@ -121,7 +160,9 @@ func runContainerInternal(ctx context.Context, logId log.Loggable, imageName str
 			Cmd:             cmd,
 		},
 		&container.HostConfig{
-			Mounts: dockerMounts,
+			Mounts:    dockerMounts,
+			Resources: limits.ToDocker(),
+			Tmpfs:     limits.GetTmpfs(),
 			LogConfig: container.LogConfig{
 				// Don't store any logs, we will copy stdout/stderr directly.
 				Type: "none",
@@ -131,16 +172,21 @@ func runContainerInternal(ctx context.Context, logId log.Loggable, imageName str
 		nil,
 		name)
 
+	tracing.EndSpan(createSpan, err)
+
 	if err != nil {
 		docker.Close()
-		return "", "", false, fmt.Errorf("Failed to create container '%s': '%w'.", name, err)
+		return "", "", false, nil, fmt.Errorf("Failed to create container '%s': '%w'.", name, err)
 	}
 
 	// Now that we have the container, we can schedule cleanup in the background.
 	defer func() {
-		go cleanupRun(docker, name, containerInstance.ID)
+		go cleanupRun(ctx, docker, name, containerInstance.ID)
 	}()
 
+	_, runSpan := tracing.StartSpan(ctx, "docker.container.run", tracing.NewAttr("container", name))
+	defer runSpan.End()
+
 	// Attach to the container so we can get stdout and stderr.
 	log.Trace("Attaching container.", log.NewAttr("name", name))
 	connection, err := docker.ContainerAttach(ctx, containerInstance.ID, container.AttachOptions{
@@ -149,7 +195,7 @@ func runContainerInternal(ctx context.Context, logId log.Loggable, imageName str
 		Stderr: true,
 	})
 	if err != nil {
-		return "", "", false, fmt.Errorf("Failed to attach to container '%s' (%s): '%w'.", name, containerInstance.ID, err)
+		return "", "", false, nil, fmt.Errorf("Failed to attach to container '%s' (%s): '%w'.", name, containerInstance.ID, err)
 	}
 	defer connection.Close()
 
@@ -162,7 +208,37 @@ func runContainerInternal(ctx context.Context, logId log.Loggable, imageName str
 	log.Trace("Starting container.", log.NewAttr("name", name))
 	err = docker.ContainerStart(ctx, containerInstance.ID, container.StartOptions{})
 	if err != nil {
-		return "", "", false, fmt.Errorf("Failed to start container '%s' (%s): '%w'.", name, containerInstance.ID, err)
+		return "", "", false, nil, fmt.Errorf("Failed to start container '%s' (%s): '%w'.", name, containerInstance.ID, err)
+	}
+
+	// Sample resource usage while the container runs.
+	// The run context may finish before the container does, so use a separate context.
+	usage := &ResourceUsage{}
+	statsCtx, statsCancel := context.WithCancel(context.Background())
+	defer statsCancel()
+	statsWaitGroup := &sync.WaitGroup{}
+	statsWaitGroup.Add(1)
+	go func() {
+		defer statsWaitGroup.Done()
+		collectContainerStats(statsCtx, docker, containerInstance.ID, usage)
+	}()
+
+	// Kill the container as soon as its output goes past the limit.
+	var outputExceeded atomic.Bool
+	if outputDir != "" {
+		statsWaitGroup.Add(1)
+		go func() {
+			defer statsWaitGroup.Done()
+			limits.WatchOutputSize(statsCtx, outputDir, func() {
+				outputExceeded.Store(true)
+
+				log.Debug("Killing container for going past the output size limit.", logId, log.NewAttr("name", name))
+				err := docker.ContainerKill(context.Background(), containerInstance.ID, "KILL")
+				if err != nil {
+					log.Warn("Failed to kill container that went past the output size limit.", err, logId, log.NewAttr("container-name", name))
+				}
+			})
+		}()
 	}
 
 	// Wait for the container to finish.
@@ -178,7 +254,7 @@ func runContainerInternal(ctx context.Context, logId log.Loggable, imageName str
 				break
 			}
 
-			return "", "", false, fmt.Errorf("Got an error when running container '%s' (%s): '%w'.", name, containerInstance.ID, err)
+			return "", "", false, nil, fmt.Errorf("Got an error when running container '%s' (%s): '%w'.", name, containerInstance.ID, err)
 		}
 	case <-statusChan:
 		// Waiting is complete.
@@ -192,6 +268,21 @@ func runContainerInternal(ctx context.Context, logId log.Loggable, imageName str
 
 	log.Debug("Done with container.", log.NewAttr("name", name))
 
+	statsCancel()
+	statsWaitGroup.Wait()
+
+	if outputExceeded.Load() {
+		usage.LimitViolation = LimitViolationOutputSize
+	}
+
+	// The run context may be done, so use a fresh one.
+	containerInfo, err := docker.ContainerInspect(context.Background(), containerInstance.ID)
+	if err != nil {
+		log.Warn("Failed to inspect container.", err, logId, log.NewAttr("container-name", name))
+	} else {
+		setInspectUsage(containerInfo, usage)
+	}
+
 	log.Trace("Container output.",
 		logId,
 		log.NewAttr("container-name", name),
@@ -201,10 +292,11 @@ func runContainerInternal(ctx context.Context, logId log.Loggable, imageName str
 		log.NewAttr("timeout", errors.Is(ctx.Err(), context.DeadlineExceeded)),
 		log.NewAttr("canceled", errors.Is(ctx.Err(), context.Canceled)),
 		log.NewAttr("output-truncated", output.Truncated),
+		log.NewAttr("resource-usage", usage),
 		output.Err,
 	)
 
-	return output.Stdout, output.Stderr, errors.Is(ctx.Err(), context.DeadlineExceeded), nil
+	return output.Stdout, output.Stderr, errors.Is(ctx.Err(), context.DeadlineExceeded), usage, nil
 }
 
 func cleanContainerName(text string) string {
@@ -320,7 +412,10 @@ func killContainer(docker *client.Client, name string, id string) {
 
 // Cleanup any leftovers from the running the container.
 // This should generally be called in another go routine to prevent blocking.
-func cleanupRun(docker *client.Client, containerName string, containerID string) {
+func cleanupRun(ctx context.Context, docker *client.Client, containerName string, containerID string) {
+	_, span := tracing.StartSpan(ctx, "docker.container.cleanup", tracing.NewAttr("container", containerName))
+	defer span.End()
+
 	killContainer(docker, containerName, containerID)
 
 	err := docker.Close()
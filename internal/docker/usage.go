@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+
+	"github.com/edulinq/autograder/internal/log"
+)
+
+// The resources used by a single grading run.
+// Memory and CPU usage are sampled while the container is running,
+// so very short runs may not report any usage.
+type ResourceUsage struct {
+	ExitCode        int    `json:"exit-code"`
+	OOMKilled       bool   `json:"oom-killed"`
+	WallTimeMSecs   int64  `json:"wall-time-msecs"`
+	PeakMemoryBytes uint64 `json:"peak-memory-bytes"`
+	CPUTimeMSecs    int64  `json:"cpu-time-msecs"`
+
+	// The first resource limit that the run went past (if any).
+	LimitViolation LimitViolation `json:"limit-violation,omitempty"`
+}
+
+// Sample the stats of a running container until it stops (or the context is done).
+// Peak memory and total CPU time will be written to the given usage.
+func collectContainerStats(ctx context.Context, docker *client.Client, containerID string, usage *ResourceUsage) {
+	response, err := docker.ContainerStats(ctx, containerID, true)
+	if err != nil {
+		log.Warn("Failed to get container stats.", err, log.NewAttr("container-id", containerID))
+		return
+	}
+	defer response.Body.Close()
+
+	decoder := json.NewDecoder(response.Body)
+
+	for {
+		var stats container.StatsResponse
+
+		err = decoder.Decode(&stats)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && (ctx.Err() == nil) {
+				log.Warn("Failed to decode container stats.", err, log.NewAttr("container-id", containerID))
+			}
+
+			return
+		}
+
+		// Cgroups v2 does not provide a max usage, so also track the max of the sampled usages.
+		peakMemory := max(stats.MemoryStats.MaxUsage, stats.MemoryStats.Usage)
+		if peakMemory > usage.PeakMemoryBytes {
+			usage.PeakMemoryBytes = peakMemory
+		}
+
+		cpuTimeMSecs := int64(stats.CPUStats.CPUUsage.TotalUsage / uint64(time.Millisecond))
+		if cpuTimeMSecs > usage.CPUTimeMSecs {
+			usage.CPUTimeMSecs = cpuTimeMSecs
+		}
+	}
+}
+
+// Fill in the usage information that is available after a container has stopped.
+func setInspectUsage(containerInfo types.ContainerJSON, usage *ResourceUsage) {
+	if (containerInfo.ContainerJSONBase == nil) || (containerInfo.State == nil) {
+		return
+	}
+
+	usage.ExitCode = containerInfo.State.ExitCode
+	usage.OOMKilled = containerInfo.State.OOMKilled
+
+	startTime, err := time.Parse(time.RFC3339Nano, containerInfo.State.StartedAt)
+	if err != nil {
+		return
+	}
+
+	endTime, err := time.Parse(time.RFC3339Nano, containerInfo.State.FinishedAt)
+	if err != nil {
+		return
+	}
+
+	if endTime.After(startTime) {
+		usage.WallTimeMSecs = endTime.Sub(startTime).Milliseconds()
+	}
+}
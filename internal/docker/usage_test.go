@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestSetInspectUsage(test *testing.T) {
+	testCases := []struct {
+		info     types.ContainerJSON
+		expected ResourceUsage
+	}{
+		{
+			types.ContainerJSON{},
+			ResourceUsage{},
+		},
+		{
+			types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{}},
+			ResourceUsage{},
+		},
+		{
+			types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+				State: &types.ContainerState{
+					ExitCode:   137,
+					OOMKilled:  true,
+					StartedAt:  "2024-01-01T00:00:00.000000000Z",
+					FinishedAt: "2024-01-01T00:00:01.500000000Z",
+				},
+			}},
+			ResourceUsage{ExitCode: 137, OOMKilled: true, WallTimeMSecs: 1500},
+		},
+		{
+			types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+				State: &types.ContainerState{
+					ExitCode:   1,
+					StartedAt:  "2024-01-01T00:00:00Z",
+					FinishedAt: "0001-01-01T00:00:00Z",
+				},
+			}},
+			ResourceUsage{ExitCode: 1},
+		},
+		{
+			types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+				State: &types.ContainerState{
+					ExitCode:  2,
+					StartedAt: "ZZZ",
+				},
+			}},
+			ResourceUsage{ExitCode: 2},
+		},
+	}
+
+	for i, testCase := range testCases {
+		var actual ResourceUsage
+		setInspectUsage(testCase.info, &actual)
+
+		if testCase.expected != actual {
+			test.Errorf("Case %d: Unexpected usage. Expected: '%s', Actual: '%s'.",
+				i, util.MustToJSONIndent(testCase.expected), util.MustToJSONIndent(actual))
+			continue
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package grader
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/edulinq/autograder/internal/docker"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/tracing"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const GRADING_CACHE_DIRNAME = "grading"
+
+// The output of a grader that can be reused for identical inputs.
+// Only the grader output is cached, all the autograder fields (IDs, user, times, etc) are filled in for each submission.
+type cachedGrading struct {
+	GradingInfo     *model.GradingInfo `json:"grading-info"`
+	OutputFilesGZip map[string][]byte  `json:"output-files-gzip"`
+	Stdout          string             `json:"stdout"`
+	Stderr          string             `json:"stderr"`
+}
+
+// Get the key for a cached grading.
+// The key is built from the grader (image digest) and the submission input files.
+// An empty key (with no error) indicates that the cache should not be used for this submission.
+func getGradingCacheKey(assignment *model.Assignment, inputFileContents map[string][]byte, options GradeOptions) (string, error) {
+	if !assignment.Deterministic || options.NoCache {
+		return "", nil
+	}
+
+	digest, err := getGraderDigest(assignment, options)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get grader digest: '%w'.", err)
+	}
+
+	if digest == "" {
+		return "", nil
+	}
+
+	// JSON objects are marshaled with sorted keys, so this is stable.
+	inputHash, err := util.Sha256HashFromJSONObject(inputFileContents)
+	if err != nil {
+		return "", fmt.Errorf("Failed to hash input files: '%w'.", err)
+	}
+
+	return util.Sha256HexFromString(digest + "::" + inputHash), nil
+}
+
+// Get a value that will change whenever the grader for an assignment changes.
+// When grading with docker this is the ID (digest) of the assignment's image,
+// otherwise it is a hash of the image info and the assignment's source files.
+// An empty digest (with no error) means that the grader could not be identified.
+func getGraderDigest(assignment *model.Assignment, options GradeOptions) (string, error) {
+	if !options.NoDocker {
+		summary, err := docker.GetImageSummary(assignment.GetImageName())
+		if err != nil {
+			return "", err
+		}
+
+		if summary == nil {
+			return "", nil
+		}
+
+		return summary.ID, nil
+	}
+
+	sourceFiles, err := util.GzipDirectoryToBytes(assignment.GetSourceDir())
+	if err != nil {
+		return "", fmt.Errorf("Failed to read assignment source files: '%w'.", err)
+	}
+
+	return util.Sha256HashFromJSONObject([]any{assignment.GetImageInfo(), sourceFiles})
+}
+
+func getGradingCachePath(assignment *model.Assignment, key string) string {
+	return filepath.Join(assignment.GetCacheDir(), GRADING_CACHE_DIRNAME, key+".json")
+}
+
+// Fetch a cached grading, returns nil if there is no cached grading.
+func fetchCachedGrading(assignment *model.Assignment, key string) (*cachedGrading, error) {
+	path := getGradingCachePath(assignment, key)
+	if !util.PathExists(path) {
+		return nil, nil
+	}
+
+	var cached cachedGrading
+	err := util.JSONFromFile(path, &cached)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read cached grading '%s': '%w'.", path, err)
+	}
+
+	if cached.GradingInfo == nil {
+		return nil, nil
+	}
+
+	return &cached, nil
+}
+
+// Store the output of a successful grader run.
+// Failing to store output is not fatal to grading, so errors are only logged.
+func storeCachedGrading(ctx context.Context, assignment *model.Assignment, key string, cached *cachedGrading) {
+	path := getGradingCachePath(assignment, key)
+
+	err := util.MkDir(filepath.Dir(path))
+	if err == nil {
+		err = util.ToJSONFile(cached, path)
+	}
+
+	if err != nil {
+		log.Warn("Failed to store cached grading.", err, assignment, log.NewAttr("path", path), tracing.NewLogAttr(ctx))
+	}
+}
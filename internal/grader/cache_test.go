@@ -0,0 +1,101 @@
+package grader
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestGradeCache(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	submissionPath := filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELPATH)
+
+	cacheDir := filepath.Join(assignment.GetCacheDir(), GRADING_CACHE_DIRNAME)
+	util.RemoveDirent(cacheDir)
+	defer util.RemoveDirent(cacheDir)
+
+	testCases := []struct {
+		deterministic bool
+		noCache       bool
+		fromCache     bool
+	}{
+		// Assignment is not deterministic, nothing is cached.
+		{false, false, false},
+		{false, false, false},
+
+		// First run fills the cache.
+		{true, false, false},
+		{true, false, true},
+		{true, false, true},
+
+		// Bypass the cache.
+		{true, true, false},
+
+		// Cache is still available.
+		{true, false, true},
+	}
+
+	var expectedScore float64 = -1
+
+	for i, testCase := range testCases {
+		assignment.Deterministic = testCase.deterministic
+
+		options := GetDefaultGradeOptions()
+		options.CheckRejection = false
+		options.NoCache = testCase.noCache
+
+		result, reject, softError, err := Grade(context.Background(), assignment, submissionPath, BASE_TEST_USER, TEST_MESSAGE, options)
+		if err != nil {
+			test.Errorf("Case %d: Failed to grade: '%v'.", i, err)
+			continue
+		}
+
+		if reject != nil {
+			test.Errorf("Case %d: Submission was rejected: '%s'.", i, reject.String())
+			continue
+		}
+
+		if softError != "" {
+			test.Errorf("Case %d: Got a soft error: '%s'.", i, softError)
+			continue
+		}
+
+		if testCase.fromCache != result.Info.FromCache {
+			test.Errorf("Case %d: Unexpected cache use. Expected: '%v', Actual: '%v'.", i, testCase.fromCache, result.Info.FromCache)
+			continue
+		}
+
+		// A cached result should not have run a grader.
+		if testCase.fromCache != (result.Info.ResourceUsage == nil) {
+			test.Errorf("Case %d: Unexpected resource usage: '%s'.", i, util.MustToJSONIndent(result.Info.ResourceUsage))
+			continue
+		}
+
+		if expectedScore < 0 {
+			expectedScore = result.Info.Score
+		}
+
+		if expectedScore != result.Info.Score {
+			test.Errorf("Case %d: Unexpected score. Expected: '%f', Actual: '%f'.", i, expectedScore, result.Info.Score)
+			continue
+		}
+
+		// Cached results still get a new submission.
+		submission, err := db.GetSubmissionResult(assignment, BASE_TEST_USER, result.Info.ShortID)
+		if err != nil {
+			test.Errorf("Case %d: Failed to get submission: '%v'.", i, err)
+			continue
+		}
+
+		if submission == nil {
+			test.Errorf("Case %d: Could not find submission.", i)
+			continue
+		}
+	}
+}
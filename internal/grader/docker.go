@@ -9,6 +9,7 @@ import (
 	"github.com/edulinq/autograder/internal/docker"
 	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/tracing"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -18,57 +19,69 @@ import (
 //   - output -- Passed in directory that will be mounted at DOCKER_OUTPUT_DIR.
 //   - work -- Should already be created inside the docker image, will only exist within the container.
 //
-// Returns: (result, file contents, stdout, stderr, failure message (soft failure), error (hard failure)).
-func runDockerGrader(ctx context.Context, assignment *model.Assignment, submissionPath string, options GradeOptions, fullSubmissionID string) (*model.GradingInfo, map[string][]byte, string, string, string, error) {
+// Returns: (result, file contents, stdout, stderr, resource usage, failure message (soft failure), error (hard failure)).
+func runDockerGrader(ctx context.Context, assignment *model.Assignment, submissionPath string, options GradeOptions, fullSubmissionID string) (*model.GradingInfo, map[string][]byte, string, string, *docker.ResourceUsage, string, error) {
 	tempDir, inputDir, outputDir, _, err := common.PrepTempGradingDir("docker")
 	if err != nil {
-		return nil, nil, "", "", "", err
+		return nil, nil, "", "", nil, "", err
 	}
 
 	if !options.LeaveTempDir {
 		defer util.RemoveDirent(tempDir)
 	} else {
-		log.Debug("Leaving behind temp grading dir.", assignment, log.NewAttr("path", tempDir))
+		log.Debug("Leaving behind temp grading dir.", assignment, log.NewAttr("path", tempDir), tracing.NewLogAttr(ctx))
 	}
 
 	// Copy over submission files to the temp input dir.
 	err = util.CopyDirentFull(submissionPath, inputDir, true)
 	if err != nil {
-		return nil, nil, "", "", "", fmt.Errorf("Failed to copy over submission/input contents: '%w'.", err)
+		return nil, nil, "", "", nil, "", fmt.Errorf("Failed to copy over submission/input contents: '%w'.", err)
 	}
 
-	stdout, stderr, timeout, canceled, err := docker.RunGradingContainer(ctx, assignment, assignment.GetImageName(), inputDir, outputDir, fullSubmissionID, assignment.MaxRuntimeSecs)
+	limits := assignment.GetResourceLimits()
+	maxRuntimeSecs := getMaxRuntimeSecs(assignment, options)
+
+	stdout, stderr, timeout, canceled, usage, err := docker.RunGradingContainer(ctx, assignment, assignment.GetImageName(), inputDir, outputDir, fullSubmissionID, maxRuntimeSecs, limits)
 	if err != nil {
-		return nil, nil, stdout, stderr, "", err
+		return nil, nil, stdout, stderr, usage, "", err
 	}
 
 	if timeout {
-		return nil, nil, stdout, stderr, getTimeoutMessage(assignment), nil
+		return nil, nil, stdout, stderr, usage, getTimeoutMessage(maxRuntimeSecs), nil
 	}
 
 	if canceled {
-		return nil, nil, stdout, stderr, getCanceledMessage(assignment), nil
+		return nil, nil, stdout, stderr, usage, getCanceledMessage(assignment), nil
+	}
+
+	if (usage != nil) && (usage.LimitViolation != docker.LimitViolationNone) {
+		return nil, nil, stdout, stderr, usage, limits.GetViolationMessage(usage.LimitViolation), nil
 	}
 
 	resultPath := filepath.Join(outputDir, common.GRADER_OUTPUT_RESULT_FILENAME)
 	if !util.PathExists(resultPath) {
 		log.Warn("Cannot find output file after the grading container was run.",
-			log.NewAttr("path", resultPath), log.NewAttr("image", assignment.GetImageName()))
+			log.NewAttr("path", resultPath), log.NewAttr("image", assignment.GetImageName()), tracing.NewLogAttr(ctx))
 
 		message := fmt.Sprintf("Cannot find output/result of grading. It is likely that the grader crashed.")
-		return nil, nil, stdout, stderr, message, nil
+		return nil, nil, stdout, stderr, usage, message, nil
 	}
 
 	var gradingInfo model.GradingInfo
 	err = util.JSONFromFile(resultPath, &gradingInfo)
 	if err != nil {
-		return nil, nil, stdout, stderr, "", err
+		return nil, nil, stdout, stderr, usage, "", err
+	}
+
+	err = gradingInfo.PrepTestCases()
+	if err != nil {
+		return nil, nil, stdout, stderr, usage, "", fmt.Errorf("Grader output has invalid test cases: '%w'.", err)
 	}
 
 	fileContents, err := util.GzipDirectoryToBytes(outputDir)
 	if err != nil {
-		return nil, nil, stdout, stderr, "", fmt.Errorf("Failed to copy grading output '%s': '%w'.", outputDir, err)
+		return nil, nil, stdout, stderr, usage, "", fmt.Errorf("Failed to copy grading output '%s': '%w'.", outputDir, err)
 	}
 
-	return &gradingInfo, fileContents, stdout, stderr, "", nil
+	return &gradingInfo, fileContents, stdout, stderr, usage, "", nil
 }
@@ -10,9 +10,11 @@ import (
 	"github.com/edulinq/autograder/internal/db"
 	"github.com/edulinq/autograder/internal/docker"
 	"github.com/edulinq/autograder/internal/lockmanager"
+	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/model"
 	"github.com/edulinq/autograder/internal/stats"
 	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/tracing"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -27,6 +29,19 @@ type GradeOptions struct {
 	AllowLate      bool
 	ProxyUser      string
 	ProxyTime      *timestamp.Timestamp
+
+	// Always run the grader, even if there is cached output for this submission.
+	NoCache bool
+
+	// Only grade for the submitting user, even if they are in a group.
+	NoGroup bool
+
+	// The git commit the submission was taken from (if any).
+	CommitSHA string
+
+	// The max runtime (in seconds) for this grading.
+	// Zero means the assignment's max runtime will be used (adjusted by any extension the user has).
+	MaxRuntimeSecs int
 }
 
 func GetDefaultGradeOptions() GradeOptions {
@@ -37,6 +52,10 @@ func GetDefaultGradeOptions() GradeOptions {
 		AllowLate:      false,
 		ProxyUser:      "",
 		ProxyTime:      nil,
+		NoCache:        false,
+		NoGroup:        false,
+		CommitSHA:      "",
+		MaxRuntimeSecs: 0,
 	}
 }
 
@@ -50,9 +69,36 @@ func GradeDefault(assignment *model.Assignment, submissionPath string, user stri
 // Return (result, reject, softGradingError, error).
 // Full success is only when ((reject == nil) && (softGradingError == "") && (error == nil)).
 func Grade(ctx context.Context, assignment *model.Assignment, submissionPath string, user string, message string, options GradeOptions) (
+	result *model.GradingResult, reject RejectReason, softGradingError string, err error) {
+	ctx, span := tracing.StartSpan(ctx, "grader.grade",
+		tracing.NewAttr("course", assignment.GetCourse().GetID()), tracing.NewAttr("assignment", assignment.GetID()), tracing.NewAttr("user", user))
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	return grade(ctx, assignment, submissionPath, user, message, options)
+}
+
+func grade(ctx context.Context, assignment *model.Assignment, submissionPath string, user string, message string, options GradeOptions) (
 	*model.GradingResult, RejectReason, string, error) {
 	gradingKey := fmt.Sprintf("%s::%s::%s", assignment.GetCourse().GetID(), assignment.GetID(), user)
 
+	var group *model.Group = nil
+	var groupMembers []string = nil
+	var err error
+
+	if !options.NoGroup {
+		group, groupMembers, err = getGroupMembers(assignment, user)
+		if err != nil {
+			return nil, nil, "", err
+		}
+	}
+
+	// Members of a group share submissions, so only one member may be grading at a time.
+	if group != nil {
+		gradingKey = fmt.Sprintf("%s::%s::group::%s", assignment.GetCourse().GetID(), assignment.GetID(), group.ID)
+	}
+
 	// Get the grading start time right before we acquire the user's lock.
 	startTimestamp := timestamp.Now()
 
@@ -63,7 +109,9 @@ func Grade(ctx context.Context, assignment *model.Assignment, submissionPath str
 	// Check for rejection inside the lock to prevent TOCTOU race conditions
 	// where concurrent submissions could both pass the limit check before either acquires the lock.
 	if options.CheckRejection {
+		_, span := tracing.StartSpan(ctx, "grader.reject")
 		reject, err := checkForRejection(assignment, submissionPath, user, message, options.AllowLate)
+		tracing.EndSpan(span, err)
 		if err != nil {
 			return nil, nil, "", fmt.Errorf("Failed to check for rejection: '%w'.", err)
 		}
@@ -73,7 +121,16 @@ func Grade(ctx context.Context, assignment *model.Assignment, submissionPath str
 		}
 	}
 
-	submissionID, inputFileContents, err := prepForGrading(assignment, submissionPath, user)
+	if options.MaxRuntimeSecs <= 0 {
+		extension, err := db.GetExtension(assignment, user)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("Failed to get extension: '%w'.", err)
+		}
+
+		options.MaxRuntimeSecs = extension.GetMaxRuntimeSecs(assignment.MaxRuntimeSecs, config.GRADING_RUNTIME_MAX_SECS.Get())
+	}
+
+	submissionID, inputFileContents, err := prepForGrading(ctx, assignment, submissionPath, user)
 	if err != nil {
 		return nil, nil, "", fmt.Errorf("Failed to prep for grading: '%w'.", err)
 	}
@@ -83,13 +140,73 @@ func Grade(ctx context.Context, assignment *model.Assignment, submissionPath str
 
 	fullSubmissionID := common.CreateFullSubmissionID(assignment.GetCourse().GetID(), assignment.GetID(), user, submissionID)
 
-	gradingInfo, outputFileContents, stdout, stderr, softGradingError, err := runGrader(ctx, assignment, submissionPath, options, fullSubmissionID)
+	var gradingInfo *model.GradingInfo
+	var outputFileContents map[string][]byte
+	var stdout string
+	var stderr string
+	var usage *docker.ResourceUsage
+	var softGradingError string
+
+	// Deterministic assignments may be able to reuse the output of an identical earlier run.
+	// Problems with the cache are not fatal, grading will just continue without it.
+	cacheKey, err := getGradingCacheKey(assignment, inputFileContents, options)
+	if err != nil {
+		log.Warn("Failed to get grading cache key.", err, assignment, log.NewUserAttr(user), tracing.NewLogAttr(ctx))
+		cacheKey = ""
+	}
+
+	var cached *cachedGrading = nil
+	if cacheKey != "" {
+		cached, err = fetchCachedGrading(assignment, cacheKey)
+		if err != nil {
+			log.Warn("Failed to fetch cached grading.", err, assignment, log.NewUserAttr(user), tracing.NewLogAttr(ctx))
+			cached = nil
+		}
+	}
+
+	if cached != nil {
+		gradingInfo = cached.GradingInfo
+		gradingInfo.FromCache = true
+		outputFileContents = cached.OutputFilesGZip
+		stdout = cached.Stdout
+		stderr = cached.Stderr
+	} else {
+		// Wait for the server to have room to run another grader.
+		var releaseSlot func()
+		releaseSlot, err = acquireGradingSlot(ctx, assignment, user)
+		if err != nil {
+			if ctx.Err() != nil {
+				return &gradingResult, nil, getCanceledMessage(assignment), nil
+			}
+
+			return nil, nil, "", fmt.Errorf("Failed to get a grading slot: '%w'.", err)
+		}
+
+		runCtx, span := tracing.StartSpan(ctx, "grader.run", tracing.NewAttr("docker", !options.NoDocker))
+		gradingInfo, outputFileContents, stdout, stderr, usage, softGradingError, err = runGrader(runCtx, assignment, submissionPath, options, fullSubmissionID)
+		tracing.EndSpan(span, err)
+
+		releaseSlot()
+
+		if (cacheKey != "") && (err == nil) && (softGradingError == "") {
+			storeCachedGrading(ctx, assignment, cacheKey, &cachedGrading{
+				GradingInfo:     gradingInfo,
+				OutputFilesGZip: outputFileContents,
+				Stdout:          stdout,
+				Stderr:          stderr,
+			})
+		}
+	}
 
 	endTimestamp := timestamp.Now()
 
 	// Copy over stdout and stderr even if an error occurred.
 	gradingResult.Stdout = stdout
 	gradingResult.Stderr = stderr
+	if usage != nil {
+		gradingResult.LimitViolation = usage.LimitViolation
+		storeResourceUsageMetrics(assignment, user, startTimestamp, usage)
+	}
 
 	// Check for hard grading errors.
 	if err != nil {
@@ -109,6 +226,16 @@ func Grade(ctx context.Context, assignment *model.Assignment, submissionPath str
 	gradingInfo.User = user
 	gradingInfo.Message = message
 	gradingInfo.ProxyUser = options.ProxyUser
+	gradingInfo.ResourceUsage = usage
+	gradingInfo.CommitSHA = options.CommitSHA
+
+	if group != nil {
+		gradingInfo.Group = &model.SubmissionGroupInfo{
+			ID:        group.ID,
+			Submitter: user,
+			Members:   groupMembers,
+		}
+	}
 
 	if options.ProxyTime == nil {
 		gradingInfo.GradingStartTime = startTimestamp
@@ -125,11 +252,19 @@ func Grade(ctx context.Context, assignment *model.Assignment, submissionPath str
 	gradingResult.Info = gradingInfo
 	gradingResult.OutputFilesGZip = outputFileContents
 
+	_, span := tracing.StartSpan(ctx, "grader.save")
 	err = db.SaveSubmission(assignment, &gradingResult)
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return &gradingResult, nil, "", fmt.Errorf("Failed to save grading result: '%w'.", err)
 	}
 
+	err = saveGroupSubmissions(assignment, &gradingResult)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return &gradingResult, nil, "", fmt.Errorf("Failed to save group grading results: '%w'.", err)
+	}
+
 	metric := stats.Metric{
 		Timestamp: startTimestamp,
 		Type:      stats.MetricTypeGradingTime,
@@ -170,9 +305,14 @@ func ResolveProxyTime(proxyTime *timestamp.Timestamp, assignment *model.Assignme
 	return &minuteBeforeDueDate
 }
 
-func prepForGrading(assignment *model.Assignment, submissionPath string, user string) (string, map[string][]byte, error) {
+func prepForGrading(ctx context.Context, assignment *model.Assignment, submissionPath string, user string) (string, map[string][]byte, error) {
+	ctx, span := tracing.StartSpan(ctx, "grader.prep")
+	defer span.End()
+
 	// Ensure the assignment docker image is built.
+	_, buildSpan := tracing.StartSpan(ctx, "docker.build", tracing.NewAttr("image", assignment.GetImageName()))
 	err := docker.BuildImageFromSourceQuick(assignment)
+	tracing.EndSpan(buildSpan, err)
 	if err != nil {
 		return "", nil, fmt.Errorf("Failed to build assignment '%s' docker image: '%w'.", assignment.FullID(), err)
 	}
@@ -190,8 +330,17 @@ func prepForGrading(assignment *model.Assignment, submissionPath string, user st
 	return submissionID, fileContents, nil
 }
 
-func getTimeoutMessage(assignment *model.Assignment) string {
-	return fmt.Sprintf("Submission has ran for too long and was killed. Max assignment runtime is %d seconds (server hard limit is %d seconds). Check for infinite loops/recursion and consult with your instructors/TAs.", assignment.MaxRuntimeSecs, config.GRADING_RUNTIME_MAX_SECS.Get())
+func getTimeoutMessage(maxRuntimeSecs int) string {
+	return fmt.Sprintf("Submission has ran for too long and was killed. Max assignment runtime is %d seconds (server hard limit is %d seconds). Check for infinite loops/recursion and consult with your instructors/TAs.", maxRuntimeSecs, config.GRADING_RUNTIME_MAX_SECS.Get())
+}
+
+// Get the max runtime for a grading run, falling back to the assignment's max runtime.
+func getMaxRuntimeSecs(assignment *model.Assignment, options GradeOptions) int {
+	if options.MaxRuntimeSecs > 0 {
+		return options.MaxRuntimeSecs
+	}
+
+	return assignment.MaxRuntimeSecs
 }
 
 func getCanceledMessage(assignment *model.Assignment) string {
@@ -201,30 +350,62 @@ func getCanceledMessage(assignment *model.Assignment) string {
 // Add an additional level for waiting for timeouts.
 // Timeouts should be handled a level below this (e.g., docker or exec),
 // but this is an additional layer just in case there are issues at that level.
-func runGrader(ctx context.Context, assignment *model.Assignment, submissionPath string, options GradeOptions, fullSubmissionID string) (*model.GradingInfo, map[string][]byte, string, string, string, error) {
+func runGrader(ctx context.Context, assignment *model.Assignment, submissionPath string, options GradeOptions, fullSubmissionID string) (*model.GradingInfo, map[string][]byte, string, string, *docker.ResourceUsage, string, error) {
 	var gradingInfo *model.GradingInfo
 	var outputFileContents map[string][]byte
 	var stdout string
 	var stderr string
+	var usage *docker.ResourceUsage
 	var softGradingError string
 	var err error
 
 	runFunc := func() {
 		if options.NoDocker {
-			gradingInfo, outputFileContents, stdout, stderr, softGradingError, err = runNoDockerGrader(ctx, assignment, submissionPath, options, fullSubmissionID)
+			gradingInfo, outputFileContents, stdout, stderr, usage, softGradingError, err = runNoDockerGrader(ctx, assignment, submissionPath, options, fullSubmissionID)
 		} else {
-			gradingInfo, outputFileContents, stdout, stderr, softGradingError, err = runDockerGrader(ctx, assignment, submissionPath, options, fullSubmissionID)
+			gradingInfo, outputFileContents, stdout, stderr, usage, softGradingError, err = runDockerGrader(ctx, assignment, submissionPath, options, fullSubmissionID)
 		}
 	}
 
-	timeoutMS := int64((assignment.MaxRuntimeSecs + extraRunTimeSecs) * 1000)
+	maxRuntimeSecs := getMaxRuntimeSecs(assignment, options)
+
+	timeoutMS := int64((maxRuntimeSecs + extraRunTimeSecs) * 1000)
 	ok := util.RunWithTimeout(timeoutMS, runFunc)
 
 	if !ok {
 		// Timeout
 		// We must return very general results (which is why we prefer to catch this at the grader level).
-		return nil, nil, "", "", getTimeoutMessage(assignment), nil
+		return nil, nil, "", "", nil, getTimeoutMessage(maxRuntimeSecs), nil
 	}
 
-	return gradingInfo, outputFileContents, stdout, stderr, softGradingError, err
+	return gradingInfo, outputFileContents, stdout, stderr, usage, softGradingError, err
+}
+
+// Record the resources used by a grader (regardless of whether grading was successful).
+func storeResourceUsageMetrics(assignment *model.Assignment, user string, startTimestamp timestamp.Timestamp, usage *docker.ResourceUsage) {
+	values := map[stats.MetricType]float64{
+		stats.MetricTypeGradingExitCode:   float64(usage.ExitCode),
+		stats.MetricTypeGradingRunTime:    float64(usage.WallTimeMSecs),
+		stats.MetricTypeGradingCPUTime:    float64(usage.CPUTimeMSecs),
+		stats.MetricTypeGradingPeakMemory: float64(usage.PeakMemoryBytes),
+	}
+
+	if usage.OOMKilled {
+		values[stats.MetricTypeGradingOOMKill] = 1
+	}
+
+	for metricType, value := range values {
+		metric := stats.Metric{
+			Timestamp: startTimestamp,
+			Type:      metricType,
+			Value:     value,
+			Attributes: map[stats.MetricAttribute]any{
+				stats.MetricAttributeUserEmail:    user,
+				stats.MetricAttributeCourseID:     assignment.GetCourse().GetID(),
+				stats.MetricAttributeAssignmentID: assignment.GetID(),
+			},
+		}
+
+		stats.AsyncStoreMetric(&metric)
+	}
 }
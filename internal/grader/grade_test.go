@@ -108,6 +108,13 @@ func runSubmissionTests(test *testing.T, parallel bool, useDocker bool) {
 					util.MustToJSONIndent(result.Info), util.MustToJSONIndent(testSubmission.TestSubmission.GradingInfo))
 			}
 
+			if result.Info.ResourceUsage == nil {
+				test.Fatalf("Successful grading is missing resource usage.")
+			}
+
+			if result.Info.ResourceUsage.ExitCode != 0 {
+				test.Fatalf("Unexpected grader exit code. Expected: 0, Actual: %d.", result.Info.ResourceUsage.ExitCode)
+			}
 		})
 
 		if !ok {
@@ -0,0 +1,73 @@
+package grader
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/common"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+)
+
+// Get the group a user is submitting with and the members of that group that are still enrolled in the course.
+// Returns (nil, nil, nil) if the user is not in a group.
+func getGroupMembers(assignment *model.Assignment, email string) (*model.Group, []string, error) {
+	group, err := db.GetUserGroup(assignment, email)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to get group for user '%s': '%w'.", email, err)
+	}
+
+	if group == nil {
+		return nil, nil, nil
+	}
+
+	users, err := db.GetCourseUsers(assignment.GetCourse())
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to get course users: '%w'.", err)
+	}
+
+	members := make([]string, 0, len(group.Members))
+	for _, member := range group.Members {
+		if (member != email) && (users[member] == nil) {
+			continue
+		}
+
+		members = append(members, member)
+	}
+
+	return group, members, nil
+}
+
+// Save a copy of a group submission for every other member of the group,
+// so the submission counts for all members.
+func saveGroupSubmissions(assignment *model.Assignment, gradingResult *model.GradingResult) error {
+	groupInfo := gradingResult.Info.Group
+	if groupInfo == nil {
+		return nil
+	}
+
+	for _, member := range groupInfo.Members {
+		if member == gradingResult.Info.User {
+			continue
+		}
+
+		shortSubmissionID, err := db.GetNextSubmissionID(assignment, member)
+		if err != nil {
+			return fmt.Errorf("Unable to get next submission id for group member '%s': '%w'.", member, err)
+		}
+
+		info := *gradingResult.Info
+		info.ID = common.CreateFullSubmissionID(info.CourseID, info.AssignmentID, member, shortSubmissionID)
+		info.ShortID = shortSubmissionID
+		info.User = member
+
+		memberResult := *gradingResult
+		memberResult.Info = &info
+
+		err = db.SaveSubmission(assignment, &memberResult)
+		if err != nil {
+			return fmt.Errorf("Failed to save submission for group member '%s': '%w'.", member, err)
+		}
+	}
+
+	return nil
+}
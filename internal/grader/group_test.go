@@ -0,0 +1,139 @@
+package grader
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestGradeGroupSubmission(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	submissionPath := filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELPATH)
+
+	submitter := "course-student@test.edulinq.org"
+	partner := "course-other@test.edulinq.org"
+	members := []string{partner, submitter}
+
+	mustSaveGroup(test, assignment, "g1", members)
+
+	testCases := []struct {
+		noGroup         bool
+		expectedMembers []string
+	}{
+		{false, members},
+		{true, nil},
+	}
+
+	for i, testCase := range testCases {
+		options := GetDefaultGradeOptions()
+		options.CheckRejection = false
+		options.NoGroup = testCase.noGroup
+
+		partnerHistory, err := db.GetSubmissionHistory(assignment, partner)
+		if err != nil {
+			test.Fatalf("Case %d: Failed to get partner history: '%v'.", i, err)
+		}
+
+		result, reject, softError, err := Grade(context.Background(), assignment, submissionPath, submitter, TEST_MESSAGE, options)
+		if err != nil {
+			test.Fatalf("Case %d: Failed to grade: '%v'.", i, err)
+		}
+
+		if (reject != nil) || (softError != "") {
+			test.Fatalf("Case %d: Submission was not successful. Reject: '%v', Soft Error: '%s'.", i, reject, softError)
+		}
+
+		newPartnerHistory, err := db.GetSubmissionHistory(assignment, partner)
+		if err != nil {
+			test.Fatalf("Case %d: Failed to get new partner history: '%v'.", i, err)
+		}
+
+		if testCase.noGroup {
+			if result.Info.Group != nil {
+				test.Errorf("Case %d: Found group info when not grading as a group: '%s'.", i, util.MustToJSONIndent(result.Info.Group))
+			}
+
+			if len(partnerHistory) != len(newPartnerHistory) {
+				test.Errorf("Case %d: Partner got a submission when not grading as a group.", i)
+			}
+
+			continue
+		}
+
+		expectedGroup := &model.SubmissionGroupInfo{
+			ID:        "g1",
+			Submitter: submitter,
+			Members:   testCase.expectedMembers,
+		}
+
+		if !reflect.DeepEqual(expectedGroup, result.Info.Group) {
+			test.Errorf("Case %d: Unexpected group info. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(expectedGroup), util.MustToJSONIndent(result.Info.Group))
+			continue
+		}
+
+		if (len(partnerHistory) + 1) != len(newPartnerHistory) {
+			test.Errorf("Case %d: Partner did not get a submission. Before: %d, After: %d.", i, len(partnerHistory), len(newPartnerHistory))
+			continue
+		}
+
+		partnerResult, err := db.GetSubmissionResult(assignment, partner, "")
+		if err != nil {
+			test.Fatalf("Case %d: Failed to get partner result: '%v'.", i, err)
+		}
+
+		if (partnerResult.User != partner) || (partnerResult.Score != result.Info.Score) ||
+			!reflect.DeepEqual(expectedGroup, partnerResult.Group) {
+			test.Errorf("Case %d: Unexpected partner result: '%s'.", i, util.MustToJSONIndent(partnerResult))
+			continue
+		}
+	}
+}
+
+func TestRejectSubmissionMaxAttemptsGroup(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	assignment.DueDate = nil
+
+	maxValue := 3
+	assignment.SubmissionLimit = &model.SubmissionLimitInfo{Max: &maxValue}
+
+	// course-student already has two submissions, course-other has none.
+	mustSaveGroup(test, assignment, "g1", []string{"course-other@test.edulinq.org", "course-student@test.edulinq.org"})
+
+	// The submission counts for both members, so course-student is now at the limit.
+	submitForRejection(test, assignment, "course-other@test.edulinq.org", false, nil)
+
+	// course-other has only used one attempt, but shares the limit with course-student.
+	submitForRejection(test, assignment, "course-other@test.edulinq.org", false, &RejectMaxAttempts{3})
+	submitForRejection(test, assignment, "course-student@test.edulinq.org", false, &RejectMaxAttempts{3})
+}
+
+func mustSaveGroup(test *testing.T, assignment *model.Assignment, id string, members []string) {
+	group := &model.Group{
+		ID:           id,
+		CourseID:     assignment.GetCourse().GetID(),
+		AssignmentID: assignment.GetID(),
+		Members:      members,
+	}
+
+	err := group.Validate(&model.GroupOptions{MaxSize: len(members)})
+	if err != nil {
+		test.Fatalf("Failed to validate group: '%v'.", err)
+	}
+
+	err = db.SaveGroup(assignment, group)
+	if err != nil {
+		test.Fatalf("Failed to save group: '%v'.", err)
+	}
+}
@@ -11,8 +11,10 @@ import (
 	"time"
 
 	"github.com/edulinq/autograder/internal/common"
+	"github.com/edulinq/autograder/internal/docker"
 	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/tracing"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -23,28 +25,31 @@ const PYTHON_DOCKER_IMAGE_BASENAME = "ghcr.io/edulinq/grader.python"
 // A small delay to wait for a process to finish after already timing out.
 var noDockerTimeoutWaitDelayMS int = 10 * 1000
 
-// Returns: (result, file contents, stdout, stderr, failure message (soft failure), error (hard failure)).
+// Resource limits are not enforced and peak memory is not reported when grading without docker.
+// Returns: (result, file contents, stdout, stderr, resource usage, failure message (soft failure), error (hard failure)).
 func runNoDockerGrader(ctx context.Context, assignment *model.Assignment, submissionPath string, options GradeOptions, fullSubmissionID string) (
-	*model.GradingInfo, map[string][]byte, string, string, string, error) {
+	*model.GradingInfo, map[string][]byte, string, string, *docker.ResourceUsage, string, error) {
 	imageInfo := assignment.GetImageInfo()
 	if imageInfo == nil {
-		return nil, nil, "", "", "", fmt.Errorf("No image information associated with assignment: '%s'.", assignment.FullID())
+		return nil, nil, "", "", nil, "", fmt.Errorf("No image information associated with assignment: '%s'.", assignment.FullID())
 	}
 
 	tempDir, inputDir, outputDir, workDir, err := common.PrepTempGradingDir("nodocker")
 	if err != nil {
-		return nil, nil, "", "", "", err
+		return nil, nil, "", "", nil, "", err
 	}
 
 	if !options.LeaveTempDir {
 		defer util.RemoveDirent(tempDir)
 	} else {
-		log.Debug("Leaving behind temp grading dir.", log.NewAttr("path", tempDir))
+		log.Debug("Leaving behind temp grading dir.", log.NewAttr("path", tempDir), tracing.NewLogAttr(ctx))
 	}
 
-	ctx, cmd, err := getAssignmentInvocation(ctx, assignment, tempDir, inputDir, outputDir, workDir)
+	maxRuntimeSecs := getMaxRuntimeSecs(assignment, options)
+
+	ctx, cmd, err := getAssignmentInvocation(ctx, assignment, maxRuntimeSecs, tempDir, inputDir, outputDir, workDir)
 	if err != nil {
-		return nil, nil, "", "", "", err
+		return nil, nil, "", "", nil, "", err
 	}
 
 	// Copy over the static files (and do any file ops).
@@ -52,60 +57,66 @@ func runNoDockerGrader(ctx context.Context, assignment *model.Assignment, submis
 	err = util.CopyFileSpecsWithOps(sourceBaseDir, sourceContainmentDir, workDir, workDir, tempDir,
 		imageInfo.StaticFiles, imageInfo.PreStaticFileOperations, imageInfo.PostStaticFileOperations)
 	if err != nil {
-		return nil, nil, "", "", "", fmt.Errorf("Failed to copy static assignment files: '%w'.", err)
+		return nil, nil, "", "", nil, "", fmt.Errorf("Failed to copy static assignment files: '%w'.", err)
 	}
 
 	// Copy over the submission files (and do any file ops).
 	err = util.CopyFileSpecsWithOps(submissionPath, "", inputDir, "", tempDir,
 		[]*util.FileSpec{util.GetPathFileSpec("*")}, []*util.FileOperation{}, imageInfo.PostSubmissionFileOperations)
 	if err != nil {
-		return nil, nil, "", "", "", fmt.Errorf("Failed to copy submission assignment files: '%w'.", err)
+		return nil, nil, "", "", nil, "", fmt.Errorf("Failed to copy submission assignment files: '%w'.", err)
 	}
 
-	stdout, stderr, timeout, canceled, err := runCMD(ctx, cmd)
+	stdout, stderr, timeout, canceled, usage, err := runCMD(ctx, cmd)
 	if err != nil {
 		log.Warn("Failed to run non-docker grader for assignment.",
-			assignment, err, log.NewAttr("cmd", cmd.String()))
+			assignment, err, log.NewAttr("cmd", cmd.String()), tracing.NewLogAttr(ctx))
 
 		// Note that this message could be a little more precise,
 		// but we make it match the case where an output file is not found to mimic Docker-based grading.
 		// This message shouldn't be used in production anyways.
 		message := "Cannot find output/result of grading. It is likely that the grader crashed."
-		return nil, nil, stdout, stderr, message, nil
+		return nil, nil, stdout, stderr, usage, message, nil
 	}
 
 	if timeout {
-		return nil, nil, stdout, stderr, getTimeoutMessage(assignment), nil
+		return nil, nil, stdout, stderr, usage, getTimeoutMessage(maxRuntimeSecs), nil
 	}
 
 	if canceled {
-		return nil, nil, stdout, stderr, getCanceledMessage(assignment), nil
+		return nil, nil, stdout, stderr, usage, getCanceledMessage(assignment), nil
 	}
 
 	resultPath := filepath.Join(outputDir, common.GRADER_OUTPUT_RESULT_FILENAME)
 	if !util.PathExists(resultPath) {
 		log.Warn("Cannot find output file after non-docker grading.",
-			log.NewAttr("path", resultPath), log.NewAttr("cmd", cmd.String()))
+			log.NewAttr("path", resultPath), log.NewAttr("cmd", cmd.String()), tracing.NewLogAttr(ctx))
 
 		message := "Cannot find output/result of grading. It is likely that the grader crashed."
-		return nil, nil, stdout, stderr, message, nil
+		return nil, nil, stdout, stderr, usage, message, nil
 	}
 
 	var gradingInfo model.GradingInfo
 	err = util.JSONFromFile(resultPath, &gradingInfo)
 	if err != nil {
-		return nil, nil, stdout, stderr, "", err
+		return nil, nil, stdout, stderr, usage, "", err
+	}
+
+	err = gradingInfo.PrepTestCases()
+	if err != nil {
+		return nil, nil, stdout, stderr, usage, "", fmt.Errorf("Grader output has invalid test cases: '%w'.", err)
 	}
 
 	fileContents, err := util.GzipDirectoryToBytes(outputDir)
 	if err != nil {
-		return nil, nil, stdout, stderr, "", fmt.Errorf("Failed to copy grading output '%s': '%w'.", outputDir, err)
+		return nil, nil, stdout, stderr, usage, "", fmt.Errorf("Failed to copy grading output '%s': '%w'.", outputDir, err)
 	}
 
-	return &gradingInfo, fileContents, stdout, stderr, "", nil
+	return &gradingInfo, fileContents, stdout, stderr, usage, "", nil
 }
 
-func runCMD(ctx context.Context, cmd *exec.Cmd) (string, string, bool, bool, error) {
+// Returns: (stdout, stderr, timeout?, canceled?, resource usage, error)
+func runCMD(ctx context.Context, cmd *exec.Cmd) (string, string, bool, bool, *docker.ResourceUsage, error) {
 	var outBuffer bytes.Buffer
 	var errBuffer bytes.Buffer
 
@@ -115,7 +126,19 @@ func runCMD(ctx context.Context, cmd *exec.Cmd) (string, string, bool, bool, err
 	timeout := false
 	canceled := false
 
+	startTime := time.Now()
 	err := cmd.Run()
+	wallTime := time.Since(startTime)
+
+	var usage *docker.ResourceUsage = nil
+	if cmd.ProcessState != nil {
+		usage = &docker.ResourceUsage{
+			ExitCode:      cmd.ProcessState.ExitCode(),
+			WallTimeMSecs: wallTime.Milliseconds(),
+			CPUTimeMSecs:  (cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()).Milliseconds(),
+		}
+	}
+
 	if err != nil {
 		timeout = errors.Is(ctx.Err(), context.DeadlineExceeded)
 		canceled = errors.Is(ctx.Err(), context.Canceled)
@@ -128,11 +151,11 @@ func runCMD(ctx context.Context, cmd *exec.Cmd) (string, string, bool, bool, err
 	stdout := outBuffer.String()
 	stderr := errBuffer.String()
 
-	return stdout, stderr, timeout, canceled, err
+	return stdout, stderr, timeout, canceled, usage, err
 }
 
 // Get a command to invoke the non-docker grader.
-func getAssignmentInvocation(ctx context.Context, assignment *model.Assignment,
+func getAssignmentInvocation(ctx context.Context, assignment *model.Assignment, maxRuntimeSecs int,
 	baseDir string, inputDir string, outputDir string, workDir string) (context.Context, *exec.Cmd, error) {
 	imageInfo := assignment.GetImageInfo()
 	if imageInfo == nil {
@@ -175,8 +198,8 @@ func getAssignmentInvocation(ctx context.Context, assignment *model.Assignment,
 
 	// Set a timeout for the command using the existing context as the parent.
 	var cancelFunc context.CancelFunc = nil
-	if assignment.MaxRuntimeSecs > 0 {
-		ctx, cancelFunc = context.WithTimeout(ctx, time.Duration(assignment.MaxRuntimeSecs)*time.Second)
+	if maxRuntimeSecs > 0 {
+		ctx, cancelFunc = context.WithTimeout(ctx, time.Duration(maxRuntimeSecs)*time.Second)
 	}
 
 	cmd := exec.CommandContext(ctx, cleanCommand[0], cleanCommand[1:]...)
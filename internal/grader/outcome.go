@@ -0,0 +1,87 @@
+package grader
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/docker"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/tracing"
+)
+
+// The outcome of grading a submission as it should be reported back to the submitter.
+type SubmissionOutcome struct {
+	Rejected bool   `json:"rejected"`
+	Message  string `json:"message"`
+
+	GradingSuccess bool               `json:"grading-success"`
+	GradingInfo    *model.GradingInfo `json:"result"`
+
+	LimitViolation docker.LimitViolation `json:"limit-violation,omitempty"`
+}
+
+// Grade a submission and convert the result into an outcome suitable for the submitter.
+// Internal errors are logged (along with the given log attributes) and not returned.
+func GradeSubmission(ctx context.Context, assignment *model.Assignment, submissionPath string, user string, message string,
+	options GradeOptions, logAttributes ...any) SubmissionOutcome {
+	outcome := SubmissionOutcome{}
+	logAttributes = append(logAttributes, tracing.NewLogAttr(ctx))
+
+	result, reject, failureMessage, err := Grade(ctx, assignment, submissionPath, user, message, options)
+	if err != nil {
+		stdout := ""
+		stderr := ""
+
+		if (result != nil) && (result.HasTextOutput()) {
+			stdout = result.Stdout
+			stderr = result.Stderr
+		}
+
+		attributes := append([]any{err, log.NewAttr("stdout", stdout), log.NewAttr("stderr", stderr)}, logAttributes...)
+		log.Warn("Submission failed internally.", attributes...)
+
+		return outcome
+	}
+
+	if reject != nil {
+		attributes := append([]any{log.NewAttr("reason", reject.String())}, logAttributes...)
+		log.Debug("Submission rejected.", attributes...)
+
+		outcome.Rejected = true
+		outcome.Message = reject.String()
+		return outcome
+	}
+
+	if failureMessage != "" {
+		stdout := ""
+		stderr := ""
+
+		if (result != nil) && (result.HasTextOutput()) {
+			stdout = result.Stdout
+			stderr = result.Stderr
+		}
+
+		attributes := append([]any{log.NewAttr("message", failureMessage), log.NewAttr("stdout", stdout), log.NewAttr("stderr", stderr)}, logAttributes...)
+		log.Debug("Submission got a soft error.", attributes...)
+
+		if result != nil {
+			outcome.LimitViolation = result.LimitViolation
+		}
+
+		outcome.Message = ConcatStdOutErr(failureMessage, stdout, stderr)
+		return outcome
+	}
+
+	outcome.GradingSuccess = true
+	outcome.GradingInfo = result.Info
+
+	return outcome
+}
+
+// Concat stdout/stderr to the given message.
+func ConcatStdOutErr(message string, stdout string, stderr string) string {
+	message += fmt.Sprintf("\n--- stdout ---\n%s\n--------------\n", stdout)
+	message += fmt.Sprintf("\n--- stderr ---\n%s\n--------------\n", stderr)
+	return message
+}
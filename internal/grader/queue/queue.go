@@ -0,0 +1,454 @@
+// An asynchronous grading queue.
+// Submissions are persisted to disk (so they survive a server restart)
+// and are graded in the order they were received by a limited number of workers (config.GRADING_QUEUE_WORKERS).
+// Submitters are given a job ID that they can use to check on their submission.
+package queue
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/grader"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	JOB_FILENAME       = "job.json"
+	SUBMISSION_DIRNAME = "submission"
+
+	// How long completed jobs (and their results) are kept around.
+	COMPLETED_JOB_TTL_MSECS = 24 * 60 * 60 * 1000
+
+	WAIT_POLL_MSECS = 10
+
+	MISSING_ASSIGNMENT_MESSAGE = "The assignment for this submission no longer exists."
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued   JobStatus = "queued"
+	JobStatusRunning  JobStatus = "running"
+	JobStatusComplete JobStatus = "complete"
+)
+
+type Job struct {
+	ID           string `json:"job-id"`
+	CourseID     string `json:"course-id"`
+	AssignmentID string `json:"assignment-id"`
+	User         string `json:"user"`
+	Message      string `json:"message"`
+
+	// Lateness is checked when a job is submitted (not when it is graded),
+	// so this is usually true for jobs that were accepted into the queue.
+	AllowLate bool `json:"allow-late"`
+
+	Status      JobStatus            `json:"status"`
+	EnqueueTime timestamp.Timestamp  `json:"enqueue-time"`
+	StartTime   *timestamp.Timestamp `json:"start-time,omitempty"`
+	EndTime     *timestamp.Timestamp `json:"end-time,omitempty"`
+
+	// Only set once the job is complete.
+	Result *grader.SubmissionOutcome `json:"result,omitempty"`
+}
+
+var (
+	lock    sync.Mutex
+	loaded  bool = false
+	stopped bool = false
+
+	// All known jobs (queued, running, and recently completed).
+	jobs map[string]*Job = make(map[string]*Job)
+
+	// Jobs that are waiting to be graded (in order).
+	pending []*Job = make([]*Job, 0)
+
+	running int = 0
+)
+
+func init() {
+	// Only count the jobs already in memory (scrapes should not wait on the disk).
+	stats.RegisterGaugeFunc("grading_queue_depth", "The number of asynchronous submissions waiting to be graded.", func() float64 {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return float64(len(pending))
+	})
+}
+
+// Add a submission to the grading queue.
+// The contents of the submission dir will be copied, so the caller is free to remove it after this returns.
+// Returns (a copy of the new job, the job's position in the queue, if the queue is full, error).
+// Positions start at 1.
+func Enqueue(assignment *model.Assignment, submissionDir string, user string, message string, allowLate bool) (*Job, int, bool, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	err := ensureLoaded()
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	if len(pending) >= config.GRADING_QUEUE_SIZE.Get() {
+		return nil, 0, true, nil
+	}
+
+	job := &Job{
+		ID:           util.UUID(),
+		CourseID:     assignment.GetCourse().GetID(),
+		AssignmentID: assignment.GetID(),
+		User:         user,
+		Message:      message,
+		AllowLate:    allowLate,
+		Status:       JobStatusQueued,
+		EnqueueTime:  timestamp.Now(),
+	}
+
+	err = util.MkDir(getJobDir(job.ID))
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("Failed to create dir for grading job '%s': '%w'.", job.ID, err)
+	}
+
+	err = util.CopyDirContents(submissionDir, getSubmissionDir(job.ID))
+	if err != nil {
+		util.RemoveDirent(getJobDir(job.ID))
+		return nil, 0, false, fmt.Errorf("Failed to copy submission for grading job '%s': '%w'.", job.ID, err)
+	}
+
+	err = saveJob(job)
+	if err != nil {
+		util.RemoveDirent(getJobDir(job.ID))
+		return nil, 0, false, err
+	}
+
+	jobs[job.ID] = job
+	pending = append(pending, job)
+
+	log.Debug("Grading job queued.", job, log.NewAttr("position", len(pending)))
+
+	removeExpiredJobs()
+	dispatch()
+
+	// The job may have already started.
+	return copyJob(job), getPosition(job), false, nil
+}
+
+// Get a copy of a job and its position in the queue (zero if the job is not waiting).
+// Returns a nil job if the job is not known.
+func GetJob(id string) (*Job, int, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	err := ensureLoaded()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	job, ok := jobs[id]
+	if !ok {
+		return nil, 0, nil
+	}
+
+	return copyJob(job), getPosition(job), nil
+}
+
+// Load any persisted jobs and start grading.
+func Start() error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	stopped = false
+
+	err := ensureLoaded()
+	if err != nil {
+		return err
+	}
+
+	dispatch()
+
+	return nil
+}
+
+// Get the number of jobs waiting to be graded.
+func GetPendingCount() (int, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	err := ensureLoaded()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(pending), nil
+}
+
+// Stop starting new grading jobs.
+// Jobs that are currently being graded will not be interrupted.
+// Any jobs still waiting in the queue will be picked up the next time the queue is loaded.
+func Stop() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	stopped = true
+}
+
+// Wait (up to the given timeout) for a job to complete and return a copy of it.
+func WaitForJob(id string, timeoutMSecs int) (*Job, error) {
+	deadline := timestamp.Now() + timestamp.FromMSecs(int64(timeoutMSecs))
+
+	for {
+		job, _, err := GetJob(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if job == nil {
+			return nil, fmt.Errorf("Unknown grading job '%s'.", id)
+		}
+
+		if job.Status == JobStatusComplete {
+			return job, nil
+		}
+
+		if timestamp.Now() > deadline {
+			return nil, fmt.Errorf("Timed out waiting for grading job '%s' (status: '%s').", id, job.Status)
+		}
+
+		time.Sleep(time.Duration(WAIT_POLL_MSECS) * time.Millisecond)
+	}
+}
+
+// Clear all queue state (including persisted jobs) and allow jobs to run again.
+// Callers should make sure no jobs are running.
+func ResetForTesting() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	util.RemoveDirent(config.GetGradingQueueDir())
+
+	loaded = false
+	stopped = false
+	jobs = make(map[string]*Job)
+	pending = make([]*Job, 0)
+	running = 0
+}
+
+func (this *Job) LogValue() []*log.Attr {
+	return []*log.Attr{
+		log.NewAttr("job-id", this.ID),
+		log.NewCourseAttr(this.CourseID),
+		log.NewAssignmentAttr(this.AssignmentID),
+		log.NewUserAttr(this.User),
+	}
+}
+
+// Start as many jobs as the number of workers allow.
+// The caller must hold the lock.
+func dispatch() {
+	for !stopped && (running < config.GRADING_QUEUE_WORKERS.Get()) && (len(pending) > 0) {
+		job := nextJob()
+
+		now := timestamp.Now()
+		job.Status = JobStatusRunning
+		job.StartTime = &now
+
+		err := saveJob(job)
+		if err != nil {
+			log.Warn("Failed to save running grading job.", err, job)
+		}
+
+		running++
+		go runJob(job)
+	}
+}
+
+// Remove and return the next job that should be graded.
+// The caller must hold the lock and ensure that there is at least one pending job.
+func nextJob() *Job {
+	job := pending[0]
+	pending = pending[1:]
+	return job
+}
+
+func runJob(job *Job) {
+	outcome := gradeJob(job)
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	now := timestamp.Now()
+	job.Status = JobStatusComplete
+	job.EndTime = &now
+	job.Result = &outcome
+
+	err := saveJob(job)
+	if err != nil {
+		log.Error("Failed to save completed grading job.", err, job)
+	}
+
+	err = util.RemoveDirent(getSubmissionDir(job.ID))
+	if err != nil {
+		log.Warn("Failed to remove submission for completed grading job.", err, job)
+	}
+
+	log.Debug("Grading job complete.", job, log.NewAttr("success", outcome.GradingSuccess))
+
+	running--
+	dispatch()
+}
+
+func gradeJob(job *Job) (outcome grader.SubmissionOutcome) {
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+
+		log.Error("Grading job paniced.", job, log.NewAttr("recover-value", value))
+		outcome = grader.SubmissionOutcome{}
+	}()
+
+	assignment, err := getAssignment(job)
+	if err != nil {
+		log.Error("Failed to get assignment for grading job.", err, job)
+		return grader.SubmissionOutcome{}
+	}
+
+	if assignment == nil {
+		log.Warn("Could not find assignment for grading job.", job)
+		return grader.SubmissionOutcome{Message: MISSING_ASSIGNMENT_MESSAGE}
+	}
+
+	options := grader.GetDefaultGradeOptions()
+	options.AllowLate = job.AllowLate
+
+	// Jobs are not tied to any request, so they should not be canceled.
+	return grader.GradeSubmission(context.Background(), assignment, getSubmissionDir(job.ID), job.User, job.Message, options, job)
+}
+
+func getAssignment(job *Job) (*model.Assignment, error) {
+	course, err := db.GetCourse(job.CourseID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get course '%s': '%w'.", job.CourseID, err)
+	}
+
+	if course == nil {
+		return nil, nil
+	}
+
+	return course.GetAssignment(job.AssignmentID), nil
+}
+
+// Load any jobs that were persisted by a previous run of the server.
+// Jobs that were running when the server stopped will be graded again.
+// The caller must hold the lock.
+func ensureLoaded() error {
+	if loaded {
+		return nil
+	}
+
+	baseDir := config.GetGradingQueueDir()
+	if !util.PathExists(baseDir) {
+		loaded = true
+		return nil
+	}
+
+	dirents, err := os.ReadDir(baseDir)
+	if err != nil {
+		return fmt.Errorf("Failed to list grading queue dir '%s': '%w'.", baseDir, err)
+	}
+
+	for _, dirent := range dirents {
+		path := filepath.Join(baseDir, dirent.Name(), JOB_FILENAME)
+		if !util.PathExists(path) {
+			continue
+		}
+
+		var job Job
+		err = util.JSONFromFile(path, &job)
+		if err != nil {
+			return fmt.Errorf("Failed to load grading job '%s': '%w'.", path, err)
+		}
+
+		jobs[job.ID] = &job
+
+		if job.Status != JobStatusComplete {
+			job.Status = JobStatusQueued
+			job.StartTime = nil
+			pending = append(pending, &job)
+		}
+	}
+
+	slices.SortStableFunc(pending, func(a *Job, b *Job) int {
+		return int(a.EnqueueTime - b.EnqueueTime)
+	})
+
+	loaded = true
+
+	if len(pending) > 0 {
+		log.Info("Loaded pending grading jobs.", log.NewAttr("count", len(pending)))
+	}
+
+	removeExpiredJobs()
+	dispatch()
+
+	return nil
+}
+
+// Remove completed jobs that have been around long enough.
+// The caller must hold the lock.
+func removeExpiredJobs() {
+	cutoff := timestamp.Now() - timestamp.FromMSecs(COMPLETED_JOB_TTL_MSECS)
+
+	for id, job := range jobs {
+		if (job.Status != JobStatusComplete) || (job.EndTime == nil) || (*job.EndTime > cutoff) {
+			continue
+		}
+
+		err := util.RemoveDirent(getJobDir(id))
+		if err != nil {
+			log.Warn("Failed to remove expired grading job.", err, job)
+			continue
+		}
+
+		delete(jobs, id)
+	}
+}
+
+// The caller must hold the lock.
+func getPosition(job *Job) int {
+	return slices.Index(pending, job) + 1
+}
+
+func saveJob(job *Job) error {
+	err := util.ToJSONFileIndent(job, filepath.Join(getJobDir(job.ID), JOB_FILENAME))
+	if err != nil {
+		return fmt.Errorf("Failed to save grading job '%s': '%w'.", job.ID, err)
+	}
+
+	return nil
+}
+
+func copyJob(job *Job) *Job {
+	jobCopy := *job
+	return &jobCopy
+}
+
+func getJobDir(id string) string {
+	return filepath.Join(config.GetGradingQueueDir(), id)
+}
+
+func getSubmissionDir(id string) string {
+	return filepath.Join(getJobDir(id), SUBMISSION_DIRNAME)
+}
@@ -0,0 +1,211 @@
+package queue
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	TEST_USER       = "course-student@test.edulinq.org"
+	TEST_WAIT_MSECS = 60 * 1000
+	TEST_MESSAGE    = "queue test"
+)
+
+var SUBMISSION_RELDIR string = filepath.Join("test-submissions", "solution")
+
+func TestQueueGradeBase(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	ResetForTesting()
+	defer ResetForTesting()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+
+	job, _, full, err := Enqueue(assignment, filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELDIR), TEST_USER, TEST_MESSAGE, true)
+	if err != nil {
+		test.Fatalf("Failed to enqueue: '%v'.", err)
+	}
+
+	if full {
+		test.Fatalf("Queue is unexpectedly full.")
+	}
+
+	job, err = WaitForJob(job.ID, TEST_WAIT_MSECS)
+	if err != nil {
+		test.Fatalf("Failed to wait for job: '%v'.", err)
+	}
+
+	if (job.Result == nil) || !job.Result.GradingSuccess {
+		test.Fatalf("Job was not a grading success: '%s'.", util.MustToJSONIndent(job))
+	}
+
+	if (job.StartTime == nil) || (job.EndTime == nil) {
+		test.Fatalf("Job is missing start/end times: '%s'.", util.MustToJSONIndent(job))
+	}
+
+	if util.PathExists(getSubmissionDir(job.ID)) {
+		test.Fatalf("Submission dir was not removed after grading.")
+	}
+
+	submission, err := db.GetSubmissionResult(assignment, TEST_USER, job.Result.GradingInfo.ShortID)
+	if err != nil {
+		test.Fatalf("Failed to get submission: '%v'.", err)
+	}
+
+	if submission == nil {
+		test.Fatalf("Could not find graded submission.")
+	}
+
+	if submission.Message != TEST_MESSAGE {
+		test.Fatalf("Unexpected submission message. Expected: '%s', Actual: '%s'.", TEST_MESSAGE, submission.Message)
+	}
+}
+
+func TestQueuePositionAndFull(test *testing.T) {
+	ResetForTesting()
+	defer ResetForTesting()
+
+	// Do not start any jobs.
+	oldWorkers := config.GRADING_QUEUE_WORKERS.Get()
+	config.GRADING_QUEUE_WORKERS.Set(0)
+	defer config.GRADING_QUEUE_WORKERS.Set(oldWorkers)
+
+	oldSize := config.GRADING_QUEUE_SIZE.Get()
+	config.GRADING_QUEUE_SIZE.Set(2)
+	defer config.GRADING_QUEUE_SIZE.Set(oldSize)
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	submissionDir := filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELDIR)
+
+	testCases := []struct {
+		position int
+		full     bool
+	}{
+		{1, false},
+		{2, false},
+		{0, true},
+	}
+
+	ids := make([]string, 0, len(testCases))
+
+	for i, testCase := range testCases {
+		job, position, full, err := Enqueue(assignment, submissionDir, TEST_USER, TEST_MESSAGE, true)
+		if err != nil {
+			test.Errorf("Case %d: Failed to enqueue: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.full != full {
+			test.Errorf("Case %d: Unexpected full value. Expected: '%v', Actual: '%v'.", i, testCase.full, full)
+			continue
+		}
+
+		if testCase.position != position {
+			test.Errorf("Case %d: Unexpected position. Expected: %d, Actual: %d.", i, testCase.position, position)
+			continue
+		}
+
+		if full {
+			if job != nil {
+				test.Errorf("Case %d: Got a job when the queue is full.", i)
+			}
+
+			continue
+		}
+
+		if job.Status != JobStatusQueued {
+			test.Errorf("Case %d: Unexpected status. Expected: '%s', Actual: '%s'.", i, JobStatusQueued, job.Status)
+			continue
+		}
+
+		ids = append(ids, job.ID)
+	}
+
+	for i, id := range ids {
+		job, position, err := GetJob(id)
+		if err != nil {
+			test.Errorf("Case %d: Failed to get job: '%v'.", i, err)
+			continue
+		}
+
+		if job == nil {
+			test.Errorf("Case %d: Could not find job.", i)
+			continue
+		}
+
+		if position != (i + 1) {
+			test.Errorf("Case %d: Unexpected position. Expected: %d, Actual: %d.", i, i+1, position)
+			continue
+		}
+	}
+
+	count, err := GetPendingCount()
+	if err != nil {
+		test.Fatalf("Failed to get pending count: '%v'.", err)
+	}
+
+	if count != 2 {
+		test.Fatalf("Unexpected pending count. Expected: 2, Actual: %d.", count)
+	}
+
+	job, _, err := GetJob("ZZZ")
+	if err != nil {
+		test.Fatalf("Failed to get missing job: '%v'.", err)
+	}
+
+	if job != nil {
+		test.Fatalf("Got a job for an unknown ID: '%s'.", util.MustToJSONIndent(job))
+	}
+}
+
+// Jobs that were persisted (e.g., before a server restart) should get graded when the queue starts.
+func TestQueueResume(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	ResetForTesting()
+	defer ResetForTesting()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+
+	oldWorkers := config.GRADING_QUEUE_WORKERS.Get()
+	config.GRADING_QUEUE_WORKERS.Set(0)
+
+	job, _, _, err := Enqueue(assignment, filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELDIR), TEST_USER, TEST_MESSAGE, true)
+	config.GRADING_QUEUE_WORKERS.Set(oldWorkers)
+	if err != nil {
+		test.Fatalf("Failed to enqueue: '%v'.", err)
+	}
+
+	// Forget everything in memory (but keep the persisted job).
+	Stop()
+	clearMemory()
+
+	err = Start()
+	if err != nil {
+		test.Fatalf("Failed to start queue: '%v'.", err)
+	}
+
+	job, err = WaitForJob(job.ID, TEST_WAIT_MSECS)
+	if err != nil {
+		test.Fatalf("Failed to wait for job: '%v'.", err)
+	}
+
+	if (job.Result == nil) || !job.Result.GradingSuccess {
+		test.Fatalf("Resumed job was not a grading success: '%s'.", util.MustToJSONIndent(job))
+	}
+}
+
+func clearMemory() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	loaded = false
+	jobs = make(map[string]*Job)
+	pending = make([]*Job, 0)
+}
@@ -30,6 +30,9 @@ type RegradeOptions struct {
 	// Setting this true is useful for testing (as one round of analysis tests can be wrapped up).
 	RetainOriginalContext bool `json:"-"`
 
+	// Always run the grader, even if there is cached output for a submission.
+	BypassCache bool `json:"bypass-cache"`
+
 	ResolvedUsers []string `json:"-"`
 }
 
@@ -57,6 +60,11 @@ func Regrade(assignment *model.Assignment, options RegradeOptions) (*RegradeResu
 		options.Context = context.Background()
 	}
 
+	options.GradeOptions.NoCache = (options.GradeOptions.NoCache || options.BypassCache)
+
+	// Each user is regraded individually (including members of a group).
+	options.GradeOptions.NoGroup = true
+
 	if options.RegradeCutoff == nil {
 		now := timestamp.Now()
 		options.RegradeCutoff = &now
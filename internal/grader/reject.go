@@ -61,6 +61,11 @@ func (this *RejectLate) String() string {
 		this.AssignmentName, this.DueDate.SafeMessage(), deltaString)
 }
 
+// Check if a submission would be rejected if it was graded right now.
+func CheckForRejection(assignment *model.Assignment, submissionPath string, email string, message string, allowLate bool) (RejectReason, error) {
+	return checkForRejection(assignment, submissionPath, email, message, allowLate)
+}
+
 func checkForRejection(assignment *model.Assignment, submissionPath string, email string, message string, allowLate bool) (RejectReason, error) {
 	user, err := db.GetServerUser(email)
 	if err != nil {
@@ -76,29 +81,60 @@ func checkForRejection(assignment *model.Assignment, submissionPath string, emai
 		return nil, nil
 	}
 
-	reason := checkLateSubmission(assignment, allowLate)
+	extension, err := db.GetExtension(assignment, email)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get extension: '%w'.", err)
+	}
+
+	reason := checkLateSubmission(assignment, extension, allowLate)
 	if reason != nil {
 		return reason, nil
 	}
 
-	return checkSubmissionLimit(assignment, email)
+	_, groupMembers, err := getGroupMembers(assignment, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if groupMembers == nil {
+		return checkSubmissionLimit(assignment, extension, email)
+	}
+
+	// Group members share submissions, so every member must be within their own submission limit.
+	for _, member := range groupMembers {
+		memberExtension := extension
+		if member != email {
+			memberExtension, err = db.GetExtension(assignment, member)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get extension for group member '%s': '%w'.", member, err)
+			}
+		}
+
+		reason, err = checkSubmissionLimit(assignment, memberExtension, member)
+		if (reason != nil) || (err != nil) {
+			return reason, err
+		}
+	}
+
+	return nil, nil
 }
 
-func checkLateSubmission(assignment *model.Assignment, allowLate bool) RejectReason {
-	if assignment.DueDate == nil {
+func checkLateSubmission(assignment *model.Assignment, extension *model.Extension, allowLate bool) RejectReason {
+	dueDate := extension.GetDueDate(assignment.DueDate)
+	if dueDate == nil {
 		return nil
 	}
 
 	now := timestamp.Now()
 
-	if (now > *assignment.DueDate) && !allowLate {
-		return &RejectLate{assignment.Name, *assignment.DueDate}
+	if (now > *dueDate) && !allowLate {
+		return &RejectLate{assignment.Name, *dueDate}
 	}
 
 	return nil
 }
 
-func checkSubmissionLimit(assignment *model.Assignment, email string) (RejectReason, error) {
+func checkSubmissionLimit(assignment *model.Assignment, extension *model.Extension, email string) (RejectReason, error) {
 	// Do not check for submission limits in testing mode.
 	if config.UNIT_TESTING_MODE.Get() {
 		return nil, nil
@@ -133,8 +169,11 @@ func checkSubmissionLimit(assignment *model.Assignment, email string) (RejectRea
 	}
 
 	if *limit.Max >= 0 {
-		if countStudentSubmissions(history) >= *limit.Max {
-			return &RejectMaxAttempts{*limit.Max}, nil
+		// Extensions can grant additional attempts.
+		maxAttempts := *limit.Max + extension.GetExtraAttempts()
+
+		if countStudentSubmissions(history) >= maxAttempts {
+			return &RejectMaxAttempts{maxAttempts}, nil
 		}
 	}
 
@@ -136,6 +136,76 @@ func TestRejectLateSubmissionWithAllow(test *testing.T) {
 	submitForRejection(test, assignment, "course-other@test.edulinq.org", true, nil)
 }
 
+func TestRejectSubmissionMaxAttemptsExtension(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	assignment.DueDate = nil
+
+	maxValue := 0
+	assignment.SubmissionLimit = &model.SubmissionLimitInfo{Max: &maxValue}
+
+	mustSaveExtension(test, &model.Extension{
+		CourseID:      TEST_COURSE_ID,
+		User:          "course-other@test.edulinq.org",
+		ExtraAttempts: 1,
+	})
+
+	// The extension allows for one submission.
+	submitForRejection(test, assignment, "course-other@test.edulinq.org", false, nil)
+	submitForRejection(test, assignment, "course-other@test.edulinq.org", false, &RejectMaxAttempts{1})
+
+	// Other users do not get the extension.
+	submitForRejection(test, assignment, "course-student@test.edulinq.org", false, &RejectMaxAttempts{0})
+}
+
+func TestRejectLateSubmissionExtension(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	assignment.SubmissionLimit = &model.SubmissionLimitInfo{}
+
+	// Set the due date to be the Unix epoch.
+	dueDate := timestamp.Zero()
+	assignment.DueDate = &dueDate
+
+	// A new due date in the future.
+	newDueDate := timestamp.FromMSecs(timestamp.Now().ToMSecs() + (24 * 60 * 60 * 1000))
+	mustSaveExtension(test, &model.Extension{
+		CourseID:     TEST_COURSE_ID,
+		AssignmentID: TEST_ASSIGNMENT_ID,
+		User:         "course-other@test.edulinq.org",
+		DueDate:      &newDueDate,
+	})
+
+	submitForRejection(test, assignment, "course-other@test.edulinq.org", false, nil)
+
+	// Extra time that is not enough to make the submission on time.
+	extraTime := util.DurationSpec{Days: 1}
+	mustSaveExtension(test, &model.Extension{
+		CourseID:  TEST_COURSE_ID,
+		User:      "course-student@test.edulinq.org",
+		ExtraTime: &extraTime,
+	})
+
+	extendedDueDate := timestamp.FromMSecs(extraTime.TotalMSecs())
+	submitForRejection(test, assignment, "course-student@test.edulinq.org", false, &RejectLate{assignment.Name, extendedDueDate})
+}
+
+func mustSaveExtension(test *testing.T, extension *model.Extension) {
+	err := extension.Validate()
+	if err != nil {
+		test.Fatalf("Failed to validate extension: '%v'.", err)
+	}
+
+	err = db.SaveExtension(extension)
+	if err != nil {
+		test.Fatalf("Failed to save extension: '%v'.", err)
+	}
+}
+
 func testMaxWindowAttempts(test *testing.T, user string, expectReject bool) {
 	db.ResetForTesting()
 	defer db.ResetForTesting()
@@ -0,0 +1,273 @@
+package grader
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+// The scheduler limits how many graders can be running at the same time,
+// both across the whole server (config.GRADING_MAX_CONCURRENT) and within a single course (config.GRADING_MAX_CONCURRENT_COURSE).
+// When there are more graders waiting than available slots, the next grader to run is chosen by:
+//  1. First attempts (a user's first submission for an assignment) before resubmissions.
+//     Resubmissions that have been waiting for longer than config.GRADING_RESUBMISSION_MAX_WAIT_SECS
+//     are treated like first attempts (so they cannot be starved by a steady stream of first attempts).
+//  2. Round-robin across courses.
+//  3. Round-robin across users within a course.
+//  4. The order that graders started waiting.
+type gradingScheduler struct {
+	lock sync.Mutex
+
+	running         int
+	runningByCourse map[string]int
+
+	// Requests that are waiting for a slot (in the order they arrived).
+	waiting []*gradingRequest
+
+	// The last course/user (per course) that was given a slot.
+	lastCourse string
+	lastUsers  map[string]string
+}
+
+type gradingRequest struct {
+	courseID     string
+	user         string
+	firstAttempt bool
+	waitStart    timestamp.Timestamp
+
+	granted bool
+	ready   chan any
+}
+
+var scheduler *gradingScheduler = newGradingScheduler()
+
+func init() {
+	stats.RegisterGaugeFunc("grading_in_flight", "The number of graders that are currently running.", func() float64 {
+		running, _ := scheduler.getCounts()
+		return float64(running)
+	})
+
+	stats.RegisterGaugeFunc("grading_waiting", "The number of graders that are waiting for a grading slot.", func() float64 {
+		_, waiting := scheduler.getCounts()
+		return float64(waiting)
+	})
+}
+
+func newGradingScheduler() *gradingScheduler {
+	return &gradingScheduler{
+		runningByCourse: make(map[string]int),
+		waiting:         make([]*gradingRequest, 0),
+		lastUsers:       make(map[string]string),
+	}
+}
+
+// Wait for a grading slot.
+// On success, the returned function must be called to release the slot once grading is complete.
+// An error is only returned if the context is done before a slot is available.
+func (this *gradingScheduler) acquire(ctx context.Context, courseID string, user string, firstAttempt bool) (func(), error) {
+	request := &gradingRequest{
+		courseID:     courseID,
+		user:         user,
+		firstAttempt: firstAttempt,
+		waitStart:    timestamp.Now(),
+		ready:        make(chan any),
+	}
+
+	this.lock.Lock()
+	this.waiting = append(this.waiting, request)
+	this.schedule()
+	this.lock.Unlock()
+
+	release := func() {
+		this.release(courseID)
+	}
+
+	select {
+	case <-request.ready:
+		return release, nil
+	case <-ctx.Done():
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// The slot may have been granted right as the context finished.
+	if request.granted {
+		return release, nil
+	}
+
+	this.waiting = slices.DeleteFunc(this.waiting, func(other *gradingRequest) bool {
+		return (other == request)
+	})
+
+	return nil, ctx.Err()
+}
+
+func (this *gradingScheduler) release(courseID string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.running--
+	this.runningByCourse[courseID]--
+	if this.runningByCourse[courseID] <= 0 {
+		delete(this.runningByCourse, courseID)
+	}
+
+	this.schedule()
+}
+
+// Get the number of running and waiting graders.
+func (this *gradingScheduler) getCounts() (int, int) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.running, len(this.waiting)
+}
+
+// Hand out as many slots as possible.
+// The caller must hold the lock.
+func (this *gradingScheduler) schedule() {
+	for {
+		request := this.next()
+		if request == nil {
+			return
+		}
+
+		this.grant(request)
+	}
+}
+
+// Give a slot to a waiting request.
+// The caller must hold the lock.
+func (this *gradingScheduler) grant(request *gradingRequest) {
+	this.waiting = slices.DeleteFunc(this.waiting, func(other *gradingRequest) bool {
+		return (other == request)
+	})
+
+	this.running++
+	this.runningByCourse[request.courseID]++
+
+	this.lastCourse = request.courseID
+	this.lastUsers[request.courseID] = request.user
+
+	request.granted = true
+	close(request.ready)
+}
+
+// Choose the next request that should get a slot (without changing any state).
+// Returns nil if no request can run right now.
+// The caller must hold the lock.
+func (this *gradingScheduler) next() *gradingRequest {
+	maxRunning := config.GRADING_MAX_CONCURRENT.Get()
+	if (maxRunning > 0) && (this.running >= maxRunning) {
+		return nil
+	}
+
+	maxCourseRunning := config.GRADING_MAX_CONCURRENT_COURSE.Get()
+
+	promoteBefore := timestamp.Now() - timestamp.FromMSecs(int64(config.GRADING_RESUBMISSION_MAX_WAIT_SECS.Get())*1000)
+
+	candidates := make([]*gradingRequest, 0, len(this.waiting))
+	hasPriority := false
+
+	for _, request := range this.waiting {
+		if (maxCourseRunning > 0) && (this.runningByCourse[request.courseID] >= maxCourseRunning) {
+			continue
+		}
+
+		candidates = append(candidates, request)
+		hasPriority = (hasPriority || request.hasPriority(promoteBefore))
+	}
+
+	if hasPriority {
+		candidates = slices.DeleteFunc(candidates, func(request *gradingRequest) bool {
+			return !request.hasPriority(promoteBefore)
+		})
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	courses := make([]string, 0, len(candidates))
+	for _, request := range candidates {
+		courses = append(courses, request.courseID)
+	}
+
+	courseID := nextRoundRobin(courses, this.lastCourse)
+
+	users := make([]string, 0, len(candidates))
+	for _, request := range candidates {
+		if request.courseID == courseID {
+			users = append(users, request.user)
+		}
+	}
+
+	user := nextRoundRobin(users, this.lastUsers[courseID])
+
+	// Candidates are in the order they started waiting.
+	for _, request := range candidates {
+		if (request.courseID == courseID) && (request.user == user) {
+			return request
+		}
+	}
+
+	return nil
+}
+
+// Check if a request should be run before other (non-priority) requests.
+// First attempts always have priority, while resubmissions get priority once they started waiting before |promoteBefore|.
+func (this *gradingRequest) hasPriority(promoteBefore timestamp.Timestamp) bool {
+	return this.firstAttempt || (this.waitStart <= promoteBefore)
+}
+
+// Get the first value (in sorted order) after the last value, wrapping around if necessary.
+// The values must not be empty.
+func nextRoundRobin(values []string, last string) string {
+	slices.Sort(values)
+
+	for _, value := range values {
+		if value > last {
+			return value
+		}
+	}
+
+	return values[0]
+}
+
+// Wait for a slot to run a grader and record how long the wait took.
+func acquireGradingSlot(ctx context.Context, assignment *model.Assignment, user string) (func(), error) {
+	history, err := db.GetSubmissionHistory(assignment, user)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get submission history: '%w'.", err)
+	}
+
+	startTimestamp := timestamp.Now()
+
+	release, err := scheduler.acquire(ctx, assignment.GetCourse().GetID(), user, (len(history) == 0))
+	if err != nil {
+		return nil, err
+	}
+
+	metric := stats.Metric{
+		Timestamp: startTimestamp,
+		Type:      stats.MetricTypeGradingWaitTime,
+		Value:     float64((timestamp.Now() - startTimestamp).ToMSecs()),
+		Attributes: map[stats.MetricAttribute]any{
+			stats.MetricAttributeUserEmail:    user,
+			stats.MetricAttributeCourseID:     assignment.GetCourse().GetID(),
+			stats.MetricAttributeAssignmentID: assignment.GetID(),
+		},
+	}
+
+	stats.AsyncStoreMetric(&metric)
+
+	return release, nil
+}
@@ -0,0 +1,320 @@
+package grader
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestSchedulerNext(test *testing.T) {
+	testCases := []struct {
+		maxRunning       int
+		maxCourseRunning int
+		running          map[string]int
+		lastCourse       string
+		lastUsers        map[string]string
+		waiting          []*gradingRequest
+		expected         []string
+	}{
+		// No limits.
+		{
+			0, 0, nil, "", nil,
+			[]*gradingRequest{
+				&gradingRequest{courseID: "A", user: "1"},
+				&gradingRequest{courseID: "A", user: "2"},
+			},
+			[]string{"A::1", "A::2"},
+		},
+
+		// Global limit.
+		{
+			1, 0, nil, "", nil,
+			[]*gradingRequest{
+				&gradingRequest{courseID: "A", user: "1"},
+				&gradingRequest{courseID: "A", user: "2"},
+			},
+			[]string{"A::1"},
+		},
+		{
+			2, 0, map[string]int{"A": 2}, "", nil,
+			[]*gradingRequest{
+				&gradingRequest{courseID: "A", user: "1"},
+			},
+			[]string{},
+		},
+
+		// Course limit.
+		{
+			0, 1, map[string]int{"A": 1}, "", nil,
+			[]*gradingRequest{
+				&gradingRequest{courseID: "A", user: "1"},
+				&gradingRequest{courseID: "B", user: "1"},
+				&gradingRequest{courseID: "B", user: "2"},
+			},
+			[]string{"B::1"},
+		},
+
+		// Round-robin across courses.
+		{
+			0, 0, nil, "", nil,
+			[]*gradingRequest{
+				&gradingRequest{courseID: "A", user: "1"},
+				&gradingRequest{courseID: "A", user: "1"},
+				&gradingRequest{courseID: "A", user: "2"},
+				&gradingRequest{courseID: "B", user: "1"},
+			},
+			[]string{"A::1", "B::1", "A::2", "A::1"},
+		},
+		{
+			0, 0, nil, "A", nil,
+			[]*gradingRequest{
+				&gradingRequest{courseID: "A", user: "1"},
+				&gradingRequest{courseID: "B", user: "1"},
+			},
+			[]string{"B::1", "A::1"},
+		},
+
+		// Round-robin across users.
+		{
+			0, 0, nil, "", map[string]string{"A": "1"},
+			[]*gradingRequest{
+				&gradingRequest{courseID: "A", user: "1"},
+				&gradingRequest{courseID: "A", user: "1"},
+				&gradingRequest{courseID: "A", user: "2"},
+				&gradingRequest{courseID: "A", user: "3"},
+			},
+			[]string{"A::2", "A::3", "A::1", "A::1"},
+		},
+
+		// First attempts first.
+		{
+			0, 0, nil, "", nil,
+			[]*gradingRequest{
+				&gradingRequest{courseID: "A", user: "1"},
+				&gradingRequest{courseID: "A", user: "2"},
+				&gradingRequest{courseID: "B", user: "3", firstAttempt: true},
+				&gradingRequest{courseID: "A", user: "4", firstAttempt: true},
+			},
+			[]string{"A::4", "B::3", "A::1", "A::2"},
+		},
+
+		// Resubmissions that have waited too long are treated like first attempts.
+		{
+			0, 0, nil, "", nil,
+			[]*gradingRequest{
+				&gradingRequest{courseID: "A", user: "1"},
+				&gradingRequest{courseID: "A", user: "2", waitStart: timestamp.Timestamp(1)},
+				&gradingRequest{courseID: "B", user: "3", firstAttempt: true},
+				&gradingRequest{courseID: "A", user: "4", firstAttempt: true},
+			},
+			[]string{"A::2", "B::3", "A::4", "A::1"},
+		},
+
+		// First attempts that cannot run do not block others.
+		{
+			0, 1, map[string]int{"A": 1}, "", nil,
+			[]*gradingRequest{
+				&gradingRequest{courseID: "A", user: "1", firstAttempt: true},
+				&gradingRequest{courseID: "B", user: "2"},
+			},
+			[]string{"B::2"},
+		},
+	}
+
+	oldMax := config.GRADING_MAX_CONCURRENT.Get()
+	defer config.GRADING_MAX_CONCURRENT.Set(oldMax)
+
+	oldCourseMax := config.GRADING_MAX_CONCURRENT_COURSE.Get()
+	defer config.GRADING_MAX_CONCURRENT_COURSE.Set(oldCourseMax)
+
+	for i, testCase := range testCases {
+		config.GRADING_MAX_CONCURRENT.Set(testCase.maxRunning)
+		config.GRADING_MAX_CONCURRENT_COURSE.Set(testCase.maxCourseRunning)
+
+		scheduler := newGradingScheduler()
+		scheduler.lastCourse = testCase.lastCourse
+
+		for courseID, count := range testCase.running {
+			scheduler.running += count
+			scheduler.runningByCourse[courseID] = count
+		}
+
+		for courseID, user := range testCase.lastUsers {
+			scheduler.lastUsers[courseID] = user
+		}
+
+		for _, request := range testCase.waiting {
+			request.ready = make(chan any)
+			if request.waitStart.IsZero() {
+				request.waitStart = timestamp.Now()
+			}
+
+			scheduler.waiting = append(scheduler.waiting, request)
+		}
+
+		// Grant slots the same way schedule() does, but keep track of the order.
+		actual := make([]string, 0, len(testCase.waiting))
+		for request := scheduler.next(); request != nil; request = scheduler.next() {
+			scheduler.grant(request)
+			actual = append(actual, fmt.Sprintf("%s::%s", request.courseID, request.user))
+		}
+
+		if !slices.Equal(testCase.expected, actual) {
+			test.Errorf("Case %d: Unexpected grant order. Expected: '%s', Actual: '%s'.",
+				i, util.MustToJSON(testCase.expected), util.MustToJSON(actual))
+			continue
+		}
+
+		expectedWaiting := len(testCase.waiting) - len(testCase.expected)
+		if expectedWaiting != len(scheduler.waiting) {
+			test.Errorf("Case %d: Unexpected number of waiting requests. Expected: %d, Actual: %d.",
+				i, expectedWaiting, len(scheduler.waiting))
+			continue
+		}
+	}
+}
+
+func TestSchedulerAcquireRelease(test *testing.T) {
+	oldMax := config.GRADING_MAX_CONCURRENT.Get()
+	config.GRADING_MAX_CONCURRENT.Set(1)
+	defer config.GRADING_MAX_CONCURRENT.Set(oldMax)
+
+	scheduler := newGradingScheduler()
+
+	release, err := scheduler.acquire(context.Background(), "A", "1", true)
+	if err != nil {
+		test.Fatalf("Failed to acquire first slot: '%v'.", err)
+	}
+
+	done := make(chan any)
+	go func() {
+		secondRelease, err := scheduler.acquire(context.Background(), "A", "2", true)
+		if err != nil {
+			test.Errorf("Failed to acquire second slot: '%v'.", err)
+		} else {
+			secondRelease()
+		}
+
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		test.Fatalf("Second slot was acquired while the first was still held.")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		test.Fatalf("Second slot was not acquired after the first was released.")
+	}
+
+	if scheduler.running != 0 {
+		test.Fatalf("Unexpected number of running graders. Expected: 0, Actual: %d.", scheduler.running)
+	}
+}
+
+// Under sustained load from first attempts, a waiting resubmission must still get a slot.
+func TestSchedulerResubmissionNotStarved(test *testing.T) {
+	oldMax := config.GRADING_MAX_CONCURRENT.Get()
+	config.GRADING_MAX_CONCURRENT.Set(1)
+	defer config.GRADING_MAX_CONCURRENT.Set(oldMax)
+
+	oldMaxWait := config.GRADING_RESUBMISSION_MAX_WAIT_SECS.Get()
+	config.GRADING_RESUBMISSION_MAX_WAIT_SECS.Set(1)
+	defer config.GRADING_RESUBMISSION_MAX_WAIT_SECS.Set(oldMaxWait)
+
+	scheduler := newGradingScheduler()
+
+	release, err := scheduler.acquire(context.Background(), "A", "first-0", true)
+	if err != nil {
+		test.Fatalf("Failed to acquire first slot: '%v'.", err)
+	}
+
+	done := make(chan any)
+	go func() {
+		resubmissionRelease, err := scheduler.acquire(context.Background(), "A", "resubmission", false)
+		if err != nil {
+			test.Errorf("Failed to acquire resubmission slot: '%v'.", err)
+		} else {
+			resubmissionRelease()
+		}
+
+		close(done)
+	}()
+
+	// Keep a first attempt waiting at all times, and keep handing the slot to the next waiting request.
+	deadline := time.After(5 * time.Second)
+	for i := 1; ; i++ {
+		next := make(chan func(), 1)
+		go func(user string) {
+			nextRelease, err := scheduler.acquire(context.Background(), "A", user, true)
+			if err != nil {
+				test.Errorf("Failed to acquire first attempt slot: '%v'.", err)
+				close(next)
+				return
+			}
+
+			next <- nextRelease
+		}(fmt.Sprintf("first-%d", i))
+
+		// Wait for the new first attempt to be waiting before releasing the slot.
+		for {
+			_, waiting := scheduler.getCounts()
+			if waiting >= 2 {
+				break
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		release()
+
+		select {
+		case <-done:
+			return
+		case <-deadline:
+			test.Fatalf("Resubmission was starved by first attempts.")
+		case release = <-next:
+			if release == nil {
+				return
+			}
+		}
+	}
+}
+
+func TestSchedulerAcquireCanceled(test *testing.T) {
+	oldMax := config.GRADING_MAX_CONCURRENT.Get()
+	config.GRADING_MAX_CONCURRENT.Set(1)
+	defer config.GRADING_MAX_CONCURRENT.Set(oldMax)
+
+	scheduler := newGradingScheduler()
+
+	release, err := scheduler.acquire(context.Background(), "A", "1", true)
+	if err != nil {
+		test.Fatalf("Failed to acquire first slot: '%v'.", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = scheduler.acquire(ctx, "A", "2", true)
+	if err == nil {
+		test.Fatalf("Did not get an error when the context was canceled.")
+	}
+
+	if len(scheduler.waiting) != 0 {
+		test.Fatalf("Canceled request is still waiting.")
+	}
+}
@@ -0,0 +1,109 @@
+package grader
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/tracing"
+)
+
+func TestGradeTracing(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	submissionPath := filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELPATH)
+
+	exporter := tracetest.NewInMemoryExporter()
+	defer tracing.SetExporterForTesting(exporter)()
+
+	ctx, span := tracing.StartSpan(context.Background(), "test.root")
+	traceID := tracing.GetTraceID(ctx)
+
+	options := GetDefaultGradeOptions()
+	options.NoDocker = true
+	options.AllowLate = true
+
+	_, reject, softError, err := Grade(ctx, assignment, submissionPath, "course-student@test.edulinq.org", TEST_MESSAGE, options)
+	span.End()
+
+	if err != nil {
+		test.Fatalf("Failed to grade: '%v'.", err)
+	}
+
+	if reject != nil {
+		test.Fatalf("Submission was rejected: '%s'.", reject.String())
+	}
+
+	if softError != "" {
+		test.Fatalf("Submission got a soft error: '%s'.", softError)
+	}
+
+	expectedNames := []string{"grader.reject", "docker.build", "grader.prep", "grader.run", "grader.save", "grader.grade", "test.root"}
+
+	spans := exporter.GetSpans()
+	if len(spans) != len(expectedNames) {
+		test.Fatalf("Unexpected number of spans. Expected: %d, Actual: %d.", len(expectedNames), len(spans))
+	}
+
+	for i, span := range spans {
+		if span.Name != expectedNames[i] {
+			test.Errorf("Span %d: Unexpected name. Expected: '%s', Actual: '%s'.", i, expectedNames[i], span.Name)
+		}
+
+		if span.SpanContext.TraceID().String() != traceID {
+			test.Errorf("Span %d: Unexpected trace ID. Expected: '%s', Actual: '%s'.", i, traceID, span.SpanContext.TraceID().String())
+		}
+	}
+}
+
+func TestGradeSubmissionLogsTraceID(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	oldValue := log.SetBackgroundLogging(false)
+	defer log.SetBackgroundLogging(oldValue)
+
+	log.SetLevels(log.LevelOff, log.LevelTrace)
+	defer log.SetLevelFatal()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	submissionPath := filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELPATH)
+
+	exporter := tracetest.NewInMemoryExporter()
+	defer tracing.SetExporterForTesting(exporter)()
+
+	ctx, span := tracing.StartSpan(context.Background(), "test.root")
+	traceID := tracing.GetTraceID(ctx)
+
+	options := GetDefaultGradeOptions()
+	options.NoDocker = true
+
+	// The assignment is past due, so the submission will be rejected (and logged).
+	outcome := GradeSubmission(ctx, assignment, submissionPath, "course-student@test.edulinq.org", TEST_MESSAGE, options)
+	span.End()
+
+	if !outcome.Rejected {
+		test.Fatalf("Late submission was not rejected.")
+	}
+
+	query := log.ParsedLogQuery{
+		Level:       log.LevelTrace,
+		MessageText: "Submission rejected.",
+		Attributes:  map[string]string{"trace-id": traceID},
+	}
+
+	records, err := db.GetLogRecords(query)
+	if err != nil {
+		test.Fatalf("Failed to get log records: '%v'.", err)
+	}
+
+	if len(records) != 1 {
+		test.Fatalf("Unexpected number of log records with the trace ID. Expected: 1, Actual: %d.", len(records))
+	}
+}
@@ -0,0 +1,73 @@
+package jwt
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+// The amount of clock skew (in seconds) allowed when checking token times.
+const CLOCK_SKEW_SECS = 60
+
+// Check the standard claims of a token:
+// the issuer, audience, expiration, issued at time, and (if not empty) nonce.
+func CheckClaims(claims map[string]any, issuer string, audience string, nonce string) error {
+	tokenIssuer, _ := claims["iss"].(string)
+	if tokenIssuer != issuer {
+		return fmt.Errorf("Token has the wrong issuer. Expected: '%s', Actual: '%s'.", issuer, tokenIssuer)
+	}
+
+	if !slices.Contains(GetAudience(claims), audience) {
+		return fmt.Errorf("Token was not issued for this audience ('%s').", audience)
+	}
+
+	now := NowSecs()
+
+	expiration, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("Token does not have an expiration time.")
+	}
+
+	if int64(expiration) < (now - CLOCK_SKEW_SECS) {
+		return fmt.Errorf("Token has expired.")
+	}
+
+	issuedAt, ok := claims["iat"].(float64)
+	if ok && (int64(issuedAt) > (now + CLOCK_SKEW_SECS)) {
+		return fmt.Errorf("Token was issued in the future.")
+	}
+
+	if nonce != "" {
+		tokenNonce, _ := claims["nonce"].(string)
+		if tokenNonce != nonce {
+			return fmt.Errorf("Token has the wrong nonce.")
+		}
+	}
+
+	return nil
+}
+
+// The audience claim may be a single string or a list of strings.
+func GetAudience(claims map[string]any) []string {
+	audience := make([]string, 0)
+
+	switch value := claims["aud"].(type) {
+	case string:
+		audience = append(audience, value)
+	case []any:
+		for _, item := range value {
+			text, ok := item.(string)
+			if ok {
+				audience = append(audience, text)
+			}
+		}
+	}
+
+	return audience
+}
+
+// The current time in seconds (the unit JWT times use).
+func NowSecs() int64 {
+	return timestamp.Now().ToMSecs() / 1000
+}
@@ -0,0 +1,119 @@
+// Minimal support for signing and verifying JSON Web Tokens (JWTs).
+// Only RS256 signatures are supported.
+package jwt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const ALGORITHM_RS256 = "RS256"
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// Sign the claims with the given key.
+func Sign(claims map[string]any, key *rsa.PrivateKey, keyID string) (string, error) {
+	tokenHeader := header{
+		Algorithm: ALGORITHM_RS256,
+		Type:      "JWT",
+		KeyID:     keyID,
+	}
+
+	headerJSON, err := util.ToJSON(tokenHeader)
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode JWT header: '%w'.", err)
+	}
+
+	claimsJSON, err := util.ToJSON(claims)
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode JWT claims: '%w'.", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(headerJSON)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claimsJSON))
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("Failed to sign JWT: '%w'.", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verify a token's signature using the key set at the given URL and return its claims.
+// No claims are checked, see CheckClaims().
+func VerifyWithKeySetURL(rawToken string, keySetURL string) (map[string]any, error) {
+	return verify(rawToken, func(keyID string) (*rsa.PublicKey, error) {
+		return getRemoteKey(keySetURL, keyID)
+	})
+}
+
+// Verify a token's signature using the given key and return its claims.
+// No claims are checked, see CheckClaims().
+func VerifyWithKey(rawToken string, key *rsa.PublicKey) (map[string]any, error) {
+	return verify(rawToken, func(keyID string) (*rsa.PublicKey, error) {
+		return key, nil
+	})
+}
+
+func verify(rawToken string, getKey func(string) (*rsa.PublicKey, error)) (map[string]any, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("Token is not a valid JWT.")
+	}
+
+	var tokenHeader header
+	err := decodePart(parts[0], &tokenHeader)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode JWT header: '%w'.", err)
+	}
+
+	if tokenHeader.Algorithm != ALGORITHM_RS256 {
+		return nil, fmt.Errorf("JWT has an unsupported signing algorithm: '%s'.", tokenHeader.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("JWT signature is not valid base64: '%w'.", err)
+	}
+
+	key, err := getKey(tokenHeader.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	if err != nil {
+		return nil, fmt.Errorf("JWT signature is not valid: '%w'.", err)
+	}
+
+	claims := make(map[string]any)
+	err = decodePart(parts[1], &claims)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode JWT claims: '%w'.", err)
+	}
+
+	return claims, nil
+}
+
+func decodePart(part string, target any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("JWT part is not valid base64: '%w'.", err)
+	}
+
+	return util.JSONFromBytes(data, target)
+}
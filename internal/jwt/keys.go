@@ -0,0 +1,139 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// A JSON Web Key (only RSA keys are supported).
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+type JSONWebKeySet struct {
+	Keys []*JSONWebKey `json:"keys"`
+}
+
+// Remote signing keys keyed by key set URL and then key ID.
+var remoteKeys map[string]map[string]*rsa.PublicKey = make(map[string]map[string]*rsa.PublicKey)
+var remoteKeysLock sync.Mutex
+
+func NewJSONWebKey(key *rsa.PublicKey, keyID string) *JSONWebKey {
+	return &JSONWebKey{
+		KeyType:   "RSA",
+		KeyID:     keyID,
+		Use:       "sig",
+		Algorithm: ALGORITHM_RS256,
+		N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func (this *JSONWebKey) ToPublicKey() (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(this.N)
+	if err != nil {
+		return nil, fmt.Errorf("Key modulus is not valid base64: '%w'.", err)
+	}
+
+	eBytes, err := base64.RawURLEncoding.DecodeString(this.E)
+	if err != nil {
+		return nil, fmt.Errorf("Key exponent is not valid base64: '%w'.", err)
+	}
+
+	exponent := new(big.Int).SetBytes(eBytes)
+	if !exponent.IsInt64() || (exponent.Int64() <= 1) || (exponent.Int64() > (1 << 31)) {
+		return nil, fmt.Errorf("Key exponent is out of range.")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+// Get a key from a remote key set.
+// If the key cannot be found, the keys will be refetched once (in case the keys were rotated).
+// An empty ID is allowed when the key set only has a single key.
+func getRemoteKey(keySetURL string, keyID string) (*rsa.PublicKey, error) {
+	remoteKeysLock.Lock()
+	defer remoteKeysLock.Unlock()
+
+	keys, exists := remoteKeys[keySetURL]
+	if exists {
+		key := findKey(keys, keyID)
+		if key != nil {
+			return key, nil
+		}
+	}
+
+	keys, err := fetchKeys(keySetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteKeys[keySetURL] = keys
+
+	key := findKey(keys, keyID)
+	if key == nil {
+		return nil, fmt.Errorf("Could not find signing key '%s' in key set '%s'.", keyID, keySetURL)
+	}
+
+	return key, nil
+}
+
+func fetchKeys(keySetURL string) (map[string]*rsa.PublicKey, error) {
+	body, err := util.Get(keySetURL)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch key set '%s': '%w'.", keySetURL, err)
+	}
+
+	var keySet JSONWebKeySet
+	err = util.JSONFromString(body, &keySet)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse key set '%s': '%w'.", keySetURL, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(keySet.Keys))
+	for _, key := range keySet.Keys {
+		if (key.KeyType != "RSA") || ((key.Use != "") && (key.Use != "sig")) {
+			continue
+		}
+
+		publicKey, err := key.ToPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("Key set '%s' has an invalid signing key '%s': '%w'.", keySetURL, key.KeyID, err)
+		}
+
+		keys[key.KeyID] = publicKey
+	}
+
+	return keys, nil
+}
+
+func findKey(keys map[string]*rsa.PublicKey, keyID string) *rsa.PublicKey {
+	if (keyID == "") && (len(keys) == 1) {
+		for _, key := range keys {
+			return key
+		}
+	}
+
+	return keys[keyID]
+}
+
+// Clear all cached remote keys.
+func ClearKeyCache() {
+	remoteKeysLock.Lock()
+	defer remoteKeysLock.Unlock()
+
+	remoteKeys = make(map[string]map[string]*rsa.PublicKey)
+}
@@ -0,0 +1,51 @@
+package blackboard
+
+import (
+	"fmt"
+	neturl "net/url"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+)
+
+func (this *BlackboardBackend) FetchAssignment(assignmentID string) (*lmstypes.Assignment, error) {
+	if assignmentID == "" {
+		return nil, fmt.Errorf("Cannot fetch assignment, target assignment ID is empty.")
+	}
+
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	endpoint := fmt.Sprintf(
+		"/v2/courses/%s/gradebook/columns/%s",
+		neturl.PathEscape(this.CourseID), neturl.PathEscape(assignmentID))
+
+	var column Column
+	err := this.get(endpoint, &column)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch assignment: '%w'.", err)
+	}
+
+	return column.ToLMSType(this.CourseID), nil
+}
+
+// Assignments are the course's gradebook columns.
+func (this *BlackboardBackend) FetchAssignments() ([]*lmstypes.Assignment, error) {
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	endpoint := fmt.Sprintf(
+		"/v2/courses/%s/gradebook/columns?limit=%d",
+		neturl.PathEscape(this.CourseID), PAGE_SIZE)
+
+	columns, err := getAll[Column](this, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch assignments: '%w'.", err)
+	}
+
+	assignments := make([]*lmstypes.Assignment, 0, len(columns))
+	for _, column := range columns {
+		assignments = append(assignments, column.ToLMSType(this.CourseID))
+	}
+
+	return assignments, nil
+}
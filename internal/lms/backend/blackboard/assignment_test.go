@@ -0,0 +1,53 @@
+package blackboard
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+var dueDate timestamp.Timestamp = timestamp.MustGuessFromString("2023-10-06T06:59:59Z")
+var expectedAssignment lmstypes.Assignment = lmstypes.Assignment{
+	ID:          TEST_ASSIGNMENT_ID,
+	Name:        "Assignment 0",
+	LMSCourseID: TEST_COURSE_ID,
+	DueDate:     &dueDate,
+	MaxPoints:   100.0,
+}
+
+func TestFetchAssignmentBase(test *testing.T) {
+	assignment, err := testBackend.FetchAssignment(TEST_ASSIGNMENT_ID)
+	if err != nil {
+		test.Fatalf("Failed to fetch assignment: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(&expectedAssignment, assignment) {
+		test.Fatalf("Assignment not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expectedAssignment), util.MustToJSONIndent(assignment))
+	}
+}
+
+func TestFetchAssignmentsBase(test *testing.T) {
+	assignments, err := testBackend.FetchAssignments()
+	if err != nil {
+		test.Fatalf("Failed to fetch assignments: '%v'.", err)
+	}
+
+	expected := []*lmstypes.Assignment{
+		&expectedAssignment,
+		&lmstypes.Assignment{
+			ID:          "_98766_1",
+			Name:        "Late Days",
+			LMSCourseID: TEST_COURSE_ID,
+			MaxPoints:   10.0,
+		},
+	}
+
+	if !reflect.DeepEqual(expected, assignments) {
+		test.Fatalf("Assignments not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(assignments))
+	}
+}
@@ -0,0 +1,42 @@
+package blackboard
+
+import (
+	"fmt"
+	"strings"
+)
+
+type BlackboardBackend struct {
+	CourseID string
+	BaseURL  string
+
+	// The REST application's key and secret (used to get access tokens).
+	AppKey    string
+	AppSecret string
+}
+
+// The API token is the REST application's key and secret in the form: "<key>:<secret>".
+func NewBackend(blackboardCourseID string, apiToken string, baseURL string) (*BlackboardBackend, error) {
+	if blackboardCourseID == "" {
+		return nil, fmt.Errorf("Blackboard course ID (course-id) cannot be empty.")
+	}
+
+	appKey, appSecret, ok := strings.Cut(apiToken, ":")
+	if !ok || (appKey == "") || (appSecret == "") {
+		return nil, fmt.Errorf("Blackboard API token (api-token) must be in the form '<application key>:<application secret>'.")
+	}
+
+	if baseURL == "" {
+		return nil, fmt.Errorf("Blackboard base URL (base-url) cannot be empty.")
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	backend := BlackboardBackend{
+		CourseID:  blackboardCourseID,
+		BaseURL:   baseURL,
+		AppKey:    appKey,
+		AppSecret: appSecret,
+	}
+
+	return &backend, nil
+}
@@ -0,0 +1,19 @@
+package blackboard
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+)
+
+// Blackboard grade feedback is rich text that Blackboard may rewrite (e.g., wrap in HTML or sanitize),
+// so it cannot reliably hold the autograder's comments.
+// Instead, comments for Blackboard courses are stored in the autograder's database (see lms.withDBComments()).
+func (this *BlackboardBackend) UpdateComments(assignmentID string, comments []*lmstypes.SubmissionComment) error {
+	return fmt.Errorf("Blackboard does not support updating comments.")
+}
+
+// See UpdateComments().
+func (this *BlackboardBackend) UpdateComment(assignmentID string, comment *lmstypes.SubmissionComment) error {
+	return fmt.Errorf("Blackboard does not support updating comments.")
+}
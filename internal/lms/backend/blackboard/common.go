@@ -0,0 +1,185 @@
+package blackboard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edulinq/autograder/internal/lockmanager"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	API_PATH                   string = "/learn/api/public"
+	TOKEN_PATH                 string = "/learn/api/public/v1/oauth2/token"
+	PAGE_SIZE                  int    = 100
+	UPLOAD_SLEEP_TIME_SEC             = int64(0.5 * float64(time.Second))
+	TOKEN_EXPIRATION_BUFFER_MS        = int64(60 * 1000)
+)
+
+type accessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type accessToken struct {
+	token      string
+	expiration timestamp.Timestamp
+}
+
+// Access tokens keyed by "<base URL>::<application key>".
+var accessTokens map[string]*accessToken = make(map[string]*accessToken)
+var accessTokensLock sync.Mutex
+
+// A page of results from a list endpoint.
+type page[T any] struct {
+	Results []*T `json:"results"`
+	Paging  struct {
+		NextPage string `json:"nextPage"`
+	} `json:"paging"`
+}
+
+func (this *BlackboardBackend) getAPILock() {
+	lockmanager.Lock(this.getLockKey())
+}
+
+func (this *BlackboardBackend) releaseAPILock() {
+	lockmanager.Unlock(this.getLockKey())
+}
+
+// Lock based on the REST application,
+// since Blackboard rate limits by application.
+func (this *BlackboardBackend) getLockKey() string {
+	return fmt.Sprintf("blackboard::%s::%s", this.BaseURL, this.AppKey)
+}
+
+func (this *BlackboardBackend) standardHeaders() (map[string][]string, error) {
+	token, err := this.getAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]string{
+		"Authorization": []string{fmt.Sprintf("Bearer %s", token)},
+		"Accept":        []string{"application/json"},
+	}, nil
+}
+
+// Get an access token (using the OAuth 2 client credentials grant).
+// Tokens are cached until they (almost) expire.
+func (this *BlackboardBackend) getAccessToken() (string, error) {
+	accessTokensLock.Lock()
+	defer accessTokensLock.Unlock()
+
+	cacheKey := fmt.Sprintf("%s::%s", this.BaseURL, this.AppKey)
+	now := timestamp.Now()
+
+	cachedToken, exists := accessTokens[cacheKey]
+	if exists && (cachedToken.expiration.ToMSecs() > (now.ToMSecs() + TOKEN_EXPIRATION_BUFFER_MS)) {
+		return cachedToken.token, nil
+	}
+
+	credentials := base64.StdEncoding.EncodeToString([]byte(this.AppKey + ":" + this.AppSecret))
+	headers := map[string][]string{
+		"Authorization": []string{fmt.Sprintf("Basic %s", credentials)},
+	}
+
+	form := map[string]string{
+		"grant_type": "client_credentials",
+	}
+
+	body, _, err := util.PostWithHeaders(this.BaseURL+TOKEN_PATH, form, headers)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get Blackboard access token: '%w'.", err)
+	}
+
+	var response accessTokenResponse
+	err = util.JSONFromString(body, &response)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse Blackboard access token response: '%w'.", err)
+	}
+
+	if response.AccessToken == "" {
+		return "", fmt.Errorf("Blackboard access token response does not have a token.")
+	}
+
+	accessTokens[cacheKey] = &accessToken{
+		token:      response.AccessToken,
+		expiration: timestamp.FromMSecs(now.ToMSecs() + (response.ExpiresIn * 1000)),
+	}
+
+	return response.AccessToken, nil
+}
+
+func ClearAccessTokens() {
+	accessTokensLock.Lock()
+	defer accessTokensLock.Unlock()
+
+	accessTokens = make(map[string]*accessToken)
+}
+
+// Get a single object from the API.
+func (this *BlackboardBackend) get(endpoint string, target any) error {
+	headers, err := this.standardHeaders()
+	if err != nil {
+		return err
+	}
+
+	body, _, err := util.GetWithHeaders(this.BaseURL+API_PATH+endpoint, headers)
+	if err != nil {
+		return err
+	}
+
+	return util.JSONFromString(body, target)
+}
+
+// Get all the results from a list endpoint (following the next page links).
+func getAll[T any](backend *BlackboardBackend, endpoint string) ([]*T, error) {
+	headers, err := backend.standardHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*T, 0)
+
+	// Next page links are relative to the server.
+	url := backend.BaseURL + API_PATH + endpoint
+	for url != "" {
+		body, _, err := util.GetWithHeaders(url, headers)
+		if err != nil {
+			return nil, err
+		}
+
+		var resultsPage page[T]
+		err = util.JSONFromString(body, &resultsPage)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to unmarshal results page: '%w'.", err)
+		}
+
+		for _, result := range resultsPage.Results {
+			if result != nil {
+				results = append(results, result)
+			}
+		}
+
+		url = ""
+		if resultsPage.Paging.NextPage != "" {
+			url = backend.BaseURL + resultsPage.Paging.NextPage
+		}
+	}
+
+	return results, nil
+}
+
+// Send a JSON body to the API (with PATCH).
+func (this *BlackboardBackend) patch(endpoint string, body any) error {
+	headers, err := this.standardHeaders()
+	if err != nil {
+		return err
+	}
+
+	_, _, err = util.PatchBodyWithHeaders(this.BaseURL+API_PATH+endpoint, "application/json", util.MustToJSON(body), headers)
+	return err
+}
@@ -0,0 +1,110 @@
+package blackboard
+
+import (
+	"strings"
+	"time"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+// A user's membership in a course (with the user expanded).
+type Membership struct {
+	UserID       string `json:"userId"`
+	CourseRoleID string `json:"courseRoleId"`
+	User         *User  `json:"user"`
+}
+
+type User struct {
+	ID       string `json:"id"`
+	UserName string `json:"userName"`
+	Name     struct {
+		Given  string `json:"given"`
+		Family string `json:"family"`
+	} `json:"name"`
+	Contact struct {
+		Email string `json:"email"`
+	} `json:"contact"`
+}
+
+// A gradebook column, which is what scores are attached to.
+type Column struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Score struct {
+		Possible float64 `json:"possible"`
+	} `json:"score"`
+	Grading struct {
+		Due *time.Time `json:"due"`
+	} `json:"grading"`
+}
+
+type Grade struct {
+	UserID   string   `json:"userId"`
+	ColumnID string   `json:"columnId"`
+	Score    *float64 `json:"score"`
+}
+
+// The body for updating a grade.
+type gradeUpdate struct {
+	Score float64 `json:"score"`
+}
+
+// Blackboard (built-in) course roles to autograder roles.
+var roleMapping map[string]model.CourseUserRole = map[string]model.CourseUserRole{
+	"Guest":             model.CourseRoleOther,
+	"CourseBuilder":     model.CourseRoleOther,
+	"Student":           model.CourseRoleStudent,
+	"Grader":            model.CourseRoleGrader,
+	"TeachingAssistant": model.CourseRoleAdmin,
+	"Instructor":        model.CourseRoleOwner,
+}
+
+func (this *Membership) GetRole() model.CourseUserRole {
+	role, ok := roleMapping[this.CourseRoleID]
+	if !ok {
+		return model.CourseRoleOther
+	}
+
+	return role
+}
+
+func (this *Membership) ToLMSType() *lmstypes.User {
+	user := &lmstypes.User{
+		ID:   this.UserID,
+		Role: this.GetRole(),
+	}
+
+	if this.User != nil {
+		user.Name = strings.TrimSpace(this.User.Name.Given + " " + this.User.Name.Family)
+		user.Email = this.User.Contact.Email
+	}
+
+	return user
+}
+
+func (this *Column) ToLMSType(courseID string) *lmstypes.Assignment {
+	return &lmstypes.Assignment{
+		ID:          this.ID,
+		Name:        this.Name,
+		LMSCourseID: courseID,
+		DueDate:     timestamp.FromGoTimePointer(this.Grading.Due),
+		MaxPoints:   this.Score.Possible,
+	}
+}
+
+// Blackboard grade feedback is not used for comments (see lms.withDBComments()),
+// so scores never have comments.
+func (this *Grade) ToLMSType() *lmstypes.SubmissionScore {
+	score := 0.0
+	if this.Score != nil {
+		score = *this.Score
+	}
+
+	return &lmstypes.SubmissionScore{
+		UserID:   this.UserID,
+		Score:    score,
+		Comments: make([]*lmstypes.SubmissionComment, 0),
+	}
+}
@@ -0,0 +1,93 @@
+package blackboard
+
+import (
+	"fmt"
+	neturl "net/url"
+	"time"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+)
+
+func (this *BlackboardBackend) FetchAssignmentScore(assignmentID string, userID string) (*lmstypes.SubmissionScore, error) {
+	if assignmentID == "" {
+		return nil, fmt.Errorf("Cannot fetch assignment score, target assignment ID is empty.")
+	}
+
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	var grade Grade
+	err := this.get(gradeEndpoint(this.CourseID, assignmentID, userID), &grade)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch score: '%w'.", err)
+	}
+
+	return grade.ToLMSType(), nil
+}
+
+func (this *BlackboardBackend) FetchAssignmentScores(assignmentID string) ([]*lmstypes.SubmissionScore, error) {
+	if assignmentID == "" {
+		return nil, fmt.Errorf("Cannot fetch assignment scores, target assignment ID is empty.")
+	}
+
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	endpoint := fmt.Sprintf(
+		"/v2/courses/%s/gradebook/columns/%s/users?limit=%d",
+		neturl.PathEscape(this.CourseID), neturl.PathEscape(assignmentID), PAGE_SIZE)
+
+	grades, err := getAll[Grade](this, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch scores: '%w'.", err)
+	}
+
+	scores := make([]*lmstypes.SubmissionScore, 0, len(grades))
+	for _, grade := range grades {
+		scores = append(scores, grade.ToLMSType())
+	}
+
+	return scores, nil
+}
+
+// Blackboard updates grades one user at a time.
+func (this *BlackboardBackend) UpdateAssignmentScores(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+	if assignmentID == "" {
+		return fmt.Errorf("Cannot update assignment scores, target assignment ID is empty.")
+	}
+
+	for i, score := range scores {
+		if len(score.Comments) > 0 {
+			return fmt.Errorf("Blackboard scores cannot be uploaded with comments. Student '%s' for assignment '%s' has %d.", score.UserID, assignmentID, len(score.Comments))
+		}
+
+		if i != 0 {
+			time.Sleep(time.Duration(UPLOAD_SLEEP_TIME_SEC))
+		}
+
+		err := this.updateAssignmentScore(assignmentID, score)
+		if err != nil {
+			return fmt.Errorf("Failed on score %d: '%w'.", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (this *BlackboardBackend) updateAssignmentScore(assignmentID string, score *lmstypes.SubmissionScore) error {
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	err := this.patch(gradeEndpoint(this.CourseID, assignmentID, score.UserID), gradeUpdate{Score: score.Score})
+	if err != nil {
+		return fmt.Errorf("Failed to upload score for user '%s': '%w'.", score.UserID, err)
+	}
+
+	return nil
+}
+
+func gradeEndpoint(courseID string, assignmentID string, userID string) string {
+	return fmt.Sprintf(
+		"/v2/courses/%s/gradebook/columns/%s/users/%s",
+		neturl.PathEscape(courseID), neturl.PathEscape(assignmentID), neturl.PathEscape(userID))
+}
@@ -0,0 +1,84 @@
+package blackboard
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+var testScore lmstypes.SubmissionScore = lmstypes.SubmissionScore{
+	UserID:   "_40_1",
+	Score:    95.0,
+	Comments: []*lmstypes.SubmissionComment{},
+}
+
+func TestFetchAssignmentScoreBase(test *testing.T) {
+	score, err := testBackend.FetchAssignmentScore(TEST_ASSIGNMENT_ID, "_40_1")
+	if err != nil {
+		test.Fatalf("Failed to fetch assignment score: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(&testScore, score) {
+		test.Fatalf("Score not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(testScore), util.MustToJSONIndent(score))
+	}
+}
+
+func TestFetchAssignmentScoresBase(test *testing.T) {
+	scores, err := testBackend.FetchAssignmentScores(TEST_ASSIGNMENT_ID)
+	if err != nil {
+		test.Fatalf("Failed to fetch assignment scores: '%v'.", err)
+	}
+
+	expected := []*lmstypes.SubmissionScore{
+		&testScore,
+		// Not graded.
+		&lmstypes.SubmissionScore{
+			UserID:   "_41_1",
+			Score:    0.0,
+			Comments: []*lmstypes.SubmissionComment{},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, scores) {
+		test.Fatalf("Scores not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(scores))
+	}
+}
+
+func TestUpdateAssignmentScoresBase(test *testing.T) {
+	scores := []*lmstypes.SubmissionScore{
+		&lmstypes.SubmissionScore{
+			UserID: "_40_1",
+			Score:  90.0,
+		},
+		&lmstypes.SubmissionScore{
+			UserID: "_41_1",
+			Score:  80.5,
+		},
+	}
+
+	err := testBackend.UpdateAssignmentScores(TEST_ASSIGNMENT_ID, scores)
+	if err != nil {
+		test.Fatalf("Failed to update assignment scores: '%v'.", err)
+	}
+}
+
+func TestUpdateAssignmentScoresComments(test *testing.T) {
+	scores := []*lmstypes.SubmissionScore{
+		&lmstypes.SubmissionScore{
+			UserID: "_40_1",
+			Score:  90.0,
+			Comments: []*lmstypes.SubmissionComment{
+				&lmstypes.SubmissionComment{Text: "a"},
+			},
+		},
+	}
+
+	err := testBackend.UpdateAssignmentScores(TEST_ASSIGNMENT_ID, scores)
+	if err == nil {
+		test.Fatalf("Did not get an error when uploading a score with a comment.")
+	}
+}
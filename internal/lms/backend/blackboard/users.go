@@ -0,0 +1,55 @@
+package blackboard
+
+import (
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/log"
+)
+
+func (this *BlackboardBackend) FetchUsers() ([]*lmstypes.User, error) {
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	endpoint := fmt.Sprintf(
+		"/v1/courses/%s/users?expand=user&limit=%d",
+		neturl.PathEscape(this.CourseID), PAGE_SIZE)
+
+	memberships, err := getAll[Membership](this, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch users: '%w'.", err)
+	}
+
+	users := make([]*lmstypes.User, 0, len(memberships))
+	for _, membership := range memberships {
+		users = append(users, membership.ToLMSType())
+	}
+
+	return users, nil
+}
+
+// Blackboard cannot search a course's memberships by email,
+// so all the users are fetched and matched by email.
+func (this *BlackboardBackend) FetchUser(email string) (*lmstypes.User, error) {
+	users, err := this.FetchUsers()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch user '%s': '%w'.", email, err)
+	}
+
+	matches := make([]*lmstypes.User, 0, 1)
+	for _, user := range users {
+		if strings.EqualFold(user.Email, email) {
+			matches = append(matches, user)
+		}
+	}
+
+	if len(matches) != 1 {
+		log.Warn("Did not find exactly one matching user in blackboard.",
+			log.NewAttr("email", email), log.NewAttr("num-results", len(matches)))
+		return nil, nil
+	}
+
+	return matches[0], nil
+}
@@ -0,0 +1,49 @@
+package d2l
+
+import (
+	"fmt"
+	neturl "net/url"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+)
+
+func (this *D2LBackend) FetchAssignment(assignmentID string) (*lmstypes.Assignment, error) {
+	if assignmentID == "" {
+		return nil, fmt.Errorf("Cannot fetch assignment, target assignment ID is empty.")
+	}
+
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	var gradeObject GradeObject
+	err := this.get(this.leURL("/grades/%s", neturl.PathEscape(assignmentID)), &gradeObject)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch assignment: '%w'.", err)
+	}
+
+	return gradeObject.ToLMSType(this.OrgUnitID), nil
+}
+
+// Assignments are the course's numeric grade items.
+// (D2L grade items do not have due dates.)
+func (this *D2LBackend) FetchAssignments() ([]*lmstypes.Assignment, error) {
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	var gradeObjects []*GradeObject
+	err := this.get(this.leURL("/grades/"), &gradeObjects)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch assignments: '%w'.", err)
+	}
+
+	assignments := make([]*lmstypes.Assignment, 0, len(gradeObjects))
+	for _, gradeObject := range gradeObjects {
+		if (gradeObject == nil) || (gradeObject.GradeType != GRADE_TYPE_NUMERIC) {
+			continue
+		}
+
+		assignments = append(assignments, gradeObject.ToLMSType(this.OrgUnitID))
+	}
+
+	return assignments, nil
+}
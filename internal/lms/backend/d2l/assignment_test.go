@@ -0,0 +1,51 @@
+package d2l
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+var expectedAssignment lmstypes.Assignment = lmstypes.Assignment{
+	ID:          TEST_ASSIGNMENT_ID,
+	Name:        "Assignment 0",
+	LMSCourseID: TEST_COURSE_ID,
+	MaxPoints:   100.0,
+}
+
+func TestFetchAssignmentBase(test *testing.T) {
+	assignment, err := testBackend.FetchAssignment(TEST_ASSIGNMENT_ID)
+	if err != nil {
+		test.Fatalf("Failed to fetch assignment: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(&expectedAssignment, assignment) {
+		test.Fatalf("Assignment not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expectedAssignment), util.MustToJSONIndent(assignment))
+	}
+}
+
+// Non-numeric grade items are skipped.
+func TestFetchAssignmentsBase(test *testing.T) {
+	assignments, err := testBackend.FetchAssignments()
+	if err != nil {
+		test.Fatalf("Failed to fetch assignments: '%v'.", err)
+	}
+
+	expected := []*lmstypes.Assignment{
+		&expectedAssignment,
+		&lmstypes.Assignment{
+			ID:          "98766",
+			Name:        "Late Days",
+			LMSCourseID: TEST_COURSE_ID,
+			MaxPoints:   10.0,
+		},
+	}
+
+	if !reflect.DeepEqual(expected, assignments) {
+		test.Fatalf("Assignments not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(assignments))
+	}
+}
@@ -0,0 +1,38 @@
+package d2l
+
+import (
+	"fmt"
+	"strings"
+)
+
+type D2LBackend struct {
+	OrgUnitID string
+	APIToken  string
+	BaseURL   string
+}
+
+// The course ID is the course offering's org unit ID,
+// and the API token is an OAuth 2 access token.
+func NewBackend(orgUnitID string, apiToken string, baseURL string) (*D2LBackend, error) {
+	if orgUnitID == "" {
+		return nil, fmt.Errorf("D2L course ID (course-id) cannot be empty.")
+	}
+
+	if apiToken == "" {
+		return nil, fmt.Errorf("D2L API token (api-token) cannot be empty.")
+	}
+
+	if baseURL == "" {
+		return nil, fmt.Errorf("D2L base URL (base-url) cannot be empty.")
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	backend := D2LBackend{
+		OrgUnitID: orgUnitID,
+		APIToken:  apiToken,
+		BaseURL:   baseURL,
+	}
+
+	return &backend, nil
+}
@@ -0,0 +1,52 @@
+package d2l
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+)
+
+func (this *D2LBackend) UpdateComments(assignmentID string, comments []*lmstypes.SubmissionComment) error {
+	if assignmentID == "" {
+		return fmt.Errorf("Cannot update comments, target assignment ID is empty.")
+	}
+
+	for i, comment := range comments {
+		if i != 0 {
+			time.Sleep(time.Duration(UPLOAD_SLEEP_TIME_SEC))
+		}
+
+		err := this.UpdateComment(assignmentID, comment)
+		if err != nil {
+			return fmt.Errorf("Failed on comment %d: '%w'.", i, err)
+		}
+	}
+
+	return nil
+}
+
+// A D2L comment is a part of the grade (and identified by the user's ID),
+// so the comment is updated by re-saving the user's current grade with the new comment.
+func (this *D2LBackend) UpdateComment(assignmentID string, comment *lmstypes.SubmissionComment) error {
+	if assignmentID == "" {
+		return fmt.Errorf("Cannot update comment, target assignment ID is empty.")
+	}
+
+	this.getAPILock()
+	value, err := this.fetchGradeValue(assignmentID, comment.ID)
+	this.releaseAPILock()
+
+	if err != nil {
+		return fmt.Errorf("Failed to fetch current score for comment: '%w'.", err)
+	}
+
+	score := value.ToLMSType(comment.ID).Score
+
+	err = this.putGradeValue(assignmentID, comment.ID, score, comment.Text, value.GetPrivateComment())
+	if err != nil {
+		return fmt.Errorf("Failed to update comment: '%w'.", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package d2l
+
+import (
+	"fmt"
+	neturl "net/url"
+	"time"
+
+	"github.com/edulinq/autograder/internal/lockmanager"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	LP_VERSION            string = "1.43"
+	LE_VERSION            string = "1.70"
+	UPLOAD_SLEEP_TIME_SEC        = int64(0.5 * float64(time.Second))
+)
+
+func (this *D2LBackend) getAPILock() {
+	lockmanager.Lock(this.getLockKey())
+}
+
+func (this *D2LBackend) releaseAPILock() {
+	lockmanager.Unlock(this.getLockKey())
+}
+
+// Lock based on the API token (like Canvas).
+func (this *D2LBackend) getLockKey() string {
+	return fmt.Sprintf("d2l::%s", this.APIToken)
+}
+
+func (this *D2LBackend) standardHeaders() map[string][]string {
+	return map[string][]string{
+		"Authorization": []string{fmt.Sprintf("Bearer %s", this.APIToken)},
+		"Accept":        []string{"application/json"},
+	}
+}
+
+// Get a URL for the Learning Environment (LE) API within the course.
+func (this *D2LBackend) leURL(format string, args ...any) string {
+	return fmt.Sprintf("%s/d2l/api/le/%s/%s", this.BaseURL, LE_VERSION, neturl.PathEscape(this.OrgUnitID)) + fmt.Sprintf(format, args...)
+}
+
+// Get a URL for the Learning Platform (LP) API.
+func (this *D2LBackend) lpURL(format string, args ...any) string {
+	return fmt.Sprintf("%s/d2l/api/lp/%s", this.BaseURL, LP_VERSION) + fmt.Sprintf(format, args...)
+}
+
+func (this *D2LBackend) get(url string, target any) error {
+	body, _, err := util.GetWithHeaders(url, this.standardHeaders())
+	if err != nil {
+		return err
+	}
+
+	return util.JSONFromString(body, target)
+}
+
+func (this *D2LBackend) put(url string, body any) error {
+	_, _, err := util.PutBodyWithHeaders(url, "application/json", util.MustToJSON(body), this.standardHeaders())
+	return err
+}
+
+// Next page links are full URLs, make sure they point to the configured server.
+func (this *D2LBackend) rebaseLink(url string) (string, error) {
+	parsed, err := neturl.Parse(url)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse URL '%s': '%w'.", url, err)
+	}
+
+	url = this.BaseURL + parsed.EscapedPath()
+	if parsed.RawQuery != "" {
+		url += "?" + parsed.RawQuery
+	}
+
+	return url, nil
+}
@@ -0,0 +1,181 @@
+package d2l
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+const (
+	GRADE_TYPE_NUMERIC        = "Numeric"
+	GRADE_OBJECT_TYPE_NUMERIC = 1
+	TEXT_TYPE_TEXT            = "Text"
+)
+
+type User struct {
+	Identifier   string `json:"Identifier"`
+	DisplayName  string `json:"DisplayName"`
+	EmailAddress string `json:"EmailAddress"`
+}
+
+type Role struct {
+	ID   int64  `json:"Id"`
+	Name string `json:"Name"`
+}
+
+type OrgUnitUser struct {
+	User *User `json:"User"`
+	Role *Role `json:"Role"`
+}
+
+type usersPage struct {
+	PagingInfo struct {
+		Bookmark     string `json:"Bookmark"`
+		HasMoreItems bool   `json:"HasMoreItems"`
+	} `json:"PagingInfo"`
+	Items []*OrgUnitUser `json:"Items"`
+}
+
+type GradeObject struct {
+	ID        int64   `json:"Id"`
+	Name      string  `json:"Name"`
+	GradeType string  `json:"GradeType"`
+	MaxPoints float64 `json:"MaxPoints"`
+}
+
+type RichText struct {
+	Text string `json:"Text"`
+	HTML string `json:"Html"`
+}
+
+type GradeValue struct {
+	PointsNumerator *float64   `json:"PointsNumerator"`
+	Comments        *RichText  `json:"Comments"`
+	PrivateComments *RichText  `json:"PrivateComments"`
+	LastModified    *time.Time `json:"LastModified"`
+}
+
+type UserGradeValue struct {
+	User       *User       `json:"User"`
+	GradeValue *GradeValue `json:"GradeValue"`
+}
+
+type gradeValuesPage struct {
+	Next    *string           `json:"Next"`
+	Objects []*UserGradeValue `json:"Objects"`
+}
+
+type RichTextInput struct {
+	Content string `json:"Content"`
+	Type    string `json:"Type"`
+}
+
+// The body for updating a grade value.
+// Comments are always replaced, so existing comments need to be sent to be preserved.
+type IncomingGradeValue struct {
+	Comments        RichTextInput `json:"Comments"`
+	PrivateComments RichTextInput `json:"PrivateComments"`
+	GradeObjectType int           `json:"GradeObjectType"`
+	PointsNumerator float64       `json:"PointsNumerator"`
+}
+
+// D2L role names are configured by each organization.
+// These are the common (lower-cased) names mapped to autograder roles.
+var roleMapping map[string]model.CourseUserRole = map[string]model.CourseUserRole{
+	"learner":            model.CourseRoleStudent,
+	"student":            model.CourseRoleStudent,
+	"grader":             model.CourseRoleGrader,
+	"ta":                 model.CourseRoleGrader,
+	"teaching assistant": model.CourseRoleGrader,
+	"administrator":      model.CourseRoleAdmin,
+	"instructor":         model.CourseRoleOwner,
+}
+
+func (this *OrgUnitUser) GetRole() model.CourseUserRole {
+	if this.Role == nil {
+		return model.CourseRoleOther
+	}
+
+	role, ok := roleMapping[strings.ToLower(strings.TrimSpace(this.Role.Name))]
+	if !ok {
+		return model.CourseRoleOther
+	}
+
+	return role
+}
+
+func (this *OrgUnitUser) ToLMSType() *lmstypes.User {
+	user := &lmstypes.User{
+		Role: this.GetRole(),
+	}
+
+	if this.User != nil {
+		user.ID = this.User.Identifier
+		user.Name = this.User.DisplayName
+		user.Email = this.User.EmailAddress
+	}
+
+	return user
+}
+
+func (this *GradeObject) ToLMSType(orgUnitID string) *lmstypes.Assignment {
+	return &lmstypes.Assignment{
+		ID:          formatID(this.ID),
+		Name:        this.Name,
+		LMSCourseID: orgUnitID,
+		MaxPoints:   this.MaxPoints,
+	}
+}
+
+func (this *GradeValue) GetComment() string {
+	if (this == nil) || (this.Comments == nil) {
+		return ""
+	}
+
+	return this.Comments.Text
+}
+
+func (this *GradeValue) GetPrivateComment() string {
+	if (this == nil) || (this.PrivateComments == nil) {
+		return ""
+	}
+
+	return this.PrivateComments.Text
+}
+
+// A grade has a single (public) comment, so the comment is identified by the user's ID.
+// A nil grade value (the user has not been graded) is allowed.
+func (this *GradeValue) ToLMSType(userID string) *lmstypes.SubmissionScore {
+	score := &lmstypes.SubmissionScore{
+		UserID:   userID,
+		Comments: make([]*lmstypes.SubmissionComment, 0, 1),
+	}
+
+	if this == nil {
+		return score
+	}
+
+	if this.PointsNumerator != nil {
+		score.Score = *this.PointsNumerator
+	}
+
+	score.Time = timestamp.FromGoTimePointer(this.LastModified)
+
+	comment := this.GetComment()
+	if comment != "" {
+		score.Comments = append(score.Comments, &lmstypes.SubmissionComment{
+			ID:   userID,
+			Text: comment,
+		})
+	}
+
+	return score
+}
+
+func formatID(id int64) string {
+	return fmt.Sprintf("%d", id)
+}
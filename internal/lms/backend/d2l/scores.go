@@ -0,0 +1,159 @@
+package d2l
+
+import (
+	"fmt"
+	neturl "net/url"
+	"time"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+)
+
+func (this *D2LBackend) FetchAssignmentScore(assignmentID string, userID string) (*lmstypes.SubmissionScore, error) {
+	if assignmentID == "" {
+		return nil, fmt.Errorf("Cannot fetch assignment score, target assignment ID is empty.")
+	}
+
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	value, err := this.fetchGradeValue(assignmentID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch score: '%w'.", err)
+	}
+
+	return value.ToLMSType(userID), nil
+}
+
+func (this *D2LBackend) FetchAssignmentScores(assignmentID string) ([]*lmstypes.SubmissionScore, error) {
+	if assignmentID == "" {
+		return nil, fmt.Errorf("Cannot fetch assignment scores, target assignment ID is empty.")
+	}
+
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	values, err := this.fetchGradeValues(assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch scores: '%w'.", err)
+	}
+
+	scores := make([]*lmstypes.SubmissionScore, 0, len(values))
+	for _, value := range values {
+		scores = append(scores, value.GradeValue.ToLMSType(value.User.Identifier))
+	}
+
+	return scores, nil
+}
+
+// D2L updates grades one user at a time.
+// Since an update replaces a grade's comments, existing comments are kept for scores without a comment.
+func (this *D2LBackend) UpdateAssignmentScores(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+	if assignmentID == "" {
+		return fmt.Errorf("Cannot update assignment scores, target assignment ID is empty.")
+	}
+
+	this.getAPILock()
+	values, err := this.fetchGradeValues(assignmentID)
+	this.releaseAPILock()
+
+	if err != nil {
+		return fmt.Errorf("Failed to fetch existing scores: '%w'.", err)
+	}
+
+	existingValues := make(map[string]*GradeValue, len(values))
+	for _, value := range values {
+		existingValues[value.User.Identifier] = value.GradeValue
+	}
+
+	for i, score := range scores {
+		if len(score.Comments) > 1 {
+			return fmt.Errorf("Scores to upload can have at most one comment. Student '%s' for assignment '%s' has %d.", score.UserID, assignmentID, len(score.Comments))
+		}
+
+		existingValue := existingValues[score.UserID]
+
+		comment := existingValue.GetComment()
+		for _, newComment := range score.Comments {
+			comment = newComment.Text
+		}
+
+		if i != 0 {
+			time.Sleep(time.Duration(UPLOAD_SLEEP_TIME_SEC))
+		}
+
+		err = this.putGradeValue(assignmentID, score.UserID, score.Score, comment, existingValue.GetPrivateComment())
+		if err != nil {
+			return fmt.Errorf("Failed on score %d: '%w'.", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Get all the (non-nil) user grade values for a grade item.
+func (this *D2LBackend) fetchGradeValues(assignmentID string) ([]*UserGradeValue, error) {
+	url := this.leURL("/grades/%s/values/", neturl.PathEscape(assignmentID))
+
+	values := make([]*UserGradeValue, 0)
+
+	for url != "" {
+		var page gradeValuesPage
+		err := this.get(url, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, value := range page.Objects {
+			if (value == nil) || (value.User == nil) {
+				continue
+			}
+
+			values = append(values, value)
+		}
+
+		url = ""
+		if (page.Next != nil) && (*page.Next != "") {
+			url, err = this.rebaseLink(*page.Next)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return values, nil
+}
+
+func (this *D2LBackend) fetchGradeValue(assignmentID string, userID string) (*GradeValue, error) {
+	var value GradeValue
+	err := this.get(this.leURL("/grades/%s/values/%s", neturl.PathEscape(assignmentID), neturl.PathEscape(userID)), &value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+func (this *D2LBackend) putGradeValue(assignmentID string, userID string, score float64, comment string, privateComment string) error {
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	value := IncomingGradeValue{
+		Comments: RichTextInput{
+			Content: comment,
+			Type:    TEXT_TYPE_TEXT,
+		},
+		PrivateComments: RichTextInput{
+			Content: privateComment,
+			Type:    TEXT_TYPE_TEXT,
+		},
+		GradeObjectType: GRADE_OBJECT_TYPE_NUMERIC,
+		PointsNumerator: score,
+	}
+
+	err := this.put(this.leURL("/grades/%s/values/%s", neturl.PathEscape(assignmentID), neturl.PathEscape(userID)), value)
+	if err != nil {
+		return fmt.Errorf("Failed to upload score for user '%s': '%w'.", userID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package d2l
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const testCommentText string = "{\n\"id\": \"course101::hw0::course-student@test.edulinq.org::1696364768\",\n\"score\": 100,\n\"__autograder__v01__\": 0\n}"
+
+var gradeTime timestamp.Timestamp = timestamp.MustGuessFromString("2023-10-03T20:26:08Z")
+var otherGradeTime timestamp.Timestamp = timestamp.MustGuessFromString("2023-10-04T20:26:08Z")
+
+var testScore lmstypes.SubmissionScore = lmstypes.SubmissionScore{
+	UserID: "40",
+	Score:  100.0,
+	Time:   &gradeTime,
+	Comments: []*lmstypes.SubmissionComment{
+		&lmstypes.SubmissionComment{
+			ID:   "40",
+			Text: testCommentText,
+		},
+	},
+}
+
+func TestFetchAssignmentScoreBase(test *testing.T) {
+	score, err := testBackend.FetchAssignmentScore(TEST_ASSIGNMENT_ID, "40")
+	if err != nil {
+		test.Fatalf("Failed to fetch assignment score: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(&testScore, score) {
+		test.Fatalf("Score not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(testScore), util.MustToJSONIndent(score))
+	}
+}
+
+// Scores are split over two pages.
+func TestFetchAssignmentScoresBase(test *testing.T) {
+	scores, err := testBackend.FetchAssignmentScores(TEST_ASSIGNMENT_ID)
+	if err != nil {
+		test.Fatalf("Failed to fetch assignment scores: '%v'.", err)
+	}
+
+	expected := []*lmstypes.SubmissionScore{
+		&testScore,
+		// Not graded.
+		&lmstypes.SubmissionScore{
+			UserID:   "41",
+			Score:    0.0,
+			Comments: []*lmstypes.SubmissionComment{},
+		},
+		&lmstypes.SubmissionScore{
+			UserID:   "42",
+			Score:    75.0,
+			Time:     &otherGradeTime,
+			Comments: []*lmstypes.SubmissionComment{},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, scores) {
+		test.Fatalf("Scores not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(scores))
+	}
+}
+
+// Existing comments (public and private) are kept when a score does not have a comment.
+func TestUpdateAssignmentScoresBase(test *testing.T) {
+	scores := []*lmstypes.SubmissionScore{
+		&lmstypes.SubmissionScore{
+			UserID: "40",
+			Score:  95.0,
+		},
+		&lmstypes.SubmissionScore{
+			UserID: "41",
+			Score:  80.5,
+		},
+		&lmstypes.SubmissionScore{
+			UserID: "42",
+			Score:  70.0,
+			Comments: []*lmstypes.SubmissionComment{
+				&lmstypes.SubmissionComment{
+					Text: "new comment",
+				},
+			},
+		},
+	}
+
+	err := testBackend.UpdateAssignmentScores(TEST_ASSIGNMENT_ID, scores)
+	if err != nil {
+		test.Fatalf("Failed to update assignment scores: '%v'.", err)
+	}
+}
+
+func TestUpdateCommentBase(test *testing.T) {
+	comment := &lmstypes.SubmissionComment{
+		ID:   "42",
+		Text: "updated",
+	}
+
+	err := testBackend.UpdateComment(TEST_ASSIGNMENT_ID, comment)
+	if err != nil {
+		test.Fatalf("Failed to update comment: '%v'.", err)
+	}
+}
@@ -0,0 +1,67 @@
+package d2l
+
+import (
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/log"
+)
+
+func (this *D2LBackend) FetchUsers() ([]*lmstypes.User, error) {
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	baseURL := this.lpURL("/enrollments/orgUnits/%s/users/", neturl.PathEscape(this.OrgUnitID))
+	url := baseURL
+
+	users := make([]*lmstypes.User, 0)
+
+	for url != "" {
+		var page usersPage
+		err := this.get(url, &page)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to fetch users: '%w'.", err)
+		}
+
+		for _, user := range page.Items {
+			if (user == nil) || (user.User == nil) {
+				continue
+			}
+
+			users = append(users, user.ToLMSType())
+		}
+
+		url = ""
+		if page.PagingInfo.HasMoreItems && (page.PagingInfo.Bookmark != "") {
+			url = baseURL + "?bookmark=" + neturl.QueryEscape(page.PagingInfo.Bookmark)
+		}
+	}
+
+	return users, nil
+}
+
+// D2L cannot search a course's enrollments by email,
+// so all the users are fetched and matched by email.
+func (this *D2LBackend) FetchUser(email string) (*lmstypes.User, error) {
+	users, err := this.FetchUsers()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch user '%s': '%w'.", email, err)
+	}
+
+	matches := make([]*lmstypes.User, 0, 1)
+	for _, user := range users {
+		if strings.EqualFold(user.Email, email) {
+			matches = append(matches, user)
+		}
+	}
+
+	if len(matches) != 1 {
+		log.Warn("Did not find exactly one matching user in d2l.",
+			log.NewAttr("email", email), log.NewAttr("num-results", len(matches)))
+		return nil, nil
+	}
+
+	return matches[0], nil
+}
@@ -0,0 +1,82 @@
+package d2l
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+var expectedUsers []*lmstypes.User = []*lmstypes.User{
+	&lmstypes.User{
+		ID:    "10",
+		Name:  "course-owner",
+		Email: "course-owner@test.edulinq.org",
+		Role:  model.CourseRoleOwner,
+	},
+	&lmstypes.User{
+		ID:    "20",
+		Name:  "course-admin",
+		Email: "course-admin@test.edulinq.org",
+		Role:  model.CourseRoleAdmin,
+	},
+	&lmstypes.User{
+		ID:    "30",
+		Name:  "course-grader",
+		Email: "course-grader@test.edulinq.org",
+		Role:  model.CourseRoleGrader,
+	},
+	&lmstypes.User{
+		ID:    "40",
+		Name:  "course-student",
+		Email: "course-student@test.edulinq.org",
+		Role:  model.CourseRoleStudent,
+	},
+	&lmstypes.User{
+		ID:    "50",
+		Name:  "course-other",
+		Email: "course-other@test.edulinq.org",
+		Role:  model.CourseRoleOther,
+	},
+}
+
+func TestD2LUserGetBase(test *testing.T) {
+	testCases := []struct {
+		email    string
+		expected *lmstypes.User
+	}{
+		{"course-owner@test.edulinq.org", expectedUsers[0]},
+		{"course-grader@test.edulinq.org", expectedUsers[2]},
+		{"Course-Student@test.edulinq.org", expectedUsers[3]},
+		{"zzz@test.edulinq.org", nil},
+	}
+
+	for i, testCase := range testCases {
+		user, err := testBackend.FetchUser(testCase.email)
+		if err != nil {
+			test.Errorf("Case %d: Failed to fetch user: '%v'.", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(testCase.expected, user) {
+			test.Errorf("Case %d: User not as expected. Expected: '%+v', Actual: '%+v'.",
+				i, util.MustToJSONIndent(testCase.expected), util.MustToJSONIndent(user))
+			continue
+		}
+	}
+}
+
+// Users are split over two pages.
+func TestD2LUsersGetBase(test *testing.T) {
+	users, err := testBackend.FetchUsers()
+	if err != nil {
+		test.Fatalf("Failed to fetch users: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expectedUsers, users) {
+		test.Fatalf("Users not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expectedUsers), util.MustToJSONIndent(users))
+	}
+}
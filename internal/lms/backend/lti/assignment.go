@@ -0,0 +1,102 @@
+package lti
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/lti"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+var lineItemScopes []string = []string{lti.SCOPE_AGS_LINE_ITEM_READONLY}
+
+// Assignment IDs are line item URLs.
+func (this *LTIBackend) FetchAssignment(assignmentID string) (*lmstypes.Assignment, error) {
+	lineItem, err := this.fetchLineItem(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.toLMSAssignment(lineItem), nil
+}
+
+func (this *LTIBackend) FetchAssignments() ([]*lmstypes.Assignment, error) {
+	url := this.Options.LineItemsURL
+	if url == "" {
+		return nil, fmt.Errorf("LTI option 'line-items-url' is required to fetch assignments.")
+	}
+
+	assignments := make([]*lmstypes.Assignment, 0)
+
+	for url != "" {
+		body, responseHeaders, err := this.get(url, lti.CONTENT_TYPE_LINE_ITEM_CONTAINER, lineItemScopes)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to fetch line items: '%w'.", err)
+		}
+
+		var lineItems []*lti.LineItem
+		err = util.JSONFromString(body, &lineItems)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to unmarshal line items page: '%w'.", err)
+		}
+
+		for _, lineItem := range lineItems {
+			if lineItem == nil {
+				continue
+			}
+
+			assignments = append(assignments, this.toLMSAssignment(lineItem))
+		}
+
+		url = fetchNextLink(responseHeaders)
+	}
+
+	return assignments, nil
+}
+
+func (this *LTIBackend) fetchLineItem(lineItemID string) (*lti.LineItem, error) {
+	if lineItemID == "" {
+		return nil, fmt.Errorf("Cannot fetch line item, target line item ID is empty.")
+	}
+
+	body, _, err := this.get(lineItemID, lti.CONTENT_TYPE_LINE_ITEM, lineItemScopes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch line item: '%w'.", err)
+	}
+
+	var lineItem lti.LineItem
+	err = util.JSONFromString(body, &lineItem)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal line item: '%w'.", err)
+	}
+
+	if lineItem.ID == "" {
+		lineItem.ID = lineItemID
+	}
+
+	return &lineItem, nil
+}
+
+func (this *LTIBackend) toLMSAssignment(lineItem *lti.LineItem) *lmstypes.Assignment {
+	var dueDate *timestamp.Timestamp = nil
+	if lineItem.EndDateTime != "" {
+		instance, err := time.Parse(time.RFC3339, lineItem.EndDateTime)
+		if err != nil {
+			log.Warn("Failed to parse line item end date.", err,
+				log.NewAttr("line-item", lineItem.ID), log.NewAttr("end-date", lineItem.EndDateTime))
+		} else {
+			dueDate = timestamp.FromGoTimePointer(&instance)
+		}
+	}
+
+	return &lmstypes.Assignment{
+		ID:          lineItem.ID,
+		Name:        lineItem.Label,
+		LMSCourseID: this.CourseID,
+		DueDate:     dueDate,
+		MaxPoints:   lineItem.ScoreMaximum,
+	}
+}
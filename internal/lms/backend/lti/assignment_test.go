@@ -0,0 +1,78 @@
+package lti
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/lti"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestFetchAssignmentsBase(test *testing.T) {
+	id := testPlatform.AddLineItem("Assignment Fetch", 20)
+
+	expected := &lmstypes.Assignment{
+		ID:          id,
+		Name:        "Assignment Fetch",
+		LMSCourseID: lti.TEST_CONTEXT_ID,
+		MaxPoints:   20,
+	}
+
+	assignment, err := testBackend.FetchAssignment(id)
+	if err != nil {
+		test.Fatalf("Failed to fetch assignment: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, assignment) {
+		test.Fatalf("Assignment not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(assignment))
+	}
+
+	assignments, err := testBackend.FetchAssignments()
+	if err != nil {
+		test.Fatalf("Failed to fetch assignments: '%v'.", err)
+	}
+
+	found := false
+	for _, assignment := range assignments {
+		if assignment.ID == id {
+			found = true
+
+			if !reflect.DeepEqual(expected, assignment) {
+				test.Fatalf("Listed assignment not as expected. Expected: '%s', Actual: '%s'.",
+					util.MustToJSONIndent(expected), util.MustToJSONIndent(assignment))
+			}
+		}
+	}
+
+	if !found {
+		test.Fatalf("Could not find line item '%s' in assignments: '%s'.", id, util.MustToJSONIndent(assignments))
+	}
+}
+
+func TestFetchAssignmentsErrors(test *testing.T) {
+	_, err := testBackend.FetchAssignment(testPlatform.Issuer() + "/lineitems/ZZZ")
+	if err == nil {
+		test.Fatalf("Did not get an error for a missing line item.")
+	}
+
+	_, err = testBackend.FetchAssignment("")
+	if err == nil {
+		test.Fatalf("Did not get an error for an empty line item.")
+	}
+
+	options := *testBackend.Options
+	options.LineItemsURL = ""
+
+	backend, err := NewBackend(lti.TEST_CONTEXT_ID, &options)
+	if err != nil {
+		test.Fatalf("Failed to create backend: '%v'.", err)
+	}
+
+	_, err = backend.FetchAssignments()
+	if (err == nil) || !strings.Contains(err.Error(), "line-items-url") {
+		test.Fatalf("Did not get the expected error for a missing line items URL: '%v'.", err)
+	}
+}
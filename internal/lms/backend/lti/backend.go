@@ -0,0 +1,33 @@
+// An LMS backend that uses the LTI Advantage services:
+// Assignment and Grade Services (AGS) for assignments (line items) and scores,
+// and Names and Role Provisioning Services (NRPS) for users.
+package lti
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/model"
+)
+
+type LTIBackend struct {
+	// The LTI context ID.
+	CourseID string
+	Options  *model.LTIOptions
+}
+
+func NewBackend(contextID string, options *model.LTIOptions) (*LTIBackend, error) {
+	if contextID == "" {
+		return nil, fmt.Errorf("LTI context ID (course-id) cannot be empty.")
+	}
+
+	if options == nil {
+		return nil, fmt.Errorf("LTI options (lti) cannot be empty.")
+	}
+
+	backend := LTIBackend{
+		CourseID: contextID,
+		Options:  options,
+	}
+
+	return &backend, nil
+}
@@ -0,0 +1,16 @@
+package lti
+
+import (
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+)
+
+// AGS does not have standalone comments, a comment can only be sent along with a score.
+// Since all score uploads already include their comment, updating comments is a no-op.
+func (this *LTIBackend) UpdateComments(assignmentID string, comments []*lmstypes.SubmissionComment) error {
+	return nil
+}
+
+// See UpdateComments().
+func (this *LTIBackend) UpdateComment(assignmentID string, comment *lmstypes.SubmissionComment) error {
+	return nil
+}
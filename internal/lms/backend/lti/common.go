@@ -0,0 +1,105 @@
+package lti
+
+import (
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/lti"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const HEADER_LINK string = "Link"
+
+// Make an authenticated GET request to an LTI service.
+// Returns: (body, headers (response), error)
+func (this *LTIBackend) get(url string, accept string, scopes []string) (string, map[string][]string, error) {
+	headers, err := this.standardHeaders(accept, scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return util.GetWithHeaders(url, headers)
+}
+
+// Make an authenticated POST request (with a JSON body) to an LTI service.
+func (this *LTIBackend) post(url string, contentType string, body any, scopes []string) error {
+	headers, err := this.standardHeaders("", scopes)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = util.PostBodyWithHeaders(url, contentType, util.MustToJSON(body), headers)
+	return err
+}
+
+func (this *LTIBackend) standardHeaders(accept string, scopes []string) (map[string][]string, error) {
+	token, err := lti.GetAccessToken(this.Options, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string][]string{
+		"Authorization": []string{fmt.Sprintf("Bearer %s", token)},
+	}
+
+	if accept != "" {
+		headers["Accept"] = []string{accept}
+	}
+
+	return headers, nil
+}
+
+// Add a path suffix to a service URL (which may have a query string).
+// E.g., the results for a line item are at "<line item URL path>/results".
+func addPathSuffix(url string, suffix string) (string, error) {
+	parsed, err := neturl.Parse(url)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse LTI service URL '%s': '%w'.", url, err)
+	}
+
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/") + suffix
+
+	return parsed.String(), nil
+}
+
+// Add a query parameter to a service URL (which may already have a query string).
+func addQueryParam(url string, key string, value string) (string, error) {
+	parsed, err := neturl.Parse(url)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse LTI service URL '%s': '%w'.", url, err)
+	}
+
+	query := parsed.Query()
+	query.Set(key, value)
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String(), nil
+}
+
+// See if the response headers have a next link.
+// Returns the link or an empty string.
+func fetchNextLink(headers map[string][]string) string {
+	values, ok := headers[HEADER_LINK]
+	if !ok {
+		return ""
+	}
+
+	for _, value := range values {
+		links := strings.Split(value, ",")
+		for _, link := range links {
+			parts := strings.Split(link, ";")
+			if len(parts) < 2 {
+				continue
+			}
+
+			for _, param := range parts[1:] {
+				if strings.TrimSpace(param) == `rel="next"` {
+					return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+				}
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,48 @@
+package lti
+
+import (
+	"testing"
+)
+
+func TestAddPathSuffix(test *testing.T) {
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://lms.edulinq.org/lineitems/1", "https://lms.edulinq.org/lineitems/1/results"},
+		{"https://lms.edulinq.org/lineitems/1/", "https://lms.edulinq.org/lineitems/1/results"},
+		{"https://lms.edulinq.org/lineitems/1/lineitem?type_id=2", "https://lms.edulinq.org/lineitems/1/lineitem/results?type_id=2"},
+	}
+
+	for i, testCase := range testCases {
+		actual, err := addPathSuffix(testCase.url, "/results")
+		if err != nil {
+			test.Errorf("Case %d: Got an unexpected error: '%v'.", i, err)
+			continue
+		}
+
+		if actual != testCase.expected {
+			test.Errorf("Case %d: Unexpected URL. Expected: '%s', Actual: '%s'.", i, testCase.expected, actual)
+		}
+	}
+}
+
+func TestFetchNextLink(test *testing.T) {
+	testCases := []struct {
+		headers  map[string][]string
+		expected string
+	}{
+		{map[string][]string{}, ""},
+		{map[string][]string{HEADER_LINK: []string{`<https://lms.edulinq.org/members?page=2>; rel="next"`}}, "https://lms.edulinq.org/members?page=2"},
+		{map[string][]string{HEADER_LINK: []string{`<https://lms.edulinq.org/members?page=1>; rel="first", <https://lms.edulinq.org/members?page=2>; rel="next"`}}, "https://lms.edulinq.org/members?page=2"},
+		{map[string][]string{HEADER_LINK: []string{`<https://lms.edulinq.org/members?page=1>; rel="first"`}}, ""},
+		{map[string][]string{HEADER_LINK: []string{`<https://lms.edulinq.org/members?page=2>; type="json"; rel="next"`}}, "https://lms.edulinq.org/members?page=2"},
+	}
+
+	for i, testCase := range testCases {
+		actual := fetchNextLink(testCase.headers)
+		if actual != testCase.expected {
+			test.Errorf("Case %d: Unexpected link. Expected: '%s', Actual: '%s'.", i, testCase.expected, actual)
+		}
+	}
+}
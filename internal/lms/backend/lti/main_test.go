@@ -0,0 +1,37 @@
+package lti
+
+import (
+	"os"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/lti"
+)
+
+var testPlatform *lti.TestPlatform
+var testBackend *LTIBackend
+
+func TestMain(suite *testing.M) {
+	var err error
+
+	// Run inside a func so defers will run before os.Exit().
+	code := func() int {
+		db.PrepForTestingMain()
+		defer db.CleanupTestingMain()
+
+		testPlatform, err = lti.NewTestPlatform()
+		if err != nil {
+			panic(err)
+		}
+		defer testPlatform.Close()
+
+		testBackend, err = NewBackend(lti.TEST_CONTEXT_ID, testPlatform.Options())
+		if err != nil {
+			panic(err)
+		}
+
+		return suite.Run()
+	}()
+
+	os.Exit(code)
+}
@@ -0,0 +1,159 @@
+package lti
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/lti"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	ACTIVITY_PROGRESS_COMPLETED = "Completed"
+	GRADING_PROGRESS_GRADED     = "FullyGraded"
+)
+
+var resultScopes []string = []string{lti.SCOPE_AGS_LINE_ITEM_READONLY, lti.SCOPE_AGS_RESULT_READONLY}
+var scoreScopes []string = []string{lti.SCOPE_AGS_LINE_ITEM_READONLY, lti.SCOPE_AGS_SCORE}
+
+func (this *LTIBackend) FetchAssignmentScore(assignmentID string, userID string) (*lmstypes.SubmissionScore, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("Cannot fetch assignment score, target user ID is empty.")
+	}
+
+	scores, err := this.fetchAssignmentScores(assignmentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, score := range scores {
+		if score.UserID == userID {
+			return score, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (this *LTIBackend) FetchAssignmentScores(assignmentID string) ([]*lmstypes.SubmissionScore, error) {
+	return this.fetchAssignmentScores(assignmentID, "")
+}
+
+// Fetch the results for a line item (optionally filtered by user).
+func (this *LTIBackend) fetchAssignmentScores(assignmentID string, userID string) ([]*lmstypes.SubmissionScore, error) {
+	if assignmentID == "" {
+		return nil, fmt.Errorf("Cannot fetch assignment scores, target assignment ID is empty.")
+	}
+
+	lineItem, err := this.fetchLineItem(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := addPathSuffix(lineItem.ID, "/results")
+	if err != nil {
+		return nil, err
+	}
+
+	if userID != "" {
+		url, err = addQueryParam(url, "user_id", userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	scores := make([]*lmstypes.SubmissionScore, 0)
+
+	for url != "" {
+		body, responseHeaders, err := this.get(url, lti.CONTENT_TYPE_RESULT_CONTAINER, resultScopes)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to fetch results: '%w'.", err)
+		}
+
+		var results []*lti.Result
+		err = util.JSONFromString(body, &results)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to unmarshal results page: '%w'.", err)
+		}
+
+		for _, result := range results {
+			if result == nil {
+				continue
+			}
+
+			score := result.GetScore(lineItem.ScoreMaximum)
+			if score == nil {
+				continue
+			}
+
+			submissionScore := &lmstypes.SubmissionScore{
+				UserID:   result.UserID,
+				Score:    *score,
+				Comments: make([]*lmstypes.SubmissionComment, 0),
+			}
+
+			if result.Comment != "" {
+				submissionScore.Comments = append(submissionScore.Comments, &lmstypes.SubmissionComment{
+					ID:   result.ID,
+					Text: result.Comment,
+				})
+			}
+
+			scores = append(scores, submissionScore)
+		}
+
+		url = fetchNextLink(responseHeaders)
+	}
+
+	return scores, nil
+}
+
+// AGS takes one score at a time.
+// Each score may have (at most) one comment, which will replace any existing comment.
+func (this *LTIBackend) UpdateAssignmentScores(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+	if assignmentID == "" {
+		return fmt.Errorf("Cannot update assignment scores, target assignment ID is empty.")
+	}
+
+	lineItem, err := this.fetchLineItem(assignmentID)
+	if err != nil {
+		return err
+	}
+
+	url, err := addPathSuffix(lineItem.ID, "/scores")
+	if err != nil {
+		return err
+	}
+
+	for i, score := range scores {
+		if len(score.Comments) > 1 {
+			return fmt.Errorf("Scores to upload can have at most one comment. Student '%s' for assignment '%s' has %d.", score.UserID, assignmentID, len(score.Comments))
+		}
+
+		scoreTime := timestamp.Now()
+		if score.Time != nil {
+			scoreTime = *score.Time
+		}
+
+		ltiScore := lti.Score{
+			UserID:           score.UserID,
+			ScoreGiven:       score.Score,
+			ScoreMaximum:     lineItem.ScoreMaximum,
+			Timestamp:        lti.FormatTime(scoreTime),
+			ActivityProgress: ACTIVITY_PROGRESS_COMPLETED,
+			GradingProgress:  GRADING_PROGRESS_GRADED,
+		}
+
+		for _, comment := range score.Comments {
+			ltiScore.Comment = comment.Text
+		}
+
+		err = this.post(url, lti.CONTENT_TYPE_SCORE, ltiScore, scoreScopes)
+		if err != nil {
+			return fmt.Errorf("Failed to upload score %d (user '%s'): '%w'.", i, score.UserID, err)
+		}
+	}
+
+	return nil
+}
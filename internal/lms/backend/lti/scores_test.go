@@ -0,0 +1,114 @@
+package lti
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestUpdateAssignmentScoresBase(test *testing.T) {
+	id := testPlatform.AddLineItem("Assignment Scores", 20)
+
+	scores := []*lmstypes.SubmissionScore{
+		&lmstypes.SubmissionScore{
+			UserID: "user-1",
+			Score:  15,
+			Comments: []*lmstypes.SubmissionComment{
+				&lmstypes.SubmissionComment{Text: "Good job."},
+			},
+		},
+		&lmstypes.SubmissionScore{
+			UserID: "user-2",
+			Score:  10,
+		},
+	}
+
+	err := testBackend.UpdateAssignmentScores(id, scores)
+	if err != nil {
+		test.Fatalf("Failed to update scores: '%v'.", err)
+	}
+
+	platformScores := testPlatform.GetScores(id)
+	if len(platformScores) != 2 {
+		test.Fatalf("Unexpected number of platform scores. Expected: 2, Actual: %d.", len(platformScores))
+	}
+
+	score := platformScores["user-1"]
+	if (score == nil) || !util.IsClose(score.ScoreGiven, 15) || !util.IsClose(score.ScoreMaximum, 20) ||
+		(score.Comment != "Good job.") || (score.GradingProgress != GRADING_PROGRESS_GRADED) {
+		test.Fatalf("Unexpected platform score: '%s'.", util.MustToJSONIndent(score))
+	}
+
+	expected := []*lmstypes.SubmissionScore{
+		&lmstypes.SubmissionScore{
+			UserID: "user-1",
+			Score:  15,
+			Comments: []*lmstypes.SubmissionComment{
+				&lmstypes.SubmissionComment{
+					ID:   id + "/results/user-1",
+					Text: "Good job.",
+				},
+			},
+		},
+		&lmstypes.SubmissionScore{
+			UserID:   "user-2",
+			Score:    10,
+			Comments: []*lmstypes.SubmissionComment{},
+		},
+	}
+
+	actual, err := testBackend.FetchAssignmentScores(id)
+	if err != nil {
+		test.Fatalf("Failed to fetch scores: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		test.Fatalf("Scores not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(actual))
+	}
+
+	single, err := testBackend.FetchAssignmentScore(id, "user-2")
+	if err != nil {
+		test.Fatalf("Failed to fetch score: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected[1], single) {
+		test.Fatalf("Score not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected[1]), util.MustToJSONIndent(single))
+	}
+
+	missing, err := testBackend.FetchAssignmentScore(id, "ZZZ")
+	if err != nil {
+		test.Fatalf("Failed to fetch missing score: '%v'.", err)
+	}
+
+	if missing != nil {
+		test.Fatalf("Got a score for a missing user: '%s'.", util.MustToJSONIndent(missing))
+	}
+}
+
+func TestUpdateAssignmentScoresTooManyComments(test *testing.T) {
+	id := testPlatform.AddLineItem("Assignment Comments", 20)
+
+	scores := []*lmstypes.SubmissionScore{
+		&lmstypes.SubmissionScore{
+			UserID: "user-1",
+			Score:  15,
+			Comments: []*lmstypes.SubmissionComment{
+				&lmstypes.SubmissionComment{Text: "A"},
+				&lmstypes.SubmissionComment{Text: "B"},
+			},
+		},
+	}
+
+	err := testBackend.UpdateAssignmentScores(id, scores)
+	if err == nil {
+		test.Fatalf("Did not get an error for too many comments.")
+	}
+
+	if len(testPlatform.GetScores(id)) != 0 {
+		test.Fatalf("Scores were uploaded despite an error.")
+	}
+}
@@ -0,0 +1,74 @@
+package lti
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/lti"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const MEMBER_STATUS_ACTIVE = "Active"
+
+var membershipScopes []string = []string{lti.SCOPE_NRPS_MEMBERSHIP}
+
+// Fetch the (active) members of the context.
+func (this *LTIBackend) FetchUsers() ([]*lmstypes.User, error) {
+	url := this.Options.MembershipsURL
+	if url == "" {
+		return nil, fmt.Errorf("LTI option 'memberships-url' is required to fetch users.")
+	}
+
+	users := make([]*lmstypes.User, 0)
+
+	for url != "" {
+		body, responseHeaders, err := this.get(url, lti.CONTENT_TYPE_MEMBERSHIP_CONTAINER, membershipScopes)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to fetch memberships: '%w'.", err)
+		}
+
+		var container lti.MembershipContainer
+		err = util.JSONFromString(body, &container)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to unmarshal memberships page: '%w'.", err)
+		}
+
+		for _, member := range container.Members {
+			if member == nil {
+				continue
+			}
+
+			// A missing status means active.
+			if (member.Status != "") && (member.Status != MEMBER_STATUS_ACTIVE) {
+				continue
+			}
+
+			users = append(users, &lmstypes.User{
+				ID:    member.UserID,
+				Name:  member.Name,
+				Email: member.Email,
+				Role:  lti.GetCourseRole(member.Roles),
+			})
+		}
+
+		url = fetchNextLink(responseHeaders)
+	}
+
+	return users, nil
+}
+
+// NRPS cannot search for a single user, so all users are fetched.
+func (this *LTIBackend) FetchUser(email string) (*lmstypes.User, error) {
+	users, err := this.FetchUsers()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch user '%s': '%w'.", email, err)
+	}
+
+	for _, user := range users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,56 @@
+package lti
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/lti"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestFetchUsersBase(test *testing.T) {
+	testPlatform.SetMembers([]*lti.Member{
+		&lti.Member{UserID: "1", Name: "Alice", Email: "alice@test.edulinq.org", Roles: []string{lti.ROLE_LEARNER}},
+		&lti.Member{UserID: "2", Name: "Bob", Email: "bob@test.edulinq.org", Roles: []string{lti.ROLE_INSTRUCTOR}, Status: "Active"},
+		&lti.Member{UserID: "3", Name: "Claire", Email: "claire@test.edulinq.org", Roles: []string{"Instructor", "TeachingAssistant"}},
+		&lti.Member{UserID: "4", Name: "Dan", Email: "dan@test.edulinq.org", Roles: []string{lti.ROLE_LEARNER}, Status: "Inactive"},
+	})
+	defer testPlatform.SetMembers(nil)
+
+	expected := []*lmstypes.User{
+		&lmstypes.User{ID: "1", Name: "Alice", Email: "alice@test.edulinq.org", Role: model.CourseRoleStudent},
+		&lmstypes.User{ID: "2", Name: "Bob", Email: "bob@test.edulinq.org", Role: model.CourseRoleOwner},
+		&lmstypes.User{ID: "3", Name: "Claire", Email: "claire@test.edulinq.org", Role: model.CourseRoleGrader},
+	}
+
+	users, err := testBackend.FetchUsers()
+	if err != nil {
+		test.Fatalf("Failed to fetch users: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, users) {
+		test.Fatalf("Users not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(users))
+	}
+
+	user, err := testBackend.FetchUser("claire@test.edulinq.org")
+	if err != nil {
+		test.Fatalf("Failed to fetch user: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected[2], user) {
+		test.Fatalf("User not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected[2]), util.MustToJSONIndent(user))
+	}
+
+	user, err = testBackend.FetchUser("dan@test.edulinq.org")
+	if err != nil {
+		test.Fatalf("Failed to fetch inactive user: '%v'.", err)
+	}
+
+	if user != nil {
+		test.Fatalf("Got an inactive user: '%s'.", util.MustToJSONIndent(user))
+	}
+}
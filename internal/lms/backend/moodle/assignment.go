@@ -0,0 +1,59 @@
+package moodle
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+)
+
+func (this *MoodleBackend) FetchAssignment(assignmentID string) (*lmstypes.Assignment, error) {
+	if assignmentID == "" {
+		return nil, fmt.Errorf("Cannot fetch assignment, target assignment ID is empty.")
+	}
+
+	assignments, err := this.FetchAssignments()
+	if err != nil {
+		return nil, err
+	}
+
+	// Moodle does not have a function to fetch a single assignment.
+	for _, assignment := range assignments {
+		if assignment.ID == assignmentID {
+			return assignment, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Could not find Moodle assignment '%s'.", assignmentID)
+}
+
+func (this *MoodleBackend) FetchAssignments() ([]*lmstypes.Assignment, error) {
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	params := map[string]string{
+		"courseids[0]": this.CourseID,
+	}
+
+	var response courseAssignments
+	err := this.call("mod_assign_get_assignments", params, &response)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch assignments: '%w'.", err)
+	}
+
+	assignments := make([]*lmstypes.Assignment, 0)
+	for _, course := range response.Courses {
+		if course == nil {
+			continue
+		}
+
+		for _, assignment := range course.Assignments {
+			if assignment == nil {
+				continue
+			}
+
+			assignments = append(assignments, assignment.ToLMSType())
+		}
+	}
+
+	return assignments, nil
+}
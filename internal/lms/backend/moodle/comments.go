@@ -0,0 +1,61 @@
+package moodle
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+)
+
+func (this *MoodleBackend) UpdateComments(assignmentID string, comments []*lmstypes.SubmissionComment) error {
+	if assignmentID == "" {
+		return fmt.Errorf("Cannot update comments, target assignment ID is empty.")
+	}
+
+	for i, comment := range comments {
+		if i != 0 {
+			time.Sleep(time.Duration(UPLOAD_SLEEP_TIME_SEC))
+		}
+
+		err := this.UpdateComment(assignmentID, comment)
+		if err != nil {
+			return fmt.Errorf("Failed on comment %d: '%w'.", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Moodle feedback comments are a part of the grade (and identified by the user's ID),
+// so the comment is updated by re-saving the user's current grade with the new comment.
+func (this *MoodleBackend) UpdateComment(assignmentID string, comment *lmstypes.SubmissionComment) error {
+	if assignmentID == "" {
+		return fmt.Errorf("Cannot update comment, target assignment ID is empty.")
+	}
+
+	score, err := this.FetchAssignmentScore(assignmentID, comment.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch current score for comment: '%w'.", err)
+	}
+
+	if score == nil {
+		return fmt.Errorf("Could not find a score for user '%s' on assignment '%s' to comment on.", comment.ID, assignmentID)
+	}
+
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	params := map[string]string{
+		"assignmentid": assignmentID,
+		"applytoall":   "0",
+	}
+
+	addGradeParams(params, 0, comment.ID, score.Score, comment.Text)
+
+	err = this.call("mod_assign_save_grades", params, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to update comment: '%w'.", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package moodle
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+)
+
+func TestUpdateCommentsBase(test *testing.T) {
+	testCases := []struct {
+		text     string
+		errorSub string
+	}{
+		{"updated", ""},
+		{"locked", "gradelocked"},
+	}
+
+	for i, testCase := range testCases {
+		comments := []*lmstypes.SubmissionComment{
+			&lmstypes.SubmissionComment{
+				ID:   "40",
+				Text: testCase.text,
+			},
+		}
+
+		err := testBackend.UpdateComments(TEST_ASSIGNMENT_ID, comments)
+		if err != nil {
+			if testCase.errorSub == "" {
+				test.Errorf("Case %d: Failed to update comments: '%v'.", i, err)
+			} else if !strings.Contains(err.Error(), testCase.errorSub) {
+				test.Errorf("Case %d: Error is not as expected. Expected substring: '%s', Actual: '%v'.", i, testCase.errorSub, err)
+			}
+
+			continue
+		}
+
+		if testCase.errorSub != "" {
+			test.Errorf("Case %d: Did not get an expected error.", i)
+			continue
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package moodle
+
+import (
+	"fmt"
+	"html"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/edulinq/autograder/internal/lockmanager"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	REST_PATH             string = "/webservice/rest/server.php"
+	REST_FORMAT           string = "json"
+	POST_PAGE_SIZE        int    = 75
+	UPLOAD_SLEEP_TIME_SEC        = int64(0.5 * float64(time.Second))
+)
+
+// Moodle reports web service errors with a normal (200) response containing an exception.
+type exception struct {
+	Exception string `json:"exception"`
+	ErrorCode string `json:"errorcode"`
+	Message   string `json:"message"`
+}
+
+func (this *MoodleBackend) getAPILock() {
+	lockmanager.Lock(this.getLockKey())
+}
+
+func (this *MoodleBackend) releaseAPILock() {
+	lockmanager.Unlock(this.getLockKey())
+}
+
+// Lock based on the API token (like Canvas).
+func (this *MoodleBackend) getLockKey() string {
+	return fmt.Sprintf("moodle::%s", this.APIToken)
+}
+
+// Call a web service function and unmarshal the result into the target (if not nil).
+// The function name goes in the query, while the token and function parameters are sent as a form
+// (so the token does not end up in any logged URLs).
+func (this *MoodleBackend) call(function string, params map[string]string, target any) error {
+	query := neturl.Values{}
+	query.Set("wsfunction", function)
+	query.Set("moodlewsrestformat", REST_FORMAT)
+
+	url := this.BaseURL + REST_PATH + "?" + query.Encode()
+
+	form := make(map[string]string, len(params)+1)
+	for key, value := range params {
+		form[key] = value
+	}
+
+	form["wstoken"] = this.APIToken
+
+	body, _, err := util.PostWithHeaders(url, form, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to call Moodle function '%s': '%w'.", function, err)
+	}
+
+	return parseResponse(function, body, target)
+}
+
+func parseResponse(function string, body string, target any) error {
+	body = strings.TrimSpace(body)
+
+	// Functions without a return value respond with "null".
+	if (body == "") || (body == "null") {
+		return nil
+	}
+
+	if strings.HasPrefix(body, "{") {
+		var response exception
+		err := util.JSONFromString(body, &response)
+		if err != nil {
+			return fmt.Errorf("Failed to unmarshal response for Moodle function '%s': '%w'.", function, err)
+		}
+
+		if response.Exception != "" {
+			return fmt.Errorf("Moodle function '%s' raised an exception (%s, %s): '%s'.",
+				function, response.Exception, response.ErrorCode, response.Message)
+		}
+	}
+
+	if target == nil {
+		return nil
+	}
+
+	err := util.JSONFromString(body, target)
+	if err != nil {
+		return fmt.Errorf("Failed to unmarshal response for Moodle function '%s': '%w'.", function, err)
+	}
+
+	return nil
+}
+
+// Feedback text from the gradebook has been formatted for display.
+// Undo the formatting for plain text (line breaks and HTML entities).
+func cleanFeedback(text string) string {
+	text = strings.ReplaceAll(text, "<br />", "")
+	text = strings.ReplaceAll(text, "<br>", "")
+	text = strings.ReplaceAll(text, "&nbsp;", " ")
+
+	return html.UnescapeString(text)
+}
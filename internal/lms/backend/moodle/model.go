@@ -0,0 +1,151 @@
+package moodle
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+const (
+	GRADE_ITEM_MODULE_ASSIGN = "assign"
+
+	// FORMAT_PLAIN in Moodle.
+	TEXT_FORMAT_PLAIN = "2"
+)
+
+// Response of mod_assign_get_assignments.
+type courseAssignments struct {
+	Courses []*struct {
+		ID          int64         `json:"id"`
+		Assignments []*Assignment `json:"assignments"`
+	} `json:"courses"`
+}
+
+type Assignment struct {
+	ID       int64  `json:"id"`
+	CourseID int64  `json:"course"`
+	Name     string `json:"name"`
+
+	// Unix time (seconds), zero when there is no due date.
+	DueDate int64 `json:"duedate"`
+
+	// Maximum grade, negative values indicate a scale is used instead of points.
+	Grade float64 `json:"grade"`
+}
+
+// An entry of core_enrol_get_enrolled_users.
+type User struct {
+	ID       int64   `json:"id"`
+	FullName string  `json:"fullname"`
+	Email    string  `json:"email"`
+	Roles    []*Role `json:"roles"`
+}
+
+type Role struct {
+	ID        int64  `json:"roleid"`
+	Name      string `json:"name"`
+	ShortName string `json:"shortname"`
+}
+
+// Response of gradereport_user_get_grade_items.
+type gradeReport struct {
+	UserGrades []*UserGrades `json:"usergrades"`
+}
+
+type UserGrades struct {
+	UserID     int64        `json:"userid"`
+	GradeItems []*GradeItem `json:"gradeitems"`
+}
+
+type GradeItem struct {
+	ID           int64    `json:"id"`
+	ItemModule   string   `json:"itemmodule"`
+	ItemInstance int64    `json:"iteminstance"`
+	GradeRaw     *float64 `json:"graderaw"`
+	DateGraded   *int64   `json:"gradedategraded"`
+	Feedback     string   `json:"feedback"`
+}
+
+// Moodle (standard) role short names to autograder role.
+var roleMapping map[string]model.CourseUserRole = map[string]model.CourseUserRole{
+	"guest":          model.CourseRoleOther,
+	"user":           model.CourseRoleOther,
+	"student":        model.CourseRoleStudent,
+	"teacher":        model.CourseRoleGrader,
+	"manager":        model.CourseRoleAdmin,
+	"editingteacher": model.CourseRoleOwner,
+}
+
+func (this *User) GetRole() model.CourseUserRole {
+	var maxRole model.CourseUserRole = model.CourseRoleOther
+	for _, role := range this.Roles {
+		maxRole = max(maxRole, roleMapping[role.ShortName])
+	}
+
+	return maxRole
+}
+
+func (this *User) ToLMSType() *lmstypes.User {
+	return &lmstypes.User{
+		ID:    formatID(this.ID),
+		Name:  this.FullName,
+		Email: this.Email,
+		Role:  this.GetRole(),
+	}
+}
+
+func (this *Assignment) ToLMSType() *lmstypes.Assignment {
+	var dueDate *timestamp.Timestamp = nil
+	if this.DueDate > 0 {
+		value := timestamp.FromMSecs(this.DueDate * 1000)
+		dueDate = &value
+	}
+
+	return &lmstypes.Assignment{
+		ID:          formatID(this.ID),
+		Name:        this.Name,
+		LMSCourseID: formatID(this.CourseID),
+		DueDate:     dueDate,
+		MaxPoints:   max(0.0, this.Grade),
+	}
+}
+
+func (this *GradeItem) IsAssignment(assignmentID string) bool {
+	return (this.ItemModule == GRADE_ITEM_MODULE_ASSIGN) && (formatID(this.ItemInstance) == assignmentID)
+}
+
+// Moodle stores a single feedback comment for each user's grade,
+// so the comment is identified by the user's ID.
+func (this *GradeItem) ToLMSType(userID int64) *lmstypes.SubmissionScore {
+	score := 0.0
+	if this.GradeRaw != nil {
+		score = *this.GradeRaw
+	}
+
+	var gradeTime *timestamp.Timestamp = nil
+	if this.DateGraded != nil {
+		value := timestamp.FromMSecs(*this.DateGraded * 1000)
+		gradeTime = &value
+	}
+
+	comments := make([]*lmstypes.SubmissionComment, 0, 1)
+	if this.Feedback != "" {
+		comments = append(comments, &lmstypes.SubmissionComment{
+			ID:   formatID(userID),
+			Text: cleanFeedback(this.Feedback),
+		})
+	}
+
+	return &lmstypes.SubmissionScore{
+		UserID:   formatID(userID),
+		Score:    score,
+		Time:     gradeTime,
+		Comments: comments,
+	}
+}
+
+func formatID(id int64) string {
+	return fmt.Sprintf("%d", id)
+}
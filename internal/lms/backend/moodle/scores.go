@@ -0,0 +1,146 @@
+package moodle
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func (this *MoodleBackend) FetchAssignmentScore(assignmentID string, userID string) (*lmstypes.SubmissionScore, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("Cannot fetch assignment score, target user ID is empty.")
+	}
+
+	scores, err := this.fetchAssignmentScores(assignmentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, score := range scores {
+		if score.UserID == userID {
+			return score, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (this *MoodleBackend) FetchAssignmentScores(assignmentID string) ([]*lmstypes.SubmissionScore, error) {
+	return this.fetchAssignmentScores(assignmentID, "")
+}
+
+// Scores (and feedback comments) are read from the course gradebook (optionally for a single user).
+func (this *MoodleBackend) fetchAssignmentScores(assignmentID string, userID string) ([]*lmstypes.SubmissionScore, error) {
+	if assignmentID == "" {
+		return nil, fmt.Errorf("Cannot fetch assignment scores, target assignment ID is empty.")
+	}
+
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	params := map[string]string{
+		"courseid": this.CourseID,
+	}
+
+	if userID != "" {
+		params["userid"] = userID
+	}
+
+	var report gradeReport
+	err := this.call("gradereport_user_get_grade_items", params, &report)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch scores: '%w'.", err)
+	}
+
+	scores := make([]*lmstypes.SubmissionScore, 0, len(report.UserGrades))
+	for _, userGrades := range report.UserGrades {
+		if userGrades == nil {
+			continue
+		}
+
+		for _, item := range userGrades.GradeItems {
+			if (item == nil) || !item.IsAssignment(assignmentID) {
+				continue
+			}
+
+			scores = append(scores, item.ToLMSType(userGrades.UserID))
+		}
+	}
+
+	return scores, nil
+}
+
+func (this *MoodleBackend) UpdateAssignmentScores(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+	if assignmentID == "" {
+		return fmt.Errorf("Cannot update assignment scores, target assignment ID is empty.")
+	}
+
+	for page := 0; (page * POST_PAGE_SIZE) < len(scores); page++ {
+		startIndex := page * POST_PAGE_SIZE
+		endIndex := min(len(scores), ((page + 1) * POST_PAGE_SIZE))
+
+		if page != 0 {
+			time.Sleep(time.Duration(UPLOAD_SLEEP_TIME_SEC))
+		}
+
+		err := this.updateAssignmentScores(assignmentID, scores[startIndex:endIndex])
+		if err != nil {
+			return fmt.Errorf("Failed on page %d: '%w'.", page, err)
+		}
+	}
+
+	return nil
+}
+
+func (this *MoodleBackend) updateAssignmentScores(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	if len(scores) > POST_PAGE_SIZE {
+		return fmt.Errorf("Too many score upload requests at once. Found %d, max %d.", len(scores), POST_PAGE_SIZE)
+	}
+
+	params := map[string]string{
+		"assignmentid": assignmentID,
+		"applytoall":   "0",
+	}
+
+	for i, score := range scores {
+		if len(score.Comments) > 1 {
+			return fmt.Errorf("Scores to upload can have at most one comment. Student '%s' for assignment '%s' has %d.", score.UserID, assignmentID, len(score.Comments))
+		}
+
+		text := ""
+		if len(score.Comments) == 1 {
+			text = score.Comments[0].Text
+		}
+
+		addGradeParams(params, i, score.UserID, score.Score, text)
+	}
+
+	err := this.call("mod_assign_save_grades", params, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to upload scores: '%w'.", err)
+	}
+
+	return nil
+}
+
+// Add the parameters for a single grade to a mod_assign_save_grades call.
+// Feedback is only sent when there is a comment, so existing feedback is left alone otherwise.
+func addGradeParams(params map[string]string, index int, userID string, score float64, comment string) {
+	prefix := fmt.Sprintf("grades[%d]", index)
+
+	params[prefix+"[userid]"] = userID
+	params[prefix+"[grade]"] = util.FloatToStr(score)
+	params[prefix+"[attemptnumber]"] = "-1"
+	params[prefix+"[addattempt]"] = "0"
+	params[prefix+"[workflowstate]"] = ""
+
+	if comment != "" {
+		params[prefix+"[plugindata][assignfeedbackcomments_editor][text]"] = comment
+		params[prefix+"[plugindata][assignfeedbackcomments_editor][format]"] = TEXT_FORMAT_PLAIN
+	}
+}
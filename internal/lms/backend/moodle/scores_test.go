@@ -0,0 +1,101 @@
+package moodle
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const testCommentText string = "{\n\"id\": \"course101::hw0::course-student@test.edulinq.org::1696364768\",\n\"submission-time\":1234,\n\"upload-time\":1235,\n\"raw-score\": 100,\n\"score\": 100,\n\"lock\": false,\n\"late-date-usage\": 0,\n\"num-days-late\": 0,\n\"reject\": false,\n\"__autograder__v01__\": 0\n}"
+
+var gradeTime timestamp.Timestamp = timestamp.FromMSecs(1696364768000)
+
+var testScore lmstypes.SubmissionScore = lmstypes.SubmissionScore{
+	UserID: "40",
+	Score:  100.0,
+	Time:   &gradeTime,
+	Comments: []*lmstypes.SubmissionComment{
+		&lmstypes.SubmissionComment{
+			ID:   "40",
+			Text: testCommentText,
+		},
+	},
+}
+
+func TestFetchAssignmentScoreBase(test *testing.T) {
+	score, err := testBackend.FetchAssignmentScore(TEST_ASSIGNMENT_ID, "40")
+	if err != nil {
+		test.Fatalf("Failed to fetch assignment score: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(&testScore, score) {
+		test.Fatalf("Score not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(testScore), util.MustToJSONIndent(score))
+	}
+}
+
+func TestFetchAssignmentScoresBase(test *testing.T) {
+	scores, err := testBackend.FetchAssignmentScores(TEST_ASSIGNMENT_ID)
+	if err != nil {
+		test.Fatalf("Failed to fetch assignment scores: '%v'.", err)
+	}
+
+	expected := []*lmstypes.SubmissionScore{
+		&testScore,
+		// Not graded.
+		&lmstypes.SubmissionScore{
+			UserID:   "41",
+			Score:    0.0,
+			Comments: []*lmstypes.SubmissionComment{},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, scores) {
+		test.Fatalf("Scores not as expected. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(scores))
+	}
+}
+
+func TestUpdateAssignmentScoresBase(test *testing.T) {
+	scores := []*lmstypes.SubmissionScore{
+		&lmstypes.SubmissionScore{
+			UserID: "40",
+			Score:  95.0,
+			Comments: []*lmstypes.SubmissionComment{
+				&lmstypes.SubmissionComment{
+					Text: testCommentText,
+				},
+			},
+		},
+		&lmstypes.SubmissionScore{
+			UserID: "41",
+			Score:  80.0,
+		},
+	}
+
+	err := testBackend.UpdateAssignmentScores(TEST_ASSIGNMENT_ID, scores)
+	if err != nil {
+		test.Fatalf("Failed to update assignment scores: '%v'.", err)
+	}
+}
+
+func TestUpdateAssignmentScoresTooManyComments(test *testing.T) {
+	scores := []*lmstypes.SubmissionScore{
+		&lmstypes.SubmissionScore{
+			UserID: "40",
+			Score:  95.0,
+			Comments: []*lmstypes.SubmissionComment{
+				&lmstypes.SubmissionComment{Text: "a"},
+				&lmstypes.SubmissionComment{Text: "b"},
+			},
+		},
+	}
+
+	err := testBackend.UpdateAssignmentScores(TEST_ASSIGNMENT_ID, scores)
+	if err == nil {
+		test.Fatalf("Did not get an error when uploading a score with multiple comments.")
+	}
+}
@@ -0,0 +1,59 @@
+package moodle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/log"
+)
+
+func (this *MoodleBackend) FetchUsers() ([]*lmstypes.User, error) {
+	this.getAPILock()
+	defer this.releaseAPILock()
+
+	params := map[string]string{
+		"courseid": this.CourseID,
+	}
+
+	var rawUsers []*User
+	err := this.call("core_enrol_get_enrolled_users", params, &rawUsers)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch users: '%w'.", err)
+	}
+
+	users := make([]*lmstypes.User, 0, len(rawUsers))
+	for _, user := range rawUsers {
+		if user == nil {
+			continue
+		}
+
+		users = append(users, user.ToLMSType())
+	}
+
+	return users, nil
+}
+
+// Moodle cannot search a course's users,
+// so all the users are fetched and matched by email.
+func (this *MoodleBackend) FetchUser(email string) (*lmstypes.User, error) {
+	users, err := this.FetchUsers()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch user '%s': '%w'.", email, err)
+	}
+
+	matches := make([]*lmstypes.User, 0, 1)
+	for _, user := range users {
+		if strings.EqualFold(user.Email, email) {
+			matches = append(matches, user)
+		}
+	}
+
+	if len(matches) != 1 {
+		log.Warn("Did not find exactly one matching user in moodle.",
+			log.NewAttr("email", email), log.NewAttr("num-results", len(matches)))
+		return nil, nil
+	}
+
+	return matches[0], nil
+}
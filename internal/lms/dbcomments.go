@@ -0,0 +1,153 @@
+package lms
+
+import (
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+// Wraps a backend for an LMS that cannot (reliably) store submission comments.
+// Comments are stored in the database instead (see model.LMSComment),
+// while everything else (including scores) still goes to the LMS.
+// Each user has at most one comment per assignment, which is identified by the user's LMS ID.
+type dbCommentsBackend struct {
+	lmsBackend
+
+	course *model.Course
+}
+
+func withDBComments(course *model.Course, backend lmsBackend) lmsBackend {
+	return &dbCommentsBackend{
+		lmsBackend: backend,
+		course:     course,
+	}
+}
+
+func (this *dbCommentsBackend) UpdateComments(assignmentID string, comments []*lmstypes.SubmissionComment) error {
+	if assignmentID == "" {
+		return fmt.Errorf("Cannot update comments, target assignment ID is empty.")
+	}
+
+	now := timestamp.Now()
+
+	dbComments := make([]*model.LMSComment, 0, len(comments))
+	for _, comment := range comments {
+		dbComments = append(dbComments, &model.LMSComment{
+			CourseID:        this.course.GetID(),
+			AssignmentLMSID: assignmentID,
+			UserLMSID:       comment.ID,
+			Text:            comment.Text,
+			UpdateTime:      now,
+		})
+	}
+
+	err := db.SaveLMSComments(dbComments)
+	if err != nil {
+		return fmt.Errorf("Failed to save comments: '%w'.", err)
+	}
+
+	return nil
+}
+
+func (this *dbCommentsBackend) UpdateComment(assignmentID string, comment *lmstypes.SubmissionComment) error {
+	return this.UpdateComments(assignmentID, []*lmstypes.SubmissionComment{comment})
+}
+
+func (this *dbCommentsBackend) FetchAssignmentScores(assignmentID string) ([]*lmstypes.SubmissionScore, error) {
+	scores, err := this.lmsBackend.FetchAssignmentScores(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.attachComments(assignmentID, scores)
+	if err != nil {
+		return nil, err
+	}
+
+	return scores, nil
+}
+
+func (this *dbCommentsBackend) FetchAssignmentScore(assignmentID string, userID string) (*lmstypes.SubmissionScore, error) {
+	score, err := this.lmsBackend.FetchAssignmentScore(assignmentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if score == nil {
+		return nil, nil
+	}
+
+	err = this.attachComments(assignmentID, []*lmstypes.SubmissionScore{score})
+	if err != nil {
+		return nil, err
+	}
+
+	return score, nil
+}
+
+// Upload the scores (without comments) to the LMS, and then save the comments.
+func (this *dbCommentsBackend) UpdateAssignmentScores(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+	if assignmentID == "" {
+		return fmt.Errorf("Cannot update assignment scores, target assignment ID is empty.")
+	}
+
+	lmsScores := make([]*lmstypes.SubmissionScore, 0, len(scores))
+	comments := make([]*lmstypes.SubmissionComment, 0, len(scores))
+
+	for _, score := range scores {
+		if len(score.Comments) > 1 {
+			return fmt.Errorf("Scores to upload can have at most one comment. Student '%s' for assignment '%s' has %d.", score.UserID, assignmentID, len(score.Comments))
+		}
+
+		for _, comment := range score.Comments {
+			comments = append(comments, &lmstypes.SubmissionComment{
+				ID:   score.UserID,
+				Text: comment.Text,
+			})
+		}
+
+		lmsScore := *score
+		lmsScore.Comments = nil
+		lmsScores = append(lmsScores, &lmsScore)
+	}
+
+	err := this.lmsBackend.UpdateAssignmentScores(assignmentID, lmsScores)
+	if err != nil {
+		return err
+	}
+
+	return this.UpdateComments(assignmentID, comments)
+}
+
+// Replace any comments from the LMS with the ones in the database.
+func (this *dbCommentsBackend) attachComments(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+	dbComments, err := db.GetLMSComments(this.course, assignmentID)
+	if err != nil {
+		return fmt.Errorf("Failed to get comments: '%w'.", err)
+	}
+
+	userComments := make(map[string]*model.LMSComment, len(dbComments))
+	for _, dbComment := range dbComments {
+		userComments[dbComment.UserLMSID] = dbComment
+	}
+
+	for _, score := range scores {
+		score.Comments = make([]*lmstypes.SubmissionComment, 0, 1)
+
+		dbComment := userComments[score.UserID]
+		if dbComment == nil {
+			continue
+		}
+
+		score.Comments = append(score.Comments, &lmstypes.SubmissionComment{
+			ID:   dbComment.UserLMSID,
+			Text: dbComment.Text,
+			Time: dbComment.UpdateTime.SafeString(),
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package lms
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/db"
+	testbackend "github.com/edulinq/autograder/internal/lms/backend/test"
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// A test backend that remembers the scores uploaded to it.
+type scoresBackend struct {
+	*testbackend.TestLMSBackend
+
+	scores []*lmstypes.SubmissionScore
+}
+
+func (this *scoresBackend) UpdateAssignmentScores(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+	this.scores = scores
+
+	return nil
+}
+
+func (this *scoresBackend) FetchAssignmentScores(assignmentID string) ([]*lmstypes.SubmissionScore, error) {
+	scores := make([]*lmstypes.SubmissionScore, 0, len(this.scores))
+	for _, lmsScore := range this.scores {
+		score := *lmsScore
+		scores = append(scores, &score)
+	}
+
+	return scores, nil
+}
+
+func TestDBCommentsBase(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	course := db.MustGetTestCourse()
+
+	testBackend, err := testbackend.NewBackend(course.GetID())
+	if err != nil {
+		test.Fatalf("Failed to create test backend: '%v'.", err)
+	}
+
+	lmsScores := &scoresBackend{
+		TestLMSBackend: testBackend,
+	}
+
+	backend := withDBComments(course, lmsScores)
+
+	scores := []*lmstypes.SubmissionScore{
+		&lmstypes.SubmissionScore{
+			UserID:   "U1",
+			Score:    1,
+			Comments: []*lmstypes.SubmissionComment{&lmstypes.SubmissionComment{Text: "first"}},
+		},
+		&lmstypes.SubmissionScore{
+			UserID: "U2",
+			Score:  2,
+		},
+	}
+
+	err = backend.UpdateAssignmentScores("A1", scores)
+	if err != nil {
+		test.Fatalf("Failed to update scores: '%v'.", err)
+	}
+
+	// Comments should not make it to the LMS.
+	for _, score := range lmsScores.scores {
+		if len(score.Comments) != 0 {
+			test.Fatalf("User '%s' has comments in the LMS: '%s'.", score.UserID, util.MustToJSONIndent(score.Comments))
+		}
+	}
+
+	err = backend.UpdateComment("A1", &lmstypes.SubmissionComment{ID: "U2", Text: "second"})
+	if err != nil {
+		test.Fatalf("Failed to update comment: '%v'.", err)
+	}
+
+	fetchedScores, err := backend.FetchAssignmentScores("A1")
+	if err != nil {
+		test.Fatalf("Failed to fetch scores: '%v'.", err)
+	}
+
+	expected := map[string]string{
+		"U1": "first",
+		"U2": "second",
+	}
+
+	actual := make(map[string]string, len(fetchedScores))
+	for _, score := range fetchedScores {
+		if len(score.Comments) != 1 {
+			test.Fatalf("User '%s' does not have exactly one comment: '%s'.", score.UserID, util.MustToJSONIndent(score.Comments))
+		}
+
+		actual[score.UserID] = score.Comments[0].Text
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		test.Fatalf("Unexpected comments. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expected), util.MustToJSONIndent(actual))
+	}
+
+	// Comments are scoped to an assignment.
+	fetchedScores, err = backend.FetchAssignmentScores("A2")
+	if err != nil {
+		test.Fatalf("Failed to fetch other assignment scores: '%v'.", err)
+	}
+
+	for _, score := range fetchedScores {
+		if len(score.Comments) != 0 {
+			test.Fatalf("User '%s' has comments for another assignment: '%s'.", score.UserID, util.MustToJSONIndent(score.Comments))
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package lms
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/edulinq/autograder/internal/lms/backend/blackboard"
 	"github.com/edulinq/autograder/internal/lms/backend/canvas"
+	"github.com/edulinq/autograder/internal/lms/backend/d2l"
+	"github.com/edulinq/autograder/internal/lms/backend/lti"
+	"github.com/edulinq/autograder/internal/lms/backend/moodle"
 	"github.com/edulinq/autograder/internal/lms/backend/test"
 	"github.com/edulinq/autograder/internal/lms/lmstypes"
 	"github.com/edulinq/autograder/internal/model"
@@ -24,19 +29,59 @@ type lmsBackend interface {
 	FetchUser(email string) (*lmstypes.User, error)
 }
 
-func getBackend(course *model.Course) (lmsBackend, error) {
+// Get the backend for a course.
+// LMS calls will be traced as part of the context (if any).
+func getBackend(ctx context.Context, course *model.Course) (lmsBackend, error) {
+	backend, err := newBackend(course)
+	if err != nil {
+		return nil, err
+	}
+
+	return withTracing(ctx, course, backend), nil
+}
+
+func newBackend(course *model.Course) (lmsBackend, error) {
 	adapter := course.GetLMSAdapter()
 	if adapter == nil {
 		return nil, fmt.Errorf("Course '%s' has no LMS information.", course.GetID())
 	}
 
 	switch adapter.Type {
+	case model.LMS_TYPE_BLACKBOARD:
+		backend, err := blackboard.NewBackend(adapter.LMSCourseID, adapter.APIToken, adapter.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+
+		// Blackboard feedback is not a reliable place to keep submission comments.
+		return withDBComments(course, backend), nil
 	case model.LMS_TYPE_CANVAS:
 		backend, err := canvas.NewBackend(adapter.LMSCourseID, adapter.APIToken, adapter.BaseURL)
 		if err != nil {
 			return nil, err
 		}
 
+		return backend, nil
+	case model.LMS_TYPE_D2L:
+		backend, err := d2l.NewBackend(adapter.LMSCourseID, adapter.APIToken, adapter.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+
+		return backend, nil
+	case model.LMS_TYPE_LTI:
+		backend, err := lti.NewBackend(adapter.LMSCourseID, adapter.LTI)
+		if err != nil {
+			return nil, err
+		}
+
+		return backend, nil
+	case model.LMS_TYPE_MOODLE:
+		backend, err := moodle.NewBackend(adapter.LMSCourseID, adapter.APIToken, adapter.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+
 		return backend, nil
 	case model.LMS_TYPE_TEST:
 		backend, err := test.NewBackend(course.GetID())
@@ -50,8 +95,8 @@ func getBackend(course *model.Course) (lmsBackend, error) {
 	}
 }
 
-func FetchAssignment(course *model.Course, assignmentID string) (*lmstypes.Assignment, error) {
-	backend, err := getBackend(course)
+func FetchAssignment(ctx context.Context, course *model.Course, assignmentID string) (*lmstypes.Assignment, error) {
+	backend, err := getBackend(ctx, course)
 	if err != nil {
 		return nil, err
 	}
@@ -59,8 +104,8 @@ func FetchAssignment(course *model.Course, assignmentID string) (*lmstypes.Assig
 	return backend.FetchAssignment(assignmentID)
 }
 
-func FetchAssignments(course *model.Course) ([]*lmstypes.Assignment, error) {
-	backend, err := getBackend(course)
+func FetchAssignments(ctx context.Context, course *model.Course) ([]*lmstypes.Assignment, error) {
+	backend, err := getBackend(ctx, course)
 	if err != nil {
 		return nil, err
 	}
@@ -68,8 +113,8 @@ func FetchAssignments(course *model.Course) ([]*lmstypes.Assignment, error) {
 	return backend.FetchAssignments()
 }
 
-func UpdateComments(course *model.Course, assignmentID string, comments []*lmstypes.SubmissionComment) error {
-	backend, err := getBackend(course)
+func UpdateComments(ctx context.Context, course *model.Course, assignmentID string, comments []*lmstypes.SubmissionComment) error {
+	backend, err := getBackend(ctx, course)
 	if err != nil {
 		return err
 	}
@@ -77,8 +122,8 @@ func UpdateComments(course *model.Course, assignmentID string, comments []*lmsty
 	return backend.UpdateComments(assignmentID, comments)
 }
 
-func UpdateComment(course *model.Course, assignmentID string, comment *lmstypes.SubmissionComment) error {
-	backend, err := getBackend(course)
+func UpdateComment(ctx context.Context, course *model.Course, assignmentID string, comment *lmstypes.SubmissionComment) error {
+	backend, err := getBackend(ctx, course)
 	if err != nil {
 		return err
 	}
@@ -86,8 +131,8 @@ func UpdateComment(course *model.Course, assignmentID string, comment *lmstypes.
 	return backend.UpdateComment(assignmentID, comment)
 }
 
-func FetchAssignmentScores(course *model.Course, assignmentID string) ([]*lmstypes.SubmissionScore, error) {
-	backend, err := getBackend(course)
+func FetchAssignmentScores(ctx context.Context, course *model.Course, assignmentID string) ([]*lmstypes.SubmissionScore, error) {
+	backend, err := getBackend(ctx, course)
 	if err != nil {
 		return nil, err
 	}
@@ -95,8 +140,8 @@ func FetchAssignmentScores(course *model.Course, assignmentID string) ([]*lmstyp
 	return backend.FetchAssignmentScores(assignmentID)
 }
 
-func FetchAssignmentScore(course *model.Course, assignmentID string, userID string) (*lmstypes.SubmissionScore, error) {
-	backend, err := getBackend(course)
+func FetchAssignmentScore(ctx context.Context, course *model.Course, assignmentID string, userID string) (*lmstypes.SubmissionScore, error) {
+	backend, err := getBackend(ctx, course)
 	if err != nil {
 		return nil, err
 	}
@@ -104,8 +149,8 @@ func FetchAssignmentScore(course *model.Course, assignmentID string, userID stri
 	return backend.FetchAssignmentScore(assignmentID, userID)
 }
 
-func UpdateAssignmentScores(course *model.Course, assignmentID string, scores []*lmstypes.SubmissionScore) error {
-	backend, err := getBackend(course)
+func UpdateAssignmentScores(ctx context.Context, course *model.Course, assignmentID string, scores []*lmstypes.SubmissionScore) error {
+	backend, err := getBackend(ctx, course)
 	if err != nil {
 		return err
 	}
@@ -113,8 +158,8 @@ func UpdateAssignmentScores(course *model.Course, assignmentID string, scores []
 	return backend.UpdateAssignmentScores(assignmentID, scores)
 }
 
-func FetchUsers(course *model.Course) ([]*lmstypes.User, error) {
-	backend, err := getBackend(course)
+func FetchUsers(ctx context.Context, course *model.Course) ([]*lmstypes.User, error) {
+	backend, err := getBackend(ctx, course)
 	if err != nil {
 		return nil, err
 	}
@@ -122,8 +167,8 @@ func FetchUsers(course *model.Course) ([]*lmstypes.User, error) {
 	return backend.FetchUsers()
 }
 
-func FetchUser(course *model.Course, email string) (*lmstypes.User, error) {
-	backend, err := getBackend(course)
+func FetchUser(ctx context.Context, course *model.Course, email string) (*lmstypes.User, error) {
+	backend, err := getBackend(ctx, course)
 	if err != nil {
 		return nil, err
 	}
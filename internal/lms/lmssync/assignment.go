@@ -1,6 +1,7 @@
 package lmssync
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -12,7 +13,7 @@ import (
 	"github.com/edulinq/autograder/internal/util"
 )
 
-func syncAssignments(course *model.Course, dryRun bool) (*model.AssignmentSyncResult, error) {
+func syncAssignments(ctx context.Context, course *model.Course, dryRun bool) (*model.AssignmentSyncResult, error) {
 	result := model.NewAssignmentSyncResult()
 
 	adapter := course.GetLMSAdapter()
@@ -20,7 +21,7 @@ func syncAssignments(course *model.Course, dryRun bool) (*model.AssignmentSyncRe
 		return result, nil
 	}
 
-	lmsAssignments, err := lms.FetchAssignments(course)
+	lmsAssignments, err := lms.FetchAssignments(ctx, course)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get assignments: '%w'.", err)
 	}
@@ -1,22 +1,23 @@
 package lmssync
 
 import (
+	"context"
 	"github.com/edulinq/autograder/internal/model"
 )
 
 // Sync all available aspects of the course with their LMS.
 // Will return nil (with no error) if the course has no LMS.
-func SyncLMS(course *model.Course, dryRun bool, sendEmails bool) (*model.LMSSyncResult, error) {
+func SyncLMS(ctx context.Context, course *model.Course, dryRun bool, sendEmails bool) (*model.LMSSyncResult, error) {
 	if !course.HasLMSAdapter() {
 		return nil, nil
 	}
 
-	userSync, err := SyncAllLMSUsers(course, dryRun, sendEmails)
+	userSync, err := SyncAllLMSUsers(ctx, course, dryRun, sendEmails)
 	if err != nil {
 		return nil, err
 	}
 
-	assignmentSync, err := syncAssignments(course, dryRun)
+	assignmentSync, err := syncAssignments(ctx, course, dryRun)
 	if err != nil {
 		return nil, err
 	}
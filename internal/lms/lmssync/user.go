@@ -1,6 +1,7 @@
 package lmssync
 
 import (
+	"context"
 	"fmt"
 	"slices"
 
@@ -12,8 +13,8 @@ import (
 )
 
 // Sync users with the provided LMS.
-func SyncAllLMSUsers(course *model.Course, dryRun bool, sendEmails bool) ([]*model.UserOpResult, error) {
-	lmsUsersSlice, err := lms.FetchUsers(course)
+func SyncAllLMSUsers(ctx context.Context, course *model.Course, dryRun bool, sendEmails bool) ([]*model.UserOpResult, error) {
+	lmsUsersSlice, err := lms.FetchUsers(ctx, course)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to fetch LMS users: '%w'.", err)
 	}
@@ -28,15 +29,15 @@ func SyncAllLMSUsers(course *model.Course, dryRun bool, sendEmails bool) ([]*mod
 	return syncLMSUsers(course, dryRun, sendEmails, false, lmsUsers)
 }
 
-func SyncLMSUserEmail(course *model.Course, email string, dryRun bool, sendEmails bool) ([]*model.UserOpResult, error) {
-	return SyncLMSUserEmails(course, []string{email}, dryRun, sendEmails)
+func SyncLMSUserEmail(ctx context.Context, course *model.Course, email string, dryRun bool, sendEmails bool) ([]*model.UserOpResult, error) {
+	return SyncLMSUserEmails(ctx, course, []string{email}, dryRun, sendEmails)
 }
 
-func SyncLMSUserEmails(course *model.Course, emails []string, dryRun bool, sendEmails bool) ([]*model.UserOpResult, error) {
+func SyncLMSUserEmails(ctx context.Context, course *model.Course, emails []string, dryRun bool, sendEmails bool) ([]*model.UserOpResult, error) {
 	lmsUsers := make(map[string]*lmstypes.User)
 
 	for _, email := range emails {
-		lmsUser, err := lms.FetchUser(course, email)
+		lmsUser, err := lms.FetchUser(ctx, course, email)
 		if err != nil {
 			return nil, err
 		}
@@ -49,6 +50,15 @@ func SyncLMSUserEmails(course *model.Course, emails []string, dryRun bool, sendE
 	return syncLMSUsers(course, dryRun, sendEmails, true, lmsUsers)
 }
 
+// Sync a user that was provided directly by the LMS (e.g., from an LTI launch) instead of being fetched.
+func SyncLMSUser(course *model.Course, lmsUser *lmstypes.User, dryRun bool, sendEmails bool) ([]*model.UserOpResult, error) {
+	lmsUsers := map[string]*lmstypes.User{
+		lmsUser.Email: lmsUser,
+	}
+
+	return syncLMSUsers(course, dryRun, sendEmails, true, lmsUsers)
+}
+
 // Sync LMS users.
 // Note that |skipMissing| makes it so that only users in |lmsUsers| will be considered.
 // This means that deletes will never be processed (since they are always in the LMS).
@@ -1,6 +1,7 @@
 package lmssync
 
 import (
+	"context"
 	"fmt"
 	"slices"
 	"testing"
@@ -43,7 +44,7 @@ func TestCourseSyncLMSUserEmails(test *testing.T) {
 	course.GetLMSAdapter().SyncUserAttributes = true
 
 	emails := []string{email}
-	results, err := SyncLMSUserEmails(course, emails, false, false)
+	results, err := SyncLMSUserEmails(context.Background(), course, emails, false, false)
 	if err != nil {
 		test.Fatalf("Got an error when syncing known user: '%v'.", err)
 	}
@@ -59,7 +60,7 @@ func TestCourseSyncLMSUserEmails(test *testing.T) {
 	}
 
 	emails = []string{"ZZZ@test.edulinq.org"}
-	results, err = SyncLMSUserEmails(course, emails, false, false)
+	results, err = SyncLMSUserEmails(context.Background(), course, emails, false, false)
 	if err != nil {
 		test.Fatalf("Got an error when syncing unknown user: '%v'.", err)
 	}
@@ -105,7 +106,7 @@ func TestCourseSyncLMSUsers(test *testing.T) {
 		course.GetLMSAdapter().SyncUserAdds = testCase.syncAdd
 		course.GetLMSAdapter().SyncUserRemoves = testCase.syncDel
 
-		result, err := SyncAllLMSUsers(course, false, true)
+		result, err := SyncAllLMSUsers(context.Background(), course, false, true)
 		if err != nil {
 			test.Errorf("Case %d (%s): User sync failed: '%v'.", i, label, err)
 			continue
@@ -0,0 +1,108 @@
+package lms
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/tracing"
+)
+
+// Wraps a backend so that each LMS call gets its own span
+// (as a child of any span in the caller's context).
+type tracingBackend struct {
+	lmsBackend
+
+	ctx    context.Context
+	course *model.Course
+}
+
+func withTracing(ctx context.Context, course *model.Course, backend lmsBackend) lmsBackend {
+	return &tracingBackend{
+		lmsBackend: backend,
+		ctx:        ctx,
+		course:     course,
+	}
+}
+
+func (this *tracingBackend) startSpan(operation string, assignmentID string) trace.Span {
+	_, span := tracing.StartSpan(this.ctx, "lms."+operation,
+		tracing.NewAttr("course", this.course.GetID()), tracing.NewAttr("lms-type", this.course.GetLMSAdapter().Type),
+		tracing.NewAttr("lms-assignment", assignmentID))
+
+	return span
+}
+
+func (this *tracingBackend) FetchAssignments() ([]*lmstypes.Assignment, error) {
+	span := this.startSpan("FetchAssignments", "")
+	assignments, err := this.lmsBackend.FetchAssignments()
+	tracing.EndSpan(span, err)
+
+	return assignments, err
+}
+
+func (this *tracingBackend) FetchAssignment(assignmentID string) (*lmstypes.Assignment, error) {
+	span := this.startSpan("FetchAssignment", assignmentID)
+	assignment, err := this.lmsBackend.FetchAssignment(assignmentID)
+	tracing.EndSpan(span, err)
+
+	return assignment, err
+}
+
+func (this *tracingBackend) UpdateComments(assignmentID string, comments []*lmstypes.SubmissionComment) error {
+	span := this.startSpan("UpdateComments", assignmentID)
+	err := this.lmsBackend.UpdateComments(assignmentID, comments)
+	tracing.EndSpan(span, err)
+
+	return err
+}
+
+func (this *tracingBackend) UpdateComment(assignmentID string, comment *lmstypes.SubmissionComment) error {
+	span := this.startSpan("UpdateComment", assignmentID)
+	err := this.lmsBackend.UpdateComment(assignmentID, comment)
+	tracing.EndSpan(span, err)
+
+	return err
+}
+
+func (this *tracingBackend) FetchAssignmentScores(assignmentID string) ([]*lmstypes.SubmissionScore, error) {
+	span := this.startSpan("FetchAssignmentScores", assignmentID)
+	scores, err := this.lmsBackend.FetchAssignmentScores(assignmentID)
+	tracing.EndSpan(span, err)
+
+	return scores, err
+}
+
+func (this *tracingBackend) FetchAssignmentScore(assignmentID string, userID string) (*lmstypes.SubmissionScore, error) {
+	span := this.startSpan("FetchAssignmentScore", assignmentID)
+	score, err := this.lmsBackend.FetchAssignmentScore(assignmentID, userID)
+	tracing.EndSpan(span, err)
+
+	return score, err
+}
+
+func (this *tracingBackend) UpdateAssignmentScores(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+	span := this.startSpan("UpdateAssignmentScores", assignmentID)
+	err := this.lmsBackend.UpdateAssignmentScores(assignmentID, scores)
+	tracing.EndSpan(span, err)
+
+	return err
+}
+
+func (this *tracingBackend) FetchUsers() ([]*lmstypes.User, error) {
+	span := this.startSpan("FetchUsers", "")
+	users, err := this.lmsBackend.FetchUsers()
+	tracing.EndSpan(span, err)
+
+	return users, err
+}
+
+func (this *tracingBackend) FetchUser(email string) (*lmstypes.User, error) {
+	span := this.startSpan("FetchUser", "")
+	user, err := this.lmsBackend.FetchUser(email)
+	tracing.EndSpan(span, err)
+
+	return user, err
+}
@@ -0,0 +1,49 @@
+package lms
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/tracing"
+)
+
+func TestLMSTracingUsesCallerContext(test *testing.T) {
+	course := db.MustGetTestCourse()
+
+	exporter := tracetest.NewInMemoryExporter()
+	defer tracing.SetExporterForTesting(exporter)()
+
+	ctx, span := tracing.StartSpan(context.Background(), "test.root")
+	traceID := tracing.GetTraceID(ctx)
+
+	_, err := FetchAssignments(ctx, course)
+	span.End()
+
+	if err != nil {
+		test.Fatalf("Failed to fetch assignments: '%v'.", err)
+	}
+
+	expectedNames := []string{"lms.FetchAssignments", "test.root"}
+
+	spans := exporter.GetSpans()
+	if len(spans) != len(expectedNames) {
+		test.Fatalf("Unexpected number of spans. Expected: %d, Actual: %d.", len(expectedNames), len(spans))
+	}
+
+	for i, span := range spans {
+		if span.Name != expectedNames[i] {
+			test.Errorf("Span %d: Unexpected name. Expected: '%s', Actual: '%s'.", i, expectedNames[i], span.Name)
+		}
+
+		if span.SpanContext.TraceID().String() != traceID {
+			test.Errorf("Span %d: Unexpected trace ID. Expected: '%s', Actual: '%s'.", i, traceID, span.SpanContext.TraceID().String())
+		}
+	}
+
+	if spans[0].Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		test.Fatalf("LMS span is not a child of the caller's span.")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,6 +25,15 @@ type RawLogQuery struct {
 	CourseID     string `json:"target-course,omitempty"`
 	AssignmentID string `json:"target-assignment,omitempty"`
 	TargetUser   string `json:"target-email,omitempty"`
+
+	BeforeString string            `json:"before,omitempty"`
+	MessageText  string            `json:"message,omitempty"`
+	ErrorText    string            `json:"error,omitempty"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+	Sort   int `json:"sort,omitempty"`
 }
 
 // The fully parsed query to be executed.
@@ -34,6 +44,29 @@ type ParsedLogQuery struct {
 	CourseID     string
 	AssignmentID string
 	UserEmail    string
+
+	// Only match records from before this time.
+	// A value of zero is treated as the end of time.
+	Before timestamp.Timestamp
+
+	// Only match records that contain this text (case-insensitive) in their message/error.
+	MessageText string
+	ErrorText   string
+
+	// Only match records that have all these attributes.
+	// Values are compared against the string representation of the record's attribute.
+	Attributes map[string]string
+
+	// Take at most this many records (after sorting and skipping the offset).
+	// A non-positive number means no limit will be applied.
+	Limit int
+
+	// Skip this many records (after sorting).
+	Offset int
+
+	// Define how the results should be sorted by time.
+	// -1 for ascending, 0 for no sorting (storage order), 1 for descending.
+	Sort int
 }
 
 // Parse a raw log query and return a version with clean attributes.
@@ -72,6 +105,32 @@ func (this RawLogQuery) Parse() (*ParsedLogQuery, []error) {
 
 	parsed.UserEmail = this.TargetUser
 
+	parsed.Before, err = parseLogQueryTime("before", this.BeforeString)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if !parsed.Before.IsZero() && (parsed.Before <= parsed.After) {
+		errs = append(errs, fmt.Errorf("The 'before' component of log query ('%s') must be later than the 'after' component ('%s').",
+			parsed.Before.SafeString(), parsed.After.SafeString()))
+	}
+
+	parsed.MessageText = this.MessageText
+	parsed.ErrorText = this.ErrorText
+
+	parsed.Attributes, err = parseLogQueryAttributes(this.Attributes)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if this.Offset < 0 {
+		errs = append(errs, fmt.Errorf("Negative 'offset' component of log query (%d).", this.Offset))
+	}
+
+	parsed.Limit = max(0, this.Limit)
+	parsed.Offset = max(0, this.Offset)
+	parsed.Sort = normalizeSort(this.Sort)
+
 	return &parsed, errs
 }
 
@@ -153,16 +212,20 @@ func parseLogQueryTiming(now timestamp.Timestamp, afterString string, pastString
 }
 
 func parseLogQueryAfter(afterString string) (timestamp.Timestamp, error) {
-	if afterString == "" {
+	return parseLogQueryTime("after", afterString)
+}
+
+func parseLogQueryTime(name string, timeString string) (timestamp.Timestamp, error) {
+	if timeString == "" {
 		return timestamp.Zero(), nil
 	}
 
-	after, err := timestamp.GuessFromString(afterString)
+	instance, err := timestamp.GuessFromString(timeString)
 	if err != nil {
-		return timestamp.Zero(), fmt.Errorf("Could not parse 'after' component of log query ('%s'): '%v'.", afterString, err)
+		return timestamp.Zero(), fmt.Errorf("Could not parse '%s' component of log query ('%s'): '%v'.", name, timeString, err)
 	}
 
-	return after, nil
+	return instance, nil
 }
 
 func parseLogQueryPastDuration(pastString string) (timestamp.Timestamp, error) {
@@ -190,6 +253,37 @@ func parseLogQueryID(id string) (string, error) {
 	return strings.TrimSpace(id), nil
 }
 
+func parseLogQueryAttributes(attributes map[string]string) (map[string]string, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+
+	parsed := make(map[string]string, len(attributes))
+	for key, value := range attributes {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("Empty attribute key in 'attributes' component of log query.")
+		}
+
+		parsed[key] = value
+	}
+
+	return parsed, nil
+}
+
+// Ensure the semantics of sort ordering are followed.
+func normalizeSort(sort int) int {
+	if sort < 0 {
+		return -1
+	}
+
+	if sort > 0 {
+		return 1
+	}
+
+	return 0
+}
+
 func (this RawLogQuery) String() string {
 	text, err := json.Marshal(this)
 	if err != nil {
@@ -228,6 +322,34 @@ func (this ParsedLogQuery) String() string {
 	}
 	builder.WriteString(fmt.Sprintf(", User: '%s'", user))
 
+	if !this.Before.IsZero() {
+		builder.WriteString(fmt.Sprintf(", Before: '%s'", this.Before.SafeString()))
+	}
+
+	if this.MessageText != "" {
+		builder.WriteString(fmt.Sprintf(", Message: '%s'", this.MessageText))
+	}
+
+	if this.ErrorText != "" {
+		builder.WriteString(fmt.Sprintf(", Error: '%s'", this.ErrorText))
+	}
+
+	if len(this.Attributes) > 0 {
+		builder.WriteString(fmt.Sprintf(", Attributes: %v", this.Attributes))
+	}
+
+	if this.Limit > 0 {
+		builder.WriteString(fmt.Sprintf(", Limit: %d", this.Limit))
+	}
+
+	if this.Offset > 0 {
+		builder.WriteString(fmt.Sprintf(", Offset: %d", this.Offset))
+	}
+
+	if this.Sort != 0 {
+		builder.WriteString(fmt.Sprintf(", Sort: %d", this.Sort))
+	}
+
 	return builder.String()
 }
 
@@ -259,5 +381,69 @@ func (this ParsedLogQuery) Match(record *Record) bool {
 		return false
 	}
 
+	if !this.Before.IsZero() && (record.Timestamp >= this.Before) {
+		return false
+	}
+
+	if !containsFold(record.Message, this.MessageText) {
+		return false
+	}
+
+	if !containsFold(record.Error, this.ErrorText) {
+		return false
+	}
+
+	for key, expected := range this.Attributes {
+		value, exists := record.Attributes[key]
+		if !exists || (fmt.Sprintf("%v", value) != expected) {
+			return false
+		}
+	}
+
 	return true
 }
+
+// Check if this query filters on the content of records (text or attributes).
+// Storage backends generally cannot apply these filters themselves,
+// so they have to match (and then sort and paginate) records in Go.
+func (this ParsedLogQuery) HasContentFilters() bool {
+	return (this.MessageText != "") || (this.ErrorText != "") || (len(this.Attributes) > 0)
+}
+
+// Apply the sorting and pagination (offset and limit) parts of a query to records that have already been matched.
+func LimitAndSort(records []*Record, query ParsedLogQuery) []*Record {
+	sortOrder := normalizeSort(query.Sort)
+	if sortOrder != 0 {
+		// Use a stable sort so records with the same timestamp keep their storage order.
+		slices.SortStableFunc(records, func(a *Record, b *Record) int {
+			if a.Timestamp == b.Timestamp {
+				return 0
+			}
+
+			if a.Timestamp < b.Timestamp {
+				return sortOrder
+			}
+
+			return -sortOrder
+		})
+	}
+
+	if query.Offset > 0 {
+		offset := min(query.Offset, len(records))
+		records = records[offset:]
+	}
+
+	if (query.Limit > 0) && (query.Limit < len(records)) {
+		records = records[0:query.Limit]
+	}
+
+	return records
+}
+
+func containsFold(text string, search string) bool {
+	if search == "" {
+		return true
+	}
+
+	return strings.Contains(strings.ToLower(text), strings.ToLower(search))
+}
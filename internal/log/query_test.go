@@ -221,6 +221,101 @@ func TestLogQueryBase(test *testing.T) {
 			[]string{},
 			false,
 		},
+
+		{
+			RawLogQuery{
+				AfterString:  "2000-01-02",
+				BeforeString: "2000-01-03",
+			},
+			ParsedLogQuery{
+				After:  timestamp.MustGuessFromString("2000-01-02T00:00:00Z"),
+				Before: timestamp.MustGuessFromString("2000-01-03T00:00:00Z"),
+			},
+			[]string{},
+			false,
+		},
+		{
+			RawLogQuery{
+				BeforeString: "ZZZ",
+			},
+			ParsedLogQuery{},
+			[]string{
+				"Could not parse 'before' component of log query ('ZZZ'): 'Could not guess time 'ZZZ'.'.",
+			},
+			false,
+		},
+		{
+			RawLogQuery{
+				AfterString:  "2000-01-03",
+				BeforeString: "2000-01-02",
+			},
+			ParsedLogQuery{},
+			[]string{
+				"The 'before' component of log query ('2000-01-02T00:00:00Z') must be later than the 'after' component ('2000-01-03T00:00:00Z').",
+			},
+			false,
+		},
+
+		{
+			RawLogQuery{
+				MessageText: "Course",
+				ErrorText:   "warning",
+				Attributes:  map[string]string{" container-name ": "abc"},
+			},
+			ParsedLogQuery{
+				MessageText: "Course",
+				ErrorText:   "warning",
+				Attributes:  map[string]string{"container-name": "abc"},
+			},
+			[]string{},
+			false,
+		},
+		{
+			RawLogQuery{
+				Attributes: map[string]string{" ": "abc"},
+			},
+			ParsedLogQuery{},
+			[]string{
+				"Empty attribute key in 'attributes' component of log query.",
+			},
+			false,
+		},
+
+		{
+			RawLogQuery{
+				Limit:  10,
+				Offset: 5,
+				Sort:   -3,
+			},
+			ParsedLogQuery{
+				Limit:  10,
+				Offset: 5,
+				Sort:   -1,
+			},
+			[]string{},
+			false,
+		},
+		{
+			RawLogQuery{
+				Limit: -1,
+				Sort:  2,
+			},
+			ParsedLogQuery{
+				Sort: 1,
+			},
+			[]string{},
+			false,
+		},
+		{
+			RawLogQuery{
+				Offset: -1,
+			},
+			ParsedLogQuery{},
+			[]string{
+				"Negative 'offset' component of log query (-1).",
+			},
+			false,
+		},
 	}
 
 	for i, testCase := range testCases {
@@ -245,7 +340,7 @@ func TestLogQueryBase(test *testing.T) {
 			actual.After = testCase.expected.After
 		}
 
-		if testCase.expected != *actual {
+		if !reflect.DeepEqual(testCase.expected, *actual) {
 			test.Fatalf("Case %d: Parsed query not as expected. Expected: '%v', Actual: '%v'.", i,
 				testCase.expected, *actual)
 		}
@@ -519,6 +614,120 @@ func TestParsedLogQueryMatch(test *testing.T) {
 			},
 			false,
 		},
+
+		// Before
+		{
+			ParsedLogQuery{
+				Level:  LevelInfo,
+				Before: timestamp.Timestamp(200),
+			},
+			&Record{
+				Level:     LevelInfo,
+				Timestamp: timestamp.Timestamp(100),
+			},
+			true,
+		},
+		{
+			ParsedLogQuery{
+				Level:  LevelInfo,
+				Before: timestamp.Timestamp(100),
+			},
+			&Record{
+				Level:     LevelInfo,
+				Timestamp: timestamp.Timestamp(100),
+			},
+			false,
+		},
+
+		// Message
+		{
+			ParsedLogQuery{
+				Level:       LevelInfo,
+				MessageText: "GRADING",
+			},
+			&Record{
+				Level:     LevelInfo,
+				Message:   "Failed grading.",
+				Timestamp: timestamp.Timestamp(100),
+			},
+			true,
+		},
+		{
+			ParsedLogQuery{
+				Level:       LevelInfo,
+				MessageText: "ZZZ",
+			},
+			&Record{
+				Level:     LevelInfo,
+				Message:   "Failed grading.",
+				Timestamp: timestamp.Timestamp(100),
+			},
+			false,
+		},
+
+		// Error
+		{
+			ParsedLogQuery{
+				Level:     LevelInfo,
+				ErrorText: "timeout",
+			},
+			&Record{
+				Level:     LevelInfo,
+				Message:   "Failed grading.",
+				Error:     "Container Timeout",
+				Timestamp: timestamp.Timestamp(100),
+			},
+			true,
+		},
+		{
+			ParsedLogQuery{
+				Level:     LevelInfo,
+				ErrorText: "timeout",
+			},
+			&Record{
+				Level:     LevelInfo,
+				Message:   "Container timeout.",
+				Timestamp: timestamp.Timestamp(100),
+			},
+			false,
+		},
+
+		// Attributes
+		{
+			ParsedLogQuery{
+				Level:      LevelInfo,
+				Attributes: map[string]string{"container-name": "abc", "count": "3"},
+			},
+			&Record{
+				Level:      LevelInfo,
+				Timestamp:  timestamp.Timestamp(100),
+				Attributes: map[string]any{"container-name": "abc", "count": 3, "other": "xyz"},
+			},
+			true,
+		},
+		{
+			ParsedLogQuery{
+				Level:      LevelInfo,
+				Attributes: map[string]string{"container-name": "abc"},
+			},
+			&Record{
+				Level:      LevelInfo,
+				Timestamp:  timestamp.Timestamp(100),
+				Attributes: map[string]any{"container-name": "ABC"},
+			},
+			false,
+		},
+		{
+			ParsedLogQuery{
+				Level:      LevelInfo,
+				Attributes: map[string]string{"container-name": "abc"},
+			},
+			&Record{
+				Level:     LevelInfo,
+				Timestamp: timestamp.Timestamp(100),
+			},
+			false,
+		},
 	}
 
 	for i, testCase := range testCases {
@@ -530,3 +739,61 @@ func TestParsedLogQueryMatch(test *testing.T) {
 		}
 	}
 }
+
+func TestLogQueryLimitAndSort(test *testing.T) {
+	testCases := []struct {
+		query    ParsedLogQuery
+		expected []string
+	}{
+		{ParsedLogQuery{}, []string{"B", "A", "C", "D"}},
+		{ParsedLogQuery{Sort: -1}, []string{"A", "C", "B", "D"}},
+		{ParsedLogQuery{Sort: 1}, []string{"D", "B", "A", "C"}},
+		{ParsedLogQuery{Limit: 2}, []string{"B", "A"}},
+		{ParsedLogQuery{Limit: 10}, []string{"B", "A", "C", "D"}},
+		{ParsedLogQuery{Offset: 1}, []string{"A", "C", "D"}},
+		{ParsedLogQuery{Offset: 10}, []string{}},
+		{ParsedLogQuery{Sort: -1, Offset: 1, Limit: 2}, []string{"C", "B"}},
+	}
+
+	for i, testCase := range testCases {
+		// Note that A and C have the same timestamp.
+		records := []*Record{
+			&Record{Message: "B", Timestamp: timestamp.Timestamp(200)},
+			&Record{Message: "A", Timestamp: timestamp.Timestamp(100)},
+			&Record{Message: "C", Timestamp: timestamp.Timestamp(100)},
+			&Record{Message: "D", Timestamp: timestamp.Timestamp(300)},
+		}
+
+		actual := make([]string, 0)
+		for _, record := range LimitAndSort(records, testCase.query) {
+			actual = append(actual, record.Message)
+		}
+
+		if !reflect.DeepEqual(testCase.expected, actual) {
+			test.Errorf("Case %d: Unexpected records. Expected: '%v', Actual: '%v'.", i, testCase.expected, actual)
+			continue
+		}
+	}
+}
+
+func TestParsedLogQueryHasContentFilters(test *testing.T) {
+	testCases := []struct {
+		query    ParsedLogQuery
+		expected bool
+	}{
+		{ParsedLogQuery{}, false},
+		{ParsedLogQuery{Level: LevelWarn, CourseID: "course101", Before: timestamp.Timestamp(100), Sort: 1, Limit: 2, Offset: 1}, false},
+		{ParsedLogQuery{MessageText: "abc"}, true},
+		{ParsedLogQuery{ErrorText: "abc"}, true},
+		{ParsedLogQuery{Attributes: map[string]string{"abc": "123"}}, true},
+		{ParsedLogQuery{Attributes: map[string]string{}}, false},
+	}
+
+	for i, testCase := range testCases {
+		actual := testCase.query.HasContentFilters()
+		if testCase.expected != actual {
+			test.Errorf("Case %d: Unexpected result. Expected: '%v', Actual: '%v'.", i, testCase.expected, actual)
+			continue
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package lti
+
+import (
+	"strings"
+
+	"github.com/edulinq/autograder/internal/model"
+)
+
+// See: https://www.imsglobal.org/spec/lti/v1p3 .
+const (
+	LTI_VERSION                  = "1.3.0"
+	MESSAGE_TYPE_RESOURCE_LINK   = "LtiResourceLinkRequest"
+	CLAIM_PREFIX                 = "https://purl.imsglobal.org/spec/lti/claim/"
+	CLAIM_MESSAGE_TYPE           = CLAIM_PREFIX + "message_type"
+	CLAIM_VERSION                = CLAIM_PREFIX + "version"
+	CLAIM_DEPLOYMENT_ID          = CLAIM_PREFIX + "deployment_id"
+	CLAIM_TARGET_LINK_URI        = CLAIM_PREFIX + "target_link_uri"
+	CLAIM_RESOURCE_LINK          = CLAIM_PREFIX + "resource_link"
+	CLAIM_ROLES                  = CLAIM_PREFIX + "roles"
+	CLAIM_CONTEXT                = CLAIM_PREFIX + "context"
+	CLAIM_CUSTOM                 = CLAIM_PREFIX + "custom"
+	CLAIM_AGS_ENDPOINT           = "https://purl.imsglobal.org/spec/lti-ags/claim/endpoint"
+	CLAIM_NRPS                   = "https://purl.imsglobal.org/spec/lti-nrps/claim/namesroleservice"
+	CUSTOM_ASSIGNMENT_ID         = "assignment_id"
+	SCOPE_AGS_LINE_ITEM_READONLY = "https://purl.imsglobal.org/spec/lti-ags/scope/lineitem.readonly"
+	SCOPE_AGS_RESULT_READONLY    = "https://purl.imsglobal.org/spec/lti-ags/scope/result.readonly"
+	SCOPE_AGS_SCORE              = "https://purl.imsglobal.org/spec/lti-ags/scope/score"
+	SCOPE_NRPS_MEMBERSHIP        = "https://purl.imsglobal.org/spec/lti-nrps/scope/contextmembership.readonly"
+)
+
+const (
+	ROLE_PREFIX             = "http://purl.imsglobal.org/vocab/lis/v2/membership"
+	ROLE_ADMINISTRATOR      = ROLE_PREFIX + "#Administrator"
+	ROLE_CONTENT_DEVELOPER  = ROLE_PREFIX + "#ContentDeveloper"
+	ROLE_INSTRUCTOR         = ROLE_PREFIX + "#Instructor"
+	ROLE_LEARNER            = ROLE_PREFIX + "#Learner"
+	ROLE_MENTOR             = ROLE_PREFIX + "#Mentor"
+	ROLE_TEACHING_ASSISTANT = ROLE_PREFIX + "/Instructor#TeachingAssistant"
+)
+
+// LTI context (membership) role to autograder role.
+// Services may use the short role names, so both are included.
+var roleMapping map[string]model.CourseUserRole = map[string]model.CourseUserRole{
+	ROLE_ADMINISTRATOR:      model.CourseRoleAdmin,
+	ROLE_CONTENT_DEVELOPER:  model.CourseRoleOther,
+	ROLE_INSTRUCTOR:         model.CourseRoleOwner,
+	ROLE_LEARNER:            model.CourseRoleStudent,
+	ROLE_MENTOR:             model.CourseRoleOther,
+	ROLE_TEACHING_ASSISTANT: model.CourseRoleGrader,
+
+	"Administrator":     model.CourseRoleAdmin,
+	"ContentDeveloper":  model.CourseRoleOther,
+	"Instructor":        model.CourseRoleOwner,
+	"Learner":           model.CourseRoleStudent,
+	"Mentor":            model.CourseRoleOther,
+	"TeachingAssistant": model.CourseRoleGrader,
+}
+
+// Get the autograder role for a set of LTI roles.
+// Teaching assistants are a sub-role of instructors (and will always have the instructor role as well),
+// so they take priority over instructors.
+// Otherwise, the highest role is used.
+// Users without any known role are given the "other" role.
+func GetCourseRole(roles []string) model.CourseUserRole {
+	isTA := false
+	var maxRole model.CourseUserRole = model.CourseRoleOther
+
+	for _, role := range roles {
+		role = strings.TrimSpace(role)
+
+		courseRole, exists := roleMapping[role]
+		if !exists {
+			continue
+		}
+
+		if courseRole == model.CourseRoleGrader {
+			isTA = true
+		}
+
+		maxRole = max(maxRole, courseRole)
+	}
+
+	if isTA && (maxRole == model.CourseRoleOwner) {
+		return model.CourseRoleGrader
+	}
+
+	return maxRole
+}
+
+// Get a list of strings from a claim (non-strings are skipped).
+func GetStringList(value any) []string {
+	result := make([]string, 0)
+
+	items, ok := value.([]any)
+	if !ok {
+		return result
+	}
+
+	for _, item := range items {
+		text, ok := item.(string)
+		if ok {
+			result = append(result, text)
+		}
+	}
+
+	return result
+}
+
+// Get a string field from an object claim.
+func GetObjectString(claims map[string]any, claim string, field string) string {
+	object, ok := claims[claim].(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	value, _ := object[field].(string)
+	return value
+}
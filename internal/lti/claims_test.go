@@ -0,0 +1,45 @@
+package lti
+
+import (
+	"testing"
+
+	"github.com/edulinq/autograder/internal/model"
+)
+
+func TestGetCourseRole(test *testing.T) {
+	testCases := []struct {
+		roles    []string
+		expected model.CourseUserRole
+	}{
+		{[]string{}, model.CourseRoleOther},
+		{nil, model.CourseRoleOther},
+		{[]string{"ZZZ"}, model.CourseRoleOther},
+		{[]string{"http://purl.imsglobal.org/vocab/lis/v2/institution/person#Instructor"}, model.CourseRoleOther},
+
+		{[]string{ROLE_LEARNER}, model.CourseRoleStudent},
+		{[]string{ROLE_INSTRUCTOR}, model.CourseRoleOwner},
+		{[]string{ROLE_ADMINISTRATOR}, model.CourseRoleAdmin},
+		{[]string{ROLE_MENTOR}, model.CourseRoleOther},
+		{[]string{ROLE_CONTENT_DEVELOPER}, model.CourseRoleOther},
+		{[]string{ROLE_INSTRUCTOR, ROLE_TEACHING_ASSISTANT}, model.CourseRoleGrader},
+		{[]string{ROLE_TEACHING_ASSISTANT}, model.CourseRoleGrader},
+
+		// Short names.
+		{[]string{"Learner"}, model.CourseRoleStudent},
+		{[]string{"Instructor", "TeachingAssistant"}, model.CourseRoleGrader},
+		{[]string{" Instructor "}, model.CourseRoleOwner},
+
+		// Highest role.
+		{[]string{ROLE_LEARNER, ROLE_INSTRUCTOR}, model.CourseRoleOwner},
+		{[]string{ROLE_LEARNER, ROLE_MENTOR}, model.CourseRoleStudent},
+		{[]string{ROLE_ADMINISTRATOR, ROLE_INSTRUCTOR}, model.CourseRoleOwner},
+		{[]string{ROLE_LEARNER, ROLE_TEACHING_ASSISTANT, ROLE_INSTRUCTOR}, model.CourseRoleGrader},
+	}
+
+	for i, testCase := range testCases {
+		actual := GetCourseRole(testCase.roles)
+		if actual != testCase.expected {
+			test.Errorf("Case %d: Unexpected role. Expected: '%s', Actual: '%s'.", i, testCase.expected.String(), actual.String())
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package lti
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/jwt"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	KEY_DIRNAME  = "lti"
+	KEY_FILENAME = "tool-key.pem"
+	KEY_BITS     = 2048
+)
+
+// The tool's signing key, keyed by the path it was loaded from.
+var toolKey *rsa.PrivateKey = nil
+var toolKeyPath string = ""
+var toolKeyLock sync.Mutex
+
+// Get the path to the tool's private key.
+func GetKeyPath() string {
+	path := config.LTI_KEY_PATH.Get()
+	if path != "" {
+		return path
+	}
+
+	return filepath.Join(config.GetWorkDir(), KEY_DIRNAME, KEY_FILENAME)
+}
+
+// Get the tool's private key (and its ID).
+// If the key does not exist (and the default path is used), it will be generated and saved.
+func GetToolKey() (*rsa.PrivateKey, string, error) {
+	toolKeyLock.Lock()
+	defer toolKeyLock.Unlock()
+
+	path := GetKeyPath()
+	if (toolKey != nil) && (toolKeyPath == path) {
+		return toolKey, getKeyID(&toolKey.PublicKey), nil
+	}
+
+	key, err := loadOrCreateKey(path, (config.LTI_KEY_PATH.Get() == ""))
+	if err != nil {
+		return nil, "", err
+	}
+
+	toolKey = key
+	toolKeyPath = path
+
+	return toolKey, getKeyID(&toolKey.PublicKey), nil
+}
+
+// Get the public key set for the tool (which platforms will use to verify the tool's messages).
+func GetToolKeySet() (*jwt.JSONWebKeySet, error) {
+	key, keyID, err := GetToolKey()
+	if err != nil {
+		return nil, err
+	}
+
+	keySet := &jwt.JSONWebKeySet{
+		Keys: []*jwt.JSONWebKey{
+			jwt.NewJSONWebKey(&key.PublicKey, keyID),
+		},
+	}
+
+	return keySet, nil
+}
+
+// Sign claims with the tool's key.
+func SignToolJWT(claims map[string]any) (string, error) {
+	key, keyID, err := GetToolKey()
+	if err != nil {
+		return "", err
+	}
+
+	return jwt.Sign(claims, key, keyID)
+}
+
+func loadOrCreateKey(path string, create bool) (*rsa.PrivateKey, error) {
+	if util.PathExists(path) {
+		return loadKey(path)
+	}
+
+	if !create {
+		return nil, fmt.Errorf("LTI key file does not exist: '%s'.", path)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, KEY_BITS)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate LTI key: '%w'.", err)
+	}
+
+	err = util.MkDir(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to make LTI key dir '%s': '%w'.", filepath.Dir(path), err)
+	}
+
+	block := &pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}
+
+	err = os.WriteFile(path, pem.EncodeToMemory(block), 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to write LTI key to '%s': '%w'.", path, err)
+	}
+
+	log.Info("Generated a new LTI key.", log.NewAttr("path", path))
+
+	return key, nil
+}
+
+func loadKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read LTI key '%s': '%w'.", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("LTI key '%s' is not PEM encoded.", path)
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err == nil {
+		return key, nil
+	}
+
+	anyKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse LTI key '%s': '%w'.", path, err)
+	}
+
+	key, ok := anyKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("LTI key '%s' is not an RSA key.", path)
+	}
+
+	return key, nil
+}
+
+// Key IDs are derived from the public key.
+func getKeyID(key *rsa.PublicKey) string {
+	hash := sha256.Sum256(key.N.Bytes())
+	return hex.EncodeToString(hash[:8])
+}
@@ -0,0 +1,275 @@
+package launch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/jwt"
+	"github.com/edulinq/autograder/internal/lms/lmssync"
+	"github.com/edulinq/autograder/internal/lms/lmstypes"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/lti"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const SESSION_TOKEN_NAME = "lti"
+
+type LaunchResult struct {
+	Email        string `json:"email"`
+	CourseID     string `json:"course-id"`
+	AssignmentID string `json:"assignment-id,omitempty"`
+	CourseRole   string `json:"course-role"`
+
+	TokenInfo      *model.TokenInfo `json:"token-info"`
+	TokenCleartext string           `json:"token-cleartext"`
+}
+
+// Finish a launch by verifying the platform's ID token,
+// syncing the user into the course (according to the course's LMS adapter),
+// and issuing the user a session token (scoped to the launched course).
+// Returns: (result, user error, internal error).
+// User errors are safe to show to the user.
+func FinishLaunch(state string, idToken string) (*LaunchResult, error, error) {
+	pending := popLoginState(state)
+	if pending == nil {
+		return nil, fmt.Errorf("Unknown or expired LTI launch. Please try launching again."), nil
+	}
+
+	if idToken == "" {
+		return nil, fmt.Errorf("No LTI ID token ('id_token') was provided."), nil
+	}
+
+	options, err := getPlatformOptions(pending.issuer, pending.clientID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if options == nil {
+		return nil, fmt.Errorf("Unknown LTI platform: '%s'.", pending.issuer), nil
+	}
+
+	claims, err := jwt.VerifyWithKeySetURL(idToken, options.KeySetURL)
+	if err != nil {
+		log.Warn("Failed to verify LTI launch.", err, log.NewAttr("issuer", pending.issuer))
+		return nil, fmt.Errorf("Invalid LTI launch: '%v'.", err), nil
+	}
+
+	err = checkClaims(claims, pending)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid LTI launch: '%v'.", err), nil
+	}
+
+	course, err := getCourse(claims, pending)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if course == nil {
+		contextID := lti.GetObjectString(claims, lti.CLAIM_CONTEXT, "id")
+		return nil, fmt.Errorf("No course is linked to LTI context '%s'.", contextID), nil
+	}
+
+	lmsUser, userErr := getUser(claims)
+	if userErr != nil {
+		return nil, userErr, nil
+	}
+
+	courseUser, userErr, err := syncUser(course, lmsUser)
+	if (userErr != nil) || (err != nil) {
+		return nil, userErr, err
+	}
+
+	result := &LaunchResult{
+		Email:      courseUser.Email,
+		CourseID:   course.GetID(),
+		CourseRole: courseUser.Role.String(),
+	}
+
+	assignment := getAssignment(course, claims)
+	if assignment != nil {
+		result.AssignmentID = assignment.GetID()
+	}
+
+	result.TokenInfo, result.TokenCleartext, err = createSessionToken(courseUser.Email, course.GetID())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Info("LTI launch.", course, log.NewAttr("email", result.Email),
+		log.NewAttr("assignment", result.AssignmentID), log.NewAttr("role", result.CourseRole))
+
+	return result, nil, nil
+}
+
+func checkClaims(claims map[string]any, pending *loginState) error {
+	err := jwt.CheckClaims(claims, pending.issuer, pending.clientID, pending.nonce)
+	if err != nil {
+		return err
+	}
+
+	messageType, _ := claims[lti.CLAIM_MESSAGE_TYPE].(string)
+	if messageType != lti.MESSAGE_TYPE_RESOURCE_LINK {
+		return fmt.Errorf("Unsupported LTI message type: '%s'.", messageType)
+	}
+
+	version, _ := claims[lti.CLAIM_VERSION].(string)
+	if version != lti.LTI_VERSION {
+		return fmt.Errorf("Unsupported LTI version: '%s'.", version)
+	}
+
+	deploymentID, _ := claims[lti.CLAIM_DEPLOYMENT_ID].(string)
+	if deploymentID == "" {
+		return fmt.Errorf("LTI launch is missing a deployment ID.")
+	}
+
+	return nil
+}
+
+// Get the course the launch is for.
+// Returns nil if no course matches.
+func getCourse(claims map[string]any, pending *loginState) (*model.Course, error) {
+	contextID := lti.GetObjectString(claims, lti.CLAIM_CONTEXT, "id")
+	if contextID == "" {
+		return nil, nil
+	}
+
+	deploymentID, _ := claims[lti.CLAIM_DEPLOYMENT_ID].(string)
+
+	courses, err := db.GetCourses()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get courses: '%w'.", err)
+	}
+
+	for _, course := range courses {
+		options := getLTIOptions(course)
+		if options == nil {
+			continue
+		}
+
+		if (course.GetLMSAdapter().LMSCourseID == contextID) && options.Matches(pending.issuer, pending.clientID, deploymentID) {
+			return course, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// The returned error is a user error.
+func getUser(claims map[string]any) (*lmstypes.User, error) {
+	userID, _ := claims["sub"].(string)
+	if userID == "" {
+		return nil, fmt.Errorf("LTI launch does not identify a user (anonymous launches are not supported).")
+	}
+
+	email, _ := claims["email"].(string)
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, fmt.Errorf("LTI launch did not supply an email. Ensure the platform is configured to share user emails with this tool.")
+	}
+
+	if email == model.RootUserEmail {
+		return nil, fmt.Errorf("Root is not allowed to authenticate.")
+	}
+
+	name, _ := claims["name"].(string)
+
+	lmsUser := &lmstypes.User{
+		ID:    userID,
+		Name:  strings.TrimSpace(name),
+		Email: email,
+		Role:  lti.GetCourseRole(lti.GetStringList(claims[lti.CLAIM_ROLES])),
+	}
+
+	return lmsUser, nil
+}
+
+// Sync the launching user into the course (respecting the course's LMS sync options).
+// Returns: (course user, user error, internal error).
+func syncUser(course *model.Course, lmsUser *lmstypes.User) (*model.CourseUser, error, error) {
+	results, err := lmssync.SyncLMSUser(course, lmsUser, false, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to sync LTI user '%s': '%w'.", lmsUser.Email, err)
+	}
+
+	for _, result := range results {
+		if result.ValidationError != nil {
+			return nil, fmt.Errorf("Could not sync LTI user '%s': '%s'.", lmsUser.Email, result.ValidationError.ExternalMessage), nil
+		}
+
+		if result.HasErrors() {
+			return nil, nil, fmt.Errorf("Failed to sync LTI user '%s': '%s'.", lmsUser.Email, util.MustToJSON(result.ToExternalResult()))
+		}
+	}
+
+	courseUser, err := db.GetCourseUser(course, lmsUser.Email)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to get course user '%s': '%w'.", lmsUser.Email, err)
+	}
+
+	if courseUser == nil {
+		return nil, fmt.Errorf("User '%s' is not enrolled in course '%s'.", lmsUser.Email, course.GetID()), nil
+	}
+
+	return courseUser, nil, nil
+}
+
+// Get the assignment (if any) that the launch is for.
+// The assignment is identified by (in order):
+// the "assignment_id" custom parameter, the AGS line item, or the resource link.
+func getAssignment(course *model.Course, claims map[string]any) *model.Assignment {
+	assignmentID := lti.GetObjectString(claims, lti.CLAIM_CUSTOM, lti.CUSTOM_ASSIGNMENT_ID)
+	if assignmentID != "" {
+		return course.GetAssignment(assignmentID)
+	}
+
+	lmsIDs := []string{
+		lti.GetObjectString(claims, lti.CLAIM_AGS_ENDPOINT, "lineitem"),
+		lti.GetObjectString(claims, lti.CLAIM_RESOURCE_LINK, "id"),
+	}
+
+	for _, lmsID := range lmsIDs {
+		if lmsID == "" {
+			continue
+		}
+
+		for _, assignment := range course.GetAssignments() {
+			if assignment.GetLMSID() == lmsID {
+				return assignment
+			}
+		}
+	}
+
+	return nil
+}
+
+func createSessionToken(email string, courseID string) (*model.TokenInfo, string, error) {
+	user, err := db.GetServerUser(email)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to get user '%s': '%w'.", email, err)
+	}
+
+	if user == nil {
+		return nil, "", fmt.Errorf("Could not find user '%s'.", email)
+	}
+
+	expiration := timestamp.Now() + timestamp.FromMSecs(int64(config.LTI_SESSION_SECS.Get())*1000)
+	scope := &model.TokenScope{
+		Courses: []string{courseID},
+	}
+
+	token, cleartext, err := user.CreateRandomTokenFull(SESSION_TOKEN_NAME, model.TokenSourceLTI, &expiration, scope)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to create session token for user '%s': '%w'.", email, err)
+	}
+
+	err = db.UpsertUser(user)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to save user '%s': '%w'.", email, err)
+	}
+
+	return &token.TokenInfo, cleartext, nil
+}
@@ -0,0 +1,398 @@
+package launch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/lti"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestLaunchBase(test *testing.T) {
+	platform := mustStartTestPlatform(test)
+	defer platform.Close()
+
+	testCases := []struct {
+		claims       map[string]any
+		syncAdds     bool
+		email        string
+		role         string
+		assignmentID string
+		userErrorSub string
+	}{
+		// Existing user.
+		{
+			map[string]any{"email": "course-student@test.edulinq.org"},
+			true, "course-student@test.edulinq.org", "student", "", "",
+		},
+		{
+			map[string]any{"email": "course-student@test.edulinq.org"},
+			false, "course-student@test.edulinq.org", "student", "", "",
+		},
+
+		// Role changes are synced.
+		{
+			map[string]any{"email": "course-student@test.edulinq.org", lti.CLAIM_ROLES: []any{lti.ROLE_INSTRUCTOR, lti.ROLE_TEACHING_ASSISTANT}},
+			true, "course-student@test.edulinq.org", "grader", "", "",
+		},
+
+		// New user.
+		{
+			map[string]any{"email": "new-user@test.edulinq.org", "name": "New User", lti.CLAIM_ROLES: []any{lti.ROLE_INSTRUCTOR}},
+			true, "new-user@test.edulinq.org", "owner", "", "",
+		},
+		{
+			map[string]any{"email": "new-user@test.edulinq.org"},
+			false, "", "", "", "is not enrolled in course",
+		},
+
+		// Assignments.
+		{
+			map[string]any{"email": "course-student@test.edulinq.org", lti.CLAIM_CUSTOM: map[string]any{lti.CUSTOM_ASSIGNMENT_ID: "hw0"}},
+			true, "course-student@test.edulinq.org", "student", "hw0", "",
+		},
+		{
+			map[string]any{"email": "course-student@test.edulinq.org", lti.CLAIM_CUSTOM: map[string]any{lti.CUSTOM_ASSIGNMENT_ID: "ZZZ"}},
+			true, "course-student@test.edulinq.org", "student", "", "",
+		},
+		{
+			map[string]any{"email": "course-student@test.edulinq.org", lti.CLAIM_RESOURCE_LINK: map[string]any{"id": "hw0-lms-id"}},
+			true, "course-student@test.edulinq.org", "student", "hw0", "",
+		},
+
+		// Errors.
+		{
+			map[string]any{},
+			true, "", "", "", "did not supply an email",
+		},
+		{
+			map[string]any{"email": "root"},
+			true, "", "", "", "Root is not allowed",
+		},
+		{
+			map[string]any{"email": "course-student@test.edulinq.org", lti.CLAIM_CONTEXT: map[string]any{"id": "ZZZ"}},
+			true, "", "", "", "No course is linked",
+		},
+		{
+			map[string]any{"email": "course-student@test.edulinq.org", lti.CLAIM_DEPLOYMENT_ID: "ZZZ"},
+			true, "", "", "", "No course is linked",
+		},
+		{
+			map[string]any{"email": "course-student@test.edulinq.org", lti.CLAIM_DEPLOYMENT_ID: nil},
+			true, "", "", "", "missing a deployment ID",
+		},
+		{
+			map[string]any{"email": "course-student@test.edulinq.org", lti.CLAIM_MESSAGE_TYPE: "LtiDeepLinkingRequest"},
+			true, "", "", "", "Unsupported LTI message type",
+		},
+		{
+			map[string]any{"email": "course-student@test.edulinq.org", lti.CLAIM_VERSION: "1.1"},
+			true, "", "", "", "Unsupported LTI version",
+		},
+		{
+			map[string]any{"email": "course-student@test.edulinq.org", "aud": "ZZZ"},
+			true, "", "", "", "not issued for this audience",
+		},
+		{
+			map[string]any{"email": "course-student@test.edulinq.org", "nonce": "ZZZ"},
+			true, "", "", "", "wrong nonce",
+		},
+	}
+
+	for i, testCase := range testCases {
+		setupCourse(test, platform, testCase.syncAdds)
+
+		platform.SetLaunchClaims(testCase.claims)
+
+		result, userErr, err := launch(test, platform, "lti-user-1")
+		if err != nil {
+			test.Errorf("Case %d: Got an unexpected internal error: '%v'.", i, err)
+			continue
+		}
+
+		if userErr != nil {
+			if testCase.userErrorSub == "" {
+				test.Errorf("Case %d: Got an unexpected user error: '%v'.", i, userErr)
+			} else if !strings.Contains(userErr.Error(), testCase.userErrorSub) {
+				test.Errorf("Case %d: Unexpected user error. Expected Substring: '%s', Actual: '%v'.", i, testCase.userErrorSub, userErr)
+			}
+
+			continue
+		}
+
+		if testCase.userErrorSub != "" {
+			test.Errorf("Case %d: Did not get an expected user error: '%s'.", i, testCase.userErrorSub)
+			continue
+		}
+
+		if (result.Email != testCase.email) || (result.CourseRole != testCase.role) || (result.AssignmentID != testCase.assignmentID) || (result.CourseID != "course101") {
+			test.Errorf("Case %d: Unexpected result: '%s'.", i, util.MustToJSON(result))
+			continue
+		}
+
+		user := db.MustGetServerUser(result.Email)
+		if user == nil {
+			test.Errorf("Case %d: Could not find launched user.", i)
+			continue
+		}
+
+		// The LMS ID should be synced (when syncing is enabled).
+		if testCase.syncAdds && (util.PointerToString(user.CourseInfo["course101"].LMSID) != "lti-user-1") {
+			test.Errorf("Case %d: LMS ID was not synced: '%s'.", i, util.MustToJSON(user.CourseInfo["course101"]))
+			continue
+		}
+
+		// The session token should be scoped to the course.
+		token, err := user.AuthToken(util.Sha256HexFromString(result.TokenCleartext))
+		if err != nil {
+			test.Errorf("Case %d: Failed to auth with session token: '%v'.", i, err)
+			continue
+		}
+
+		if (token == nil) || (token.Source != model.TokenSourceLTI) || (token.ExpirationTime == nil) ||
+			(token.Scope == nil) || (util.MustToJSON(token.Scope.Courses) != `["course101"]`) {
+			test.Errorf("Case %d: Session token is not as expected: '%s'.", i, util.MustToJSON(token))
+			continue
+		}
+	}
+}
+
+func TestStartLoginErrors(test *testing.T) {
+	platform := mustStartTestPlatform(test)
+	defer platform.Close()
+
+	setupCourse(test, platform, true)
+
+	testCases := []struct {
+		request      *LoginRequest
+		userErrorSub string
+	}{
+		{&LoginRequest{Issuer: platform.Issuer(), LoginHint: "1"}, ""},
+		{&LoginRequest{Issuer: platform.Issuer(), ClientID: lti.TEST_CLIENT_ID, LoginHint: "1"}, ""},
+		{&LoginRequest{Issuer: platform.Issuer(), ClientID: lti.TEST_CLIENT_ID, DeploymentID: lti.TEST_DEPLOYMENT_ID, LoginHint: "1"}, ""},
+
+		{&LoginRequest{Issuer: platform.Issuer()}, "must have an issuer"},
+		{&LoginRequest{LoginHint: "1"}, "must have an issuer"},
+		{&LoginRequest{Issuer: "ZZZ", LoginHint: "1"}, "Unknown LTI platform"},
+		{&LoginRequest{Issuer: platform.Issuer(), ClientID: "ZZZ", LoginHint: "1"}, "Unknown LTI platform"},
+		{&LoginRequest{Issuer: platform.Issuer(), DeploymentID: "ZZZ", LoginHint: "1"}, "Unknown LTI platform"},
+	}
+
+	for i, testCase := range testCases {
+		authURL, userErr, err := StartLogin(testCase.request, "http://localhost/launch")
+		if err != nil {
+			test.Errorf("Case %d: Got an unexpected internal error: '%v'.", i, err)
+			continue
+		}
+
+		if userErr != nil {
+			if testCase.userErrorSub == "" {
+				test.Errorf("Case %d: Got an unexpected user error: '%v'.", i, userErr)
+			} else if !strings.Contains(userErr.Error(), testCase.userErrorSub) {
+				test.Errorf("Case %d: Unexpected user error. Expected Substring: '%s', Actual: '%v'.", i, testCase.userErrorSub, userErr)
+			}
+
+			continue
+		}
+
+		if testCase.userErrorSub != "" {
+			test.Errorf("Case %d: Did not get an expected user error: '%s'.", i, testCase.userErrorSub)
+			continue
+		}
+
+		if !strings.HasPrefix(authURL, platform.Issuer()+"/auth?") {
+			test.Errorf("Case %d: Unexpected auth URL: '%s'.", i, authURL)
+			continue
+		}
+	}
+}
+
+func TestFinishLaunchBadState(test *testing.T) {
+	platform := mustStartTestPlatform(test)
+	defer platform.Close()
+
+	setupCourse(test, platform, true)
+
+	platform.SetLaunchClaims(map[string]any{"email": "course-student@test.edulinq.org"})
+
+	authURL, userErr, err := StartLogin(&LoginRequest{Issuer: platform.Issuer(), LoginHint: "1"}, "http://localhost/launch")
+	if (userErr != nil) || (err != nil) {
+		test.Fatalf("Failed to start login: '%v', '%v'.", userErr, err)
+	}
+
+	_, form, err := platform.Authorize(authURL)
+	if err != nil {
+		test.Fatalf("Failed to authorize: '%v'.", err)
+	}
+
+	// Unknown state.
+	_, userErr, err = FinishLaunch("ZZZ", form["id_token"])
+	if (userErr == nil) || (err != nil) {
+		test.Fatalf("Did not get a user error for an unknown state: '%v', '%v'.", userErr, err)
+	}
+
+	// Bad token (the state is still consumed).
+	_, userErr, err = FinishLaunch(form["state"], form["id_token"]+"ZZZ")
+	if (userErr == nil) || (err != nil) {
+		test.Fatalf("Did not get a user error for a bad token: '%v', '%v'.", userErr, err)
+	}
+
+	// States can only be used once.
+	_, userErr, err = FinishLaunch(form["state"], form["id_token"])
+	if (userErr == nil) || (err != nil) {
+		test.Fatalf("Did not get a user error for a reused state: '%v', '%v'.", userErr, err)
+	}
+}
+
+func TestLaunchTrustedPlatforms(test *testing.T) {
+	platform := mustStartTestPlatform(test)
+	defer platform.Close()
+
+	testCases := []struct {
+		platforms       string
+		courseKeySetURL string
+		userErrorSub    string
+	}{
+		{platform.Issuer() + "=" + platform.Issuer() + "/jwks", "", ""},
+		{"https://lms.test.edulinq.org=https://lms.test.edulinq.org/jwks, " + platform.Issuer() + "=" + platform.Issuer() + "/jwks", "", ""},
+
+		// The course's key set URL is never used.
+		{platform.Issuer() + "=" + platform.Issuer() + "/jwks", "http://localhost:1/jwks", ""},
+		{platform.Issuer() + "=" + platform.Issuer() + "/ZZZ", platform.Issuer() + "/jwks", "Invalid LTI launch"},
+
+		// Untrusted platforms.
+		{"", "", "Unknown LTI platform"},
+		{"https://lms.test.edulinq.org=https://lms.test.edulinq.org/jwks", "", "Unknown LTI platform"},
+	}
+
+	for i, testCase := range testCases {
+		setupCourse(test, platform, true)
+		config.LTI_PLATFORMS.Set(testCase.platforms)
+
+		if testCase.courseKeySetURL != "" {
+			course := db.MustGetCourse("course101")
+			course.LMS.LTI.KeySetURL = testCase.courseKeySetURL
+			db.MustSaveCourse(course)
+		}
+
+		platform.SetLaunchClaims(map[string]any{"email": "course-student@test.edulinq.org"})
+
+		request := &LoginRequest{
+			Issuer:    platform.Issuer(),
+			ClientID:  lti.TEST_CLIENT_ID,
+			LoginHint: "lti-user-1",
+		}
+
+		authURL, userErr, err := StartLogin(request, "http://localhost/launch")
+		if err != nil {
+			test.Errorf("Case %d: Got an unexpected internal error when starting: '%v'.", i, err)
+			continue
+		}
+
+		var result *LaunchResult = nil
+		if userErr == nil {
+			_, form, err := platform.Authorize(authURL)
+			if err != nil {
+				test.Errorf("Case %d: Failed to authorize: '%v'.", i, err)
+				continue
+			}
+
+			result, userErr, err = FinishLaunch(form["state"], form["id_token"])
+			if err != nil {
+				test.Errorf("Case %d: Got an unexpected internal error when finishing: '%v'.", i, err)
+				continue
+			}
+		}
+
+		if userErr != nil {
+			if testCase.userErrorSub == "" {
+				test.Errorf("Case %d: Got an unexpected user error: '%v'.", i, userErr)
+			} else if !strings.Contains(userErr.Error(), testCase.userErrorSub) {
+				test.Errorf("Case %d: Unexpected user error. Expected Substring: '%s', Actual: '%v'.", i, testCase.userErrorSub, userErr)
+			}
+
+			continue
+		}
+
+		if testCase.userErrorSub != "" {
+			test.Errorf("Case %d: Did not get an expected user error: '%s'.", i, testCase.userErrorSub)
+			continue
+		}
+
+		if result.Email != "course-student@test.edulinq.org" {
+			test.Errorf("Case %d: Unexpected result: '%s'.", i, util.MustToJSON(result))
+			continue
+		}
+	}
+}
+
+func TestLaunchTrustedPlatformsBadConfig(test *testing.T) {
+	platform := mustStartTestPlatform(test)
+	defer platform.Close()
+
+	setupCourse(test, platform, true)
+
+	for i, platforms := range []string{"ZZZ", platform.Issuer() + "=", "=" + platform.Issuer() + "/jwks"} {
+		config.LTI_PLATFORMS.Set(platforms)
+
+		_, _, err := StartLogin(&LoginRequest{Issuer: platform.Issuer(), LoginHint: "1"}, "http://localhost/launch")
+		if err == nil {
+			test.Errorf("Case %d: Did not get an internal error for a bad config: '%s'.", i, platforms)
+			continue
+		}
+	}
+}
+
+func launch(test *testing.T, platform *lti.TestPlatform, userID string) (*LaunchResult, error, error) {
+	request := &LoginRequest{
+		Issuer:    platform.Issuer(),
+		ClientID:  lti.TEST_CLIENT_ID,
+		LoginHint: userID,
+	}
+
+	authURL, userErr, err := StartLogin(request, "http://localhost/launch")
+	if (userErr != nil) || (err != nil) {
+		test.Fatalf("Failed to start login: '%v', '%v'.", userErr, err)
+	}
+
+	_, form, err := platform.Authorize(authURL)
+	if err != nil {
+		test.Fatalf("Failed to authorize: '%v'.", err)
+	}
+
+	return FinishLaunch(form["state"], form["id_token"])
+}
+
+// Reset the DB, trust the platform, and link course101 to the platform.
+func setupCourse(test *testing.T, platform *lti.TestPlatform, syncAdds bool) {
+	db.ResetForTesting()
+	test.Cleanup(db.ResetForTesting)
+
+	oldPlatforms := config.LTI_PLATFORMS.Get()
+	test.Cleanup(func() { config.LTI_PLATFORMS.Set(oldPlatforms) })
+	config.LTI_PLATFORMS.Set(platform.Issuer() + "=" + platform.Issuer() + "/jwks")
+
+	course := db.MustGetCourse("course101")
+
+	course.LMS = platform.Adapter()
+	course.LMS.SyncUserAdds = syncAdds
+
+	course.GetAssignment("hw0").LMSID = "hw0-lms-id"
+
+	err := db.SaveCourse(course)
+	if err != nil {
+		test.Fatalf("Failed to save course: '%v'.", err)
+	}
+}
+
+func mustStartTestPlatform(test *testing.T) *lti.TestPlatform {
+	platform, err := lti.NewTestPlatform()
+	if err != nil {
+		test.Fatalf("Failed to start test platform: '%v'.", err)
+	}
+
+	return platform
+}
@@ -0,0 +1,224 @@
+// The LTI 1.3 launch flow.
+// A launch starts with a third-party initiated login from the platform (StartLogin()),
+// which sends the user back to the platform to authenticate,
+// and ends with the platform posting a signed ID token (FinishLaunch()).
+// See: https://www.imsglobal.org/spec/security/v1p0/#platform-originating-messages .
+package launch
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// How long a user has to complete a launch after the login is started.
+const LOGIN_STATE_TTL_MSECS = 10 * 60 * 1000
+
+// The parameters of a third-party initiated login.
+type LoginRequest struct {
+	Issuer       string
+	ClientID     string
+	DeploymentID string
+	LoginHint    string
+	MessageHint  string
+}
+
+// Information about a launch that has been started, but not finished.
+type loginState struct {
+	issuer     string
+	clientID   string
+	nonce      string
+	expiration timestamp.Timestamp
+}
+
+// Pending launches keyed by state.
+var loginStates map[string]*loginState = make(map[string]*loginState)
+var loginStatesLock sync.Mutex
+
+// Start a launch and get the platform URL that the user should be sent to.
+// |launchURL| is the URL that the platform will post the launch to,
+// and should match the URL registered with the platform.
+// Returns: (platform URL, user error, internal error).
+func StartLogin(request *LoginRequest, launchURL string) (string, error, error) {
+	if (request.Issuer == "") || (request.LoginHint == "") {
+		return "", fmt.Errorf("LTI login requests must have an issuer ('iss') and login hint ('login_hint')."), nil
+	}
+
+	options, err := getPlatformOptions(request.Issuer, request.ClientID, request.DeploymentID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if options == nil {
+		return "", fmt.Errorf("Unknown LTI platform: '%s'.", request.Issuer), nil
+	}
+
+	state, err := util.RandHex(model.DEFAULT_TOKEN_LEN)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to generate LTI login state: '%w'.", err)
+	}
+
+	nonce, err := util.RandHex(model.DEFAULT_TOKEN_LEN)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to generate LTI login nonce: '%w'.", err)
+	}
+
+	storeLoginState(state, &loginState{
+		issuer:     options.Issuer,
+		clientID:   options.ClientID,
+		nonce:      nonce,
+		expiration: timestamp.Now() + LOGIN_STATE_TTL_MSECS,
+	})
+
+	query := url.Values{}
+	query.Set("scope", "openid")
+	query.Set("response_type", "id_token")
+	query.Set("response_mode", "form_post")
+	query.Set("prompt", "none")
+	query.Set("client_id", options.ClientID)
+	query.Set("redirect_uri", launchURL)
+	query.Set("login_hint", request.LoginHint)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+
+	if request.MessageHint != "" {
+		query.Set("lti_message_hint", request.MessageHint)
+	}
+
+	separator := "?"
+	if strings.Contains(options.AuthLoginURL, "?") {
+		separator = "&"
+	}
+
+	return options.AuthLoginURL + separator + query.Encode(), nil, nil
+}
+
+// Get the LTI options for a platform registration.
+// All courses registered with the same platform (issuer and client) share the same platform options.
+// If |clientID| is empty, then the issuer must only have one client registered.
+// Courses can only register with platforms that the server trusts (config.LTI_PLATFORMS),
+// and the returned options always use the trusted key set URL (instead of the course's).
+// Returns nil if no registration matches.
+func getPlatformOptions(issuer string, clientID string, deploymentID string) (*model.LTIOptions, error) {
+	platforms, err := getTrustedPlatforms()
+	if err != nil {
+		return nil, err
+	}
+
+	keySetURL, exists := platforms[issuer]
+	if !exists {
+		return nil, nil
+	}
+
+	courses, err := db.GetCourses()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get courses: '%w'.", err)
+	}
+
+	var result *model.LTIOptions = nil
+	for _, course := range courses {
+		options := getLTIOptions(course)
+		if options == nil {
+			continue
+		}
+
+		matchClientID := clientID
+		if matchClientID == "" {
+			matchClientID = options.ClientID
+		}
+
+		if !options.Matches(issuer, matchClientID, deploymentID) {
+			continue
+		}
+
+		if (result != nil) && (result.ClientID != options.ClientID) {
+			return nil, fmt.Errorf("LTI platform '%s' has multiple clients registered, but the login did not specify a client.", issuer)
+		}
+
+		result = options
+	}
+
+	if result != nil {
+		trustedOptions := *result
+		trustedOptions.KeySetURL = keySetURL
+		result = &trustedOptions
+	}
+
+	return result, nil
+}
+
+// Get the key set URLs of the platforms trusted by the server (keyed by issuer).
+func getTrustedPlatforms() (map[string]string, error) {
+	platforms := make(map[string]string)
+
+	for _, part := range strings.Split(config.LTI_PLATFORMS.Get(), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		issuer, keySetURL, found := strings.Cut(part, "=")
+		issuer = strings.TrimSpace(issuer)
+		keySetURL = strings.TrimSpace(keySetURL)
+
+		if !found || (issuer == "") || (keySetURL == "") {
+			return nil, fmt.Errorf("Trusted LTI platform ('%s') is not of the form 'issuer=key-set-url': '%s'.", config.LTI_PLATFORMS.Key, part)
+		}
+
+		platforms[issuer] = keySetURL
+	}
+
+	return platforms, nil
+}
+
+// Get the LTI options for a course (or nil if the course does not use LTI).
+func getLTIOptions(course *model.Course) *model.LTIOptions {
+	adapter := course.GetLMSAdapter()
+	if (adapter == nil) || (adapter.Type != model.LMS_TYPE_LTI) {
+		return nil
+	}
+
+	return adapter.LTI
+}
+
+func storeLoginState(state string, pending *loginState) {
+	loginStatesLock.Lock()
+	defer loginStatesLock.Unlock()
+
+	// Remove any abandoned launches.
+	now := timestamp.Now()
+	for key, value := range loginStates {
+		if value.expiration < now {
+			delete(loginStates, key)
+		}
+	}
+
+	loginStates[state] = pending
+}
+
+// Get and remove a pending launch (states can only be used once).
+// Returns nil if the state is unknown or expired.
+func popLoginState(state string) *loginState {
+	loginStatesLock.Lock()
+	defer loginStatesLock.Unlock()
+
+	pending, exists := loginStates[state]
+	if !exists {
+		return nil
+	}
+
+	delete(loginStates, state)
+
+	if pending.expiration < timestamp.Now() {
+		return nil
+	}
+
+	return pending
+}
@@ -0,0 +1,200 @@
+package lti
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/edulinq/autograder/internal/jwt"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// Types and helpers for the LTI Advantage services:
+// Assignment and Grade Services (AGS) and Names and Role Provisioning Services (NRPS).
+// See: https://www.imsglobal.org/spec/lti-ags/v2p0 and https://www.imsglobal.org/spec/lti-nrps/v2p0 .
+
+const (
+	CONTENT_TYPE_LINE_ITEM            = "application/vnd.ims.lis.v2.lineitem+json"
+	CONTENT_TYPE_LINE_ITEM_CONTAINER  = "application/vnd.ims.lis.v2.lineitemcontainer+json"
+	CONTENT_TYPE_RESULT_CONTAINER     = "application/vnd.ims.lis.v2.resultcontainer+json"
+	CONTENT_TYPE_SCORE                = "application/vnd.ims.lis.v1.score+json"
+	CONTENT_TYPE_MEMBERSHIP_CONTAINER = "application/vnd.ims.lti-nrps.v2.membershipcontainer+json"
+
+	CLIENT_ASSERTION_TYPE = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	// Refresh access tokens that are this close to expiring.
+	ACCESS_TOKEN_EXPIRATION_BUFFER_SECS = 60
+
+	// How long client assertions are valid for.
+	CLIENT_ASSERTION_TTL_SECS = 5 * 60
+)
+
+type LineItem struct {
+	ID             string  `json:"id,omitempty"`
+	Label          string  `json:"label"`
+	ScoreMaximum   float64 `json:"scoreMaximum"`
+	ResourceLinkID string  `json:"resourceLinkId,omitempty"`
+	ResourceID     string  `json:"resourceId,omitempty"`
+	Tag            string  `json:"tag,omitempty"`
+	EndDateTime    string  `json:"endDateTime,omitempty"`
+}
+
+type Result struct {
+	ID            string   `json:"id,omitempty"`
+	ScoreOf       string   `json:"scoreOf,omitempty"`
+	UserID        string   `json:"userId"`
+	ResultScore   *float64 `json:"resultScore,omitempty"`
+	ResultMaximum *float64 `json:"resultMaximum,omitempty"`
+	Comment       string   `json:"comment,omitempty"`
+}
+
+type Score struct {
+	UserID           string  `json:"userId"`
+	ScoreGiven       float64 `json:"scoreGiven"`
+	ScoreMaximum     float64 `json:"scoreMaximum"`
+	Comment          string  `json:"comment,omitempty"`
+	Timestamp        string  `json:"timestamp"`
+	ActivityProgress string  `json:"activityProgress"`
+	GradingProgress  string  `json:"gradingProgress"`
+}
+
+type MembershipContainer struct {
+	ID      string    `json:"id"`
+	Context *Context  `json:"context"`
+	Members []*Member `json:"members"`
+}
+
+type Context struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+type Member struct {
+	UserID string   `json:"user_id"`
+	Status string   `json:"status,omitempty"`
+	Name   string   `json:"name,omitempty"`
+	Email  string   `json:"email,omitempty"`
+	Roles  []string `json:"roles"`
+}
+
+type accessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+type accessToken struct {
+	token      string
+	expiration int64
+}
+
+// Access tokens keyed by token URL, client ID, and scopes.
+var accessTokens map[string]*accessToken = make(map[string]*accessToken)
+var accessTokensLock sync.Mutex
+
+// Get an access token for the platform's services.
+// Tokens are requested using the OAuth 2 client credentials grant with a JWT signed by the tool.
+// See: https://www.imsglobal.org/spec/security/v1p0/#using-json-web-tokens-with-oauth-2-0-client-credentials-grant .
+func GetAccessToken(options *model.LTIOptions, scopes []string) (string, error) {
+	if options.AuthTokenURL == "" {
+		return "", fmt.Errorf("LTI option 'auth-token-url' is required to use LTI services.")
+	}
+
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	scope := strings.Join(scopes, " ")
+
+	cacheKey := strings.Join([]string{options.AuthTokenURL, options.ClientID, scope}, "::")
+
+	accessTokensLock.Lock()
+	defer accessTokensLock.Unlock()
+
+	now := jwt.NowSecs()
+
+	cachedToken, exists := accessTokens[cacheKey]
+	if exists && (cachedToken.expiration > (now + ACCESS_TOKEN_EXPIRATION_BUFFER_SECS)) {
+		return cachedToken.token, nil
+	}
+
+	claims := map[string]any{
+		"iss": options.ClientID,
+		"sub": options.ClientID,
+		"aud": options.AuthTokenURL,
+		"iat": now,
+		"exp": now + CLIENT_ASSERTION_TTL_SECS,
+		"jti": util.UUID(),
+	}
+
+	assertion, err := SignToolJWT(claims)
+	if err != nil {
+		return "", fmt.Errorf("Failed to sign LTI client assertion: '%w'.", err)
+	}
+
+	form := map[string]string{
+		"grant_type":            "client_credentials",
+		"client_assertion_type": CLIENT_ASSERTION_TYPE,
+		"client_assertion":      assertion,
+		"scope":                 scope,
+	}
+
+	body, err := util.Post(options.AuthTokenURL, form)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get LTI access token: '%w'.", err)
+	}
+
+	var response accessTokenResponse
+	err = util.JSONFromString(body, &response)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse LTI access token response: '%w'.", err)
+	}
+
+	if response.AccessToken == "" {
+		return "", fmt.Errorf("LTI access token response does not contain a token.")
+	}
+
+	accessTokens[cacheKey] = &accessToken{
+		token:      response.AccessToken,
+		expiration: now + response.ExpiresIn,
+	}
+
+	return response.AccessToken, nil
+}
+
+// Clear all cached access tokens.
+func ClearAccessTokens() {
+	accessTokensLock.Lock()
+	defer accessTokensLock.Unlock()
+
+	accessTokens = make(map[string]*accessToken)
+}
+
+// Get the score a result represents (scaled to |scoreMaximum|).
+// Returns nil if the result does not have a score.
+func (this *Result) GetScore(scoreMaximum float64) *float64 {
+	if this.ResultScore == nil {
+		return nil
+	}
+
+	// The spec says that a missing maximum means a maximum of 1.
+	resultMaximum := 1.0
+	if (this.ResultMaximum != nil) && !util.IsZero(*this.ResultMaximum) {
+		resultMaximum = *this.ResultMaximum
+	}
+
+	score := *this.ResultScore
+	if !util.IsZero(scoreMaximum) {
+		score = score / resultMaximum * scoreMaximum
+	}
+
+	return &score
+}
+
+// Get the LTI (ISO 8601) representation of a timestamp.
+func FormatTime(instance timestamp.Timestamp) string {
+	return instance.ToGoTime().UTC().Format("2006-01-02T15:04:05.000Z07:00")
+}
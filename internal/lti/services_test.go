@@ -0,0 +1,92 @@
+package lti
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestGetAccessToken(test *testing.T) {
+	platform, err := NewTestPlatform()
+	if err != nil {
+		test.Fatalf("Failed to start test platform: '%v'.", err)
+	}
+	defer platform.Close()
+
+	options := platform.Options()
+
+	token, err := GetAccessToken(options, []string{SCOPE_AGS_SCORE, SCOPE_AGS_LINE_ITEM_READONLY})
+	if err != nil {
+		test.Fatalf("Failed to get access token: '%v'.", err)
+	}
+
+	// Tokens are cached (scope order does not matter).
+	otherToken, err := GetAccessToken(options, []string{SCOPE_AGS_LINE_ITEM_READONLY, SCOPE_AGS_SCORE})
+	if err != nil {
+		test.Fatalf("Failed to get cached access token: '%v'.", err)
+	}
+
+	if token != otherToken {
+		test.Fatalf("Access token was not cached. Expected: '%s', Actual: '%s'.", token, otherToken)
+	}
+
+	// Different scopes get a different token.
+	otherToken, err = GetAccessToken(options, []string{SCOPE_NRPS_MEMBERSHIP})
+	if err != nil {
+		test.Fatalf("Failed to get other access token: '%v'.", err)
+	}
+
+	if token == otherToken {
+		test.Fatalf("Access tokens for different scopes should be different.")
+	}
+
+	// The wrong client will not be able to get a token.
+	options.ClientID = "ZZZ"
+
+	_, err = GetAccessToken(options, []string{SCOPE_AGS_SCORE})
+	if err == nil {
+		test.Fatalf("Did not get an error for a bad client.")
+	}
+
+	// Services are required.
+	options.AuthTokenURL = ""
+
+	_, err = GetAccessToken(options, []string{SCOPE_AGS_SCORE})
+	if (err == nil) || !strings.Contains(err.Error(), "auth-token-url") {
+		test.Fatalf("Did not get the expected error for a missing token URL: '%v'.", err)
+	}
+}
+
+func TestResultGetScore(test *testing.T) {
+	testCases := []struct {
+		score        *float64
+		maximum      *float64
+		scoreMaximum float64
+		expected     *float64
+	}{
+		{nil, nil, 10, nil},
+		{util.FloatPointer(0.5), nil, 10, util.FloatPointer(5)},
+		{util.FloatPointer(5), util.FloatPointer(10), 10, util.FloatPointer(5)},
+		{util.FloatPointer(5), util.FloatPointer(10), 100, util.FloatPointer(50)},
+		{util.FloatPointer(5), util.FloatPointer(10), 0, util.FloatPointer(5)},
+		{util.FloatPointer(5), util.FloatPointer(0), 10, util.FloatPointer(50)},
+	}
+
+	for i, testCase := range testCases {
+		result := Result{
+			ResultScore:   testCase.score,
+			ResultMaximum: testCase.maximum,
+		}
+
+		actual := result.GetScore(testCase.scoreMaximum)
+		if (actual == nil) != (testCase.expected == nil) {
+			test.Errorf("Case %d: Unexpected nil score. Expected: '%v', Actual: '%v'.", i, testCase.expected, actual)
+			continue
+		}
+
+		if (actual != nil) && !util.IsClose(*actual, *testCase.expected) {
+			test.Errorf("Case %d: Unexpected score. Expected: '%f', Actual: '%f'.", i, *testCase.expected, *actual)
+		}
+	}
+}
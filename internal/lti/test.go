@@ -0,0 +1,438 @@
+package lti
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/edulinq/autograder/internal/jwt"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	TEST_CLIENT_ID     = "autograder-lti-test"
+	TEST_DEPLOYMENT_ID = "test-deployment"
+	TEST_CONTEXT_ID    = "test-context"
+	TEST_KEY_ID        = "test-platform-key"
+	TEST_RESOURCE_LINK = "test-resource-link"
+)
+
+// A minimal local LTI 1.3 platform (LMS) for testing.
+// It implements authentication (which immediately "launches" the configured user),
+// signing keys, access tokens, AGS (line items, results, and scores), and NRPS (memberships).
+type TestPlatform struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	lock sync.Mutex
+
+	// Claims to add to (or override in) the next launches.
+	// A nil value removes the claim.
+	launchClaims map[string]any
+
+	lineItems []*LineItem
+
+	// Scores keyed by line item ID and then user ID.
+	scores map[string]map[string]*Score
+
+	members []*Member
+
+	// Scopes for each access token.
+	accessTokens map[string][]string
+}
+
+func NewTestPlatform() (*TestPlatform, error) {
+	key, err := rsa.GenerateKey(rand.Reader, KEY_BITS)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate test platform key: '%w'.", err)
+	}
+
+	platform := &TestPlatform{
+		key:          key,
+		launchClaims: make(map[string]any),
+		lineItems:    make([]*LineItem, 0),
+		scores:       make(map[string]map[string]*Score),
+		members:      make([]*Member, 0),
+		accessTokens: make(map[string][]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth", platform.handleAuth)
+	mux.HandleFunc("/jwks", platform.handleKeys)
+	mux.HandleFunc("/token", platform.handleToken)
+	mux.HandleFunc("/lineitems", platform.handleLineItems)
+	mux.HandleFunc("/lineitems/", platform.handleLineItem)
+	mux.HandleFunc("/memberships", platform.handleMemberships)
+
+	platform.server = httptest.NewServer(mux)
+
+	return platform, nil
+}
+
+func (this *TestPlatform) Issuer() string {
+	return this.server.URL
+}
+
+func (this *TestPlatform) Close() {
+	this.server.Close()
+
+	ClearAccessTokens()
+	jwt.ClearKeyCache()
+}
+
+// Get the LTI options that point to this platform.
+func (this *TestPlatform) Options() *model.LTIOptions {
+	return &model.LTIOptions{
+		Issuer:         this.Issuer(),
+		ClientID:       TEST_CLIENT_ID,
+		AuthLoginURL:   this.Issuer() + "/auth",
+		KeySetURL:      this.Issuer() + "/jwks",
+		DeploymentID:   TEST_DEPLOYMENT_ID,
+		AuthTokenURL:   this.Issuer() + "/token",
+		LineItemsURL:   this.Issuer() + "/lineitems",
+		MembershipsURL: this.Issuer() + "/memberships",
+	}
+}
+
+// Get an LMS adapter (with user syncing) that uses this platform.
+func (this *TestPlatform) Adapter() *model.LMSAdapter {
+	return &model.LMSAdapter{
+		Type:               model.LMS_TYPE_LTI,
+		LMSCourseID:        TEST_CONTEXT_ID,
+		LTI:                this.Options(),
+		SyncUserAttributes: true,
+		SyncUserAdds:       true,
+	}
+}
+
+// Set the claims that will be used for the next launches,
+// e.g., {"sub": "123", "email": "alice@test.edulinq.org", "https://purl.imsglobal.org/spec/lti/claim/roles": [...]}.
+func (this *TestPlatform) SetLaunchClaims(claims map[string]any) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.launchClaims = claims
+}
+
+func (this *TestPlatform) SetMembers(members []*Member) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.members = members
+}
+
+// Add a line item and return its ID.
+func (this *TestPlatform) AddLineItem(label string, scoreMaximum float64) string {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	lineItem := &LineItem{
+		ID:           fmt.Sprintf("%s/lineitems/%d", this.Issuer(), len(this.lineItems)),
+		Label:        label,
+		ScoreMaximum: scoreMaximum,
+	}
+
+	this.lineItems = append(this.lineItems, lineItem)
+	this.scores[lineItem.ID] = make(map[string]*Score)
+
+	return lineItem.ID
+}
+
+// Get the scores for a line item keyed by user ID.
+func (this *TestPlatform) GetScores(lineItemID string) map[string]*Score {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	result := make(map[string]*Score)
+	for userID, score := range this.scores[lineItemID] {
+		scoreCopy := *score
+		result[userID] = &scoreCopy
+	}
+
+	return result
+}
+
+// Act as a user's browser and visit the platform's authentication URL.
+// Returns the launch URL and the form that should be posted to it.
+func (this *TestPlatform) Authorize(authURL string) (string, map[string]string, error) {
+	body, err := util.Get(authURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to visit authentication URL: '%w'.", err)
+	}
+
+	var response map[string]string
+	err = util.JSONFromString(body, &response)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to parse authentication response: '%w'.", err)
+	}
+
+	form := map[string]string{
+		"state":    response["state"],
+		"id_token": response["id_token"],
+	}
+
+	return response["redirect_uri"], form, nil
+}
+
+// A real platform would respond with an HTML form that posts the ID token and state to the redirect URI,
+// but a JSON object is easier to work with in tests.
+func (this *TestPlatform) handleAuth(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+
+	if (query.Get("scope") != "openid") || (query.Get("response_type") != "id_token") || (query.Get("client_id") != TEST_CLIENT_ID) {
+		http.Error(response, "Unsupported request.", http.StatusBadRequest)
+		return
+	}
+
+	now := jwt.NowSecs()
+
+	claims := map[string]any{
+		"iss":                 this.Issuer(),
+		"aud":                 TEST_CLIENT_ID,
+		"sub":                 query.Get("login_hint"),
+		"exp":                 now + 300,
+		"iat":                 now,
+		"nonce":               query.Get("nonce"),
+		CLAIM_MESSAGE_TYPE:    MESSAGE_TYPE_RESOURCE_LINK,
+		CLAIM_VERSION:         LTI_VERSION,
+		CLAIM_DEPLOYMENT_ID:   TEST_DEPLOYMENT_ID,
+		CLAIM_TARGET_LINK_URI: query.Get("redirect_uri"),
+		CLAIM_RESOURCE_LINK:   map[string]any{"id": TEST_RESOURCE_LINK},
+		CLAIM_CONTEXT:         map[string]any{"id": TEST_CONTEXT_ID},
+		CLAIM_ROLES:           []any{ROLE_LEARNER},
+		CLAIM_AGS_ENDPOINT:    map[string]any{"lineitems": this.Issuer() + "/lineitems"},
+		CLAIM_NRPS:            map[string]any{"context_memberships_url": this.Issuer() + "/memberships"},
+	}
+
+	this.lock.Lock()
+	for key, value := range this.launchClaims {
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+	}
+	this.lock.Unlock()
+
+	idToken, err := jwt.Sign(claims, this.key, TEST_KEY_ID)
+	if err != nil {
+		http.Error(response, "Failed to sign ID token.", http.StatusInternalServerError)
+		return
+	}
+
+	writeTestJSON(response, http.StatusOK, map[string]string{
+		"redirect_uri": query.Get("redirect_uri"),
+		"state":        query.Get("state"),
+		"id_token":     idToken,
+	})
+}
+
+func (this *TestPlatform) handleKeys(response http.ResponseWriter, request *http.Request) {
+	keySet := jwt.JSONWebKeySet{
+		Keys: []*jwt.JSONWebKey{
+			jwt.NewJSONWebKey(&this.key.PublicKey, TEST_KEY_ID),
+		},
+	}
+
+	writeTestJSON(response, http.StatusOK, keySet)
+}
+
+func (this *TestPlatform) handleToken(response http.ResponseWriter, request *http.Request) {
+	err := request.ParseForm()
+	if err != nil {
+		writeTestJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	if (request.PostForm.Get("grant_type") != "client_credentials") || (request.PostForm.Get("client_assertion_type") != CLIENT_ASSERTION_TYPE) {
+		writeTestJSON(response, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	toolKey, _, err := GetToolKey()
+	if err != nil {
+		writeTestJSON(response, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	claims, err := jwt.VerifyWithKey(request.PostForm.Get("client_assertion"), &toolKey.PublicKey)
+	if err == nil {
+		err = jwt.CheckClaims(claims, TEST_CLIENT_ID, this.Issuer()+"/token", "")
+	}
+
+	if (err != nil) || (claims["sub"] != TEST_CLIENT_ID) {
+		writeTestJSON(response, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	token := util.UUID()
+	scopes := strings.Fields(request.PostForm.Get("scope"))
+
+	this.lock.Lock()
+	this.accessTokens[token] = scopes
+	this.lock.Unlock()
+
+	writeTestJSON(response, http.StatusOK, map[string]any{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"scope":        strings.Join(scopes, " "),
+	})
+}
+
+func (this *TestPlatform) handleLineItems(response http.ResponseWriter, request *http.Request) {
+	if !this.checkAccess(response, request, SCOPE_AGS_LINE_ITEM_READONLY) {
+		return
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	writeTestJSON(response, http.StatusOK, this.lineItems)
+}
+
+// Handle "/lineitems/<id>", "/lineitems/<id>/results", and "/lineitems/<id>/scores".
+func (this *TestPlatform) handleLineItem(response http.ResponseWriter, request *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(request.URL.Path, "/lineitems/"), "/")
+	lineItemID := this.Issuer() + "/lineitems/" + parts[0]
+
+	this.lock.Lock()
+	index := slices.IndexFunc(this.lineItems, func(lineItem *LineItem) bool { return lineItem.ID == lineItemID })
+	this.lock.Unlock()
+
+	if index < 0 {
+		http.NotFound(response, request)
+		return
+	}
+
+	if len(parts) == 1 {
+		if !this.checkAccess(response, request, SCOPE_AGS_LINE_ITEM_READONLY) {
+			return
+		}
+
+		this.lock.Lock()
+		defer this.lock.Unlock()
+
+		writeTestJSON(response, http.StatusOK, this.lineItems[index])
+		return
+	}
+
+	switch parts[1] {
+	case "results":
+		this.handleResults(response, request, lineItemID)
+	case "scores":
+		this.handleScores(response, request, lineItemID)
+	default:
+		http.NotFound(response, request)
+	}
+}
+
+func (this *TestPlatform) handleResults(response http.ResponseWriter, request *http.Request, lineItemID string) {
+	if !this.checkAccess(response, request, SCOPE_AGS_RESULT_READONLY) {
+		return
+	}
+
+	userID := request.URL.Query().Get("user_id")
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	results := make([]*Result, 0)
+	for _, score := range this.scores[lineItemID] {
+		if (userID != "") && (score.UserID != userID) {
+			continue
+		}
+
+		results = append(results, &Result{
+			ID:            lineItemID + "/results/" + score.UserID,
+			ScoreOf:       lineItemID,
+			UserID:        score.UserID,
+			ResultScore:   &score.ScoreGiven,
+			ResultMaximum: &score.ScoreMaximum,
+			Comment:       score.Comment,
+		})
+	}
+
+	slices.SortFunc(results, func(a *Result, b *Result) int {
+		return strings.Compare(a.UserID, b.UserID)
+	})
+
+	writeTestJSON(response, http.StatusOK, results)
+}
+
+func (this *TestPlatform) handleScores(response http.ResponseWriter, request *http.Request, lineItemID string) {
+	if !this.checkAccess(response, request, SCOPE_AGS_SCORE) {
+		return
+	}
+
+	if (request.Method != "POST") || (request.Header.Get("Content-Type") != CONTENT_TYPE_SCORE) {
+		http.Error(response, "Unsupported request.", http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		http.Error(response, "Failed to read request.", http.StatusBadRequest)
+		return
+	}
+
+	var score Score
+	err = util.JSONFromBytes(body, &score)
+	if (err != nil) || (score.UserID == "") || (score.Timestamp == "") {
+		http.Error(response, "Invalid score.", http.StatusBadRequest)
+		return
+	}
+
+	this.lock.Lock()
+	this.scores[lineItemID][score.UserID] = &score
+	this.lock.Unlock()
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+func (this *TestPlatform) handleMemberships(response http.ResponseWriter, request *http.Request) {
+	if !this.checkAccess(response, request, SCOPE_NRPS_MEMBERSHIP) {
+		return
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	container := MembershipContainer{
+		ID:      this.Issuer() + "/memberships",
+		Context: &Context{ID: TEST_CONTEXT_ID},
+		Members: this.members,
+	}
+
+	writeTestJSON(response, http.StatusOK, container)
+}
+
+// Check that the request has an access token with the given scope.
+// Returns false (and writes an error response) if the request does not have access.
+func (this *TestPlatform) checkAccess(response http.ResponseWriter, request *http.Request, scope string) bool {
+	token := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+
+	this.lock.Lock()
+	scopes, exists := this.accessTokens[token]
+	this.lock.Unlock()
+
+	if !exists || !slices.Contains(scopes, scope) {
+		http.Error(response, "Unauthorized.", http.StatusUnauthorized)
+		return false
+	}
+
+	return true
+}
+
+func writeTestJSON(response http.ResponseWriter, status int, value any) {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	response.Write([]byte(util.MustToJSON(value)))
+}
@@ -27,6 +27,19 @@ type Assignment struct {
 
 	LMSID string `json:"lms-id,omitempty"`
 
+	// If true, the grader always gives the same output for the same input,
+	// so the output of earlier runs may be reused.
+	Deterministic bool `json:"deterministic,omitempty"`
+
+	// Items that graders can manually award on top of the autograder's score.
+	Rubric *Rubric `json:"rubric,omitempty"`
+
+	// Set if this assignment is submitted by groups (e.g., partners).
+	Groups *GroupOptions `json:"groups,omitempty"`
+
+	// Set if this assignment accepts submissions from students' git repos.
+	GitSubmission *GitSubmissionOptions `json:"git-submission,omitempty"`
+
 	// Inheritable
 	LatePolicy      *LateGradingPolicy   `json:"late-policy,omitempty"`
 	SubmissionLimit *SubmissionLimitInfo `json:"submission-limit,omitempty"`
@@ -103,6 +116,16 @@ func (this *Assignment) GetSubmissionLimit() *SubmissionLimitInfo {
 	return this.Course.SubmissionLimit
 }
 
+// Get the container resource limits to use for this assignment or nil if there are no limits.
+// If this assignment has no resource limits, the course will be checked.
+func (this *Assignment) GetResourceLimits() *docker.ResourceLimits {
+	if this.ResourceLimits != nil {
+		return this.ResourceLimits
+	}
+
+	return this.Course.ResourceLimits
+}
+
 func (this *Assignment) GetImageName() string {
 	return strings.ToLower(fmt.Sprintf("autograder.%s.%s", this.Course.GetID(), this.ID))
 }
@@ -152,6 +175,21 @@ func (this *Assignment) Validate() error {
 		}
 	}
 
+	err = this.Rubric.Validate()
+	if err != nil {
+		return fmt.Errorf("Failed to validate rubric: '%w'.", err)
+	}
+
+	err = this.Groups.Validate()
+	if err != nil {
+		return fmt.Errorf("Failed to validate group options: '%w'.", err)
+	}
+
+	err = this.GitSubmission.Validate()
+	if err != nil {
+		return fmt.Errorf("Failed to validate git submission options: '%w'.", err)
+	}
+
 	if this.RelSourceDir == "" {
 		return fmt.Errorf("Relative source dir must not be empty.")
 	}
@@ -26,6 +26,16 @@ func ReadAssignmentConfig(course *Course, path string, relSourceDir string) (*As
 		return nil, fmt.Errorf("Could not load assignment config (%s): '%w'.", path, err)
 	}
 
+	err = addAssignment(course, &assignment, relSourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load assignment config (%s): '%w'.", path, err)
+	}
+
+	return &assignment, nil
+}
+
+// Attach a freshly loaded assignment to its course, validate it, and add it to the course.
+func addAssignment(course *Course, assignment *Assignment, relSourceDir string) error {
 	assignment.Course = course
 
 	// Only override the relative source dir if it is empty.
@@ -34,15 +44,15 @@ func ReadAssignmentConfig(course *Course, path string, relSourceDir string) (*As
 		assignment.RelSourceDir = relSourceDir
 	}
 
-	err = assignment.Validate()
+	err := assignment.Validate()
 	if err != nil {
-		return nil, fmt.Errorf("Failed to validate assignment config (%s): '%w'.", path, err)
+		return fmt.Errorf("Failed to validate assignment config: '%w'.", err)
 	}
 
-	err = course.AddAssignment(&assignment)
+	err = course.AddAssignment(assignment)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to add assignment to course (%s): '%w'.", path, err)
+		return fmt.Errorf("Failed to add assignment to course: '%w'.", err)
 	}
 
-	return &assignment, nil
+	return nil
 }
@@ -23,8 +23,9 @@ type Course struct {
 	LMS *LMSAdapter `json:"lms,omitempty"`
 
 	// Inheritable by assignments.
-	LatePolicy      *LateGradingPolicy   `json:"late-policy,omitempty"`
-	SubmissionLimit *SubmissionLimitInfo `json:"submission-limit,omitempty"`
+	LatePolicy      *LateGradingPolicy     `json:"late-policy,omitempty"`
+	SubmissionLimit *SubmissionLimitInfo   `json:"submission-limit,omitempty"`
+	ResourceLimits  *docker.ResourceLimits `json:"resource-limits,omitempty"`
 
 	Tasks []*UserTaskInfo `json:"tasks,omitempty"`
 
@@ -109,6 +110,11 @@ func (this *Course) Validate() error {
 		}
 	}
 
+	err = this.ResourceLimits.Validate()
+	if err != nil {
+		return fmt.Errorf("Failed to validate resource limits: '%w'.", err)
+	}
+
 	if this.Tasks == nil {
 		this.Tasks = make([]*UserTaskInfo, 0)
 	}
@@ -74,3 +74,35 @@ func ReadCourseConfig(path string) (*Course, error) {
 
 	return &course, nil
 }
+
+// Load a course (and its assignments) from the JSON representations of their configs.
+// This is meant for database backends that do not store courses as files.
+func LoadCourseFromJSON(courseJSON []byte, assignmentJSONs [][]byte) (*Course, error) {
+	var course Course
+	err := util.JSONFromBytes(courseJSON, &course)
+	if err != nil {
+		return nil, fmt.Errorf("Could not load course config: '%w'.", err)
+	}
+
+	course.Assignments = make(map[string]*Assignment)
+
+	err = course.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("Could not validate course config ('%s'): '%w'.", course.ID, err)
+	}
+
+	for _, assignmentJSON := range assignmentJSONs {
+		var assignment Assignment
+		err = util.JSONFromBytes(assignmentJSON, &assignment)
+		if err != nil {
+			return nil, fmt.Errorf("Could not load assignment config for course '%s': '%w'.", course.ID, err)
+		}
+
+		err = addAssignment(&course, &assignment, "")
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load assignment '%s' for course '%s': '%w'.", assignment.ID, course.ID, err)
+		}
+	}
+
+	return &course, nil
+}
@@ -2,9 +2,11 @@ package model
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/util"
 )
 
 func TestFullLoadCourseBase(test *testing.T) {
@@ -28,3 +30,66 @@ func TestFullLoadCourseBase(test *testing.T) {
 		test.Fatalf("Unexpected number of submissions. Expected %d, Actual: %d.", 3, len(submissions))
 	}
 }
+
+func TestLoadCourseFromJSONBase(test *testing.T) {
+	testPath := filepath.Join(config.GetTestdataDir(), "course-languages", COURSE_CONFIG_FILENAME)
+
+	expected, err := LoadCourseFromPath(testPath, true)
+	if err != nil {
+		test.Fatalf("Failed to load course: '%v'.", err)
+	}
+
+	courseJSON := []byte(util.MustToJSON(expected))
+
+	assignmentJSONs := make([][]byte, 0, len(expected.Assignments))
+	for _, assignment := range expected.Assignments {
+		assignmentJSONs = append(assignmentJSONs, []byte(util.MustToJSON(assignment)))
+	}
+
+	actual, err := LoadCourseFromJSON(courseJSON, assignmentJSONs)
+	if err != nil {
+		test.Fatalf("Failed to load course from JSON: '%v'.", err)
+	}
+
+	if util.MustToJSONIndent(expected) != util.MustToJSONIndent(actual) {
+		test.Fatalf("Unexpected course. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(actual))
+	}
+
+	if len(expected.Assignments) != len(actual.Assignments) {
+		test.Fatalf("Unexpected number of assignments. Expected %d, Actual: %d.", len(expected.Assignments), len(actual.Assignments))
+	}
+
+	for id, expectedAssignment := range expected.Assignments {
+		actualAssignment := actual.GetAssignment(id)
+		if actualAssignment == nil {
+			test.Fatalf("Missing assignment '%s'.", id)
+		}
+
+		if actualAssignment.Course != actual {
+			test.Fatalf("Assignment '%s' is not attached to its course.", id)
+		}
+
+		if util.MustToJSONIndent(expectedAssignment) != util.MustToJSONIndent(actualAssignment) {
+			test.Fatalf("Unexpected assignment '%s'. Expected: '%s', Actual: '%s'.",
+				id, util.MustToJSONIndent(expectedAssignment), util.MustToJSONIndent(actualAssignment))
+		}
+	}
+}
+
+func TestLoadCourseFromJSONBadAssignment(test *testing.T) {
+	courseJSON := []byte(`{"id": "course-json"}`)
+	assignmentJSONs := [][]byte{
+		[]byte(`{"id": "a1", "_rel_source-dir": "a1", "image": "alpine"}`),
+		[]byte(`{"id": "a1", "_rel_source-dir": "a2", "image": "alpine"}`),
+	}
+
+	_, err := LoadCourseFromJSON(courseJSON, assignmentJSONs)
+	if err == nil {
+		test.Fatalf("Did not get an error on duplicate assignments.")
+	}
+
+	expectedSubstring := "Found multiple assignments with the same ID"
+	if !strings.Contains(err.Error(), expectedSubstring) {
+		test.Fatalf("Did not get expected error substring. Expected: '%s', Actual: '%s'.", expectedSubstring, err.Error())
+	}
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/common"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const EXTENSIONS_FILENAME = "extensions.json"
+
+// An individual extension/accommodation for a user.
+// An extension is either for a specific assignment, or course-wide (when the assignment ID is empty).
+// When a user has both, the assignment-specific values take precedence over the course-wide ones.
+type Extension struct {
+	CourseID     string `json:"course-id"`
+	AssignmentID string `json:"assignment-id,omitempty"`
+	User         string `json:"user"`
+
+	// A new due date that replaces the assignment's due date.
+	// Only allowed on assignment-specific extensions.
+	DueDate *timestamp.Timestamp `json:"due-date,omitempty"`
+
+	// Additional time added onto the assignment's due date.
+	// Ignored if a due date is also set.
+	ExtraTime *util.DurationSpec `json:"extra-time,omitempty"`
+
+	// Submission attempts allowed on top of the assignment's submission limit.
+	ExtraAttempts int `json:"extra-attempts,omitempty"`
+
+	// A multiplier on the assignment's max runtime (e.g., 1.5 for 50% more time).
+	// Zero means no change.
+	RuntimeMultiplier float64 `json:"runtime-multiplier,omitempty"`
+
+	Notes string `json:"notes,omitempty"`
+
+	UpdateTime timestamp.Timestamp `json:"update-time"`
+}
+
+func (this *Extension) Validate() error {
+	if this == nil {
+		return fmt.Errorf("Extension is nil.")
+	}
+
+	var err error
+
+	this.CourseID, err = common.ValidateID(this.CourseID)
+	if err != nil {
+		return fmt.Errorf("Extension has an invalid course ID: '%w'.", err)
+	}
+
+	if this.AssignmentID != "" {
+		this.AssignmentID, err = common.ValidateID(this.AssignmentID)
+		if err != nil {
+			return fmt.Errorf("Extension has an invalid assignment ID: '%w'.", err)
+		}
+	}
+
+	this.User = strings.ToLower(strings.TrimSpace(this.User))
+	if this.User == "" {
+		return fmt.Errorf("Extension is missing a user.")
+	}
+
+	if (this.DueDate != nil) && (this.AssignmentID == "") {
+		return fmt.Errorf("Course-wide extensions cannot set a due date, use extra time instead.")
+	}
+
+	if this.ExtraTime != nil {
+		err = this.ExtraTime.Validate()
+		if err != nil {
+			return fmt.Errorf("Extension has invalid extra time: '%w'.", err)
+		}
+	}
+
+	if this.ExtraAttempts < 0 {
+		return fmt.Errorf("Extension extra attempts must be non-negative, found: %d.", this.ExtraAttempts)
+	}
+
+	if this.RuntimeMultiplier < 0 {
+		return fmt.Errorf("Extension runtime multiplier must be non-negative, found: %f.", this.RuntimeMultiplier)
+	}
+
+	return nil
+}
+
+func (this *Extension) IsCourseWide() bool {
+	return (this != nil) && (this.AssignmentID == "")
+}
+
+// Get the due date for this user given the assignment's due date.
+// A nil due date means there is no due date.
+func (this *Extension) GetDueDate(dueDate *timestamp.Timestamp) *timestamp.Timestamp {
+	if (this == nil) || (dueDate == nil) {
+		return dueDate
+	}
+
+	if this.DueDate != nil {
+		newDueDate := *this.DueDate
+		return &newDueDate
+	}
+
+	if this.ExtraTime != nil {
+		newDueDate := timestamp.FromMSecs(dueDate.ToMSecs() + this.ExtraTime.TotalMSecs())
+		return &newDueDate
+	}
+
+	return dueDate
+}
+
+func (this *Extension) GetExtraAttempts() int {
+	if this == nil {
+		return 0
+	}
+
+	return this.ExtraAttempts
+}
+
+// Get the max runtime for this user given the assignment's max runtime.
+// The result is never more than the server's hard limit.
+func (this *Extension) GetMaxRuntimeSecs(maxRuntimeSecs int, serverMaxRuntimeSecs int) int {
+	if (this == nil) || (this.RuntimeMultiplier <= 0) {
+		return maxRuntimeSecs
+	}
+
+	runtime := int(math.Ceil(float64(maxRuntimeSecs) * this.RuntimeMultiplier))
+	if (serverMaxRuntimeSecs > 0) && (runtime > serverMaxRuntimeSecs) {
+		runtime = serverMaxRuntimeSecs
+	}
+
+	return runtime
+}
+
+// Merge a course-wide and assignment-specific extension into a single effective extension.
+// Any set values in the assignment extension override the values in the course extension.
+// Either (or both) extensions may be nil.
+func MergeExtensions(courseExtension *Extension, assignmentExtension *Extension) *Extension {
+	if courseExtension == nil {
+		return assignmentExtension
+	}
+
+	if assignmentExtension == nil {
+		return courseExtension
+	}
+
+	result := *assignmentExtension
+
+	if result.ExtraTime == nil {
+		result.ExtraTime = courseExtension.ExtraTime
+	}
+
+	if result.ExtraAttempts == 0 {
+		result.ExtraAttempts = courseExtension.ExtraAttempts
+	}
+
+	if result.RuntimeMultiplier == 0 {
+		result.RuntimeMultiplier = courseExtension.RuntimeMultiplier
+	}
+
+	return &result
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestExtensionValidate(test *testing.T) {
+	dueDate := timestamp.FromMSecs(1000)
+
+	testCases := []struct {
+		extension *Extension
+		hasError  bool
+	}{
+		{&Extension{CourseID: "course101", User: "a@test.edulinq.org"}, false},
+		{&Extension{CourseID: "course101", AssignmentID: "hw0", User: "a@test.edulinq.org", DueDate: &dueDate}, false},
+		{&Extension{CourseID: "course101", User: "a@test.edulinq.org", ExtraTime: &util.DurationSpec{Days: 1}}, false},
+		{&Extension{CourseID: "course101", User: "a@test.edulinq.org", ExtraAttempts: 2, RuntimeMultiplier: 1.5}, false},
+
+		{nil, true},
+		{&Extension{User: "a@test.edulinq.org"}, true},
+		{&Extension{CourseID: "course101"}, true},
+		{&Extension{CourseID: "course101", AssignmentID: "a b", User: "a@test.edulinq.org"}, true},
+		{&Extension{CourseID: "course101", User: "a@test.edulinq.org", DueDate: &dueDate}, true},
+		{&Extension{CourseID: "course101", User: "a@test.edulinq.org", ExtraTime: &util.DurationSpec{Days: -1}}, true},
+		{&Extension{CourseID: "course101", User: "a@test.edulinq.org", ExtraAttempts: -1}, true},
+		{&Extension{CourseID: "course101", User: "a@test.edulinq.org", RuntimeMultiplier: -1}, true},
+	}
+
+	for i, testCase := range testCases {
+		err := testCase.extension.Validate()
+		if testCase.hasError != (err != nil) {
+			test.Errorf("Case %d: Unexpected error result. Expected error: '%v', Actual: '%v'.", i, testCase.hasError, err)
+			continue
+		}
+	}
+}
+
+func TestExtensionGetDueDate(test *testing.T) {
+	baseDueDate := timestamp.FromMSecs(1000)
+	newDueDate := timestamp.FromMSecs(5000)
+	extraDueDate := timestamp.FromMSecs(3000)
+
+	testCases := []struct {
+		extension *Extension
+		dueDate   *timestamp.Timestamp
+		expected  *timestamp.Timestamp
+	}{
+		{nil, nil, nil},
+		{nil, &baseDueDate, &baseDueDate},
+		{&Extension{}, &baseDueDate, &baseDueDate},
+		{&Extension{DueDate: &newDueDate}, &baseDueDate, &newDueDate},
+		{&Extension{DueDate: &newDueDate}, nil, nil},
+		{&Extension{ExtraTime: &util.DurationSpec{Seconds: 2}}, &baseDueDate, &extraDueDate},
+		{&Extension{DueDate: &newDueDate, ExtraTime: &util.DurationSpec{Seconds: 2}}, &baseDueDate, &newDueDate},
+	}
+
+	for i, testCase := range testCases {
+		actual := testCase.extension.GetDueDate(testCase.dueDate)
+		if !reflect.DeepEqual(testCase.expected, actual) {
+			test.Errorf("Case %d: Unexpected due date. Expected: '%s', Actual: '%s'.", i, testCase.expected.SafeString(), actual.SafeString())
+			continue
+		}
+	}
+}
+
+func TestExtensionGetMaxRuntimeSecs(test *testing.T) {
+	testCases := []struct {
+		extension *Extension
+		runtime   int
+		serverMax int
+		expected  int
+	}{
+		{nil, 10, 100, 10},
+		{&Extension{}, 10, 100, 10},
+		{&Extension{RuntimeMultiplier: 1.5}, 10, 100, 15},
+		{&Extension{RuntimeMultiplier: 1.25}, 10, 100, 13},
+		{&Extension{RuntimeMultiplier: 20}, 10, 100, 100},
+		{&Extension{RuntimeMultiplier: 20}, 10, 0, 200},
+	}
+
+	for i, testCase := range testCases {
+		actual := testCase.extension.GetMaxRuntimeSecs(testCase.runtime, testCase.serverMax)
+		if testCase.expected != actual {
+			test.Errorf("Case %d: Unexpected runtime. Expected: '%d', Actual: '%d'.", i, testCase.expected, actual)
+			continue
+		}
+	}
+}
+
+func TestMergeExtensions(test *testing.T) {
+	dueDate := timestamp.FromMSecs(1000)
+
+	courseExtension := &Extension{
+		CourseID:          "course101",
+		User:              "a@test.edulinq.org",
+		ExtraTime:         &util.DurationSpec{Days: 1},
+		ExtraAttempts:     1,
+		RuntimeMultiplier: 2,
+	}
+
+	assignmentExtension := &Extension{
+		CourseID:      "course101",
+		AssignmentID:  "hw0",
+		User:          "a@test.edulinq.org",
+		DueDate:       &dueDate,
+		ExtraAttempts: 3,
+	}
+
+	expected := &Extension{
+		CourseID:          "course101",
+		AssignmentID:      "hw0",
+		User:              "a@test.edulinq.org",
+		DueDate:           &dueDate,
+		ExtraTime:         &util.DurationSpec{Days: 1},
+		ExtraAttempts:     3,
+		RuntimeMultiplier: 2,
+	}
+
+	testCases := []struct {
+		course     *Extension
+		assignment *Extension
+		expected   *Extension
+	}{
+		{nil, nil, nil},
+		{courseExtension, nil, courseExtension},
+		{nil, assignmentExtension, assignmentExtension},
+		{courseExtension, assignmentExtension, expected},
+	}
+
+	for i, testCase := range testCases {
+		actual := MergeExtensions(testCase.course, testCase.assignment)
+		if !reflect.DeepEqual(testCase.expected, actual) {
+			test.Errorf("Case %d: Unexpected extension. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(testCase.expected), util.MustToJSONIndent(actual))
+			continue
+		}
+	}
+
+	// The inputs should not be modified.
+	if assignmentExtension.ExtraTime != nil {
+		test.Fatalf("Assignment extension was modified.")
+	}
+}
@@ -0,0 +1,324 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+const GIT_REPOS_FILENAME = "git-repos.json"
+
+// The placeholder in a repo name pattern that is replaced with the student's username (the part of their email before the "@").
+const GIT_REPO_NAME_USER_PLACEHOLDER = "{user}"
+
+// Options for assignments that accept submissions from students' git repos (e.g., GitHub Classroom).
+// Each student registers their repo, and pushed commits are graded
+// (either on a push webhook or when the course's repos are polled).
+type GitSubmissionOptions struct {
+	// The template repo that student repos are created from.
+	// Students may only register repos that are next to the template repo (same host and owner)
+	// and are named according to RepoNamePattern.
+	TemplateURL string `json:"template-url,omitempty"`
+
+	// The name students' repos must have (e.g., "hw0-{user}"), see GIT_REPO_NAME_USER_PLACEHOLDER.
+	// Students may not register repos if this (or TemplateURL) is empty.
+	RepoNamePattern string `json:"repo-name-pattern,omitempty"`
+
+	// Additional hosts (e.g., "github.com") that graders may register (https) repos on.
+	AllowedHosts []string `json:"allowed-hosts,omitempty"`
+
+	// The branch to grade.
+	// If empty, the repo's default branch will be used.
+	Branch string `json:"branch,omitempty"`
+
+	// The secret used to sign push webhooks (HMAC-SHA256).
+	// Webhooks are not accepted for assignments without a secret (but repos can still be polled).
+	WebhookSecret string `json:"webhook-secret,omitempty"`
+
+	// Credentials for cloning private repos.
+	// These are only sent to allowed hosts (see CheckRepoURL()).
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// A user's git repo that is registered for an assignment.
+// Each user has at most one repo per assignment.
+type GitRepo struct {
+	CourseID     string `json:"course-id"`
+	AssignmentID string `json:"assignment-id"`
+	User         string `json:"user"`
+	URL          string `json:"url"`
+
+	// The last commit that was submitted for grading.
+	LastCommit     string               `json:"last-commit,omitempty"`
+	LastCommitTime *timestamp.Timestamp `json:"last-commit-time,omitempty"`
+
+	CreatedBy  string              `json:"created-by"`
+	UpdateTime timestamp.Timestamp `json:"update-time"`
+}
+
+func (this *GitSubmissionOptions) Validate() error {
+	if this == nil {
+		return nil
+	}
+
+	this.TemplateURL = strings.TrimSpace(this.TemplateURL)
+	this.RepoNamePattern = strings.TrimSpace(this.RepoNamePattern)
+	this.Branch = strings.TrimSpace(this.Branch)
+
+	if strings.Contains(this.RepoNamePattern, "/") {
+		return fmt.Errorf("Git submission repo name pattern '%s' cannot contain a '/'.", this.RepoNamePattern)
+	}
+
+	for i, host := range this.AllowedHosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			return fmt.Errorf("Git submission allowed hosts cannot have an empty host.")
+		}
+
+		if strings.ContainsAny(host, "/@") {
+			return fmt.Errorf("Git submission allowed host '%s' is not a host name.", host)
+		}
+
+		this.AllowedHosts[i] = host
+	}
+
+	return nil
+}
+
+// Check that a repo URL may be registered by a (non-server admin) user.
+// The URL must use https and be on either the template repo's host or one of the allowed hosts.
+func (this *GitSubmissionOptions) CheckRepoURL(rawURL string) error {
+	if this == nil {
+		return fmt.Errorf("Assignment does not accept git submissions.")
+	}
+
+	host, err := getHTTPSHost(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if !this.isAllowedHost(host) {
+		return fmt.Errorf("Git repos on host '%s' are not allowed for this assignment.", host)
+	}
+
+	return nil
+}
+
+// Check that a repo URL may be registered by a student (with the given email).
+// On top of the checks from CheckRepoURL(), the repo must have the same host and owner as the template repo
+// and be named according to the repo name pattern,
+// so students can only register their own repos.
+func (this *GitSubmissionOptions) CheckStudentRepoURL(rawURL string, email string) error {
+	if this == nil {
+		return fmt.Errorf("Assignment does not accept git submissions.")
+	}
+
+	if (this.TemplateURL == "") || (this.RepoNamePattern == "") {
+		return fmt.Errorf("Students may not register git repos for this assignment, a grader must register them.")
+	}
+
+	templateOwner, _, err := getHTTPSOwnerAndName(this.TemplateURL)
+	if err != nil {
+		return fmt.Errorf("Assignment has an invalid template repo: '%w'.", err)
+	}
+
+	host, err := getHTTPSHost(rawURL)
+	if err != nil {
+		return err
+	}
+
+	templateHost, _ := getHTTPSHost(this.TemplateURL)
+	if host != templateHost {
+		return fmt.Errorf("Git repos must be on host '%s'.", templateHost)
+	}
+
+	owner, name, err := getHTTPSOwnerAndName(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if owner != templateOwner {
+		return fmt.Errorf("Git repos must be owned by '%s'.", templateOwner)
+	}
+
+	expectedName := this.GetStudentRepoName(email)
+	if name != expectedName {
+		return fmt.Errorf("Git repo must be named '%s'.", expectedName)
+	}
+
+	return nil
+}
+
+// Get the (lowercase) repo name that a student must use.
+func (this *GitSubmissionOptions) GetStudentRepoName(email string) string {
+	username, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	return strings.ToLower(strings.ReplaceAll(this.RepoNamePattern, GIT_REPO_NAME_USER_PLACEHOLDER, username))
+}
+
+// Check if this assignment's credentials (Username/Token) can be sent when fetching the given URL.
+// Credentials are only sent over https to allowed hosts.
+func (this *GitSubmissionOptions) CanSendCredentials(rawURL string) bool {
+	if this == nil {
+		return false
+	}
+
+	host, err := getHTTPSHost(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return this.isAllowedHost(host)
+}
+
+func (this *GitSubmissionOptions) isAllowedHost(host string) bool {
+	templateHost, err := getHTTPSHost(this.TemplateURL)
+	if (err == nil) && (host == templateHost) {
+		return true
+	}
+
+	for _, allowedHost := range this.AllowedHosts {
+		if host == strings.ToLower(strings.TrimSpace(allowedHost)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get the (lowercase) host (including any port) of an https URL.
+// Any other type of URL (e.g., ssh, SCP-like, or file) is an error.
+func getHTTPSHost(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("Git repo URL '%s' could not be parsed: '%w'.", rawURL, err)
+	}
+
+	if parsedURL.Scheme != "https" {
+		return "", fmt.Errorf("Git repo URL '%s' must be an https URL.", rawURL)
+	}
+
+	if parsedURL.Host == "" {
+		return "", fmt.Errorf("Git repo URL '%s' does not have a host.", rawURL)
+	}
+
+	return strings.ToLower(parsedURL.Host), nil
+}
+
+// Get the (lowercase) owner and name of an https repo URL of the form "https://host/owner/name[.git]".
+func getHTTPSOwnerAndName(rawURL string) (string, string, error) {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", "", fmt.Errorf("Git repo URL '%s' could not be parsed: '%w'.", rawURL, err)
+	}
+
+	path := strings.TrimSuffix(strings.Trim(parsedURL.Path, "/"), ".git")
+
+	parts := strings.Split(path, "/")
+	if (len(parts) != 2) || (parts[0] == "") || (parts[1] == "") {
+		return "", "", fmt.Errorf("Git repo URL '%s' is not of the form 'https://host/owner/name'.", rawURL)
+	}
+
+	return strings.ToLower(parts[0]), strings.ToLower(parts[1]), nil
+}
+
+func (this *GitRepo) Validate() error {
+	if this == nil {
+		return fmt.Errorf("Git repo is nil.")
+	}
+
+	if (this.CourseID == "") || (this.AssignmentID == "") {
+		return fmt.Errorf("Git repo is missing a course or assignment.")
+	}
+
+	this.User = strings.ToLower(strings.TrimSpace(this.User))
+	if this.User == "" {
+		return fmt.Errorf("Git repo is missing a user.")
+	}
+
+	this.URL = strings.TrimSpace(this.URL)
+	if this.URL == "" {
+		return fmt.Errorf("Git repo for user '%s' is missing a URL.", this.User)
+	}
+
+	if NormalizeGitURL(this.URL) == "" {
+		return fmt.Errorf("Git repo for user '%s' has an invalid URL: '%s'.", this.User, this.URL)
+	}
+
+	return nil
+}
+
+// Check if this repo is the one referred to by the given URL (which may be in a different form).
+func (this *GitRepo) MatchesURL(otherURL string) bool {
+	if this == nil {
+		return false
+	}
+
+	normalURL := NormalizeGitURL(otherURL)
+	return (normalURL != "") && (normalURL == NormalizeGitURL(this.URL))
+}
+
+// Get the repo (from a list of repos) for a user, or nil.
+func GetUserGitRepo(repos []*GitRepo, email string) *GitRepo {
+	for _, repo := range repos {
+		if repo.User == email {
+			return repo
+		}
+	}
+
+	return nil
+}
+
+// Normalize the different ways of referring to the same repo into a "host/path" form,
+// e.g., "https://github.com/org/repo.git", "git@github.com:org/repo.git", and "https://github.com/org/repo"
+// will all be normalized to "github.com/org/repo".
+// Local ("file://") URLs keep their scheme.
+// Returns an empty string if the URL cannot be understood.
+// Normalization is only used to compare URLs,
+// see GitSubmissionOptions.CheckRepoURL() for which URLs users may register.
+func NormalizeGitURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+
+	host := ""
+	path := ""
+
+	if strings.Contains(rawURL, "://") {
+		parsedURL, err := url.Parse(rawURL)
+		if err != nil {
+			return ""
+		}
+
+		// Local repos have no host.
+		if parsedURL.Scheme == "file" {
+			return "file://" + strings.TrimSuffix(strings.TrimRight(parsedURL.Path, "/"), ".git")
+		}
+
+		host = parsedURL.Hostname()
+		path = parsedURL.Path
+	} else {
+		// SCP-like syntax: "[user@]host:path".
+		var ok bool
+		host, path, ok = strings.Cut(rawURL, ":")
+		if !ok {
+			return ""
+		}
+
+		_, afterUser, hasUser := strings.Cut(host, "@")
+		if hasUser {
+			host = afterUser
+		}
+	}
+
+	host = strings.ToLower(host)
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+
+	if (host == "") || (path == "") {
+		return ""
+	}
+
+	return host + "/" + path
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/edulinq/autograder/internal/docker"
 	"github.com/edulinq/autograder/internal/timestamp"
 	"github.com/edulinq/autograder/internal/util"
 )
@@ -14,6 +15,9 @@ type GradingResult struct {
 	OutputFilesGZip map[string][]byte `json:"output-files-gzip"`
 	Stdout          string            `json:"stdout"`
 	Stderr          string            `json:"stderr"`
+
+	// Set when the grader was stopped for going past a resource limit.
+	LimitViolation docker.LimitViolation `json:"limit-violation,omitempty"`
 }
 
 type GradingInfo struct {
@@ -31,6 +35,18 @@ type GradingInfo struct {
 	ProxyStartTime *timestamp.Timestamp `json:"proxy_start_time,omitempty"`
 	ProxyEndTime   *timestamp.Timestamp `json:"proxy_end_time,omitempty"`
 
+	// The resources used by the grader (if available).
+	ResourceUsage *docker.ResourceUsage `json:"resource-usage,omitempty"`
+
+	// True if the grader output was reused from an identical earlier run instead of running the grader.
+	FromCache bool `json:"from-cache,omitempty"`
+
+	// Set if this submission was made on behalf of a group.
+	Group *SubmissionGroupInfo `json:"group,omitempty"`
+
+	// The git commit that was graded (for submissions that come from a git repo).
+	CommitSHA string `json:"commit-sha,omitempty"`
+
 	// Information generally filled out by the grader.
 
 	Name             string              `json:"name"`
@@ -54,6 +70,9 @@ type GradedQuestion struct {
 	Message          string              `json:"message"`
 	GradingStartTime timestamp.Timestamp `json:"grading_start_time"`
 	GradingEndTime   timestamp.Timestamp `json:"grading_end_time"`
+
+	// Optional structured results for the individual test cases that make up this question.
+	TestCases []*GradedTestCase `json:"test_cases,omitempty"`
 }
 
 func (this *GradingResult) HasTextOutput() bool {
@@ -125,6 +144,24 @@ func (this GradingInfo) Report() string {
 	return builder.String()
 }
 
+// Validate the test cases for all questions and fill in any missing diffs.
+func (this *GradingInfo) PrepTestCases() error {
+	for _, question := range this.Questions {
+		if question == nil {
+			continue
+		}
+
+		for i, testCase := range question.TestCases {
+			err := testCase.Validate()
+			if err != nil {
+				return fmt.Errorf("Invalid test case at index %d for question '%s': '%w'.", i, question.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Fill in the MaxPoints, Score, and (if empty) time fields.
 func (this *GradingInfo) ComputePoints() {
 	for _, question := range this.Questions {
@@ -152,6 +189,10 @@ func (this GradedQuestion) Report() string {
 		}
 	}
 
+	for _, testCase := range this.TestCases {
+		builder.WriteString(testCase.Report())
+	}
+
 	return builder.String()
 }
 
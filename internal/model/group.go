@@ -0,0 +1,178 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/common"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+const GROUPS_FILENAME = "groups.json"
+
+// Options for assignments that are submitted by groups (e.g., partners) instead of individuals.
+type GroupOptions struct {
+	// The max number of users allowed in a group.
+	MaxSize int `json:"max-size"`
+
+	// Allow students to form their own groups.
+	// If false, only course admins can create groups.
+	AllowStudentGroups bool `json:"allow-student-groups,omitempty"`
+}
+
+// A group of users that share submissions for an assignment.
+// Every submission made by a member of a group counts for all members.
+type Group struct {
+	ID           string   `json:"id"`
+	CourseID     string   `json:"course-id"`
+	AssignmentID string   `json:"assignment-id"`
+	Members      []string `json:"members"`
+
+	// Users that have been invited to join the group, but have not accepted yet.
+	// Invited users are not members (and do not share submissions) until they accept.
+	Invited []string `json:"invited,omitempty"`
+
+	CreatedBy  string              `json:"created-by"`
+	UpdateTime timestamp.Timestamp `json:"update-time"`
+}
+
+// Information about the group that made a submission.
+type SubmissionGroupInfo struct {
+	ID string `json:"id"`
+
+	// The user that actually made the submission.
+	Submitter string `json:"submitter"`
+
+	// All the members of the group (including the submitter) at the time of submission.
+	Members []string `json:"members"`
+}
+
+func (this *GroupOptions) Validate() error {
+	if this == nil {
+		return nil
+	}
+
+	if this.MaxSize < 2 {
+		return fmt.Errorf("Group max size must be at least 2, found: %d.", this.MaxSize)
+	}
+
+	return nil
+}
+
+// Validate a group against the assignment's group options.
+// Members and invited users will be normalized, sorted, and deduplicated.
+// Invited users that are already members are dropped from the invites.
+func (this *Group) Validate(options *GroupOptions) error {
+	if this == nil {
+		return fmt.Errorf("Group is nil.")
+	}
+
+	if options == nil {
+		return fmt.Errorf("Assignment does not allow groups.")
+	}
+
+	var err error
+
+	this.ID, err = common.ValidateID(this.ID)
+	if err != nil {
+		return fmt.Errorf("Group has an invalid ID: '%w'.", err)
+	}
+
+	if (this.CourseID == "") || (this.AssignmentID == "") {
+		return fmt.Errorf("Group '%s' is missing a course or assignment.", this.ID)
+	}
+
+	this.Members, err = normalizeEmails(this.Members)
+	if err != nil {
+		return fmt.Errorf("Group '%s' has an invalid member: '%w'.", this.ID, err)
+	}
+
+	this.Invited, err = normalizeEmails(this.Invited)
+	if err != nil {
+		return fmt.Errorf("Group '%s' has an invalid invite: '%w'.", this.ID, err)
+	}
+
+	this.Invited = slices.DeleteFunc(this.Invited, func(email string) bool {
+		return slices.Contains(this.Members, email)
+	})
+
+	if len(this.Invited) == 0 {
+		this.Invited = nil
+	}
+
+	if len(this.Members) == 0 {
+		return fmt.Errorf("Group '%s' has no members.", this.ID)
+	}
+
+	// Invites count against the max size, so accepting one can never make the group too large.
+	size := len(this.Members) + len(this.Invited)
+	if size > options.MaxSize {
+		return fmt.Errorf("Group '%s' has too many members and invites (%d), max: %d.", this.ID, size, options.MaxSize)
+	}
+
+	return nil
+}
+
+// All users in the group (members and invited users).
+func (this *Group) GetAllUsers() []string {
+	if this == nil {
+		return nil
+	}
+
+	return append(slices.Clone(this.Members), this.Invited...)
+}
+
+func (this *Group) HasMember(email string) bool {
+	if this == nil {
+		return false
+	}
+
+	return slices.Contains(this.Members, email)
+}
+
+func (this *Group) HasInvite(email string) bool {
+	if this == nil {
+		return false
+	}
+
+	return slices.Contains(this.Invited, email)
+}
+
+// Get the group (from a list of groups) that a user is in, or nil.
+func GetUserGroup(groups []*Group, email string) *Group {
+	for _, group := range groups {
+		if group.HasMember(email) {
+			return group
+		}
+	}
+
+	return nil
+}
+
+// Get the groups (from a list of groups) that a user has been invited to.
+func GetUserInvites(groups []*Group, email string) []*Group {
+	invites := make([]*Group, 0)
+	for _, group := range groups {
+		if group.HasInvite(email) {
+			invites = append(invites, group)
+		}
+	}
+
+	return invites
+}
+
+func normalizeEmails(emails []string) ([]string, error) {
+	results := make([]string, 0, len(emails))
+	for _, email := range emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" {
+			return nil, fmt.Errorf("Found an empty email.")
+		}
+
+		results = append(results, email)
+	}
+
+	slices.Sort(results)
+	return slices.Compact(results), nil
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupOptionsValidate(test *testing.T) {
+	testCases := []struct {
+		options  *GroupOptions
+		hasError bool
+	}{
+		{nil, false},
+		{&GroupOptions{MaxSize: 2}, false},
+		{&GroupOptions{MaxSize: 4, AllowStudentGroups: true}, false},
+
+		{&GroupOptions{}, true},
+		{&GroupOptions{MaxSize: 1}, true},
+		{&GroupOptions{MaxSize: -1}, true},
+	}
+
+	for i, testCase := range testCases {
+		err := testCase.options.Validate()
+		if testCase.hasError != (err != nil) {
+			test.Errorf("Case %d: Unexpected error result. Expected error: '%v', Actual: '%v'.", i, testCase.hasError, err)
+			continue
+		}
+	}
+}
+
+func TestGroupValidate(test *testing.T) {
+	options := &GroupOptions{MaxSize: 2}
+
+	testCases := []struct {
+		id              string
+		members         []string
+		invited         []string
+		options         *GroupOptions
+		hasError        bool
+		expectedMembers []string
+		expectedInvited []string
+	}{
+		{"g1", []string{"b@test.edulinq.org", "a@test.edulinq.org"}, nil, options, false, []string{"a@test.edulinq.org", "b@test.edulinq.org"}, nil},
+		{"g1", []string{" A@test.edulinq.org ", "a@test.edulinq.org"}, nil, options, false, []string{"a@test.edulinq.org"}, nil},
+
+		// Invites.
+		{"g1", []string{"a@test.edulinq.org"}, []string{" B@test.edulinq.org "}, options, false, []string{"a@test.edulinq.org"}, []string{"b@test.edulinq.org"}},
+		{"g1", []string{"a@test.edulinq.org"}, []string{"a@test.edulinq.org"}, options, false, []string{"a@test.edulinq.org"}, nil},
+		{"g1", []string{"a@test.edulinq.org"}, []string{}, options, false, []string{"a@test.edulinq.org"}, nil},
+
+		{"g1", []string{"a@test.edulinq.org"}, nil, nil, true, nil, nil},
+		{"", []string{"a@test.edulinq.org"}, nil, options, true, nil, nil},
+		{"g 1", []string{"a@test.edulinq.org"}, nil, options, true, nil, nil},
+		{"g1", []string{}, nil, options, true, nil, nil},
+		{"g1", []string{""}, nil, options, true, nil, nil},
+		{"g1", []string{"a@test.edulinq.org", "b@test.edulinq.org", "c@test.edulinq.org"}, nil, options, true, nil, nil},
+
+		// Bad invites.
+		{"g1", []string{}, []string{"a@test.edulinq.org"}, options, true, nil, nil},
+		{"g1", []string{"a@test.edulinq.org"}, []string{""}, options, true, nil, nil},
+		{"g1", []string{"a@test.edulinq.org"}, []string{"b@test.edulinq.org", "c@test.edulinq.org"}, options, true, nil, nil},
+	}
+
+	for i, testCase := range testCases {
+		group := &Group{
+			ID:           testCase.id,
+			CourseID:     "course101",
+			AssignmentID: "hw0",
+			Members:      testCase.members,
+			Invited:      testCase.invited,
+		}
+
+		err := group.Validate(testCase.options)
+		if testCase.hasError {
+			if err == nil {
+				test.Errorf("Case %d: Did not get an expected error.", i)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			test.Errorf("Case %d: Got an unexpected error: '%v'.", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(testCase.expectedMembers, group.Members) {
+			test.Errorf("Case %d: Unexpected members. Expected: '%v', Actual: '%v'.", i, testCase.expectedMembers, group.Members)
+			continue
+		}
+
+		if !reflect.DeepEqual(testCase.expectedInvited, group.Invited) {
+			test.Errorf("Case %d: Unexpected invites. Expected: '%v', Actual: '%v'.", i, testCase.expectedInvited, group.Invited)
+			continue
+		}
+	}
+}
+
+func TestGetUserGroup(test *testing.T) {
+	groups := []*Group{
+		&Group{ID: "g1", Members: []string{"a@test.edulinq.org", "b@test.edulinq.org"}},
+		&Group{ID: "g2", Members: []string{"c@test.edulinq.org"}},
+	}
+
+	testCases := []struct {
+		email    string
+		expected *Group
+	}{
+		{"a@test.edulinq.org", groups[0]},
+		{"b@test.edulinq.org", groups[0]},
+		{"c@test.edulinq.org", groups[1]},
+		{"z@test.edulinq.org", nil},
+	}
+
+	for i, testCase := range testCases {
+		actual := GetUserGroup(groups, testCase.email)
+		if testCase.expected != actual {
+			test.Errorf("Case %d: Unexpected group. Expected: '%v', Actual: '%v'.", i, testCase.expected, actual)
+			continue
+		}
+	}
+}
+
+func TestGetUserInvites(test *testing.T) {
+	groups := []*Group{
+		&Group{ID: "g1", Members: []string{"a@test.edulinq.org"}, Invited: []string{"b@test.edulinq.org", "c@test.edulinq.org"}},
+		&Group{ID: "g2", Members: []string{"d@test.edulinq.org"}, Invited: []string{"c@test.edulinq.org"}},
+	}
+
+	testCases := []struct {
+		email    string
+		expected []*Group
+	}{
+		{"a@test.edulinq.org", []*Group{}},
+		{"b@test.edulinq.org", []*Group{groups[0]}},
+		{"c@test.edulinq.org", []*Group{groups[0], groups[1]}},
+		{"z@test.edulinq.org", []*Group{}},
+	}
+
+	for i, testCase := range testCases {
+		actual := GetUserInvites(groups, testCase.email)
+		if !reflect.DeepEqual(testCase.expected, actual) {
+			test.Errorf("Case %d: Unexpected invites. Expected: '%v', Actual: '%v'.", i, testCase.expected, actual)
+			continue
+		}
+	}
+}
@@ -6,8 +6,12 @@ import (
 )
 
 const (
-	LMS_TYPE_CANVAS = "canvas"
-	LMS_TYPE_TEST   = "test"
+	LMS_TYPE_BLACKBOARD = "blackboard"
+	LMS_TYPE_CANVAS     = "canvas"
+	LMS_TYPE_D2L        = "d2l"
+	LMS_TYPE_LTI        = "lti"
+	LMS_TYPE_MOODLE     = "moodle"
+	LMS_TYPE_TEST       = "test"
 )
 
 type LMSAdapter struct {
@@ -18,6 +22,9 @@ type LMSAdapter struct {
 	APIToken    string `json:"api-token,omitempty"`
 	BaseURL     string `json:"base-url,omitempty"`
 
+	// LTI 1.3 options (only used with the "lti" type).
+	LTI *LTIOptions `json:"lti,omitempty"`
+
 	// Behavior options.
 
 	SyncUserAttributes bool `json:"sync-user-attributes,omitempty"`
@@ -38,6 +45,21 @@ func (this *LMSAdapter) Validate() error {
 	}
 	this.Type = strings.ToLower(this.Type)
 
+	if this.Type == LMS_TYPE_LTI {
+		if this.LTI == nil {
+			return fmt.Errorf("LMS type '%s' requires LTI options ('lti').", LMS_TYPE_LTI)
+		}
+
+		if this.LMSCourseID == "" {
+			return fmt.Errorf("LMS type '%s' requires a course ID (the LTI context ID).", LMS_TYPE_LTI)
+		}
+
+		err := this.LTI.Validate()
+		if err != nil {
+			return fmt.Errorf("Invalid LTI options: '%w'.", err)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/common"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+const LMS_COMMENTS_FILENAME = "lms-comments.json"
+
+// A comment on a user's score for an LMS assignment that is stored in the autograder instead of the LMS.
+// This is used for LMSs that cannot reliably store submission comments,
+// which the autograder uses to keep track of information like scoring and late days.
+// Each user has at most one comment per assignment, so a comment is identified by its user's LMS ID.
+type LMSComment struct {
+	CourseID        string `json:"course-id"`
+	AssignmentLMSID string `json:"assignment-lms-id"`
+	UserLMSID       string `json:"user-lms-id"`
+
+	Text string `json:"text"`
+
+	UpdateTime timestamp.Timestamp `json:"update-time"`
+}
+
+func (this *LMSComment) Validate() error {
+	if this == nil {
+		return fmt.Errorf("LMS comment is nil.")
+	}
+
+	var err error
+
+	this.CourseID, err = common.ValidateID(this.CourseID)
+	if err != nil {
+		return fmt.Errorf("LMS comment has an invalid course ID: '%w'.", err)
+	}
+
+	this.AssignmentLMSID = strings.TrimSpace(this.AssignmentLMSID)
+	if this.AssignmentLMSID == "" {
+		return fmt.Errorf("LMS comment is missing an assignment LMS ID.")
+	}
+
+	this.UserLMSID = strings.TrimSpace(this.UserLMSID)
+	if this.UserLMSID == "" {
+		return fmt.Errorf("LMS comment is missing a user LMS ID.")
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Information about an LTI 1.3 platform (LMS) registration.
+// The autograder acts as the tool, and the platform supplies these values when the tool is registered.
+// The LTI context ID is the course ID of the LMS adapter.
+type LTIOptions struct {
+	// Identity options (required).
+	Issuer       string `json:"issuer"`
+	ClientID     string `json:"client-id"`
+	AuthLoginURL string `json:"auth-login-url"`
+	KeySetURL    string `json:"key-set-url"`
+
+	// If set, only launches from this deployment are accepted.
+	DeploymentID string `json:"deployment-id,omitempty"`
+
+	// Service options (required to use the LTI services, e.g., syncing users or uploading scores).
+	AuthTokenURL   string `json:"auth-token-url,omitempty"`
+	LineItemsURL   string `json:"line-items-url,omitempty"`
+	MembershipsURL string `json:"memberships-url,omitempty"`
+}
+
+func (this *LTIOptions) Validate() error {
+	required := []struct {
+		name  string
+		value *string
+	}{
+		{"issuer", &this.Issuer},
+		{"client-id", &this.ClientID},
+		{"auth-login-url", &this.AuthLoginURL},
+		{"key-set-url", &this.KeySetURL},
+	}
+
+	for _, field := range required {
+		*field.value = strings.TrimSpace(*field.value)
+		if *field.value == "" {
+			return fmt.Errorf("LTI option '%s' cannot be empty.", field.name)
+		}
+	}
+
+	this.DeploymentID = strings.TrimSpace(this.DeploymentID)
+	this.AuthTokenURL = strings.TrimSpace(this.AuthTokenURL)
+	this.LineItemsURL = strings.TrimSpace(this.LineItemsURL)
+	this.MembershipsURL = strings.TrimSpace(this.MembershipsURL)
+
+	return nil
+}
+
+// Check if a launch/login from this platform registration is allowed.
+// An empty deployment ID will match any deployment.
+func (this *LTIOptions) Matches(issuer string, clientID string, deploymentID string) bool {
+	if (this.Issuer != issuer) || (this.ClientID != clientID) {
+		return false
+	}
+
+	return (deploymentID == "") || (this.DeploymentID == "") || (this.DeploymentID == deploymentID)
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/common"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+const MANUAL_GRADE_FILENAME = "manual-grade.json"
+
+// A rubric that graders can use to manually add points on top of the autograder's score.
+type Rubric struct {
+	Items []*RubricItem `json:"items"`
+}
+
+type RubricItem struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+
+	// The points awarded when this item is selected.
+	// Negative points are a deduction.
+	Points float64 `json:"points"`
+}
+
+// A grader's manual grading of a single submission.
+type ManualGrade struct {
+	ID           string `json:"id"`
+	ShortID      string `json:"short-id"`
+	CourseID     string `json:"course-id"`
+	AssignmentID string `json:"assignment-id"`
+	User         string `json:"user"`
+
+	Grader     string              `json:"grader"`
+	UpdateTime timestamp.Timestamp `json:"update-time"`
+
+	// The IDs of the rubric items that were selected.
+	Selections []string `json:"selections"`
+
+	Comments string `json:"comments,omitempty"`
+
+	// Free-form points added to (or removed from if negative) the rubric score.
+	Adjustment float64 `json:"adjustment,omitempty"`
+}
+
+func (this *Rubric) Validate() error {
+	if this == nil {
+		return nil
+	}
+
+	seenIDs := make(map[string]bool, len(this.Items))
+
+	for i, item := range this.Items {
+		if item == nil {
+			return fmt.Errorf("Rubric item at index %d is nil.", i)
+		}
+
+		var err error
+		item.ID, err = common.ValidateID(item.ID)
+		if err != nil {
+			return fmt.Errorf("Rubric item at index %d has an invalid ID: '%w'.", i, err)
+		}
+
+		if seenIDs[item.ID] {
+			return fmt.Errorf("Rubric has a duplicate item ID: '%s'.", item.ID)
+		}
+
+		seenIDs[item.ID] = true
+	}
+
+	return nil
+}
+
+// Get the rubric item with the given ID, or nil if there is no such item.
+func (this *Rubric) GetItem(id string) *RubricItem {
+	if this == nil {
+		return nil
+	}
+
+	for _, item := range this.Items {
+		if item.ID == id {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// Ensure that the manual grade is well-formed and only references items in the given rubric.
+// Selections will be normalized (trimmed, lowercased, sorted, and deduplicated).
+func (this *ManualGrade) Validate(rubric *Rubric) error {
+	if this == nil {
+		return fmt.Errorf("Manual grade is nil.")
+	}
+
+	if this.ID == "" {
+		this.ID = common.CreateFullSubmissionID(this.CourseID, this.AssignmentID, this.User, this.ShortID)
+	}
+
+	if (this.CourseID == "") || (this.AssignmentID == "") || (this.User == "") || (this.ShortID == "") {
+		return fmt.Errorf("Manual grade is missing a submission component: '%s'.", this.ID)
+	}
+
+	if this.Grader == "" {
+		return fmt.Errorf("Manual grade '%s' is missing a grader.", this.ID)
+	}
+
+	if this.Selections == nil {
+		this.Selections = make([]string, 0)
+	}
+
+	for i, selection := range this.Selections {
+		this.Selections[i] = strings.TrimSpace(strings.ToLower(selection))
+	}
+
+	slices.Sort(this.Selections)
+	this.Selections = slices.Compact(this.Selections)
+
+	for _, selection := range this.Selections {
+		if rubric.GetItem(selection) == nil {
+			return fmt.Errorf("Manual grade '%s' selects an unknown rubric item: '%s'.", this.ID, selection)
+		}
+	}
+
+	return nil
+}
+
+// Get the points from this manual grade (selected rubric items plus the adjustment).
+// Selections that are no longer in the rubric are ignored.
+func (this *ManualGrade) GetScore(rubric *Rubric) float64 {
+	if this == nil {
+		return 0.0
+	}
+
+	score := this.Adjustment
+
+	for _, selection := range this.Selections {
+		item := rubric.GetItem(selection)
+		if item != nil {
+			score += item.Points
+		}
+	}
+
+	return score
+}
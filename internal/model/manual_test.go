@@ -0,0 +1,139 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRubricValidate(test *testing.T) {
+	testCases := []struct {
+		rubric   *Rubric
+		hasError bool
+	}{
+		{nil, false},
+		{&Rubric{}, false},
+		{&Rubric{Items: []*RubricItem{&RubricItem{ID: "a", Points: 1}}}, false},
+		{&Rubric{Items: []*RubricItem{&RubricItem{ID: " A ", Points: -1}}}, false},
+		{&Rubric{Items: []*RubricItem{&RubricItem{ID: "a"}, &RubricItem{ID: "b"}}}, false},
+
+		{&Rubric{Items: []*RubricItem{nil}}, true},
+		{&Rubric{Items: []*RubricItem{&RubricItem{}}}, true},
+		{&Rubric{Items: []*RubricItem{&RubricItem{ID: "a b"}}}, true},
+		{&Rubric{Items: []*RubricItem{&RubricItem{ID: "a"}, &RubricItem{ID: "A"}}}, true},
+	}
+
+	for i, testCase := range testCases {
+		err := testCase.rubric.Validate()
+		if testCase.hasError != (err != nil) {
+			test.Errorf("Case %d: Unexpected error result. Expected error: '%v', Actual: '%v'.", i, testCase.hasError, err)
+			continue
+		}
+	}
+}
+
+func TestManualGradeValidateAndScore(test *testing.T) {
+	rubric := &Rubric{
+		Items: []*RubricItem{
+			&RubricItem{ID: "style", Points: 1},
+			&RubricItem{ID: "docs", Points: 0.5},
+			&RubricItem{ID: "hardcoded", Points: -2},
+		},
+	}
+
+	testCases := []struct {
+		selections         []string
+		adjustment         float64
+		rubric             *Rubric
+		hasError           bool
+		expectedSelections []string
+		expectedScore      float64
+	}{
+		{nil, 0, rubric, false, []string{}, 0},
+		{[]string{}, 1.5, rubric, false, []string{}, 1.5},
+		{[]string{"style"}, 0, rubric, false, []string{"style"}, 1},
+		{[]string{"style", "docs"}, 0, rubric, false, []string{"docs", "style"}, 1.5},
+		{[]string{" STYLE ", "style", "hardcoded"}, -0.5, rubric, false, []string{"hardcoded", "style"}, -1.5},
+		{nil, 2, nil, false, []string{}, 2},
+
+		{[]string{"zzz"}, 0, rubric, true, nil, 0},
+		{[]string{"style"}, 0, nil, true, nil, 0},
+	}
+
+	for i, testCase := range testCases {
+		grade := &ManualGrade{
+			ShortID:      "1",
+			CourseID:     "course101",
+			AssignmentID: "hw0",
+			User:         "course-student@test.edulinq.org",
+			Grader:       "course-grader@test.edulinq.org",
+			Selections:   testCase.selections,
+			Adjustment:   testCase.adjustment,
+		}
+
+		err := grade.Validate(testCase.rubric)
+		if testCase.hasError {
+			if err == nil {
+				test.Errorf("Case %d: Did not get an expected error.", i)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			test.Errorf("Case %d: Got an unexpected error: '%v'.", i, err)
+			continue
+		}
+
+		if grade.ID != "course101::hw0::course-student@test.edulinq.org::1" {
+			test.Errorf("Case %d: Unexpected ID: '%s'.", i, grade.ID)
+			continue
+		}
+
+		if !reflect.DeepEqual(testCase.expectedSelections, grade.Selections) {
+			test.Errorf("Case %d: Unexpected selections. Expected: '%v', Actual: '%v'.", i, testCase.expectedSelections, grade.Selections)
+			continue
+		}
+
+		score := grade.GetScore(testCase.rubric)
+		if testCase.expectedScore != score {
+			test.Errorf("Case %d: Unexpected score. Expected: '%f', Actual: '%f'.", i, testCase.expectedScore, score)
+			continue
+		}
+	}
+}
+
+func TestManualGradeValidateMissingFields(test *testing.T) {
+	testCases := []*ManualGrade{
+		nil,
+		&ManualGrade{CourseID: "c", AssignmentID: "a", User: "u", ShortID: "1"},
+		&ManualGrade{AssignmentID: "a", User: "u", ShortID: "1", Grader: "g"},
+		&ManualGrade{CourseID: "c", AssignmentID: "a", User: "u", Grader: "g"},
+	}
+
+	for i, testCase := range testCases {
+		err := testCase.Validate(nil)
+		if err == nil {
+			test.Errorf("Case %d: Did not get an expected error.", i)
+		}
+	}
+}
+
+// Selections that were removed from the rubric no longer count towards the score.
+func TestManualGradeScoreRemovedItem(test *testing.T) {
+	grade := &ManualGrade{
+		Selections: []string{"removed", "style"},
+		Adjustment: 1,
+	}
+
+	rubric := &Rubric{Items: []*RubricItem{&RubricItem{ID: "style", Points: 2}}}
+
+	score := grade.GetScore(rubric)
+	if score != 3 {
+		test.Fatalf("Unexpected score. Expected: '3', Actual: '%f'.", score)
+	}
+
+	var nilGrade *ManualGrade = nil
+	if nilGrade.GetScore(rubric) != 0 {
+		test.Fatalf("Nil manual grade has a non-zero score.")
+	}
+}
@@ -17,6 +17,9 @@ type ScoringInfo struct {
 	NumDaysLate    int                 `json:"num-days-late"`
 	Reject         bool                `json:"reject"`
 
+	// Set when the raw score includes a manual grade that was made for an earlier submission.
+	StaleManualGrade bool `json:"stale-manual-grade,omitempty"`
+
 	// A distinct key so we can recognize this as an autograder object.
 	AutograderStructVersion string `json:"__autograder__version__"`
 
@@ -97,6 +97,43 @@ func LoadGradingResult(resultPath string) (*GradingResult, error) {
 	}, nil
 }
 
+// Write a full standard grading result into a submission dir.
+// This is the inverse of LoadGradingResult().
+func WriteGradingResult(result *GradingResult, baseSubmissionDir string) error {
+	err := util.MkDir(baseSubmissionDir)
+	if err != nil {
+		return fmt.Errorf("Failed to make submission dir '%s': '%w'.", baseSubmissionDir, err)
+	}
+
+	resultPath := filepath.Join(baseSubmissionDir, SUBMISSION_RESULT_FILENAME)
+	err = util.ToJSONFileIndent(result.Info, resultPath)
+	if err != nil {
+		return fmt.Errorf("Failed to write submission result '%s': '%w'.", resultPath, err)
+	}
+
+	err = util.GzipBytesToDirectory(filepath.Join(baseSubmissionDir, common.GRADING_INPUT_DIRNAME), result.InputFilesGZip)
+	if err != nil {
+		return fmt.Errorf("Failed to write submission input files: '%w'.", err)
+	}
+
+	err = util.GzipBytesToDirectory(filepath.Join(baseSubmissionDir, common.GRADING_OUTPUT_DIRNAME), result.OutputFilesGZip)
+	if err != nil {
+		return fmt.Errorf("Failed to write submission output files: '%w'.", err)
+	}
+
+	err = util.WriteFile(result.Stdout, filepath.Join(baseSubmissionDir, common.SUBMISSION_STDOUT_FILENAME))
+	if err != nil {
+		return fmt.Errorf("Failed to write submission stdout file: '%w'.", err)
+	}
+
+	err = util.WriteFile(result.Stderr, filepath.Join(baseSubmissionDir, common.SUBMISSION_STDERR_FILENAME))
+	if err != nil {
+		return fmt.Errorf("Failed to write submission stderr file: '%w'.", err)
+	}
+
+	return nil
+}
+
 func MustLoadGradingResult(resultPath string) *GradingResult {
 	result, err := LoadGradingResult(resultPath)
 	if err != nil {
@@ -138,6 +138,38 @@ func TestTaskValidationBase(test *testing.T) {
             }`,
 			"",
 		},
+		{
+			&UserTaskInfo{
+				Type: TaskTypeCourseBackup,
+				When: &util.ScheduledTime{
+					Daily: "3:00",
+				},
+				Options: map[string]any{
+					"incremental": true,
+					"full-every":  7,
+					"keep-last":   3,
+					"keep-daily":  7,
+					"keep-weekly": 4,
+					"verify":      true,
+				},
+			},
+			`{
+                "type": "backup",
+                "when": {
+                    "daily": "3:00",
+                    "every": {}
+                },
+                "options": {
+                    "incremental": true,
+                    "full-every": 7,
+                    "keep-last": 3,
+                    "keep-daily": 7,
+                    "keep-weekly": 4,
+                    "verify": true
+                }
+            }`,
+			"",
+		},
 
 		// Errors
 		{
@@ -202,6 +234,32 @@ func TestTaskValidationBase(test *testing.T) {
 			``,
 			"'to' value is not properly formatted",
 		},
+		{
+			&UserTaskInfo{
+				Type: TaskTypeCourseBackup,
+				When: &util.ScheduledTime{
+					Daily: "3:00",
+				},
+				Options: map[string]any{
+					"keep-last": -1,
+				},
+			},
+			``,
+			"'keep-last' value must be non-negative",
+		},
+		{
+			&UserTaskInfo{
+				Type: TaskTypeCourseBackup,
+				When: &util.ScheduledTime{
+					Daily: "3:00",
+				},
+				Options: map[string]any{
+					"incremental": "ZZZ",
+				},
+			},
+			``,
+			"'incremental' value is not a boolean",
+		},
 	}
 
 	for i, testCase := range testCases {
@@ -15,6 +15,7 @@ const (
 
 	TaskTypeCourseBackup        TaskType = "backup"
 	TaskTypeCourseEmailLogs     TaskType = "email-logs"
+	TaskTypeCourseGitPoll       TaskType = "git-poll"
 	TaskTypeCourseReport        TaskType = "report"
 	TaskTypeCourseScoringUpload TaskType = "scoring-upload"
 	TaskTypeCourseUpdate        TaskType = "update"
@@ -27,6 +28,7 @@ var taskTypeToString = map[TaskType]string{
 
 	TaskTypeCourseBackup:        string(TaskTypeCourseBackup),
 	TaskTypeCourseEmailLogs:     string(TaskTypeCourseEmailLogs),
+	TaskTypeCourseGitPoll:       string(TaskTypeCourseGitPoll),
 	TaskTypeCourseReport:        string(TaskTypeCourseReport),
 	TaskTypeCourseScoringUpload: string(TaskTypeCourseScoringUpload),
 	TaskTypeCourseUpdate:        string(TaskTypeCourseUpdate),
@@ -39,6 +41,7 @@ var stringToTaskType = map[string]TaskType{
 
 	string(TaskTypeCourseBackup):        TaskTypeCourseBackup,
 	string(TaskTypeCourseEmailLogs):     TaskTypeCourseEmailLogs,
+	string(TaskTypeCourseGitPoll):       TaskTypeCourseGitPoll,
 	string(TaskTypeCourseReport):        TaskTypeCourseReport,
 	string(TaskTypeCourseScoringUpload): TaskTypeCourseScoringUpload,
 	string(TaskTypeCourseUpdate):        TaskTypeCourseUpdate,
@@ -62,7 +65,7 @@ func (this *TaskType) UnmarshalJSON(data []byte) error {
 func validateTaskTypes(task *UserTaskInfo) error {
 	switch task.Type {
 	case TaskTypeCourseBackup:
-		return nil
+		return validateTaskTypeCourseBackup(task)
 	case TaskTypeCourseReport:
 		return validateTaskTypeCourseReport(task)
 	case TaskTypeCourseScoringUpload:
@@ -71,6 +74,8 @@ func validateTaskTypes(task *UserTaskInfo) error {
 		return nil
 	case TaskTypeCourseEmailLogs:
 		return validateTaskTypeCourseEmailLogs(task)
+	case TaskTypeCourseGitPoll:
+		return nil
 	case TaskTypeTest:
 		return nil
 	default:
@@ -78,6 +83,42 @@ func validateTaskTypes(task *UserTaskInfo) error {
 	}
 }
 
+func validateTaskTypeCourseBackup(task *UserTaskInfo) error {
+	for _, key := range []string{"keep-last", "keep-daily", "keep-weekly", "full-every"} {
+		_, exists := task.Options[key]
+		if !exists {
+			continue
+		}
+
+		value, err := GetTaskOptionAsType(task, key, 0)
+		if err != nil {
+			return fmt.Errorf("'%s' value is not an integer: '%w'.", key, err)
+		}
+
+		if value < 0 {
+			return fmt.Errorf("'%s' value must be non-negative, found: %d.", key, value)
+		}
+
+		task.Options[key] = value
+	}
+
+	for _, key := range []string{"incremental", "verify"} {
+		_, exists := task.Options[key]
+		if !exists {
+			continue
+		}
+
+		value, err := GetTaskOptionAsType(task, key, false)
+		if err != nil {
+			return fmt.Errorf("'%s' value is not a boolean: '%w'.", key, err)
+		}
+
+		task.Options[key] = value
+	}
+
+	return nil
+}
+
 func validateTaskTypeCourseEmailLogs(task *UserTaskInfo) error {
 	err := validateEmailList(task)
 	if err != nil {
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/util"
+)
+
+type TestCaseStatus string
+
+const (
+	TestCaseStatusPass TestCaseStatus = "pass"
+	TestCaseStatusFail TestCaseStatus = "fail"
+	TestCaseStatusSkip TestCaseStatus = "skip"
+)
+
+// The result of a single test case within a graded question.
+type GradedTestCase struct {
+	Name    string         `json:"name"`
+	Status  TestCaseStatus `json:"status"`
+	Message string         `json:"message,omitempty"`
+
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+
+	// A unified diff (expected vs actual).
+	// If not provided by the grader, it will be computed for failed test cases that have an expected or actual value.
+	Diff string `json:"diff,omitempty"`
+
+	DurationMSecs int64 `json:"duration_msecs,omitempty"`
+}
+
+func (this *GradedTestCase) Validate() error {
+	if this == nil {
+		return fmt.Errorf("Test case is nil.")
+	}
+
+	if this.Name == "" {
+		return fmt.Errorf("Test case is missing a name.")
+	}
+
+	this.Status = TestCaseStatus(strings.ToLower(string(this.Status)))
+
+	switch this.Status {
+	case TestCaseStatusPass, TestCaseStatusFail, TestCaseStatusSkip:
+	default:
+		return fmt.Errorf("Test case '%s' has an unknown status '%s'.", this.Name, this.Status)
+	}
+
+	if this.DurationMSecs < 0 {
+		return fmt.Errorf("Test case '%s' has a negative duration: %d.", this.Name, this.DurationMSecs)
+	}
+
+	if (this.Status == TestCaseStatusFail) && (this.Diff == "") && ((this.Expected != "") || (this.Actual != "")) {
+		diff, err := util.ComputeUnfiedDiffFull(this.Expected, "expected", this.Actual, "actual")
+		if err != nil {
+			return fmt.Errorf("Failed to compute diff for test case '%s': '%w'.", this.Name, err)
+		}
+
+		this.Diff = diff
+	}
+
+	return nil
+}
+
+func (this GradedTestCase) Report() string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("    [%s] %s", strings.ToUpper(string(this.Status)), this.Name))
+
+	if this.DurationMSecs > 0 {
+		builder.WriteString(fmt.Sprintf(" (%d ms)", this.DurationMSecs))
+	}
+
+	builder.WriteString("\n")
+
+	if this.Message != "" {
+		for _, line := range strings.Split(this.Message, "\n") {
+			builder.WriteString(fmt.Sprintf("        %s\n", strings.TrimSpace(line)))
+		}
+	}
+
+	if (this.Status == TestCaseStatusFail) && (this.Diff != "") {
+		for _, line := range strings.Split(strings.TrimRight(this.Diff, "\n"), "\n") {
+			builder.WriteString(fmt.Sprintf("        %s\n", line))
+		}
+	}
+
+	return builder.String()
+}
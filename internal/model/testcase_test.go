@@ -0,0 +1,111 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestGradedTestCaseValidate(test *testing.T) {
+	testCases := []struct {
+		testCase     *GradedTestCase
+		hasError     bool
+		expectedDiff bool
+	}{
+		{&GradedTestCase{Name: "a", Status: TestCaseStatusPass}, false, false},
+		{&GradedTestCase{Name: "a", Status: "PASS"}, false, false},
+		{&GradedTestCase{Name: "a", Status: TestCaseStatusSkip}, false, false},
+		{&GradedTestCase{Name: "a", Status: TestCaseStatusFail}, false, false},
+
+		// Diffs are only computed for failures.
+		{&GradedTestCase{Name: "a", Status: TestCaseStatusPass, Expected: "1", Actual: "1"}, false, false},
+		{&GradedTestCase{Name: "a", Status: TestCaseStatusFail, Expected: "1\n", Actual: "2\n"}, false, true},
+		{&GradedTestCase{Name: "a", Status: TestCaseStatusFail, Expected: "1\n"}, false, true},
+
+		// Provided diffs are kept.
+		{&GradedTestCase{Name: "a", Status: TestCaseStatusFail, Expected: "1\n", Actual: "2\n", Diff: "custom"}, false, true},
+
+		{nil, true, false},
+		{&GradedTestCase{Status: TestCaseStatusPass}, true, false},
+		{&GradedTestCase{Name: "a"}, true, false},
+		{&GradedTestCase{Name: "a", Status: "ZZZ"}, true, false},
+		{&GradedTestCase{Name: "a", Status: TestCaseStatusPass, DurationMSecs: -1}, true, false},
+	}
+
+	for i, testCase := range testCases {
+		oldDiff := ""
+		if testCase.testCase != nil {
+			oldDiff = testCase.testCase.Diff
+		}
+
+		err := testCase.testCase.Validate()
+		if testCase.hasError {
+			if err == nil {
+				test.Errorf("Case %d: Did not get an expected error.", i)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			test.Errorf("Case %d: Got an unexpected error: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.expectedDiff != (testCase.testCase.Diff != "") {
+			test.Errorf("Case %d: Unexpected diff: '%s'.", i, testCase.testCase.Diff)
+			continue
+		}
+
+		if (oldDiff != "") && (oldDiff != testCase.testCase.Diff) {
+			test.Errorf("Case %d: Provided diff was changed. Expected: '%s', Actual: '%s'.", i, oldDiff, testCase.testCase.Diff)
+			continue
+		}
+	}
+}
+
+func TestGradingInfoReportTestCases(test *testing.T) {
+	info := GradingInfo{
+		Name: "Assignment",
+		Questions: []*GradedQuestion{
+			&GradedQuestion{
+				Name:      "Q1",
+				MaxPoints: 2,
+				Score:     1,
+				TestCases: []*GradedTestCase{
+					&GradedTestCase{Name: "T1", Status: TestCaseStatusPass, DurationMSecs: 12},
+					&GradedTestCase{Name: "T2", Status: TestCaseStatusFail, Message: "Wrong output.", Expected: "a\n", Actual: "b\n"},
+				},
+			},
+		},
+	}
+
+	err := info.PrepTestCases()
+	if err != nil {
+		test.Fatalf("Failed to prep test cases: '%v'.", err)
+	}
+
+	expectedLines := []string{
+		"Q1: 1 / 2",
+		"    [PASS] T1 (12 ms)",
+		"    [FAIL] T2",
+		"        Wrong output.",
+		"        -a",
+		"        +b",
+	}
+
+	report := info.Report()
+	for _, line := range expectedLines {
+		if !strings.Contains(report, line+"\n") {
+			test.Errorf("Report is missing line '%s': '%s'.", line, report)
+		}
+	}
+
+	info.Questions[0].TestCases = append(info.Questions[0].TestCases, &GradedTestCase{Name: "T3", Status: "ZZZ"})
+
+	err = info.PrepTestCases()
+	if err == nil {
+		test.Fatalf("Did not get an error on an invalid test case: '%s'.", util.MustToJSONIndent(info))
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
+	"slices"
 	"strings"
 
 	"golang.org/x/crypto/argon2"
 
+	"github.com/edulinq/autograder/internal/common"
 	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/timestamp"
 	"github.com/edulinq/autograder/internal/util"
@@ -26,6 +28,8 @@ const (
 	TokenSourceUser                 = "user"
 	TokenSourceAdmin                = "admin"
 	TokenSourcePassword             = "password"
+	TokenSourceSSO                  = "sso"
+	TokenSourceLTI                  = "lti"
 )
 
 // Information about a token that does not contain the actual token bytes.
@@ -36,6 +40,30 @@ type TokenInfo struct {
 	Name         string              `json:"name"`
 	CreationTime timestamp.Timestamp `json:"creation-time"`
 	AccessTime   timestamp.Timestamp `json:"access-time"`
+
+	// When this token stops working (nil means never).
+	ExpirationTime *timestamp.Timestamp `json:"expiration-time,omitempty"`
+
+	// What this token may be used for (nil means anything the user can do).
+	Scope *TokenScope `json:"scope,omitempty"`
+}
+
+// Limits on the requests a token can be used to make.
+// All the limits in a scope apply, e.g., a token that is read-only and restricted to a course
+// can only make read-only requests to that course.
+type TokenScope struct {
+	// Only allow requests that do not modify data.
+	ReadOnly bool `json:"read-only,omitempty"`
+
+	// Only allow making submissions (and checking on queued submissions).
+	SubmitOnly bool `json:"submit-only,omitempty"`
+
+	// Only allow course requests to these courses.
+	Courses []string `json:"courses,omitempty"`
+
+	// Only allow requests to endpoints that start with one of these prefixes,
+	// e.g., "courses/assignments/submissions/fetch/".
+	Endpoints []string `json:"endpoints,omitempty"`
 }
 
 // Tokens refer to any hex string that is used for authentication.
@@ -197,17 +225,90 @@ func (this *Token) Validate() error {
 		return fmt.Errorf("Token ('%s') has unknown source.", this.Name)
 	}
 
+	if this.Scope != nil {
+		err = this.Scope.Validate()
+		if err != nil {
+			return fmt.Errorf("Token ('%s') has an invalid scope: '%w'.", this.Name, err)
+		}
+
+		if this.Scope.IsEmpty() {
+			this.Scope = nil
+		}
+	}
+
 	return nil
 }
 
+// Check if this token has expired as of the given time.
+func (this *Token) IsExpiredAt(now timestamp.Timestamp) bool {
+	return (this.ExpirationTime != nil) && (*this.ExpirationTime <= now)
+}
+
+func (this *Token) IsExpired() bool {
+	return this.IsExpiredAt(timestamp.Now())
+}
+
+func (this *TokenScope) IsEmpty() bool {
+	return (this == nil) || (!this.ReadOnly && !this.SubmitOnly && (len(this.Courses) == 0) && (len(this.Endpoints) == 0))
+}
+
+func (this *TokenScope) Validate() error {
+	if this.ReadOnly && this.SubmitOnly {
+		return fmt.Errorf("A scope cannot be both read-only and submit-only.")
+	}
+
+	courses := make([]string, 0, len(this.Courses))
+	for _, courseID := range this.Courses {
+		courseID, err := common.ValidateID(strings.TrimSpace(courseID))
+		if err != nil {
+			return fmt.Errorf("Scope has an invalid course ID: '%w'.", err)
+		}
+
+		courses = append(courses, courseID)
+	}
+
+	endpoints := make([]string, 0, len(this.Endpoints))
+	for _, endpoint := range this.Endpoints {
+		endpoint = strings.TrimPrefix(strings.TrimSpace(endpoint), "/")
+		if endpoint == "" {
+			return fmt.Errorf("Scope has an empty endpoint prefix.")
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	slices.Sort(courses)
+	slices.Sort(endpoints)
+
+	this.Courses = slices.Compact(courses)
+	this.Endpoints = slices.Compact(endpoints)
+
+	return nil
+}
+
+func (this *TokenScope) Clone() *TokenScope {
+	if this == nil {
+		return nil
+	}
+
+	return &TokenScope{
+		ReadOnly:   this.ReadOnly,
+		SubmitOnly: this.SubmitOnly,
+		Courses:    slices.Clone(this.Courses),
+		Endpoints:  slices.Clone(this.Endpoints),
+	}
+}
+
 func (this *Token) Clone() *Token {
 	return &Token{
 		TokenInfo: TokenInfo{
-			ID:           this.ID,
-			Source:       this.Source,
-			Name:         this.Name,
-			CreationTime: this.CreationTime,
-			AccessTime:   this.AccessTime,
+			ID:             this.ID,
+			Source:         this.Source,
+			Name:           this.Name,
+			CreationTime:   this.CreationTime,
+			AccessTime:     this.AccessTime,
+			ExpirationTime: this.ExpirationTime,
+			Scope:          this.Scope.Clone(),
 		},
 		HexDigest: this.HexDigest,
 	}
@@ -1,8 +1,10 @@
 package model
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/edulinq/autograder/internal/timestamp"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -71,3 +73,102 @@ func TestTokenRandom(test *testing.T) {
 		test.Fatalf("Token did match when it should not have.")
 	}
 }
+
+func TestTokenExpiration(test *testing.T) {
+	now := timestamp.Now()
+	past := now - 1
+	future := now + 1000
+
+	testCases := []struct {
+		expirationTime *timestamp.Timestamp
+		expected       bool
+	}{
+		{nil, false},
+		{&past, true},
+		{&now, true},
+		{&future, false},
+	}
+
+	for i, testCase := range testCases {
+		token := &Token{
+			TokenInfo: TokenInfo{
+				ExpirationTime: testCase.expirationTime,
+			},
+		}
+
+		actual := token.IsExpiredAt(now)
+		if testCase.expected != actual {
+			test.Errorf("Case %d: Unexpected expiration. Expected: %v, Actual: %v.", i, testCase.expected, actual)
+			continue
+		}
+	}
+}
+
+func TestTokenScopeValidate(test *testing.T) {
+	testCases := []struct {
+		scope         *TokenScope
+		expected      *TokenScope
+		errorExpected bool
+	}{
+		{
+			&TokenScope{},
+			&TokenScope{Courses: []string{}, Endpoints: []string{}},
+			false,
+		},
+		{
+			&TokenScope{ReadOnly: true},
+			&TokenScope{ReadOnly: true, Courses: []string{}, Endpoints: []string{}},
+			false,
+		},
+		{
+			&TokenScope{
+				Courses:   []string{" Course101 ", "course101", "abc"},
+				Endpoints: []string{"/courses/get", " courses/assignments/ "},
+			},
+			&TokenScope{
+				Courses:   []string{"abc", "course101"},
+				Endpoints: []string{"courses/assignments/", "courses/get"},
+			},
+			false,
+		},
+
+		// Errors.
+		{
+			&TokenScope{ReadOnly: true, SubmitOnly: true},
+			nil,
+			true,
+		},
+		{
+			&TokenScope{Courses: []string{"bad id!"}},
+			nil,
+			true,
+		},
+		{
+			&TokenScope{Endpoints: []string{" "}},
+			nil,
+			true,
+		},
+	}
+
+	for i, testCase := range testCases {
+		err := testCase.scope.Validate()
+		if err != nil {
+			if !testCase.errorExpected {
+				test.Errorf("Case %d: Got an unexpected error: '%v'.", i, err)
+			}
+
+			continue
+		}
+
+		if testCase.errorExpected {
+			test.Errorf("Case %d: Did not get an expected error.", i)
+			continue
+		}
+
+		if !reflect.DeepEqual(testCase.expected, testCase.scope) {
+			test.Errorf("Case %d: Unexpected scope. Expected: '%s', Actual: '%s'.",
+				i, util.MustToJSONIndent(testCase.expected), util.MustToJSONIndent(testCase.scope))
+			continue
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/edulinq/autograder/internal/common"
 	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/timestamp"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -323,6 +324,12 @@ func (this *ServerUser) SetRandomPassword() (string, error) {
 }
 
 func (this *ServerUser) CreateRandomToken(name string, source TokenSource) (*Token, string, error) {
+	return this.CreateRandomTokenFull(name, source, nil, nil)
+}
+
+// Create a random token that may expire and/or be limited to a scope (nil values mean no limit).
+// Any of the user's tokens that have already expired will be removed.
+func (this *ServerUser) CreateRandomTokenFull(name string, source TokenSource, expirationTime *timestamp.Timestamp, scope *TokenScope) (*Token, string, error) {
 	if this.Salt == nil {
 		return nil, "", fmt.Errorf("User '%s' does not have a salt, and therefore cannot have a token.", this.Email)
 	}
@@ -332,20 +339,64 @@ func (this *ServerUser) CreateRandomToken(name string, source TokenSource) (*Tok
 		return nil, "", fmt.Errorf("User '%s' failed to generate a random token: '%w'.", this.Email, err)
 	}
 
+	token.ExpirationTime = expirationTime
+	token.Scope = scope
+
+	err = token.Validate()
+	if err != nil {
+		return nil, "", fmt.Errorf("User '%s' failed to generate a valid token: '%w'.", this.Email, err)
+	}
+
+	this.RemoveExpiredTokens()
+
 	this.Tokens = append(this.Tokens, token)
 	this.compactTokens()
 
 	return token, cleartext, nil
 }
 
+// Remove any of this user's tokens that have expired.
+// Returns the removed tokens.
+func (this *ServerUser) RemoveExpiredTokens() []*Token {
+	now := timestamp.Now()
+
+	removed := make([]*Token, 0)
+	tokens := make([]*Token, 0, len(this.Tokens))
+
+	for _, token := range this.Tokens {
+		if token.IsExpiredAt(now) {
+			removed = append(removed, token)
+		} else {
+			tokens = append(tokens, token)
+		}
+	}
+
+	this.Tokens = tokens
+
+	return removed
+}
+
 // Attempt to authenticate this user with the provided text.
-// True will be returned if any of the tokens match.
+// True will be returned if any of the tokens match (and the matching token has not expired).
 func (this *ServerUser) Auth(input string) (bool, error) {
-	var match bool = false
+	token, err := this.AuthToken(input)
+	if err != nil {
+		return false, err
+	}
+
+	return ((token != nil) && !token.IsExpired()), nil
+}
+
+// Attempt to authenticate this user with the provided text,
+// and return the password/token that matched (or nil if nothing matched).
+// Note that expired tokens can still be returned,
+// callers are responsible for checking the expiration (and scope) of the returned token.
+func (this *ServerUser) AuthToken(input string) (*Token, error) {
+	var match *Token = nil
 	var errs error = nil
 
 	if this.Salt == nil {
-		return false, fmt.Errorf("User '%s' has no salt. Cannot auth.", this.Email)
+		return nil, fmt.Errorf("User '%s' has no salt. Cannot auth.", this.Email)
 	}
 
 	// Make sure that the password and all tokens are checked so we are not vulnerable to timing attacks.
@@ -353,17 +404,21 @@ func (this *ServerUser) Auth(input string) (bool, error) {
 	if this.Password != nil {
 		tokenMatch, err := this.Password.Check(input, *this.Salt)
 		errs = errors.Join(errs, err)
-		match = match || tokenMatch
+		if tokenMatch && (match == nil) {
+			match = this.Password
+		}
 	}
 
 	for _, token := range this.Tokens {
 		tokenMatch, err := token.Check(input, *this.Salt)
 		errs = errors.Join(errs, err)
-		match = match || tokenMatch
+		if tokenMatch && (match == nil) {
+			match = token
+		}
 	}
 
 	if errs != nil {
-		return false, errs
+		return nil, errs
 	}
 
 	return match, nil
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/edulinq/autograder/internal/timestamp"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -1162,6 +1163,98 @@ func TestUserServerCreateRandomToken(test *testing.T) {
 	}
 }
 
+func TestUserServerCreateRandomTokenFull(test *testing.T) {
+	user := baseTestServerUser.Clone()
+
+	past := timestamp.Now() - 1
+	future := timestamp.Now() + 1000000
+
+	// An already expired token.
+	expiredCleartext, expiredToken := MustNewRandomToken(*user.Salt, TokenSourceServer, "expired")
+	expiredToken.ExpirationTime = &past
+	user.Tokens = append(user.Tokens, expiredToken)
+
+	scope := &TokenScope{ReadOnly: true, Courses: []string{"course101"}}
+
+	token, cleartext, err := user.CreateRandomTokenFull("scoped", TokenSourceUser, &future, scope)
+	if err != nil {
+		test.Fatalf("Failed to create random token: '%v'.", err)
+	}
+
+	if (token.ExpirationTime == nil) || (*token.ExpirationTime != future) {
+		test.Fatalf("Unexpected expiration time: '%s'.", token.ExpirationTime.SafeString())
+	}
+
+	if !reflect.DeepEqual(scope, token.Scope) {
+		test.Fatalf("Unexpected scope: '%s'.", util.MustToJSONIndent(token.Scope))
+	}
+
+	// The expired token should have been removed.
+	for _, userToken := range user.Tokens {
+		if userToken.ID == expiredToken.ID {
+			test.Fatalf("Expired token was not removed.")
+		}
+	}
+
+	match, err := user.AuthToken(util.Sha256HexFromString(cleartext))
+	if err != nil {
+		test.Fatalf("Failed to perform authentication: '%v'.", err)
+	}
+
+	if (match == nil) || (match.ID != token.ID) {
+		test.Fatalf("Did not match the new token.")
+	}
+
+	auth, err := user.Auth(util.Sha256HexFromString(expiredCleartext))
+	if err != nil {
+		test.Fatalf("Failed to perform authentication: '%v'.", err)
+	}
+
+	if auth {
+		test.Fatalf("Authenticated with an expired token.")
+	}
+
+	// Invalid scopes are not allowed.
+	_, _, err = user.CreateRandomTokenFull("bad", TokenSourceUser, nil, &TokenScope{ReadOnly: true, SubmitOnly: true})
+	if err == nil {
+		test.Fatalf("Did not get an error when creating a token with an invalid scope.")
+	}
+}
+
+func TestUserServerAuthExpiredToken(test *testing.T) {
+	past := timestamp.Now() - 1
+
+	cleartext, token := MustNewRandomToken(BASE_SALT, TokenSourceServer, "expired")
+	token.ExpirationTime = &past
+
+	user := setServerUserTokens(baseTestServerUser, []*Token{token})
+
+	// Auth() will not authenticate with an expired token.
+	auth, err := user.Auth(util.Sha256HexFromString(cleartext))
+	if err != nil {
+		test.Fatalf("Failed to perform authentication: '%v'.", err)
+	}
+
+	if auth {
+		test.Fatalf("Authenticated with an expired token.")
+	}
+
+	// AuthToken() will still return the expired token.
+	match, err := user.AuthToken(util.Sha256HexFromString(cleartext))
+	if err != nil {
+		test.Fatalf("Failed to perform authentication: '%v'.", err)
+	}
+
+	if (match == nil) || !match.IsExpired() {
+		test.Fatalf("Did not match the expired token.")
+	}
+
+	removed := user.RemoveExpiredTokens()
+	if (len(removed) != 1) || (len(user.Tokens) != 0) {
+		test.Fatalf("Expired token was not removed. Removed: %d, Remaining: %d.", len(removed), len(user.Tokens))
+	}
+}
+
 func setServerUserEmail(user *ServerUser, email string) *ServerUser {
 	newUser := *user
 	newUser.Email = email
@@ -0,0 +1,331 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/procedures/users"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// How long a user has to complete a login with the provider.
+const LOGIN_STATE_TTL_MSECS = 10 * 60 * 1000
+
+const SESSION_TOKEN_NAME = "sso"
+
+// Information about a login that has been started, but not finished.
+type loginState struct {
+	nonce        string
+	codeVerifier string
+	redirectURL  string
+	expiration   timestamp.Timestamp
+}
+
+// Pending logins keyed by state.
+var loginStates map[string]*loginState = make(map[string]*loginState)
+var loginStatesLock sync.Mutex
+
+type LoginResult struct {
+	Email string `json:"email"`
+
+	// The server user did not exist before this login and was created.
+	CreatedUser bool `json:"created-user"`
+
+	TokenInfo      *model.TokenInfo `json:"token-info"`
+	TokenCleartext string           `json:"token-cleartext"`
+}
+
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+}
+
+func IsEnabled() bool {
+	return config.OIDC_ISSUER.Get() != ""
+}
+
+// Start a new login and get the provider URL that the user should be sent to.
+// |redirectURL| is the callback URL that the provider will send the user back to,
+// and should match the URL registered with the provider.
+func StartLogin(redirectURL string) (string, error) {
+	if !IsEnabled() {
+		return "", fmt.Errorf("Single sign-on is not configured.")
+	}
+
+	provider, err := getProvider(config.OIDC_ISSUER.Get())
+	if err != nil {
+		return "", err
+	}
+
+	state, err := util.RandHex(model.DEFAULT_TOKEN_LEN)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate login state: '%w'.", err)
+	}
+
+	nonce, err := util.RandHex(model.DEFAULT_TOKEN_LEN)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate login nonce: '%w'.", err)
+	}
+
+	codeVerifier, err := util.RandHex(2 * model.DEFAULT_TOKEN_LEN)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate login code verifier: '%w'.", err)
+	}
+
+	storeLoginState(state, &loginState{
+		nonce:        nonce,
+		codeVerifier: codeVerifier,
+		redirectURL:  redirectURL,
+		expiration:   timestamp.Now() + LOGIN_STATE_TTL_MSECS,
+	})
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", config.OIDC_CLIENT_ID.Get())
+	query.Set("redirect_uri", redirectURL)
+	query.Set("scope", config.OIDC_SCOPES.Get())
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return provider.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Finish a login by exchanging the provider's code for an ID token,
+// provisioning a server user (if enabled and necessary),
+// and issuing the user a session token.
+// Returns: (result, user error, internal error).
+// User errors are safe to show to the user.
+func FinishLogin(state string, code string) (*LoginResult, error, error) {
+	if !IsEnabled() {
+		return nil, fmt.Errorf("Single sign-on is not configured."), nil
+	}
+
+	pending := popLoginState(state)
+	if pending == nil {
+		return nil, fmt.Errorf("Unknown or expired login. Please try logging in again."), nil
+	}
+
+	if code == "" {
+		return nil, fmt.Errorf("No authorization code was provided."), nil
+	}
+
+	provider, err := getProvider(config.OIDC_ISSUER.Get())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claims, err := exchangeCode(provider, code, pending)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	email, name, userErr := getClaimedUser(claims)
+	if userErr != nil {
+		return nil, userErr, nil
+	}
+
+	user, createdUser, userErr, err := getOrCreateUser(email, name)
+	if (userErr != nil) || (err != nil) {
+		return nil, userErr, err
+	}
+
+	expiration := timestamp.Now() + timestamp.FromMSecs(int64(config.OIDC_SESSION_SECS.Get())*1000)
+
+	token, cleartext, err := user.CreateRandomTokenFull(SESSION_TOKEN_NAME, model.TokenSourceSSO, &expiration, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to create session token for user '%s': '%w'.", email, err)
+	}
+
+	err = db.UpsertUser(user)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to save user '%s': '%w'.", email, err)
+	}
+
+	log.Info("Single sign-on login.", log.NewAttr("email", email), log.NewAttr("created-user", createdUser))
+
+	result := &LoginResult{
+		Email:          email,
+		CreatedUser:    createdUser,
+		TokenInfo:      &token.TokenInfo,
+		TokenCleartext: cleartext,
+	}
+
+	return result, nil, nil
+}
+
+func exchangeCode(provider *provider, code string, pending *loginState) (map[string]any, error) {
+	// The client secret is sent in the form (client_secret_post) instead of an auth header,
+	// so it will never be included in stored HTTP requests.
+	form := map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          code,
+		"redirect_uri":  pending.redirectURL,
+		"client_id":     config.OIDC_CLIENT_ID.Get(),
+		"client_secret": config.OIDC_CLIENT_SECRET.Get(),
+		"code_verifier": pending.codeVerifier,
+	}
+
+	body, err := util.Post(provider.TokenEndpoint, form)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to exchange authorization code: '%w'.", err)
+	}
+
+	var response tokenResponse
+	err = util.JSONFromString(body, &response)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse token response: '%w'.", err)
+	}
+
+	if response.IDToken == "" {
+		return nil, fmt.Errorf("Token response does not contain an ID token.")
+	}
+
+	return provider.verifyIDToken(response.IDToken, config.OIDC_CLIENT_ID.Get(), pending.nonce)
+}
+
+// Get the user's email and name from the ID token claims.
+// The returned error is a user error.
+func getClaimedUser(claims map[string]any) (string, string, error) {
+	emailClaim := config.OIDC_EMAIL_CLAIM.Get()
+
+	email, _ := claims[emailClaim].(string)
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", "", fmt.Errorf("Single sign-on provider did not supply an email ('%s' claim).", emailClaim)
+	}
+
+	if email == model.RootUserEmail {
+		return "", "", fmt.Errorf("Root is not allowed to authenticate.")
+	}
+
+	// Unless configured otherwise, the provider must tell us that the email has been verified
+	// (otherwise anyone with an account on the provider could claim any email).
+	if !isEmailVerified(claims) {
+		if config.OIDC_REQUIRE_VERIFIED.Get() {
+			return "", "", fmt.Errorf("Single sign-on provider has not verified email '%s'.", email)
+		}
+
+		// The provider explicitly said the email is not verified.
+		_, exists := claims["email_verified"]
+		if exists {
+			return "", "", fmt.Errorf("Single sign-on provider has not verified email '%s'.", email)
+		}
+	}
+
+	name, _ := claims[config.OIDC_NAME_CLAIM.Get()].(string)
+
+	return email, strings.TrimSpace(name), nil
+}
+
+// Check the 'email_verified' claim.
+// Some providers send the claim as a string instead of a bool.
+func isEmailVerified(claims map[string]any) bool {
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		return verified
+	case string:
+		return strings.EqualFold(verified, "true")
+	default:
+		return false
+	}
+}
+
+// Returns: (user, created user, user error, internal error).
+func getOrCreateUser(email string, name string) (*model.ServerUser, bool, error, error) {
+	user, err := db.GetServerUser(email)
+	if err != nil {
+		return nil, false, nil, fmt.Errorf("Failed to get user '%s': '%w'.", email, err)
+	}
+
+	if user != nil {
+		return user, false, nil, nil
+	}
+
+	if !config.OIDC_AUTO_PROVISION.Get() {
+		return nil, false, fmt.Errorf("User '%s' does not exist on this server.", email), nil
+	}
+
+	options := users.UpsertUsersOptions{
+		RawUsers: []*model.RawServerUserData{
+			&model.RawServerUserData{
+				Email: email,
+				Name:  name,
+				Role:  model.GetServerUserRoleString(model.ServerRoleUser),
+			},
+		},
+		SkipUpdates:       true,
+		ContextServerRole: model.ServerRoleRoot,
+	}
+
+	result := users.UpsertUser(options)
+	if result.ValidationError != nil {
+		return nil, false, fmt.Errorf("Could not create user '%s': '%s'.", email, result.ValidationError.ExternalMessage), nil
+	}
+
+	if result.HasErrors() {
+		return nil, false, nil, fmt.Errorf("Failed to create user '%s': '%s'.", email, util.MustToJSON(result))
+	}
+
+	user, err = db.GetServerUser(email)
+	if err != nil {
+		return nil, false, nil, fmt.Errorf("Failed to get created user '%s': '%w'.", email, err)
+	}
+
+	if user == nil {
+		return nil, false, nil, fmt.Errorf("Could not find created user '%s'.", email)
+	}
+
+	return user, true, nil, nil
+}
+
+func storeLoginState(state string, pending *loginState) {
+	loginStatesLock.Lock()
+	defer loginStatesLock.Unlock()
+
+	// Remove any abandoned logins.
+	now := timestamp.Now()
+	for key, value := range loginStates {
+		if value.expiration < now {
+			delete(loginStates, key)
+		}
+	}
+
+	loginStates[state] = pending
+}
+
+// Get and remove a pending login (states can only be used once).
+// Returns nil if the state is unknown or expired.
+func popLoginState(state string) *loginState {
+	loginStatesLock.Lock()
+	defer loginStatesLock.Unlock()
+
+	pending, exists := loginStates[state]
+	if !exists {
+		return nil
+	}
+
+	delete(loginStates, state)
+
+	if pending.expiration < timestamp.Now() {
+		return nil
+	}
+
+	return pending
+}
@@ -0,0 +1,198 @@
+package oidc
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestLoginBase(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	provider := mustStartTestProvider(test)
+	defer provider.Close()
+
+	defer provider.SetConfig()()
+
+	defer config.OIDC_AUTO_PROVISION.Set(config.OIDC_AUTO_PROVISION.Get())
+	defer config.OIDC_REQUIRE_VERIFIED.Set(config.OIDC_REQUIRE_VERIFIED.Get())
+
+	testCases := []struct {
+		claims          map[string]any
+		provision       bool
+		requireVerified bool
+		createdUser     bool
+		userErrorSub    string
+	}{
+		// Existing user.
+		{map[string]any{"email": "course-student@test.edulinq.org", "email_verified": true}, true, true, false, ""},
+		{map[string]any{"email": "course-student@test.edulinq.org", "email_verified": true}, false, true, false, ""},
+		{map[string]any{"email": "course-student@test.edulinq.org", "email_verified": "true"}, true, true, false, ""},
+		{map[string]any{"email": "course-student@test.edulinq.org"}, true, false, false, ""},
+
+		// New user.
+		{map[string]any{"email": "new-user@test.edulinq.org", "name": "New User", "email_verified": true}, true, true, true, ""},
+		{map[string]any{"email": "new-user@test.edulinq.org", "name": "New User", "email_verified": true}, false, true, false, "does not exist"},
+
+		// Errors.
+		{map[string]any{}, true, true, false, "did not supply an email"},
+		{map[string]any{"email": "course-student@test.edulinq.org"}, true, true, false, "has not verified email"},
+		{map[string]any{"email": "course-student@test.edulinq.org", "email_verified": false}, true, true, false, "has not verified email"},
+		{map[string]any{"email": "course-student@test.edulinq.org", "email_verified": "false"}, true, true, false, "has not verified email"},
+		{map[string]any{"email": "course-student@test.edulinq.org", "email_verified": false}, true, false, false, "has not verified email"},
+		{map[string]any{"email": "root", "email_verified": true}, true, true, false, "Root is not allowed"},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+
+		config.OIDC_AUTO_PROVISION.Set(testCase.provision)
+		config.OIDC_REQUIRE_VERIFIED.Set(testCase.requireVerified)
+		provider.SetClaims(testCase.claims)
+
+		result, userErr, err := login(test, provider)
+		if err != nil {
+			test.Errorf("Case %d: Got an unexpected internal error: '%v'.", i, err)
+			continue
+		}
+
+		if userErr != nil {
+			if testCase.userErrorSub == "" {
+				test.Errorf("Case %d: Got an unexpected user error: '%v'.", i, userErr)
+			} else if !strings.Contains(userErr.Error(), testCase.userErrorSub) {
+				test.Errorf("Case %d: Unexpected user error. Expected Substring: '%s', Actual: '%v'.", i, testCase.userErrorSub, userErr)
+			}
+
+			continue
+		}
+
+		if testCase.userErrorSub != "" {
+			test.Errorf("Case %d: Did not get an expected user error: '%s'.", i, testCase.userErrorSub)
+			continue
+		}
+
+		if result.CreatedUser != testCase.createdUser {
+			test.Errorf("Case %d: Unexpected user creation. Expected: %v, Actual: %v.", i, testCase.createdUser, result.CreatedUser)
+			continue
+		}
+
+		user, err := db.GetServerUser(result.Email)
+		if err != nil {
+			test.Errorf("Case %d: Failed to get user: '%v'.", i, err)
+			continue
+		}
+
+		if user == nil {
+			test.Errorf("Case %d: Could not find logged in user.", i)
+			continue
+		}
+
+		if testCase.createdUser && ((user.Name == nil) || (*user.Name != testCase.claims["name"])) {
+			test.Errorf("Case %d: Created user has the wrong name: '%s'.", i, util.MustToJSON(user))
+			continue
+		}
+
+		// The session token should work (and expire).
+		token, err := user.AuthToken(util.Sha256HexFromString(result.TokenCleartext))
+		if err != nil {
+			test.Errorf("Case %d: Failed to auth with session token: '%v'.", i, err)
+			continue
+		}
+
+		if (token == nil) || (token.Source != model.TokenSourceSSO) || (token.ExpirationTime == nil) {
+			test.Errorf("Case %d: Session token is not as expected: '%s'.", i, util.MustToJSON(token))
+			continue
+		}
+	}
+}
+
+func TestLoginBadState(test *testing.T) {
+	provider := mustStartTestProvider(test)
+	defer provider.Close()
+
+	defer provider.SetConfig()()
+
+	provider.SetClaims(map[string]any{"email": "course-student@test.edulinq.org", "email_verified": true})
+
+	authURL, err := StartLogin("http://localhost/callback")
+	if err != nil {
+		test.Fatalf("Failed to start login: '%v'.", err)
+	}
+
+	callbackURL, err := provider.Authorize(authURL)
+	if err != nil {
+		test.Fatalf("Failed to authorize: '%v'.", err)
+	}
+
+	query := mustParseQuery(test, callbackURL)
+
+	// Unknown state.
+	_, userErr, err := FinishLogin("ZZZ", query.Get("code"))
+	if (userErr == nil) || (err != nil) {
+		test.Fatalf("Did not get a user error for an unknown state: '%v', '%v'.", userErr, err)
+	}
+
+	// Bad code.
+	_, userErr, err = FinishLogin(query.Get("state"), "ZZZ")
+	if (userErr != nil) || (err == nil) {
+		test.Fatalf("Did not get an internal error for a bad code: '%v', '%v'.", userErr, err)
+	}
+
+	// States can only be used once.
+	_, userErr, err = FinishLogin(query.Get("state"), query.Get("code"))
+	if (userErr == nil) || (err != nil) {
+		test.Fatalf("Did not get a user error for a reused state: '%v', '%v'.", userErr, err)
+	}
+}
+
+func TestLoginNotConfigured(test *testing.T) {
+	_, err := StartLogin("http://localhost/callback")
+	if err == nil {
+		test.Fatalf("Did not get an error when starting a login without configuration.")
+	}
+
+	_, userErr, _ := FinishLogin("ZZZ", "ZZZ")
+	if userErr == nil {
+		test.Fatalf("Did not get an error when finishing a login without configuration.")
+	}
+}
+
+func login(test *testing.T, provider *TestProvider) (*LoginResult, error, error) {
+	authURL, err := StartLogin("http://localhost/callback")
+	if err != nil {
+		test.Fatalf("Failed to start login: '%v'.", err)
+	}
+
+	callbackURL, err := provider.Authorize(authURL)
+	if err != nil {
+		test.Fatalf("Failed to authorize: '%v'.", err)
+	}
+
+	query := mustParseQuery(test, callbackURL)
+
+	return FinishLogin(query.Get("state"), query.Get("code"))
+}
+
+func mustParseQuery(test *testing.T, rawURL string) url.Values {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		test.Fatalf("Failed to parse URL '%s': '%v'.", rawURL, err)
+	}
+
+	return parsed.Query()
+}
+
+func mustStartTestProvider(test *testing.T) *TestProvider {
+	provider, err := NewTestProvider()
+	if err != nil {
+		test.Fatalf("Failed to start test provider: '%v'.", err)
+	}
+
+	return provider
+}
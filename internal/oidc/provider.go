@@ -0,0 +1,87 @@
+package oidc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/edulinq/autograder/internal/jwt"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const DISCOVERY_PATH = "/.well-known/openid-configuration"
+
+// The parts of an OpenID Connect discovery document that we use.
+// See: https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata .
+type ProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type provider struct {
+	ProviderMetadata
+}
+
+// Providers are cached by issuer.
+var providers map[string]*provider = make(map[string]*provider)
+var providersLock sync.Mutex
+
+// Get the (possibly cached) provider for an issuer.
+func getProvider(issuer string) (*provider, error) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+
+	result, exists := providers[issuer]
+	if exists {
+		return result, nil
+	}
+
+	result, err := discoverProvider(issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	providers[issuer] = result
+
+	return result, nil
+}
+
+func discoverProvider(issuer string) (*provider, error) {
+	uri := strings.TrimSuffix(issuer, "/") + DISCOVERY_PATH
+
+	body, err := util.Get(uri)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch OpenID Connect discovery document for issuer '%s': '%w'.", issuer, err)
+	}
+
+	var metadata ProviderMetadata
+	err = util.JSONFromString(body, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse OpenID Connect discovery document for issuer '%s': '%w'.", issuer, err)
+	}
+
+	if metadata.Issuer != issuer {
+		return nil, fmt.Errorf("OpenID Connect discovery document has the wrong issuer. Expected: '%s', Actual: '%s'.", issuer, metadata.Issuer)
+	}
+
+	if (metadata.AuthorizationEndpoint == "") || (metadata.TokenEndpoint == "") || (metadata.JWKSURI == "") {
+		return nil, fmt.Errorf("OpenID Connect discovery document for issuer '%s' is missing required endpoints.", issuer)
+	}
+
+	result := &provider{
+		ProviderMetadata: metadata,
+	}
+
+	return result, nil
+}
+
+// Clear all cached providers (and their keys).
+func clearProviders() {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+
+	providers = make(map[string]*provider)
+	jwt.ClearKeyCache()
+}
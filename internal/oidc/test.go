@@ -0,0 +1,249 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/jwt"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	TEST_CLIENT_ID     = "autograder-test"
+	TEST_CLIENT_SECRET = "autograder-test-secret"
+	TEST_KEY_ID        = "test-key"
+)
+
+// A minimal local OpenID Connect provider for testing.
+// It implements discovery, signing keys, authorization (which immediately "logs in" the user), and code exchange.
+type TestProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	lock sync.Mutex
+
+	// Claims to include in ID tokens (in addition to the standard claims).
+	claims map[string]any
+
+	// Pending authorization codes.
+	codes map[string]*testCode
+}
+
+type testCode struct {
+	clientID      string
+	redirectURL   string
+	nonce         string
+	codeChallenge string
+	claims        map[string]any
+}
+
+func NewTestProvider() (*TestProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate test signing key: '%w'.", err)
+	}
+
+	provider := &TestProvider{
+		key:    key,
+		claims: make(map[string]any),
+		codes:  make(map[string]*testCode),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(DISCOVERY_PATH, provider.handleDiscovery)
+	mux.HandleFunc("/jwks", provider.handleKeys)
+	mux.HandleFunc("/authorize", provider.handleAuthorize)
+	mux.HandleFunc("/token", provider.handleToken)
+
+	provider.server = httptest.NewServer(mux)
+
+	return provider, nil
+}
+
+func (this *TestProvider) Issuer() string {
+	return this.server.URL
+}
+
+func (this *TestProvider) Close() {
+	this.server.Close()
+	clearProviders()
+}
+
+// Set the claims that will be used for the next logins, e.g., {"email": "alice@test.edulinq.org", "name": "Alice"}.
+func (this *TestProvider) SetClaims(claims map[string]any) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.claims = claims
+}
+
+// Set the config options to use this provider.
+// Returns a function that will reset the options.
+func (this *TestProvider) SetConfig() func() {
+	options := map[*config.StringOption]string{
+		config.OIDC_ISSUER:        this.Issuer(),
+		config.OIDC_CLIENT_ID:     TEST_CLIENT_ID,
+		config.OIDC_CLIENT_SECRET: TEST_CLIENT_SECRET,
+	}
+
+	oldValues := make(map[*config.StringOption]string, len(options))
+	for option, value := range options {
+		oldValues[option] = option.Get()
+		option.Set(value)
+	}
+
+	clearProviders()
+
+	return func() {
+		for option, value := range oldValues {
+			option.Set(value)
+		}
+
+		clearProviders()
+	}
+}
+
+// Act as a user's browser and visit the provider's authorization URL.
+// Returns the callback URL (including the code and state) that the provider redirects to.
+func (this *TestProvider) Authorize(authURL string) (string, error) {
+	client := http.Client{
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	response, err := client.Get(authURL)
+	if err != nil {
+		return "", fmt.Errorf("Failed to visit authorization URL: '%w'.", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusFound {
+		return "", fmt.Errorf("Authorization did not redirect, got status %d.", response.StatusCode)
+	}
+
+	return response.Header.Get("Location"), nil
+}
+
+func (this *TestProvider) handleDiscovery(response http.ResponseWriter, request *http.Request) {
+	metadata := ProviderMetadata{
+		Issuer:                this.Issuer(),
+		AuthorizationEndpoint: this.Issuer() + "/authorize",
+		TokenEndpoint:         this.Issuer() + "/token",
+		JWKSURI:               this.Issuer() + "/jwks",
+	}
+
+	writeTestJSON(response, http.StatusOK, metadata)
+}
+
+func (this *TestProvider) handleKeys(response http.ResponseWriter, request *http.Request) {
+	keySet := jwt.JSONWebKeySet{
+		Keys: []*jwt.JSONWebKey{
+			jwt.NewJSONWebKey(&this.key.PublicKey, TEST_KEY_ID),
+		},
+	}
+
+	writeTestJSON(response, http.StatusOK, keySet)
+}
+
+func (this *TestProvider) handleAuthorize(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+
+	if (query.Get("response_type") != "code") || (query.Get("code_challenge_method") != "S256") {
+		http.Error(response, "Unsupported request.", http.StatusBadRequest)
+		return
+	}
+
+	code := util.UUID()
+
+	this.lock.Lock()
+	this.codes[code] = &testCode{
+		clientID:      query.Get("client_id"),
+		redirectURL:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		claims:        this.claims,
+	}
+	this.lock.Unlock()
+
+	callback := url.Values{}
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+
+	http.Redirect(response, request, query.Get("redirect_uri")+"?"+callback.Encode(), http.StatusFound)
+}
+
+func (this *TestProvider) handleToken(response http.ResponseWriter, request *http.Request) {
+	err := request.ParseForm()
+	if err != nil {
+		writeTestJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	this.lock.Lock()
+	code, exists := this.codes[request.PostForm.Get("code")]
+	delete(this.codes, request.PostForm.Get("code"))
+	this.lock.Unlock()
+
+	if !exists {
+		writeTestJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	if (request.PostForm.Get("client_id") != code.clientID) || (request.PostForm.Get("client_secret") != TEST_CLIENT_SECRET) {
+		writeTestJSON(response, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	challenge := sha256.Sum256([]byte(request.PostForm.Get("code_verifier")))
+	if (request.PostForm.Get("redirect_uri") != code.redirectURL) ||
+		(base64.RawURLEncoding.EncodeToString(challenge[:]) != code.codeChallenge) {
+		writeTestJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := jwt.NowSecs()
+
+	claims := map[string]any{
+		"iss":   this.Issuer(),
+		"sub":   util.UUID(),
+		"aud":   code.clientID,
+		"exp":   now + 300,
+		"iat":   now,
+		"nonce": code.nonce,
+	}
+
+	for key, value := range code.claims {
+		claims[key] = value
+	}
+
+	idToken, err := this.sign(claims)
+	if err != nil {
+		writeTestJSON(response, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeTestJSON(response, http.StatusOK, map[string]string{
+		"access_token": util.UUID(),
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+func (this *TestProvider) sign(claims map[string]any) (string, error) {
+	return jwt.Sign(claims, this.key, TEST_KEY_ID)
+}
+
+func writeTestJSON(response http.ResponseWriter, status int, value any) {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	response.Write([]byte(util.MustToJSON(value)))
+}
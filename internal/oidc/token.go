@@ -0,0 +1,21 @@
+package oidc
+
+import (
+	"github.com/edulinq/autograder/internal/jwt"
+)
+
+// Verify an ID token and return its claims.
+// See: https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation .
+func (this *provider) verifyIDToken(rawToken string, clientID string, nonce string) (map[string]any, error) {
+	claims, err := jwt.VerifyWithKeySetURL(rawToken, this.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+
+	err = jwt.CheckClaims(claims, this.Issuer, clientID, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,98 @@
+package oidc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/jwt"
+)
+
+func TestVerifyIDToken(test *testing.T) {
+	testProvider := mustStartTestProvider(test)
+	defer testProvider.Close()
+
+	provider, err := getProvider(testProvider.Issuer())
+	if err != nil {
+		test.Fatalf("Failed to get provider: '%v'.", err)
+	}
+
+	now := jwt.NowSecs()
+
+	baseClaims := func() map[string]any {
+		return map[string]any{
+			"iss":   testProvider.Issuer(),
+			"aud":   TEST_CLIENT_ID,
+			"exp":   now + 300,
+			"iat":   now,
+			"nonce": "abc",
+			"email": "alice@test.edulinq.org",
+		}
+	}
+
+	testCases := []struct {
+		change      func(map[string]any)
+		tamper      bool
+		errorSubstr string
+	}{
+		{nil, false, ""},
+		{func(claims map[string]any) { claims["aud"] = []any{"other", TEST_CLIENT_ID} }, false, ""},
+		{func(claims map[string]any) { claims["exp"] = now - 30 }, false, ""},
+
+		{func(claims map[string]any) { claims["iss"] = "ZZZ" }, false, "wrong issuer"},
+		{func(claims map[string]any) { claims["aud"] = "ZZZ" }, false, "not issued for this audience"},
+		{func(claims map[string]any) { claims["exp"] = now - 300 }, false, "expired"},
+		{func(claims map[string]any) { delete(claims, "exp") }, false, "expiration"},
+		{func(claims map[string]any) { claims["iat"] = now + 300 }, false, "future"},
+		{func(claims map[string]any) { claims["nonce"] = "ZZZ" }, false, "nonce"},
+		{nil, true, "signature is not valid"},
+	}
+
+	for i, testCase := range testCases {
+		claims := baseClaims()
+		if testCase.change != nil {
+			testCase.change(claims)
+		}
+
+		token, err := testProvider.sign(claims)
+		if err != nil {
+			test.Errorf("Case %d: Failed to sign token: '%v'.", i, err)
+			continue
+		}
+
+		if testCase.tamper {
+			parts := strings.Split(token, ".")
+			otherClaims := baseClaims()
+			otherClaims["email"] = "mallory@test.edulinq.org"
+
+			otherToken, err := testProvider.sign(otherClaims)
+			if err != nil {
+				test.Errorf("Case %d: Failed to sign other token: '%v'.", i, err)
+				continue
+			}
+
+			parts[1] = strings.Split(otherToken, ".")[1]
+			token = strings.Join(parts, ".")
+		}
+
+		actualClaims, err := provider.verifyIDToken(token, TEST_CLIENT_ID, "abc")
+		if err != nil {
+			if testCase.errorSubstr == "" {
+				test.Errorf("Case %d: Got an unexpected error: '%v'.", i, err)
+			} else if !strings.Contains(err.Error(), testCase.errorSubstr) {
+				test.Errorf("Case %d: Unexpected error. Expected Substring: '%s', Actual: '%v'.", i, testCase.errorSubstr, err)
+			}
+
+			continue
+		}
+
+		if testCase.errorSubstr != "" {
+			test.Errorf("Case %d: Did not get an expected error: '%s'.", i, testCase.errorSubstr)
+			continue
+		}
+
+		if actualClaims["email"] != "alice@test.edulinq.org" {
+			test.Errorf("Case %d: Unexpected claims: '%v'.", i, actualClaims)
+			continue
+		}
+	}
+}
@@ -3,6 +3,8 @@ package backup
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/edulinq/autograder/internal/config"
 	"github.com/edulinq/autograder/internal/db"
@@ -11,6 +13,9 @@ import (
 	"github.com/edulinq/autograder/internal/util"
 )
 
+// The file (at the root of a course backup) that holds the course's users.
+const USERS_FILENAME = "users.json"
+
 func BackupCourse(courseID string) error {
 	course, err := db.GetCourse(courseID)
 	if err != nil {
@@ -24,42 +29,207 @@ func BackupCourse(courseID string) error {
 	return BackupCourseFull(course, "", "")
 }
 
+type BackupOptions struct {
+	// The directory to write the backup to.
+	// Defaults to config.GetBackupDir().
+	Dest string `json:"-"`
+
+	// The ID to name the backup with.
+	// Defaults to the current time.
+	BackupID string `json:"-"`
+
+	// Only store the submission files that changed since the course's most recent backup.
+	Incremental bool `json:"incremental"`
+
+	// Make a full backup once this many incremental backups have been made in a row.
+	// Zero means that there is no limit.
+	FullEvery int `json:"full-every"`
+
+	// Check the new backup against its manifest after it is made.
+	Verify bool `json:"verify"`
+
+	RetentionPolicy
+}
+
+type BackupResult struct {
+	Path     string          `json:"path"`
+	Manifest *BackupManifest `json:"manifest"`
+
+	// The names of old backups that were removed by the retention policy.
+	RemovedBackups []string `json:"removed-backups"`
+
+	// Only set if verification was requested.
+	Verification *VerifyResult `json:"verification,omitempty"`
+}
+
 func BackupCourseFull(course *model.Course, dest string, backupID string) error {
+	_, err := BackupCourseWithOptions(course, BackupOptions{Dest: dest, BackupID: backupID})
+	return err
+}
+
+func BackupCourseWithOptions(course *model.Course, options BackupOptions) (*BackupResult, error) {
+	dest := options.Dest
 	if dest == "" {
 		dest = config.GetBackupDir()
 	}
 
 	if util.IsFile(dest) {
-		return fmt.Errorf("Backup directory exists and is a file: '%s'.", dest)
+		return nil, fmt.Errorf("Backup directory exists and is a file: '%s'.", dest)
 	}
 
 	err := util.MkDir(dest)
 	if err != nil {
-		return fmt.Errorf("Could not create dest dir '%s': '%w'.", dest, err)
+		return nil, fmt.Errorf("Could not create dest dir '%s': '%w'.", dest, err)
 	}
 
 	baseTempDir, err := util.MkDirTemp("autograder-backup-course-")
 	if err != nil {
-		return fmt.Errorf("Could not create temp backup dir: '%w'.", err)
+		return nil, fmt.Errorf("Could not create temp backup dir: '%w'.", err)
 	}
 	defer util.RemoveDirent(baseTempDir)
 
-	baseFilename, targetPath := getBackupPath(dest, course.GetID(), backupID)
+	baseFilename, targetPath := getBackupPath(dest, course.GetID(), options.BackupID)
 
 	tempDir := filepath.Join(baseTempDir, baseFilename)
 	err = db.DumpCourse(course, tempDir)
 	if err != nil {
-		return fmt.Errorf("Failed to dump course: '%w'.", err)
+		return nil, fmt.Errorf("Failed to dump course: '%w'.", err)
+	}
+
+	err = dumpCourseUsers(course, tempDir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to dump course users: '%w'.", err)
+	}
+
+	files, err := computeManifestFiles(tempDir, baseFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &BackupManifest{
+		CourseID: course.GetID(),
+		Name:     baseFilename,
+		Files:    files,
+	}
+
+	if options.Incremental {
+		err = makeIncremental(tempDir, manifest, dest, options.FullEvery)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to make incremental backup: '%w'.", err)
+		}
+	}
+
+	err = util.ToJSONFileIndent(manifest, filepath.Join(tempDir, MANIFEST_FILENAME))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to write backup manifest: '%w'.", err)
 	}
 
 	err = util.Zip(tempDir, targetPath, true)
 	if err != nil {
-		return fmt.Errorf("Failed to zip dumpped course dir '%s' into '%s': '%w'.", tempDir, targetPath, err)
+		return nil, fmt.Errorf("Failed to zip dumpped course dir '%s' into '%s': '%w'.", tempDir, targetPath, err)
+	}
+
+	result := &BackupResult{
+		Path:           targetPath,
+		Manifest:       manifest,
+		RemovedBackups: make([]string, 0),
+	}
+
+	if !options.RetentionPolicy.IsEmpty() {
+		result.RemovedBackups, err = ApplyRetention(dest, course.GetID(), options.RetentionPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to apply backup retention policy: '%w'.", err)
+		}
+	}
+
+	if options.Verify {
+		result.Verification, err = VerifyBackup(targetPath)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to verify backup: '%w'.", err)
+		}
+	}
+
+	return result, nil
+}
+
+// Turn a (full) backup into an incremental backup based on the course's most recent backup.
+// Submission files that are unchanged from the previous backup are removed from the backup dir,
+// and their manifest entries will point to the backup that holds their contents.
+// If there is no previous backup (or the chain of incremental backups is too long), the backup is left as a full backup.
+func makeIncremental(baseDir string, manifest *BackupManifest, dest string, fullEvery int) error {
+	backups, err := getBackups(dest, manifest.CourseID)
+	if err != nil {
+		return err
+	}
+
+	if len(backups) == 0 {
+		return nil
+	}
+
+	previous := backups[0].manifest
+	if (fullEvery > 0) && (previous.ChainLength >= fullEvery) {
+		return nil
+	}
+
+	prefix := model.SUBMISSIONS_DIRNAME + "/"
+
+	for relpath, entry := range manifest.Files {
+		if !strings.HasPrefix(relpath, prefix) {
+			continue
+		}
+
+		previousEntry := previous.Files[relpath]
+		if (previousEntry == nil) || (previousEntry.SHA256 != entry.SHA256) {
+			continue
+		}
+
+		err = util.RemoveDirent(filepath.Join(baseDir, filepath.FromSlash(relpath)))
+		if err != nil {
+			return fmt.Errorf("Failed to remove unchanged file '%s': '%w'.", relpath, err)
+		}
+
+		entry.Backup = previousEntry.Backup
 	}
 
+	manifest.Incremental = true
+	manifest.ChainLength = previous.ChainLength + 1
+
 	return nil
 }
 
+// Users are not a part of a course's dump (since they are server-level),
+// so write out the course-level information for each enrolled user.
+func dumpCourseUsers(course *model.Course, targetDir string) error {
+	courseUsers, err := db.GetCourseUsers(course)
+	if err != nil {
+		return err
+	}
+
+	rawUsers := make([]*model.RawCourseUserData, 0, len(courseUsers))
+	for _, courseUser := range courseUsers {
+		rawUser := &model.RawCourseUserData{
+			Email:      courseUser.Email,
+			CourseRole: courseUser.Role.String(),
+		}
+
+		if courseUser.Name != nil {
+			rawUser.Name = *courseUser.Name
+		}
+
+		if courseUser.LMSID != nil {
+			rawUser.CourseLMSID = *courseUser.LMSID
+		}
+
+		rawUsers = append(rawUsers, rawUser)
+	}
+
+	slices.SortFunc(rawUsers, func(a *model.RawCourseUserData, b *model.RawCourseUserData) int {
+		return strings.Compare(a.Email, b.Email)
+	})
+
+	return util.ToJSONFileIndent(rawUsers, filepath.Join(targetDir, USERS_FILENAME))
+}
+
 func getBackupPath(dest string, basename string, backupID string) (string, string) {
 	if backupID == "" {
 		backupID = fmt.Sprintf("%d", timestamp.Now().ToMSecs())
@@ -71,7 +241,7 @@ func getBackupPath(dest string, basename string, backupID string) (string, strin
 
 	for (targetPath == "") || (util.PathExists(targetPath)) {
 		offsetCount++
-		baseFilename = fmt.Sprintf("%s-%s-%d.zip", basename, backupID, offsetCount)
+		baseFilename = fmt.Sprintf("%s-%s-%d", basename, backupID, offsetCount)
 		targetPath = filepath.Join(dest, baseFilename+".zip")
 	}
 
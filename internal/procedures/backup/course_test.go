@@ -2,15 +2,18 @@ package backup
 
 import (
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/edulinq/autograder/internal/config"
 	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
 	"github.com/edulinq/autograder/internal/util"
 )
 
 // This hash is expected to change when the test data for course101 is changed.
-const EXPECTED_MD5 = "653312f61f18834cc1c9a4ce707d28a2"
+const EXPECTED_MD5 = "fe79d8e2babc338cb48588767b234d02"
 
 func TestBackupTempDir(test *testing.T) {
 	tempDir, err := util.MkDirTemp("autograder-test-course-backup-")
@@ -64,3 +67,119 @@ func doBackup(test *testing.T, dest string, expectedPath string) {
 		test.Fatalf("MD5s do not match. Expected: '%s', Actual: '%s'.", EXPECTED_MD5, actualMD5)
 	}
 }
+
+func TestBackupIncremental(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	tempDir := util.MustMkDirTemp("autograder-test-course-backup-incremental-")
+	defer util.RemoveDirent(tempDir)
+
+	course := db.MustGetTestCourse()
+	assignment := course.GetAssignment("hw0")
+	email := "course-student@test.edulinq.org"
+
+	users, err := db.GetCourseUsers(course)
+	if err != nil {
+		test.Fatalf("Failed to get course users: '%v'.", err)
+	}
+
+	options := BackupOptions{
+		Dest:        tempDir,
+		BackupID:    "1",
+		Incremental: true,
+		FullEvery:   1,
+	}
+
+	// There is no previous backup, so the first backup is full.
+	result, err := BackupCourseWithOptions(course, options)
+	if err != nil {
+		test.Fatalf("Failed to make first backup: '%v'.", err)
+	}
+
+	if result.Manifest.Incremental || (len(result.Manifest.GetDependencies()) != 0) {
+		test.Fatalf("First backup is not a full backup: '%s'.", util.MustToJSONIndent(result.Manifest))
+	}
+
+	// Change a single submission.
+	manualGrade := &model.ManualGrade{
+		ShortID:      "1697406265",
+		CourseID:     course.GetID(),
+		AssignmentID: assignment.GetID(),
+		User:         email,
+		Grader:       "course-grader@test.edulinq.org",
+		Selections:   []string{"style"},
+	}
+
+	err = manualGrade.Validate(assignment.Rubric)
+	if err != nil {
+		test.Fatalf("Failed to validate manual grade: '%v'.", err)
+	}
+
+	err = db.SaveManualGrade(manualGrade)
+	if err != nil {
+		test.Fatalf("Failed to save manual grade: '%v'.", err)
+	}
+
+	options.BackupID = "2"
+
+	result, err = BackupCourseWithOptions(course, options)
+	if err != nil {
+		test.Fatalf("Failed to make incremental backup: '%v'.", err)
+	}
+
+	manifest := result.Manifest
+
+	if !manifest.Incremental || (manifest.ChainLength != 1) {
+		test.Fatalf("Second backup is not incremental: '%s'.", util.MustToJSONIndent(manifest))
+	}
+
+	if !reflect.DeepEqual([]string{"course101-1"}, manifest.GetDependencies()) {
+		test.Fatalf("Unexpected dependencies: '%v'.", manifest.GetDependencies())
+	}
+
+	// Only the new manual grade is a changed submission file.
+	for relpath, entry := range manifest.Files {
+		stored := (entry.Backup == manifest.Name)
+		isSubmission := strings.HasPrefix(relpath, model.SUBMISSIONS_DIRNAME+"/")
+		isManualGrade := strings.HasSuffix(relpath, model.MANUAL_GRADE_FILENAME)
+
+		if stored != (!isSubmission || isManualGrade) {
+			test.Fatalf("Unexpected storage for '%s': '%s'.", relpath, util.MustToJSONIndent(entry))
+		}
+	}
+
+	verification, err := VerifyBackup(result.Path)
+	if err != nil {
+		test.Fatalf("Failed to verify incremental backup: '%v'.", err)
+	}
+
+	if !verification.Valid {
+		test.Fatalf("Incremental backup is not valid: '%s'.", util.MustToJSONIndent(verification))
+	}
+
+	// An incremental backup restores like a full one.
+	restoreOptions := RestoreOptions{
+		NewCourseID: "restored",
+		ContextUser: db.MustGetRoot(),
+	}
+
+	restoreResult, userErr, err := RestoreCourse(result.Path, restoreOptions)
+	checkRestoreResult(test, "incremental", restoreResult, userErr, err, len(users), 3, 0)
+
+	if restoreResult.RestoredManualGrades != 1 {
+		test.Fatalf("Unexpected number of restored manual grades: %d.", restoreResult.RestoredManualGrades)
+	}
+
+	// The chain has reached its max length, so the next backup is full.
+	options.BackupID = "3"
+
+	result, err = BackupCourseWithOptions(course, options)
+	if err != nil {
+		test.Fatalf("Failed to make third backup: '%v'.", err)
+	}
+
+	if result.Manifest.Incremental || (len(result.Manifest.GetDependencies()) != 0) {
+		test.Fatalf("Third backup is not a full backup: '%s'.", util.MustToJSONIndent(result.Manifest))
+	}
+}
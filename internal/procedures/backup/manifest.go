@@ -0,0 +1,299 @@
+package backup
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// The file (at the root of a course backup) that describes the backup's contents.
+const MANIFEST_FILENAME = "manifest.json"
+
+// Backup names are used as filenames, so they cannot have any path components.
+var backupNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\._\-]*$`)
+
+type BackupManifest struct {
+	CourseID string `json:"course-id"`
+
+	// The name of this backup (the backup's filename without the extension).
+	Name string `json:"name"`
+
+	// Incremental backups only store the submission files that changed since the previous backup.
+	// The contents of all other files are stored in the backup listed in their entry.
+	Incremental bool `json:"incremental"`
+
+	// The number of incremental backups made since the last full backup (zero for a full backup).
+	ChainLength int `json:"chain-length"`
+
+	// All the files in the course backup (keyed by their slash-separated path relative to the backup root).
+	// The manifest itself is not included.
+	Files map[string]*ManifestEntry `json:"files"`
+}
+
+type ManifestEntry struct {
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+
+	// The name of the backup that stores this file's contents.
+	Backup string `json:"backup"`
+}
+
+// Ensure that the manifest cannot reference anything outside of its backups.
+// Manifests may come from uploaded backups, so every file path must stay within the backup
+// and every backup name must be a plain name (which will be resolved within a backup dir).
+func (this *BackupManifest) Validate() error {
+	if this == nil {
+		return fmt.Errorf("Backup manifest is nil.")
+	}
+
+	if !backupNameRegex.MatchString(this.Name) {
+		return fmt.Errorf("Backup manifest has an invalid name: '%s'.", this.Name)
+	}
+
+	for relpath, entry := range this.Files {
+		if !isLocalPath(relpath) {
+			return fmt.Errorf("Backup manifest has a file path outside of the backup: '%s'.", relpath)
+		}
+
+		if entry == nil {
+			return fmt.Errorf("Backup manifest has a nil entry for file '%s'.", relpath)
+		}
+
+		if !backupNameRegex.MatchString(entry.Backup) {
+			return fmt.Errorf("Backup manifest has an invalid backup name for file '%s': '%s'.", relpath, entry.Backup)
+		}
+	}
+
+	return nil
+}
+
+// The names of all the other backups that this backup needs to be complete.
+func (this *BackupManifest) GetDependencies() []string {
+	dependencies := make([]string, 0)
+
+	for _, entry := range this.Files {
+		if (entry.Backup == this.Name) || slices.Contains(dependencies, entry.Backup) {
+			continue
+		}
+
+		dependencies = append(dependencies, entry.Backup)
+	}
+
+	slices.Sort(dependencies)
+
+	return dependencies
+}
+
+// Compute manifest entries for every file in a dir.
+// All entries will point to the given backup.
+func computeManifestFiles(baseDir string, backupName string) (map[string]*ManifestEntry, error) {
+	files := make(map[string]*ManifestEntry)
+
+	err := filepath.WalkDir(baseDir, func(path string, dirent fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if dirent.IsDir() {
+			return nil
+		}
+
+		relpath, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return fmt.Errorf("Could not compute relative path for '%s': '%w'.", path, err)
+		}
+
+		info, err := dirent.Info()
+		if err != nil {
+			return fmt.Errorf("Could not stat '%s': '%w'.", path, err)
+		}
+
+		hash, err := util.Sha256FileHex(path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(relpath)] = &ManifestEntry{
+			SHA256: hash,
+			Size:   info.Size(),
+			Backup: backupName,
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to compute manifest for '%s': '%w'.", baseDir, err)
+	}
+
+	return files, nil
+}
+
+// Read the manifest from a backup zip file.
+// Returns nil if the backup does not have a manifest (e.g., it was made before manifests existed).
+func ReadManifest(zipPath string) (*BackupManifest, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open backup '%s': '%w'.", zipPath, err)
+	}
+	defer reader.Close()
+
+	backupName := strings.TrimSuffix(filepath.Base(zipPath), ".zip")
+
+	file := findZipFile(&reader.Reader, backupName, MANIFEST_FILENAME)
+	if file == nil {
+		return nil, nil
+	}
+
+	manifestReader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("Could not open manifest in backup '%s': '%w'.", zipPath, err)
+	}
+	defer manifestReader.Close()
+
+	data, err := io.ReadAll(manifestReader)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read manifest in backup '%s': '%w'.", zipPath, err)
+	}
+
+	var manifest BackupManifest
+	err = util.JSONFromBytes(data, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read manifest in backup '%s': '%w'.", zipPath, err)
+	}
+
+	err = manifest.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid manifest in backup '%s': '%w'.", zipPath, err)
+	}
+
+	return &manifest, nil
+}
+
+// Find a file within a backup zip.
+// Backup zips place all their contents in a top-level directory named after the backup.
+func findZipFile(reader *zip.Reader, backupName string, relpath string) *zip.File {
+	name := path.Join(backupName, relpath)
+
+	for _, file := range reader.File {
+		if file.Name == name {
+			return file
+		}
+	}
+
+	return nil
+}
+
+// Extract any files that an incremental backup stores in other backups into the given (unzipped) backup dir.
+// The other backups are expected to be in |backupsDir|.
+// Extracted files are checked against the manifest.
+func fillIncrementalBackup(baseDir string, backupsDir string) error {
+	manifestPath := filepath.Join(baseDir, MANIFEST_FILENAME)
+	if !util.IsFile(manifestPath) {
+		return nil
+	}
+
+	var manifest BackupManifest
+	err := util.JSONFromFile(manifestPath, &manifest)
+	if err != nil {
+		return fmt.Errorf("Failed to read backup manifest: '%w'.", err)
+	}
+
+	err = manifest.Validate()
+	if err != nil {
+		return fmt.Errorf("Invalid backup manifest: '%w'.", err)
+	}
+
+	readers := make(map[string]*zip.ReadCloser)
+	defer func() {
+		for _, reader := range readers {
+			reader.Close()
+		}
+	}()
+
+	for relpath, entry := range manifest.Files {
+		if entry.Backup == manifest.Name {
+			continue
+		}
+
+		reader, exists := readers[entry.Backup]
+		if !exists {
+			zipPath := filepath.Join(backupsDir, entry.Backup+".zip")
+			if !util.IsFile(zipPath) {
+				return fmt.Errorf("Incremental backup '%s' requires backup '%s', which could not be found in '%s'.", manifest.Name, entry.Backup, backupsDir)
+			}
+
+			reader, err = zip.OpenReader(zipPath)
+			if err != nil {
+				return fmt.Errorf("Could not open backup '%s': '%w'.", zipPath, err)
+			}
+
+			readers[entry.Backup] = reader
+		}
+
+		file := findZipFile(&reader.Reader, entry.Backup, relpath)
+		if file == nil {
+			return fmt.Errorf("Backup '%s' is missing file '%s'.", entry.Backup, relpath)
+		}
+
+		err = extractZipFile(file, filepath.Join(baseDir, filepath.FromSlash(relpath)), entry.SHA256)
+		if err != nil {
+			return fmt.Errorf("Failed to extract '%s' from backup '%s': '%w'.", relpath, entry.Backup, err)
+		}
+	}
+
+	return nil
+}
+
+func extractZipFile(file *zip.File, outPath string, expectedHash string) error {
+	err := util.MkDir(filepath.Dir(outPath))
+	if err != nil {
+		return err
+	}
+
+	inFile, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer inFile.Close()
+
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+
+	_, err = io.Copy(outFile, inFile)
+	if err != nil {
+		return err
+	}
+
+	hash, err := util.Sha256FileHex(outPath)
+	if err != nil {
+		return err
+	}
+
+	if hash != expectedHash {
+		return fmt.Errorf("Checksum does not match. Expected: '%s', Actual: '%s'.", expectedHash, hash)
+	}
+
+	return nil
+}
+
+// Check that a slash-separated relative path is clean and stays within its base dir.
+func isLocalPath(relpath string) bool {
+	if (relpath == "") || (path.Clean(relpath) != relpath) || strings.Contains(relpath, "\\") {
+		return false
+	}
+
+	return filepath.IsLocal(filepath.FromSlash(relpath))
+}
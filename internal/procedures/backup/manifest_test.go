@@ -0,0 +1,69 @@
+package backup
+
+import (
+	"testing"
+)
+
+func TestBackupManifestValidate(test *testing.T) {
+	testCases := []struct {
+		name     string
+		relpath  string
+		backup   string
+		hasError bool
+	}{
+		{"course101-2", "course.json", "course101-2", false},
+		{"course101-2", "submissions/hw0/a@test.edulinq.org/1/submission-result.json", "course101-1", false},
+		{"course101-2", "a..b", "course101-1.old", false},
+
+		// Bad names.
+		{"", "course.json", "course101-2", true},
+		{"../course101-2", "course.json", "course101-2", true},
+		{".course101-2", "course.json", "course101-2", true},
+
+		// Bad paths.
+		{"course101-2", "", "course101-2", true},
+		{"course101-2", "../course.json", "course101-2", true},
+		{"course101-2", "submissions/../../course.json", "course101-2", true},
+		{"course101-2", "/etc/passwd", "course101-2", true},
+		{"course101-2", "./course.json", "course101-2", true},
+		{"course101-2", "submissions//course.json", "course101-2", true},
+		{"course101-2", "..\\course.json", "course101-2", true},
+
+		// Bad backups.
+		{"course101-2", "course.json", "", true},
+		{"course101-2", "course.json", "..", true},
+		{"course101-2", "course.json", "../../tmp/other", true},
+		{"course101-2", "course.json", "/tmp/other", true},
+	}
+
+	for i, testCase := range testCases {
+		manifest := &BackupManifest{
+			Name: testCase.name,
+			Files: map[string]*ManifestEntry{
+				testCase.relpath: &ManifestEntry{
+					Backup: testCase.backup,
+				},
+			},
+		}
+
+		err := manifest.Validate()
+		if testCase.hasError != (err != nil) {
+			test.Errorf("Case %d: Unexpected error result. Expected error: '%v', Actual: '%v'.", i, testCase.hasError, err)
+			continue
+		}
+	}
+}
+
+func TestBackupManifestValidateNilEntry(test *testing.T) {
+	manifest := &BackupManifest{
+		Name: "course101-1",
+		Files: map[string]*ManifestEntry{
+			"course.json": nil,
+		},
+	}
+
+	err := manifest.Validate()
+	if err == nil {
+		test.Fatalf("Did not get an error for a nil entry.")
+	}
+}
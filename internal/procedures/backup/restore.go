@@ -0,0 +1,808 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/edulinq/autograder/internal/common"
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/lockmanager"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/procedures/courses"
+	"github.com/edulinq/autograder/internal/procedures/users"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const RESTORE_ASSIGNMENTS_DIRNAME = "assignments"
+
+type RestoreOptions struct {
+	// Restore the course under this ID instead of the ID found in the backup.
+	NewCourseID string `json:"new-course-id"`
+
+	// Allow restoring into a course that already exists.
+	// Without this, it is an error for the target course to already exist.
+	Merge bool `json:"merge"`
+
+	// When merging, replace the existing course's config with the config from the backup.
+	// Without this, the existing course config is kept (and every assignment in the backup must already exist in the course).
+	OverwriteCourse bool `json:"overwrite-course"`
+
+	// Replace the course information of users already enrolled in the course with the information from the backup.
+	// Without this, conflicting users are left alone and reported.
+	OverwriteUsers bool `json:"overwrite-users"`
+
+	// Replace existing submissions (with the same ID) with the submissions from the backup.
+	// Without this, conflicting submissions are left alone and reported.
+	OverwriteSubmissions bool `json:"overwrite-submissions"`
+
+	// Send emails to newly created users.
+	SendEmails bool `json:"send-emails"`
+
+	// Validate the backup and report what would be restored, but do not make any changes.
+	DryRun bool `json:"dry-run"`
+
+	// The dir that holds the other backups that an incremental backup depends on.
+	// Defaults to the dir the backup is in.
+	BackupsDir string `json:"-"`
+
+	ContextUser *model.ServerUser `json:"-"`
+}
+
+type RestoreResult struct {
+	// The course ID found in the backup.
+	SourceCourseID string `json:"source-course-id"`
+
+	// The ID the course was restored as.
+	CourseID string `json:"course-id"`
+
+	DryRun bool `json:"dry-run"`
+
+	// True if the course already existed before the restore.
+	CourseExisted bool `json:"course-existed"`
+
+	// True if the course config from the backup was (or would be, for a dry run) saved,
+	// and the course updated from its source.
+	CourseUpdated bool `json:"course-updated"`
+
+	Users         []*model.ExternalUserOpResult `json:"users"`
+	UserConflicts []string                      `json:"user-conflicts"`
+
+	// Full submission IDs (using the restored course ID).
+	RestoredSubmissions []string `json:"restored-submissions"`
+	SkippedSubmissions  []string `json:"skipped-submissions"`
+
+	RestoredManualGrades int `json:"restored-manual-grades"`
+
+	// Group IDs in the form: '<assignment id>::<group id>'.
+	RestoredGroups []string `json:"restored-groups"`
+	SkippedGroups  []string `json:"skipped-groups"`
+
+	RestoredExtensions int `json:"restored-extensions"`
+
+	RestoredGitRepos int `json:"restored-git-repos"`
+
+	RestoredLMSComments int `json:"restored-lms-comments"`
+}
+
+// All the course data read from a backup.
+type restoreData struct {
+	sourceCourseID string
+
+	course       *model.Course
+	users        []*model.RawCourseUserData
+	submissions  []*model.GradingResult
+	manualGrades map[string]*model.ManualGrade
+	groups       map[string][]*model.Group
+	extensions   []*model.Extension
+	gitRepos     map[string][]*model.GitRepo
+	lmsComments  []*model.LMSComment
+}
+
+// Restore a course from a backup (either a zip file or a directory) created by BackupCourseFull().
+// Analysis results are not restored, since they will be recomputed when requested.
+// Backups do not contain a course's source (which is needed for grading),
+// so the source is taken from the server's existing copy (see restoreCourseSource()).
+// Returns (result, user errors, internal errors).
+// User errors come from the backup or options (e.g., an invalid backup or an existing course),
+// while internal errors are failures of the system.
+func RestoreCourse(path string, options RestoreOptions) (*RestoreResult, error, error) {
+	if options.ContextUser == nil {
+		return nil, nil, fmt.Errorf("No context user provided.")
+	}
+
+	backupsDir := options.BackupsDir
+	if backupsDir == "" {
+		backupsDir = filepath.Dir(util.ShouldAbs(path))
+	}
+
+	baseDir, cleanup, err := getRestoreDir(path, backupsDir)
+	if err != nil {
+		return nil, err, nil
+	}
+	defer cleanup()
+
+	data, err := readRestoreData(baseDir, options.NewCourseID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read backup '%s': '%w'.", path, err), nil
+	}
+
+	course := data.course
+
+	lockKey := fmt.Sprintf("course-restore-%s", course.GetID())
+	lockmanager.Lock(lockKey)
+	defer lockmanager.Unlock(lockKey)
+
+	existingCourse, err := db.GetCourse(course.GetID())
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to check for existing course '%s': '%w'.", course.GetID(), err)
+	}
+
+	if (existingCourse != nil) && !options.Merge {
+		return nil, fmt.Errorf("Course '%s' already exists, a merge must be requested to restore into an existing course.", course.GetID()), nil
+	}
+
+	updateCourse := ((existingCourse == nil) || options.OverwriteCourse)
+
+	var sourceDir string
+	if updateCourse {
+		sourceDir, err = getRestoreSourceDir(data)
+		if err != nil {
+			return nil, err, nil
+		}
+	} else {
+		// Keep the existing course config.
+		for _, assignment := range course.Assignments {
+			if existingCourse.GetAssignment(assignment.GetID()) == nil {
+				return nil, fmt.Errorf("Backup has an assignment ('%s') that does not exist in course '%s', the course config must be overwritten to restore it.",
+					assignment.GetID(), course.GetID()), nil
+			}
+		}
+
+		data.course = existingCourse
+	}
+
+	result := &RestoreResult{
+		SourceCourseID:      data.sourceCourseID,
+		CourseID:            course.GetID(),
+		DryRun:              options.DryRun,
+		CourseExisted:       (existingCourse != nil),
+		CourseUpdated:       updateCourse,
+		Users:               make([]*model.ExternalUserOpResult, 0),
+		UserConflicts:       make([]string, 0),
+		RestoredSubmissions: make([]string, 0),
+		SkippedSubmissions:  make([]string, 0),
+		RestoredGroups:      make([]string, 0),
+		SkippedGroups:       make([]string, 0),
+	}
+
+	if updateCourse && !options.DryRun {
+		err = db.SaveCourse(course)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to save restored course '%s': '%w'.", course.GetID(), err)
+		}
+
+		data.course, err = restoreCourseSource(course, sourceDir, options)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to update source for restored course '%s': '%w'.", course.GetID(), err)
+		}
+	}
+
+	err = restoreUsers(existingCourse, data, options, result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = restoreSubmissions(data, options, result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = restoreGroups(data, options, result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = restoreExtensions(data, options, result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = restoreGitRepos(data, options, result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = restoreLMSComments(data, options, result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, nil, nil
+}
+
+// Find the directory that a restored course's source will be copied from.
+// This is the course's current source dir (if it exists),
+// and then the source dir of the course the backup was made from (e.g., when restoring under a new ID).
+// If neither exist, then the course config must have a source that the source can be synced from (and an empty string is returned).
+func getRestoreSourceDir(data *restoreData) (string, error) {
+	courseIDs := []string{data.course.GetID(), data.sourceCourseID}
+	for _, courseID := range courseIDs {
+		sourceDir := filepath.Join(config.GetSourcesDir(), courseID)
+		if util.IsDir(sourceDir) {
+			return sourceDir, nil
+		}
+	}
+
+	source := data.course.GetSource()
+	if (source != nil) && !source.IsEmpty() && !source.IsNil() {
+		return "", nil
+	}
+
+	return "", fmt.Errorf("Could not find a source for course '%s'. The course must either have a source set in its config, or have its source already on the server.", data.course.GetID())
+}
+
+// Fill the restored course's source dir (using the course config from the backup),
+// and then update the course from its local source (which will also sync any source set in the course config).
+// Returns the updated course.
+func restoreCourseSource(course *model.Course, sourceDir string, options RestoreOptions) (*model.Course, error) {
+	baseSourceDir := util.ShouldAbs(course.GetBaseSourceDir())
+
+	if (sourceDir != "") && (util.ShouldAbs(sourceDir) != baseSourceDir) {
+		if util.PathExists(baseSourceDir) {
+			err := util.RemoveDirent(baseSourceDir)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to remove source dir '%s': '%w'.", baseSourceDir, err)
+			}
+		}
+
+		err := util.CopyDirWhole(sourceDir, baseSourceDir)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to copy source dir from '%s' to '%s': '%w'.", sourceDir, baseSourceDir, err)
+		}
+	}
+
+	err := util.MkDir(baseSourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create source dir '%s': '%w'.", baseSourceDir, err)
+	}
+
+	err = util.ToJSONFileIndent(course, course.GetSourceConfigPath())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to write course config into source dir: '%w'.", err)
+	}
+
+	upsertOptions := courses.CourseUpsertOptions{
+		ContextUser: options.ContextUser,
+		CourseUpsertPublicOptions: courses.CourseUpsertPublicOptions{
+			SkipLMSSync:     true,
+			SkipBuildImages: true,
+			SkipEmails:      true,
+		},
+	}
+
+	_, err = courses.UpdateFromLocalSource(course, upsertOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedCourse, err := db.GetCourse(course.GetID())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get updated course: '%w'.", err)
+	}
+
+	if updatedCourse == nil {
+		return nil, fmt.Errorf("Could not find updated course.")
+	}
+
+	for _, assignment := range course.Assignments {
+		if updatedCourse.GetAssignment(assignment.GetID()) == nil {
+			return nil, fmt.Errorf("Backup has an assignment ('%s') that is not in the course's source.", assignment.GetID())
+		}
+	}
+
+	return updatedCourse, nil
+}
+
+// Get a temp directory that holds the backup's contents, unzipping the backup if necessary.
+// Any files that an incremental backup stores in other backups will be filled in from the backups in |backupsDir|.
+// The returned cleanup function should always be called.
+func getRestoreDir(path string, backupsDir string) (string, func(), error) {
+	noop := func() {}
+
+	if !util.PathExists(path) {
+		return "", noop, fmt.Errorf("Backup path does not exist: '%s'.", path)
+	}
+
+	tempDir, err := util.MkDirTemp("autograder-restore-course-")
+	if err != nil {
+		return "", noop, fmt.Errorf("Could not create temp restore dir: '%w'.", err)
+	}
+
+	cleanup := func() {
+		util.RemoveDirent(tempDir)
+	}
+
+	if util.IsDir(path) {
+		err = util.CopyDirContents(path, tempDir)
+	} else {
+		err = util.Unzip(path, tempDir)
+	}
+
+	if err != nil {
+		cleanup()
+		return "", noop, fmt.Errorf("Failed to read backup '%s': '%w'.", path, err)
+	}
+
+	baseDir, err := getBackupBaseDir(tempDir)
+	if err != nil {
+		cleanup()
+		return "", noop, err
+	}
+
+	err = fillIncrementalBackup(baseDir, backupsDir)
+	if err != nil {
+		cleanup()
+		return "", noop, err
+	}
+
+	return baseDir, cleanup, nil
+}
+
+func readRestoreData(baseDir string, newCourseID string) (*restoreData, error) {
+	configPath := filepath.Join(baseDir, model.COURSE_CONFIG_FILENAME)
+
+	course, err := model.LoadCourseFromPath(configPath, false)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load course: '%w'.", err)
+	}
+
+	data := &restoreData{
+		sourceCourseID: course.GetID(),
+		course:         course,
+		users:          make([]*model.RawCourseUserData, 0),
+		submissions:    make([]*model.GradingResult, 0),
+		manualGrades:   make(map[string]*model.ManualGrade),
+		groups:         make(map[string][]*model.Group),
+		extensions:     make([]*model.Extension, 0),
+		gitRepos:       make(map[string][]*model.GitRepo),
+		lmsComments:    make([]*model.LMSComment, 0),
+	}
+
+	if newCourseID != "" {
+		newCourseID, err = common.ValidateID(newCourseID)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid new course ID: '%w'.", err)
+		}
+
+		course.ID = newCourseID
+	}
+
+	usersPath := filepath.Join(baseDir, USERS_FILENAME)
+	if util.IsFile(usersPath) {
+		err = util.JSONFromFile(usersPath, &data.users)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load course users: '%w'.", err)
+		}
+	}
+
+	err = readRestoreSubmissions(baseDir, data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, assignment := range course.Assignments {
+		path := filepath.Join(baseDir, RESTORE_ASSIGNMENTS_DIRNAME, assignment.GetID(), model.GROUPS_FILENAME)
+		if !util.IsFile(path) {
+			continue
+		}
+
+		groups := make([]*model.Group, 0)
+		err = util.JSONFromFile(path, &groups)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load groups for assignment '%s': '%w'.", assignment.GetID(), err)
+		}
+
+		for _, group := range groups {
+			if group == nil {
+				continue
+			}
+
+			group.CourseID = course.GetID()
+			group.AssignmentID = assignment.GetID()
+
+			err = group.Validate(assignment.Groups)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid group for assignment '%s': '%w'.", assignment.GetID(), err)
+			}
+
+			data.groups[assignment.GetID()] = append(data.groups[assignment.GetID()], group)
+		}
+	}
+
+	for _, assignment := range course.Assignments {
+		path := filepath.Join(baseDir, RESTORE_ASSIGNMENTS_DIRNAME, assignment.GetID(), model.GIT_REPOS_FILENAME)
+		if !util.IsFile(path) {
+			continue
+		}
+
+		gitRepos := make([]*model.GitRepo, 0)
+		err = util.JSONFromFile(path, &gitRepos)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load git repos for assignment '%s': '%w'.", assignment.GetID(), err)
+		}
+
+		for _, gitRepo := range gitRepos {
+			if gitRepo == nil {
+				continue
+			}
+
+			gitRepo.CourseID = course.GetID()
+			gitRepo.AssignmentID = assignment.GetID()
+
+			err = gitRepo.Validate()
+			if err != nil {
+				return nil, fmt.Errorf("Invalid git repo for assignment '%s': '%w'.", assignment.GetID(), err)
+			}
+
+			data.gitRepos[assignment.GetID()] = append(data.gitRepos[assignment.GetID()], gitRepo)
+		}
+	}
+
+	extensionsPath := filepath.Join(baseDir, model.EXTENSIONS_FILENAME)
+	if util.IsFile(extensionsPath) {
+		extensions := make([]*model.Extension, 0)
+		err = util.JSONFromFile(extensionsPath, &extensions)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load extensions: '%w'.", err)
+		}
+
+		for _, extension := range extensions {
+			if extension == nil {
+				continue
+			}
+
+			extension.CourseID = course.GetID()
+
+			err = extension.Validate()
+			if err != nil {
+				return nil, fmt.Errorf("Invalid extension for user '%s': '%w'.", extension.User, err)
+			}
+
+			if (extension.AssignmentID != "") && (course.GetAssignment(extension.AssignmentID) == nil) {
+				return nil, fmt.Errorf("Extension for user '%s' is for an unknown assignment: '%s'.", extension.User, extension.AssignmentID)
+			}
+
+			data.extensions = append(data.extensions, extension)
+		}
+	}
+
+	lmsCommentsPath := filepath.Join(baseDir, model.LMS_COMMENTS_FILENAME)
+	if util.IsFile(lmsCommentsPath) {
+		lmsComments := make([]*model.LMSComment, 0)
+		err = util.JSONFromFile(lmsCommentsPath, &lmsComments)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load LMS comments: '%w'.", err)
+		}
+
+		for _, lmsComment := range lmsComments {
+			if lmsComment == nil {
+				continue
+			}
+
+			lmsComment.CourseID = course.GetID()
+
+			err = lmsComment.Validate()
+			if err != nil {
+				return nil, fmt.Errorf("Invalid LMS comment for user '%s': '%w'.", lmsComment.UserLMSID, err)
+			}
+
+			data.lmsComments = append(data.lmsComments, lmsComment)
+		}
+	}
+
+	return data, nil
+}
+
+// Backup zips place all their contents inside a single top-level directory (named after the backup).
+// Find the directory that holds the course config, which is either the given dir or its only child.
+func getBackupBaseDir(baseDir string) (string, error) {
+	if util.IsFile(filepath.Join(baseDir, model.COURSE_CONFIG_FILENAME)) {
+		return baseDir, nil
+	}
+
+	dirents, err := os.ReadDir(baseDir)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read backup dir '%s': '%w'.", baseDir, err)
+	}
+
+	if (len(dirents) == 1) && dirents[0].IsDir() {
+		childDir := filepath.Join(baseDir, dirents[0].Name())
+		if util.IsFile(filepath.Join(childDir, model.COURSE_CONFIG_FILENAME)) {
+			return childDir, nil
+		}
+	}
+
+	return "", fmt.Errorf("Could not find a course config ('%s') in the backup.", model.COURSE_CONFIG_FILENAME)
+}
+
+// Read all the submissions (and their manual grades) from a backup.
+// Submissions are moved into the restored course.
+func readRestoreSubmissions(baseDir string, data *restoreData) error {
+	submissionsDir := filepath.Join(baseDir, model.SUBMISSIONS_DIRNAME)
+	if !util.IsDir(submissionsDir) {
+		return nil
+	}
+
+	resultPaths, err := util.FindFiles(model.SUBMISSION_RESULT_FILENAME, submissionsDir)
+	if err != nil {
+		return fmt.Errorf("Failed to search for submission results in '%s': '%w'.", submissionsDir, err)
+	}
+
+	for _, resultPath := range resultPaths {
+		submission, err := model.LoadGradingResult(resultPath)
+		if err != nil {
+			return fmt.Errorf("Failed to load submission: '%w'.", err)
+		}
+
+		info := submission.Info
+
+		assignment := data.course.GetAssignment(info.AssignmentID)
+		if assignment == nil {
+			return fmt.Errorf("Submission '%s' is for an unknown assignment: '%s'.", info.ID, info.AssignmentID)
+		}
+
+		info.CourseID = data.course.GetID()
+		info.ID = common.CreateFullSubmissionID(info.CourseID, info.AssignmentID, info.User, info.ShortID)
+
+		data.submissions = append(data.submissions, submission)
+
+		manualGradePath := filepath.Join(filepath.Dir(resultPath), model.MANUAL_GRADE_FILENAME)
+		if !util.IsFile(manualGradePath) {
+			continue
+		}
+
+		var manualGrade model.ManualGrade
+		err = util.JSONFromFile(manualGradePath, &manualGrade)
+		if err != nil {
+			return fmt.Errorf("Failed to load manual grade for submission '%s': '%w'.", info.ID, err)
+		}
+
+		manualGrade.CourseID = info.CourseID
+
+		err = manualGrade.Validate(assignment.Rubric)
+		if err != nil {
+			return fmt.Errorf("Invalid manual grade for submission '%s': '%w'.", info.ID, err)
+		}
+
+		data.manualGrades[info.ID] = &manualGrade
+	}
+
+	return nil
+}
+
+// Enroll the backup's users in the course.
+// Users that are already enrolled with different information are conflicts.
+func restoreUsers(existingCourse *model.Course, data *restoreData, options RestoreOptions, result *RestoreResult) error {
+	existingUsers := make(map[string]*model.CourseUser)
+
+	if existingCourse != nil {
+		var err error
+		existingUsers, err = db.GetCourseUsers(existingCourse)
+		if err != nil {
+			return fmt.Errorf("Failed to get existing course users: '%w'.", err)
+		}
+	}
+
+	rawUsers := make([]*model.RawCourseUserData, 0, len(data.users))
+	for _, rawUser := range data.users {
+		if rawUser == nil {
+			continue
+		}
+
+		existingUser := existingUsers[rawUser.Email]
+		if (existingUser != nil) && !isSameCourseUser(existingUser, rawUser) {
+			result.UserConflicts = append(result.UserConflicts, rawUser.Email)
+
+			if !options.OverwriteUsers {
+				continue
+			}
+		}
+
+		rawUsers = append(rawUsers, rawUser)
+	}
+
+	upsertOptions := users.UpsertUsersOptions{
+		RawUsers:          model.ToRawServerUserDatas(rawUsers, data.course),
+		SendEmails:        options.SendEmails,
+		DryRun:            options.DryRun,
+		ContextEmail:      options.ContextUser.Email,
+		ContextServerRole: options.ContextUser.Role,
+	}
+
+	for _, userResult := range users.UpsertUsers(upsertOptions) {
+		result.Users = append(result.Users, userResult.ToExternalResult())
+	}
+
+	slices.SortFunc(result.Users, model.CompareExternalUserOpResultPointer)
+	slices.Sort(result.UserConflicts)
+
+	return nil
+}
+
+func isSameCourseUser(user *model.CourseUser, rawUser *model.RawCourseUserData) bool {
+	name := ""
+	if user.Name != nil {
+		name = *user.Name
+	}
+
+	lmsID := ""
+	if user.LMSID != nil {
+		lmsID = *user.LMSID
+	}
+
+	return (user.Role == model.GetCourseUserRole(rawUser.CourseRole)) && (name == rawUser.Name) && (lmsID == rawUser.CourseLMSID)
+}
+
+// Save the backup's submissions (and manual grades).
+// Submissions that already exist are conflicts.
+func restoreSubmissions(data *restoreData, options RestoreOptions, result *RestoreResult) error {
+	submissions := make([]*model.GradingResult, 0, len(data.submissions))
+
+	for _, submission := range data.submissions {
+		info := submission.Info
+		assignment := data.course.GetAssignment(info.AssignmentID)
+
+		existing, err := db.GetSubmissionResult(assignment, info.User, info.ShortID)
+		if err != nil {
+			return fmt.Errorf("Failed to check for existing submission '%s': '%w'.", info.ID, err)
+		}
+
+		if existing != nil {
+			if !options.OverwriteSubmissions {
+				result.SkippedSubmissions = append(result.SkippedSubmissions, info.ID)
+				continue
+			}
+
+			if !options.DryRun {
+				_, err = db.RemoveSubmission(assignment, info.User, info.ShortID)
+				if err != nil {
+					return fmt.Errorf("Failed to remove existing submission '%s': '%w'.", info.ID, err)
+				}
+			}
+		}
+
+		submissions = append(submissions, submission)
+		result.RestoredSubmissions = append(result.RestoredSubmissions, info.ID)
+	}
+
+	if !options.DryRun {
+		err := db.SaveSubmissions(data.course, submissions)
+		if err != nil {
+			return fmt.Errorf("Failed to save restored submissions: '%w'.", err)
+		}
+	}
+
+	for _, submission := range submissions {
+		manualGrade := data.manualGrades[submission.Info.ID]
+		if manualGrade == nil {
+			continue
+		}
+
+		if !options.DryRun {
+			err := db.SaveManualGrade(manualGrade)
+			if err != nil {
+				return fmt.Errorf("Failed to save restored manual grade for submission '%s': '%w'.", submission.Info.ID, err)
+			}
+		}
+
+		result.RestoredManualGrades++
+	}
+
+	slices.Sort(result.RestoredSubmissions)
+	slices.Sort(result.SkippedSubmissions)
+
+	return nil
+}
+
+// Save the backup's groups.
+// Groups with a member that is already in a different group are conflicts (and are always skipped).
+func restoreGroups(data *restoreData, options RestoreOptions, result *RestoreResult) error {
+	for assignmentID, groups := range data.groups {
+		assignment := data.course.GetAssignment(assignmentID)
+
+		existingGroups, err := db.GetGroups(assignment)
+		if err != nil {
+			return fmt.Errorf("Failed to get existing groups for assignment '%s': '%w'.", assignmentID, err)
+		}
+
+		for _, group := range groups {
+			key := fmt.Sprintf("%s::%s", assignmentID, group.ID)
+
+			if hasGroupConflict(existingGroups, group) {
+				result.SkippedGroups = append(result.SkippedGroups, key)
+				continue
+			}
+
+			if !options.DryRun {
+				err = db.SaveGroup(assignment, group)
+				if err != nil {
+					return fmt.Errorf("Failed to save restored group '%s': '%w'.", key, err)
+				}
+			}
+
+			result.RestoredGroups = append(result.RestoredGroups, key)
+		}
+	}
+
+	slices.Sort(result.RestoredGroups)
+	slices.Sort(result.SkippedGroups)
+
+	return nil
+}
+
+func hasGroupConflict(existingGroups []*model.Group, group *model.Group) bool {
+	for _, member := range group.Members {
+		existingGroup := model.GetUserGroup(existingGroups, member)
+		if (existingGroup != nil) && (existingGroup.ID != group.ID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Save the backup's extensions.
+// Extensions replace any existing extension for the same user and assignment.
+func restoreExtensions(data *restoreData, options RestoreOptions, result *RestoreResult) error {
+	for _, extension := range data.extensions {
+		if !options.DryRun {
+			err := db.SaveExtension(extension)
+			if err != nil {
+				return fmt.Errorf("Failed to save restored extension for user '%s': '%w'.", extension.User, err)
+			}
+		}
+
+		result.RestoredExtensions++
+	}
+
+	return nil
+}
+
+// Save the backup's git repos.
+// Repos replace any existing repo for the same user and assignment.
+func restoreGitRepos(data *restoreData, options RestoreOptions, result *RestoreResult) error {
+	for assignmentID, gitRepos := range data.gitRepos {
+		assignment := data.course.GetAssignment(assignmentID)
+
+		for _, gitRepo := range gitRepos {
+			if !options.DryRun {
+				err := db.SaveGitRepo(assignment, gitRepo)
+				if err != nil {
+					return fmt.Errorf("Failed to save restored git repo for user '%s': '%w'.", gitRepo.User, err)
+				}
+			}
+
+			result.RestoredGitRepos++
+		}
+	}
+
+	return nil
+}
+
+// Save the backup's LMS comments (see model.LMSComment).
+// Comments replace any existing comment for the same user and LMS assignment.
+func restoreLMSComments(data *restoreData, options RestoreOptions, result *RestoreResult) error {
+	if !options.DryRun {
+		err := db.SaveLMSComments(data.lmsComments)
+		if err != nil {
+			return fmt.Errorf("Failed to save restored LMS comments: '%w'.", err)
+		}
+	}
+
+	result.RestoredLMSComments = len(data.lmsComments)
+
+	return nil
+}
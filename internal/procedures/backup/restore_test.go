@@ -0,0 +1,357 @@
+package backup
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+var expectedSubmissionShortIDs []string = []string{"1697406256", "1697406265", "1697406272"}
+
+func TestRestoreNewCourseID(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	backupPath := mustBackupTestCourse(test)
+	defer util.RemoveDirent(filepath.Dir(backupPath))
+
+	sourceCourse := db.MustGetTestCourse()
+
+	sourceUsers, err := db.GetCourseUsers(sourceCourse)
+	if err != nil {
+		test.Fatalf("Failed to get source course users: '%v'.", err)
+	}
+
+	// First a dry run, which should not change anything.
+	options := RestoreOptions{
+		NewCourseID: "restored",
+		DryRun:      true,
+		ContextUser: db.MustGetRoot(),
+	}
+
+	result, userErr, err := RestoreCourse(backupPath, options)
+	checkRestoreResult(test, "dry run", result, userErr, err, len(sourceUsers), 3, 0)
+
+	course, err := db.GetCourse("restored")
+	if err != nil {
+		test.Fatalf("Failed to get course after dry run: '%v'.", err)
+	}
+
+	if course != nil {
+		test.Fatalf("Course exists after a dry run.")
+	}
+
+	// Now a real restore.
+	options.DryRun = false
+
+	result, userErr, err = RestoreCourse(backupPath, options)
+	checkRestoreResult(test, "restore", result, userErr, err, len(sourceUsers), 3, 0)
+
+	if result.SourceCourseID != "course101" {
+		test.Fatalf("Unexpected source course ID: '%s'.", result.SourceCourseID)
+	}
+
+	course = db.MustGetCourse("restored")
+	defer util.RemoveDirent(course.GetBaseSourceDir())
+
+	// The restored course must have a source to grade from.
+	sourceCourse, err = model.LoadCourseFromPath(course.GetSourceConfigPath(), true)
+	if err != nil {
+		test.Fatalf("Failed to load restored course source: '%v'.", err)
+	}
+
+	if sourceCourse.GetID() != "restored" {
+		test.Fatalf("Restored course source has the wrong ID: '%s'.", sourceCourse.GetID())
+	}
+
+	if !util.IsDir(course.GetAssignment("hw0").GetSourceDir()) {
+		test.Fatalf("Restored assignment does not have a source dir: '%s'.", course.GetAssignment("hw0").GetSourceDir())
+	}
+
+	sourceCourse = db.MustGetTestCourse()
+
+	if len(course.Assignments) != len(sourceCourse.Assignments) {
+		test.Fatalf("Unexpected number of restored assignments. Expected: %d, Actual: %d.", len(sourceCourse.Assignments), len(course.Assignments))
+	}
+
+	restoredUsers, err := db.GetCourseUsers(course)
+	if err != nil {
+		test.Fatalf("Failed to get restored course users: '%v'.", err)
+	}
+
+	if util.MustToJSON(sourceUsers) != util.MustToJSON(restoredUsers) {
+		test.Fatalf("Restored course users do not match. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(sourceUsers), util.MustToJSONIndent(restoredUsers))
+	}
+
+	assignment := course.GetAssignment("hw0")
+	email := "course-student@test.edulinq.org"
+
+	history, err := db.GetSubmissionHistory(assignment, email)
+	if err != nil {
+		test.Fatalf("Failed to get restored submission history: '%v'.", err)
+	}
+
+	if len(history) != len(expectedSubmissionShortIDs) {
+		test.Fatalf("Unexpected restored submission history: '%s'.", util.MustToJSONIndent(history))
+	}
+
+	for _, item := range history {
+		if item.CourseID != "restored" {
+			test.Fatalf("Restored submission has the wrong course: '%s'.", util.MustToJSONIndent(item))
+		}
+	}
+}
+
+func TestRestoreConflicts(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	backupPath := mustBackupTestCourse(test)
+	defer util.RemoveDirent(filepath.Dir(backupPath))
+
+	course := db.MustGetTestCourse()
+	assignment := course.GetAssignment("hw0")
+	email := "course-student@test.edulinq.org"
+
+	// Change a user's role and remove a submission so there is something to differ from the backup.
+	user := db.MustGetServerUser(email)
+	user.CourseInfo[course.GetID()].Role = model.CourseRoleGrader
+
+	err := db.UpsertUser(user)
+	if err != nil {
+		test.Fatalf("Failed to update user: '%v'.", err)
+	}
+
+	_, err = db.RemoveSubmission(assignment, email, "1697406272")
+	if err != nil {
+		test.Fatalf("Failed to remove submission: '%v'.", err)
+	}
+
+	users, err := db.GetCourseUsers(course)
+	if err != nil {
+		test.Fatalf("Failed to get course users: '%v'.", err)
+	}
+
+	// Restoring over an existing course requires a merge.
+	options := RestoreOptions{
+		ContextUser: db.MustGetRoot(),
+	}
+
+	_, userErr, err := RestoreCourse(backupPath, options)
+	if err != nil {
+		test.Fatalf("Got an unexpected internal error: '%v'.", err)
+	}
+
+	if userErr == nil {
+		test.Fatalf("Did not get an error when restoring over an existing course without a merge.")
+	}
+
+	// Change the course config, which a merge should keep unless asked to overwrite it.
+	course.Name = "Changed Name"
+
+	err = db.SaveCourse(course)
+	if err != nil {
+		test.Fatalf("Failed to save course: '%v'.", err)
+	}
+
+	// Merge, but keep existing data.
+	options.Merge = true
+
+	result, userErr, err := RestoreCourse(backupPath, options)
+	checkRestoreResult(test, "merge", result, userErr, err, len(users)-1, 1, 2)
+
+	if result.CourseUpdated || (db.MustGetTestCourse().GetName() != "Changed Name") {
+		test.Fatalf("Course config was changed without an overwrite.")
+	}
+
+	if !slices.Equal([]string{email}, result.UserConflicts) {
+		test.Fatalf("Unexpected user conflicts: '%v'.", result.UserConflicts)
+	}
+
+	if db.MustGetServerUser(email).CourseInfo[course.GetID()].Role != model.CourseRoleGrader {
+		test.Fatalf("Conflicting user was changed without an overwrite.")
+	}
+
+	history, err := db.GetSubmissionHistory(assignment, email)
+	if err != nil {
+		test.Fatalf("Failed to get submission history: '%v'.", err)
+	}
+
+	if len(history) != len(expectedSubmissionShortIDs) {
+		test.Fatalf("Removed submission was not restored: '%s'.", util.MustToJSONIndent(history))
+	}
+
+	// Merge and overwrite.
+	options.OverwriteCourse = true
+	options.OverwriteUsers = true
+	options.OverwriteSubmissions = true
+
+	result, userErr, err = RestoreCourse(backupPath, options)
+	checkRestoreResult(test, "overwrite", result, userErr, err, len(users), 3, 0)
+
+	if !result.CourseUpdated || (db.MustGetTestCourse().GetName() != "Course 101") {
+		test.Fatalf("Course config was not changed with an overwrite.")
+	}
+
+	if db.MustGetServerUser(email).CourseInfo[course.GetID()].Role != model.CourseRoleStudent {
+		test.Fatalf("Conflicting user was not changed with an overwrite.")
+	}
+}
+
+func TestRestoreNoSource(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	backupPath := mustBackupTestCourse(test)
+	defer util.RemoveDirent(filepath.Dir(backupPath))
+
+	// Without the source course's source (or a source in the config), the restored course would not be able to grade.
+	err := util.RemoveDirent(db.MustGetTestCourse().GetBaseSourceDir())
+	if err != nil {
+		test.Fatalf("Failed to remove course source: '%v'.", err)
+	}
+
+	options := RestoreOptions{
+		NewCourseID: "restored",
+		ContextUser: db.MustGetRoot(),
+	}
+
+	_, userErr, err := RestoreCourse(backupPath, options)
+	if err != nil {
+		test.Fatalf("Got an unexpected internal error: '%v'.", err)
+	}
+
+	if userErr == nil {
+		test.Fatalf("Did not get an error when restoring a course without a source.")
+	}
+
+	course, err := db.GetCourse("restored")
+	if err != nil {
+		test.Fatalf("Failed to get course: '%v'.", err)
+	}
+
+	if course != nil {
+		test.Fatalf("Course was restored without a source.")
+	}
+}
+
+func TestRestoreFromDir(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	backupPath := mustBackupTestCourse(test)
+	defer util.RemoveDirent(filepath.Dir(backupPath))
+
+	backupDir := filepath.Join(filepath.Dir(backupPath), "unzipped")
+
+	err := util.Unzip(backupPath, backupDir)
+	if err != nil {
+		test.Fatalf("Failed to unzip backup: '%v'.", err)
+	}
+
+	options := RestoreOptions{
+		NewCourseID: "restored",
+		DryRun:      true,
+		ContextUser: db.MustGetRoot(),
+	}
+
+	result, userErr, err := RestoreCourse(backupDir, options)
+	if (userErr != nil) || (err != nil) {
+		test.Fatalf("Failed to restore from a directory: '%v', '%v'.", userErr, err)
+	}
+
+	if len(result.RestoredSubmissions) != len(expectedSubmissionShortIDs) {
+		test.Fatalf("Unexpected restored submissions: '%v'.", result.RestoredSubmissions)
+	}
+}
+
+func TestRestoreBadBackup(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	tempDir := util.MustMkDirTemp("autograder-test-course-restore-bad-")
+	defer util.RemoveDirent(tempDir)
+
+	notZipPath := filepath.Join(tempDir, "not-a-zip.zip")
+	err := util.WriteFile("Not a zip file.", notZipPath)
+	if err != nil {
+		test.Fatalf("Failed to write bad zip file: '%v'.", err)
+	}
+
+	backupPath := mustBackupTestCourse(test)
+	defer util.RemoveDirent(filepath.Dir(backupPath))
+
+	testCases := []struct {
+		path        string
+		newCourseID string
+	}{
+		{filepath.Join(tempDir, "ZZZ.zip"), ""},
+		{notZipPath, ""},
+		{tempDir, ""},
+		{backupPath, "bad id!"},
+	}
+
+	for i, testCase := range testCases {
+		options := RestoreOptions{
+			NewCourseID: testCase.newCourseID,
+			ContextUser: db.MustGetRoot(),
+		}
+
+		_, userErr, err := RestoreCourse(testCase.path, options)
+		if err != nil {
+			test.Errorf("Case %d: Got an unexpected internal error: '%v'.", i, err)
+			continue
+		}
+
+		if userErr == nil {
+			test.Errorf("Case %d: Did not get an expected user error.", i)
+			continue
+		}
+	}
+}
+
+func checkRestoreResult(test *testing.T, label string, result *RestoreResult, userErr error, err error,
+	expectedUsers int, expectedRestored int, expectedSkipped int) {
+	if userErr != nil {
+		test.Fatalf("%s: Got an unexpected user error: '%v'.", label, userErr)
+	}
+
+	if err != nil {
+		test.Fatalf("%s: Got an unexpected internal error: '%v'.", label, err)
+	}
+
+	if len(result.Users) != expectedUsers {
+		test.Fatalf("%s: Unexpected number of user results. Expected: %d, Actual: %d.", label, expectedUsers, len(result.Users))
+	}
+
+	for _, userResult := range result.Users {
+		if (userResult.ValidationError != nil) || (userResult.SystemError != nil) {
+			test.Fatalf("%s: Got a user error: '%s'.", label, util.MustToJSONIndent(userResult))
+		}
+	}
+
+	if len(result.RestoredSubmissions) != expectedRestored {
+		test.Fatalf("%s: Unexpected restored submissions. Expected: %d, Actual: '%v'.", label, expectedRestored, result.RestoredSubmissions)
+	}
+
+	if len(result.SkippedSubmissions) != expectedSkipped {
+		test.Fatalf("%s: Unexpected skipped submissions. Expected: %d, Actual: '%v'.", label, expectedSkipped, result.SkippedSubmissions)
+	}
+}
+
+func mustBackupTestCourse(test *testing.T) string {
+	tempDir := util.MustMkDirTemp("autograder-test-course-restore-")
+
+	err := BackupCourseFull(db.MustGetTestCourse(), tempDir, "test")
+	if err != nil {
+		util.RemoveDirent(tempDir)
+		test.Fatalf("Failed to backup course: '%v'.", err)
+	}
+
+	return filepath.Join(tempDir, "course101-test.zip")
+}
@@ -0,0 +1,188 @@
+package backup
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// Which of a course's backups to keep.
+// Any backup not kept by at least one rule is removed,
+// but backups that a kept backup depends on (see BackupManifest.GetDependencies()) are always kept.
+// The most recent backup is also always kept.
+type RetentionPolicy struct {
+	// Keep this many of the most recent backups.
+	KeepLast int `json:"keep-last"`
+
+	// Keep the most recent backup from each of this many of the most recent days that have a backup.
+	KeepDaily int `json:"keep-daily"`
+
+	// Keep the most recent backup from each of this many of the most recent weeks that have a backup.
+	KeepWeekly int `json:"keep-weekly"`
+}
+
+func (this RetentionPolicy) IsEmpty() bool {
+	return (this.KeepLast <= 0) && (this.KeepDaily <= 0) && (this.KeepWeekly <= 0)
+}
+
+// A course backup found on disk.
+type backupInfo struct {
+	path     string
+	manifest *BackupManifest
+
+	// When the backup was made (the backup file's modification time).
+	time timestamp.Timestamp
+}
+
+// Remove the course backups in |dest| that are not kept by the policy.
+// Backups without a manifest are never removed.
+// Returns the names of the removed backups.
+func ApplyRetention(dest string, courseID string, policy RetentionPolicy) ([]string, error) {
+	removed := make([]string, 0)
+
+	if policy.IsEmpty() {
+		return removed, nil
+	}
+
+	backups, err := getBackups(dest, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := selectRetainedBackups(backups, policy)
+
+	for _, backup := range backups {
+		if keep[backup.manifest.Name] {
+			continue
+		}
+
+		err = util.RemoveDirent(backup.path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to remove backup '%s': '%w'.", backup.path, err)
+		}
+
+		removed = append(removed, backup.manifest.Name)
+	}
+
+	slices.Sort(removed)
+
+	return removed, nil
+}
+
+// Get the names of the backups that should be kept.
+// |backups| should be sorted with the most recent backup first.
+func selectRetainedBackups(backups []*backupInfo, policy RetentionPolicy) map[string]bool {
+	keep := make(map[string]bool)
+
+	if len(backups) == 0 {
+		return keep
+	}
+
+	keep[backups[0].manifest.Name] = true
+
+	for i := 0; (i < policy.KeepLast) && (i < len(backups)); i++ {
+		keep[backups[i].manifest.Name] = true
+	}
+
+	keepPeriods(backups, policy.KeepDaily, "2006-01-02", keep)
+	keepPeriods(backups, policy.KeepWeekly, "", keep)
+
+	// Keep any backups that kept backups depend on.
+	for _, backup := range backups {
+		if !keep[backup.manifest.Name] {
+			continue
+		}
+
+		for _, dependency := range backup.manifest.GetDependencies() {
+			keep[dependency] = true
+		}
+	}
+
+	return keep
+}
+
+// Keep the most recent backup in each of the most recent |count| periods.
+// Periods are identified by formatting the backup's (UTC) time with |layout|,
+// an empty layout means to use ISO weeks.
+func keepPeriods(backups []*backupInfo, count int, layout string, keep map[string]bool) {
+	if count <= 0 {
+		return
+	}
+
+	seenPeriods := make(map[string]bool)
+
+	for _, backup := range backups {
+		instance := backup.time.ToGoTime().UTC()
+
+		period := ""
+		if layout == "" {
+			year, week := instance.ISOWeek()
+			period = fmt.Sprintf("%d-%02d", year, week)
+		} else {
+			period = instance.Format(layout)
+		}
+
+		if seenPeriods[period] {
+			continue
+		}
+
+		if len(seenPeriods) >= count {
+			break
+		}
+
+		seenPeriods[period] = true
+		keep[backup.manifest.Name] = true
+	}
+}
+
+// Get all of a course's backups in |dest| (most recent first).
+// Backups without a manifest are skipped.
+func getBackups(dest string, courseID string) ([]*backupInfo, error) {
+	paths, err := filepath.Glob(filepath.Join(dest, courseID+"-*.zip"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to search for backups in '%s': '%w'.", dest, err)
+	}
+
+	backups := make([]*backupInfo, 0, len(paths))
+
+	for _, path := range paths {
+		manifest, err := ReadManifest(path)
+		if err != nil {
+			log.Warn("Failed to read backup manifest, skipping backup.", err, log.NewAttr("path", path))
+			continue
+		}
+
+		// Skip old backups and backups for other courses that share a prefix.
+		if (manifest == nil) || (manifest.CourseID != courseID) {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to stat backup '%s': '%w'.", path, err)
+		}
+
+		backups = append(backups, &backupInfo{
+			path:     path,
+			manifest: manifest,
+			time:     timestamp.FromGoTime(info.ModTime()),
+		})
+	}
+
+	slices.SortFunc(backups, func(a *backupInfo, b *backupInfo) int {
+		if a.time != b.time {
+			return cmp.Compare(b.time.ToMSecs(), a.time.ToMSecs())
+		}
+
+		return strings.Compare(b.manifest.Name, a.manifest.Name)
+	})
+
+	return backups, nil
+}
@@ -0,0 +1,141 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestSelectRetainedBackups(test *testing.T) {
+	day := int64(24 * 60 * 60 * 1000)
+
+	// 2024-01-01 (a Monday) at noon UTC.
+	baseTime := int64(1704110400000)
+
+	// Backups every 12 hours over two weeks (newest first).
+	// The last backup is incremental and depends on the one before it.
+	backups := make([]*backupInfo, 0)
+	for i := 27; i >= 0; i-- {
+		backups = append(backups, &backupInfo{
+			manifest: &BackupManifest{
+				Name:  backupName(i),
+				Files: map[string]*ManifestEntry{"a": &ManifestEntry{Backup: backupName(i)}},
+			},
+			time: timestamp.FromMSecs(baseTime + (int64(i) * day / 2)),
+		})
+	}
+
+	backups[0].manifest.Files["b"] = &ManifestEntry{Backup: backupName(26)}
+
+	testCases := []struct {
+		policy   RetentionPolicy
+		expected []string
+	}{
+		// The most recent backup (and its dependencies) are always kept.
+		{RetentionPolicy{}, []string{"27", "26"}},
+		{RetentionPolicy{KeepLast: 1}, []string{"27", "26"}},
+		{RetentionPolicy{KeepLast: 3}, []string{"27", "26", "25"}},
+
+		// The latest backup in each day.
+		{RetentionPolicy{KeepDaily: 3}, []string{"27", "26", "24"}},
+
+		// The latest backup in each week.
+		{RetentionPolicy{KeepWeekly: 5}, []string{"27", "26", "12"}},
+
+		// Combined.
+		{RetentionPolicy{KeepLast: 3, KeepDaily: 3, KeepWeekly: 3}, []string{"27", "26", "25", "24", "12"}},
+	}
+
+	for i, testCase := range testCases {
+		keep := selectRetainedBackups(backups, testCase.policy)
+
+		expected := make(map[string]bool, len(testCase.expected))
+		for _, id := range testCase.expected {
+			expected["course101-"+id] = true
+		}
+
+		if !reflect.DeepEqual(expected, keep) {
+			test.Errorf("Case %d: Unexpected retained backups. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(expected), util.MustToJSONIndent(keep))
+			continue
+		}
+	}
+}
+
+func TestApplyRetention(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	tempDir := util.MustMkDirTemp("autograder-test-course-backup-retention-")
+	defer util.RemoveDirent(tempDir)
+
+	course := db.MustGetTestCourse()
+
+	// A backup without a manifest is never removed.
+	oldBackupPath := filepath.Join(tempDir, "course101-old.zip")
+	oldBackupDir := util.MustMkDirTemp("autograder-test-course-backup-retention-old-")
+	defer util.RemoveDirent(oldBackupDir)
+
+	err := util.Zip(oldBackupDir, oldBackupPath, true)
+	if err != nil {
+		test.Fatalf("Failed to make old backup: '%v'.", err)
+	}
+
+	now := time.Now()
+
+	for i := 0; i < 4; i++ {
+		result, err := BackupCourseWithOptions(course, BackupOptions{Dest: tempDir, BackupID: backupName(i)[len("course101-"):]})
+		if err != nil {
+			test.Fatalf("Failed to make backup %d: '%v'.", i, err)
+		}
+
+		// Space out the backups by a day.
+		instance := now.Add(time.Duration(i-4) * 24 * time.Hour)
+		err = os.Chtimes(result.Path, instance, instance)
+		if err != nil {
+			test.Fatalf("Failed to set backup time: '%v'.", err)
+		}
+	}
+
+	options := BackupOptions{
+		Dest:            tempDir,
+		BackupID:        backupName(4)[len("course101-"):],
+		RetentionPolicy: RetentionPolicy{KeepLast: 2},
+	}
+
+	result, err := BackupCourseWithOptions(course, options)
+	if err != nil {
+		test.Fatalf("Failed to make backup with retention: '%v'.", err)
+	}
+
+	expectedRemoved := []string{backupName(0), backupName(1), backupName(2)}
+	if !reflect.DeepEqual(expectedRemoved, result.RemovedBackups) {
+		test.Fatalf("Unexpected removed backups. Expected: '%v', Actual: '%v'.", expectedRemoved, result.RemovedBackups)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(tempDir, "*.zip"))
+	if err != nil {
+		test.Fatalf("Failed to list backups: '%v'.", err)
+	}
+
+	expectedPaths := []string{
+		filepath.Join(tempDir, backupName(3)+".zip"),
+		filepath.Join(tempDir, backupName(4)+".zip"),
+		oldBackupPath,
+	}
+
+	if !reflect.DeepEqual(expectedPaths, paths) {
+		test.Fatalf("Unexpected remaining backups. Expected: '%v', Actual: '%v'.", expectedPaths, paths)
+	}
+}
+
+func backupName(i int) string {
+	return fmt.Sprintf("course101-%02d", i)
+}
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"archive/zip"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/edulinq/autograder/internal/util"
+)
+
+type VerifyResult struct {
+	Name  string `json:"name"`
+	Valid bool   `json:"valid"`
+
+	FileCount int `json:"file-count"`
+
+	// Backups that this backup depends on, but could not be found.
+	MissingBackups []string `json:"missing-backups"`
+
+	// Files in the manifest that could not be found in the backup that should hold them.
+	MissingFiles []string `json:"missing-files"`
+
+	// Files whose contents do not match the manifest.
+	CorruptFiles []string `json:"corrupt-files"`
+}
+
+// Check that a backup is complete, i.e., that every file in its manifest exists and matches its checksum.
+// Files stored in other backups (for incremental backups) are checked in those backups,
+// which are expected to be in the same directory as this backup.
+// Returns an error if the backup cannot be read or has no manifest.
+func VerifyBackup(path string) (*VerifyResult, error) {
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("Backup '%s' does not have a manifest.", path)
+	}
+
+	result := &VerifyResult{
+		Name:           manifest.Name,
+		FileCount:      len(manifest.Files),
+		MissingBackups: make([]string, 0),
+		MissingFiles:   make([]string, 0),
+		CorruptFiles:   make([]string, 0),
+	}
+
+	backupsDir := filepath.Dir(path)
+
+	readers := make(map[string]*zip.ReadCloser)
+	defer func() {
+		for _, reader := range readers {
+			if reader != nil {
+				reader.Close()
+			}
+		}
+	}()
+
+	for relpath, entry := range manifest.Files {
+		reader, exists := readers[entry.Backup]
+		if !exists {
+			reader, err = zip.OpenReader(filepath.Join(backupsDir, entry.Backup+".zip"))
+			if err != nil {
+				reader = nil
+				result.MissingBackups = append(result.MissingBackups, entry.Backup)
+			}
+
+			readers[entry.Backup] = reader
+		}
+
+		if reader == nil {
+			result.MissingFiles = append(result.MissingFiles, relpath)
+			continue
+		}
+
+		file := findZipFile(&reader.Reader, entry.Backup, relpath)
+		if file == nil {
+			result.MissingFiles = append(result.MissingFiles, relpath)
+			continue
+		}
+
+		if !checkZipFile(file, entry) {
+			result.CorruptFiles = append(result.CorruptFiles, relpath)
+		}
+	}
+
+	slices.Sort(result.MissingBackups)
+	slices.Sort(result.MissingFiles)
+	slices.Sort(result.CorruptFiles)
+
+	result.Valid = ((len(result.MissingBackups) + len(result.MissingFiles) + len(result.CorruptFiles)) == 0)
+
+	return result, nil
+}
+
+func checkZipFile(file *zip.File, entry *ManifestEntry) bool {
+	if int64(file.UncompressedSize64) != entry.Size {
+		return false
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return false
+	}
+	defer reader.Close()
+
+	hash, err := util.Sha256HexFromReader(reader)
+	if err != nil {
+		return false
+	}
+
+	return (hash == entry.SHA256)
+}
@@ -0,0 +1,151 @@
+package backup
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestVerifyBackupBase(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	tempDir := util.MustMkDirTemp("autograder-test-course-backup-verify-")
+	defer util.RemoveDirent(tempDir)
+
+	result, err := BackupCourseWithOptions(db.MustGetTestCourse(), BackupOptions{Dest: tempDir, BackupID: "1", Verify: true})
+	if err != nil {
+		test.Fatalf("Failed to make backup: '%v'.", err)
+	}
+
+	if (result.Verification == nil) || !result.Verification.Valid {
+		test.Fatalf("Backup is not valid: '%s'.", util.MustToJSONIndent(result.Verification))
+	}
+
+	if result.Verification.FileCount != len(result.Manifest.Files) {
+		test.Fatalf("Unexpected file count. Expected: %d, Actual: %d.", len(result.Manifest.Files), result.Verification.FileCount)
+	}
+}
+
+func TestVerifyBackupMissingBackup(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	tempDir := util.MustMkDirTemp("autograder-test-course-backup-verify-")
+	defer util.RemoveDirent(tempDir)
+
+	course := db.MustGetTestCourse()
+
+	options := BackupOptions{
+		Dest:        tempDir,
+		BackupID:    "1",
+		Incremental: true,
+	}
+
+	baseResult, err := BackupCourseWithOptions(course, options)
+	if err != nil {
+		test.Fatalf("Failed to make base backup: '%v'.", err)
+	}
+
+	options.BackupID = "2"
+
+	result, err := BackupCourseWithOptions(course, options)
+	if err != nil {
+		test.Fatalf("Failed to make incremental backup: '%v'.", err)
+	}
+
+	util.RemoveDirent(baseResult.Path)
+
+	verification, err := VerifyBackup(result.Path)
+	if err != nil {
+		test.Fatalf("Failed to verify backup: '%v'.", err)
+	}
+
+	if verification.Valid {
+		test.Fatalf("Backup with a missing base backup is valid.")
+	}
+
+	if !reflect.DeepEqual([]string{"course101-1"}, verification.MissingBackups) {
+		test.Fatalf("Unexpected missing backups: '%v'.", verification.MissingBackups)
+	}
+
+	if len(verification.MissingFiles) == 0 {
+		test.Fatalf("Did not find any missing files.")
+	}
+}
+
+func TestVerifyBackupCorruptFile(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	tempDir := util.MustMkDirTemp("autograder-test-course-backup-verify-")
+	defer util.RemoveDirent(tempDir)
+
+	result, err := BackupCourseWithOptions(db.MustGetTestCourse(), BackupOptions{Dest: tempDir, BackupID: "1"})
+	if err != nil {
+		test.Fatalf("Failed to make backup: '%v'.", err)
+	}
+
+	// Change a file and rebuild the backup.
+	unzipDir := filepath.Join(tempDir, "unzipped")
+
+	err = util.Unzip(result.Path, unzipDir)
+	if err != nil {
+		test.Fatalf("Failed to unzip backup: '%v'.", err)
+	}
+
+	err = util.WriteFile("{}", filepath.Join(unzipDir, "course101-1", USERS_FILENAME))
+	if err != nil {
+		test.Fatalf("Failed to modify backup file: '%v'.", err)
+	}
+
+	util.RemoveDirent(result.Path)
+
+	err = util.Zip(filepath.Join(unzipDir, "course101-1"), result.Path, true)
+	if err != nil {
+		test.Fatalf("Failed to rezip backup: '%v'.", err)
+	}
+
+	verification, err := VerifyBackup(result.Path)
+	if err != nil {
+		test.Fatalf("Failed to verify backup: '%v'.", err)
+	}
+
+	if verification.Valid {
+		test.Fatalf("Corrupt backup is valid.")
+	}
+
+	if !reflect.DeepEqual([]string{USERS_FILENAME}, verification.CorruptFiles) {
+		test.Fatalf("Unexpected corrupt files: '%v'.", verification.CorruptFiles)
+	}
+}
+
+func TestVerifyBackupNoManifest(test *testing.T) {
+	tempDir := util.MustMkDirTemp("autograder-test-course-backup-verify-")
+	defer util.RemoveDirent(tempDir)
+
+	sourceDir := filepath.Join(tempDir, "course101-old")
+	err := util.MkDir(sourceDir)
+	if err != nil {
+		test.Fatalf("Failed to make backup dir: '%v'.", err)
+	}
+
+	err = util.WriteFile("{}", filepath.Join(sourceDir, "course.json"))
+	if err != nil {
+		test.Fatalf("Failed to write backup file: '%v'.", err)
+	}
+
+	path := filepath.Join(tempDir, "course101-old.zip")
+	err = util.Zip(sourceDir, path, true)
+	if err != nil {
+		test.Fatalf("Failed to zip backup: '%v'.", err)
+	}
+
+	_, err = VerifyBackup(path)
+	if err == nil {
+		test.Fatalf("Did not get an error when verifying a backup without a manifest.")
+	}
+}
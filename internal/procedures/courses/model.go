@@ -1,6 +1,7 @@
 package courses
 
 import (
+	"context"
 	"strings"
 
 	"github.com/edulinq/autograder/internal/model"
@@ -24,6 +25,9 @@ type CourseUpsertPublicOptions struct {
 type CourseUpsertOptions struct {
 	ContextUser *model.ServerUser `json:"-"`
 
+	// Any LMS calls will be traced as part of this context (if any).
+	Context context.Context `json:"-"`
+
 	CourseUpsertPublicOptions
 }
 
@@ -124,7 +124,7 @@ func syncLMS(course *model.Course, options CourseUpsertOptions, result *CourseUp
 		}()
 	}
 
-	lmsSyncResult, err := lmssync.SyncLMS(course, options.DryRun, !options.SkipEmails)
+	lmsSyncResult, err := lmssync.SyncLMS(options.Context, course, options.DryRun, !options.SkipEmails)
 	if err != nil {
 		return fmt.Errorf("Failed to sync course with LMS: '%w'.", err)
 	}
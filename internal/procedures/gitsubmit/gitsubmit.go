@@ -0,0 +1,213 @@
+// Submissions that come from students' git repos (e.g., GitHub Classroom).
+// Each student registers a repo for an assignment (see model.GitRepo),
+// and new commits are graded when the repo is pushed to (via a webhook) or polled.
+package gitsubmit
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/grader"
+	"github.com/edulinq/autograder/internal/lockmanager"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	REPO_DIRNAME   = "repo"
+	GIT_DIRNAME    = ".git"
+	SHORT_SHA_SIZE = 8
+)
+
+// Grade the latest commit of a user's registered repo (on the assignment's branch).
+// If |commitSHA| is not empty, then the repo must be at that commit (e.g., the commit from a push).
+// Commits that have already been graded for this repo are skipped.
+// Returns (the outcome (nil if no grading happened), error).
+func GradeRepo(ctx context.Context, assignment *model.Assignment, repo *model.GitRepo, commitSHA string) (*grader.SubmissionOutcome, error) {
+	if assignment.GitSubmission == nil {
+		return nil, fmt.Errorf("Assignment '%s' does not accept git submissions.", assignment.FullID())
+	}
+
+	// Make sure the same repo is not being graded multiple times at once (e.g., from a poll and a push).
+	lockKey := fmt.Sprintf("git-submit::%s::%s::%s", assignment.GetCourse().GetID(), assignment.GetID(), repo.User)
+	lockmanager.Lock(lockKey)
+	defer lockmanager.Unlock(lockKey)
+
+	// Get a fresh copy of the repo in case it was updated while waiting for the lock.
+	repo, err := db.GetGitRepo(assignment, repo.User)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get git repo: '%w'.", err)
+	}
+
+	if repo == nil {
+		return nil, nil
+	}
+
+	if (commitSHA != "") && (commitSHA == repo.LastCommit) {
+		return nil, nil
+	}
+
+	tempDir, err := util.MkDirTemp("git-submission-")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create temp dir: '%w'.", err)
+	}
+	defer util.RemoveDirent(tempDir)
+
+	submissionDir := filepath.Join(tempDir, REPO_DIRNAME)
+
+	commitSHA, message, err := fetchCommit(assignment.GitSubmission, repo, submissionDir, commitSHA)
+	if err != nil {
+		return nil, err
+	}
+
+	if commitSHA == repo.LastCommit {
+		return nil, nil
+	}
+
+	// The repo's history is not a part of the submission.
+	err = util.RemoveDirent(filepath.Join(submissionDir, GIT_DIRNAME))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to remove git dir from submission: '%w'.", err)
+	}
+
+	log.Debug("Grading git submission.", assignment, log.NewUserAttr(repo.User), log.NewAttr("commit", commitSHA))
+
+	options := grader.GetDefaultGradeOptions()
+	options.CommitSHA = commitSHA
+
+	outcome := grader.GradeSubmission(ctx, assignment, submissionDir, repo.User, message, options,
+		assignment, log.NewUserAttr(repo.User), log.NewAttr("commit", commitSHA))
+
+	// Record the commit even if it was rejected, so the same commit will not keep getting submitted.
+	now := timestamp.Now()
+	repo.LastCommit = commitSHA
+	repo.LastCommitTime = &now
+
+	err = db.SaveGitRepo(assignment, repo)
+	if err != nil {
+		return &outcome, fmt.Errorf("Failed to save git repo: '%w'.", err)
+	}
+
+	return &outcome, nil
+}
+
+// Grade any new commits for all the registered repos of an assignment.
+// Failures for individual repos are logged and do not stop other repos from being graded.
+// Returns the number of commits graded.
+func PollAssignment(ctx context.Context, assignment *model.Assignment) (int, error) {
+	if assignment.GitSubmission == nil {
+		return 0, nil
+	}
+
+	repos, err := db.GetGitRepos(assignment)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get git repos for assignment '%s': '%w'.", assignment.FullID(), err)
+	}
+
+	count := 0
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			break
+		}
+
+		outcome, err := GradeRepo(ctx, assignment, repo, "")
+		if err != nil {
+			log.Warn("Failed to grade git repo.", err, assignment, log.NewUserAttr(repo.User), log.NewAttr("url", repo.URL))
+			continue
+		}
+
+		if outcome != nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Poll all the assignments in a course that accept git submissions.
+// Returns the number of commits graded.
+func PollCourse(ctx context.Context, course *model.Course) (int, error) {
+	count := 0
+	for _, assignment := range course.GetSortedAssignments() {
+		assignmentCount, err := PollAssignment(ctx, assignment)
+		if err != nil {
+			return count, err
+		}
+
+		count += assignmentCount
+	}
+
+	return count, nil
+}
+
+// Get the repos that a push to |ref| of the repo at |url| is relevant to.
+// Pushes to branches other than the assignment's branch are ignored
+// (when the assignment has no branch, only pushes to branches are relevant).
+func GetPushedRepos(assignment *model.Assignment, url string, ref string) ([]*model.GitRepo, error) {
+	if assignment.GitSubmission == nil {
+		return nil, fmt.Errorf("Assignment '%s' does not accept git submissions.", assignment.FullID())
+	}
+
+	refName := plumbing.ReferenceName(ref)
+	if !refName.IsBranch() {
+		return make([]*model.GitRepo, 0), nil
+	}
+
+	branch := assignment.GitSubmission.Branch
+	if (branch != "") && (refName.Short() != branch) {
+		return make([]*model.GitRepo, 0), nil
+	}
+
+	return db.GetGitReposByURL(assignment, url)
+}
+
+// Clone a repo into |path| and checkout the target commit.
+// Returns (the commit's SHA, the first line of the commit message, error).
+func fetchCommit(options *model.GitSubmissionOptions, repo *model.GitRepo, path string, commitSHA string) (string, string, error) {
+	ref := commitSHA
+	if ref == "" {
+		ref = options.Branch
+	}
+
+	// Never send the assignment's credentials to a host it was not configured for.
+	username := ""
+	token := ""
+	if options.CanSendCredentials(repo.URL) {
+		username = options.Username
+		token = options.Token
+	}
+
+	gitRepo, err := util.GitEnsureRepo(repo.URL, path, false, ref, username, token)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to fetch git repo '%s': '%w'.", repo.URL, err)
+	}
+
+	head, err := gitRepo.Head()
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to get head of git repo '%s': '%w'.", repo.URL, err)
+	}
+
+	hash := head.Hash().String()
+	if (commitSHA != "") && (hash != commitSHA) {
+		return "", "", fmt.Errorf("Git repo '%s' is at commit '%s', expected '%s'.", repo.URL, hash, commitSHA)
+	}
+
+	message := fmt.Sprintf("Git commit %s.", hash[:min(SHORT_SHA_SIZE, len(hash))])
+
+	commit, err := gitRepo.CommitObject(head.Hash())
+	if err == nil {
+		summary, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		if summary != "" {
+			message = fmt.Sprintf("%s %s", message, summary)
+		}
+	}
+
+	return hash, message, nil
+}
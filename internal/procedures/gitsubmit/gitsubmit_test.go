@@ -0,0 +1,245 @@
+package gitsubmit
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/grader"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const TEST_USER = "course-student@test.edulinq.org"
+
+func TestGradeRepoBase(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	assignment, testRepo, repo := mustPrepTestRepo(test, nil)
+	defer testRepo.Close()
+
+	firstSHA := mustGetHead(test, testRepo)
+
+	// Grade the initial commit.
+	outcome, err := GradeRepo(context.Background(), assignment, repo, "")
+	if err != nil {
+		test.Fatalf("Failed to grade repo: '%v'.", err)
+	}
+
+	mustCheckOutcome(test, assignment, outcome, firstSHA)
+
+	// The same commit is not graded again.
+	outcome, err = GradeRepo(context.Background(), assignment, repo, "")
+	if err != nil {
+		test.Fatalf("Failed to grade unchanged repo: '%v'.", err)
+	}
+
+	if outcome != nil {
+		test.Fatalf("Got an outcome for an already graded commit: '%s'.", util.MustToJSONIndent(outcome))
+	}
+
+	outcome, err = GradeRepo(context.Background(), assignment, repo, firstSHA)
+	if err != nil {
+		test.Fatalf("Failed to grade already graded commit: '%v'.", err)
+	}
+
+	if outcome != nil {
+		test.Fatalf("Got an outcome for an already graded specific commit: '%s'.", util.MustToJSONIndent(outcome))
+	}
+
+	// New commits are picked up by polling.
+	secondSHA, err := testRepo.Commit("Second commit.")
+	if err != nil {
+		test.Fatalf("Failed to make second commit: '%v'.", err)
+	}
+
+	count, err := PollCourse(context.Background(), assignment.GetCourse())
+	if err != nil {
+		test.Fatalf("Failed to poll course: '%v'.", err)
+	}
+
+	if count != 1 {
+		test.Fatalf("Unexpected poll count. Expected: 1, Actual: %d.", count)
+	}
+
+	repo, err = db.GetGitRepo(assignment, TEST_USER)
+	if err != nil {
+		test.Fatalf("Failed to get git repo: '%v'.", err)
+	}
+
+	if repo.LastCommit != secondSHA {
+		test.Fatalf("Unexpected last commit after poll. Expected: '%s', Actual: '%s'.", secondSHA, repo.LastCommit)
+	}
+
+	count, err = PollAssignment(context.Background(), assignment)
+	if err != nil {
+		test.Fatalf("Failed to poll assignment: '%v'.", err)
+	}
+
+	if count != 0 {
+		test.Fatalf("Unexpected second poll count. Expected: 0, Actual: %d.", count)
+	}
+
+	// An older commit can be specifically requested.
+	outcome, err = GradeRepo(context.Background(), assignment, repo, firstSHA)
+	if err != nil {
+		test.Fatalf("Failed to grade older commit: '%v'.", err)
+	}
+
+	mustCheckOutcome(test, assignment, outcome, firstSHA)
+
+	// A commit that does not exist is an error.
+	_, err = GradeRepo(context.Background(), assignment, repo, "0123456789012345678901234567890123456789")
+	if err == nil {
+		test.Fatalf("Did not get an error when grading an unknown commit.")
+	}
+}
+
+func TestGradeRepoNoGitSubmission(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	repo := &model.GitRepo{User: TEST_USER, URL: "https://github.com/test/repo"}
+
+	_, err := GradeRepo(context.Background(), assignment, repo, "")
+	if err == nil {
+		test.Fatalf("Did not get an error when grading an assignment without git submissions.")
+	}
+
+	count, err := PollAssignment(context.Background(), assignment)
+	if err != nil {
+		test.Fatalf("Failed to poll assignment: '%v'.", err)
+	}
+
+	if count != 0 {
+		test.Fatalf("Unexpected poll count. Expected: 0, Actual: %d.", count)
+	}
+}
+
+func TestSubmitPush(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	assignment, testRepo, _ := mustPrepTestRepo(test, &model.GitSubmissionOptions{Branch: "master"})
+	defer testRepo.Close()
+
+	sha := mustGetHead(test, testRepo)
+
+	testCases := []struct {
+		url           string
+		ref           string
+		after         string
+		deleted       bool
+		expectedUsers []string
+	}{
+		{"https://github.com/test/other.git", "refs/heads/master", sha, false, []string{}},
+		{testRepo.URL, "refs/heads/other", sha, false, []string{}},
+		{testRepo.URL, "refs/tags/v1", sha, false, []string{}},
+		{testRepo.URL, "refs/heads/master", "0000000000000000000000000000000000000000", true, []string{}},
+		{testRepo.URL, "refs/heads/master", sha, false, []string{TEST_USER}},
+	}
+
+	for i, testCase := range testCases {
+		event := &PushEvent{
+			Ref:     testCase.ref,
+			After:   testCase.after,
+			Deleted: testCase.deleted,
+		}
+		event.Repository.CloneURL = testCase.url
+
+		users, err := SubmitPush(assignment, event)
+		if err != nil {
+			test.Errorf("Case %d: Failed to submit push: '%v'.", i, err)
+			continue
+		}
+
+		WaitForPushes()
+
+		if !reflect.DeepEqual(testCase.expectedUsers, users) {
+			test.Errorf("Case %d: Unexpected users. Expected: '%v', Actual: '%v'.", i, testCase.expectedUsers, users)
+			continue
+		}
+	}
+
+	repo, err := db.GetGitRepo(assignment, TEST_USER)
+	if err != nil {
+		test.Fatalf("Failed to get git repo: '%v'.", err)
+	}
+
+	if repo.LastCommit != sha {
+		test.Fatalf("Unexpected last commit after push. Expected: '%s', Actual: '%s'.", sha, repo.LastCommit)
+	}
+}
+
+func mustPrepTestRepo(test *testing.T, options *model.GitSubmissionOptions) (*model.Assignment, *TestRepo, *model.GitRepo) {
+	assignment, err := EnableTestAssignment(options)
+	if err != nil {
+		test.Fatalf("Failed to enable git submissions: '%v'.", err)
+	}
+
+	testRepo, err := NewTestRepo()
+	if err != nil {
+		test.Fatalf("Failed to create test repo: '%v'.", err)
+	}
+
+	repo := &model.GitRepo{
+		CourseID:     assignment.GetCourse().GetID(),
+		AssignmentID: assignment.GetID(),
+		User:         TEST_USER,
+		URL:          testRepo.URL,
+		CreatedBy:    TEST_USER,
+		UpdateTime:   timestamp.Now(),
+	}
+
+	err = db.SaveGitRepo(assignment, repo)
+	if err != nil {
+		testRepo.Close()
+		test.Fatalf("Failed to save git repo: '%v'.", err)
+	}
+
+	return assignment, testRepo, repo
+}
+
+func mustGetHead(test *testing.T, testRepo *TestRepo) string {
+	sha, err := util.GitGetCommitHash(testRepo.Dir)
+	if err != nil {
+		test.Fatalf("Failed to get test repo head: '%v'.", err)
+	}
+
+	return sha
+}
+
+func mustCheckOutcome(test *testing.T, assignment *model.Assignment, outcome *grader.SubmissionOutcome, expectedSHA string) {
+	if outcome == nil {
+		test.Fatalf("Did not get an outcome.")
+	}
+
+	if !outcome.GradingSuccess {
+		test.Fatalf("Grading was not successful: '%s'.", util.MustToJSONIndent(outcome))
+	}
+
+	result, err := db.GetSubmissionContents(assignment, TEST_USER, outcome.GradingInfo.ID)
+	if err != nil {
+		test.Fatalf("Failed to get submission: '%v'.", err)
+	}
+
+	if result.Info.CommitSHA != expectedSHA {
+		test.Fatalf("Unexpected commit SHA. Expected: '%s', Actual: '%s'.", expectedSHA, result.Info.CommitSHA)
+	}
+
+	if result.Info.Score != result.Info.MaxPoints {
+		test.Fatalf("Submission did not get a full score: '%s'.", util.MustToJSONIndent(result.Info))
+	}
+
+	// The repo's history should not be a part of the submission.
+	for relpath := range result.InputFilesGZip {
+		if strings.HasPrefix(relpath, GIT_DIRNAME) {
+			test.Fatalf("Found the git dir in the submission: '%s'.", relpath)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package gitsubmit
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// The relative path (inside the test submission assignment) to a submission that gets a perfect score.
+var TEST_SUBMISSION_RELPATH string = filepath.Join("test-submissions", "solution", "assignment.sh")
+
+// A local git repo that can be used as a student repo in testing.
+type TestRepo struct {
+	Dir string
+	URL string
+
+	repo *git.Repository
+}
+
+// Create a local repo with an initial commit of the test submission (see db.MustGetTestSubmissionAssignment()).
+// Callers should call Close() when done.
+func NewTestRepo() (*TestRepo, error) {
+	dir, err := util.MkDirTemp("test-git-submission-")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to make temp dir: '%w'.", err)
+	}
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		util.RemoveDirent(dir)
+		return nil, fmt.Errorf("Failed to init test repo: '%w'.", err)
+	}
+
+	testRepo := &TestRepo{
+		Dir:  dir,
+		URL:  "file://" + dir,
+		repo: repo,
+	}
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	sourcePath := filepath.Join(assignment.GetSourceDir(), TEST_SUBMISSION_RELPATH)
+
+	err = util.CopyFile(sourcePath, filepath.Join(dir, filepath.Base(sourcePath)))
+	if err != nil {
+		testRepo.Close()
+		return nil, fmt.Errorf("Failed to copy test submission: '%w'.", err)
+	}
+
+	_, err = testRepo.Commit("Initial commit.")
+	if err != nil {
+		testRepo.Close()
+		return nil, err
+	}
+
+	return testRepo, nil
+}
+
+// Commit everything in the repo and return the commit's SHA.
+func (this *TestRepo) Commit(message string) (string, error) {
+	tree, err := this.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("Failed to get test repo worktree: '%w'.", err)
+	}
+
+	err = tree.AddGlob(".")
+	if err != nil {
+		return "", fmt.Errorf("Failed to add files to test repo: '%w'.", err)
+	}
+
+	options := &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author: &object.Signature{
+			Name:  "Test",
+			Email: "test@test.edulinq.org",
+			When:  time.Now(),
+		},
+	}
+
+	hash, err := tree.Commit(message, options)
+	if err != nil {
+		return "", fmt.Errorf("Failed to commit to test repo: '%w'.", err)
+	}
+
+	return hash.String(), nil
+}
+
+func (this *TestRepo) Close() {
+	util.RemoveDirent(this.Dir)
+}
+
+// Enable git submissions for the test submission assignment.
+// The assignment's due date is also cleared so submissions are not rejected as late.
+func EnableTestAssignment(options *model.GitSubmissionOptions) (*model.Assignment, error) {
+	if options == nil {
+		options = &model.GitSubmissionOptions{}
+	}
+
+	assignment := db.MustGetTestSubmissionAssignment()
+	assignment.GitSubmission = options
+	assignment.DueDate = nil
+
+	err := db.SaveAssignment(assignment)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to save assignment: '%w'.", err)
+	}
+
+	return assignment, nil
+}
@@ -0,0 +1,126 @@
+package gitsubmit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+)
+
+const (
+	SIGNATURE_HEADER = "X-Hub-Signature-256"
+	EVENT_HEADER     = "X-GitHub-Event"
+	SIGNATURE_PREFIX = "sha256="
+
+	EVENT_PING = "ping"
+	EVENT_PUSH = "push"
+)
+
+// The parts of a (GitHub-style) push webhook payload that we care about.
+type PushEvent struct {
+	Ref     string `json:"ref"`
+	After   string `json:"after"`
+	Deleted bool   `json:"deleted"`
+
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+		HTMLURL  string `json:"html_url"`
+		SSHURL   string `json:"ssh_url"`
+		GitURL   string `json:"git_url"`
+	} `json:"repository"`
+}
+
+// Pushes are graded in the background.
+var pushGradings sync.WaitGroup
+
+// Get all the (non-empty) URLs that the pushed repo is known by.
+func (this *PushEvent) GetURLs() []string {
+	urls := make([]string, 0, 4)
+	for _, url := range []string{this.Repository.CloneURL, this.Repository.HTMLURL, this.Repository.SSHURL, this.Repository.GitURL} {
+		if url != "" {
+			urls = append(urls, url)
+		}
+	}
+
+	return urls
+}
+
+// Check if this push points to an actual commit (and not a deleted ref).
+func (this *PushEvent) HasCommit() bool {
+	return !this.Deleted && (strings.Trim(this.After, "0") != "")
+}
+
+// Check a webhook's signature ("sha256=<hex HMAC-SHA256 of the body>").
+func VerifySignature(secret string, body []byte, signature string) bool {
+	if (secret == "") || !strings.HasPrefix(signature, SIGNATURE_PREFIX) {
+		return false
+	}
+
+	actual, err := hex.DecodeString(strings.TrimPrefix(signature, SIGNATURE_PREFIX))
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(actual, ComputeSignature(secret, body))
+}
+
+// Compute the raw HMAC-SHA256 of a webhook body.
+func ComputeSignature(secret string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// Start grading the registered repos that a push is relevant to.
+// Grading happens in the background (see WaitForPushes()).
+// Returns the (sorted) users whose repos will be graded.
+func SubmitPush(assignment *model.Assignment, event *PushEvent) ([]string, error) {
+	users := make([]string, 0)
+
+	if !event.HasCommit() {
+		return users, nil
+	}
+
+	repos := make(map[string]*model.GitRepo)
+	for _, url := range event.GetURLs() {
+		matches, err := GetPushedRepos(assignment, url, event.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get pushed repos: '%w'.", err)
+		}
+
+		for _, repo := range matches {
+			repos[repo.User] = repo
+		}
+	}
+
+	for user, repo := range repos {
+		users = append(users, user)
+
+		pushGradings.Add(1)
+		go func() {
+			defer pushGradings.Done()
+
+			// Pushes are not tied to any request, so they should not be canceled.
+			_, err := GradeRepo(context.Background(), assignment, repo, event.After)
+			if err != nil {
+				log.Warn("Failed to grade pushed git repo.", err, assignment, log.NewUserAttr(user), log.NewAttr("commit", event.After))
+			}
+		}()
+	}
+
+	slices.Sort(users)
+
+	return users, nil
+}
+
+// Wait for all pushes that are being graded to finish.
+func WaitForPushes() {
+	pushGradings.Wait()
+}
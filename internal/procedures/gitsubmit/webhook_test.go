@@ -0,0 +1,35 @@
+package gitsubmit
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestVerifySignature(test *testing.T) {
+	body := []byte(`{"ref": "refs/heads/main"}`)
+	signature := SIGNATURE_PREFIX + hex.EncodeToString(ComputeSignature("secret", body))
+
+	testCases := []struct {
+		secret    string
+		body      []byte
+		signature string
+		expected  bool
+	}{
+		{"secret", body, signature, true},
+
+		{"other", body, signature, false},
+		{"secret", []byte(`{}`), signature, false},
+		{"secret", body, hex.EncodeToString(ComputeSignature("secret", body)), false},
+		{"secret", body, SIGNATURE_PREFIX + "ZZZ", false},
+		{"secret", body, "", false},
+		{"", body, SIGNATURE_PREFIX + hex.EncodeToString(ComputeSignature("", body)), false},
+	}
+
+	for i, testCase := range testCases {
+		actual := VerifySignature(testCase.secret, testCase.body, testCase.signature)
+		if testCase.expected != actual {
+			test.Errorf("Case %d: Unexpected result. Expected: '%v', Actual: '%v'.", i, testCase.expected, actual)
+			continue
+		}
+	}
+}
@@ -0,0 +1,761 @@
+// Move all the data in one database backend into another.
+// Migrations are incremental: data already in the target is left alone (or overwritten with the same content),
+// so an interrupted migration can be resumed by running it again.
+// This also allows a migration to be run while a server is still using the source database,
+// followed by a final (quick) run after the server has been stopped.
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	CATEGORY_USERS               = "users"
+	CATEGORY_COURSES             = "courses"
+	CATEGORY_SUBMISSIONS         = "submissions"
+	CATEGORY_MANUAL_GRADES       = "manual-grades"
+	CATEGORY_EXTENSIONS          = "extensions"
+	CATEGORY_GROUPS              = "groups"
+	CATEGORY_GIT_REPOS           = "git-repos"
+	CATEGORY_LMS_COMMENTS        = "lms-comments"
+	CATEGORY_ANALYSIS_INDIVIDUAL = "analysis-individual"
+	CATEGORY_ANALYSIS_PAIRWISE   = "analysis-pairwise"
+	CATEGORY_TASKS               = "tasks"
+	CATEGORY_LOGS                = "logs"
+	CATEGORY_METRICS             = "metrics"
+)
+
+// Analysis files in a course dump (all backends dump in the same layout as the disk backend).
+const (
+	DUMP_ANALYSIS_INDIVIDUAL_FILENAME = "analysis-individual.jsonl"
+	DUMP_ANALYSIS_PAIRWISE_FILENAME   = "analysis-pairwise.jsonl"
+)
+
+// How many records (e.g., log records) were copied into the target or skipped because they were already there.
+type Counts struct {
+	Copied  int `json:"copied"`
+	Skipped int `json:"skipped"`
+}
+
+// Counts keyed by category.
+type MigrationResult map[string]*Counts
+
+// The contents of a course that cannot be directly listed through a db.Backend.
+type courseContents struct {
+	// Emails of all users with submissions, keyed by assignment ID.
+	submissionOwners map[string][]string
+
+	manualGrades []*model.ManualGrade
+
+	lmsComments []*model.LMSComment
+
+	individualAnalysis []*model.IndividualAnalysis
+	pairwiseAnalysis   []*model.PairwiseAnalysis
+}
+
+// Open the two backends and migrate from one to the other.
+func MigrateTypes(sourceType string, targetType string) (MigrationResult, error) {
+	source, target, err := openBackends(sourceType, targetType)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+	defer target.Close()
+
+	return Migrate(source, target)
+}
+
+// Copy all data from the source into the target.
+// Nothing is removed from the source.
+func Migrate(source db.Backend, target db.Backend) (MigrationResult, error) {
+	result := MigrationResult{
+		CATEGORY_USERS:               &Counts{},
+		CATEGORY_COURSES:             &Counts{},
+		CATEGORY_SUBMISSIONS:         &Counts{},
+		CATEGORY_MANUAL_GRADES:       &Counts{},
+		CATEGORY_EXTENSIONS:          &Counts{},
+		CATEGORY_GROUPS:              &Counts{},
+		CATEGORY_GIT_REPOS:           &Counts{},
+		CATEGORY_LMS_COMMENTS:        &Counts{},
+		CATEGORY_ANALYSIS_INDIVIDUAL: &Counts{},
+		CATEGORY_ANALYSIS_PAIRWISE:   &Counts{},
+		CATEGORY_TASKS:               &Counts{},
+		CATEGORY_LOGS:                &Counts{},
+		CATEGORY_METRICS:             &Counts{},
+	}
+
+	// Users go first, since course operations may look at course users.
+	err := migrateUsers(source, target, result[CATEGORY_USERS])
+	if err != nil {
+		return result, err
+	}
+
+	courses, err := source.GetCourses()
+	if err != nil {
+		return result, fmt.Errorf("Failed to get source courses: '%w'.", err)
+	}
+
+	for _, courseID := range sortedKeys(courses) {
+		err = migrateCourse(source, target, courses[courseID], result)
+		if err != nil {
+			return result, fmt.Errorf("Failed to migrate course '%s': '%w'.", courseID, err)
+		}
+
+		log.Info("Migrated course.", courses[courseID])
+	}
+
+	err = migrateTasks(source, target, result[CATEGORY_TASKS])
+	if err != nil {
+		return result, err
+	}
+
+	err = migrateLogs(source, target, result[CATEGORY_LOGS])
+	if err != nil {
+		return result, err
+	}
+
+	for _, metricType := range stats.GetKnownMetricTypes() {
+		err = migrateMetrics(source, target, metricType, result[CATEGORY_METRICS])
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func openBackends(sourceType string, targetType string) (db.Backend, db.Backend, error) {
+	// Backends of the same type are configured the same way, so they would be the same database.
+	if sourceType == targetType {
+		return nil, nil, fmt.Errorf("Source and target databases must be different types, found '%s' for both.", sourceType)
+	}
+
+	source, err := db.OpenBackend(sourceType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to open source database: '%w'.", err)
+	}
+
+	target, err := db.OpenBackend(targetType)
+	if err != nil {
+		source.Close()
+		return nil, nil, fmt.Errorf("Failed to open target database: '%w'.", err)
+	}
+
+	return source, target, nil
+}
+
+// Users are copied over if they differ in any way (including tokens).
+func migrateUsers(source db.Backend, target db.Backend, counts *Counts) error {
+	sourceUsers, err := source.GetServerUsers()
+	if err != nil {
+		return fmt.Errorf("Failed to get source users: '%w'.", err)
+	}
+
+	targetUsers, err := target.GetServerUsers()
+	if err != nil {
+		return fmt.Errorf("Failed to get target users: '%w'.", err)
+	}
+
+	for _, email := range sortedKeys(sourceUsers) {
+		same, err := sameJSON(sourceUsers[email], targetUsers[email])
+		if err != nil {
+			return fmt.Errorf("Failed to compare user '%s': '%w'.", email, err)
+		}
+
+		if same {
+			counts.Skipped++
+			continue
+		}
+
+		// Upserting merges users, so remove any stale version first.
+		if targetUsers[email] != nil {
+			err = target.DeleteUser(email)
+			if err != nil {
+				return fmt.Errorf("Failed to remove stale target user '%s': '%w'.", email, err)
+			}
+		}
+
+		err = target.UpsertUsers(map[string]*model.ServerUser{email: sourceUsers[email]})
+		if err != nil {
+			return fmt.Errorf("Failed to save target user '%s': '%w'.", email, err)
+		}
+
+		counts.Copied++
+	}
+
+	return nil
+}
+
+func migrateCourse(source db.Backend, target db.Backend, course *model.Course, result MigrationResult) error {
+	err := target.SaveCourse(course)
+	if err != nil {
+		return fmt.Errorf("Failed to save target course: '%w'.", err)
+	}
+
+	result[CATEGORY_COURSES].Copied++
+
+	contents, err := getCourseContents(source, course)
+	if err != nil {
+		return err
+	}
+
+	for _, assignmentID := range sortedKeys(contents.submissionOwners) {
+		assignment := course.GetAssignment(assignmentID)
+
+		for _, email := range contents.submissionOwners[assignmentID] {
+			err = migrateSubmissions(source, target, assignment, email, result[CATEGORY_SUBMISSIONS])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Manual grades can only be saved after the submissions they are for.
+	for _, grade := range contents.manualGrades {
+		err = migrateManualGrade(target, course, grade, result[CATEGORY_MANUAL_GRADES])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = migrateExtensions(source, target, course, result[CATEGORY_EXTENSIONS])
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range course.GetSortedAssignments() {
+		err = migrateGroups(source, target, assignment, result[CATEGORY_GROUPS])
+		if err != nil {
+			return err
+		}
+
+		err = migrateGitRepos(source, target, assignment, result[CATEGORY_GIT_REPOS])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = migrateLMSComments(target, course, contents.lmsComments, result[CATEGORY_LMS_COMMENTS])
+	if err != nil {
+		return err
+	}
+
+	// Analysis results are keyed, so storing them again just overwrites them.
+	err = target.StoreIndividualAnalysis(contents.individualAnalysis)
+	if err != nil {
+		return fmt.Errorf("Failed to store target individual analysis: '%w'.", err)
+	}
+
+	result[CATEGORY_ANALYSIS_INDIVIDUAL].Copied += len(contents.individualAnalysis)
+
+	err = target.StorePairwiseAnalysis(contents.pairwiseAnalysis)
+	if err != nil {
+		return fmt.Errorf("Failed to store target pairwise analysis: '%w'.", err)
+	}
+
+	result[CATEGORY_ANALYSIS_PAIRWISE].Copied += len(contents.pairwiseAnalysis)
+
+	return nil
+}
+
+// Copy over any submissions that the target does not already have.
+// Submissions (including their gzipped input/output files) are copied as-is, they are not re-read from disk.
+func migrateSubmissions(source db.Backend, target db.Backend, assignment *model.Assignment, email string, counts *Counts) error {
+	history, err := target.GetSubmissionHistory(assignment, email)
+	if err != nil {
+		return fmt.Errorf("Failed to get target submission history for '%s' on '%s': '%w'.", email, assignment.FullID(), err)
+	}
+
+	existingIDs := make(map[string]bool, len(history))
+	for _, item := range history {
+		existingIDs[item.ShortID] = true
+	}
+
+	attempts, err := source.GetSubmissionAttempts(assignment, email)
+	if err != nil {
+		return fmt.Errorf("Failed to get source submissions for '%s' on '%s': '%w'.", email, assignment.FullID(), err)
+	}
+
+	missing := make([]*model.GradingResult, 0, len(attempts))
+	for _, attempt := range attempts {
+		if existingIDs[attempt.Info.ShortID] {
+			counts.Skipped++
+		} else {
+			missing = append(missing, attempt)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	err = target.SaveSubmissions(assignment.GetCourse(), missing)
+	if err != nil {
+		return fmt.Errorf("Failed to save target submissions for '%s' on '%s': '%w'.", email, assignment.FullID(), err)
+	}
+
+	counts.Copied += len(missing)
+
+	return nil
+}
+
+// Manual grades are copied over if they differ in any way.
+func migrateManualGrade(target db.Backend, course *model.Course, grade *model.ManualGrade, counts *Counts) error {
+	assignment := course.GetAssignment(grade.AssignmentID)
+	if assignment == nil {
+		return fmt.Errorf("Manual grade '%s' is for an unknown assignment.", grade.ID)
+	}
+
+	targetGrade, err := target.GetManualGrade(assignment, grade.User, grade.ShortID)
+	if err != nil {
+		return fmt.Errorf("Failed to get target manual grade '%s': '%w'.", grade.ID, err)
+	}
+
+	if targetGrade != nil {
+		same, err := sameJSON(grade, targetGrade)
+		if err != nil {
+			return fmt.Errorf("Failed to compare manual grade '%s': '%w'.", grade.ID, err)
+		}
+
+		if same {
+			counts.Skipped++
+			return nil
+		}
+	}
+
+	err = target.SaveManualGrade(grade)
+	if err != nil {
+		return fmt.Errorf("Failed to save target manual grade '%s': '%w'.", grade.ID, err)
+	}
+
+	counts.Copied++
+
+	return nil
+}
+
+// Extensions are copied over if they differ in any way.
+func migrateExtensions(source db.Backend, target db.Backend, course *model.Course, counts *Counts) error {
+	sourceExtensions, err := source.GetExtensions(course.GetID())
+	if err != nil {
+		return fmt.Errorf("Failed to get source extensions: '%w'.", err)
+	}
+
+	targetExtensions, err := target.GetExtensions(course.GetID())
+	if err != nil {
+		return fmt.Errorf("Failed to get target extensions: '%w'.", err)
+	}
+
+	existing := make(map[string]*model.Extension, len(targetExtensions))
+	for _, extension := range targetExtensions {
+		existing[getExtensionKey(extension)] = extension
+	}
+
+	for _, extension := range sourceExtensions {
+		key := getExtensionKey(extension)
+
+		same, err := sameJSON(extension, existing[key])
+		if err != nil {
+			return fmt.Errorf("Failed to compare extension '%s': '%w'.", key, err)
+		}
+
+		if same {
+			counts.Skipped++
+			continue
+		}
+
+		err = target.SaveExtension(extension)
+		if err != nil {
+			return fmt.Errorf("Failed to save target extension '%s': '%w'.", key, err)
+		}
+
+		counts.Copied++
+	}
+
+	return nil
+}
+
+// Groups are copied over if they differ in any way.
+func migrateGroups(source db.Backend, target db.Backend, assignment *model.Assignment, counts *Counts) error {
+	sourceGroups, err := source.GetGroups(assignment)
+	if err != nil {
+		return fmt.Errorf("Failed to get source groups for '%s': '%w'.", assignment.FullID(), err)
+	}
+
+	targetGroups, err := target.GetGroups(assignment)
+	if err != nil {
+		return fmt.Errorf("Failed to get target groups for '%s': '%w'.", assignment.FullID(), err)
+	}
+
+	existing := make(map[string]*model.Group, len(targetGroups))
+	for _, group := range targetGroups {
+		existing[group.ID] = group
+	}
+
+	for _, group := range sourceGroups {
+		same, err := sameJSON(group, existing[group.ID])
+		if err != nil {
+			return fmt.Errorf("Failed to compare group '%s' for '%s': '%w'.", group.ID, assignment.FullID(), err)
+		}
+
+		if same {
+			counts.Skipped++
+			continue
+		}
+
+		err = target.SaveGroup(assignment, group)
+		if err != nil {
+			return fmt.Errorf("Failed to save target group '%s' for '%s': '%w'.", group.ID, assignment.FullID(), err)
+		}
+
+		counts.Copied++
+	}
+
+	return nil
+}
+
+// Git repos are copied over if they differ in any way.
+func migrateGitRepos(source db.Backend, target db.Backend, assignment *model.Assignment, counts *Counts) error {
+	sourceRepos, err := source.GetGitRepos(assignment)
+	if err != nil {
+		return fmt.Errorf("Failed to get source git repos for '%s': '%w'.", assignment.FullID(), err)
+	}
+
+	targetRepos, err := target.GetGitRepos(assignment)
+	if err != nil {
+		return fmt.Errorf("Failed to get target git repos for '%s': '%w'.", assignment.FullID(), err)
+	}
+
+	for _, repo := range sourceRepos {
+		same, err := sameJSON(repo, model.GetUserGitRepo(targetRepos, repo.User))
+		if err != nil {
+			return fmt.Errorf("Failed to compare git repo for '%s' on '%s': '%w'.", repo.User, assignment.FullID(), err)
+		}
+
+		if same {
+			counts.Skipped++
+			continue
+		}
+
+		err = target.SaveGitRepo(assignment, repo)
+		if err != nil {
+			return fmt.Errorf("Failed to save target git repo for '%s' on '%s': '%w'.", repo.User, assignment.FullID(), err)
+		}
+
+		counts.Copied++
+	}
+
+	return nil
+}
+
+// LMS comments are copied over if they differ in any way.
+func migrateLMSComments(target db.Backend, course *model.Course, comments []*model.LMSComment, counts *Counts) error {
+	// Comments can only be fetched per LMS assignment.
+	existing := make(map[string]map[string]*model.LMSComment)
+
+	for _, comment := range comments {
+		_, exists := existing[comment.AssignmentLMSID]
+		if !exists {
+			targetComments, err := target.GetLMSComments(course.GetID(), comment.AssignmentLMSID)
+			if err != nil {
+				return fmt.Errorf("Failed to get target LMS comments for LMS assignment '%s': '%w'.", comment.AssignmentLMSID, err)
+			}
+
+			existing[comment.AssignmentLMSID] = make(map[string]*model.LMSComment, len(targetComments))
+			for _, targetComment := range targetComments {
+				existing[comment.AssignmentLMSID][targetComment.UserLMSID] = targetComment
+			}
+		}
+
+		key := getLMSCommentKey(comment)
+
+		same, err := sameJSON(comment, existing[comment.AssignmentLMSID][comment.UserLMSID])
+		if err != nil {
+			return fmt.Errorf("Failed to compare LMS comment '%s': '%w'.", key, err)
+		}
+
+		if same {
+			counts.Skipped++
+			continue
+		}
+
+		err = target.SaveLMSComments([]*model.LMSComment{comment})
+		if err != nil {
+			return fmt.Errorf("Failed to save target LMS comment '%s': '%w'.", key, err)
+		}
+
+		counts.Copied++
+	}
+
+	return nil
+}
+
+// Make the target's active tasks match the source's.
+func migrateTasks(source db.Backend, target db.Backend, counts *Counts) error {
+	sourceTasks, err := source.GetActiveTasks()
+	if err != nil {
+		return fmt.Errorf("Failed to get source tasks: '%w'.", err)
+	}
+
+	targetTasks, err := target.GetActiveTasks()
+	if err != nil {
+		return fmt.Errorf("Failed to get target tasks: '%w'.", err)
+	}
+
+	// A nil task removes it from the target.
+	upsertTasks := make(map[string]*model.FullScheduledTask)
+	for hash := range targetTasks {
+		_, exists := sourceTasks[hash]
+		if !exists {
+			upsertTasks[hash] = nil
+		}
+	}
+
+	for hash, task := range sourceTasks {
+		same, err := sameJSON(task, targetTasks[hash])
+		if err != nil {
+			return fmt.Errorf("Failed to compare task '%s': '%w'.", hash, err)
+		}
+
+		if same {
+			counts.Skipped++
+		} else {
+			upsertTasks[hash] = task
+			counts.Copied++
+		}
+	}
+
+	if len(upsertTasks) == 0 {
+		return nil
+	}
+
+	err = target.UpsertActiveTasks(upsertTasks)
+	if err != nil {
+		return fmt.Errorf("Failed to save target tasks: '%w'.", err)
+	}
+
+	return nil
+}
+
+// Log records are append-only and always returned in the order they were stored,
+// so any records already in the target are a prefix of the source's records.
+func migrateLogs(source db.Backend, target db.Backend, counts *Counts) error {
+	query := log.ParsedLogQuery{Level: log.LevelTrace}
+
+	sourceRecords, err := source.GetLogRecords(query)
+	if err != nil {
+		return fmt.Errorf("Failed to get source log records: '%w'.", err)
+	}
+
+	targetRecords, err := target.GetLogRecords(query)
+	if err != nil {
+		return fmt.Errorf("Failed to get target log records: '%w'.", err)
+	}
+
+	if len(targetRecords) > len(sourceRecords) {
+		return fmt.Errorf("Target has more log records (%d) than the source (%d).", len(targetRecords), len(sourceRecords))
+	}
+
+	counts.Skipped += len(targetRecords)
+
+	for _, record := range sourceRecords[len(targetRecords):] {
+		err = target.LogDirect(record)
+		if err != nil {
+			return fmt.Errorf("Failed to store target log record: '%w'.", err)
+		}
+
+		counts.Copied++
+	}
+
+	return nil
+}
+
+// Like logs, metrics are append-only and ordered.
+func migrateMetrics(source db.Backend, target db.Backend, metricType stats.MetricType, counts *Counts) error {
+	query := stats.Query{Type: metricType}
+
+	sourceRecords, err := source.GetMetrics(query)
+	if err != nil {
+		return fmt.Errorf("Failed to get source metrics of type '%s': '%w'.", metricType, err)
+	}
+
+	targetRecords, err := target.GetMetrics(query)
+	if err != nil {
+		return fmt.Errorf("Failed to get target metrics of type '%s': '%w'.", metricType, err)
+	}
+
+	if len(targetRecords) > len(sourceRecords) {
+		return fmt.Errorf("Target has more metrics of type '%s' (%d) than the source (%d).",
+			metricType, len(targetRecords), len(sourceRecords))
+	}
+
+	counts.Skipped += len(targetRecords)
+
+	for _, record := range sourceRecords[len(targetRecords):] {
+		err = target.StoreMetric(record)
+		if err != nil {
+			return fmt.Errorf("Failed to store target metric of type '%s': '%w'.", metricType, err)
+		}
+
+		counts.Copied++
+	}
+
+	return nil
+}
+
+// Dump a course to find the parts of it that cannot be listed directly through a backend.
+// Dumps have the same layout for all backends.
+func getCourseContents(backend db.Backend, course *model.Course) (*courseContents, error) {
+	tempDir, err := util.MkDirTemp("autograder-migrate-course-")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create temp dir: '%w'.", err)
+	}
+	defer util.RemoveDirent(tempDir)
+
+	err = backend.DumpCourse(course, tempDir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to dump course: '%w'.", err)
+	}
+
+	contents := courseContents{
+		submissionOwners: make(map[string][]string),
+		manualGrades:     make([]*model.ManualGrade, 0),
+		lmsComments:      make([]*model.LMSComment, 0),
+	}
+
+	submissionsDir := filepath.Join(tempDir, model.SUBMISSIONS_DIRNAME)
+	for _, assignment := range course.GetSortedAssignments() {
+		dir := filepath.Join(submissionsDir, assignment.GetID())
+		if !util.IsDir(dir) {
+			continue
+		}
+
+		dirents, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list submission owners in '%s': '%w'.", dir, err)
+		}
+
+		emails := make([]string, 0, len(dirents))
+		for _, dirent := range dirents {
+			if dirent.IsDir() {
+				emails = append(emails, dirent.Name())
+			}
+		}
+
+		slices.Sort(emails)
+		contents.submissionOwners[assignment.GetID()] = emails
+
+		grades, err := readManualGrades(dir, emails)
+		if err != nil {
+			return nil, err
+		}
+
+		contents.manualGrades = append(contents.manualGrades, grades...)
+	}
+
+	lmsCommentsPath := filepath.Join(tempDir, model.LMS_COMMENTS_FILENAME)
+	if util.PathExists(lmsCommentsPath) {
+		err = util.JSONFromFile(lmsCommentsPath, &contents.lmsComments)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read dumped LMS comments: '%w'.", err)
+		}
+	}
+
+	contents.individualAnalysis, err = readJSONL(filepath.Join(tempDir, DUMP_ANALYSIS_INDIVIDUAL_FILENAME), model.IndividualAnalysis{})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read dumped individual analysis: '%w'.", err)
+	}
+
+	contents.pairwiseAnalysis, err = readJSONL(filepath.Join(tempDir, DUMP_ANALYSIS_PAIRWISE_FILENAME), model.PairwiseAnalysis{})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read dumped pairwise analysis: '%w'.", err)
+	}
+
+	return &contents, nil
+}
+
+// Manual grades are dumped alongside the submissions they are for.
+func readManualGrades(assignmentDir string, emails []string) ([]*model.ManualGrade, error) {
+	grades := make([]*model.ManualGrade, 0)
+
+	for _, email := range emails {
+		userDir := filepath.Join(assignmentDir, email)
+
+		dirents, err := os.ReadDir(userDir)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list submissions in '%s': '%w'.", userDir, err)
+		}
+
+		for _, dirent := range dirents {
+			path := filepath.Join(userDir, dirent.Name(), model.MANUAL_GRADE_FILENAME)
+			if !util.PathExists(path) {
+				continue
+			}
+
+			var grade model.ManualGrade
+			err = util.JSONFromFile(path, &grade)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to read dumped manual grade '%s': '%w'.", path, err)
+			}
+
+			grades = append(grades, &grade)
+		}
+	}
+
+	return grades, nil
+}
+
+// Extensions are unique per (assignment, user) in a course.
+// Course-wide extensions have an empty assignment ID.
+func getExtensionKey(extension *model.Extension) string {
+	return fmt.Sprintf("%s::%s", extension.AssignmentID, extension.User)
+}
+
+// LMS comments are unique per (LMS assignment, LMS user) in a course.
+func getLMSCommentKey(comment *model.LMSComment) string {
+	return fmt.Sprintf("%s::%s", comment.AssignmentLMSID, comment.UserLMSID)
+}
+
+func readJSONL[T any](path string, emptyRecord T) ([]*T, error) {
+	if !util.PathExists(path) {
+		return make([]*T, 0), nil
+	}
+
+	return util.FilterJSONLFile(path, emptyRecord, func(record *T) bool {
+		return true
+	})
+}
+
+// Check if two objects serialize to the same JSON.
+func sameJSON(a any, b any) (bool, error) {
+	aHash, err := util.Sha256HashFromJSONObject(a)
+	if err != nil {
+		return false, err
+	}
+
+	bHash, err := util.Sha256HashFromJSONObject(b)
+	if err != nil {
+		return false, err
+	}
+
+	return (aHash == bHash), nil
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
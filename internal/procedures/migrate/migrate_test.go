@@ -0,0 +1,377 @@
+package migrate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const (
+	TEST_SUBMISSION_ID_1 = "course101::hw0::course-student@test.edulinq.org::1697406256"
+	TEST_SUBMISSION_ID_2 = "course101::hw0::course-student@test.edulinq.org::1697406265"
+)
+
+func TestMigrateBase(test *testing.T) {
+	source, target := openTestBackends(test)
+	defer closeTestBackends(source, target)
+
+	addTestRecords(test, source)
+
+	result, err := Migrate(source, target)
+	if err != nil {
+		test.Fatalf("Failed to migrate: '%v'.", err)
+	}
+
+	for _, category := range []string{CATEGORY_USERS, CATEGORY_COURSES, CATEGORY_SUBMISSIONS, CATEGORY_MANUAL_GRADES,
+		CATEGORY_EXTENSIONS, CATEGORY_GROUPS, CATEGORY_GIT_REPOS, CATEGORY_LMS_COMMENTS, CATEGORY_ANALYSIS_INDIVIDUAL, CATEGORY_ANALYSIS_PAIRWISE, CATEGORY_LOGS, CATEGORY_METRICS} {
+		if result[category].Copied == 0 {
+			test.Errorf("Nothing was copied for '%s'.", category)
+		}
+
+		if result[category].Skipped != 0 {
+			test.Errorf("Unexpected skips for '%s'. Expected: 0, Actual: %d.", category, result[category].Skipped)
+		}
+	}
+
+	assertValid(test, source, target)
+
+	// Running again should not copy any new data.
+	result, err = Migrate(source, target)
+	if err != nil {
+		test.Fatalf("Failed to migrate a second time: '%v'.", err)
+	}
+
+	for _, category := range []string{CATEGORY_USERS, CATEGORY_SUBMISSIONS, CATEGORY_MANUAL_GRADES, CATEGORY_EXTENSIONS, CATEGORY_GROUPS, CATEGORY_GIT_REPOS, CATEGORY_LMS_COMMENTS,
+		CATEGORY_TASKS, CATEGORY_LOGS, CATEGORY_METRICS} {
+		if result[category].Copied != 0 {
+			test.Errorf("Unexpected copies for '%s' on second migration. Expected: 0, Actual: %d.", category, result[category].Copied)
+		}
+	}
+
+	assertValid(test, source, target)
+}
+
+// Simulate an interrupted migration by removing some data from the target (and adding new data to the source).
+func TestMigrateResume(test *testing.T) {
+	source, target := openTestBackends(test)
+	defer closeTestBackends(source, target)
+
+	addTestRecords(test, source)
+
+	_, err := Migrate(source, target)
+	if err != nil {
+		test.Fatalf("Failed to migrate: '%v'.", err)
+	}
+
+	course := db.MustGetTestCourse()
+	assignment := course.GetAssignment("hw0")
+
+	removed, err := target.RemoveSubmission(assignment, "course-student@test.edulinq.org", "")
+	if err != nil {
+		test.Fatalf("Failed to remove submission: '%v'.", err)
+	}
+
+	if !removed {
+		test.Fatalf("Did not remove submission.")
+	}
+
+	err = source.LogDirect(&log.Record{
+		Level:     log.LevelInfo,
+		Message:   "New record.",
+		Timestamp: timestamp.FromMSecs(200),
+	})
+	if err != nil {
+		test.Fatalf("Failed to add log record: '%v'.", err)
+	}
+
+	result, err := Migrate(source, target)
+	if err != nil {
+		test.Fatalf("Failed to resume migration: '%v'.", err)
+	}
+
+	if result[CATEGORY_SUBMISSIONS].Copied != 1 {
+		test.Errorf("Unexpected number of copied submissions. Expected: 1, Actual: %d.", result[CATEGORY_SUBMISSIONS].Copied)
+	}
+
+	if result[CATEGORY_LOGS].Copied != 1 {
+		test.Errorf("Unexpected number of copied log records. Expected: 1, Actual: %d.", result[CATEGORY_LOGS].Copied)
+	}
+
+	assertValid(test, source, target)
+}
+
+func TestValidateMismatch(test *testing.T) {
+	source, target := openTestBackends(test)
+	defer closeTestBackends(source, target)
+
+	addTestRecords(test, source)
+
+	_, err := Migrate(source, target)
+	if err != nil {
+		test.Fatalf("Failed to migrate: '%v'.", err)
+	}
+
+	course := db.MustGetTestCourse()
+	assignment := course.GetAssignment("hw0")
+
+	_, err = target.RemoveSubmission(assignment, "course-student@test.edulinq.org", "")
+	if err != nil {
+		test.Fatalf("Failed to remove submission: '%v'.", err)
+	}
+
+	err = target.DeleteUser("course-other@test.edulinq.org")
+	if err != nil {
+		test.Fatalf("Failed to remove user: '%v'.", err)
+	}
+
+	result, err := Validate(source, target)
+	if err != nil {
+		test.Fatalf("Failed to validate: '%v'.", err)
+	}
+
+	expected := []string{
+		"[users] Count mismatch for 'all'.",
+		"[submissions] Count mismatch for 'course101-hw0::course-student@test.edulinq.org'.",
+	}
+
+	if len(result.Mismatches) != len(expected) {
+		test.Fatalf("Unexpected number of mismatches. Expected: %d, Actual: %d. Mismatches: '%s'.",
+			len(expected), len(result.Mismatches), util.MustToJSONIndent(result.Mismatches))
+	}
+
+	for i, mismatch := range result.Mismatches {
+		if !strings.HasPrefix(mismatch, expected[i]) {
+			test.Errorf("Case %d: Unexpected mismatch. Expected prefix: '%s', Actual: '%s'.", i, expected[i], mismatch)
+		}
+	}
+}
+
+func TestMigrateTypesSameType(test *testing.T) {
+	_, err := MigrateTypes(db.DB_TYPE_DISK, db.DB_TYPE_DISK)
+	if err == nil {
+		test.Fatalf("Did not get an error when migrating to the same type.")
+	}
+
+	expected := "Source and target databases must be different types"
+	if !strings.Contains(err.Error(), expected) {
+		test.Fatalf("Unexpected error. Expected substring: '%s', Actual: '%v'.", expected, err)
+	}
+}
+
+func TestGetCourseContents(test *testing.T) {
+	source, target := openTestBackends(test)
+	defer closeTestBackends(source, target)
+
+	addTestRecords(test, source)
+
+	contents, err := getCourseContents(source, db.MustGetTestCourse())
+	if err != nil {
+		test.Fatalf("Failed to get course contents: '%v'.", err)
+	}
+
+	expectedOwners := map[string][]string{
+		"hw0": []string{"course-student@test.edulinq.org"},
+	}
+
+	if !reflect.DeepEqual(expectedOwners, contents.submissionOwners) {
+		test.Errorf("Unexpected submission owners. Expected: '%s', Actual: '%s'.",
+			util.MustToJSONIndent(expectedOwners), util.MustToJSONIndent(contents.submissionOwners))
+	}
+
+	if len(contents.manualGrades) != 1 {
+		test.Errorf("Unexpected number of manual grades. Expected: 1, Actual: %d.", len(contents.manualGrades))
+	}
+
+	if len(contents.lmsComments) != 2 {
+		test.Errorf("Unexpected number of LMS comments. Expected: 2, Actual: %d.", len(contents.lmsComments))
+	}
+
+	if len(contents.individualAnalysis) != 1 {
+		test.Errorf("Unexpected number of individual analysis records. Expected: 1, Actual: %d.", len(contents.individualAnalysis))
+	}
+
+	if len(contents.pairwiseAnalysis) != 1 {
+		test.Errorf("Unexpected number of pairwise analysis records. Expected: 1, Actual: %d.", len(contents.pairwiseAnalysis))
+	}
+}
+
+func openTestBackends(test *testing.T) (db.Backend, db.Backend) {
+	db.ResetForTesting()
+
+	source, err := db.OpenBackend(db.DB_TYPE_DISK)
+	if err != nil {
+		test.Fatalf("Failed to open source database: '%v'.", err)
+	}
+
+	target, err := db.OpenBackend(db.DB_TYPE_SQLITE)
+	if err != nil {
+		source.Close()
+		test.Fatalf("Failed to open target database: '%v'.", err)
+	}
+
+	err = target.Clear()
+	if err != nil {
+		closeTestBackends(source, target)
+		test.Fatalf("Failed to clear target database: '%v'.", err)
+	}
+
+	return source, target
+}
+
+func closeTestBackends(source db.Backend, target db.Backend) {
+	target.Clear()
+	target.Close()
+	source.Close()
+
+	db.ResetForTesting()
+}
+
+// Add records that are not part of the standard test data.
+func addTestRecords(test *testing.T, backend db.Backend) {
+	err := backend.LogDirect(&log.Record{
+		Level:     log.LevelInfo,
+		Message:   "Test record.",
+		Timestamp: timestamp.FromMSecs(100),
+		Course:    "course101",
+	})
+	if err != nil {
+		test.Fatalf("Failed to add log record: '%v'.", err)
+	}
+
+	err = backend.StoreMetric(&stats.Metric{
+		Timestamp: timestamp.FromMSecs(100),
+		Type:      stats.MetricTypeGradingTime,
+		Value:     1,
+		Attributes: map[stats.MetricAttribute]any{
+			stats.MetricAttributeCourseID: "course101",
+		},
+	})
+	if err != nil {
+		test.Fatalf("Failed to add metric: '%v'.", err)
+	}
+
+	err = backend.SaveManualGrade(&model.ManualGrade{
+		ID:           TEST_SUBMISSION_ID_2,
+		ShortID:      "1697406265",
+		CourseID:     "course101",
+		AssignmentID: "hw0",
+		User:         "course-student@test.edulinq.org",
+		Grader:       "course-grader@test.edulinq.org",
+		UpdateTime:   timestamp.FromMSecs(100),
+		Selections:   []string{"style"},
+		Comments:     "Nice.",
+	})
+	if err != nil {
+		test.Fatalf("Failed to add manual grade: '%v'.", err)
+	}
+
+	for _, extension := range []*model.Extension{
+		&model.Extension{
+			CourseID:      "course101",
+			User:          "course-student@test.edulinq.org",
+			ExtraAttempts: 1,
+			UpdateTime:    timestamp.FromMSecs(100),
+		},
+		&model.Extension{
+			CourseID:          "course101",
+			AssignmentID:      "hw0",
+			User:              "course-student@test.edulinq.org",
+			RuntimeMultiplier: 1.5,
+			Notes:             "Accommodation.",
+			UpdateTime:        timestamp.FromMSecs(100),
+		},
+	} {
+		err = backend.SaveExtension(extension)
+		if err != nil {
+			test.Fatalf("Failed to add extension: '%v'.", err)
+		}
+	}
+
+	err = backend.SaveGroup(db.MustGetTestAssignment(), &model.Group{
+		ID:           "g1",
+		CourseID:     "course101",
+		AssignmentID: "hw0",
+		Members:      []string{"course-student@test.edulinq.org"},
+		Invited:      []string{"course-other@test.edulinq.org"},
+		CreatedBy:    "course-student@test.edulinq.org",
+		UpdateTime:   timestamp.FromMSecs(100),
+	})
+	if err != nil {
+		test.Fatalf("Failed to add group: '%v'.", err)
+	}
+
+	lastCommitTime := timestamp.FromMSecs(100)
+
+	err = backend.SaveGitRepo(db.MustGetTestAssignment(), &model.GitRepo{
+		CourseID:       "course101",
+		AssignmentID:   "hw0",
+		User:           "course-student@test.edulinq.org",
+		URL:            "https://github.com/test/hw0-course-student.git",
+		LastCommit:     "abc123",
+		LastCommitTime: &lastCommitTime,
+		CreatedBy:      "course-student@test.edulinq.org",
+		UpdateTime:     timestamp.FromMSecs(100),
+	})
+	if err != nil {
+		test.Fatalf("Failed to add git repo: '%v'.", err)
+	}
+
+	err = backend.SaveLMSComments([]*model.LMSComment{
+		&model.LMSComment{
+			CourseID:        "course101",
+			AssignmentLMSID: "001",
+			UserLMSID:       "lms-student",
+			Text:            "Score: 1.",
+			UpdateTime:      timestamp.FromMSecs(100),
+		},
+		&model.LMSComment{
+			CourseID:        "course101",
+			AssignmentLMSID: "002",
+			UserLMSID:       "lms-student",
+			Text:            "Score: 2.",
+			UpdateTime:      timestamp.FromMSecs(100),
+		},
+	})
+	if err != nil {
+		test.Fatalf("Failed to add LMS comments: '%v'.", err)
+	}
+
+	err = backend.StoreIndividualAnalysis([]*model.IndividualAnalysis{
+		&model.IndividualAnalysis{
+			AnalysisTimestamp: timestamp.Zero(),
+			FullID:            TEST_SUBMISSION_ID_1,
+			CourseID:          "course101",
+		},
+	})
+	if err != nil {
+		test.Fatalf("Failed to add individual analysis: '%v'.", err)
+	}
+
+	err = backend.StorePairwiseAnalysis([]*model.PairwiseAnalysis{
+		&model.PairwiseAnalysis{
+			AnalysisTimestamp: timestamp.Zero(),
+			SubmissionIDs:     model.NewPairwiseKey(TEST_SUBMISSION_ID_1, TEST_SUBMISSION_ID_2),
+		},
+	})
+	if err != nil {
+		test.Fatalf("Failed to add pairwise analysis: '%v'.", err)
+	}
+}
+
+func assertValid(test *testing.T, source db.Backend, target db.Backend) {
+	result, err := Validate(source, target)
+	if err != nil {
+		test.Fatalf("Failed to validate: '%v'.", err)
+	}
+
+	if !result.IsValid() {
+		test.Fatalf("Migration is not valid: '%s'.", util.MustToJSONIndent(result.Mismatches))
+	}
+}
@@ -0,0 +1,366 @@
+package migrate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+// The outcome of comparing a source and target database.
+type ValidationResult struct {
+	// The number of objects (in the source) checked in each category.
+	Counts map[string]int `json:"counts"`
+
+	// Descriptions of all the differences between the source and target.
+	// Empty if the databases match.
+	Mismatches []string `json:"mismatches"`
+}
+
+func (this *ValidationResult) IsValid() bool {
+	return (len(this.Mismatches) == 0)
+}
+
+func (this *ValidationResult) addMismatch(category string, format string, args ...any) {
+	this.Mismatches = append(this.Mismatches, fmt.Sprintf("[%s] ", category)+fmt.Sprintf(format, args...))
+}
+
+// Compare the counts and hashes of a single category of objects.
+func (this *ValidationResult) compare(category string, key string, sourceCount int, targetCount int, source any, target any) error {
+	this.Counts[category] += sourceCount
+
+	if sourceCount != targetCount {
+		this.addMismatch(category, "Count mismatch for '%s'. Source: %d, Target: %d.", key, sourceCount, targetCount)
+		return nil
+	}
+
+	sourceHash, err := util.Sha256HashFromJSONObject(source)
+	if err != nil {
+		return fmt.Errorf("Failed to hash source %s for '%s': '%w'.", category, key, err)
+	}
+
+	targetHash, err := util.Sha256HashFromJSONObject(target)
+	if err != nil {
+		return fmt.Errorf("Failed to hash target %s for '%s': '%w'.", category, key, err)
+	}
+
+	if sourceHash != targetHash {
+		this.addMismatch(category, "Hash mismatch for '%s'. Source: '%s', Target: '%s'.", key, sourceHash, targetHash)
+	}
+
+	return nil
+}
+
+// Open the two backends and validate that the target holds the same data as the source.
+func ValidateTypes(sourceType string, targetType string) (*ValidationResult, error) {
+	source, target, err := openBackends(sourceType, targetType)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+	defer target.Close()
+
+	return Validate(source, target)
+}
+
+// Check that everything in the source is in the target (by comparing counts and hashes).
+// Note that any differences will be reported in the result, errors are only returned if the validation could not be done.
+func Validate(source db.Backend, target db.Backend) (*ValidationResult, error) {
+	result := &ValidationResult{
+		Counts:     make(map[string]int),
+		Mismatches: make([]string, 0),
+	}
+
+	sourceUsers, err := source.GetServerUsers()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get source users: '%w'.", err)
+	}
+
+	targetUsers, err := target.GetServerUsers()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get target users: '%w'.", err)
+	}
+
+	err = result.compare(CATEGORY_USERS, "all", len(sourceUsers), len(targetUsers), sourceUsers, targetUsers)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceCourses, err := source.GetCourses()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get source courses: '%w'.", err)
+	}
+
+	for _, courseID := range sortedKeys(sourceCourses) {
+		err = validateCourse(source, target, sourceCourses[courseID], result)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to validate course '%s': '%w'.", courseID, err)
+		}
+	}
+
+	sourceTasks, err := source.GetActiveTasks()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get source tasks: '%w'.", err)
+	}
+
+	targetTasks, err := target.GetActiveTasks()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get target tasks: '%w'.", err)
+	}
+
+	err = result.compare(CATEGORY_TASKS, "all", len(sourceTasks), len(targetTasks), sourceTasks, targetTasks)
+	if err != nil {
+		return nil, err
+	}
+
+	logQuery := log.ParsedLogQuery{Level: log.LevelTrace}
+
+	sourceRecords, err := source.GetLogRecords(logQuery)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get source log records: '%w'.", err)
+	}
+
+	targetRecords, err := target.GetLogRecords(logQuery)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get target log records: '%w'.", err)
+	}
+
+	err = result.compare(CATEGORY_LOGS, "all", len(sourceRecords), len(targetRecords), sourceRecords, targetRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, metricType := range stats.GetKnownMetricTypes() {
+		query := stats.Query{Type: metricType}
+
+		sourceMetrics, err := source.GetMetrics(query)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get source metrics of type '%s': '%w'.", metricType, err)
+		}
+
+		targetMetrics, err := target.GetMetrics(query)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get target metrics of type '%s': '%w'.", metricType, err)
+		}
+
+		err = result.compare(CATEGORY_METRICS, string(metricType), len(sourceMetrics), len(targetMetrics), sourceMetrics, targetMetrics)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func validateCourse(source db.Backend, target db.Backend, sourceCourse *model.Course, result *ValidationResult) error {
+	courseID := sourceCourse.GetID()
+
+	targetCourse, err := target.GetCourse(courseID)
+	if err != nil {
+		return fmt.Errorf("Failed to get target course: '%w'.", err)
+	}
+
+	if targetCourse == nil {
+		result.Counts[CATEGORY_COURSES]++
+		result.addMismatch(CATEGORY_COURSES, "Course '%s' is missing from the target.", courseID)
+		return nil
+	}
+
+	err = result.compare(CATEGORY_COURSES, courseID, 1, 1, sourceCourse, targetCourse)
+	if err != nil {
+		return err
+	}
+
+	sourceContents, err := getCourseContents(source, sourceCourse)
+	if err != nil {
+		return err
+	}
+
+	targetContents, err := getCourseContents(target, targetCourse)
+	if err != nil {
+		return err
+	}
+
+	for _, assignmentID := range sortedKeys(sourceContents.submissionOwners) {
+		assignment := sourceCourse.GetAssignment(assignmentID)
+
+		for _, email := range sourceContents.submissionOwners[assignmentID] {
+			sourceAttempts, err := getSortedAttempts(source, assignment, email)
+			if err != nil {
+				return fmt.Errorf("Failed to get source submissions: '%w'.", err)
+			}
+
+			targetAttempts, err := getSortedAttempts(target, assignment, email)
+			if err != nil {
+				return fmt.Errorf("Failed to get target submissions: '%w'.", err)
+			}
+
+			key := fmt.Sprintf("%s::%s", assignment.FullID(), email)
+			err = result.compare(CATEGORY_SUBMISSIONS, key, len(sourceAttempts), len(targetAttempts), sourceAttempts, targetAttempts)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	sameOwners, err := sameJSON(sourceContents.submissionOwners, targetContents.submissionOwners)
+	if err != nil {
+		return fmt.Errorf("Failed to compare submission owners: '%w'.", err)
+	}
+
+	if !sameOwners {
+		result.addMismatch(CATEGORY_SUBMISSIONS, "Users with submissions in course '%s' do not match.", courseID)
+	}
+
+	slices.SortFunc(sourceContents.manualGrades, compareManualGrades)
+	slices.SortFunc(targetContents.manualGrades, compareManualGrades)
+
+	err = result.compare(CATEGORY_MANUAL_GRADES, courseID,
+		len(sourceContents.manualGrades), len(targetContents.manualGrades),
+		sourceContents.manualGrades, targetContents.manualGrades)
+	if err != nil {
+		return err
+	}
+
+	sourceExtensions, err := getSortedExtensions(source, courseID)
+	if err != nil {
+		return fmt.Errorf("Failed to get source extensions: '%w'.", err)
+	}
+
+	targetExtensions, err := getSortedExtensions(target, courseID)
+	if err != nil {
+		return fmt.Errorf("Failed to get target extensions: '%w'.", err)
+	}
+
+	err = result.compare(CATEGORY_EXTENSIONS, courseID, len(sourceExtensions), len(targetExtensions), sourceExtensions, targetExtensions)
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range sourceCourse.GetSortedAssignments() {
+		sourceGroups, err := source.GetGroups(assignment)
+		if err != nil {
+			return fmt.Errorf("Failed to get source groups: '%w'.", err)
+		}
+
+		targetGroups, err := target.GetGroups(assignment)
+		if err != nil {
+			return fmt.Errorf("Failed to get target groups: '%w'.", err)
+		}
+
+		err = result.compare(CATEGORY_GROUPS, assignment.FullID(), len(sourceGroups), len(targetGroups), sourceGroups, targetGroups)
+		if err != nil {
+			return err
+		}
+
+		sourceRepos, err := getSortedGitRepos(source, assignment)
+		if err != nil {
+			return fmt.Errorf("Failed to get source git repos: '%w'.", err)
+		}
+
+		targetRepos, err := getSortedGitRepos(target, assignment)
+		if err != nil {
+			return fmt.Errorf("Failed to get target git repos: '%w'.", err)
+		}
+
+		err = result.compare(CATEGORY_GIT_REPOS, assignment.FullID(), len(sourceRepos), len(targetRepos), sourceRepos, targetRepos)
+		if err != nil {
+			return err
+		}
+	}
+
+	slices.SortFunc(sourceContents.lmsComments, compareLMSComments)
+	slices.SortFunc(targetContents.lmsComments, compareLMSComments)
+
+	err = result.compare(CATEGORY_LMS_COMMENTS, courseID,
+		len(sourceContents.lmsComments), len(targetContents.lmsComments),
+		sourceContents.lmsComments, targetContents.lmsComments)
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(sourceContents.individualAnalysis, compareIndividualAnalysis)
+	slices.SortFunc(targetContents.individualAnalysis, compareIndividualAnalysis)
+
+	err = result.compare(CATEGORY_ANALYSIS_INDIVIDUAL, courseID,
+		len(sourceContents.individualAnalysis), len(targetContents.individualAnalysis),
+		sourceContents.individualAnalysis, targetContents.individualAnalysis)
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(sourceContents.pairwiseAnalysis, comparePairwiseAnalysis)
+	slices.SortFunc(targetContents.pairwiseAnalysis, comparePairwiseAnalysis)
+
+	err = result.compare(CATEGORY_ANALYSIS_PAIRWISE, courseID,
+		len(sourceContents.pairwiseAnalysis), len(targetContents.pairwiseAnalysis),
+		sourceContents.pairwiseAnalysis, targetContents.pairwiseAnalysis)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Backends may return attempts in different orders, so sort them by ID.
+func getSortedAttempts(backend db.Backend, assignment *model.Assignment, email string) ([]*model.GradingResult, error) {
+	attempts, err := backend.GetSubmissionAttempts(assignment, email)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(attempts, func(a *model.GradingResult, b *model.GradingResult) int {
+		return strings.Compare(a.Info.ShortID, b.Info.ShortID)
+	})
+
+	return attempts, nil
+}
+
+// Backends may return extensions in different orders, so sort them by key.
+func getSortedExtensions(backend db.Backend, courseID string) ([]*model.Extension, error) {
+	extensions, err := backend.GetExtensions(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(extensions, func(a *model.Extension, b *model.Extension) int {
+		return strings.Compare(getExtensionKey(a), getExtensionKey(b))
+	})
+
+	return extensions, nil
+}
+
+// Backends may return git repos in different orders, so sort them by user.
+func getSortedGitRepos(backend db.Backend, assignment *model.Assignment) ([]*model.GitRepo, error) {
+	repos, err := backend.GetGitRepos(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(repos, func(a *model.GitRepo, b *model.GitRepo) int {
+		return strings.Compare(a.User, b.User)
+	})
+
+	return repos, nil
+}
+
+func compareManualGrades(a *model.ManualGrade, b *model.ManualGrade) int {
+	return strings.Compare(a.ID, b.ID)
+}
+
+func compareLMSComments(a *model.LMSComment, b *model.LMSComment) int {
+	return strings.Compare(getLMSCommentKey(a), getLMSCommentKey(b))
+}
+
+func compareIndividualAnalysis(a *model.IndividualAnalysis, b *model.IndividualAnalysis) int {
+	return strings.Compare(a.FullID, b.FullID)
+}
+
+func comparePairwiseAnalysis(a *model.PairwiseAnalysis, b *model.PairwiseAnalysis) int {
+	return strings.Compare(a.SubmissionIDs.String(), b.SubmissionIDs.String())
+}
@@ -0,0 +1,71 @@
+package retention
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/log"
+)
+
+var (
+	backgroundLock   sync.Mutex
+	backgroundCancel context.CancelFunc = nil
+	backgroundWait   *sync.WaitGroup    = nil
+)
+
+// Start periodically applying the retention policy from the config in the background.
+// Does nothing if background runs are disabled or already running.
+func Start() {
+	backgroundLock.Lock()
+	defer backgroundLock.Unlock()
+
+	intervalSecs := config.STATS_RETENTION_INTERVAL_SECS.Get()
+	if (intervalSecs <= 0) || (backgroundCancel != nil) {
+		return
+	}
+
+	var ctx context.Context
+	ctx, backgroundCancel = context.WithCancel(context.Background())
+	backgroundWait = &sync.WaitGroup{}
+
+	backgroundWait.Add(1)
+	go runBackground(ctx, backgroundWait, time.Duration(intervalSecs)*time.Second)
+}
+
+// Stop any background runs.
+// Once this returns, no background run will be in-progress.
+func Stop() {
+	backgroundLock.Lock()
+	defer backgroundLock.Unlock()
+
+	if backgroundCancel == nil {
+		return
+	}
+
+	backgroundCancel()
+	backgroundWait.Wait()
+
+	backgroundCancel = nil
+	backgroundWait = nil
+}
+
+func runBackground(ctx context.Context, wait *sync.WaitGroup, interval time.Duration) {
+	defer wait.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := RunFromConfig(false)
+			if err != nil {
+				log.Error("Failed to apply stats and log retention.", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,356 @@
+// Remove and downsample old metrics and log records.
+package retention
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+const MSECS_PER_DAY int64 = 24 * 60 * 60 * 1000
+
+// The log levels that retention can be set for.
+var retentionLogLevels []log.LogLevel = []log.LogLevel{
+	log.LevelTrace,
+	log.LevelDebug,
+	log.LevelInfo,
+	log.LevelWarn,
+	log.LevelError,
+	log.LevelFatal,
+}
+
+// Only one retention run may happen at a time.
+var runLock sync.Mutex
+
+// How long to keep metrics and log records (all lengths are in days).
+// A non-positive (or missing) length means to keep everything.
+type Policy struct {
+	MetricDays map[stats.MetricType]int
+
+	// Metrics older than this are downsampled into hourly rollups.
+	HourlyRollupDays int
+
+	// Metrics older than this are downsampled into daily rollups.
+	DailyRollupDays int
+
+	LogDays map[log.LogLevel]int
+}
+
+type MetricResult struct {
+	// The number of metrics removed for being too old.
+	Removed int `json:"removed"`
+
+	// The number of metrics (including older rollups) that were replaced with rollups.
+	Downsampled int `json:"downsampled"`
+
+	// The number of rollups that were stored.
+	Rollups int `json:"rollups"`
+}
+
+type Result struct {
+	// If true, nothing was actually removed or downsampled
+	// (the counts are what would have happened).
+	DryRun bool `json:"dry-run"`
+
+	// Only metric types with changes are included.
+	Metrics map[stats.MetricType]*MetricResult `json:"metrics"`
+
+	// The number of removed log records keyed by level.
+	// Only levels with removed records are included.
+	Logs map[string]int `json:"logs"`
+}
+
+// Get the retention policy from the config.
+func GetPolicy() (*Policy, error) {
+	policy := &Policy{
+		MetricDays:       make(map[stats.MetricType]int),
+		HourlyRollupDays: config.STATS_ROLLUP_HOURLY_DAYS.Get(),
+		DailyRollupDays:  config.STATS_ROLLUP_DAILY_DAYS.Get(),
+		LogDays:          make(map[log.LogLevel]int),
+	}
+
+	for _, metricType := range stats.GetKnownMetricTypes() {
+		policy.MetricDays[metricType] = config.STATS_RETENTION_DAYS.Get()
+	}
+
+	typeDays, err := parseDaysSpec(config.STATS_RETENTION_TYPES.Get())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse metric type retention ('%s'): '%w'.", config.STATS_RETENTION_TYPES.Key, err)
+	}
+
+	for rawType, days := range typeDays {
+		metricType := stats.MetricType(rawType)
+		_, exists := policy.MetricDays[metricType]
+		if !exists {
+			return nil, fmt.Errorf("Unknown metric type in retention ('%s'): '%s'.", config.STATS_RETENTION_TYPES.Key, rawType)
+		}
+
+		policy.MetricDays[metricType] = days
+	}
+
+	for _, level := range retentionLogLevels {
+		policy.LogDays[level] = config.LOG_RETENTION_DAYS.Get()
+	}
+
+	levelDays, err := parseDaysSpec(config.LOG_RETENTION_LEVELS.Get())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse log level retention ('%s'): '%w'.", config.LOG_RETENTION_LEVELS.Key, err)
+	}
+
+	for rawLevel, days := range levelDays {
+		level, err := log.ParseLevel(rawLevel)
+		if (err != nil) || (level == log.LevelOff) {
+			return nil, fmt.Errorf("Unknown log level in retention ('%s'): '%s'.", config.LOG_RETENTION_LEVELS.Key, rawLevel)
+		}
+
+		policy.LogDays[level] = days
+	}
+
+	return policy, nil
+}
+
+// Apply the retention policy from the config.
+func RunFromConfig(dryRun bool) (*Result, error) {
+	policy, err := GetPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	return Run(policy, timestamp.Now(), dryRun)
+}
+
+// Remove and downsample the metrics and log records that are old (relative to |now|) according to the policy.
+// On a dry run, nothing is changed but the result still reports what would have been changed.
+func Run(policy *Policy, now timestamp.Timestamp, dryRun bool) (*Result, error) {
+	runLock.Lock()
+	defer runLock.Unlock()
+
+	result := &Result{
+		DryRun:  dryRun,
+		Metrics: make(map[stats.MetricType]*MetricResult),
+		Logs:    make(map[string]int),
+	}
+
+	for _, metricType := range stats.GetKnownMetricTypes() {
+		metricResult, err := applyMetricType(policy, metricType, now, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to apply retention to metric type '%s': '%w'.", metricType, err)
+		}
+
+		if (metricResult.Removed + metricResult.Downsampled + metricResult.Rollups) > 0 {
+			result.Metrics[metricType] = metricResult
+		}
+	}
+
+	for _, level := range retentionLogLevels {
+		count, err := applyLogLevel(policy, level, now, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to apply retention to log level '%s': '%w'.", level.String(), err)
+		}
+
+		if count > 0 {
+			result.Logs[level.String()] = count
+		}
+	}
+
+	log.Info("Applied stats and log retention.", log.NewAttr("dry-run", dryRun),
+		log.NewAttr("metrics", result.Metrics), log.NewAttr("logs", result.Logs))
+
+	return result, nil
+}
+
+func applyMetricType(policy *Policy, metricType stats.MetricType, now timestamp.Timestamp, dryRun bool) (*MetricResult, error) {
+	result := &MetricResult{}
+
+	// Everything before this is removed.
+	removeCutoff := timestamp.Timestamp(math.MinInt64)
+	if policy.MetricDays[metricType] > 0 {
+		removeCutoff = daysBefore(now, policy.MetricDays[metricType])
+	}
+
+	// [start, end) windows of metrics to downsample (oldest first).
+	windows := make([]*rollupWindow, 0, 2)
+
+	windowStart := removeCutoff
+	if policy.DailyRollupDays > 0 {
+		dailyAggregation := stats.Aggregation{TimeBucket: stats.TimeBucketDay}
+		windowEnd := dailyAggregation.GetBucketStart(daysBefore(now, policy.DailyRollupDays))
+
+		if windowEnd > windowStart {
+			windows = append(windows, &rollupWindow{stats.TimeBucketDay, windowStart, windowEnd})
+			windowStart = windowEnd
+		}
+	}
+
+	if policy.HourlyRollupDays > 0 {
+		hourlyAggregation := stats.Aggregation{TimeBucket: stats.TimeBucketHour}
+		windowEnd := hourlyAggregation.GetBucketStart(daysBefore(now, policy.HourlyRollupDays))
+
+		if windowEnd > windowStart {
+			windows = append(windows, &rollupWindow{stats.TimeBucketHour, windowStart, windowEnd})
+		}
+	}
+
+	hasRemoval := (removeCutoff > timestamp.Timestamp(math.MinInt64))
+	if !hasRemoval && (len(windows) == 0) {
+		return result, nil
+	}
+
+	if hasRemoval {
+		removeQuery := makeWindowQuery(metricType, timestamp.Timestamp(math.MinInt64), removeCutoff)
+
+		if dryRun {
+			count, err := db.CountMetricsInWindow(removeQuery)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to count old metrics: '%w'.", err)
+			}
+
+			result.Removed = count
+		} else {
+			count, err := db.ReplaceMetrics(removeQuery, nil)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to remove old metrics: '%w'.", err)
+			}
+
+			result.Removed = count
+		}
+	}
+
+	// Windows are processed a day at a time (skipping days without metrics),
+	// so only a day's worth of metrics are read at once.
+	dailyAggregation := stats.Aggregation{TimeBucket: stats.TimeBucketDay}
+	for _, window := range windows {
+		chunkStart := window.start
+		for chunkStart < window.end {
+			oldest, err := db.GetOldestMetricTimeInWindow(makeWindowQuery(metricType, chunkStart, window.end))
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get the next metrics to downsample: '%w'.", err)
+			}
+
+			if oldest == nil {
+				break
+			}
+
+			chunkStart = max(chunkStart, dailyAggregation.GetBucketStart(*oldest))
+			chunkEnd := min(dailyAggregation.GetBucketStart(chunkStart)+timestamp.Timestamp(MSECS_PER_DAY), window.end)
+
+			err = downsampleWindow(metricType, &rollupWindow{window.bucket, chunkStart, chunkEnd}, dryRun, result)
+			if err != nil {
+				return nil, err
+			}
+
+			chunkStart = chunkEnd
+		}
+	}
+
+	return result, nil
+}
+
+// Downsample all the metrics in a window, and add the counts to the result.
+func downsampleWindow(metricType stats.MetricType, window *rollupWindow, dryRun bool, result *MetricResult) error {
+	windowQuery := makeWindowQuery(metricType, window.start, window.end)
+
+	windowMetrics, err := db.GetMetricsInWindow(windowQuery)
+	if err != nil {
+		return fmt.Errorf("Failed to get metrics to downsample: '%w'.", err)
+	}
+
+	if (len(windowMetrics) == 0) || stats.IsDownsampled(windowMetrics, window.bucket) {
+		return nil
+	}
+
+	rollups, err := stats.Downsample(windowMetrics, window.bucket)
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		_, err = db.ReplaceMetrics(windowQuery, rollups)
+		if err != nil {
+			return fmt.Errorf("Failed to store '%s' rollups: '%w'.", window.bucket, err)
+		}
+	}
+
+	result.Downsampled += len(windowMetrics)
+	result.Rollups += len(rollups)
+
+	return nil
+}
+
+func applyLogLevel(policy *Policy, level log.LogLevel, now timestamp.Timestamp, dryRun bool) (int, error) {
+	days := policy.LogDays[level]
+	if days <= 0 {
+		return 0, nil
+	}
+
+	cutoff := daysBefore(now, days)
+
+	if dryRun {
+		return db.CountLogRecords(level, cutoff)
+	}
+
+	return db.RemoveLogRecords(level, cutoff)
+}
+
+type rollupWindow struct {
+	bucket stats.TimeBucket
+	start  timestamp.Timestamp
+	end    timestamp.Timestamp
+}
+
+// Get a query for the [start, end) time window (the query's time window excludes its start).
+func makeWindowQuery(metricType stats.MetricType, start timestamp.Timestamp, end timestamp.Timestamp) stats.Query {
+	after := start
+	if after > timestamp.Timestamp(math.MinInt64) {
+		after--
+	}
+
+	return stats.Query{
+		Type:   metricType,
+		After:  after,
+		Before: end,
+	}
+}
+
+func daysBefore(now timestamp.Timestamp, days int) timestamp.Timestamp {
+	return now - timestamp.Timestamp(int64(days)*MSECS_PER_DAY)
+}
+
+// Parse a comma-separated list of 'key=days'.
+func parseDaysSpec(text string) (map[string]int, error) {
+	results := make(map[string]int)
+
+	for _, part := range strings.Split(text, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, rawDays, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("Entry is not of the form 'key=days': '%s'.", part)
+		}
+
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("Entry has an empty key: '%s'.", part)
+		}
+
+		days, err := strconv.Atoi(strings.TrimSpace(rawDays))
+		if err != nil {
+			return nil, fmt.Errorf("Entry has an invalid number of days: '%s'.", part)
+		}
+
+		results[key] = days
+	}
+
+	return results, nil
+}
@@ -0,0 +1,306 @@
+package retention
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/stats"
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const testDay int64 = MSECS_PER_DAY
+const testHour int64 = 60 * 60 * 1000
+
+func TestRunBase(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	now := timestamp.FromMSecs(100 * testDay)
+
+	metrics := []*stats.Metric{
+		// Past retention.
+		&stats.Metric{Timestamp: timestamp.FromMSecs(60*testDay + 1), Type: stats.MetricTypeSystemCPU, Value: 1},
+
+		// Daily rollup.
+		&stats.Metric{Timestamp: timestamp.FromMSecs(90*testDay + 1), Type: stats.MetricTypeSystemCPU, Value: 2},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(90*testDay + testHour + 1), Type: stats.MetricTypeSystemCPU, Value: 4},
+
+		// Hourly rollup.
+		&stats.Metric{Timestamp: timestamp.FromMSecs(95*testDay + 1), Type: stats.MetricTypeSystemCPU, Value: 6},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(95*testDay + 2), Type: stats.MetricTypeSystemCPU, Value: 8},
+
+		// Recent.
+		&stats.Metric{Timestamp: timestamp.FromMSecs(99*testDay + 1), Type: stats.MetricTypeSystemCPU, Value: 10},
+
+		// No retention for this type, but still downsampled.
+		&stats.Metric{Timestamp: timestamp.FromMSecs(60*testDay + 1), Type: stats.MetricTypeSystemMemory, Value: 1},
+	}
+
+	for _, metric := range metrics {
+		err := db.StoreMetric(metric)
+		if err != nil {
+			test.Fatalf("Failed to store metric: '%v'.", err)
+		}
+	}
+
+	oldBackground := log.SetBackgroundLogging(false)
+	defer log.SetBackgroundLogging(oldBackground)
+
+	log.SetLevels(log.LevelOff, log.LevelTrace)
+	defer log.SetLevelFatal()
+
+	log.LogDirectRecord(&log.Record{Level: log.LevelTrace, Message: "old", Timestamp: timestamp.FromMSecs(50 * testDay)}, false, true)
+	log.LogDirectRecord(&log.Record{Level: log.LevelTrace, Message: "new", Timestamp: timestamp.FromMSecs(99*testDay + testHour)}, false, true)
+	log.LogDirectRecord(&log.Record{Level: log.LevelDebug, Message: "old", Timestamp: timestamp.FromMSecs(50 * testDay)}, false, true)
+
+	log.SetLevels(log.LevelOff, log.LevelOff)
+
+	policy := &Policy{
+		MetricDays:       map[stats.MetricType]int{stats.MetricTypeSystemCPU: 30},
+		HourlyRollupDays: 2,
+		DailyRollupDays:  7,
+		LogDays:          map[log.LogLevel]int{log.LevelTrace: 1},
+	}
+
+	expected := &Result{
+		Metrics: map[stats.MetricType]*MetricResult{
+			stats.MetricTypeSystemCPU:    &MetricResult{Removed: 1, Downsampled: 4, Rollups: 2},
+			stats.MetricTypeSystemMemory: &MetricResult{Removed: 0, Downsampled: 1, Rollups: 1},
+		},
+		Logs: map[string]int{"TRACE": 1},
+	}
+
+	// A dry run reports changes without making them.
+	expected.DryRun = true
+	result, err := Run(policy, now, true)
+	if err != nil {
+		test.Fatalf("Failed to do a dry run: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, result) {
+		test.Fatalf("Unexpected dry run result. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(result))
+	}
+
+	actualMetrics := mustGetMetrics(test, stats.MetricTypeSystemCPU)
+	if len(actualMetrics) != 6 {
+		test.Fatalf("Dry run changed metrics: '%s'.", util.MustToJSONIndent(actualMetrics))
+	}
+
+	// A real run.
+	expected.DryRun = false
+	result, err = Run(policy, now, false)
+	if err != nil {
+		test.Fatalf("Failed to run: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, result) {
+		test.Fatalf("Unexpected result. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(result))
+	}
+
+	expectedMetrics := []*stats.Metric{
+		&stats.Metric{
+			Timestamp:  timestamp.FromMSecs(90 * testDay),
+			Type:       stats.MetricTypeSystemCPU,
+			Value:      3,
+			Attributes: map[stats.MetricAttribute]any{},
+			Rollup:     &stats.MetricRollup{Bucket: stats.TimeBucketDay, Count: 2, Sum: 6, Min: 2, Max: 4},
+		},
+		&stats.Metric{
+			Timestamp:  timestamp.FromMSecs(95 * testDay),
+			Type:       stats.MetricTypeSystemCPU,
+			Value:      7,
+			Attributes: map[stats.MetricAttribute]any{},
+			Rollup:     &stats.MetricRollup{Bucket: stats.TimeBucketHour, Count: 2, Sum: 14, Min: 6, Max: 8},
+		},
+		metrics[5],
+	}
+
+	actualMetrics = mustGetMetrics(test, stats.MetricTypeSystemCPU)
+	if util.MustToJSON(expectedMetrics) != util.MustToJSON(actualMetrics) {
+		test.Fatalf("Unexpected metrics. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expectedMetrics), util.MustToJSONIndent(actualMetrics))
+	}
+
+	records, err := db.GetLogRecords(log.ParsedLogQuery{Level: log.LevelTrace})
+	if err != nil {
+		test.Fatalf("Failed to get log records: '%v'.", err)
+	}
+
+	if len(records) != 2 {
+		test.Fatalf("Unexpected log records: '%s'.", util.MustToJSONIndent(records))
+	}
+
+	// Running again does not change anything.
+	result, err = Run(policy, now, false)
+	if err != nil {
+		test.Fatalf("Failed to run again: '%v'.", err)
+	}
+
+	expected = &Result{
+		Metrics: map[stats.MetricType]*MetricResult{},
+		Logs:    map[string]int{},
+	}
+
+	if !reflect.DeepEqual(expected, result) {
+		test.Fatalf("Unexpected second result. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(result))
+	}
+}
+
+// Without any removal, the daily rollups reach back to the oldest metric (one day at a time).
+func TestRunNoRemoval(test *testing.T) {
+	db.ResetForTesting()
+	defer db.ResetForTesting()
+
+	now := timestamp.FromMSecs(100 * testDay)
+
+	metrics := []*stats.Metric{
+		&stats.Metric{Timestamp: timestamp.FromMSecs(1*testDay + 1), Type: stats.MetricTypeSystemCPU, Value: 1},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(1*testDay + testHour), Type: stats.MetricTypeSystemCPU, Value: 3},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(10*testDay + 1), Type: stats.MetricTypeSystemCPU, Value: 2},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(11*testDay - 1), Type: stats.MetricTypeSystemCPU, Value: 4},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(11*testDay + 1), Type: stats.MetricTypeSystemCPU, Value: 6},
+		&stats.Metric{Timestamp: timestamp.FromMSecs(99*testDay + 1), Type: stats.MetricTypeSystemCPU, Value: 8},
+	}
+
+	for _, metric := range metrics {
+		err := db.StoreMetric(metric)
+		if err != nil {
+			test.Fatalf("Failed to store metric: '%v'.", err)
+		}
+	}
+
+	policy := &Policy{
+		MetricDays:      map[stats.MetricType]int{},
+		DailyRollupDays: 7,
+		LogDays:         map[log.LogLevel]int{},
+	}
+
+	expected := &Result{
+		Metrics: map[stats.MetricType]*MetricResult{
+			stats.MetricTypeSystemCPU: &MetricResult{Removed: 0, Downsampled: 5, Rollups: 3},
+		},
+		Logs: map[string]int{},
+	}
+
+	result, err := Run(policy, now, false)
+	if err != nil {
+		test.Fatalf("Failed to run: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, result) {
+		test.Fatalf("Unexpected result. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(result))
+	}
+
+	expectedTimes := []int64{1 * testDay, 10 * testDay, 11 * testDay, 99*testDay + 1}
+
+	actualTimes := make([]int64, 0)
+	for _, metric := range mustGetMetrics(test, stats.MetricTypeSystemCPU) {
+		actualTimes = append(actualTimes, metric.Timestamp.ToMSecs())
+	}
+
+	if !reflect.DeepEqual(expectedTimes, actualTimes) {
+		test.Fatalf("Unexpected metric times. Expected: '%v', Actual: '%v'.", expectedTimes, actualTimes)
+	}
+
+	// Running again does not change anything.
+	result, err = Run(policy, now, false)
+	if err != nil {
+		test.Fatalf("Failed to run again: '%v'.", err)
+	}
+
+	if len(result.Metrics) != 0 {
+		test.Fatalf("Unexpected second result: '%s'.", util.MustToJSONIndent(result))
+	}
+}
+
+func TestGetPolicy(test *testing.T) {
+	defer config.STATS_RETENTION_DAYS.Set(config.STATS_RETENTION_DAYS.Get())
+	defer config.STATS_RETENTION_TYPES.Set(config.STATS_RETENTION_TYPES.Get())
+	defer config.LOG_RETENTION_DAYS.Set(config.LOG_RETENTION_DAYS.Get())
+	defer config.LOG_RETENTION_LEVELS.Set(config.LOG_RETENTION_LEVELS.Get())
+
+	testCases := []struct {
+		statsDays     int
+		statsTypes    string
+		logDays       int
+		logLevels     string
+		expectedError bool
+	}{
+		{30, "", 0, "", false},
+		{30, "api-request=7, cpu-usage = 0", 10, "trace=1,DEBUG=2", false},
+		{0, "zzz=7", 0, "", true},
+		{0, "api-request", 0, "", true},
+		{0, "api-request=zzz", 0, "", true},
+		{0, "=7", 0, "", true},
+		{0, "", 0, "zzz=1", true},
+		{0, "", 0, "OFF=1", true},
+	}
+
+	for i, testCase := range testCases {
+		config.STATS_RETENTION_DAYS.Set(testCase.statsDays)
+		config.STATS_RETENTION_TYPES.Set(testCase.statsTypes)
+		config.LOG_RETENTION_DAYS.Set(testCase.logDays)
+		config.LOG_RETENTION_LEVELS.Set(testCase.logLevels)
+
+		policy, err := GetPolicy()
+		if err != nil {
+			if !testCase.expectedError {
+				test.Errorf("Case %d: Failed to get policy: '%v'.", i, err)
+			}
+
+			continue
+		}
+
+		if testCase.expectedError {
+			test.Errorf("Case %d: Did not get an expected error.", i)
+			continue
+		}
+
+		if policy.MetricDays[stats.MetricTypeGradingTime] != testCase.statsDays {
+			test.Errorf("Case %d: Unexpected default metric days. Expected: %d, Actual: %d.", i, testCase.statsDays, policy.MetricDays[stats.MetricTypeGradingTime])
+		}
+
+		if policy.LogDays[log.LevelError] != testCase.logDays {
+			test.Errorf("Case %d: Unexpected default log days. Expected: %d, Actual: %d.", i, testCase.logDays, policy.LogDays[log.LevelError])
+		}
+	}
+
+	// Check the overrides from the second case.
+	config.STATS_RETENTION_DAYS.Set(30)
+	config.STATS_RETENTION_TYPES.Set("api-request=7, cpu-usage = 0")
+	config.LOG_RETENTION_DAYS.Set(10)
+	config.LOG_RETENTION_LEVELS.Set("trace=1,DEBUG=2")
+
+	policy, err := GetPolicy()
+	if err != nil {
+		test.Fatalf("Failed to get policy: '%v'.", err)
+	}
+
+	expectedMetricDays := map[stats.MetricType]int{stats.MetricTypeAPIRequest: 7, stats.MetricTypeSystemCPU: 0, stats.MetricTypeTaskTime: 30}
+	for metricType, days := range expectedMetricDays {
+		if policy.MetricDays[metricType] != days {
+			test.Errorf("Unexpected days for metric type '%s'. Expected: %d, Actual: %d.", metricType, days, policy.MetricDays[metricType])
+		}
+	}
+
+	expectedLogDays := map[log.LogLevel]int{log.LevelTrace: 1, log.LevelDebug: 2, log.LevelInfo: 10}
+	for level, days := range expectedLogDays {
+		if policy.LogDays[level] != days {
+			test.Errorf("Unexpected days for log level '%s'. Expected: %d, Actual: %d.", level.String(), days, policy.LogDays[level])
+		}
+	}
+}
+
+func mustGetMetrics(test *testing.T, metricType stats.MetricType) []*stats.Metric {
+	query := stats.Query{Type: metricType, Sort: -1}
+
+	metrics, err := db.GetMetrics(query)
+	if err != nil {
+		test.Fatalf("Failed to get metrics: '%v'.", err)
+	}
+
+	return stats.LimitAndSort(metrics, query)
+}
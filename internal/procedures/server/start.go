@@ -7,11 +7,14 @@ import (
 	"github.com/edulinq/autograder/internal/api/server"
 	"github.com/edulinq/autograder/internal/config"
 	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/grader/queue"
 	"github.com/edulinq/autograder/internal/lockmanager"
 	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/procedures/retention"
 	"github.com/edulinq/autograder/internal/stats"
 	"github.com/edulinq/autograder/internal/systemserver"
 	"github.com/edulinq/autograder/internal/tasks"
+	"github.com/edulinq/autograder/internal/tracing"
 	"github.com/edulinq/autograder/internal/util"
 )
 
@@ -27,6 +30,11 @@ func setup(initiator systemserver.ServerInitiator) error {
 
 	log.Info("Autograder Version.", log.NewAttr("version", version))
 
+	err = tracing.Start()
+	if err != nil {
+		return fmt.Errorf("Failed to start tracing: '%w'.", err)
+	}
+
 	err = db.Open()
 	if err != nil {
 		return fmt.Errorf("Failed to open the database: '%w'.", err)
@@ -48,6 +56,15 @@ func setup(initiator systemserver.ServerInitiator) error {
 	if initiator == systemserver.PRIMARY_SERVER {
 		// Initialize the task engine.
 		tasks.Start()
+
+		// Periodically remove and downsample old stats and logs.
+		retention.Start()
+
+		// Resume grading any queued submissions.
+		err = queue.Start()
+		if err != nil {
+			return fmt.Errorf("Failed to start the grading queue: '%w'.", err)
+		}
 	}
 
 	return nil
@@ -62,6 +79,8 @@ func CleanupAndStop() (err error) {
 	}
 
 	tasks.Stop()
+	queue.Stop()
+	retention.Stop()
 
 	stats.StopCollection()
 
@@ -69,6 +88,7 @@ func CleanupAndStop() (err error) {
 	apiServer = nil
 
 	err = errors.Join(err, db.Close())
+	err = errors.Join(err, tracing.Stop())
 	err = errors.Join(err, util.RemoveRecordedTempDirs())
 
 	log.Debug("Server closed.")
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"html/template"
 	"strings"
+
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
 )
 
 func (this *CourseScoringReport) ToHTML() (string, error) {
@@ -55,6 +58,36 @@ func (this *AssignmentScoringReport) ToInlineHTML() (template.HTML, error) {
 	return template.HTML(html), nil
 }
 
+// Get a full HTML report for a single graded submission (including any test cases).
+func GetSubmissionHTMLReport(info *model.GradingInfo) (string, error) {
+	if info == nil {
+		return "", fmt.Errorf("No grading info provided.")
+	}
+
+	title := fmt.Sprintf("Grading Report for %s", info.Name)
+	templateHTML := fmt.Sprintf(outterShell, title, style, submissionReportTemplate)
+
+	funcs := template.FuncMap{
+		"float": util.FloatToStr,
+		"upper": func(status model.TestCaseStatus) string {
+			return strings.ToUpper(string(status))
+		},
+	}
+
+	tmpl, err := template.New("submission-report").Funcs(funcs).Parse(templateHTML)
+	if err != nil {
+		return "", fmt.Errorf("Could not parse submission report template: '%w'.", err)
+	}
+
+	var builder strings.Builder
+	err = tmpl.Execute(&builder, info)
+	if err != nil {
+		return "", fmt.Errorf("Failed to execute submission report template: '%w'.", err)
+	}
+
+	return builder.String(), nil
+}
+
 // Replacements: [title, head, body]
 var outterShell string = `
     <html>
@@ -125,6 +158,61 @@ var assignmentReportTemplate string = `
     </div>
 `
 
+var submissionReportTemplate string = `
+    <div class='autograder autograder-submission-report'>
+        <div class='ag-header'>
+            <h2>Assignment: {{ .Name }}</h2>
+            <p>Submission: {{ .ID }}</p>
+            <p>Score: {{ float .Score }} / {{ float .MaxPoints }}</p>
+        </div>
+        <div class='ag-body'>
+            {{ if .Prologue }}
+                <pre class='prologue'>{{ .Prologue }}</pre>
+            {{ end }}
+
+            {{ range .Questions }}
+                <div class='question'>
+                    <h3>{{ .Name }}: {{ float .Score }} / {{ float .MaxPoints }}</h3>
+
+                    {{ if .Message }}
+                        <pre class='message'>{{ .Message }}</pre>
+                    {{ end }}
+
+                    {{ if .TestCases }}
+                        <table>
+                            <thead>
+                                <tr>
+                                    <th>Test Case</th>
+                                    <th>Status</th>
+                                    <th>Time (ms)</th>
+                                    <th>Details</th>
+                                </tr>
+                            </thead>
+                            <tbody>
+                                {{ range .TestCases }}
+                                    <tr class='{{ .Status }}'>
+                                        <td class='text'>{{ .Name }}</td>
+                                        <td class='text status'>{{ upper .Status }}</td>
+                                        <td class='numeric'>{{ if .DurationMSecs }}{{ .DurationMSecs }}{{ end }}</td>
+                                        <td class='text'>
+                                            {{ if .Message }}<pre class='message'>{{ .Message }}</pre>{{ end }}
+                                            {{ if and (eq .Status "fail") .Diff }}<pre class='diff'>{{ .Diff }}</pre>{{ end }}
+                                        </td>
+                                    </tr>
+                                {{ end }}
+                            </tbody>
+                        </table>
+                    {{ end }}
+                </div>
+            {{ end }}
+
+            {{ if .Epilogue }}
+                <pre class='epilogue'>{{ .Epilogue }}</pre>
+            {{ end }}
+        </div>
+    </div>
+`
+
 var style string = `
     <style>
         .autograder-assignment-scoring-report table th,
@@ -149,5 +237,34 @@ var style string = `
         .autograder-assignment-scoring-report table tr:last-child {
             font-style: italic;
         }
+
+        .autograder-submission-report table th,
+        .autograder-submission-report table .text {
+            text-align: left;
+            vertical-align: top;
+        }
+
+        .autograder-submission-report table .numeric {
+            text-align: right;
+            vertical-align: top;
+        }
+
+        .autograder-submission-report table th,
+        .autograder-submission-report table td {
+            padding: 5px;
+            padding-right: 10px;
+        }
+
+        .autograder-submission-report tr.pass .status {
+            color: green;
+        }
+
+        .autograder-submission-report tr.fail .status {
+            color: red;
+        }
+
+        .autograder-submission-report tr.skip .status {
+            color: gray;
+        }
     </style>
 `
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/model"
+)
+
+func TestSubmissionReportHTML(test *testing.T) {
+	info := &model.GradingInfo{
+		ID:        "course::assignment::user::1",
+		Name:      "Assignment <1>",
+		MaxPoints: 2,
+		Score:     1,
+		Questions: []*model.GradedQuestion{
+			&model.GradedQuestion{
+				Name:      "Q1",
+				MaxPoints: 2,
+				Score:     1,
+				TestCases: []*model.GradedTestCase{
+					&model.GradedTestCase{Name: "T1", Status: model.TestCaseStatusPass, DurationMSecs: 12},
+					&model.GradedTestCase{Name: "T2", Status: model.TestCaseStatusFail, Expected: "a\n", Actual: "b\n"},
+				},
+			},
+		},
+	}
+
+	err := info.PrepTestCases()
+	if err != nil {
+		test.Fatalf("Failed to prep test cases: '%v'.", err)
+	}
+
+	html, err := GetSubmissionHTMLReport(info)
+	if err != nil {
+		test.Fatalf("Failed to generate HTML report: '%v'.", err)
+	}
+
+	expectedParts := []string{
+		"<h2>Assignment: Assignment &lt;1&gt;</h2>",
+		"<p>Score: 1 / 2</p>",
+		"<h3>Q1: 1 / 2</h3>",
+		"<tr class='pass'>",
+		"<td class='text'>T1</td>",
+		"<td class='text status'>PASS</td>",
+		"<td class='numeric'>12</td>",
+		"<tr class='fail'>",
+		"<pre class='diff'>",
+		"-a",
+	}
+
+	for _, part := range expectedParts {
+		if !strings.Contains(html, part) {
+			test.Errorf("HTML report is missing '%s': '%s'.", part, html)
+		}
+	}
+
+	_, err = GetSubmissionHTMLReport(nil)
+	if err == nil {
+		test.Fatalf("Did not get an error on a nil grading info.")
+	}
+}
@@ -1,6 +1,7 @@
 package scoring
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -16,7 +17,7 @@ import (
 
 const LOCK_COMMENT string = "__lock__"
 
-func FullAssignmentScoringAndUpload(assignment *model.Assignment, dryRun bool) (map[string]*model.ScoringInfo, error) {
+func FullAssignmentScoringAndUpload(ctx context.Context, assignment *model.Assignment, dryRun bool) (map[string]*model.ScoringInfo, error) {
 	if assignment.GetCourse().GetLMSAdapter() == nil {
 		return nil, fmt.Errorf("Assignment's course has no LMS info associated with it.")
 	}
@@ -26,7 +27,7 @@ func FullAssignmentScoringAndUpload(assignment *model.Assignment, dryRun bool) (
 		return nil, fmt.Errorf("Failed to fetch autograder users: '%w'.", err)
 	}
 
-	lmsScores, err := lms.FetchAssignmentScores(assignment.GetCourse(), assignment.GetLMSID())
+	lmsScores, err := lms.FetchAssignmentScores(ctx, assignment.GetCourse(), assignment.GetLMSID())
 	if err != nil {
 		return nil, fmt.Errorf("Could not fetch LMS grades: '%w'.", err)
 	}
@@ -38,17 +39,22 @@ func FullAssignmentScoringAndUpload(assignment *model.Assignment, dryRun bool) (
 		return nil, fmt.Errorf("Failed to get scoring information: '%w'.", err)
 	}
 
+	err = addManualScores(assignment, scoringInfos)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to add manual grades: '%w'.", err)
+	}
+
 	// Start with each submission getting the raw score.
 	for _, scoringInfo := range scoringInfos {
 		scoringInfo.Score = scoringInfo.RawScore
 	}
 
-	err = ApplyLatePolicy(assignment, users, scoringInfos, dryRun)
+	err = ApplyLatePolicy(ctx, assignment, users, scoringInfos, dryRun)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to apply late policy: '%w'.", err)
 	}
 
-	uploadedScores, err := computeFinalScores(assignment, users, scoringInfos, lmsScores, dryRun)
+	uploadedScores, err := computeFinalScores(ctx, assignment, users, scoringInfos, lmsScores, dryRun)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to apply late policy: '%w'.", err)
 	}
@@ -56,8 +62,33 @@ func FullAssignmentScoringAndUpload(assignment *model.Assignment, dryRun bool) (
 	return uploadedScores, nil
 }
 
+// Add the points from any manual grading into the raw scores.
+// If the scored submission was not manually graded, then the manual grade for the user's most recent graded submission is carried forward (and flagged as stale).
+func addManualScores(assignment *model.Assignment, scoringInfos map[string]*model.ScoringInfo) error {
+	for email, scoringInfo := range scoringInfos {
+		manualGrade, err := db.GetLatestManualGrade(assignment, email, scoringInfo.ID)
+		if err != nil {
+			return fmt.Errorf("Failed to get manual grade for submission '%s': '%w'.", scoringInfo.ID, err)
+		}
+
+		if manualGrade == nil {
+			continue
+		}
+
+		if manualGrade.ShortID != common.GetShortSubmissionID(scoringInfo.ID) {
+			scoringInfo.StaleManualGrade = true
+			log.Info("Using a manual grade from an earlier submission.", assignment,
+				log.NewUserAttr(email), log.NewAttr("submission", scoringInfo.ID), log.NewAttr("manual-grade", manualGrade.ID))
+		}
+
+		scoringInfo.RawScore += manualGrade.GetScore(assignment.Rubric)
+	}
+
+	return nil
+}
+
 func computeFinalScores(
-	assignment *model.Assignment, users map[string]*model.CourseUser, scoringInfos map[string]*model.ScoringInfo, lmsScores []*lmstypes.SubmissionScore, dryRun bool) (map[string]*model.ScoringInfo, error) {
+	ctx context.Context, assignment *model.Assignment, users map[string]*model.CourseUser, scoringInfos map[string]*model.ScoringInfo, lmsScores []*lmstypes.SubmissionScore, dryRun bool) (map[string]*model.ScoringInfo, error) {
 	var err error
 
 	// First, look through comments for locks and autograder notes.
@@ -73,7 +104,7 @@ func computeFinalScores(
 	if dryRun {
 		log.Debug("Dry Run: Skipping upload of final grades.", assignment, log.NewAttr("grades", finalScores))
 	} else {
-		err = lms.UpdateAssignmentScores(assignment.GetCourse(), assignment.GetLMSID(), finalScores)
+		err = lms.UpdateAssignmentScores(ctx, assignment.GetCourse(), assignment.GetLMSID(), finalScores)
 		if err != nil {
 			return nil, fmt.Errorf("Failed to upload final scores: '%w'.", err)
 		}
@@ -83,7 +114,7 @@ func computeFinalScores(
 	if dryRun {
 		log.Debug("Dry Run: Skipping update of final comments.", assignment, log.NewAttr("comments", commentsToUpdate))
 	} else {
-		err = lms.UpdateComments(assignment.GetCourse(), assignment.GetLMSID(), commentsToUpdate)
+		err = lms.UpdateComments(ctx, assignment.GetCourse(), assignment.GetLMSID(), commentsToUpdate)
 		if err != nil {
 			return nil, fmt.Errorf("Failed to update final comments: '%w'.", err)
 		}
@@ -0,0 +1,87 @@
+package scoring
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+func TestAssignmentScoringManualGrade(test *testing.T) {
+	defer db.ResetForTesting()
+
+	email := "course-student@test.edulinq.org"
+
+	testCases := []struct {
+		shortID       string
+		expectedStale bool
+	}{
+		// The scored (most recent) submission.
+		{"1697406272", false},
+
+		// Earlier submissions, the grade is carried forward.
+		{"1697406265", true},
+		{"1697406256", true},
+	}
+
+	for i, testCase := range testCases {
+		db.ResetForTesting()
+
+		assignment := db.MustGetTestAssignment()
+		assignment.LMSID = "001"
+
+		manualGrade := &model.ManualGrade{
+			ShortID:      testCase.shortID,
+			CourseID:     assignment.GetCourse().GetID(),
+			AssignmentID: assignment.GetID(),
+			User:         email,
+			Grader:       "course-grader@test.edulinq.org",
+			Selections:   []string{"style", "docs"},
+			Adjustment:   -0.25,
+		}
+
+		err := manualGrade.Validate(assignment.Rubric)
+		if err != nil {
+			test.Errorf("Case %d: Failed to validate manual grade: '%v'.", i, err)
+			continue
+		}
+
+		err = db.SaveManualGrade(manualGrade)
+		if err != nil {
+			test.Errorf("Case %d: Failed to save manual grade: '%v'.", i, err)
+			continue
+		}
+
+		actual, err := FullAssignmentScoringAndUpload(context.Background(), assignment, true)
+		if err != nil {
+			test.Errorf("Case %d: Assignment score upload (dry run) failed: '%v'.", i, err)
+			continue
+		}
+
+		scoringInfo := actual[email]
+		if scoringInfo == nil {
+			test.Errorf("Case %d: Missing scoring info for '%s': '%s'.", i, email, util.MustToJSONIndent(actual))
+			continue
+		}
+
+		// 2 (autograder) + 1 (style) + 0.5 (docs) - 0.25 (adjustment).
+		expectedScore := 3.25
+
+		if scoringInfo.RawScore != expectedScore {
+			test.Errorf("Case %d: Unexpected raw score. Expected: '%f', Actual: '%f'.", i, expectedScore, scoringInfo.RawScore)
+			continue
+		}
+
+		if scoringInfo.Score != expectedScore {
+			test.Errorf("Case %d: Unexpected score. Expected: '%f', Actual: '%f'.", i, expectedScore, scoringInfo.Score)
+			continue
+		}
+
+		if testCase.expectedStale != scoringInfo.StaleManualGrade {
+			test.Errorf("Case %d: Unexpected stale value. Expected: '%v', Actual: '%v'.", i, testCase.expectedStale, scoringInfo.StaleManualGrade)
+			continue
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package scoring
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/edulinq/autograder/internal/log"
@@ -8,7 +9,7 @@ import (
 )
 
 // Returns: {assignmentID: {email: scoringInfo, ...}, ...}.
-func FullCourseScoringAndUpload(course *model.Course, dryRun bool) (map[string]map[string]*model.ScoringInfo, error) {
+func FullCourseScoringAndUpload(ctx context.Context, course *model.Course, dryRun bool) (map[string]map[string]*model.ScoringInfo, error) {
 	assignments := course.GetSortedAssignments()
 
 	log.Debug("Beginning full scoring for course.", course, log.NewAttr("dry-run", dryRun))
@@ -24,7 +25,7 @@ func FullCourseScoringAndUpload(course *model.Course, dryRun bool) (map[string]m
 			log.NewAttr("index", i),
 			log.NewAttr("dry-run", dryRun))
 
-		uploadedScores, err := FullAssignmentScoringAndUpload(assignment, dryRun)
+		uploadedScores, err := FullAssignmentScoringAndUpload(ctx, assignment, dryRun)
 		if err != nil {
 			return nil, fmt.Errorf("Failed to grade assignment '%s' for course '%s': '%w'.", assignment.GetID(), course.GetID(), err)
 		}
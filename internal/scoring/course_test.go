@@ -1,6 +1,7 @@
 package scoring
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -48,7 +49,7 @@ func TestCourseScoringEmptyStudent(test *testing.T) {
 }
 
 func runAndtestResult(test *testing.T, course *model.Course) {
-	actual, err := FullCourseScoringAndUpload(course, true)
+	actual, err := FullCourseScoringAndUpload(context.Background(), course, true)
 	if err != nil {
 		test.Fatalf("Course score upload (dryrun) failed: '%v'.", err)
 	}
@@ -61,7 +62,7 @@ func runAndtestResult(test *testing.T, course *model.Course) {
 			true, util.MustToJSONIndent(expected), util.MustToJSONIndent(actual))
 	}
 
-	actual, err = FullCourseScoringAndUpload(course, false)
+	actual, err = FullCourseScoringAndUpload(context.Background(), course, false)
 	if err != nil {
 		test.Fatalf("Course score upload failed: '%v'.", err)
 	}
@@ -1,11 +1,13 @@
 package scoring
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"strings"
 
 	"github.com/edulinq/autograder/internal/common"
+	"github.com/edulinq/autograder/internal/db"
 	"github.com/edulinq/autograder/internal/lms"
 	"github.com/edulinq/autograder/internal/lms/lmstypes"
 	"github.com/edulinq/autograder/internal/log"
@@ -30,7 +32,7 @@ type LateDaysInfo struct {
 }
 
 // This assumes that all assignments are in the LMS.
-func ApplyLatePolicy(assignment *model.Assignment, users map[string]*model.CourseUser, scores map[string]*model.ScoringInfo, dryRun bool) error {
+func ApplyLatePolicy(ctx context.Context, assignment *model.Assignment, users map[string]*model.CourseUser, scores map[string]*model.ScoringInfo, dryRun bool) error {
 	policy := assignment.GetLatePolicy()
 	if policy == nil {
 		return nil
@@ -41,7 +43,7 @@ func ApplyLatePolicy(assignment *model.Assignment, users map[string]*model.Cours
 		return nil
 	}
 
-	lmsAssignment, err := lms.FetchAssignment(assignment.GetCourse(), assignment.GetLMSID())
+	lmsAssignment, err := lms.FetchAssignment(ctx, assignment.GetCourse(), assignment.GetLMSID())
 	if err != nil {
 		return err
 	}
@@ -50,7 +52,12 @@ func ApplyLatePolicy(assignment *model.Assignment, users map[string]*model.Cours
 		return fmt.Errorf("Assignment does not have a due date.")
 	}
 
-	applyBaselinePolicy(assignment, policy, users, scores, *lmsAssignment.DueDate)
+	extensions, err := db.GetAssignmentExtensions(assignment)
+	if err != nil {
+		return fmt.Errorf("Failed to get extensions: '%w'.", err)
+	}
+
+	applyBaselinePolicy(assignment, policy, users, scores, *lmsAssignment.DueDate, extensions)
 
 	// Baseline policy is complete.
 	if policy.Type == model.BaselinePolicy {
@@ -69,7 +76,7 @@ func ApplyLatePolicy(assignment *model.Assignment, users map[string]*model.Cours
 
 	if policy.Type == model.LateDays {
 		penalty := lmsAssignment.MaxPoints * policy.Penalty
-		err = applyLateDaysPolicy(policy, assignment, users, scores, penalty, dryRun)
+		err = applyLateDaysPolicy(ctx, policy, assignment, users, scores, penalty, dryRun)
 		if err != nil {
 			return fmt.Errorf("Failed to apply late days policy: '%w'.", err)
 		}
@@ -81,9 +88,11 @@ func ApplyLatePolicy(assignment *model.Assignment, users map[string]*model.Cours
 }
 
 // Apply a common policy.
-func applyBaselinePolicy(assignment *model.Assignment, policy *model.LateGradingPolicy, users map[string]*model.CourseUser, scores map[string]*model.ScoringInfo, dueDate timestamp.Timestamp) {
+// Users with an extension are measured against their own due date.
+func applyBaselinePolicy(assignment *model.Assignment, policy *model.LateGradingPolicy, users map[string]*model.CourseUser, scores map[string]*model.ScoringInfo, dueDate timestamp.Timestamp, extensions map[string]*model.Extension) {
 	for email, score := range scores {
-		score.NumDaysLate = computeLateDays(dueDate, score.SubmissionTime, policy.GraceMinutes)
+		userDueDate := extensions[email].GetDueDate(&dueDate)
+		score.NumDaysLate = computeLateDays(*userDueDate, score.SubmissionTime, policy.GraceMinutes)
 
 		_, ok := users[email]
 		if !ok {
@@ -110,12 +119,12 @@ func applyConstantPolicy(policy *model.LateGradingPolicy, scores map[string]*mod
 	}
 }
 
-func applyLateDaysPolicy(policy *model.LateGradingPolicy, assignment *model.Assignment, users map[string]*model.CourseUser, scores map[string]*model.ScoringInfo, penalty float64, dryRun bool) error {
+func applyLateDaysPolicy(ctx context.Context, policy *model.LateGradingPolicy, assignment *model.Assignment, users map[string]*model.CourseUser, scores map[string]*model.ScoringInfo, penalty float64, dryRun bool) error {
 	if policy.LateDaysLMSID == "" {
 		return fmt.Errorf("Cannot apply late days policy, late days assignment LMS ID is empty.")
 	}
 
-	allLateDays, err := fetchLateDays(policy, assignment)
+	allLateDays, err := fetchLateDays(ctx, policy, assignment)
 	if err != nil {
 		return err
 	}
@@ -182,7 +191,7 @@ func applyLateDaysPolicy(policy *model.LateGradingPolicy, assignment *model.Assi
 		}
 	}
 
-	err = updateLateDays(policy, assignment, lateDaysToUpdate, dryRun)
+	err = updateLateDays(ctx, policy, assignment, lateDaysToUpdate, dryRun)
 	if err != nil {
 		return err
 	}
@@ -190,7 +199,7 @@ func applyLateDaysPolicy(policy *model.LateGradingPolicy, assignment *model.Assi
 	return nil
 }
 
-func updateLateDays(policy *model.LateGradingPolicy, assignment *model.Assignment, lateDaysToUpdate map[string]*LateDaysInfo, dryRun bool) error {
+func updateLateDays(ctx context.Context, policy *model.LateGradingPolicy, assignment *model.Assignment, lateDaysToUpdate map[string]*LateDaysInfo, dryRun bool) error {
 	// Update late days.
 	// Info that does NOT have a LMSCommentID will get the autograder comment added in.
 	grades := make([]*lmstypes.SubmissionScore, 0, len(lateDaysToUpdate))
@@ -215,7 +224,7 @@ func updateLateDays(policy *model.LateGradingPolicy, assignment *model.Assignmen
 	if dryRun {
 		log.Debug("Dry Run: Skipping upload of late days.", assignment, log.NewAttr("grades", grades))
 	} else {
-		err := lms.UpdateAssignmentScores(assignment.GetCourse(), policy.LateDaysLMSID, grades)
+		err := lms.UpdateAssignmentScores(ctx, assignment.GetCourse(), policy.LateDaysLMSID, grades)
 		if err != nil {
 			return fmt.Errorf("Failed to upload late days: '%w'.", err)
 		}
@@ -238,7 +247,7 @@ func updateLateDays(policy *model.LateGradingPolicy, assignment *model.Assignmen
 	if dryRun {
 		log.Debug("Dry Run: Skipping update of late day comments.", assignment, log.NewAttr("comments", comments))
 	} else {
-		err := lms.UpdateComments(assignment.GetCourse(), policy.LateDaysLMSID, comments)
+		err := lms.UpdateComments(ctx, assignment.GetCourse(), policy.LateDaysLMSID, comments)
 		if err != nil {
 			return fmt.Errorf("Failed to update late days comments: '%w'.", err)
 		}
@@ -247,9 +256,9 @@ func updateLateDays(policy *model.LateGradingPolicy, assignment *model.Assignmen
 	return nil
 }
 
-func fetchLateDays(policy *model.LateGradingPolicy, assignment *model.Assignment) (map[string]*LateDaysInfo, error) {
+func fetchLateDays(ctx context.Context, policy *model.LateGradingPolicy, assignment *model.Assignment) (map[string]*LateDaysInfo, error) {
 	// Fetch available late days from the LMS.
-	lmsLateDaysScores, err := lms.FetchAssignmentScores(assignment.GetCourse(), policy.LateDaysLMSID)
+	lmsLateDaysScores, err := lms.FetchAssignmentScores(ctx, assignment.GetCourse(), policy.LateDaysLMSID)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to fetch late days assignment (%s): '%w'.", policy.LateDaysLMSID, err)
 	}
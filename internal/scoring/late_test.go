@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/edulinq/autograder/internal/common"
+	"github.com/edulinq/autograder/internal/model"
 	"github.com/edulinq/autograder/internal/timestamp"
 	"github.com/edulinq/autograder/internal/util"
 )
@@ -102,3 +103,60 @@ func TestComputeLateDaysWithGraceTime(test *testing.T) {
 		}
 	}
 }
+
+func TestApplyBaselinePolicyExtensions(test *testing.T) {
+	var dayMSecs int64 = 24 * 60 * 60 * 1000
+
+	dueDate := timestamp.FromMSecs(10 * dayMSecs)
+	newDueDate := timestamp.FromMSecs(13 * dayMSecs)
+
+	users := map[string]*model.CourseUser{
+		"none@test.edulinq.org":       &model.CourseUser{Email: "none@test.edulinq.org"},
+		"due-date@test.edulinq.org":   &model.CourseUser{Email: "due-date@test.edulinq.org"},
+		"extra-time@test.edulinq.org": &model.CourseUser{Email: "extra-time@test.edulinq.org"},
+	}
+
+	extensions := map[string]*model.Extension{
+		"due-date@test.edulinq.org":   &model.Extension{DueDate: &newDueDate},
+		"extra-time@test.edulinq.org": &model.Extension{ExtraTime: &util.DurationSpec{Days: 1}},
+	}
+
+	policy := &model.LateGradingPolicy{
+		Type:            model.BaselinePolicy,
+		RejectAfterDays: 2,
+	}
+
+	// All submissions are three days late (relative to the assignment's due date).
+	submissionTime := timestamp.FromMSecs(13 * dayMSecs)
+
+	scores := make(map[string]*model.ScoringInfo, len(users))
+	for email := range users {
+		scores[email] = &model.ScoringInfo{SubmissionTime: submissionTime}
+	}
+
+	applyBaselinePolicy(nil, policy, users, scores, dueDate, extensions)
+
+	testCases := []struct {
+		email       string
+		numDaysLate int
+		reject      bool
+	}{
+		{"none@test.edulinq.org", 3, true},
+		{"due-date@test.edulinq.org", 0, false},
+		{"extra-time@test.edulinq.org", 2, false},
+	}
+
+	for i, testCase := range testCases {
+		score := scores[testCase.email]
+
+		if testCase.numDaysLate != score.NumDaysLate {
+			test.Errorf("Case %d: Unexpected number of days late. Expected: %d, Actual: %d.", i, testCase.numDaysLate, score.NumDaysLate)
+			continue
+		}
+
+		if testCase.reject != score.Reject {
+			test.Errorf("Case %d: Unexpected rejection. Expected: %v, Actual: %v.", i, testCase.reject, score.Reject)
+			continue
+		}
+	}
+}
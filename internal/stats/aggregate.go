@@ -0,0 +1,327 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+type AggregateFunction string
+type TimeBucket string
+
+const (
+	AggregateFunctionCount AggregateFunction = "count"
+	AggregateFunctionSum                     = "sum"
+	AggregateFunctionMean                    = "mean"
+	AggregateFunctionMin                     = "min"
+	AggregateFunctionMax                     = "max"
+)
+
+const (
+	TimeBucketNone   TimeBucket = ""
+	TimeBucketMinute            = "minute"
+	TimeBucketHour              = "hour"
+	TimeBucketDay               = "day"
+)
+
+var knownAggregateFunctions = map[AggregateFunction]bool{
+	AggregateFunctionCount: true,
+	AggregateFunctionSum:   true,
+	AggregateFunctionMean:  true,
+	AggregateFunctionMin:   true,
+	AggregateFunctionMax:   true,
+}
+
+// The size of each time bucket (in MS).
+var timeBucketSizes = map[TimeBucket]int64{
+	TimeBucketMinute: 60 * 1000,
+	TimeBucketHour:   60 * 60 * 1000,
+	TimeBucketDay:    24 * 60 * 60 * 1000,
+}
+
+// Options for aggregating metrics on the server (instead of returning each metric).
+// Metrics are grouped by the values of the GroupBy attributes and (optionally) by time bucket,
+// and then each aggregate function is computed over the values of each group.
+type Aggregation struct {
+	// Attributes to group by.
+	// Metrics without an attribute are grouped together (with no value for that attribute).
+	GroupBy []MetricAttribute `json:"group-by,omitempty"`
+
+	// Split groups into time buckets (UTC aligned).
+	// An empty value means no time bucketing.
+	TimeBucket TimeBucket `json:"time-bucket,omitempty"`
+
+	// The functions to compute for each group.
+	// Defaults to just count.
+	Functions []AggregateFunction `json:"functions,omitempty"`
+
+	// Percentiles (in [0, 100]) to compute for each group.
+	// Percentiles are linearly interpolated between the closest values.
+	// Rollup metrics (see Downsample()) only contribute their mean to percentiles.
+	Percentiles []float64 `json:"percentiles,omitempty"`
+}
+
+// The result of aggregating a single group of metrics.
+type AggregateResult struct {
+	// The GroupBy attribute values for this group.
+	Group map[MetricAttribute]any `json:"group,omitempty"`
+
+	// The start of this group's time bucket (only set when time bucketing).
+	BucketStart *timestamp.Timestamp `json:"bucket-start,omitempty"`
+
+	// The result of each aggregate function keyed by the function's name,
+	// with percentiles keyed as "p<percentile>" (e.g., "p50" or "p99.9").
+	Values map[string]float64 `json:"values"`
+}
+
+func (this *Aggregation) Validate() error {
+	if this == nil {
+		return nil
+	}
+
+	for _, attribute := range this.GroupBy {
+		if !knownMetricAttributes[attribute] {
+			return fmt.Errorf("Invalid group by attribute: '%v'.", attribute)
+		}
+	}
+
+	if (this.TimeBucket != TimeBucketNone) && (timeBucketSizes[this.TimeBucket] == 0) {
+		return fmt.Errorf("Unknown time bucket: '%v'.", this.TimeBucket)
+	}
+
+	for _, function := range this.Functions {
+		if !knownAggregateFunctions[function] {
+			return fmt.Errorf("Unknown aggregate function: '%v'.", function)
+		}
+	}
+
+	for _, percentile := range this.Percentiles {
+		if (percentile < 0) || (percentile > 100) || math.IsNaN(percentile) {
+			return fmt.Errorf("Percentile must be in [0, 100], found: '%v'.", percentile)
+		}
+	}
+
+	if (len(this.Functions) == 0) && (len(this.Percentiles) == 0) {
+		this.Functions = []AggregateFunction{AggregateFunctionCount}
+	}
+
+	return nil
+}
+
+// Get the size of the time bucket (in MS), or zero if there is no bucketing.
+func (this *Aggregation) GetBucketSize() int64 {
+	return timeBucketSizes[this.TimeBucket]
+}
+
+// Get the start of the bucket that a time falls in.
+func (this *Aggregation) GetBucketStart(time timestamp.Timestamp) timestamp.Timestamp {
+	size := this.GetBucketSize()
+	if size == 0 {
+		return time
+	}
+
+	// Round down (Go's division rounds towards zero).
+	start := (int64(time) / size) * size
+	if start > int64(time) {
+		start -= size
+	}
+
+	return timestamp.Timestamp(start)
+}
+
+// Get the key that a percentile's value is stored under.
+func GetPercentileKey(percentile float64) string {
+	return "p" + util.FloatToStr(percentile)
+}
+
+// Aggregate metrics (that already match the query's type and attributes) according to the query.
+// The query's time window is applied before aggregating,
+// and the query's sort and limit are applied to the aggregate results.
+// The query should already be validated (if there is no aggregation, only counts are computed).
+func Aggregate(metrics []*Metric, query Query) []*AggregateResult {
+	aggregation := query.Aggregate
+	if aggregation == nil {
+		aggregation = &Aggregation{
+			Functions: []AggregateFunction{AggregateFunctionCount},
+		}
+	}
+
+	groups := make(map[string]*aggregateGroup)
+
+	for _, metric := range metrics {
+		if !query.MatchTimeWindow(metric) {
+			continue
+		}
+
+		groupValues := make(map[MetricAttribute]any, len(aggregation.GroupBy))
+		for _, attribute := range aggregation.GroupBy {
+			value, exists := metric.Attributes[attribute]
+			if exists {
+				groupValues[attribute] = value
+			}
+		}
+
+		var bucketStart *timestamp.Timestamp = nil
+		if aggregation.GetBucketSize() > 0 {
+			start := aggregation.GetBucketStart(metric.Timestamp)
+			bucketStart = &start
+		}
+
+		key := getGroupKey(groupValues, bucketStart)
+
+		group := groups[key]
+		if group == nil {
+			group = &aggregateGroup{
+				groupValues: groupValues,
+				bucketStart: bucketStart,
+				values:      make([]float64, 0),
+			}
+
+			groups[key] = group
+		}
+
+		group.summary.add(metric)
+		group.values = append(group.values, metric.Value)
+	}
+
+	results := make([]*AggregateResult, 0, len(groups))
+	for _, group := range groups {
+		results = append(results, group.toResult(aggregation))
+	}
+
+	return SortAndLimitAggregates(results, query)
+}
+
+// Sort (by time bucket, then group) and limit aggregate results.
+// Results are always sorted so output is stable,
+// a descending query sort (positive) puts the latest buckets first.
+func SortAndLimitAggregates(results []*AggregateResult, query Query) []*AggregateResult {
+	slices.SortStableFunc(results, func(a *AggregateResult, b *AggregateResult) int {
+		aTime := timestamp.Zero()
+		if a.BucketStart != nil {
+			aTime = *a.BucketStart
+		}
+
+		bTime := timestamp.Zero()
+		if b.BucketStart != nil {
+			bTime = *b.BucketStart
+		}
+
+		if aTime != bTime {
+			if query.Sort > 0 {
+				return cmp.Compare(bTime, aTime)
+			}
+
+			return cmp.Compare(aTime, bTime)
+		}
+
+		return strings.Compare(getGroupKey(a.Group, nil), getGroupKey(b.Group, nil))
+	})
+
+	if (query.Limit > 0) && (query.Limit < len(results)) {
+		results = results[0:query.Limit]
+	}
+
+	return results
+}
+
+type aggregateGroup struct {
+	groupValues map[MetricAttribute]any
+	bucketStart *timestamp.Timestamp
+	summary     metricSummary
+
+	// The value of each metric in the group (only used for percentiles).
+	values []float64
+}
+
+func (this *aggregateGroup) toResult(aggregation *Aggregation) *AggregateResult {
+	result := &AggregateResult{
+		BucketStart: this.bucketStart,
+		Values:      this.computeValues(aggregation),
+	}
+
+	if len(this.groupValues) > 0 {
+		result.Group = this.groupValues
+	}
+
+	return result
+}
+
+func (this *aggregateGroup) computeValues(aggregation *Aggregation) map[string]float64 {
+	results := make(map[string]float64, len(aggregation.Functions)+len(aggregation.Percentiles))
+
+	if len(this.values) == 0 {
+		return results
+	}
+
+	for _, function := range aggregation.Functions {
+		switch function {
+		case AggregateFunctionCount:
+			results[string(function)] = float64(this.summary.count)
+		case AggregateFunctionSum:
+			results[string(function)] = this.summary.sum
+		case AggregateFunctionMean:
+			results[string(function)] = this.summary.mean()
+		case AggregateFunctionMin:
+			results[string(function)] = this.summary.min
+		case AggregateFunctionMax:
+			results[string(function)] = this.summary.max
+		}
+	}
+
+	if len(aggregation.Percentiles) > 0 {
+		sorted := slices.Clone(this.values)
+		slices.Sort(sorted)
+
+		for _, percentile := range aggregation.Percentiles {
+			results[GetPercentileKey(percentile)] = computePercentile(sorted, percentile)
+		}
+	}
+
+	return results
+}
+
+// Linear interpolation between the closest ranks (the same as Postgres' percentile_cont()).
+// The values must be sorted and non-empty.
+func computePercentile(sorted []float64, percentile float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := (percentile / 100.0) * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	if lower == upper {
+		return sorted[lower]
+	}
+
+	fraction := rank - float64(lower)
+	return sorted[lower] + (fraction * (sorted[upper] - sorted[lower]))
+}
+
+func getGroupKey(groupValues map[MetricAttribute]any, bucketStart *timestamp.Timestamp) string {
+	parts := make([]string, 0, len(groupValues)+1)
+
+	if bucketStart != nil {
+		parts = append(parts, fmt.Sprintf("%d", int64(*bucketStart)))
+	}
+
+	keys := make([]string, 0, len(groupValues))
+	for key := range groupValues {
+		keys = append(keys, string(key))
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", key, util.MustToJSON(groupValues[MetricAttribute(key)])))
+	}
+
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,205 @@
+package stats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const testMinute int64 = 60 * 1000
+
+var aggregateMetrics []*Metric = []*Metric{
+	&Metric{Timestamp: timestamp.FromMSecs(1*testMinute + 1), Type: MetricTypeGradingTime, Value: 10, Attributes: map[MetricAttribute]any{MetricAttributeCourseID: "C1", MetricAttributeAssignmentID: "A1"}},
+	&Metric{Timestamp: timestamp.FromMSecs(1*testMinute + 2), Type: MetricTypeGradingTime, Value: 20, Attributes: map[MetricAttribute]any{MetricAttributeCourseID: "C1", MetricAttributeAssignmentID: "A1"}},
+	&Metric{Timestamp: timestamp.FromMSecs(1*testMinute + 3), Type: MetricTypeGradingTime, Value: 30, Attributes: map[MetricAttribute]any{MetricAttributeCourseID: "C1", MetricAttributeAssignmentID: "A2"}},
+	&Metric{Timestamp: timestamp.FromMSecs(2*testMinute + 1), Type: MetricTypeGradingTime, Value: 40, Attributes: map[MetricAttribute]any{MetricAttributeCourseID: "C1", MetricAttributeAssignmentID: "A1"}},
+	&Metric{Timestamp: timestamp.FromMSecs(3*testMinute + 1), Type: MetricTypeGradingTime, Value: 50, Attributes: map[MetricAttribute]any{MetricAttributeCourseID: "C2"}},
+}
+
+func TestAggregateBase(test *testing.T) {
+	testCases := []struct {
+		query    Query
+		expected []*AggregateResult
+	}{
+		// Default (count of everything).
+		{
+			Query{Aggregate: &Aggregation{}},
+			[]*AggregateResult{
+				&AggregateResult{Values: map[string]float64{"count": 5}},
+			},
+		},
+
+		// All functions.
+		{
+			Query{Aggregate: &Aggregation{
+				Functions:   []AggregateFunction{AggregateFunctionCount, AggregateFunctionSum, AggregateFunctionMean, AggregateFunctionMin, AggregateFunctionMax},
+				Percentiles: []float64{0, 50, 90, 100},
+			}},
+			[]*AggregateResult{
+				&AggregateResult{Values: map[string]float64{"count": 5, "sum": 150, "mean": 30, "min": 10, "max": 50, "p0": 10, "p50": 30, "p90": 46, "p100": 50}},
+			},
+		},
+
+		// Group by.
+		{
+			Query{Aggregate: &Aggregation{
+				GroupBy:   []MetricAttribute{MetricAttributeAssignmentID},
+				Functions: []AggregateFunction{AggregateFunctionMean},
+			}},
+			[]*AggregateResult{
+				&AggregateResult{Values: map[string]float64{"mean": 50}},
+				&AggregateResult{Group: map[MetricAttribute]any{MetricAttributeAssignmentID: "A1"}, Values: map[string]float64{"mean": 70.0 / 3.0}},
+				&AggregateResult{Group: map[MetricAttribute]any{MetricAttributeAssignmentID: "A2"}, Values: map[string]float64{"mean": 30}},
+			},
+		},
+
+		// Multiple group by.
+		{
+			Query{Aggregate: &Aggregation{
+				GroupBy: []MetricAttribute{MetricAttributeCourseID, MetricAttributeAssignmentID},
+			}},
+			[]*AggregateResult{
+				&AggregateResult{Group: map[MetricAttribute]any{MetricAttributeCourseID: "C1", MetricAttributeAssignmentID: "A1"}, Values: map[string]float64{"count": 3}},
+				&AggregateResult{Group: map[MetricAttribute]any{MetricAttributeCourseID: "C1", MetricAttributeAssignmentID: "A2"}, Values: map[string]float64{"count": 1}},
+				&AggregateResult{Group: map[MetricAttribute]any{MetricAttributeCourseID: "C2"}, Values: map[string]float64{"count": 1}},
+			},
+		},
+
+		// Time buckets.
+		{
+			Query{Aggregate: &Aggregation{
+				TimeBucket: TimeBucketMinute,
+				Functions:  []AggregateFunction{AggregateFunctionSum},
+			}},
+			[]*AggregateResult{
+				&AggregateResult{BucketStart: timestampPointer(1 * testMinute), Values: map[string]float64{"sum": 60}},
+				&AggregateResult{BucketStart: timestampPointer(2 * testMinute), Values: map[string]float64{"sum": 40}},
+				&AggregateResult{BucketStart: timestampPointer(3 * testMinute), Values: map[string]float64{"sum": 50}},
+			},
+		},
+
+		// Time buckets, descending, limited.
+		{
+			Query{Sort: 1, Limit: 2, Aggregate: &Aggregation{
+				TimeBucket: TimeBucketMinute,
+				Functions:  []AggregateFunction{AggregateFunctionSum},
+			}},
+			[]*AggregateResult{
+				&AggregateResult{BucketStart: timestampPointer(3 * testMinute), Values: map[string]float64{"sum": 50}},
+				&AggregateResult{BucketStart: timestampPointer(2 * testMinute), Values: map[string]float64{"sum": 40}},
+			},
+		},
+
+		// Time buckets and group by.
+		{
+			Query{Aggregate: &Aggregation{
+				GroupBy:    []MetricAttribute{MetricAttributeAssignmentID},
+				TimeBucket: TimeBucketHour,
+			}},
+			[]*AggregateResult{
+				&AggregateResult{BucketStart: timestampPointer(0), Values: map[string]float64{"count": 1}},
+				&AggregateResult{BucketStart: timestampPointer(0), Group: map[MetricAttribute]any{MetricAttributeAssignmentID: "A1"}, Values: map[string]float64{"count": 3}},
+				&AggregateResult{BucketStart: timestampPointer(0), Group: map[MetricAttribute]any{MetricAttributeAssignmentID: "A2"}, Values: map[string]float64{"count": 1}},
+			},
+		},
+
+		// Time window.
+		{
+			Query{After: timestamp.FromMSecs(1*testMinute + 2), Before: timestamp.FromMSecs(3 * testMinute), Aggregate: &Aggregation{}},
+			[]*AggregateResult{
+				&AggregateResult{Values: map[string]float64{"count": 2}},
+			},
+		},
+		{
+			Query{After: timestamp.FromMSecs(10 * testMinute), Aggregate: &Aggregation{}},
+			[]*AggregateResult{},
+		},
+	}
+
+	for i, testCase := range testCases {
+		testCase.query.Type = MetricTypeGradingTime
+
+		err := testCase.query.Validate()
+		if err != nil {
+			test.Errorf("Case %d: Failed to validate query: '%v'.", i, err)
+			continue
+		}
+
+		actual := Aggregate(aggregateMetrics, testCase.query)
+		if !reflect.DeepEqual(testCase.expected, actual) {
+			test.Errorf("Case %d: Unexpected results. Expected: '%s', Actual: '%s'.", i,
+				util.MustToJSONIndent(testCase.expected), util.MustToJSONIndent(actual))
+			continue
+		}
+	}
+}
+
+func TestAggregationValidate(test *testing.T) {
+	testCases := []struct {
+		aggregation    *Aggregation
+		errorSubstring string
+	}{
+		{nil, ""},
+		{&Aggregation{}, ""},
+		{&Aggregation{GroupBy: []MetricAttribute{MetricAttributeCourseID}, TimeBucket: TimeBucketDay, Percentiles: []float64{0, 99.9, 100}}, ""},
+
+		{&Aggregation{GroupBy: []MetricAttribute{"ZZZ"}}, "Invalid group by attribute: 'ZZZ'."},
+		{&Aggregation{TimeBucket: "week"}, "Unknown time bucket: 'week'."},
+		{&Aggregation{Functions: []AggregateFunction{"median"}}, "Unknown aggregate function: 'median'."},
+		{&Aggregation{Percentiles: []float64{-1}}, "Percentile must be in [0, 100]"},
+		{&Aggregation{Percentiles: []float64{101}}, "Percentile must be in [0, 100]"},
+	}
+
+	for i, testCase := range testCases {
+		err := testCase.aggregation.Validate()
+		if err != nil {
+			if testCase.errorSubstring == "" {
+				test.Errorf("Case %d: Unexpected error: '%v'.", i, err)
+			} else if !strings.Contains(err.Error(), testCase.errorSubstring) {
+				test.Errorf("Case %d: Did not get expected error output. Expected Substring '%s', Actual Error: '%v'.", i, testCase.errorSubstring, err)
+			}
+
+			continue
+		}
+
+		if testCase.errorSubstring != "" {
+			test.Errorf("Case %d: Did not get expected error '%s'.", i, testCase.errorSubstring)
+			continue
+		}
+	}
+}
+
+func TestAggregationGetBucketStart(test *testing.T) {
+	testCases := []struct {
+		bucket   TimeBucket
+		time     int64
+		expected int64
+	}{
+		{TimeBucketNone, 123, 123},
+		{TimeBucketMinute, 0, 0},
+		{TimeBucketMinute, 59999, 0},
+		{TimeBucketMinute, 60000, 60000},
+		{TimeBucketMinute, -1, -60000},
+		{TimeBucketMinute, -60000, -60000},
+		{TimeBucketHour, 3*60*60*1000 + 5, 3 * 60 * 60 * 1000},
+		{TimeBucketDay, 36 * 60 * 60 * 1000, 24 * 60 * 60 * 1000},
+	}
+
+	for i, testCase := range testCases {
+		aggregation := &Aggregation{TimeBucket: testCase.bucket}
+
+		actual := aggregation.GetBucketStart(timestamp.FromMSecs(testCase.time))
+		if timestamp.FromMSecs(testCase.expected) != actual {
+			test.Errorf("Case %d: Unexpected bucket start. Expected: '%d', Actual: '%d'.", i, testCase.expected, actual)
+			continue
+		}
+	}
+}
+
+func timestampPointer(msecs int64) *timestamp.Timestamp {
+	value := timestamp.FromMSecs(msecs)
+	return &value
+}
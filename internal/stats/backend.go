@@ -39,6 +39,9 @@ func GetMetrics(query Query) ([]*Metric, error) {
 }
 
 func StoreMetric(record *Metric) error {
+	// Live values are kept even if there is no backend.
+	observeMetric(record)
+
 	backendLock.RLock()
 	defer backendLock.RUnlock()
 
@@ -0,0 +1,448 @@
+package stats
+
+// Live (in-memory) metrics that can be scraped in the Prometheus/OpenMetrics text format.
+// Values are updated as metrics are stored, so a scrape never needs to query the storage backend.
+// Note that these values are only for the life of the current process (they are not loaded from the backend on start).
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	EXPORT_NAME_PREFIX = "autograder_"
+
+	EXPORT_CONTENT_TYPE_TEXT        = "text/plain; version=0.0.4; charset=utf-8"
+	EXPORT_CONTENT_TYPE_OPENMETRICS = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+type exportKind string
+
+const (
+	exportKindCounter   exportKind = "counter"
+	exportKindGauge     exportKind = "gauge"
+	exportKindHistogram exportKind = "histogram"
+)
+
+var (
+	durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	gradingBuckets  = []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+	memoryBuckets   = []float64{16 << 20, 32 << 20, 64 << 20, 128 << 20, 256 << 20, 512 << 20, 1 << 30, 2 << 30, 4 << 30}
+)
+
+// How a stored metric type is exported.
+type exportSpec struct {
+	// The family name (without the prefix or a counter's "_total" suffix).
+	Name string
+	Help string
+	Kind exportKind
+
+	// Metric attributes that become labels.
+	// User emails are never used as labels (they would make too many series).
+	Labels []MetricAttribute
+
+	// If set, the metric's value is used as this label's value and the counter is incremented by one.
+	ValueLabel string
+
+	// The metric's value is multiplied by this before being recorded (e.g., to convert milliseconds to seconds).
+	// Zero means no scaling.
+	Scale float64
+
+	Buckets []float64
+}
+
+var exportSpecs = map[MetricType]*exportSpec{
+	MetricTypeAPIRequest: &exportSpec{
+		Name:    "api_request_duration_seconds",
+		Help:    "The time it took to respond to API requests.",
+		Kind:    exportKindHistogram,
+		Labels:  []MetricAttribute{MetricAttributeEndpoint, MetricAttributeLocator},
+		Scale:   0.001,
+		Buckets: durationBuckets,
+	},
+	MetricTypeCodeAnalysisTime: &exportSpec{
+		Name:    "code_analysis_duration_seconds",
+		Help:    "The time it took to run code analysis.",
+		Kind:    exportKindHistogram,
+		Labels:  []MetricAttribute{MetricAttributeCourseID, MetricAttributeAnalysisType},
+		Scale:   0.001,
+		Buckets: gradingBuckets,
+	},
+	MetricTypeGradingCPUTime: &exportSpec{
+		Name:    "grading_cpu_seconds",
+		Help:    "The CPU time used by graders.",
+		Kind:    exportKindHistogram,
+		Labels:  []MetricAttribute{MetricAttributeCourseID, MetricAttributeAssignmentID},
+		Scale:   0.001,
+		Buckets: gradingBuckets,
+	},
+	MetricTypeGradingExitCode: &exportSpec{
+		Name:       "grading_exits",
+		Help:       "The number of graders that exited with each exit code.",
+		Kind:       exportKindCounter,
+		Labels:     []MetricAttribute{MetricAttributeCourseID, MetricAttributeAssignmentID},
+		ValueLabel: "code",
+	},
+	MetricTypeGradingOOMKill: &exportSpec{
+		Name:   "grading_oom_kills",
+		Help:   "The number of graders that were killed for running out of memory.",
+		Kind:   exportKindCounter,
+		Labels: []MetricAttribute{MetricAttributeCourseID, MetricAttributeAssignmentID},
+	},
+	MetricTypeGradingPeakMemory: &exportSpec{
+		Name:    "grading_peak_memory_bytes",
+		Help:    "The peak memory used by graders.",
+		Kind:    exportKindHistogram,
+		Labels:  []MetricAttribute{MetricAttributeCourseID, MetricAttributeAssignmentID},
+		Buckets: memoryBuckets,
+	},
+	MetricTypeGradingRunTime: &exportSpec{
+		Name:    "grading_run_seconds",
+		Help:    "The wall time that graders were running for.",
+		Kind:    exportKindHistogram,
+		Labels:  []MetricAttribute{MetricAttributeCourseID, MetricAttributeAssignmentID},
+		Scale:   0.001,
+		Buckets: gradingBuckets,
+	},
+	MetricTypeGradingTime: &exportSpec{
+		Name:    "grading_duration_seconds",
+		Help:    "The total time it took to grade successful submissions.",
+		Kind:    exportKindHistogram,
+		Labels:  []MetricAttribute{MetricAttributeCourseID, MetricAttributeAssignmentID},
+		Scale:   0.001,
+		Buckets: gradingBuckets,
+	},
+	MetricTypeGradingWaitTime: &exportSpec{
+		Name:    "grading_wait_seconds",
+		Help:    "The time submissions waited for a grading slot.",
+		Kind:    exportKindHistogram,
+		Labels:  []MetricAttribute{MetricAttributeCourseID},
+		Scale:   0.001,
+		Buckets: gradingBuckets,
+	},
+	MetricTypeSystemCPU: &exportSpec{
+		Name: "system_cpu_percent",
+		Help: "The most recent system CPU usage.",
+		Kind: exportKindGauge,
+	},
+	MetricTypeSystemMemory: &exportSpec{
+		Name: "system_memory_percent",
+		Help: "The most recent system memory usage.",
+		Kind: exportKindGauge,
+	},
+	MetricTypeSystemNetworkIn: &exportSpec{
+		Name: "system_network_received_bytes",
+		Help: "The number of bytes received over the network.",
+		Kind: exportKindCounter,
+	},
+	MetricTypeSystemNetworkOut: &exportSpec{
+		Name: "system_network_sent_bytes",
+		Help: "The number of bytes sent over the network.",
+		Kind: exportKindCounter,
+	},
+	MetricTypeTaskTime: &exportSpec{
+		Name:    "task_duration_seconds",
+		Help:    "The time it took to run tasks.",
+		Kind:    exportKindHistogram,
+		Labels:  []MetricAttribute{MetricAttributeCourseID, MetricAttributeTaskType},
+		Scale:   0.001,
+		Buckets: gradingBuckets,
+	},
+}
+
+// A single set of label values within a family.
+type exportSeries struct {
+	labelValues []string
+
+	// The value of a counter or gauge, or the sum of a histogram.
+	value float64
+
+	// Histograms only.
+	count        uint64
+	bucketCounts []uint64
+}
+
+type exportFamily struct {
+	spec   *exportSpec
+	series map[string]*exportSeries
+}
+
+// A gauge whose value is computed at scrape time (e.g., the number of running graders).
+type exportGaugeFunc struct {
+	name      string
+	help      string
+	valueFunc func() float64
+}
+
+var (
+	exportLock       sync.Mutex
+	exportFamilies   map[MetricType]*exportFamily = make(map[MetricType]*exportFamily)
+	exportGaugeFuncs map[string]*exportGaugeFunc  = make(map[string]*exportGaugeFunc)
+)
+
+// Register a gauge that is computed whenever metrics are exported.
+// The name should not include the common prefix.
+// Registering the same name again replaces the previous gauge.
+// The value func may be called concurrently and should be cheap.
+func RegisterGaugeFunc(name string, help string, valueFunc func() float64) {
+	exportLock.Lock()
+	defer exportLock.Unlock()
+
+	exportGaugeFuncs[name] = &exportGaugeFunc{
+		name:      name,
+		help:      help,
+		valueFunc: valueFunc,
+	}
+}
+
+// Update the live values with a metric.
+// Metrics without an export spec are ignored.
+func observeMetric(metric *Metric) {
+	if metric == nil {
+		return
+	}
+
+	spec := exportSpecs[metric.Type]
+	if spec == nil {
+		return
+	}
+
+	labelValues := make([]string, 0, len(spec.Labels)+1)
+	for _, attribute := range spec.Labels {
+		labelValues = append(labelValues, getAttributeString(metric.Attributes, attribute))
+	}
+
+	value := metric.Value
+	if spec.Scale != 0 {
+		value *= spec.Scale
+	}
+
+	if spec.ValueLabel != "" {
+		labelValues = append(labelValues, formatExportValue(metric.Value))
+		value = 1
+	}
+
+	exportLock.Lock()
+	defer exportLock.Unlock()
+
+	family := exportFamilies[metric.Type]
+	if family == nil {
+		family = &exportFamily{
+			spec:   spec,
+			series: make(map[string]*exportSeries),
+		}
+
+		exportFamilies[metric.Type] = family
+	}
+
+	key := strings.Join(labelValues, "\x00")
+	series := family.series[key]
+	if series == nil {
+		series = &exportSeries{
+			labelValues:  labelValues,
+			bucketCounts: make([]uint64, len(spec.Buckets)),
+		}
+
+		family.series[key] = series
+	}
+
+	switch spec.Kind {
+	case exportKindCounter:
+		series.value += value
+	case exportKindGauge:
+		series.value = value
+	case exportKindHistogram:
+		series.value += value
+		series.count++
+
+		for i, bound := range spec.Buckets {
+			if value <= bound {
+				series.bucketCounts[i]++
+			}
+		}
+	}
+}
+
+// Write all live metrics in the Prometheus text format (or the OpenMetrics text format).
+func WriteExport(writer io.Writer, openMetrics bool) error {
+	exportLock.Lock()
+	text := buildExport(openMetrics)
+	exportLock.Unlock()
+
+	_, err := io.WriteString(writer, text)
+	if err != nil {
+		return fmt.Errorf("Failed to write exported metrics: '%w'.", err)
+	}
+
+	return nil
+}
+
+// The caller must hold the export lock.
+func buildExport(openMetrics bool) string {
+	var builder strings.Builder
+
+	metricTypes := make([]MetricType, 0, len(exportFamilies))
+	for metricType := range exportFamilies {
+		metricTypes = append(metricTypes, metricType)
+	}
+
+	slices.SortFunc(metricTypes, func(a MetricType, b MetricType) int {
+		return strings.Compare(exportSpecs[a].Name, exportSpecs[b].Name)
+	})
+
+	for _, metricType := range metricTypes {
+		writeFamily(&builder, exportFamilies[metricType], openMetrics)
+	}
+
+	names := make([]string, 0, len(exportGaugeFuncs))
+	for name := range exportGaugeFuncs {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		gauge := exportGaugeFuncs[name]
+		fullName := EXPORT_NAME_PREFIX + gauge.name
+
+		writeHeader(&builder, fullName, gauge.help, exportKindGauge)
+		writeSample(&builder, fullName, nil, nil, gauge.valueFunc())
+	}
+
+	if openMetrics {
+		builder.WriteString("# EOF\n")
+	}
+
+	return builder.String()
+}
+
+func writeFamily(builder *strings.Builder, family *exportFamily, openMetrics bool) {
+	spec := family.spec
+	name := EXPORT_NAME_PREFIX + spec.Name
+
+	labelNames := make([]string, 0, len(spec.Labels)+1)
+	for _, attribute := range spec.Labels {
+		labelNames = append(labelNames, toLabelName(attribute))
+	}
+
+	if spec.ValueLabel != "" {
+		labelNames = append(labelNames, spec.ValueLabel)
+	}
+
+	// OpenMetrics names a counter's family without the "_total" suffix.
+	if (spec.Kind == exportKindCounter) && !openMetrics {
+		writeHeader(builder, name+"_total", spec.Help, spec.Kind)
+	} else {
+		writeHeader(builder, name, spec.Help, spec.Kind)
+	}
+
+	keys := make([]string, 0, len(family.series))
+	for key := range family.series {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		series := family.series[key]
+
+		switch spec.Kind {
+		case exportKindCounter:
+			writeSample(builder, name+"_total", labelNames, series.labelValues, series.value)
+		case exportKindGauge:
+			writeSample(builder, name, labelNames, series.labelValues, series.value)
+		case exportKindHistogram:
+			bucketLabelNames := append(slices.Clone(labelNames), "le")
+
+			for i, bound := range spec.Buckets {
+				bucketLabelValues := append(slices.Clone(series.labelValues), formatExportValue(bound))
+				writeSample(builder, name+"_bucket", bucketLabelNames, bucketLabelValues, float64(series.bucketCounts[i]))
+			}
+
+			writeSample(builder, name+"_bucket", bucketLabelNames, append(slices.Clone(series.labelValues), "+Inf"), float64(series.count))
+			writeSample(builder, name+"_sum", labelNames, series.labelValues, series.value)
+			writeSample(builder, name+"_count", labelNames, series.labelValues, float64(series.count))
+		}
+	}
+}
+
+func writeHeader(builder *strings.Builder, name string, help string, kind exportKind) {
+	fmt.Fprintf(builder, "# HELP %s %s\n", name, escapeExportText(help, false))
+	fmt.Fprintf(builder, "# TYPE %s %s\n", name, kind)
+}
+
+func writeSample(builder *strings.Builder, name string, labelNames []string, labelValues []string, value float64) {
+	builder.WriteString(name)
+
+	if len(labelNames) > 0 {
+		builder.WriteString("{")
+
+		for i, labelName := range labelNames {
+			if i > 0 {
+				builder.WriteString(",")
+			}
+
+			fmt.Fprintf(builder, `%s="%s"`, labelName, escapeExportText(labelValues[i], true))
+		}
+
+		builder.WriteString("}")
+	}
+
+	builder.WriteString(" ")
+	builder.WriteString(formatExportValue(value))
+	builder.WriteString("\n")
+}
+
+func formatExportValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+
+	if math.IsInf(value, -1) {
+		return "-Inf"
+	}
+
+	if math.IsNaN(value) {
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Label names cannot contain dashes.
+func toLabelName(attribute MetricAttribute) string {
+	return strings.ReplaceAll(string(attribute), "-", "_")
+}
+
+func escapeExportText(text string, quoted bool) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, "\n", `\n`)
+
+	if quoted {
+		text = strings.ReplaceAll(text, `"`, `\"`)
+	}
+
+	return text
+}
+
+func getAttributeString(attributes map[MetricAttribute]any, attribute MetricAttribute) string {
+	value, ok := attributes[attribute]
+	if !ok || (value == nil) {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// Clear all live values (but keep any registered gauges).
+func ResetExportForTesting() {
+	exportLock.Lock()
+	defer exportLock.Unlock()
+
+	exportFamilies = make(map[MetricType]*exportFamily)
+}
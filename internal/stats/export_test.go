@@ -0,0 +1,179 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/timestamp"
+)
+
+func TestWriteExportBase(test *testing.T) {
+	ResetExportForTesting()
+	defer ResetExportForTesting()
+
+	defer clearBackend()
+	SetStorageBackend(makeTestBackend())
+
+	metrics := []*Metric{
+		&Metric{
+			Type:  MetricTypeAPIRequest,
+			Value: 20,
+			Attributes: map[MetricAttribute]any{
+				MetricAttributeEndpoint:  "/api/v03/users/get",
+				MetricAttributeUserEmail: "course-student@test.edulinq.org",
+			},
+		},
+		&Metric{
+			Type:  MetricTypeAPIRequest,
+			Value: 2000,
+			Attributes: map[MetricAttribute]any{
+				MetricAttributeEndpoint: "/api/v03/users/get",
+			},
+		},
+		&Metric{
+			Type:  MetricTypeAPIRequest,
+			Value: 1,
+			Attributes: map[MetricAttribute]any{
+				MetricAttributeEndpoint: "/api/v03/users/get",
+				MetricAttributeLocator:  "-001",
+			},
+		},
+		&Metric{
+			Type:  MetricTypeGradingExitCode,
+			Value: 0,
+			Attributes: map[MetricAttribute]any{
+				MetricAttributeCourseID:     "course101",
+				MetricAttributeAssignmentID: "hw0",
+			},
+		},
+		&Metric{
+			Type:  MetricTypeGradingExitCode,
+			Value: 0,
+			Attributes: map[MetricAttribute]any{
+				MetricAttributeCourseID:     "course101",
+				MetricAttributeAssignmentID: "hw0",
+			},
+		},
+		&Metric{
+			Type:  MetricTypeSystemCPU,
+			Value: 10,
+		},
+		&Metric{
+			Type:  MetricTypeSystemCPU,
+			Value: 12.5,
+		},
+		&Metric{
+			Type:  MetricTypeSystemNetworkIn,
+			Value: 100,
+		},
+		&Metric{
+			Type:  MetricTypeSystemNetworkIn,
+			Value: 50,
+		},
+	}
+
+	for i, metric := range metrics {
+		metric.Timestamp = timestamp.FromMSecs(int64(100 + i))
+
+		err := StoreMetric(metric)
+		if err != nil {
+			test.Fatalf("Failed to store metric %d: '%v'.", i, err)
+		}
+	}
+
+	testCases := []struct {
+		openMetrics bool
+		expected    []string
+		notExpected []string
+	}{
+		{
+			openMetrics: false,
+			expected: []string{
+				"# TYPE autograder_api_request_duration_seconds histogram\n",
+				`autograder_api_request_duration_seconds_bucket{endpoint="/api/v03/users/get",locator="",le="0.025"} 1` + "\n",
+				`autograder_api_request_duration_seconds_bucket{endpoint="/api/v03/users/get",locator="",le="2.5"} 2` + "\n",
+				`autograder_api_request_duration_seconds_bucket{endpoint="/api/v03/users/get",locator="",le="+Inf"} 2` + "\n",
+				`autograder_api_request_duration_seconds_sum{endpoint="/api/v03/users/get",locator=""} 2.02` + "\n",
+				`autograder_api_request_duration_seconds_count{endpoint="/api/v03/users/get",locator=""} 2` + "\n",
+				`autograder_api_request_duration_seconds_count{endpoint="/api/v03/users/get",locator="-001"} 1` + "\n",
+				"# TYPE autograder_grading_exits_total counter\n",
+				`autograder_grading_exits_total{course="course101",assignment="hw0",code="0"} 2` + "\n",
+				"# TYPE autograder_system_cpu_percent gauge\n",
+				"autograder_system_cpu_percent 12.5\n",
+				"autograder_system_network_received_bytes_total 150\n",
+				"# TYPE autograder_grading_in_flight_test gauge\n",
+				"autograder_grading_in_flight_test 3\n",
+			},
+			notExpected: []string{
+				"course-student@test.edulinq.org",
+				"# EOF",
+			},
+		},
+		{
+			openMetrics: true,
+			expected: []string{
+				"# TYPE autograder_grading_exits counter\n",
+				`autograder_grading_exits_total{course="course101",assignment="hw0",code="0"} 2` + "\n",
+				"# TYPE autograder_system_network_received_bytes counter\n",
+				"autograder_system_network_received_bytes_total 150\n",
+				"# EOF\n",
+			},
+		},
+	}
+
+	RegisterGaugeFunc("grading_in_flight_test", "A test gauge.", func() float64 {
+		return 3
+	})
+
+	defer func() {
+		exportLock.Lock()
+		defer exportLock.Unlock()
+
+		delete(exportGaugeFuncs, "grading_in_flight_test")
+	}()
+
+	for i, testCase := range testCases {
+		var builder strings.Builder
+		err := WriteExport(&builder, testCase.openMetrics)
+		if err != nil {
+			test.Errorf("Case %d: Failed to write export: '%v'.", i, err)
+			continue
+		}
+
+		text := builder.String()
+
+		for _, expected := range testCase.expected {
+			if !strings.Contains(text, expected) {
+				test.Errorf("Case %d: Export is missing expected text '%s'. Export: '%s'.", i, expected, text)
+			}
+		}
+
+		for _, notExpected := range testCase.notExpected {
+			if strings.Contains(text, notExpected) {
+				test.Errorf("Case %d: Export has unexpected text '%s'. Export: '%s'.", i, notExpected, text)
+			}
+		}
+	}
+}
+
+func TestEscapeExportText(test *testing.T) {
+	testCases := []struct {
+		text     string
+		quoted   bool
+		expected string
+	}{
+		{`abc`, true, `abc`},
+		{`a"b`, true, `a\"b`},
+		{`a"b`, false, `a"b`},
+		{`a\b`, true, `a\\b`},
+		{"a\nb", false, `a\nb`},
+	}
+
+	for i, testCase := range testCases {
+		actual := escapeExportText(testCase.text, testCase.quoted)
+		if testCase.expected != actual {
+			test.Errorf("Case %d: Unexpected escaped text. Expected: '%s', Actual: '%s'.", i, testCase.expected, actual)
+			continue
+		}
+	}
+}
@@ -2,6 +2,7 @@ package stats
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/edulinq/autograder/internal/config"
@@ -27,15 +28,21 @@ const (
 
 // Values for the type field inside of Metric and Query.
 const (
-	MetricTypeUnknown          MetricType = ""
-	MetricTypeAPIRequest                  = "api-request"
-	MetricTypeCodeAnalysisTime            = "code-analysis-time"
-	MetricTypeGradingTime                 = "grading-time"
-	MetricTypeSystemCPU                   = "cpu-usage"
-	MetricTypeSystemMemory                = "mem-usage"
-	MetricTypeSystemNetworkIn             = "net-in"
-	MetricTypeSystemNetworkOut            = "net-out"
-	MetricTypeTaskTime                    = "task-time"
+	MetricTypeUnknown           MetricType = ""
+	MetricTypeAPIRequest                   = "api-request"
+	MetricTypeCodeAnalysisTime             = "code-analysis-time"
+	MetricTypeGradingCPUTime               = "grading-cpu-time"
+	MetricTypeGradingExitCode              = "grading-exit-code"
+	MetricTypeGradingOOMKill               = "grading-oom-kill"
+	MetricTypeGradingPeakMemory            = "grading-peak-memory"
+	MetricTypeGradingRunTime               = "grading-run-time"
+	MetricTypeGradingTime                  = "grading-time"
+	MetricTypeGradingWaitTime              = "grading-wait-time"
+	MetricTypeSystemCPU                    = "cpu-usage"
+	MetricTypeSystemMemory                 = "mem-usage"
+	MetricTypeSystemNetworkIn              = "net-in"
+	MetricTypeSystemNetworkOut             = "net-out"
+	MetricTypeTaskTime                     = "task-time"
 )
 
 type Metric struct {
@@ -47,19 +54,29 @@ type Metric struct {
 
 	// Additional attributes that are not standard enough to be formalized in fields.
 	Attributes map[MetricAttribute]any `json:"attributes,omitempty"`
+
+	// Only set when this metric summarizes multiple downsampled metrics (see Downsample()).
+	// The value of a rollup is the mean of the metrics it summarizes.
+	Rollup *MetricRollup `json:"rollup,omitempty"`
 }
 
 // Map for quick existence check (empty value does not count).
 var knownMetricTypes = map[MetricType]bool{
-	MetricTypeUnknown:          false,
-	MetricTypeAPIRequest:       true,
-	MetricTypeCodeAnalysisTime: true,
-	MetricTypeGradingTime:      true,
-	MetricTypeSystemCPU:        true,
-	MetricTypeSystemMemory:     true,
-	MetricTypeSystemNetworkIn:  true,
-	MetricTypeSystemNetworkOut: true,
-	MetricTypeTaskTime:         true,
+	MetricTypeUnknown:           false,
+	MetricTypeAPIRequest:        true,
+	MetricTypeCodeAnalysisTime:  true,
+	MetricTypeGradingCPUTime:    true,
+	MetricTypeGradingExitCode:   true,
+	MetricTypeGradingOOMKill:    true,
+	MetricTypeGradingPeakMemory: true,
+	MetricTypeGradingRunTime:    true,
+	MetricTypeGradingTime:       true,
+	MetricTypeGradingWaitTime:   true,
+	MetricTypeSystemCPU:         true,
+	MetricTypeSystemMemory:      true,
+	MetricTypeSystemNetworkIn:   true,
+	MetricTypeSystemNetworkOut:  true,
+	MetricTypeTaskTime:          true,
 }
 
 // Map for quick existence check (empty value does not count).
@@ -146,6 +163,20 @@ func validateType(metricType MetricType) error {
 	return nil
 }
 
+// Get all the known metric types (in sorted order).
+func GetKnownMetricTypes() []MetricType {
+	metricTypes := make([]MetricType, 0, len(knownMetricTypes))
+	for metricType, known := range knownMetricTypes {
+		if known {
+			metricTypes = append(metricTypes, metricType)
+		}
+	}
+
+	slices.Sort(metricTypes)
+
+	return metricTypes
+}
+
 func AsyncStoreMetric(metric *Metric) {
 	err := metric.Validate()
 	if err != nil {
@@ -33,6 +33,10 @@ type Query struct {
 	// This filter is applied after all other Query conditions are applied.
 	Where map[MetricAttribute]any `json:"where,omitempty"`
 
+	// If set, aggregate the matching metrics instead of returning them.
+	// When aggregating, Sort and Limit apply to the aggregate results.
+	Aggregate *Aggregation `json:"aggregate,omitempty"`
+
 	// Only return data of this type.
 	// This field is required in the query to specify which kind of metric to return.
 	Type MetricType `json:"type" required:""`
@@ -52,7 +56,12 @@ func (this *Query) Validate() error {
 		this.Where = make(map[MetricAttribute]any)
 	}
 
-	return validateAttributeMap(this.Where)
+	err = validateAttributeMap(this.Where)
+	if err != nil {
+		return err
+	}
+
+	return this.Aggregate.Validate()
 }
 
 func (this *Query) Match(metric *Metric) bool {
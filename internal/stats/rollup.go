@@ -0,0 +1,152 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Attributes that are dropped when downsampling.
+// These are specific to a single request, so keeping them would leave little to combine.
+var rollupDroppedAttributes = map[MetricAttribute]bool{
+	MetricAttributeSender:    true,
+	MetricAttributeUserEmail: true,
+}
+
+// A summary of the metrics that were combined into a single (rollup) metric.
+type MetricRollup struct {
+	// The size of the time bucket this rollup covers.
+	// The rollup metric's timestamp is the start of this bucket.
+	Bucket TimeBucket `json:"bucket"`
+
+	Count int64   `json:"count"`
+	Sum   float64 `json:"sum"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+}
+
+// A running summary of metric values that treats rollups as all the metrics they summarize.
+type metricSummary struct {
+	count int64
+	sum   float64
+	min   float64
+	max   float64
+}
+
+func (this *metricSummary) add(metric *Metric) {
+	count := int64(1)
+	sum := metric.Value
+	min := metric.Value
+	max := metric.Value
+
+	if metric.Rollup != nil {
+		count = metric.Rollup.Count
+		sum = metric.Rollup.Sum
+		min = metric.Rollup.Min
+		max = metric.Rollup.Max
+	}
+
+	if this.count == 0 {
+		this.min = min
+		this.max = max
+	} else {
+		this.min = math.Min(this.min, min)
+		this.max = math.Max(this.max, max)
+	}
+
+	this.count += count
+	this.sum += sum
+}
+
+func (this *metricSummary) mean() float64 {
+	if this.count == 0 {
+		return 0
+	}
+
+	return this.sum / float64(this.count)
+}
+
+// Downsample metrics into a single rollup metric for each time bucket and set of attributes.
+// Metrics that are already rollups are merged into the bucket their timestamp falls in.
+// The user and sender attributes are dropped from the rollups.
+// The returned rollups are sorted by time (and then by type and attributes).
+func Downsample(metrics []*Metric, bucket TimeBucket) ([]*Metric, error) {
+	aggregation := Aggregation{TimeBucket: bucket}
+	if aggregation.GetBucketSize() == 0 {
+		return nil, fmt.Errorf("Unknown downsampling time bucket: '%v'.", bucket)
+	}
+
+	type rollupGroup struct {
+		metric  *Metric
+		summary metricSummary
+	}
+
+	groups := make(map[string]*rollupGroup)
+	keys := make(map[*Metric]string)
+
+	for _, metric := range metrics {
+		attributes := make(map[MetricAttribute]any, len(metric.Attributes))
+		for key, value := range metric.Attributes {
+			if !rollupDroppedAttributes[key] {
+				attributes[key] = value
+			}
+		}
+
+		bucketStart := aggregation.GetBucketStart(metric.Timestamp)
+		key := string(metric.Type) + "\x00" + getGroupKey(attributes, &bucketStart)
+
+		group := groups[key]
+		if group == nil {
+			group = &rollupGroup{
+				metric: &Metric{
+					Timestamp:  bucketStart,
+					Type:       metric.Type,
+					Attributes: attributes,
+				},
+			}
+
+			groups[key] = group
+			keys[group.metric] = key
+		}
+
+		group.summary.add(metric)
+	}
+
+	rollups := make([]*Metric, 0, len(groups))
+	for _, group := range groups {
+		group.metric.Value = group.summary.mean()
+		group.metric.Rollup = &MetricRollup{
+			Bucket: bucket,
+			Count:  group.summary.count,
+			Sum:    group.summary.sum,
+			Min:    group.summary.min,
+			Max:    group.summary.max,
+		}
+
+		rollups = append(rollups, group.metric)
+	}
+
+	slices.SortFunc(rollups, func(a *Metric, b *Metric) int {
+		if a.Timestamp != b.Timestamp {
+			return cmp.Compare(a.Timestamp, b.Timestamp)
+		}
+
+		return strings.Compare(keys[a], keys[b])
+	})
+
+	return rollups, nil
+}
+
+// Check if all the metrics are already rollups of the given time bucket,
+// i.e., downsampling them again would not change anything.
+func IsDownsampled(metrics []*Metric, bucket TimeBucket) bool {
+	for _, metric := range metrics {
+		if (metric.Rollup == nil) || (metric.Rollup.Bucket != bucket) {
+			return false
+		}
+	}
+
+	return true
+}
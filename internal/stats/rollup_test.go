@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edulinq/autograder/internal/timestamp"
+	"github.com/edulinq/autograder/internal/util"
+)
+
+const testHour int64 = 60 * testMinute
+
+func TestDownsampleBase(test *testing.T) {
+	metrics := []*Metric{
+		&Metric{Timestamp: timestamp.FromMSecs(1*testHour + 1), Type: MetricTypeAPIRequest, Value: 10, Attributes: map[MetricAttribute]any{MetricAttributeEndpoint: "E1", MetricAttributeUserEmail: "U1", MetricAttributeSender: "S1"}},
+		&Metric{Timestamp: timestamp.FromMSecs(1*testHour + 2), Type: MetricTypeAPIRequest, Value: 20, Attributes: map[MetricAttribute]any{MetricAttributeEndpoint: "E1", MetricAttributeUserEmail: "U2", MetricAttributeSender: "S2"}},
+		&Metric{Timestamp: timestamp.FromMSecs(1*testHour + 3), Type: MetricTypeAPIRequest, Value: 30, Attributes: map[MetricAttribute]any{MetricAttributeEndpoint: "E2"}},
+		&Metric{Timestamp: timestamp.FromMSecs(2*testHour + 1), Type: MetricTypeAPIRequest, Value: 40, Attributes: map[MetricAttribute]any{MetricAttributeEndpoint: "E1"}},
+
+		// An existing rollup that gets merged.
+		&Metric{
+			Timestamp:  timestamp.FromMSecs(2 * testHour),
+			Type:       MetricTypeAPIRequest,
+			Value:      5,
+			Attributes: map[MetricAttribute]any{MetricAttributeEndpoint: "E1"},
+			Rollup:     &MetricRollup{Bucket: TimeBucketMinute, Count: 2, Sum: 10, Min: 1, Max: 9},
+		},
+	}
+
+	expected := []*Metric{
+		&Metric{
+			Timestamp:  timestamp.FromMSecs(1 * testHour),
+			Type:       MetricTypeAPIRequest,
+			Value:      15,
+			Attributes: map[MetricAttribute]any{MetricAttributeEndpoint: "E1"},
+			Rollup:     &MetricRollup{Bucket: TimeBucketHour, Count: 2, Sum: 30, Min: 10, Max: 20},
+		},
+		&Metric{
+			Timestamp:  timestamp.FromMSecs(1 * testHour),
+			Type:       MetricTypeAPIRequest,
+			Value:      30,
+			Attributes: map[MetricAttribute]any{MetricAttributeEndpoint: "E2"},
+			Rollup:     &MetricRollup{Bucket: TimeBucketHour, Count: 1, Sum: 30, Min: 30, Max: 30},
+		},
+		&Metric{
+			Timestamp:  timestamp.FromMSecs(2 * testHour),
+			Type:       MetricTypeAPIRequest,
+			Value:      50.0 / 3.0,
+			Attributes: map[MetricAttribute]any{MetricAttributeEndpoint: "E1"},
+			Rollup:     &MetricRollup{Bucket: TimeBucketHour, Count: 3, Sum: 50, Min: 1, Max: 40},
+		},
+	}
+
+	actual, err := Downsample(metrics, TimeBucketHour)
+	if err != nil {
+		test.Fatalf("Failed to downsample: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		test.Fatalf("Unexpected rollups. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(actual))
+	}
+
+	if IsDownsampled(metrics, TimeBucketHour) {
+		test.Fatalf("Raw metrics are reported as downsampled.")
+	}
+
+	if !IsDownsampled(actual, TimeBucketHour) {
+		test.Fatalf("Hourly rollups are not reported as downsampled.")
+	}
+
+	if IsDownsampled(actual, TimeBucketDay) {
+		test.Fatalf("Hourly rollups are reported as downsampled by day.")
+	}
+
+	// Downsampling again does not change anything.
+	again, err := Downsample(actual, TimeBucketHour)
+	if err != nil {
+		test.Fatalf("Failed to downsample again: '%v'.", err)
+	}
+
+	if !reflect.DeepEqual(actual, again) {
+		test.Fatalf("Downsampling rollups changed them. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(actual), util.MustToJSONIndent(again))
+	}
+
+	// Aggregates count rollups as all the metrics they summarize.
+	query := Query{Aggregate: &Aggregation{
+		Functions: []AggregateFunction{AggregateFunctionCount, AggregateFunctionSum, AggregateFunctionMin, AggregateFunctionMax},
+	}}
+
+	expectedAggregates := Aggregate(metrics, query)
+	actualAggregates := Aggregate(actual, query)
+
+	if !reflect.DeepEqual(expectedAggregates, actualAggregates) {
+		test.Fatalf("Unexpected rollup aggregates. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expectedAggregates), util.MustToJSONIndent(actualAggregates))
+	}
+}
+
+func TestDownsampleBadBucket(test *testing.T) {
+	_, err := Downsample([]*Metric{}, TimeBucketNone)
+	if err == nil {
+		test.Fatalf("Did not get an error when downsampling without a bucket.")
+	}
+}
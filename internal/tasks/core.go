@@ -136,6 +136,8 @@ func runTask(task *model.FullScheduledTask) {
 		err = RunCourseBackupTask(task)
 	case model.TaskTypeCourseEmailLogs:
 		err = RunCourseEmailLogsTask(task)
+	case model.TaskTypeCourseGitPoll:
+		err = RunCourseGitPollTask(task)
 	case model.TaskTypeCourseReport:
 		err = RunCourseReportTask(task)
 	case model.TaskTypeCourseScoringUpload:
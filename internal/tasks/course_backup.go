@@ -3,8 +3,11 @@ package tasks
 import (
 	"fmt"
 
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
 	"github.com/edulinq/autograder/internal/model"
 	"github.com/edulinq/autograder/internal/procedures/backup"
+	"github.com/edulinq/autograder/internal/util"
 )
 
 func RunCourseBackupTask(task *model.FullScheduledTask) error {
@@ -12,10 +15,32 @@ func RunCourseBackupTask(task *model.FullScheduledTask) error {
 		return fmt.Errorf("Course backup task has no course.")
 	}
 
-	err := backup.BackupCourse(task.CourseID)
+	course, err := db.GetCourse(task.CourseID)
+	if err != nil {
+		return fmt.Errorf("Failed to get course '%s': '%w'.", task.CourseID, err)
+	}
+
+	if course == nil {
+		return fmt.Errorf("Unable to find course '%s'.", task.CourseID)
+	}
+
+	options, err := util.JSONTransformTypes(task.Options, backup.BackupOptions{})
+	if err != nil {
+		return fmt.Errorf("Unable to get backup options: '%w'.", err)
+	}
+
+	result, err := backup.BackupCourseWithOptions(course, options)
 	if err != nil {
 		return fmt.Errorf("Failed to run course backup task: '%w'.", err)
 	}
 
+	if len(result.RemovedBackups) > 0 {
+		log.Info("Removed old course backups.", course, log.NewAttr("removed-backups", result.RemovedBackups))
+	}
+
+	if (result.Verification != nil) && !result.Verification.Valid {
+		return fmt.Errorf("Course backup '%s' failed verification: '%s'.", result.Path, util.MustToJSON(result.Verification))
+	}
+
 	return nil
 }
@@ -0,0 +1,31 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edulinq/autograder/internal/db"
+	"github.com/edulinq/autograder/internal/log"
+	"github.com/edulinq/autograder/internal/model"
+	"github.com/edulinq/autograder/internal/procedures/gitsubmit"
+)
+
+func RunCourseGitPollTask(task *model.FullScheduledTask) error {
+	course, err := db.GetCourse(task.CourseID)
+	if err != nil {
+		return fmt.Errorf("Failed to get course '%s': '%w'.", task.CourseID, err)
+	}
+
+	if course == nil {
+		return fmt.Errorf("Unable to find course '%s'.", task.CourseID)
+	}
+
+	count, err := gitsubmit.PollCourse(context.Background(), course)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Polled git repos.", course, log.NewAttr("graded-count", count))
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/edulinq/autograder/internal/db"
@@ -18,6 +19,6 @@ func RunCourseScoringUploadTask(task *model.FullScheduledTask) error {
 		return fmt.Errorf("Unable to find course '%s'.", task.CourseID)
 	}
 
-	_, err = scoring.FullCourseScoringAndUpload(course, false)
+	_, err = scoring.FullCourseScoringAndUpload(context.Background(), course, false)
 	return err
 }
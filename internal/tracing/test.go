@@ -0,0 +1,16 @@
+package tracing
+
+import (
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Record all spans (synchronously) to the given exporter (usually an in-memory exporter from tracetest).
+// Any existing tracing will be replaced.
+// The returned function will stop tracing.
+func SetExporterForTesting(exporter sdktrace.SpanExporter) func() {
+	setProvider(sdktrace.WithSyncer(exporter), true)
+
+	return func() {
+		Stop()
+	}
+}
@@ -0,0 +1,196 @@
+// Distributed tracing (via OpenTelemetry) of API requests, grading, and analysis.
+// When tracing is not enabled, spans are still "started" but nothing is recorded or exported.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/edulinq/autograder/internal/config"
+	"github.com/edulinq/autograder/internal/log"
+)
+
+const TRACER_NAME = "github.com/edulinq/autograder"
+
+var (
+	providerLock sync.Mutex
+	provider     *sdktrace.TracerProvider = nil
+)
+
+// Start recording and exporting traces to the collector set in the config.
+// Does nothing if tracing is disabled or already started.
+func Start() error {
+	if !config.TRACING_ENABLE.Get() {
+		return nil
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(config.TRACING_OTLP_ENDPOINT.Get()))
+	if err != nil {
+		return fmt.Errorf("Failed to create OTLP trace exporter for '%s': '%w'.", config.TRACING_OTLP_ENDPOINT.Get(), err)
+	}
+
+	if !setProvider(sdktrace.WithBatcher(exporter), false) {
+		// Tracing was already started, the new exporter was never used.
+		return exporter.Shutdown(context.Background())
+	}
+
+	log.Debug("Started tracing.", log.NewAttr("endpoint", config.TRACING_OTLP_ENDPOINT.Get()))
+
+	return nil
+}
+
+// Stop recording traces and export any that are still pending.
+func Stop() error {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	if provider == nil {
+		return nil
+	}
+
+	err := provider.Shutdown(context.Background())
+	provider = nil
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	if err != nil {
+		return fmt.Errorf("Failed to stop tracing: '%w'.", err)
+	}
+
+	return nil
+}
+
+// Check if traces are being recorded.
+func IsEnabled() bool {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	return (provider != nil)
+}
+
+// Set the provider that all spans will be recorded with.
+// Returns true if the provider was set.
+func setProvider(exporterOption sdktrace.TracerProviderOption, force bool) bool {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	if (provider != nil) && !force {
+		return false
+	}
+
+	if provider != nil {
+		provider.Shutdown(context.Background())
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		exporterOption,
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", config.NAME.Get()))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return true
+}
+
+// Start a span that is a child of any span in the context.
+// Callers must end the returned span (usually with EndSpan()).
+func StartSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return otel.Tracer(TRACER_NAME).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// Start a span for an incoming HTTP request.
+// When tracing is enabled, any trace context (W3C headers) sent with the request will be used as the span's parent.
+// The returned request carries the new span in its context.
+func StartHTTPSpan(request *http.Request, name string, attributes ...attribute.KeyValue) (*http.Request, trace.Span) {
+	ctx := request.Context()
+	if IsEnabled() {
+		ctx = propagation.TraceContext{}.Extract(ctx, propagation.HeaderCarrier(request.Header))
+	}
+
+	ctx, span := StartSpan(ctx, name, attributes...)
+	return request.WithContext(ctx), span
+}
+
+// Add attributes to the span in the context (if there is one).
+func SetAttributes(ctx context.Context, attributes ...attribute.KeyValue) {
+	if ctx == nil {
+		return
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attributes...)
+}
+
+// End a span, marking it as failed if there is an error.
+func EndSpan(span trace.Span, err error) {
+	SetSpanError(span, err)
+	span.End()
+}
+
+// Mark a span as failed (if there is an error) without ending it.
+func SetSpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Get the ID of the trace that the context is a part of.
+// Returns an empty string if the context is not being traced.
+func GetTraceID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() || !spanContext.IsSampled() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
+
+// Create a log attribute with the ID of the trace that the context is a part of.
+// Returns nil (which will be ignored by the logger) if the context is not being traced.
+func NewLogAttr(ctx context.Context) *log.Attr {
+	traceID := GetTraceID(ctx)
+	if traceID == "" {
+		return nil
+	}
+
+	return log.NewAttr("trace-id", traceID)
+}
+
+// Create a span attribute.
+// Values that are not strings, bools, or numbers will be converted into strings.
+func NewAttr(key string, value any) attribute.KeyValue {
+	switch typedValue := value.(type) {
+	case string:
+		return attribute.String(key, typedValue)
+	case bool:
+		return attribute.Bool(key, typedValue)
+	case int:
+		return attribute.Int(key, typedValue)
+	case int64:
+		return attribute.Int64(key, typedValue)
+	case float64:
+		return attribute.Float64(key, typedValue)
+	default:
+		return attribute.String(key, fmt.Sprintf("%v", value))
+	}
+}
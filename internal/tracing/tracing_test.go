@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpansBase(test *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	defer SetExporterForTesting(exporter)()
+
+	if !IsEnabled() {
+		test.Fatalf("Tracing is not enabled after setting an exporter.")
+	}
+
+	ctx, parent := StartSpan(context.Background(), "parent", NewAttr("count", 1))
+	traceID := GetTraceID(ctx)
+	if len(traceID) != 32 {
+		test.Fatalf("Unexpected trace ID: '%s'.", traceID)
+	}
+
+	childCtx, child := StartSpan(ctx, "child")
+	if GetTraceID(childCtx) != traceID {
+		test.Fatalf("Child span is in a different trace. Expected: '%s', Actual: '%s'.", traceID, GetTraceID(childCtx))
+	}
+
+	EndSpan(child, errors.New("child error"))
+	EndSpan(parent, nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		test.Fatalf("Unexpected number of spans. Expected: 2, Actual: %d.", len(spans))
+	}
+
+	// Spans are exported as they end.
+	if (spans[0].Name != "child") || (spans[1].Name != "parent") {
+		test.Fatalf("Unexpected span names. Expected: ['child', 'parent'], Actual: ['%s', '%s'].", spans[0].Name, spans[1].Name)
+	}
+
+	if spans[0].Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		test.Fatalf("Child span does not have the parent span as its parent.")
+	}
+
+	if (spans[0].Status.Code != codes.Error) || (spans[0].Status.Description != "child error") {
+		test.Fatalf("Unexpected child status: '%+v'.", spans[0].Status)
+	}
+
+	if spans[1].Status.Code != codes.Unset {
+		test.Fatalf("Unexpected parent status: '%+v'.", spans[1].Status)
+	}
+
+	if (len(spans[1].Attributes) != 1) || (spans[1].Attributes[0] != NewAttr("count", 1)) {
+		test.Fatalf("Unexpected parent attributes: '%v'.", spans[1].Attributes)
+	}
+
+	logAttr := NewLogAttr(childCtx)
+	if (logAttr == nil) || (logAttr.Name != "trace-id") || (logAttr.Value != traceID) {
+		test.Fatalf("Unexpected log attribute: '%v'.", logAttr)
+	}
+}
+
+func TestSpansDisabled(test *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	SetExporterForTesting(exporter)()
+
+	if IsEnabled() {
+		test.Fatalf("Tracing is enabled after being stopped.")
+	}
+
+	ctx, span := StartSpan(nil, "disabled")
+	EndSpan(span, nil)
+
+	if GetTraceID(ctx) != "" {
+		test.Fatalf("Got a trace ID when tracing is disabled: '%s'.", GetTraceID(ctx))
+	}
+
+	if NewLogAttr(ctx) != nil {
+		test.Fatalf("Got a log attribute when tracing is disabled: '%v'.", NewLogAttr(ctx))
+	}
+
+	if len(exporter.GetSpans()) != 0 {
+		test.Fatalf("Spans were recorded when tracing is disabled.")
+	}
+}
@@ -30,6 +30,7 @@ type SavedHTTPRequest struct {
 	URL            string
 	Method         string
 	RequestHeaders map[string][]string
+	RequestBody    string `json:",omitempty"`
 
 	ResponseCode    int
 	ResponseHeaders map[string][]string
@@ -142,6 +143,39 @@ func postPutWithHeaders(verb string, uri string, form map[string]string, headers
 	return doRequest(uri, request, verb, checkResult)
 }
 
+// Post a raw body (e.g., JSON) with the given content type.
+// Returns: (body, headers (response), error)
+func PostBodyWithHeaders(uri string, contentType string, body string, headers map[string][]string) (string, map[string][]string, error) {
+	return sendBodyWithHeaders("POST", uri, contentType, body, headers)
+}
+
+// Returns: (body, headers (response), error)
+func PutBodyWithHeaders(uri string, contentType string, body string, headers map[string][]string) (string, map[string][]string, error) {
+	return sendBodyWithHeaders("PUT", uri, contentType, body, headers)
+}
+
+// Returns: (body, headers (response), error)
+func PatchBodyWithHeaders(uri string, contentType string, body string, headers map[string][]string) (string, map[string][]string, error) {
+	return sendBodyWithHeaders("PATCH", uri, contentType, body, headers)
+}
+
+func sendBodyWithHeaders(verb string, uri string, contentType string, body string, headers map[string][]string) (string, map[string][]string, error) {
+	request, err := http.NewRequest(verb, uri, strings.NewReader(body))
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to create %s request on URL '%s': '%w'.", verb, uri, err)
+	}
+
+	request.Header.Add("Content-Type", contentType)
+
+	for key, values := range headers {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+
+	return doRequest(uri, request, verb, true)
+}
+
 func PostFiles(uri string, form map[string]string, paths []string, checkResult bool) (string, error) {
 	var buffer bytes.Buffer
 
@@ -210,10 +244,16 @@ func doRequest(uri string, request *http.Request, verb string, checkResult bool)
 	body := string(rawBody)
 
 	if storeHTTPDir != "" {
+		requestBody, err := readRequestBody(request)
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed to read request body for HTTP request '%s': '%w'.", uri, err)
+		}
+
 		request := SavedHTTPRequest{
 			URL:             uri,
 			Method:          request.Method,
 			RequestHeaders:  request.Header,
+			RequestBody:     requestBody,
 			ResponseCode:    response.StatusCode,
 			ResponseHeaders: response.Header,
 			ResponseBody:    body,
@@ -225,7 +265,8 @@ func doRequest(uri string, request *http.Request, verb string, checkResult bool)
 		}
 	}
 
-	if checkResult && (response.StatusCode != http.StatusOK) {
+	// Any successful (2xx) status is OK.
+	if checkResult && ((response.StatusCode < http.StatusOK) || (response.StatusCode >= http.StatusMultipleChoices)) {
 		log.Error("Got a non-OK status.",
 			log.NewAttr("code", response.StatusCode), log.NewAttr("body", body),
 			log.NewAttr("headers", response.Header), log.NewAttr("url", uri))
@@ -235,6 +276,26 @@ func doRequest(uri string, request *http.Request, verb string, checkResult bool)
 	return body, response.Header, nil
 }
 
+// Get a copy of a request's body (without consuming it).
+func readRequestBody(request *http.Request) (string, error) {
+	if request.GetBody == nil {
+		return "", nil
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
 func writeRequest(request *SavedHTTPRequest) error {
 	baseDir := storeHTTPDir
 	if baseDir == "" {
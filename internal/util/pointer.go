@@ -25,3 +25,7 @@ func PointerToInt(target *int) int {
 
 	return *target
 }
+
+func FloatPointer(target float64) *float64 {
+	return &target
+}
@@ -3,6 +3,9 @@ package util
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
 )
 
 func Sha256Hex(data []byte) string {
@@ -14,6 +17,27 @@ func Sha256HexFromString(data string) string {
 	return Sha256Hex([]byte(data))
 }
 
+func Sha256HexFromReader(reader io.Reader) (string, error) {
+	hash := sha256.New()
+
+	_, err := io.Copy(hash, reader)
+	if err != nil {
+		return "", fmt.Errorf("Failed to copy contents for SHA256 hashing: '%w'.", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func Sha256FileHex(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed to open file '%s' for SHA256 hashing: '%w'.", path, err)
+	}
+	defer file.Close()
+
+	return Sha256HexFromReader(file)
+}
+
 // Convert an object to JSON, then hash the JSON.
 func Sha256HashFromJSONObject(object any) (string, error) {
 	json, err := ToJSON(object)